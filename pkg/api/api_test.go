@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	kruiserolloutsv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	kruiserolloutsv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+)
+
+func TestSchemeRoundTripsRolloutStatus(t *testing.T) {
+	// the API server serves times with a precision of a second
+	updated := metav1.NewTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local))
+	codecs := serializer.NewCodecFactory(GetScheme())
+
+	testCases := []struct {
+		name    string
+		rollout runtime.Object
+	}{
+		{
+			name: "v1alpha1",
+			rollout: &kruiserolloutsv1alpha1.Rollout{
+				TypeMeta:   metav1.TypeMeta{APIVersion: kruiserolloutsv1alpha1.GroupVersion.String(), Kind: "Rollout"},
+				ObjectMeta: metav1.ObjectMeta{Name: "rollout-demo", Namespace: "test"},
+				Status: kruiserolloutsv1alpha1.RolloutStatus{
+					ObservedGeneration: 3,
+					Phase:              kruiserolloutsv1alpha1.RolloutPhaseProgressing,
+					Message:            "Rollout is in step(1/2), and wait approve",
+					CanaryStatus: &kruiserolloutsv1alpha1.CanaryStatus{
+						CurrentStepIndex: 1,
+						CurrentStepState: kruiserolloutsv1alpha1.CanaryStepStatePaused,
+						CanaryRevision:   "6d8b5b7f9c",
+						CanaryReplicas:   1,
+					},
+					Conditions: []kruiserolloutsv1alpha1.RolloutCondition{{
+						Type:               kruiserolloutsv1alpha1.RolloutConditionProgressing,
+						Status:             corev1.ConditionTrue,
+						LastUpdateTime:     updated,
+						LastTransitionTime: updated,
+						Reason:             kruiserolloutsv1alpha1.ProgressingReasonInRolling,
+					}},
+				},
+			},
+		},
+		{
+			name: "v1beta1",
+			rollout: &kruiserolloutsv1beta1.Rollout{
+				TypeMeta:   metav1.TypeMeta{APIVersion: kruiserolloutsv1beta1.GroupVersion.String(), Kind: "Rollout"},
+				ObjectMeta: metav1.ObjectMeta{Name: "rollout-demo", Namespace: "test"},
+				Status: kruiserolloutsv1beta1.RolloutStatus{
+					ObservedGeneration: 3,
+					Phase:              kruiserolloutsv1beta1.RolloutPhaseProgressing,
+					Message:            "Rollout is in step(1/2), and wait approve",
+					CanaryStatus: &kruiserolloutsv1beta1.CanaryStatus{
+						CurrentStepIndex: 1,
+						CurrentStepState: kruiserolloutsv1beta1.CanaryStepStatePaused,
+						CanaryRevision:   "6d8b5b7f9c",
+						CanaryReplicas:   1,
+					},
+					Conditions: []kruiserolloutsv1beta1.RolloutCondition{{
+						Type:               kruiserolloutsv1beta1.RolloutConditionProgressing,
+						Status:             corev1.ConditionTrue,
+						LastUpdateTime:     updated,
+						LastTransitionTime: updated,
+						Reason:             kruiserolloutsv1beta1.ProgressingReasonInRolling,
+					}},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := runtime.Encode(codecs.LegacyCodec(tc.rollout.GetObjectKind().GroupVersionKind().GroupVersion()), tc.rollout)
+			assert.NoError(t, err)
+			decoded, _, err := codecs.UniversalDeserializer().Decode(data, nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.rollout, decoded)
+
+			// a newer Kruise Rollout may serve status fields the bundled types do not know, which are left out
+			// rather than failing the decoding of the known ones
+			data = bytes.Replace(data, []byte(`"status":{`), []byte(`"status":{"currentStepLabel":"canary-20%",`), 1)
+			decoded, _, err = codecs.UniversalDeserializer().Decode(data, nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.rollout, decoded)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"time"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiserolloutsv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	kruiserolloutsv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// TableHandler describes the columns printed for a kind, and how an object of that kind fills them.
+type TableHandler struct {
+	Columns []metav1.TableColumnDefinition
+	Cells   func(obj runtime.Object) ([]interface{}, error)
+}
+
+var (
+	rolloutTableHandler = TableHandler{
+		Columns: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name", Description: "Name of the rollout."},
+			{Name: "Workload", Type: "string", Description: "The workload the rollout manages, as kind/name."},
+			{Name: "Phase", Type: "string", Description: "The phase of the rollout."},
+			{Name: "Step", Type: "string", Description: "The current canary step out of all steps."},
+			{Name: "Weight", Type: "string", Description: "The share of traffic routed to the canary at the current step."},
+			{Name: "Age", Type: "string", Description: "Time since the rollout was created."},
+		},
+		Cells: rolloutCells,
+	}
+
+	// cloneSetTableHandler tells in-place updates from recreate updates with the update type, since the status of a
+	// CloneSet counts the updated pods but not how they were updated
+	cloneSetTableHandler = TableHandler{
+		Columns: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name", Description: "Name of the cloneset."},
+			{Name: "Desired", Type: "integer", Description: "The desired number of pods."},
+			{Name: "Updated", Type: "integer", Description: "The number of pods updated to the latest revision."},
+			{Name: "Updated-Ready", Type: "integer", Description: "The number of pods updated to the latest revision and ready."},
+			{Name: "Ready", Type: "integer", Description: "The number of ready pods."},
+			{Name: "Update-Type", Type: "string", Description: "How the pods are updated: InPlaceIfPossible, InPlaceOnly or ReCreate."},
+			{Name: "Age", Type: "string", Description: "Time since the cloneset was created."},
+		},
+		Cells: cloneSetCells,
+	}
+
+	// defaultTableHandler prints the columns every object has, for kinds without a handler of their own
+	defaultTableHandler = TableHandler{
+		Columns: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name", Description: "Name of the object."},
+			{Name: "Age", Type: "string", Description: "Time since the object was created."},
+		},
+		Cells: func(obj runtime.Object) ([]interface{}, error) {
+			accessor, err := meta.Accessor(obj)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{accessor.GetName(), translateTimestampSince(accessor.GetCreationTimestamp())}, nil
+		},
+	}
+
+	tableHandlers = map[schema.GroupVersionKind]TableHandler{
+		kruiserolloutsv1alpha1.SchemeGroupVersion.WithKind("Rollout"): rolloutTableHandler,
+		kruiserolloutsv1beta1.SchemeGroupVersion.WithKind("Rollout"):  rolloutTableHandler,
+		kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"):    cloneSetTableHandler,
+	}
+)
+
+// RegisterTableHandler sets the handler used to print objects of the given kind as a table.
+func RegisterTableHandler(gvk schema.GroupVersionKind, handler TableHandler) {
+	tableHandlers[gvk] = handler
+}
+
+// ConvertToTable converts objects of the given kind into a table, with one row per object.
+func ConvertToTable(gvk schema.GroupVersionKind, objs ...runtime.Object) (*metav1.Table, error) {
+	handler, ok := tableHandlers[gvk]
+	if !ok {
+		handler = defaultTableHandler
+	}
+
+	table := &metav1.Table{ColumnDefinitions: handler.Columns}
+	for _, obj := range objs {
+		cells, err := handler.Cells(obj)
+		if err != nil {
+			return nil, err
+		}
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells:  cells,
+			Object: runtime.RawExtension{Object: obj},
+		})
+	}
+	return table, nil
+}
+
+func rolloutCells(obj runtime.Object) ([]interface{}, error) {
+	var name, workload, phase, step, weight string
+	var created metav1.Time
+
+	switch r := obj.(type) {
+	case *kruiserolloutsv1beta1.Rollout:
+		name, phase, created = r.Name, string(r.Status.Phase), r.CreationTimestamp
+		workload = fmt.Sprintf("%s/%s", r.Spec.WorkloadRef.Kind, r.Spec.WorkloadRef.Name)
+		if canary := r.Spec.Strategy.Canary; canary != nil && r.Status.CanaryStatus != nil {
+			index := r.Status.CanaryStatus.CurrentStepIndex
+			step = fmt.Sprintf("%d/%d", index, len(canary.Steps))
+			if index >= 1 && int(index) <= len(canary.Steps) && canary.Steps[index-1].Traffic != nil {
+				weight = *canary.Steps[index-1].Traffic
+			}
+		}
+	case *kruiserolloutsv1alpha1.Rollout:
+		name, phase, created = r.Name, string(r.Status.Phase), r.CreationTimestamp
+		if ref := r.Spec.ObjectRef.WorkloadRef; ref != nil {
+			workload = fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+		}
+		if canary := r.Spec.Strategy.Canary; canary != nil && r.Status.CanaryStatus != nil {
+			index := r.Status.CanaryStatus.CurrentStepIndex
+			step = fmt.Sprintf("%d/%d", index, len(canary.Steps))
+			// the steps of a v1alpha1 rollout carry the traffic as a weight
+			if index >= 1 && int(index) <= len(canary.Steps) && canary.Steps[index-1].Weight != nil {
+				weight = fmt.Sprintf("%d%%", *canary.Steps[index-1].Weight)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected rollout type %T", obj)
+	}
+
+	return []interface{}{name, orNone(workload), orNone(phase), orNone(step), orNone(weight), translateTimestampSince(created)}, nil
+}
+
+func cloneSetCells(obj runtime.Object) ([]interface{}, error) {
+	cs, ok := obj.(*kruiseappsv1alpha1.CloneSet)
+	if !ok {
+		return nil, fmt.Errorf("unexpected cloneset type %T", obj)
+	}
+	desired := int32(1)
+	if cs.Spec.Replicas != nil {
+		desired = *cs.Spec.Replicas
+	}
+	updateType := cs.Spec.UpdateStrategy.Type
+	if len(updateType) == 0 {
+		updateType = kruiseappsv1alpha1.RecreateCloneSetUpdateStrategyType
+	}
+	return []interface{}{cs.Name, int64(desired), int64(cs.Status.UpdatedReplicas), int64(cs.Status.UpdatedReadyReplicas),
+		int64(cs.Status.ReadyReplicas), string(updateType), translateTimestampSince(cs.CreationTimestamp)}, nil
+}
+
+func orNone(s string) string {
+	if len(s) == 0 {
+		return "<none>"
+	}
+	return s
+}
+
+func translateTimestampSince(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
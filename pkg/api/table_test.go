@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiserolloutsv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	kruiserolloutsv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/utils/pointer"
+)
+
+func TestConvertToTable(t *testing.T) {
+	created := metav1.NewTime(time.Now().Add(-5 * time.Hour))
+
+	v1beta1Rollout := &kruiserolloutsv1beta1.Rollout{
+		ObjectMeta: metav1.ObjectMeta{Name: "rollout-demo", Namespace: "test", CreationTimestamp: created},
+		Spec: kruiserolloutsv1beta1.RolloutSpec{
+			WorkloadRef: kruiserolloutsv1beta1.ObjectRef{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet", Name: "web"},
+			Strategy: kruiserolloutsv1beta1.RolloutStrategy{
+				Canary: &kruiserolloutsv1beta1.CanaryStrategy{
+					Steps: []kruiserolloutsv1beta1.CanaryStep{
+						{TrafficRoutingStrategy: kruiserolloutsv1beta1.TrafficRoutingStrategy{Traffic: pointer.String("20%")}},
+						{TrafficRoutingStrategy: kruiserolloutsv1beta1.TrafficRoutingStrategy{Traffic: pointer.String("50%")}},
+						{TrafficRoutingStrategy: kruiserolloutsv1beta1.TrafficRoutingStrategy{Traffic: pointer.String("100%")}},
+					},
+				},
+			},
+		},
+		Status: kruiserolloutsv1beta1.RolloutStatus{
+			Phase:        kruiserolloutsv1beta1.RolloutPhaseProgressing,
+			CanaryStatus: &kruiserolloutsv1beta1.CanaryStatus{CurrentStepIndex: 2},
+		},
+	}
+	healthyRollout := &kruiserolloutsv1beta1.Rollout{
+		ObjectMeta: metav1.ObjectMeta{Name: "rollout-idle", Namespace: "test", CreationTimestamp: created},
+		Spec: kruiserolloutsv1beta1.RolloutSpec{
+			WorkloadRef: kruiserolloutsv1beta1.ObjectRef{APIVersion: "apps/v1", Kind: "Deployment", Name: "api"},
+		},
+		Status: kruiserolloutsv1beta1.RolloutStatus{Phase: kruiserolloutsv1beta1.RolloutPhaseHealthy},
+	}
+	v1alpha1Rollout := &kruiserolloutsv1alpha1.Rollout{
+		ObjectMeta: metav1.ObjectMeta{Name: "rollout-demo", Namespace: "test", CreationTimestamp: created},
+		Spec: kruiserolloutsv1alpha1.RolloutSpec{
+			ObjectRef: kruiserolloutsv1alpha1.ObjectRef{
+				WorkloadRef: &kruiserolloutsv1alpha1.WorkloadRef{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet", Name: "web"},
+			},
+			Strategy: kruiserolloutsv1alpha1.RolloutStrategy{
+				Canary: &kruiserolloutsv1alpha1.CanaryStrategy{
+					Steps: []kruiserolloutsv1alpha1.CanaryStep{
+						{TrafficRoutingStrategy: kruiserolloutsv1alpha1.TrafficRoutingStrategy{Weight: pointer.Int32(10)}},
+					},
+				},
+			},
+		},
+		Status: kruiserolloutsv1alpha1.RolloutStatus{
+			Phase:        kruiserolloutsv1alpha1.RolloutPhaseProgressing,
+			CanaryStatus: &kruiserolloutsv1alpha1.CanaryStatus{CurrentStepIndex: 1},
+		},
+	}
+	inPlaceCloneSet := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test", CreationTimestamp: created},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Replicas:       pointer.Int32(5),
+			UpdateStrategy: kruiseappsv1alpha1.CloneSetUpdateStrategy{Type: kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType},
+		},
+		Status: kruiseappsv1alpha1.CloneSetStatus{Replicas: 5, ReadyReplicas: 4, UpdatedReplicas: 3, UpdatedReadyReplicas: 2},
+	}
+	// a cloneset without replicas or update type has the defaults of the API server
+	recreateCloneSet := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test", CreationTimestamp: created},
+		Status:     kruiseappsv1alpha1.CloneSetStatus{Replicas: 1, ReadyReplicas: 1, UpdatedReplicas: 1, UpdatedReadyReplicas: 1},
+	}
+
+	testCases := []struct {
+		name      string
+		gvk       schema.GroupVersionKind
+		objs      []runtime.Object
+		expectOut string
+	}{
+		{
+			name: "v1beta1 rollouts",
+			gvk:  kruiserolloutsv1beta1.SchemeGroupVersion.WithKind("Rollout"),
+			objs: []runtime.Object{v1beta1Rollout, healthyRollout},
+			expectOut: "NAME           WORKLOAD         PHASE         STEP     WEIGHT   AGE\n" +
+				"rollout-demo   CloneSet/web     Progressing   2/3      50%      5h\n" +
+				"rollout-idle   Deployment/api   Healthy       <none>   <none>   5h\n",
+		},
+		{
+			name: "v1alpha1 rollout",
+			gvk:  kruiserolloutsv1alpha1.SchemeGroupVersion.WithKind("Rollout"),
+			objs: []runtime.Object{v1alpha1Rollout},
+			expectOut: "NAME           WORKLOAD       PHASE         STEP   WEIGHT   AGE\n" +
+				"rollout-demo   CloneSet/web   Progressing   1/1    10%      5h\n",
+		},
+		{
+			name: "clonesets",
+			gvk:  kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"),
+			objs: []runtime.Object{inPlaceCloneSet, recreateCloneSet},
+			expectOut: "NAME   DESIRED   UPDATED   UPDATED-READY   READY   UPDATE-TYPE         AGE\n" +
+				"web    5         3         2               4       InPlaceIfPossible   5h\n" +
+				"api    1         1         1               1       ReCreate            5h\n",
+		},
+		{
+			name: "kind without a handler",
+			gvk:  kruiseappsv1alpha1.SchemeGroupVersion.WithKind("BroadcastJob"),
+			objs: []runtime.Object{&kruiseappsv1alpha1.BroadcastJob{ObjectMeta: metav1.ObjectMeta{Name: "web", CreationTimestamp: created}}},
+			expectOut: "NAME   AGE\n" +
+				"web    5h\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			table, err := ConvertToTable(tc.gvk, tc.objs...)
+			assert.NoError(t, err)
+
+			out := &bytes.Buffer{}
+			assert.NoError(t, printers.NewTablePrinter(printers.PrintOptions{}).PrintObj(table, out))
+			assert.Equal(t, tc.expectOut, out.String())
+		})
+	}
+}
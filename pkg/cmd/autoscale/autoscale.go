@@ -0,0 +1,293 @@
+/*
+Copyright 2024 The Kruise Authors.
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscale
+
+import (
+	"context"
+	"fmt"
+
+	internalpolymorphichelpers "github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
+
+	"github.com/spf13/cobra"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	autoscalingv1client "k8s.io/client-go/kubernetes/typed/autoscaling/v1"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	autoscaleLong = templates.LongDesc(i18n.T(`
+		Creates an autoscaler that automatically chooses and sets the number of pods of a workload.
+
+		Looks up a cloneset, advanced statefulset, deployment, replica set, stateful set, or replication
+		controller by name and creates a HorizontalPodAutoscaler that uses the given resource as a reference.
+		The autoscaler scales the workload through its scale subresource, which CloneSets and Advanced
+		StatefulSets serve as the Kubernetes workloads do.`))
+
+	autoscaleExample = templates.Examples(i18n.T(`
+		# Auto scale a cloneset "web", with the number of pods between 2 and 10, target CPU utilization at 70%
+		kubectl-kruise autoscale cloneset/web --min=2 --max=10 --cpu-percent=70
+
+		# Auto scale an advanced statefulset "db", with at most 5 pods, no target CPU utilization specified so a default autoscaling policy will be used
+		kubectl-kruise autoscale asts db --max=5`))
+)
+
+// AutoscaleOptions declares the arguments accepted by the Autoscale command
+type AutoscaleOptions struct {
+	FilenameOptions *resource.FilenameOptions
+
+	RecordFlags *genericclioptions.RecordFlags
+	Recorder    genericclioptions.Recorder
+
+	PrintFlags *genericclioptions.PrintFlags
+	ToPrinter  func(string) (printers.ResourcePrinter, error)
+
+	Name       string
+	Min        int32
+	Max        int32
+	CPUPercent int32
+
+	createAnnotation bool
+	args             []string
+	enforceNamespace bool
+	namespace        string
+	dryRunStrategy   cmdutil.DryRunStrategy
+	builder          *resource.Builder
+	fieldManager     string
+
+	CanBeAutoscaled internalpolymorphichelpers.CanBeAutoscaledFunc
+	HPAClient       autoscalingv1client.HorizontalPodAutoscalersGetter
+
+	genericclioptions.IOStreams
+}
+
+// NewAutoscaleOptions creates the options for autoscale
+func NewAutoscaleOptions(ioStreams genericclioptions.IOStreams) *AutoscaleOptions {
+	return &AutoscaleOptions{
+		PrintFlags:      genericclioptions.NewPrintFlags("autoscaled").WithTypeSetter(scheme.Scheme),
+		FilenameOptions: &resource.FilenameOptions{},
+		RecordFlags:     genericclioptions.NewRecordFlags(),
+		Recorder:        genericclioptions.NoopRecorder{},
+
+		IOStreams: ioStreams,
+	}
+}
+
+// NewCmdAutoscale returns the autoscale Cobra command
+func NewCmdAutoscale(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewAutoscaleOptions(ioStreams)
+
+	validArgs := []string{"cloneset", "advancedstatefulset", "deployment", "replicaset", "replicationcontroller", "statefulset"}
+
+	cmd := &cobra.Command{
+		Use:                   "autoscale (-f FILENAME | TYPE NAME | TYPE/NAME) [--min=MINPODS] --max=MAXPODS [--cpu-percent=CPU]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Auto-scale a cloneset, advanced statefulset, deployment, replica set, stateful set, or replication controller"),
+		Long:                  autoscaleLong,
+		Example:               autoscaleExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+		ValidArgs: validArgs,
+	}
+
+	// bind flag structs
+	o.RecordFlags.AddFlags(cmd)
+	o.PrintFlags.AddFlags(cmd)
+	cmd.Flags().Int32Var(&o.Min, "min", -1, "The lower limit for the number of pods that can be set by the autoscaler. If it's not specified or negative, the server will apply a default value.")
+	cmd.Flags().Int32Var(&o.Max, "max", -1, "The upper limit for the number of pods that can be set by the autoscaler. Required.")
+	cmd.MarkFlagRequired("max")
+	cmd.Flags().Int32Var(&o.CPUPercent, "cpu-percent", -1, "The target average CPU utilization (represented as a percent of requested CPU) over all the pods. If it's not specified or negative, a default autoscaling policy will be used.")
+	cmd.Flags().StringVar(&o.Name, "name", "", i18n.T("The name for the newly created object. If not specified, the name of the input resource will be used."))
+	cmdutil.AddDryRunFlag(cmd)
+	cmdutil.AddFilenameOptionFlags(cmd, o.FilenameOptions, "identifying the resource to autoscale.")
+	cmdutil.AddApplyAnnotationFlags(cmd)
+	cmdutil.AddFieldManagerFlagVar(cmd, &o.fieldManager, "kubectl-kruise-autoscale")
+	return cmd
+}
+
+// Complete verifies command line arguments and loads data from the command environment
+func (o *AutoscaleOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.dryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	o.createAnnotation = cmdutil.GetFlagBool(cmd, cmdutil.ApplyAnnotationsFlag)
+	o.builder = f.NewBuilder()
+	o.CanBeAutoscaled = internalpolymorphichelpers.CanBeAutoscaledFn
+	o.args = args
+	o.RecordFlags.Complete(cmd)
+
+	o.Recorder, err = o.RecordFlags.ToRecorder()
+	if err != nil {
+		return err
+	}
+
+	kubeClient, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	o.HPAClient = kubeClient.AutoscalingV1()
+
+	o.namespace, o.enforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
+		o.PrintFlags.NamePrintFlags.Operation = operation
+		cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.dryRunStrategy)
+
+		return o.PrintFlags.ToPrinter()
+	}
+
+	return nil
+}
+
+// Validate checks that the provided autoscale options are specified.
+func (o *AutoscaleOptions) Validate() error {
+	if o.Max < 1 {
+		return fmt.Errorf("--max=MAXPODS is required and must be at least 1, max: %d", o.Max)
+	}
+	if o.Max < o.Min {
+		return fmt.Errorf("--max=MAXPODS must be larger or equal to --min=MINPODS, max: %d, min: %d", o.Max, o.Min)
+	}
+
+	return nil
+}
+
+// Run performs the execution
+func (o *AutoscaleOptions) Run() error {
+	r := o.builder.
+		Unstructured().
+		ContinueOnError().
+		NamespaceParam(o.namespace).DefaultNamespace().
+		FilenameParam(o.enforceNamespace, o.FilenameOptions).
+		ResourceTypeOrNameArgs(false, o.args...).
+		Flatten().
+		Do()
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	count := 0
+	err := r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		mapping := info.ResourceMapping()
+		if err := o.CanBeAutoscaled(mapping.GroupVersionKind.GroupKind()); err != nil {
+			return err
+		}
+
+		hpa := o.createHorizontalPodAutoscaler(info.Name, mapping)
+
+		if err := o.Recorder.Record(hpa); err != nil {
+			klog.V(4).Infof("error recording current command: %v", err)
+		}
+
+		if o.dryRunStrategy == cmdutil.DryRunClient {
+			count++
+
+			printer, err := o.ToPrinter("created")
+			if err != nil {
+				return err
+			}
+			return printer.PrintObj(hpa, o.Out)
+		}
+
+		if err := util.CreateOrUpdateAnnotation(o.createAnnotation, hpa, scheme.DefaultJSONEncoder()); err != nil {
+			return err
+		}
+
+		createOptions := metav1.CreateOptions{}
+		if o.fieldManager != "" {
+			createOptions.FieldManager = o.fieldManager
+		}
+		if o.dryRunStrategy == cmdutil.DryRunServer {
+			createOptions.DryRun = []string{metav1.DryRunAll}
+		}
+		// the autoscaler is created in the namespace of the workload, which may be given by a file
+		actualHPA, err := o.HPAClient.HorizontalPodAutoscalers(info.Namespace).Create(context.TODO(), hpa, createOptions)
+		if err != nil {
+			return err
+		}
+
+		count++
+		printer, err := o.ToPrinter("autoscaled")
+		if err != nil {
+			return err
+		}
+		return printer.PrintObj(actualHPA, o.Out)
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("no objects passed to autoscale")
+	}
+	return nil
+}
+
+// createHorizontalPodAutoscaler returns an autoscaler whose scaleTargetRef references the workload by the group,
+// version and kind of its mapping, e.g. apps.kruise.io/v1alpha1 CloneSet, which the HPA controller resolves to the
+// scale subresource of the workload.
+func (o *AutoscaleOptions) createHorizontalPodAutoscaler(refName string, mapping *meta.RESTMapping) *autoscalingv1.HorizontalPodAutoscaler {
+	name := o.Name
+	if len(name) == 0 {
+		name = refName
+	}
+
+	scaler := autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				APIVersion: mapping.GroupVersionKind.GroupVersion().String(),
+				Kind:       mapping.GroupVersionKind.Kind,
+				Name:       refName,
+			},
+			MaxReplicas: o.Max,
+		},
+	}
+
+	if o.Min > 0 {
+		v := o.Min
+		scaler.Spec.MinReplicas = &v
+	}
+	if o.CPUPercent >= 0 {
+		c := o.CPUPercent
+		scaler.Spec.TargetCPUUtilizationPercentage = &c
+	}
+
+	return &scaler
+}
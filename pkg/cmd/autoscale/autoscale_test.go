@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscale
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func newAutoscaleTestFactory(t *testing.T, path string, obj runtime.Object) *cmdtesting.TestFactory {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	codec := scheme.Codecs.LegacyCodec(internalapi.GetScheme().PrioritizedVersionsAllGroups()...)
+	client := &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != path || req.Method != http.MethodGet {
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+		}),
+	}
+	// the HPA client is replaced by the tests, the client set of the factory is only built from the client
+	tf.Client, tf.UnstructuredClient = client, client
+	return tf
+}
+
+func TestRunAutoscaleCloneSet(t *testing.T) {
+	cloneSet := &kruiseappsv1alpha1.CloneSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+	}
+	tf := newAutoscaleTestFactory(t, "/namespaces/test/clonesets/web", cloneSet)
+	defer tf.Cleanup()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdAutoscale(tf, streams)
+	o := NewAutoscaleOptions(streams)
+	o.Min, o.Max, o.CPUPercent = 2, 10, 70
+	assert.NoError(t, o.Complete(tf, cmd, []string{"cloneset/web"}))
+	assert.NoError(t, o.Validate())
+	client := kubefake.NewSimpleClientset()
+	o.HPAClient = client.AutoscalingV1()
+
+	assert.NoError(t, o.Run())
+	assert.Equal(t, "horizontalpodautoscaler.autoscaling/web autoscaled\n", out.String())
+
+	hpa, err := client.AutoscalingV1().HorizontalPodAutoscalers("test").Get(context.TODO(), "web", metav1.GetOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	gvk := kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet")
+	assert.Equal(t, gvk.GroupVersion().String(), hpa.Spec.ScaleTargetRef.APIVersion)
+	assert.Equal(t, gvk.Kind, hpa.Spec.ScaleTargetRef.Kind)
+	assert.Equal(t, "web", hpa.Spec.ScaleTargetRef.Name)
+	if assert.NotNil(t, hpa.Spec.MinReplicas) {
+		assert.Equal(t, int32(2), *hpa.Spec.MinReplicas)
+	}
+	assert.Equal(t, int32(10), hpa.Spec.MaxReplicas)
+	if assert.NotNil(t, hpa.Spec.TargetCPUUtilizationPercentage) {
+		assert.Equal(t, int32(70), *hpa.Spec.TargetCPUUtilizationPercentage)
+	}
+}
+
+func TestRunAutoscaleUnsupportedKind(t *testing.T) {
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+	}
+	tf := newAutoscaleTestFactory(t, "/namespaces/test/pods/web", pod)
+	defer tf.Cleanup()
+
+	streams := genericclioptions.NewTestIOStreamsDiscard()
+	cmd := NewCmdAutoscale(tf, streams)
+	o := NewAutoscaleOptions(streams)
+	o.Max = 10
+	assert.NoError(t, o.Complete(tf, cmd, []string{"pod/web"}))
+	client := kubefake.NewSimpleClientset()
+	o.HPAClient = client.AutoscalingV1()
+
+	assert.EqualError(t, o.Run(), "cannot autoscale a Pod")
+	assert.Empty(t, client.Actions())
+}
+
+func TestAutoscaleValidate(t *testing.T) {
+	o := NewAutoscaleOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Min, o.Max = -1, 0
+	assert.EqualError(t, o.Validate(), "--max=MAXPODS is required and must be at least 1, max: 0")
+
+	o.Min, o.Max = 5, 2
+	assert.EqualError(t, o.Validate(), "--max=MAXPODS must be larger or equal to --min=MINPODS, max: 2, min: 5")
+
+	o.Min, o.Max = 2, 2
+	assert.NoError(t, o.Validate())
+}
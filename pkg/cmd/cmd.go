@@ -23,14 +23,21 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/openkruise/kruise-tools/pkg/cmd/autoscale"
+	"github.com/openkruise/kruise-tools/pkg/cmd/convert"
 	"github.com/openkruise/kruise-tools/pkg/cmd/create"
 	"github.com/openkruise/kruise-tools/pkg/cmd/describe"
+	"github.com/openkruise/kruise-tools/pkg/cmd/edit"
 	cmdexec "github.com/openkruise/kruise-tools/pkg/cmd/exec"
 	"github.com/openkruise/kruise-tools/pkg/cmd/expose"
+	"github.com/openkruise/kruise-tools/pkg/cmd/get"
+	klabel "github.com/openkruise/kruise-tools/pkg/cmd/label"
 	"github.com/openkruise/kruise-tools/pkg/cmd/migrate"
 	krollout "github.com/openkruise/kruise-tools/pkg/cmd/rollout"
+	kscale "github.com/openkruise/kruise-tools/pkg/cmd/scale"
 	"github.com/openkruise/kruise-tools/pkg/cmd/scaledown"
 	kset "github.com/openkruise/kruise-tools/pkg/cmd/set"
+	"github.com/openkruise/kruise-tools/pkg/cmd/version"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -45,9 +52,7 @@ import (
 	"k8s.io/kubectl/pkg/cmd/patch"
 	"k8s.io/kubectl/pkg/cmd/plugin"
 	"k8s.io/kubectl/pkg/cmd/replace"
-	"k8s.io/kubectl/pkg/cmd/scale"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
-	"k8s.io/kubectl/pkg/cmd/version"
 	"k8s.io/kubectl/pkg/cmd/wait"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -374,7 +379,11 @@ func NewKubectlCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 			Commands: []*cobra.Command{
 				create.NewCmdCreate(f, ioStreams),
 				expose.NewCmdExposeService(f, ioStreams),
-				cmdWithShortOverwrite(scale.NewCmdScale(f, ioStreams), "Set a new size for a Deployment, ReplicaSet, CloneSet, or Advanced StatefulSet"),
+				get.NewCmdGet(f, ioStreams),
+				edit.NewCmdEdit(f, ioStreams),
+				kscale.NewCmdScale(f, ioStreams),
+				autoscale.NewCmdAutoscale(f, ioStreams),
+				klabel.NewCmdLabel(f, ioStreams),
 			},
 		},
 		{
@@ -390,6 +399,7 @@ func NewKubectlCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 				krollout.NewCmdRollout(f, ioStreams),
 				kset.NewCmdSet(f, ioStreams),
 				migrate.NewCmdMigrate(f, ioStreams),
+				convert.NewCmdConvert(f, ioStreams),
 			},
 		},
 		{
@@ -470,8 +480,3 @@ func NewDefaultKubectlCommandWithArgs(args []string, in io.Reader, out, errout i
 
 	return cmd
 }
-
-func cmdWithShortOverwrite(cmd *cobra.Command, short string) *cobra.Command {
-	cmd.Short = i18n.T(short)
-	return cmd
-}
@@ -0,0 +1,291 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	convertLong = templates.LongDesc(i18n.T(`
+		Convert the update strategy of clonesets.
+
+		--update-strategy sets how the pods of a cloneset are updated: ReCreate deletes and creates
+		them again, InPlaceIfPossible updates them in-place if only their images or metadata change and
+		recreates them otherwise, and InPlaceOnly only updates them in-place.
+
+		When converting to an in-place strategy while an update is in progress, a warning lists the
+		changes of the update which cannot be made in-place, e.g. changed resource requests, since
+		the pods not updated yet are not updated in-place.`))
+
+	convertExample = templates.Examples(i18n.T(`
+		# Update the pods of cloneset web in-place when possible
+		kubectl-kruise convert cloneset/web --update-strategy=InPlaceIfPossible
+
+		# Print the cloneset web updated with recreated pods, without updating it
+		kubectl-kruise convert cloneset/web --update-strategy=ReCreate --dry-run=server -o yaml`))
+
+	updateStrategies = []kruiseappsv1alpha1.CloneSetUpdateStrategyType{
+		kruiseappsv1alpha1.RecreateCloneSetUpdateStrategyType,
+		kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+		kruiseappsv1alpha1.InPlaceOnlyCloneSetUpdateStrategyType,
+	}
+)
+
+// ConvertOptions is the command line options for 'convert'
+type ConvertOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	ToPrinter  func(string) (printers.ResourcePrinter, error)
+
+	Resources        []string
+	UpdateStrategy   string
+	Namespace        string
+	EnforceNamespace bool
+	DryRunStrategy   cmdutil.DryRunStrategy
+	Builder          func() *resource.Builder
+	// KubeClient reads the controller revisions of the clonesets
+	KubeClient kubernetes.Interface
+
+	resource.FilenameOptions
+	genericclioptions.IOStreams
+}
+
+// NewConvertOptions returns an initialized ConvertOptions instance
+func NewConvertOptions(streams genericclioptions.IOStreams) *ConvertOptions {
+	return &ConvertOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("converted").WithTypeSetter(internalapi.GetScheme()),
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdConvert returns a Command instance for the 'convert' command
+func NewCmdConvert(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewConvertOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "convert (TYPE NAME | TYPE/NAME) --update-strategy=STRATEGY",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Convert the update strategy of clonesets"),
+		Long:                  convertLong,
+		Example:               convertExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.UpdateStrategy, "update-strategy", o.UpdateStrategy, "The update strategy to convert to: ReCreate, InPlaceIfPossible or InPlaceOnly.")
+	usage := "identifying the resource to convert."
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
+	cmdutil.AddDryRunFlag(cmd)
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *ConvertOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.Resources = args
+
+	var err error
+	if o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd); err != nil {
+		return err
+	}
+	if o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+
+	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
+		o.PrintFlags.NamePrintFlags.Operation = operation
+		cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+		return o.PrintFlags.ToPrinter()
+	}
+
+	if o.KubeClient, err = f.KubernetesClientSet(); err != nil {
+		return err
+	}
+	o.Builder = f.NewBuilder
+	return nil
+}
+
+// Validate makes sure that the options are valid
+func (o *ConvertOptions) Validate() error {
+	if len(o.Resources) == 0 && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
+		return fmt.Errorf("you must specify the clonesets to convert, e.g. kubectl-kruise convert cloneset/web --update-strategy=InPlaceIfPossible")
+	}
+	for _, strategy := range updateStrategies {
+		if o.UpdateStrategy == string(strategy) {
+			return nil
+		}
+	}
+	return fmt.Errorf("--update-strategy must be one of ReCreate, InPlaceIfPossible or InPlaceOnly, got %q", o.UpdateStrategy)
+}
+
+// Run converts the update strategy of the clonesets
+func (o *ConvertOptions) Run() error {
+	r := o.Builder().
+		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
+		ResourceTypeOrNameArgs(true, o.Resources...).
+		ContinueOnError().
+		Latest().
+		Flatten().
+		Do()
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	var allErrs []error
+	err := r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := o.convert(info); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		return nil
+	})
+	if err != nil {
+		allErrs = append(allErrs, err)
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// convert patches the update strategy of the cloneset of info, after warning about the changes of its update in
+// progress which cannot be made in-place.
+func (o *ConvertOptions) convert(info *resource.Info) error {
+	cs, ok := info.Object.(*kruiseappsv1alpha1.CloneSet)
+	if !ok {
+		return fmt.Errorf("only clonesets can be converted, got %s/%s", info.Mapping.Resource.GroupResource(), info.Name)
+	}
+	strategy := kruiseappsv1alpha1.CloneSetUpdateStrategyType(o.UpdateStrategy)
+
+	current := cs.Spec.UpdateStrategy.Type
+	if len(current) == 0 {
+		current = kruiseappsv1alpha1.RecreateCloneSetUpdateStrategyType
+	}
+	if current == strategy {
+		printer, err := o.ToPrinter("unchanged")
+		if err != nil {
+			return err
+		}
+		return printer.PrintObj(info.Object, o.Out)
+	}
+
+	if strategy != kruiseappsv1alpha1.RecreateCloneSetUpdateStrategyType {
+		changes, err := o.inPlaceBlockingChanges(cs)
+		if err != nil {
+			return err
+		}
+		if len(changes) > 0 {
+			fmt.Fprintf(o.ErrOut, "Warning: the update in progress of %s/%s changes %s, which cannot be updated in-place, so the pods not updated yet are not updated in-place\n",
+				info.Mapping.Resource.GroupResource(), info.Name, strings.Join(changes, ", "))
+		}
+	}
+
+	obj := info.Object
+	if o.DryRunStrategy == cmdutil.DryRunClient {
+		cs.Spec.UpdateStrategy.Type = strategy
+	} else {
+		patch := fmt.Sprintf(`{"spec":{"updateStrategy":{"type":%q}}}`, strategy)
+		var err error
+		obj, err = resource.NewHelper(info.Client, info.Mapping).
+			DryRun(o.DryRunStrategy == cmdutil.DryRunServer).
+			Patch(info.Namespace, info.Name, types.MergePatchType, []byte(patch), nil)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s/%s: %v", info.Mapping.Resource.GroupResource(), info.Name, err)
+		}
+	}
+
+	printer, err := o.ToPrinter("converted")
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(obj, o.Out)
+}
+
+// inPlaceBlockingChanges returns the changes from the template of the current revision of the cloneset to its
+// template which cannot be made in-place. Only the images and the metadata of the pods can be updated in-place.
+func (o *ConvertOptions) inPlaceBlockingChanges(cs *kruiseappsv1alpha1.CloneSet) ([]string, error) {
+	if len(cs.Status.CurrentRevision) == 0 || cs.Status.CurrentRevision == cs.Status.UpdateRevision {
+		return nil, nil
+	}
+	revision, err := o.KubeClient.AppsV1().ControllerRevisions(cs.Namespace).Get(context.TODO(), cs.Status.CurrentRevision, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the current revision of cloneset %s: %v", cs.Name, err)
+	}
+	// the revisions of a cloneset record its pod template only
+	var patch struct {
+		Spec struct {
+			Template corev1.PodTemplateSpec `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(revision.Data.Raw, &patch); err != nil {
+		return nil, fmt.Errorf("failed to decode revision %s of cloneset %s: %v", revision.Name, cs.Name, err)
+	}
+	return podSpecBlockingChanges(&patch.Spec.Template.Spec, &cs.Spec.Template.Spec), nil
+}
+
+func podSpecBlockingChanges(current, updated *corev1.PodSpec) []string {
+	currentContainers := map[string]corev1.Container{}
+	for _, c := range current.Containers {
+		currentContainers[c.Name] = c
+	}
+
+	var changes []string
+	for _, c := range updated.Containers {
+		old, ok := currentContainers[c.Name]
+		if !ok || len(current.Containers) != len(updated.Containers) {
+			return []string{"the containers"}
+		}
+		if !apiequality.Semantic.DeepEqual(old.Resources, c.Resources) {
+			changes = append(changes, fmt.Sprintf("the resources of container %s", c.Name))
+		}
+		old.Image, old.Resources = c.Image, c.Resources
+		if !apiequality.Semantic.DeepEqual(old, c) {
+			changes = append(changes, fmt.Sprintf("container %s", c.Name))
+		}
+	}
+
+	rest, updatedRest := current.DeepCopy(), updated.DeepCopy()
+	rest.Containers, updatedRest.Containers = nil, nil
+	if !apiequality.Semantic.DeepEqual(rest, updatedRest) {
+		changes = append(changes, "the pod spec")
+	}
+	return changes
+}
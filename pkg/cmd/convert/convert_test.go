@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+	restfake "k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func newConvertTestTemplate(image, cpu string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "nginx",
+				Image: image,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+				},
+			}},
+		},
+	}
+}
+
+func newConvertTestRevision(template corev1.PodTemplateSpec) *appsv1.ControllerRevision {
+	raw, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": template}})
+	return &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "test"},
+		Data:       runtime.RawExtension{Raw: raw},
+		Revision:   1,
+	}
+}
+
+func TestRunConvert(t *testing.T) {
+	testCases := []struct {
+		name         string
+		strategy     kruiseappsv1alpha1.CloneSetUpdateStrategyType
+		template     corev1.PodTemplateSpec
+		updating     bool
+		expectOut    string
+		expectErrOut string
+		expectPatch  string
+	}{
+		{
+			name:        "clean conversion",
+			template:    newConvertTestTemplate("nginx:1.25", "200m"),
+			expectOut:   "cloneset.apps.kruise.io/web converted\n",
+			expectPatch: `{"spec":{"updateStrategy":{"type":"InPlaceIfPossible"}}}`,
+		},
+		{
+			name:        "update of the images in progress",
+			template:    newConvertTestTemplate("nginx:1.25", "100m"),
+			updating:    true,
+			expectOut:   "cloneset.apps.kruise.io/web converted\n",
+			expectPatch: `{"spec":{"updateStrategy":{"type":"InPlaceIfPossible"}}}`,
+		},
+		{
+			name:         "update of the resources in progress",
+			template:     newConvertTestTemplate("nginx:1.25", "200m"),
+			updating:     true,
+			expectOut:    "cloneset.apps.kruise.io/web converted\n",
+			expectErrOut: "Warning: the update in progress of clonesets.apps.kruise.io/web changes the resources of container nginx, which cannot be updated in-place, so the pods not updated yet are not updated in-place\n",
+			expectPatch:  `{"spec":{"updateStrategy":{"type":"InPlaceIfPossible"}}}`,
+		},
+		{
+			name:      "already converted",
+			strategy:  kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+			template:  newConvertTestTemplate("nginx:1.25", "200m"),
+			expectOut: "cloneset.apps.kruise.io/web unchanged\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &kruiseappsv1alpha1.CloneSet{
+				TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+				Spec: kruiseappsv1alpha1.CloneSetSpec{
+					Template:       tc.template,
+					UpdateStrategy: kruiseappsv1alpha1.CloneSetUpdateStrategy{Type: tc.strategy},
+				},
+				Status: kruiseappsv1alpha1.CloneSetStatus{CurrentRevision: "web-1", UpdateRevision: "web-1"},
+			}
+			if tc.updating {
+				cs.Status.UpdateRevision = "web-2"
+			}
+
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			var patch string
+			codec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+			tf.Client = &restfake.RESTClient{
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					switch p, m := req.URL.Path, req.Method; {
+					case p == "/namespaces/test/clonesets/web" && m == http.MethodGet:
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, cs)}, nil
+					case p == "/namespaces/test/clonesets/web" && m == http.MethodPatch:
+						body, err := io.ReadAll(req.Body)
+						if err != nil {
+							return nil, err
+						}
+						patch = string(body)
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, cs)}, nil
+					}
+					t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+					return nil, nil
+				}),
+			}
+
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdConvert(tf, streams)
+			o := NewConvertOptions(streams)
+			o.UpdateStrategy = string(kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType)
+			assert.NoError(t, o.Complete(tf, cmd, []string{"cloneset/web"}))
+			assert.NoError(t, o.Validate())
+			o.KubeClient = fake.NewSimpleClientset(newConvertTestRevision(newConvertTestTemplate("nginx:1.24", "100m")))
+
+			assert.NoError(t, o.Run())
+			assert.Equal(t, tc.expectOut, out.String())
+			assert.Equal(t, tc.expectErrOut, errOut.String())
+			if len(tc.expectPatch) > 0 {
+				assert.JSONEq(t, tc.expectPatch, patch)
+			} else {
+				assert.Empty(t, patch)
+			}
+		})
+	}
+}
+
+func TestConvertValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		options   *ConvertOptions
+		expectErr string
+	}{
+		{
+			name:      "missing resources",
+			options:   &ConvertOptions{UpdateStrategy: "InPlaceIfPossible"},
+			expectErr: "you must specify the clonesets to convert, e.g. kubectl-kruise convert cloneset/web --update-strategy=InPlaceIfPossible",
+		},
+		{
+			name:      "missing update strategy",
+			options:   &ConvertOptions{Resources: []string{"cloneset/web"}},
+			expectErr: `--update-strategy must be one of ReCreate, InPlaceIfPossible or InPlaceOnly, got ""`,
+		},
+		{
+			name:      "unknown update strategy",
+			options:   &ConvertOptions{Resources: []string{"cloneset/web"}, UpdateStrategy: "RollingUpdate"},
+			expectErr: `--update-strategy must be one of ReCreate, InPlaceIfPossible or InPlaceOnly, got "RollingUpdate"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.options.Validate(), tc.expectErr)
+		})
+	}
+}
@@ -141,7 +141,13 @@ func NewCmdCreate(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cob
 	// create subcommands
 	cmd.AddCommand(NewCmdCreateJob(f, ioStreams))
 	cmd.AddCommand(NewCmdCreateBroadcastJob(f, ioStreams))
+	cmd.AddCommand(NewCmdCreateAdvancedCronJob(f, ioStreams))
 	cmd.AddCommand(NewCmdCreateCRR(f, ioStreams))
+	cmd.AddCommand(NewCmdCreateCloneSet(f, ioStreams))
+	cmd.AddCommand(NewCmdCreateImagePullJob(f, ioStreams))
+	cmd.AddCommand(NewCmdCreatePodProbeMarker(f, ioStreams))
+	cmd.AddCommand(NewCmdCreateResourceDistribution(f, ioStreams))
+	cmd.AddCommand(NewCmdCreateSidecarSet(f, ioStreams))
 	return cmd
 }
 
@@ -446,3 +452,45 @@ func (o *CreateSubcommandOptions) Run() error {
 
 	return o.PrintObj(obj, o.Out)
 }
+
+// ServerSideApplyOptions holds the options submitting the object generated by a create subcommand by server-side
+// apply instead of creating it, so that an object which already exists is updated rather than rejected.
+type ServerSideApplyOptions struct {
+	ServerSide     bool
+	ForceConflicts bool
+}
+
+// AddFlags adds the --server-side and --force-conflicts flags to cmd.
+func (o *ServerSideApplyOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.ServerSide, "server-side", o.ServerSide, "If true, the object is submitted by server-side apply with the field manager instead of being created, and updated if it already exists.")
+	cmd.Flags().BoolVar(&o.ForceConflicts, "force-conflicts", o.ForceConflicts, "If true, server-side apply takes over the fields owned by other field managers. Only works with --server-side.")
+}
+
+// Validate makes sure that the server-side apply options are valid
+func (o *ServerSideApplyOptions) Validate() error {
+	if o.ForceConflicts && !o.ServerSide {
+		return fmt.Errorf("--force-conflicts only works with --server-side")
+	}
+	return nil
+}
+
+// CompletePrintFlags sets the operation printFlags print the applied object with.
+func (o *ServerSideApplyOptions) CompletePrintFlags(printFlags *genericclioptions.PrintFlags) {
+	if o.ServerSide {
+		printFlags.NamePrintFlags.Operation = "serverside-applied"
+	}
+}
+
+// ApplyPatch returns the server-side apply patch of obj, which must have its apiVersion and kind set, and the
+// options applying it as fieldManager.
+func (o *ServerSideApplyOptions) ApplyPatch(obj kruntime.Object, fieldManager string, dryRunStrategy cmdutil.DryRunStrategy) ([]byte, metav1.PatchOptions, error) {
+	data, err := kruntime.Encode(scheme.DefaultJSONEncoder(), obj)
+	if err != nil {
+		return nil, metav1.PatchOptions{}, err
+	}
+	patchOptions := metav1.PatchOptions{FieldManager: fieldManager, Force: &o.ForceConflicts}
+	if dryRunStrategy == cmdutil.DryRunServer {
+		patchOptions.DryRun = []string{metav1.DryRunAll}
+	}
+	return data, patchOptions, nil
+}
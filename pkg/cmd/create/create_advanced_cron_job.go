@@ -0,0 +1,287 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"fmt"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// AdvancedCronJobTemplateJob makes an AdvancedCronJob create Jobs
+	AdvancedCronJobTemplateJob = "job"
+	// AdvancedCronJobTemplateBroadcastJob makes an AdvancedCronJob create BroadcastJobs
+	AdvancedCronJobTemplateBroadcastJob = "broadcastjob"
+)
+
+var (
+	advancedCronJobLong = templates.LongDesc(i18n.T(`
+		Create an advancedCronJob with the specified name, which creates a Job or a BroadcastJob on a schedule.`))
+
+	advancedCronJobExample = templates.Examples(i18n.T(`
+		# Create an advancedCronJob creating a Job every 5 minutes
+		kubectl kruise create advancedcronjob my-acj --image=busybox --schedule="*/5 * * * *"
+
+		# Create an advancedCronJob with command
+		kubectl kruise create advancedcronjob my-acj --image=busybox --schedule="*/5 * * * *" -- date
+
+		# Create an advancedCronJob running a BroadcastJob on every node each night, skipping a run while the previous one is running
+		kubectl kruise create advancedcronjob clean-logs --image=busybox --schedule=@daily --job-template=broadcastjob --concurrency-policy=Forbid -- sh -c "rm -rf /var/log/app/*.gz"`))
+)
+
+// CreateAdvancedCronJobOptions is the command line options for 'create advancedcronjob'
+type CreateAdvancedCronJobOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+
+	PrintObj func(obj runtime.Object) error
+
+	Name              string
+	Image             string
+	Schedule          string
+	JobTemplate       string
+	ConcurrencyPolicy string
+	Command           []string
+
+	Namespace            string
+	EnforceNamespace     bool
+	kruisev1alpha1Client kruiseclientsets.Interface
+	DryRunStrategy       cmdutil.DryRunStrategy
+	FieldManager         string
+	CreateAnnotation     bool
+	ServerSideApplyOptions
+
+	genericclioptions.IOStreams
+}
+
+// NewCreateAdvancedCronJobOptions initializes and returns new CreateAdvancedCronJobOptions instance
+func NewCreateAdvancedCronJobOptions(ioStreams genericclioptions.IOStreams) *CreateAdvancedCronJobOptions {
+	return &CreateAdvancedCronJobOptions{
+		PrintFlags:  genericclioptions.NewPrintFlags("created").WithTypeSetter(internalapi.GetScheme()),
+		JobTemplate: AdvancedCronJobTemplateJob,
+		IOStreams:   ioStreams,
+	}
+}
+
+// NewCmdCreateAdvancedCronJob is a command to ease creating AdvancedCronJobs.
+func NewCmdCreateAdvancedCronJob(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCreateAdvancedCronJobOptions(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "advancedcronjob NAME --image=image --schedule='*/5 * * * *' [--job-template=job|broadcastjob] [--concurrency-policy=Allow|Forbid|Replace] -- [COMMAND] [args...]",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"advancedCronJob", "acj"},
+		Short:                 advancedCronJobLong,
+		Long:                  advancedCronJobLong,
+		Example:               advancedCronJobExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+
+	cmdutil.AddApplyAnnotationFlags(cmd)
+	cmdutil.AddValidateFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().StringVar(&o.Image, "image", o.Image, "Image name to run.")
+	cmd.Flags().StringVar(&o.Schedule, "schedule", o.Schedule, "A schedule in the Cron format the job should be run with, e.g. \"*/5 * * * *\" or @daily.")
+	cmd.Flags().StringVar(&o.JobTemplate, "job-template", o.JobTemplate, "The kind of the jobs created on the schedule, one of job or broadcastjob.")
+	cmd.Flags().StringVar(&o.ConcurrencyPolicy, "concurrency-policy", o.ConcurrencyPolicy, "How to treat a run while the previous one is still running, one of Allow, Forbid or Replace. Defaults to Allow.")
+	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl kruise-create")
+	o.ServerSideApplyOptions.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *CreateAdvancedCronJobOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	name, err := NameFromCommandArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	o.Name = name
+	if len(args) > 1 {
+		o.Command = args[1:]
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.kruisev1alpha1Client, err = kruiseclientsets.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+
+	o.CreateAnnotation = cmdutil.GetFlagBool(cmd, cmdutil.ApplyAnnotationsFlag)
+
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	o.ServerSideApplyOptions.CompletePrintFlags(o.PrintFlags)
+	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	o.PrintObj = func(obj runtime.Object) error {
+		return printer.PrintObj(obj, o.Out)
+	}
+
+	return nil
+}
+
+// Validate makes sure provided values and valid AdvancedCronJob options
+func (o *CreateAdvancedCronJobOptions) Validate() error {
+	if err := o.ServerSideApplyOptions.Validate(); err != nil {
+		return err
+	}
+	if len(o.Image) == 0 {
+		return fmt.Errorf("--image must be specified")
+	}
+	if len(o.Schedule) == 0 {
+		return fmt.Errorf("--schedule must be specified")
+	}
+	// the controller of AdvancedCronJobs parses the schedule with the standard parser too
+	if _, err := cron.ParseStandard(o.Schedule); err != nil {
+		return fmt.Errorf("invalid --schedule %q: %v", o.Schedule, err)
+	}
+	if o.JobTemplate != AdvancedCronJobTemplateJob && o.JobTemplate != AdvancedCronJobTemplateBroadcastJob {
+		return fmt.Errorf("--job-template must be one of %s or %s, got %q", AdvancedCronJobTemplateJob, AdvancedCronJobTemplateBroadcastJob, o.JobTemplate)
+	}
+	switch kruiseappsv1alpha1.ConcurrencyPolicy(o.ConcurrencyPolicy) {
+	case "", kruiseappsv1alpha1.AllowConcurrent, kruiseappsv1alpha1.ForbidConcurrent, kruiseappsv1alpha1.ReplaceConcurrent:
+	default:
+		return fmt.Errorf("--concurrency-policy must be one of %s, %s or %s, got %q", kruiseappsv1alpha1.AllowConcurrent,
+			kruiseappsv1alpha1.ForbidConcurrent, kruiseappsv1alpha1.ReplaceConcurrent, o.ConcurrencyPolicy)
+	}
+	return nil
+}
+
+// Run performs the execution of 'create advancedcronjob' sub command
+func (o *CreateAdvancedCronJobOptions) Run() error {
+	cronJob := o.createAdvancedCronJob()
+
+	if err := util.CreateOrUpdateAnnotation(o.CreateAnnotation, cronJob, scheme.DefaultJSONEncoder()); err != nil {
+		return err
+	}
+
+	if o.DryRunStrategy != cmdutil.DryRunClient {
+		if o.ServerSide {
+			data, patchOptions, err := o.ServerSideApplyOptions.ApplyPatch(cronJob, o.FieldManager, o.DryRunStrategy)
+			if err != nil {
+				return err
+			}
+			cronJob, err = o.kruisev1alpha1Client.AppsV1alpha1().AdvancedCronJobs(o.Namespace).Patch(context.TODO(), cronJob.Name, types.ApplyPatchType, data, patchOptions)
+			if err != nil {
+				return fmt.Errorf("failed to apply advancedcronjob: %v", err)
+			}
+		} else {
+			createOptions := metav1.CreateOptions{}
+			if o.FieldManager != "" {
+				createOptions.FieldManager = o.FieldManager
+			}
+			if o.DryRunStrategy == cmdutil.DryRunServer {
+				createOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			var err error
+			cronJob, err = o.kruisev1alpha1Client.AppsV1alpha1().AdvancedCronJobs(o.Namespace).Create(context.TODO(), cronJob, createOptions)
+			if err != nil {
+				return fmt.Errorf("failed to create advancedcronjob: %v", err)
+			}
+		}
+	}
+
+	return o.PrintObj(cronJob)
+}
+
+func (o *CreateAdvancedCronJobOptions) createAdvancedCronJob() *kruiseappsv1alpha1.AdvancedCronJob {
+	containers := []corev1.Container{
+		{
+			Name:    o.Name,
+			Image:   o.Image,
+			Command: o.Command,
+		},
+	}
+
+	cronJob := &kruiseappsv1alpha1.AdvancedCronJob{
+		// this is ok because we know exactly how we want to be serialized
+		TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: kruiseappsv1alpha1.AdvancedCronJobKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: o.Name,
+		},
+		Spec: kruiseappsv1alpha1.AdvancedCronJobSpec{
+			Schedule:          o.Schedule,
+			ConcurrencyPolicy: kruiseappsv1alpha1.ConcurrencyPolicy(o.ConcurrencyPolicy),
+		},
+	}
+	// a Job is retried in the same pod, while a BroadcastJob runs a single pod per node which must not restart
+	switch o.JobTemplate {
+	case AdvancedCronJobTemplateBroadcastJob:
+		cronJob.Spec.Template.BroadcastJobTemplate = &kruiseappsv1alpha1.BroadcastJobTemplateSpec{
+			Spec: kruiseappsv1alpha1.BroadcastJobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers:    containers,
+						RestartPolicy: corev1.RestartPolicyNever,
+					},
+				},
+				CompletionPolicy: kruiseappsv1alpha1.CompletionPolicy{
+					Type: kruiseappsv1alpha1.Always,
+				},
+			},
+		}
+	default:
+		cronJob.Spec.Template.JobTemplate = &batchv1.JobTemplateSpec{
+			Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers:    containers,
+						RestartPolicy: corev1.RestartPolicyOnFailure,
+					},
+				},
+			},
+		}
+	}
+	if o.EnforceNamespace {
+		cronJob.Namespace = o.Namespace
+	}
+	return cronJob
+}
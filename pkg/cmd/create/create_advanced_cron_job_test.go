@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestCreateAdvancedCronJobGenerate(t *testing.T) {
+	o := &CreateAdvancedCronJobOptions{
+		Name:              "my-acj",
+		Image:             "busybox",
+		Schedule:          "*/5 * * * *",
+		JobTemplate:       AdvancedCronJobTemplateJob,
+		ConcurrencyPolicy: "Forbid",
+		Command:           []string{"date"},
+	}
+	assert.NoError(t, o.Validate())
+
+	cronJob := o.createAdvancedCronJob()
+	assert.Equal(t, kruiseappsv1alpha1.AdvancedCronJobKind, cronJob.Kind)
+	assert.Equal(t, "*/5 * * * *", cronJob.Spec.Schedule)
+	assert.Equal(t, kruiseappsv1alpha1.ForbidConcurrent, cronJob.Spec.ConcurrencyPolicy)
+	assert.Nil(t, cronJob.Spec.Template.BroadcastJobTemplate)
+	if assert.NotNil(t, cronJob.Spec.Template.JobTemplate) {
+		podSpec := cronJob.Spec.Template.JobTemplate.Spec.Template.Spec
+		assert.Equal(t, corev1.RestartPolicyOnFailure, podSpec.RestartPolicy)
+		assert.Equal(t, []corev1.Container{{Name: "my-acj", Image: "busybox", Command: []string{"date"}}}, podSpec.Containers)
+	}
+
+	o.JobTemplate = AdvancedCronJobTemplateBroadcastJob
+	o.ConcurrencyPolicy = ""
+	assert.NoError(t, o.Validate())
+
+	cronJob = o.createAdvancedCronJob()
+	assert.Empty(t, cronJob.Spec.ConcurrencyPolicy)
+	assert.Nil(t, cronJob.Spec.Template.JobTemplate)
+	if assert.NotNil(t, cronJob.Spec.Template.BroadcastJobTemplate) {
+		spec := cronJob.Spec.Template.BroadcastJobTemplate.Spec
+		assert.Equal(t, corev1.RestartPolicyNever, spec.Template.Spec.RestartPolicy)
+		assert.Equal(t, []corev1.Container{{Name: "my-acj", Image: "busybox", Command: []string{"date"}}}, spec.Template.Spec.Containers)
+		assert.Equal(t, kruiseappsv1alpha1.CompletionPolicy{Type: kruiseappsv1alpha1.Always}, spec.CompletionPolicy)
+	}
+}
+
+func TestCreateAdvancedCronJobValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		options   *CreateAdvancedCronJobOptions
+		expectErr string
+	}{
+		{
+			name:      "missing image",
+			options:   &CreateAdvancedCronJobOptions{Schedule: "@daily", JobTemplate: AdvancedCronJobTemplateJob},
+			expectErr: "--image must be specified",
+		},
+		{
+			name:      "missing schedule",
+			options:   &CreateAdvancedCronJobOptions{Image: "busybox", JobTemplate: AdvancedCronJobTemplateJob},
+			expectErr: "--schedule must be specified",
+		},
+		{
+			name:      "too few fields",
+			options:   &CreateAdvancedCronJobOptions{Image: "busybox", Schedule: "*/5 * * *", JobTemplate: AdvancedCronJobTemplateJob},
+			expectErr: `invalid --schedule "*/5 * * *": expected exactly 5 fields, found 4: [*/5 * * *]`,
+		},
+		{
+			name:      "minute out of range",
+			options:   &CreateAdvancedCronJobOptions{Image: "busybox", Schedule: "60 * * * *", JobTemplate: AdvancedCronJobTemplateJob},
+			expectErr: `invalid --schedule "60 * * * *": end of range (60) above maximum (59): 60`,
+		},
+		{
+			name:      "reversed range",
+			options:   &CreateAdvancedCronJobOptions{Image: "busybox", Schedule: "0 9 * * fri-mon", JobTemplate: AdvancedCronJobTemplateJob},
+			expectErr: `invalid --schedule "0 9 * * fri-mon": beginning of range (5) beyond end of range (1): fri-mon`,
+		},
+		{
+			name:      "invalid step",
+			options:   &CreateAdvancedCronJobOptions{Image: "busybox", Schedule: "*/0 * * * *", JobTemplate: AdvancedCronJobTemplateJob},
+			expectErr: `invalid --schedule "*/0 * * * *": step of range should be a positive number: */0`,
+		},
+		{
+			name:      "unknown descriptor",
+			options:   &CreateAdvancedCronJobOptions{Image: "busybox", Schedule: "@fortnightly", JobTemplate: AdvancedCronJobTemplateJob},
+			expectErr: `invalid --schedule "@fortnightly": unrecognized descriptor: @fortnightly`,
+		},
+		{
+			name:      "unknown template",
+			options:   &CreateAdvancedCronJobOptions{Image: "busybox", Schedule: "@hourly", JobTemplate: "cronjob"},
+			expectErr: `--job-template must be one of job or broadcastjob, got "cronjob"`,
+		},
+		{
+			name:      "unknown concurrency policy",
+			options:   &CreateAdvancedCronJobOptions{Image: "busybox", Schedule: "@hourly", JobTemplate: AdvancedCronJobTemplateJob, ConcurrencyPolicy: "Queue"},
+			expectErr: `--concurrency-policy must be one of Allow, Forbid or Replace, got "Queue"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.options.Validate(), tc.expectErr)
+		})
+	}
+}
+
+func TestCreateAdvancedCronJobValidateSchedule(t *testing.T) {
+	for _, schedule := range []string{
+		"*/5 * * * *",
+		"0 0 1 1 *",
+		"30 9-17/2 * * MON-FRI",
+		"0,15,30,45 * ? jan,jul 0",
+		"@midnight",
+		"@every 1h30m",
+		"CRON_TZ=Asia/Shanghai 0 9 * * *",
+		"TZ=UTC @daily",
+	} {
+		o := &CreateAdvancedCronJobOptions{Image: "busybox", Schedule: schedule, JobTemplate: AdvancedCronJobTemplateJob}
+		assert.NoError(t, o.Validate(), schedule)
+	}
+}
+
+func TestRunCreateAdvancedCronJob(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdCreateAdvancedCronJob(tf, streams)
+	o := NewCreateAdvancedCronJobOptions(streams)
+	o.Image = "busybox"
+	o.Schedule = "@daily"
+	o.JobTemplate = AdvancedCronJobTemplateBroadcastJob
+	assert.NoError(t, o.Complete(tf, cmd, []string{"clean-logs"}))
+	assert.NoError(t, o.Validate())
+	client := kruisefake.NewSimpleClientset()
+	o.kruisev1alpha1Client = client
+
+	assert.NoError(t, o.Run())
+	assert.Equal(t, "advancedcronjob.apps.kruise.io/clean-logs created\n", out.String())
+
+	cronJob, err := client.AppsV1alpha1().AdvancedCronJobs("test").Get(context.TODO(), "clean-logs", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "@daily", cronJob.Spec.Schedule)
+	assert.NotNil(t, cronJob.Spec.Template.BroadcastJobTemplate)
+}
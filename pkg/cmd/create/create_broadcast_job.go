@@ -25,11 +25,11 @@ import (
 	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
 	"github.com/spf13/cobra"
 
-	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -37,6 +37,7 @@ import (
 	"k8s.io/kubectl/pkg/util"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
+	utilpointer "k8s.io/utils/pointer"
 )
 
 var (
@@ -50,6 +51,12 @@ var (
 		# Create a broadcastJob with command
 		kubectl kruise create broadcastJob my-bcj --image=busybox -- date
 
+		# Create a broadcastJob that runs on at most 2 nodes at a time and is deleted 10 minutes after it finishes
+		kubectl kruise create broadcastJob my-bcj --image=busybox --parallelism=2 --ttl=600 -- date
+
+		# Create a broadcastJob that keeps running on new nodes as they join the cluster
+		kubectl kruise create broadcastJob my-bcj --image=busybox --completion-policy=Never -- sleep 3600
+
 		# Create a broadcastJob from a AdvancedCronJob named "a-advancedCronjob"
 		kubectl kruise create broadcastJob test-bcj --from=acj/a-advancedCronjob`))
 )
@@ -60,10 +67,13 @@ type CreateBroadcastJobOptions struct {
 
 	PrintObj func(obj runtime.Object) error
 
-	Name    string
-	Image   string
-	From    string
-	Command []string
+	Name             string
+	Image            string
+	From             string
+	Command          []string
+	Parallelism      string
+	CompletionPolicy string
+	TTL              int32
 
 	Namespace            string
 	EnforceNamespace     bool
@@ -72,6 +82,7 @@ type CreateBroadcastJobOptions struct {
 	Builder              *resource.Builder
 	FieldManager         string
 	CreateAnnotation     bool
+	ServerSideApplyOptions
 
 	genericclioptions.IOStreams
 }
@@ -80,6 +91,7 @@ type CreateBroadcastJobOptions struct {
 func NewCreateBroadcastJobOptions(ioStreams genericclioptions.IOStreams) *CreateBroadcastJobOptions {
 	return &CreateBroadcastJobOptions{
 		PrintFlags: genericclioptions.NewPrintFlags("created").WithTypeSetter(scheme.Scheme),
+		TTL:        -1,
 		IOStreams:  ioStreams,
 	}
 }
@@ -88,8 +100,9 @@ func NewCreateBroadcastJobOptions(ioStreams genericclioptions.IOStreams) *Create
 func NewCmdCreateBroadcastJob(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	o := NewCreateBroadcastJobOptions(ioStreams)
 	cmd := &cobra.Command{
-		Use:                   "broadcastJob NAME --image=image [--from=cronjob/name] -- [COMMAND] [args...]",
+		Use:                   "broadcastJob NAME --image=image [--from=cronjob/name] [--parallelism=n] [--completion-policy=Always|Never] [--ttl=seconds] -- [COMMAND] [args...]",
 		DisableFlagsInUseLine: true,
+		Aliases:               []string{"broadcastjob", "bcj"},
 		Short:                 broadcastJobLong,
 		Long:                  broadcastJobLong,
 		Example:               broadcastJobExample,
@@ -107,7 +120,11 @@ func NewCmdCreateBroadcastJob(f cmdutil.Factory, ioStreams genericclioptions.IOS
 	cmdutil.AddDryRunFlag(cmd)
 	cmd.Flags().StringVar(&o.Image, "image", o.Image, "Image name to run.")
 	cmd.Flags().StringVar(&o.From, "from", o.From, "The name of the resource to create a BroadcastJob from (only advancedCronjob is supported).")
+	cmd.Flags().StringVar(&o.Parallelism, "parallelism", o.Parallelism, "The maximum number or percentage of nodes to run pods on at the same time, e.g. 2 or 50%. Not setting it means no limit.")
+	cmd.Flags().StringVar(&o.CompletionPolicy, "completion-policy", o.CompletionPolicy, "The completion policy of the BroadcastJob, one of Always or Never. Defaults to Always.")
+	cmd.Flags().Int32Var(&o.TTL, "ttl", o.TTL, "The number of seconds after the BroadcastJob finishes before it is deleted. Only works with the Always completion policy.")
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl kruise-create")
+	o.ServerSideApplyOptions.AddFlags(cmd)
 	return cmd
 }
 
@@ -143,6 +160,7 @@ func (o *CreateBroadcastJobOptions) Complete(f cmdutil.Factory, cmd *cobra.Comma
 	if err != nil {
 		return err
 	}
+	o.ServerSideApplyOptions.CompletePrintFlags(o.PrintFlags)
 	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
 	printer, err := o.PrintFlags.ToPrinter()
 	if err != nil {
@@ -157,12 +175,32 @@ func (o *CreateBroadcastJobOptions) Complete(f cmdutil.Factory, cmd *cobra.Comma
 
 // Validate makes sure provided values and valid BroadcastJob options
 func (o *CreateBroadcastJobOptions) Validate() error {
+	if err := o.ServerSideApplyOptions.Validate(); err != nil {
+		return err
+	}
 	if (len(o.Image) == 0 && len(o.From) == 0) || (len(o.Image) != 0 && len(o.From) != 0) {
 		return fmt.Errorf("either --image or --from must be specified")
 	}
 	if o.Command != nil && len(o.Command) != 0 && len(o.From) != 0 {
 		return fmt.Errorf("cannot specify --from and command")
 	}
+	if len(o.From) != 0 && (len(o.Parallelism) != 0 || len(o.CompletionPolicy) != 0 || o.TTL >= 0) {
+		return fmt.Errorf("cannot specify --from with --parallelism, --completion-policy or --ttl")
+	}
+	if len(o.Parallelism) != 0 {
+		if err := validateParallelism(o.Parallelism); err != nil {
+			return err
+		}
+	}
+	switch kruiseappsv1alpha1.CompletionPolicyType(o.CompletionPolicy) {
+	case "", kruiseappsv1alpha1.Always:
+	case kruiseappsv1alpha1.Never:
+		if o.TTL >= 0 {
+			return fmt.Errorf("--ttl only works with the %s completion policy", kruiseappsv1alpha1.Always)
+		}
+	default:
+		return fmt.Errorf("--completion-policy must be one of %s or %s, got %q", kruiseappsv1alpha1.Always, kruiseappsv1alpha1.Never, o.CompletionPolicy)
+	}
 	return nil
 }
 
@@ -203,17 +241,28 @@ func (o *CreateBroadcastJobOptions) Run() error {
 	}
 
 	if o.DryRunStrategy != cmdutil.DryRunClient {
-		createOptions := metav1.CreateOptions{}
-		if o.FieldManager != "" {
-			createOptions.FieldManager = o.FieldManager
-		}
-		if o.DryRunStrategy == cmdutil.DryRunServer {
-			createOptions.DryRun = []string{metav1.DryRunAll}
-		}
-		var err error
-		job, err = o.kruisev1alpha1Client.AppsV1alpha1().BroadcastJobs(o.Namespace).Create(context.TODO(), job, createOptions)
-		if err != nil {
-			return fmt.Errorf("failed to create job: %v", err)
+		if o.ServerSide {
+			data, patchOptions, err := o.ServerSideApplyOptions.ApplyPatch(job, o.FieldManager, o.DryRunStrategy)
+			if err != nil {
+				return err
+			}
+			job, err = o.kruisev1alpha1Client.AppsV1alpha1().BroadcastJobs(o.Namespace).Patch(context.TODO(), job.Name, types.ApplyPatchType, data, patchOptions)
+			if err != nil {
+				return fmt.Errorf("failed to apply job: %v", err)
+			}
+		} else {
+			createOptions := metav1.CreateOptions{}
+			if o.FieldManager != "" {
+				createOptions.FieldManager = o.FieldManager
+			}
+			if o.DryRunStrategy == cmdutil.DryRunServer {
+				createOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			var err error
+			job, err = o.kruisev1alpha1Client.AppsV1alpha1().BroadcastJobs(o.Namespace).Create(context.TODO(), job, createOptions)
+			if err != nil {
+				return fmt.Errorf("failed to create job: %v", err)
+			}
 		}
 	}
 
@@ -223,7 +272,7 @@ func (o *CreateBroadcastJobOptions) Run() error {
 func (o *CreateBroadcastJobOptions) createBroadcastJob() *kruiseappsv1alpha1.BroadcastJob {
 	job := &kruiseappsv1alpha1.BroadcastJob{
 		// this is ok because we know exactly how we want to be serialized
-		TypeMeta: metav1.TypeMeta{APIVersion: batchv1.SchemeGroupVersion.String(), Kind: kruiseappsv1alpha1.AdvancedCronJobKind},
+		TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "BroadcastJob"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name: o.Name,
 		},
@@ -240,8 +289,21 @@ func (o *CreateBroadcastJobOptions) createBroadcastJob() *kruiseappsv1alpha1.Bro
 					RestartPolicy: corev1.RestartPolicyNever,
 				},
 			},
+			CompletionPolicy: kruiseappsv1alpha1.CompletionPolicy{
+				Type: kruiseappsv1alpha1.Always,
+			},
 		},
 	}
+	if len(o.Parallelism) != 0 {
+		parallelism := intstr.Parse(o.Parallelism)
+		job.Spec.Parallelism = &parallelism
+	}
+	if len(o.CompletionPolicy) != 0 {
+		job.Spec.CompletionPolicy.Type = kruiseappsv1alpha1.CompletionPolicyType(o.CompletionPolicy)
+	}
+	if o.TTL >= 0 {
+		job.Spec.CompletionPolicy.TTLSecondsAfterFinished = utilpointer.Int32(o.TTL)
+	}
 	if o.EnforceNamespace {
 		job.Namespace = o.Namespace
 	}
@@ -267,7 +329,7 @@ func (o *CreateBroadcastJobOptions) createBroadcastJobFromBroadcastJobTemplate(o
 	}
 	broadcastJob = &kruiseappsv1alpha1.BroadcastJob{
 		// this is ok because we know exactly how we want to be serialized
-		TypeMeta: metav1.TypeMeta{APIVersion: batchv1.SchemeGroupVersion.String(), Kind: "Job"},
+		TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "BroadcastJob"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        o.Name,
 			Annotations: annotations,
@@ -289,3 +351,21 @@ func (o *CreateBroadcastJobOptions) createBroadcastJobFromBroadcastJobTemplate(o
 
 	return broadcastJob
 }
+
+// validateParallelism checks a --parallelism of a job running on nodes, which is either a positive number of nodes or
+// a percentage of the nodes between 1% and 100%. A percentage is rounded up, so 1% of any number of nodes is at least
+// one node, and 100% is all of them.
+func validateParallelism(value string) error {
+	parallelism := intstr.Parse(value)
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(&parallelism, 100, true)
+	if err != nil {
+		return fmt.Errorf("invalid --parallelism %q: %v", value, err)
+	}
+	if scaled <= 0 {
+		return fmt.Errorf("--parallelism must be greater than 0")
+	}
+	if parallelism.Type == intstr.String && scaled > 100 {
+		return fmt.Errorf("--parallelism must be a percentage between 1%% and 100%%, got %s", value)
+	}
+	return nil
+}
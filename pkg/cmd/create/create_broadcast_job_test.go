@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+func TestCreateBroadcastJobGenerate(t *testing.T) {
+	two, half := intstr.FromInt(2), intstr.FromString("50%")
+
+	testCases := []struct {
+		name                   string
+		command                []string
+		parallelism            string
+		completionPolicy       string
+		ttl                    int32
+		expectParallelism      *intstr.IntOrString
+		expectCompletionPolicy kruiseappsv1alpha1.CompletionPolicy
+	}{
+		{
+			name:                   "defaults",
+			ttl:                    -1,
+			expectCompletionPolicy: kruiseappsv1alpha1.CompletionPolicy{Type: kruiseappsv1alpha1.Always},
+		},
+		{
+			name:              "command parallelism and ttl",
+			command:           []string{"sh", "-c", "echo hello"},
+			parallelism:       "2",
+			ttl:               600,
+			expectParallelism: &two,
+			expectCompletionPolicy: kruiseappsv1alpha1.CompletionPolicy{
+				Type:                    kruiseappsv1alpha1.Always,
+				TTLSecondsAfterFinished: utilpointer.Int32(600),
+			},
+		},
+		{
+			name:                   "percent parallelism and never completes",
+			command:                []string{"sleep", "3600"},
+			parallelism:            "50%",
+			completionPolicy:       "Never",
+			ttl:                    -1,
+			expectParallelism:      &half,
+			expectCompletionPolicy: kruiseappsv1alpha1.CompletionPolicy{Type: kruiseappsv1alpha1.Never},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &CreateBroadcastJobOptions{
+				Name:             "my-bcj",
+				Image:            "busybox",
+				Command:          tc.command,
+				Parallelism:      tc.parallelism,
+				CompletionPolicy: tc.completionPolicy,
+				TTL:              tc.ttl,
+			}
+			assert.NoError(t, o.Validate())
+
+			job := o.createBroadcastJob()
+			assert.Equal(t, "BroadcastJob", job.Kind)
+			assert.Equal(t, kruiseappsv1alpha1.SchemeGroupVersion.String(), job.APIVersion)
+			assert.Len(t, job.Spec.Template.Spec.Containers, 1)
+			assert.Equal(t, "busybox", job.Spec.Template.Spec.Containers[0].Image)
+			assert.Equal(t, tc.command, job.Spec.Template.Spec.Containers[0].Command)
+			assert.Equal(t, tc.expectParallelism, job.Spec.Parallelism)
+			assert.Equal(t, tc.expectCompletionPolicy, job.Spec.CompletionPolicy)
+		})
+	}
+}
+
+func TestCreateBroadcastJobValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		options   *CreateBroadcastJobOptions
+		expectErr string
+	}{
+		{
+			name:      "missing image",
+			options:   &CreateBroadcastJobOptions{TTL: -1},
+			expectErr: "either --image or --from must be specified",
+		},
+		{
+			name:      "from with parallelism",
+			options:   &CreateBroadcastJobOptions{From: "acj/demo", Parallelism: "2", TTL: -1},
+			expectErr: "cannot specify --from with --parallelism, --completion-policy or --ttl",
+		},
+		{
+			name:      "zero parallelism",
+			options:   &CreateBroadcastJobOptions{Image: "busybox", Parallelism: "0", TTL: -1},
+			expectErr: "--parallelism must be greater than 0",
+		},
+		{
+			name:      "zero percent parallelism",
+			options:   &CreateBroadcastJobOptions{Image: "busybox", Parallelism: "0%", TTL: -1},
+			expectErr: "--parallelism must be greater than 0",
+		},
+		{
+			name:      "parallelism above 100 percent",
+			options:   &CreateBroadcastJobOptions{Image: "busybox", Parallelism: "150%", TTL: -1},
+			expectErr: "--parallelism must be a percentage between 1% and 100%, got 150%",
+		},
+		{
+			name:      "invalid parallelism",
+			options:   &CreateBroadcastJobOptions{Image: "busybox", Parallelism: "half", TTL: -1},
+			expectErr: `invalid --parallelism "half": invalid value for IntOrString: invalid type: string is not a percentage`,
+		},
+		{
+			name:      "unknown completion policy",
+			options:   &CreateBroadcastJobOptions{Image: "busybox", CompletionPolicy: "Sometimes", TTL: -1},
+			expectErr: `--completion-policy must be one of Always or Never, got "Sometimes"`,
+		},
+		{
+			name:      "ttl with never completion policy",
+			options:   &CreateBroadcastJobOptions{Image: "busybox", CompletionPolicy: "Never", TTL: 60},
+			expectErr: "--ttl only works with the Always completion policy",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.options.Validate(), tc.expectErr)
+		})
+	}
+}
+
+func TestRunCreateBroadcastJob(t *testing.T) {
+	testCases := []struct {
+		name         string
+		dryRun       string
+		output       string
+		expectCreate bool
+		expectOut    string
+	}{
+		{
+			name:         "create",
+			expectCreate: true,
+			expectOut:    "broadcastjob.apps.kruise.io/my-bcj created\n",
+		},
+		{
+			name:      "client dry-run yaml",
+			dryRun:    "client",
+			output:    "yaml",
+			expectOut: "parallelism: 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdCreateBroadcastJob(tf, streams)
+			args := []string{"my-bcj", "--", "date"}
+			if len(tc.dryRun) > 0 {
+				args = append([]string{"--dry-run=" + tc.dryRun}, args...)
+			}
+			assert.NoError(t, cmd.ParseFlags(args))
+
+			o := NewCreateBroadcastJobOptions(streams)
+			o.Image = "busybox"
+			o.Parallelism = "2"
+			o.PrintFlags.OutputFormat = &tc.output
+			assert.NoError(t, o.Complete(tf, cmd, cmd.Flags().Args()))
+			assert.NoError(t, o.Validate())
+			client := kruisefake.NewSimpleClientset()
+			o.kruisev1alpha1Client = client
+
+			assert.NoError(t, o.Run())
+			assert.Contains(t, out.String(), tc.expectOut)
+
+			job, err := client.AppsV1alpha1().BroadcastJobs("test").Get(context.TODO(), "my-bcj", metav1.GetOptions{})
+			assert.Equal(t, tc.expectCreate, err == nil)
+			if tc.expectCreate {
+				assert.Equal(t, []string{"date"}, job.Spec.Template.Spec.Containers[0].Command)
+			}
+		})
+	}
+}
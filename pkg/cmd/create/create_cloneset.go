@@ -0,0 +1,294 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/generate"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+var (
+	cloneSetLong = templates.LongDesc(i18n.T(`
+		Create a cloneset with the specified name.`))
+
+	cloneSetExample = templates.Examples(i18n.T(`
+		# Create a cloneset named my-app that runs the nginx image
+		kubectl kruise create cloneset my-app --image=nginx
+
+		# Create a cloneset with 3 replicas that exposes port 80
+		kubectl kruise create cloneset my-app --image=nginx --replicas=3 --port=80
+
+		# Create a cloneset that recreates pods on update, with custom labels
+		kubectl kruise create cloneset my-app --image=nginx --labels=app=web,tier=frontend --update-strategy=ReCreate
+
+		# Print the generated cloneset without creating it
+		kubectl kruise create cloneset my-app --image=nginx --dry-run=client -o yaml
+
+		# Create or update the cloneset by server-side apply, taking over conflicting fields
+		kubectl kruise create cloneset my-app --image=nginx:1.25 --server-side --force-conflicts`))
+)
+
+// CreateCloneSetOptions is the command line options for 'create cloneset'
+type CreateCloneSetOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+
+	PrintObj func(obj runtime.Object) error
+
+	Name           string
+	Image          string
+	Replicas       int32
+	Port           int32
+	Labels         string
+	UpdateStrategy string
+
+	Namespace            string
+	EnforceNamespace     bool
+	kruisev1alpha1Client kruiseclientsets.Interface
+	DryRunStrategy       cmdutil.DryRunStrategy
+	FieldManager         string
+	CreateAnnotation     bool
+	ServerSideApplyOptions
+
+	genericclioptions.IOStreams
+}
+
+// NewCreateCloneSetOptions initializes and returns new CreateCloneSetOptions instance
+func NewCreateCloneSetOptions(ioStreams genericclioptions.IOStreams) *CreateCloneSetOptions {
+	return &CreateCloneSetOptions{
+		PrintFlags:     genericclioptions.NewPrintFlags("created").WithTypeSetter(internalapi.GetScheme()),
+		Replicas:       1,
+		Port:           -1,
+		UpdateStrategy: string(kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType),
+		IOStreams:      ioStreams,
+	}
+}
+
+// NewCmdCreateCloneSet is a command to ease creating CloneSets.
+func NewCmdCreateCloneSet(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCreateCloneSetOptions(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "cloneset NAME --image=image [--replicas=1] [--port=port] [--labels=key=value] [--update-strategy=InPlaceIfPossible|ReCreate]",
+		DisableFlagsInUseLine: true,
+		Short:                 cloneSetLong,
+		Long:                  cloneSetLong,
+		Example:               cloneSetExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+
+	cmdutil.AddApplyAnnotationFlags(cmd)
+	cmdutil.AddValidateFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().StringVar(&o.Image, "image", o.Image, "Image name to run.")
+	cmd.Flags().Int32VarP(&o.Replicas, "replicas", "r", o.Replicas, "Number of replicas to create. Default is 1.")
+	cmd.Flags().Int32Var(&o.Port, "port", o.Port, "The port that the container exposes.")
+	cmd.Flags().StringVarP(&o.Labels, "labels", "l", o.Labels, "Comma separated labels to apply to the cloneset, its selector and pod template. Defaults to app=NAME.")
+	cmd.Flags().StringVar(&o.UpdateStrategy, "update-strategy", o.UpdateStrategy, "The update strategy of the cloneset, one of InPlaceIfPossible or ReCreate.")
+	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl kruise-create")
+	o.ServerSideApplyOptions.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *CreateCloneSetOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	name, err := NameFromCommandArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	o.Name = name
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.kruisev1alpha1Client, err = kruiseclientsets.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+
+	o.CreateAnnotation = cmdutil.GetFlagBool(cmd, cmdutil.ApplyAnnotationsFlag)
+
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	o.ServerSideApplyOptions.CompletePrintFlags(o.PrintFlags)
+	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	o.PrintObj = func(obj runtime.Object) error {
+		return printer.PrintObj(obj, o.Out)
+	}
+
+	return nil
+}
+
+// Validate makes sure provided values and valid CloneSet options
+func (o *CreateCloneSetOptions) Validate() error {
+	if err := o.ServerSideApplyOptions.Validate(); err != nil {
+		return err
+	}
+	if len(o.Image) == 0 {
+		return fmt.Errorf("--image must be specified")
+	}
+	if o.Replicas < 0 {
+		return fmt.Errorf("--replicas must be greater than or equal to 0")
+	}
+	if o.Port > 0 {
+		if errs := utilvalidation.IsValidPortNum(int(o.Port)); len(errs) != 0 {
+			return fmt.Errorf("--port %d is invalid: %s", o.Port, strings.Join(errs, ", "))
+		}
+	}
+	if len(o.Labels) > 0 {
+		if _, err := generate.ParseLabels(o.Labels); err != nil {
+			return err
+		}
+	}
+	switch kruiseappsv1alpha1.CloneSetUpdateStrategyType(o.UpdateStrategy) {
+	case kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType, kruiseappsv1alpha1.RecreateCloneSetUpdateStrategyType:
+	default:
+		return fmt.Errorf("--update-strategy must be one of %s or %s, got %q",
+			kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType, kruiseappsv1alpha1.RecreateCloneSetUpdateStrategyType, o.UpdateStrategy)
+	}
+	return nil
+}
+
+// Run performs the execution of 'create cloneset' sub command
+func (o *CreateCloneSetOptions) Run() error {
+	cloneSet, err := o.createCloneSet()
+	if err != nil {
+		return err
+	}
+
+	if err := util.CreateOrUpdateAnnotation(o.CreateAnnotation, cloneSet, scheme.DefaultJSONEncoder()); err != nil {
+		return err
+	}
+
+	if o.DryRunStrategy != cmdutil.DryRunClient {
+		if o.ServerSide {
+			data, patchOptions, err := o.ServerSideApplyOptions.ApplyPatch(cloneSet, o.FieldManager, o.DryRunStrategy)
+			if err != nil {
+				return err
+			}
+			cloneSet, err = o.kruisev1alpha1Client.AppsV1alpha1().CloneSets(o.Namespace).Patch(context.TODO(), cloneSet.Name, types.ApplyPatchType, data, patchOptions)
+			if err != nil {
+				return fmt.Errorf("failed to apply cloneset: %v", err)
+			}
+		} else {
+			createOptions := metav1.CreateOptions{}
+			if o.FieldManager != "" {
+				createOptions.FieldManager = o.FieldManager
+			}
+			if o.DryRunStrategy == cmdutil.DryRunServer {
+				createOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			cloneSet, err = o.kruisev1alpha1Client.AppsV1alpha1().CloneSets(o.Namespace).Create(context.TODO(), cloneSet, createOptions)
+			if err != nil {
+				return fmt.Errorf("failed to create cloneset: %v", err)
+			}
+		}
+	}
+
+	return o.PrintObj(cloneSet)
+}
+
+func (o *CreateCloneSetOptions) createCloneSet() (*kruiseappsv1alpha1.CloneSet, error) {
+	labels := map[string]string{"app": o.Name}
+	if len(o.Labels) > 0 {
+		var err error
+		labels, err = generate.ParseLabels(o.Labels)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	container := corev1.Container{
+		Name:  containerNameFromImage(o.Image),
+		Image: o.Image,
+	}
+	if o.Port > 0 {
+		container.Ports = []corev1.ContainerPort{{ContainerPort: o.Port}}
+	}
+
+	cloneSet := &kruiseappsv1alpha1.CloneSet{
+		// this is ok because we know exactly how we want to be serialized
+		TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   o.Name,
+			Labels: labels,
+		},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Replicas: utilpointer.Int32(o.Replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{container},
+				},
+			},
+			UpdateStrategy: kruiseappsv1alpha1.CloneSetUpdateStrategy{
+				Type: kruiseappsv1alpha1.CloneSetUpdateStrategyType(o.UpdateStrategy),
+			},
+		},
+	}
+	if o.EnforceNamespace {
+		cloneSet.Namespace = o.Namespace
+	}
+	return cloneSet, nil
+}
+
+// containerNameFromImage derives a container name from an image reference,
+// e.g. "registry.example.com/library/nginx:1.25" becomes "nginx".
+func containerNameFromImage(image string) string {
+	name := path.Base(image)
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestCreateCloneSetGenerate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		image          string
+		replicas       int32
+		port           int32
+		labels         string
+		updateStrategy string
+		expectLabels   map[string]string
+		expectSpec     corev1.PodSpec
+		expectStrategy kruiseappsv1alpha1.CloneSetUpdateStrategyType
+	}{
+		{
+			name:           "defaults",
+			image:          "nginx",
+			replicas:       1,
+			port:           -1,
+			updateStrategy: "InPlaceIfPossible",
+			expectLabels:   map[string]string{"app": "my-app"},
+			expectSpec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}},
+			},
+			expectStrategy: kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+		},
+		{
+			name:           "port labels and recreate",
+			image:          "registry.example.com/library/nginx:1.25",
+			replicas:       3,
+			port:           80,
+			labels:         "app=web,tier=frontend",
+			updateStrategy: "ReCreate",
+			expectLabels:   map[string]string{"app": "web", "tier": "frontend"},
+			expectSpec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:  "nginx",
+					Image: "registry.example.com/library/nginx:1.25",
+					Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+				}},
+			},
+			expectStrategy: kruiseappsv1alpha1.RecreateCloneSetUpdateStrategyType,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &CreateCloneSetOptions{
+				Name:           "my-app",
+				Image:          tc.image,
+				Replicas:       tc.replicas,
+				Port:           tc.port,
+				Labels:         tc.labels,
+				UpdateStrategy: tc.updateStrategy,
+			}
+			assert.NoError(t, o.Validate())
+
+			cloneSet, err := o.createCloneSet()
+			assert.NoError(t, err)
+			assert.Equal(t, "my-app", cloneSet.Name)
+			assert.Equal(t, tc.expectLabels, cloneSet.Labels)
+			assert.Equal(t, tc.replicas, *cloneSet.Spec.Replicas)
+			assert.Equal(t, &metav1.LabelSelector{MatchLabels: tc.expectLabels}, cloneSet.Spec.Selector)
+			assert.Equal(t, tc.expectLabels, cloneSet.Spec.Template.Labels)
+			assert.Equal(t, tc.expectSpec, cloneSet.Spec.Template.Spec)
+			assert.Equal(t, kruiseappsv1alpha1.CloneSetUpdateStrategy{Type: tc.expectStrategy}, cloneSet.Spec.UpdateStrategy)
+		})
+	}
+}
+
+func TestCreateCloneSetValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		options   *CreateCloneSetOptions
+		expectErr string
+	}{
+		{
+			name:      "missing image",
+			options:   &CreateCloneSetOptions{UpdateStrategy: "InPlaceIfPossible"},
+			expectErr: "--image must be specified",
+		},
+		{
+			name:      "negative replicas",
+			options:   &CreateCloneSetOptions{Image: "nginx", Replicas: -1, UpdateStrategy: "InPlaceIfPossible"},
+			expectErr: "--replicas must be greater than or equal to 0",
+		},
+		{
+			name:      "invalid port",
+			options:   &CreateCloneSetOptions{Image: "nginx", Port: 70000, UpdateStrategy: "InPlaceIfPossible"},
+			expectErr: "--port 70000 is invalid: must be between 1 and 65535, inclusive",
+		},
+		{
+			name:      "unknown update strategy",
+			options:   &CreateCloneSetOptions{Image: "nginx", UpdateStrategy: "InPlaceOnly"},
+			expectErr: `--update-strategy must be one of InPlaceIfPossible or ReCreate, got "InPlaceOnly"`,
+		},
+		{
+			name:      "force conflicts without server side",
+			options:   &CreateCloneSetOptions{Image: "nginx", UpdateStrategy: "InPlaceIfPossible", ServerSideApplyOptions: ServerSideApplyOptions{ForceConflicts: true}},
+			expectErr: "--force-conflicts only works with --server-side",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.options.Validate(), tc.expectErr)
+		})
+	}
+}
+
+func TestRunCreateCloneSet(t *testing.T) {
+	testCases := []struct {
+		name         string
+		dryRun       string
+		output       string
+		expectCreate bool
+		expectOut    string
+	}{
+		{
+			name:         "create",
+			expectCreate: true,
+			expectOut:    "cloneset.apps.kruise.io/my-app created\n",
+		},
+		{
+			name:      "client dry-run",
+			dryRun:    "client",
+			expectOut: "cloneset.apps.kruise.io/my-app created (dry run)\n",
+		},
+		{
+			name:      "client dry-run yaml",
+			dryRun:    "client",
+			output:    "yaml",
+			expectOut: "kind: CloneSet",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdCreateCloneSet(tf, streams)
+			if len(tc.dryRun) > 0 {
+				assert.NoError(t, cmd.Flags().Set("dry-run", tc.dryRun))
+			}
+
+			o := NewCreateCloneSetOptions(streams)
+			o.Image = "nginx"
+			o.PrintFlags.OutputFormat = &tc.output
+			assert.NoError(t, o.Complete(tf, cmd, []string{"my-app"}))
+			assert.NoError(t, o.Validate())
+			client := kruisefake.NewSimpleClientset()
+			o.kruisev1alpha1Client = client
+
+			assert.NoError(t, o.Run())
+			assert.Contains(t, out.String(), tc.expectOut)
+
+			_, err := client.AppsV1alpha1().CloneSets("test").Get(context.TODO(), "my-app", metav1.GetOptions{})
+			assert.Equal(t, tc.expectCreate, err == nil)
+		})
+	}
+}
+
+func TestRunCreateCloneSetServerSide(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req)
+		// echo the applied cloneset, which already exists or is created by server-side apply alike
+		body, _ := io.ReadAll(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdCreateCloneSet(tf, streams)
+	o := NewCreateCloneSetOptions(streams)
+	o.Image = "nginx"
+	o.FieldManager = "kubectl kruise-create"
+	o.ServerSideApplyOptions = ServerSideApplyOptions{ServerSide: true, ForceConflicts: true}
+	assert.NoError(t, o.Complete(tf, cmd, []string{"my-app"}))
+	assert.NoError(t, o.Validate())
+	client, err := kruiseclientsets.NewForConfig(&rest.Config{Host: server.URL})
+	assert.NoError(t, err)
+	o.kruisev1alpha1Client = client
+
+	assert.NoError(t, o.Run())
+	assert.Equal(t, "cloneset.apps.kruise.io/my-app serverside-applied\n", out.String())
+
+	if assert.Len(t, requests, 1) {
+		req := requests[0]
+		assert.Equal(t, http.MethodPatch, req.Method)
+		assert.Equal(t, "/apis/apps.kruise.io/v1alpha1/namespaces/test/clonesets/my-app", req.URL.Path)
+		assert.Equal(t, string(types.ApplyPatchType), req.Header.Get("Content-Type"))
+		assert.Equal(t, "kubectl kruise-create", req.URL.Query().Get("fieldManager"))
+		assert.Equal(t, "true", req.URL.Query().Get("force"))
+	}
+}
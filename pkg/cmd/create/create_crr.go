@@ -26,6 +26,7 @@ import (
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -89,6 +90,7 @@ type CreateCRROptions struct {
 	Builder              *resource.Builder
 	FieldManager         string
 	CreateAnnotation     bool
+	ServerSideApplyOptions
 
 	genericclioptions.IOStreams
 }
@@ -127,6 +129,7 @@ func NewCmdCreateCRR(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *
 	cmd.Flags().Int64VarP(&o.UnreadyGracePeriodSeconds, "unreadyGracePeriodSeconds", "u", o.UnreadyGracePeriodSeconds, "UnreadyGracePeriodSeconds is the optional duration in seconds to mark Pod as not ready over this duration before executing preStop hook and stopping the container")
 	cmd.Flags().Int32VarP(&o.MinStartedSeconds, "minStartedSeconds", "m", o.MinStartedSeconds, "Minimum number of seconds for which a newly created container should be started and ready without any of its container crashing, for it to be considered Succeeded.Defaults to 0 (container will be considered Succeeded as soon as it is started and ready)")
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl kruise-create")
+	o.ServerSideApplyOptions.AddFlags(cmd)
 	return cmd
 }
 
@@ -174,6 +177,7 @@ func (o *CreateCRROptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args
 	if err != nil {
 		return err
 	}
+	o.ServerSideApplyOptions.CompletePrintFlags(o.PrintFlags)
 	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
 	printer, err := o.PrintFlags.ToPrinter()
 	if err != nil {
@@ -188,6 +192,9 @@ func (o *CreateCRROptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args
 
 // Validate makes sure provided values and valid crr options
 func (o *CreateCRROptions) Validate() error {
+	if err := o.ServerSideApplyOptions.Validate(); err != nil {
+		return err
+	}
 	// validate whether containers in pod
 	if !o.isPodExist() {
 		return fmt.Errorf("pod %s in namespace %s not exist", o.Name, o.Namespace)
@@ -213,17 +220,28 @@ func (o *CreateCRROptions) Run() error {
 	crr := o.createCRR()
 
 	if o.DryRunStrategy != cmdutil.DryRunClient {
-		createOptions := metav1.CreateOptions{}
-		if o.FieldManager != "" {
-			createOptions.FieldManager = o.FieldManager
-		}
-		if o.DryRunStrategy == cmdutil.DryRunServer {
-			createOptions.DryRun = []string{metav1.DryRunAll}
-		}
-		var err error
-		crr, err = o.kruisev1alpha1Client.AppsV1alpha1().ContainerRecreateRequests(o.Namespace).Create(context.TODO(), crr, createOptions)
-		if err != nil {
-			return fmt.Errorf("failed to create crr: %v", err)
+		if o.ServerSide {
+			data, patchOptions, err := o.ServerSideApplyOptions.ApplyPatch(crr, o.FieldManager, o.DryRunStrategy)
+			if err != nil {
+				return err
+			}
+			crr, err = o.kruisev1alpha1Client.AppsV1alpha1().ContainerRecreateRequests(o.Namespace).Patch(context.TODO(), crr.Name, types.ApplyPatchType, data, patchOptions)
+			if err != nil {
+				return fmt.Errorf("failed to apply crr: %v", err)
+			}
+		} else {
+			createOptions := metav1.CreateOptions{}
+			if o.FieldManager != "" {
+				createOptions.FieldManager = o.FieldManager
+			}
+			if o.DryRunStrategy == cmdutil.DryRunServer {
+				createOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			var err error
+			crr, err = o.kruisev1alpha1Client.AppsV1alpha1().ContainerRecreateRequests(o.Namespace).Create(context.TODO(), crr, createOptions)
+			if err != nil {
+				return fmt.Errorf("failed to create crr: %v", err)
+			}
 		}
 	}
 
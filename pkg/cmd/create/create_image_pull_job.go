@@ -0,0 +1,291 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+var (
+	imagePullJobLong = templates.LongDesc(i18n.T(`
+		Create an imagePullJob with the specified name, to pull an image on the nodes in advance.`))
+
+	imagePullJobExample = templates.Examples(i18n.T(`
+		# Create an imagePullJob that pulls nginx:1.25 on all nodes
+		kubectl kruise create imagepulljob pull-nginx --image=nginx:1.25
+
+		# Pull the image on 5 nodes at a time, on the nodes labeled with pool=web only
+		kubectl kruise create imagepulljob pull-nginx --image=nginx:1.25 --parallelism=5 --selector=pool=web
+
+		# Pull a private image with a pull secret, and delete the job 10 minutes after it finishes
+		kubectl kruise create imagepulljob pull-app --image=registry.example.com/team/app:v2 --pull-secrets=regcred --ttl=600
+
+		# Keep pulling the image on new nodes as they join the cluster
+		kubectl kruise create imagepulljob pull-nginx --image=nginx:1.25 --completion-policy=Never`))
+
+	// imageReferenceRegexp matches an image reference of the form [domain[:port]/]path[:tag][@digest], where
+	// the path is made of lowercase components as in the reference grammar of the distribution project.
+	imageReferenceRegexp = regexp.MustCompile(`^` +
+		`(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+		`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+		`(?::[\w][\w.-]{0,127})?` +
+		`(?:@[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,})?` +
+		`$`)
+)
+
+// CreateImagePullJobOptions is the command line options for 'create imagepulljob'
+type CreateImagePullJobOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+
+	PrintObj func(obj runtime.Object) error
+
+	Name             string
+	Image            string
+	Parallelism      string
+	Selector         string
+	PullSecrets      []string
+	CompletionPolicy string
+	TTL              int32
+
+	Namespace            string
+	EnforceNamespace     bool
+	kruisev1alpha1Client kruiseclientsets.Interface
+	DryRunStrategy       cmdutil.DryRunStrategy
+	FieldManager         string
+	CreateAnnotation     bool
+	ServerSideApplyOptions
+
+	genericclioptions.IOStreams
+}
+
+// NewCreateImagePullJobOptions initializes and returns new CreateImagePullJobOptions instance
+func NewCreateImagePullJobOptions(ioStreams genericclioptions.IOStreams) *CreateImagePullJobOptions {
+	return &CreateImagePullJobOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("created").WithTypeSetter(internalapi.GetScheme()),
+		TTL:        -1,
+		IOStreams:  ioStreams,
+	}
+}
+
+// NewCmdCreateImagePullJob is a command to ease creating ImagePullJobs.
+func NewCmdCreateImagePullJob(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCreateImagePullJobOptions(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "imagepulljob NAME --image=image [--parallelism=n] [--selector=key=value] [--pull-secrets=secret] [--completion-policy=Always|Never] [--ttl=seconds]",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"imagePullJob", "ipj"},
+		Short:                 imagePullJobLong,
+		Long:                  imagePullJobLong,
+		Example:               imagePullJobExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+
+	cmdutil.AddApplyAnnotationFlags(cmd)
+	cmdutil.AddValidateFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().StringVar(&o.Image, "image", o.Image, "Image to pull on the nodes.")
+	cmd.Flags().StringVar(&o.Parallelism, "parallelism", o.Parallelism, "The maximum number or percentage of nodes to pull the image on at the same time, e.g. 5 or 50%. Defaults to 1.")
+	cmd.Flags().StringVar(&o.Selector, "selector", o.Selector, "Label query over the nodes to pull the image on, e.g. pool=web. Not setting it means all nodes.")
+	cmd.Flags().StringSliceVar(&o.PullSecrets, "pull-secrets", o.PullSecrets, "Comma separated names of the secrets in the namespace of the job to use for pulling the image.")
+	cmd.Flags().StringVar(&o.CompletionPolicy, "completion-policy", o.CompletionPolicy, "The completion policy of the ImagePullJob, one of Always or Never. Defaults to Always.")
+	cmd.Flags().Int32Var(&o.TTL, "ttl", o.TTL, "The number of seconds after the ImagePullJob finishes before it is deleted. Only works with the Always completion policy.")
+	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl kruise-create")
+	o.ServerSideApplyOptions.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *CreateImagePullJobOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	name, err := NameFromCommandArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	o.Name = name
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.kruisev1alpha1Client, err = kruiseclientsets.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+
+	o.CreateAnnotation = cmdutil.GetFlagBool(cmd, cmdutil.ApplyAnnotationsFlag)
+
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	o.ServerSideApplyOptions.CompletePrintFlags(o.PrintFlags)
+	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	o.PrintObj = func(obj runtime.Object) error {
+		return printer.PrintObj(obj, o.Out)
+	}
+
+	return nil
+}
+
+// Validate makes sure provided values and valid ImagePullJob options
+func (o *CreateImagePullJobOptions) Validate() error {
+	if err := o.ServerSideApplyOptions.Validate(); err != nil {
+		return err
+	}
+	if len(o.Image) == 0 {
+		return fmt.Errorf("--image must be specified")
+	}
+	if !imageReferenceRegexp.MatchString(o.Image) {
+		return fmt.Errorf("invalid --image %q: must be a reference of the form [registry/]repository[:tag][@digest], e.g. nginx:1.25", o.Image)
+	}
+	if len(o.Parallelism) != 0 {
+		if err := validateParallelism(o.Parallelism); err != nil {
+			return err
+		}
+	}
+	if len(o.Selector) != 0 {
+		if _, err := metav1.ParseToLabelSelector(o.Selector); err != nil {
+			return fmt.Errorf("invalid --selector: %v", err)
+		}
+	}
+	for _, secret := range o.PullSecrets {
+		if len(secret) == 0 {
+			return fmt.Errorf("--pull-secrets must not contain empty names")
+		}
+	}
+	switch kruiseappsv1alpha1.CompletionPolicyType(o.CompletionPolicy) {
+	case "", kruiseappsv1alpha1.Always:
+	case kruiseappsv1alpha1.Never:
+		if o.TTL >= 0 {
+			return fmt.Errorf("--ttl only works with the %s completion policy", kruiseappsv1alpha1.Always)
+		}
+	default:
+		return fmt.Errorf("--completion-policy must be one of %s or %s, got %q", kruiseappsv1alpha1.Always, kruiseappsv1alpha1.Never, o.CompletionPolicy)
+	}
+	return nil
+}
+
+// Run performs the execution of 'create imagepulljob' sub command
+func (o *CreateImagePullJobOptions) Run() error {
+	job, err := o.createImagePullJob()
+	if err != nil {
+		return err
+	}
+
+	if err := util.CreateOrUpdateAnnotation(o.CreateAnnotation, job, scheme.DefaultJSONEncoder()); err != nil {
+		return err
+	}
+
+	if o.DryRunStrategy != cmdutil.DryRunClient {
+		if o.ServerSide {
+			data, patchOptions, err := o.ServerSideApplyOptions.ApplyPatch(job, o.FieldManager, o.DryRunStrategy)
+			if err != nil {
+				return err
+			}
+			job, err = o.kruisev1alpha1Client.AppsV1alpha1().ImagePullJobs(o.Namespace).Patch(context.TODO(), job.Name, types.ApplyPatchType, data, patchOptions)
+			if err != nil {
+				return fmt.Errorf("failed to apply imagepulljob: %v", err)
+			}
+		} else {
+			createOptions := metav1.CreateOptions{}
+			if o.FieldManager != "" {
+				createOptions.FieldManager = o.FieldManager
+			}
+			if o.DryRunStrategy == cmdutil.DryRunServer {
+				createOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			job, err = o.kruisev1alpha1Client.AppsV1alpha1().ImagePullJobs(o.Namespace).Create(context.TODO(), job, createOptions)
+			if err != nil {
+				return fmt.Errorf("failed to create imagepulljob: %v", err)
+			}
+		}
+	}
+
+	return o.PrintObj(job)
+}
+
+func (o *CreateImagePullJobOptions) createImagePullJob() (*kruiseappsv1alpha1.ImagePullJob, error) {
+	job := &kruiseappsv1alpha1.ImagePullJob{
+		// this is ok because we know exactly how we want to be serialized
+		TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "ImagePullJob"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: o.Name,
+		},
+		Spec: kruiseappsv1alpha1.ImagePullJobSpec{
+			Image: o.Image,
+			ImagePullJobTemplate: kruiseappsv1alpha1.ImagePullJobTemplate{
+				PullSecrets: o.PullSecrets,
+				CompletionPolicy: kruiseappsv1alpha1.CompletionPolicy{
+					Type: kruiseappsv1alpha1.Always,
+				},
+			},
+		},
+	}
+	if len(o.Parallelism) != 0 {
+		parallelism := intstr.Parse(o.Parallelism)
+		job.Spec.Parallelism = &parallelism
+	}
+	if len(o.Selector) != 0 {
+		selector, err := metav1.ParseToLabelSelector(o.Selector)
+		if err != nil {
+			return nil, err
+		}
+		job.Spec.Selector = &kruiseappsv1alpha1.ImagePullJobNodeSelector{LabelSelector: *selector}
+	}
+	if len(o.CompletionPolicy) != 0 {
+		job.Spec.CompletionPolicy.Type = kruiseappsv1alpha1.CompletionPolicyType(o.CompletionPolicy)
+	}
+	if o.TTL >= 0 {
+		job.Spec.CompletionPolicy.TTLSecondsAfterFinished = utilpointer.Int32(o.TTL)
+	}
+	if o.EnforceNamespace {
+		job.Namespace = o.Namespace
+	}
+	return job, nil
+}
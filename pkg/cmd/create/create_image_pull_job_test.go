@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+func TestCreateImagePullJobGenerate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		options        *CreateImagePullJobOptions
+		expectTemplate kruiseappsv1alpha1.ImagePullJobTemplate
+	}{
+		{
+			name:    "defaults",
+			options: &CreateImagePullJobOptions{Image: "nginx:1.25", TTL: -1},
+			expectTemplate: kruiseappsv1alpha1.ImagePullJobTemplate{
+				CompletionPolicy: kruiseappsv1alpha1.CompletionPolicy{Type: kruiseappsv1alpha1.Always},
+			},
+		},
+		{
+			name: "parallelism selector pull secrets and ttl",
+			options: &CreateImagePullJobOptions{
+				Image:       "nginx:1.25",
+				Parallelism: "5",
+				Selector:    "pool=web,zone in (a,b)",
+				PullSecrets: []string{"regcred"},
+				TTL:         600,
+			},
+			expectTemplate: kruiseappsv1alpha1.ImagePullJobTemplate{
+				PullSecrets: []string{"regcred"},
+				Selector: &kruiseappsv1alpha1.ImagePullJobNodeSelector{
+					LabelSelector: metav1.LabelSelector{
+						MatchLabels:      map[string]string{"pool": "web"},
+						MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"a", "b"}}},
+					},
+				},
+				Parallelism: intOrStrPtr(intstr.FromInt(5)),
+				CompletionPolicy: kruiseappsv1alpha1.CompletionPolicy{
+					Type:                    kruiseappsv1alpha1.Always,
+					TTLSecondsAfterFinished: utilpointer.Int32(600),
+				},
+			},
+		},
+		{
+			name:    "percentage parallelism and never completes",
+			options: &CreateImagePullJobOptions{Image: "nginx:1.25", Parallelism: "50%", CompletionPolicy: "Never", TTL: -1},
+			expectTemplate: kruiseappsv1alpha1.ImagePullJobTemplate{
+				Parallelism:      intOrStrPtr(intstr.FromString("50%")),
+				CompletionPolicy: kruiseappsv1alpha1.CompletionPolicy{Type: kruiseappsv1alpha1.Never},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.options.Name = "pull-nginx"
+			assert.NoError(t, tc.options.Validate())
+
+			job, err := tc.options.createImagePullJob()
+			assert.NoError(t, err)
+			assert.Equal(t, "pull-nginx", job.Name)
+			assert.Equal(t, "nginx:1.25", job.Spec.Image)
+			assert.Equal(t, tc.expectTemplate, job.Spec.ImagePullJobTemplate)
+		})
+	}
+}
+
+func TestCreateImagePullJobValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		options   *CreateImagePullJobOptions
+		expectErr string
+	}{
+		{
+			name:      "missing image",
+			options:   &CreateImagePullJobOptions{TTL: -1},
+			expectErr: "--image must be specified",
+		},
+		{
+			name:      "uppercase repository",
+			options:   &CreateImagePullJobOptions{Image: "Nginx:1.25", TTL: -1},
+			expectErr: `invalid --image "Nginx:1.25": must be a reference of the form [registry/]repository[:tag][@digest], e.g. nginx:1.25`,
+		},
+		{
+			name:      "empty tag",
+			options:   &CreateImagePullJobOptions{Image: "nginx:", TTL: -1},
+			expectErr: `invalid --image "nginx:": must be a reference of the form [registry/]repository[:tag][@digest], e.g. nginx:1.25`,
+		},
+		{
+			name:      "zero parallelism",
+			options:   &CreateImagePullJobOptions{Image: "nginx", Parallelism: "0", TTL: -1},
+			expectErr: "--parallelism must be greater than 0",
+		},
+		{
+			name:      "parallelism above 100 percent",
+			options:   &CreateImagePullJobOptions{Image: "nginx", Parallelism: "101%", TTL: -1},
+			expectErr: "--parallelism must be a percentage between 1% and 100%, got 101%",
+		},
+		{
+			name:      "invalid selector",
+			options:   &CreateImagePullJobOptions{Image: "nginx", Selector: "pool=web=x", TTL: -1},
+			expectErr: `invalid --selector: couldn't parse the selector string "pool=web=x": found '=', expected: ',' or 'end of string'`,
+		},
+		{
+			name:      "ttl with never completes",
+			options:   &CreateImagePullJobOptions{Image: "nginx", CompletionPolicy: "Never", TTL: 600},
+			expectErr: "--ttl only works with the Always completion policy",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.options.Validate(), tc.expectErr)
+		})
+	}
+
+	for _, image := range []string{
+		"nginx",
+		"library/nginx:1.25",
+		"localhost:5000/team/app:v2",
+		"registry.example.com/team/app_x@sha256:4c4c5b1c9b5a8e7e7f5b3f6c9f0e0d1b2a3c4d5e6f708192a3b4c5d6e7f80912",
+	} {
+		o := &CreateImagePullJobOptions{Image: image, TTL: -1}
+		assert.NoError(t, o.Validate(), image)
+	}
+}
+
+func TestRunCreateImagePullJob(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdCreateImagePullJob(tf, streams)
+	o := NewCreateImagePullJobOptions(streams)
+	o.Image = "nginx:1.25"
+	o.Parallelism = "5"
+	o.Selector = "pool=web"
+	assert.NoError(t, o.Complete(tf, cmd, []string{"pull-nginx"}))
+	assert.NoError(t, o.Validate())
+	client := kruisefake.NewSimpleClientset()
+	o.kruisev1alpha1Client = client
+
+	assert.NoError(t, o.Run())
+	assert.Equal(t, "imagepulljob.apps.kruise.io/pull-nginx created\n", out.String())
+
+	job, err := client.AppsV1alpha1().ImagePullJobs("test").Get(context.TODO(), "pull-nginx", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx:1.25", job.Spec.Image)
+	assert.Equal(t, intOrStrPtr(intstr.FromInt(5)), job.Spec.Parallelism)
+	assert.Equal(t, map[string]string{"pool": "web"}, job.Spec.Selector.MatchLabels)
+}
+
+func intOrStrPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
@@ -73,6 +73,7 @@ type CreateJobOptions struct {
 	Builder          *resource.Builder
 	FieldManager     string
 	CreateAnnotation bool
+	ServerSideApplyOptions
 
 	genericclioptions.IOStreams
 }
@@ -109,6 +110,7 @@ func NewCmdCreateJob(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *
 	cmd.Flags().StringVar(&o.Image, "image", o.Image, "Image name to run.")
 	cmd.Flags().StringVar(&o.From, "from", o.From, "The name of the resource to create a Job from (cronjob and advancedCronjob are supported).")
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl kruise-create")
+	o.ServerSideApplyOptions.AddFlags(cmd)
 	return cmd
 }
 
@@ -144,6 +146,7 @@ func (o *CreateJobOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args
 	if err != nil {
 		return err
 	}
+	o.ServerSideApplyOptions.CompletePrintFlags(o.PrintFlags)
 	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
 	printer, err := o.PrintFlags.ToPrinter()
 	if err != nil {
@@ -158,6 +161,9 @@ func (o *CreateJobOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args
 
 // Validate makes sure provided values and valid Job options
 func (o *CreateJobOptions) Validate() error {
+	if err := o.ServerSideApplyOptions.Validate(); err != nil {
+		return err
+	}
 	if (len(o.Image) == 0 && len(o.From) == 0) || (len(o.Image) != 0 && len(o.From) != 0) {
 		return fmt.Errorf("either --image or --from must be specified")
 	}
@@ -208,17 +214,28 @@ func (o *CreateJobOptions) Run() error {
 	}
 
 	if o.DryRunStrategy != cmdutil.DryRunClient {
-		createOptions := metav1.CreateOptions{}
-		if o.FieldManager != "" {
-			createOptions.FieldManager = o.FieldManager
-		}
-		if o.DryRunStrategy == cmdutil.DryRunServer {
-			createOptions.DryRun = []string{metav1.DryRunAll}
-		}
-		var err error
-		job, err = o.Client.Jobs(o.Namespace).Create(context.TODO(), job, createOptions)
-		if err != nil {
-			return fmt.Errorf("failed to create job: %v", err)
+		if o.ServerSide {
+			data, patchOptions, err := o.ServerSideApplyOptions.ApplyPatch(job, o.FieldManager, o.DryRunStrategy)
+			if err != nil {
+				return err
+			}
+			job, err = o.Client.Jobs(o.Namespace).Patch(context.TODO(), job.Name, types.ApplyPatchType, data, patchOptions)
+			if err != nil {
+				return fmt.Errorf("failed to apply job: %v", err)
+			}
+		} else {
+			createOptions := metav1.CreateOptions{}
+			if o.FieldManager != "" {
+				createOptions.FieldManager = o.FieldManager
+			}
+			if o.DryRunStrategy == cmdutil.DryRunServer {
+				createOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			var err error
+			job, err = o.Client.Jobs(o.Namespace).Create(context.TODO(), job, createOptions)
+			if err != nil {
+				return fmt.Errorf("failed to create job: %v", err)
+			}
 		}
 	}
 
@@ -0,0 +1,398 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	podProbeMarkerLong = templates.LongDesc(i18n.T(`
+		Create a podProbeMarker with the specified name, to run custom probes in the pods it selects.
+
+		A probe is given with --probe as comma separated key=value pairs:
+
+		    name        the name of the probe, required and unique within the podProbeMarker
+		    container   the container to probe, defaults to --container
+		    tcp         a port to open a TCP connection to, e.g. tcp=8080
+		    http        a port and path to send an HTTP GET request to, e.g. http=8080/healthz
+		    exec        a command to run in the container, e.g. exec=cat /tmp/healthy
+		    condition   the pod condition type recording the result of the probe
+		    period      how often in seconds to run the probe
+		    timeout     the number of seconds after which the probe times out
+
+		A probe sets exactly one of tcp, http or exec. --marker-state maps a probe state, Succeeded or
+		Failed, to a label or annotation set on the pod, e.g. Succeeded:labels.healthy=true, and applies
+		to every probe. A probe without a condition needs at least one --marker-state.`))
+
+	podProbeMarkerExample = templates.Examples(i18n.T(`
+		# Probe port 8080 of the main container of the pods labeled with app=web, and label them healthy when it succeeds
+		kubectl kruise create podprobemarker web-healthz --selector=app=web --container=main --probe=name=healthz,tcp=8080 --marker-state=Succeeded:labels.healthy=true
+
+		# Record the result of an HTTP probe in the game.kruise.io/healthy condition of the pods
+		kubectl kruise create podprobemarker game --selector=app=game --probe=name=healthy,container=main,http=8080/healthz,condition=game.kruise.io/healthy
+
+		# Lower the deletion cost of the pods whose exec probe fails
+		kubectl kruise create podprobemarker idle --selector=app=game --container=main --probe='name=idle,exec=/bin/sh -c /check-idle.sh' \
+		  --marker-state=Succeeded:annotations.controller.kubernetes.io/pod-deletion-cost=10 \
+		  --marker-state=Failed:annotations.controller.kubernetes.io/pod-deletion-cost=-10`))
+)
+
+// CreatePodProbeMarkerOptions is the command line options for 'create podprobemarker'
+type CreatePodProbeMarkerOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+
+	PrintObj func(obj runtime.Object) error
+
+	Name         string
+	Selector     string
+	Container    string
+	Probes       []string
+	MarkerStates []string
+
+	Namespace            string
+	EnforceNamespace     bool
+	kruisev1alpha1Client kruiseclientsets.Interface
+	DryRunStrategy       cmdutil.DryRunStrategy
+	FieldManager         string
+	CreateAnnotation     bool
+	ServerSideApplyOptions
+
+	genericclioptions.IOStreams
+}
+
+// NewCreatePodProbeMarkerOptions initializes and returns new CreatePodProbeMarkerOptions instance
+func NewCreatePodProbeMarkerOptions(ioStreams genericclioptions.IOStreams) *CreatePodProbeMarkerOptions {
+	return &CreatePodProbeMarkerOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("created").WithTypeSetter(internalapi.GetScheme()),
+		IOStreams:  ioStreams,
+	}
+}
+
+// NewCmdCreatePodProbeMarker is a command to ease creating PodProbeMarkers.
+func NewCmdCreatePodProbeMarker(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCreatePodProbeMarkerOptions(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "podprobemarker NAME --selector=key=value --probe=name=NAME,tcp=PORT [--container=container] [--marker-state=STATE:labels.KEY=VALUE]",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"podProbeMarker", "ppm"},
+		Short:                 i18n.T("Create a podProbeMarker with the specified name"),
+		Long:                  podProbeMarkerLong,
+		Example:               podProbeMarkerExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+
+	cmdutil.AddApplyAnnotationFlags(cmd)
+	cmdutil.AddValidateFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().StringVar(&o.Selector, "selector", o.Selector, "Label query over the pods to probe, e.g. app=web.")
+	cmd.Flags().StringVar(&o.Container, "container", o.Container, "The container to probe for the probes which do not set one.")
+	cmd.Flags().StringArrayVar(&o.Probes, "probe", o.Probes, "A probe to run, as comma separated key=value pairs, e.g. name=healthz,tcp=8080. Can be repeated.")
+	cmd.Flags().StringArrayVar(&o.MarkerStates, "marker-state", o.MarkerStates, "A label or annotation to set on the pods in a probe state, e.g. Succeeded:labels.healthy=true. Can be repeated.")
+	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl kruise-create")
+	o.ServerSideApplyOptions.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *CreatePodProbeMarkerOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	name, err := NameFromCommandArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	o.Name = name
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.kruisev1alpha1Client, err = kruiseclientsets.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+
+	o.CreateAnnotation = cmdutil.GetFlagBool(cmd, cmdutil.ApplyAnnotationsFlag)
+
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	o.ServerSideApplyOptions.CompletePrintFlags(o.PrintFlags)
+	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	o.PrintObj = func(obj runtime.Object) error {
+		return printer.PrintObj(obj, o.Out)
+	}
+
+	return nil
+}
+
+// Validate makes sure provided values and valid PodProbeMarker options
+func (o *CreatePodProbeMarkerOptions) Validate() error {
+	if err := o.ServerSideApplyOptions.Validate(); err != nil {
+		return err
+	}
+	if len(o.Selector) == 0 {
+		return fmt.Errorf("--selector must be specified")
+	}
+	if _, err := metav1.ParseToLabelSelector(o.Selector); err != nil {
+		return fmt.Errorf("invalid --selector: %v", err)
+	}
+	if len(o.Probes) == 0 {
+		return fmt.Errorf("at least one --probe must be specified, e.g. --probe=name=healthz,tcp=8080")
+	}
+	policies, err := parseMarkerStates(o.MarkerStates)
+	if err != nil {
+		return err
+	}
+
+	names := sets.NewString()
+	for _, spec := range o.Probes {
+		probe, err := parseProbe(spec, o.Container)
+		if err != nil {
+			return err
+		}
+		if names.Has(probe.Name) {
+			return fmt.Errorf("duplicate probe name %q", probe.Name)
+		}
+		names.Insert(probe.Name)
+		if len(probe.PodConditionType) == 0 && len(policies) == 0 {
+			return fmt.Errorf("probe %q must set a condition, or --marker-state must be specified", probe.Name)
+		}
+	}
+	return nil
+}
+
+// Run performs the execution of 'create podprobemarker' sub command
+func (o *CreatePodProbeMarkerOptions) Run() error {
+	marker, err := o.createPodProbeMarker()
+	if err != nil {
+		return err
+	}
+
+	if err := util.CreateOrUpdateAnnotation(o.CreateAnnotation, marker, scheme.DefaultJSONEncoder()); err != nil {
+		return err
+	}
+
+	if o.DryRunStrategy != cmdutil.DryRunClient {
+		if o.ServerSide {
+			data, patchOptions, err := o.ServerSideApplyOptions.ApplyPatch(marker, o.FieldManager, o.DryRunStrategy)
+			if err != nil {
+				return err
+			}
+			marker, err = o.kruisev1alpha1Client.AppsV1alpha1().PodProbeMarkers(o.Namespace).Patch(context.TODO(), marker.Name, types.ApplyPatchType, data, patchOptions)
+			if err != nil {
+				return fmt.Errorf("failed to apply podprobemarker: %v", err)
+			}
+		} else {
+			createOptions := metav1.CreateOptions{}
+			if o.FieldManager != "" {
+				createOptions.FieldManager = o.FieldManager
+			}
+			if o.DryRunStrategy == cmdutil.DryRunServer {
+				createOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			marker, err = o.kruisev1alpha1Client.AppsV1alpha1().PodProbeMarkers(o.Namespace).Create(context.TODO(), marker, createOptions)
+			if err != nil {
+				return fmt.Errorf("failed to create podprobemarker: %v", err)
+			}
+		}
+	}
+
+	return o.PrintObj(marker)
+}
+
+func (o *CreatePodProbeMarkerOptions) createPodProbeMarker() (*kruiseappsv1alpha1.PodProbeMarker, error) {
+	selector, err := metav1.ParseToLabelSelector(o.Selector)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := parseMarkerStates(o.MarkerStates)
+	if err != nil {
+		return nil, err
+	}
+
+	marker := &kruiseappsv1alpha1.PodProbeMarker{
+		// this is ok because we know exactly how we want to be serialized
+		TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "PodProbeMarker"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: o.Name,
+		},
+		Spec: kruiseappsv1alpha1.PodProbeMarkerSpec{
+			Selector: selector,
+		},
+	}
+	for _, spec := range o.Probes {
+		probe, err := parseProbe(spec, o.Container)
+		if err != nil {
+			return nil, err
+		}
+		probe.MarkerPolicy = policies
+		marker.Spec.Probes = append(marker.Spec.Probes, probe)
+	}
+	if o.EnforceNamespace {
+		marker.Namespace = o.Namespace
+	}
+	return marker, nil
+}
+
+// parseProbe parses a --probe of comma separated key=value pairs into a probe of container, unless the probe sets
+// a container of its own.
+func parseProbe(spec, container string) (kruiseappsv1alpha1.PodContainerProbe, error) {
+	probe := kruiseappsv1alpha1.PodContainerProbe{ContainerName: container}
+	handlers := 0
+	for _, pair := range strings.Split(spec, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !found || len(value) == 0 {
+			return probe, fmt.Errorf("invalid --probe %q: %q must be of the form key=value", spec, pair)
+		}
+		switch key {
+		case "name":
+			probe.Name = value
+		case "container":
+			probe.ContainerName = value
+		case "condition":
+			probe.PodConditionType = value
+		case "tcp":
+			handlers++
+			probe.Probe.TCPSocket = &corev1.TCPSocketAction{Port: intstr.Parse(value)}
+		case "http":
+			handlers++
+			port, path, _ := strings.Cut(value, "/")
+			probe.Probe.HTTPGet = &corev1.HTTPGetAction{Port: intstr.Parse(port), Path: "/" + path}
+		case "exec":
+			handlers++
+			probe.Probe.Exec = &corev1.ExecAction{Command: strings.Fields(value)}
+		case "period", "timeout":
+			seconds, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || seconds <= 0 {
+				return probe, fmt.Errorf("invalid --probe %q: %s must be a positive number of seconds, got %q", spec, key, value)
+			}
+			if key == "period" {
+				probe.Probe.PeriodSeconds = int32(seconds)
+			} else {
+				probe.Probe.TimeoutSeconds = int32(seconds)
+			}
+		default:
+			return probe, fmt.Errorf("invalid --probe %q: unknown key %q", spec, key)
+		}
+	}
+
+	if len(probe.Name) == 0 {
+		return probe, fmt.Errorf("invalid --probe %q: a name must be specified", spec)
+	}
+	if len(probe.ContainerName) == 0 {
+		return probe, fmt.Errorf("invalid --probe %q: a container must be specified, or --container", spec)
+	}
+	if handlers != 1 {
+		return probe, fmt.Errorf("invalid --probe %q: exactly one of tcp, http or exec must be specified", spec)
+	}
+	if port := probeHandlerPort(probe.Probe.Probe); port != nil && port.Type == intstr.Int && (port.IntVal <= 0 || port.IntVal > 65535) {
+		return probe, fmt.Errorf("invalid --probe %q: port %d must be between 1 and 65535", spec, port.IntVal)
+	}
+	return probe, nil
+}
+
+// probeHandlerPort returns the port of the TCP or HTTP handler of probe, or nil for an exec handler.
+func probeHandlerPort(probe corev1.Probe) *intstr.IntOrString {
+	switch {
+	case probe.TCPSocket != nil:
+		return &probe.TCPSocket.Port
+	case probe.HTTPGet != nil:
+		return &probe.HTTPGet.Port
+	}
+	return nil
+}
+
+// parseMarkerStates parses the --marker-state flags of the form STATE:labels.KEY=VALUE or
+// STATE:annotations.KEY=VALUE into marker policies, in the order their states are first given.
+func parseMarkerStates(specs []string) ([]kruiseappsv1alpha1.ProbeMarkerPolicy, error) {
+	var policies []kruiseappsv1alpha1.ProbeMarkerPolicy
+	for _, spec := range specs {
+		state, marker, _ := strings.Cut(spec, ":")
+		key, value, found := strings.Cut(marker, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid --marker-state %q: must be of the form STATE:labels.KEY=VALUE or STATE:annotations.KEY=VALUE", spec)
+		}
+		switch kruiseappsv1alpha1.ProbeState(state) {
+		case kruiseappsv1alpha1.ProbeSucceeded, kruiseappsv1alpha1.ProbeFailed:
+		default:
+			return nil, fmt.Errorf("invalid --marker-state %q: the state must be one of %s or %s", spec, kruiseappsv1alpha1.ProbeSucceeded, kruiseappsv1alpha1.ProbeFailed)
+		}
+
+		var policy *kruiseappsv1alpha1.ProbeMarkerPolicy
+		for i := range policies {
+			if policies[i].State == kruiseappsv1alpha1.ProbeState(state) {
+				policy = &policies[i]
+			}
+		}
+		if policy == nil {
+			policies = append(policies, kruiseappsv1alpha1.ProbeMarkerPolicy{State: kruiseappsv1alpha1.ProbeState(state)})
+			policy = &policies[len(policies)-1]
+		}
+
+		if label := strings.TrimPrefix(key, "labels."); label != key && len(label) > 0 {
+			if policy.Labels == nil {
+				policy.Labels = map[string]string{}
+			}
+			policy.Labels[label] = value
+		} else if annotation := strings.TrimPrefix(key, "annotations."); annotation != key && len(annotation) > 0 {
+			if policy.Annotations == nil {
+				policy.Annotations = map[string]string{}
+			}
+			policy.Annotations[annotation] = value
+		} else {
+			return nil, fmt.Errorf("invalid --marker-state %q: must be of the form STATE:labels.KEY=VALUE or STATE:annotations.KEY=VALUE", spec)
+		}
+	}
+	return policies, nil
+}
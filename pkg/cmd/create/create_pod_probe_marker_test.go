@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestParseProbe(t *testing.T) {
+	testCases := []struct {
+		name        string
+		spec        string
+		expectProbe kruiseappsv1alpha1.PodContainerProbe
+		expectErr   string
+	}{
+		{
+			name: "tcp",
+			spec: "name=healthz,tcp=8080",
+			expectProbe: kruiseappsv1alpha1.PodContainerProbe{
+				Name:          "healthz",
+				ContainerName: "main",
+				Probe: kruiseappsv1alpha1.ContainerProbeSpec{Probe: corev1.Probe{ProbeHandler: corev1.ProbeHandler{
+					TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(8080)},
+				}}},
+			},
+		},
+		{
+			name: "tcp named port with container period and timeout",
+			spec: "name=healthz,container=sidecar,tcp=metrics,period=10,timeout=3",
+			expectProbe: kruiseappsv1alpha1.PodContainerProbe{
+				Name:          "healthz",
+				ContainerName: "sidecar",
+				Probe: kruiseappsv1alpha1.ContainerProbeSpec{Probe: corev1.Probe{
+					ProbeHandler:   corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString("metrics")}},
+					PeriodSeconds:  10,
+					TimeoutSeconds: 3,
+				}},
+			},
+		},
+		{
+			name: "http with path and condition",
+			spec: "name=healthy,http=8080/api/healthz,condition=game.kruise.io/healthy",
+			expectProbe: kruiseappsv1alpha1.PodContainerProbe{
+				Name:             "healthy",
+				ContainerName:    "main",
+				PodConditionType: "game.kruise.io/healthy",
+				Probe: kruiseappsv1alpha1.ContainerProbeSpec{Probe: corev1.Probe{ProbeHandler: corev1.ProbeHandler{
+					HTTPGet: &corev1.HTTPGetAction{Port: intstr.FromInt(8080), Path: "/api/healthz"},
+				}}},
+			},
+		},
+		{
+			name: "http without path",
+			spec: "name=healthy,http=8080",
+			expectProbe: kruiseappsv1alpha1.PodContainerProbe{
+				Name:          "healthy",
+				ContainerName: "main",
+				Probe: kruiseappsv1alpha1.ContainerProbeSpec{Probe: corev1.Probe{ProbeHandler: corev1.ProbeHandler{
+					HTTPGet: &corev1.HTTPGetAction{Port: intstr.FromInt(8080), Path: "/"},
+				}}},
+			},
+		},
+		{
+			name: "exec",
+			spec: "name=idle,exec=/bin/sh -c /check-idle.sh",
+			expectProbe: kruiseappsv1alpha1.PodContainerProbe{
+				Name:          "idle",
+				ContainerName: "main",
+				Probe: kruiseappsv1alpha1.ContainerProbeSpec{Probe: corev1.Probe{ProbeHandler: corev1.ProbeHandler{
+					Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", "/check-idle.sh"}},
+				}}},
+			},
+		},
+		{
+			name:      "missing name",
+			spec:      "tcp=8080",
+			expectErr: `invalid --probe "tcp=8080": a name must be specified`,
+		},
+		{
+			name:      "no handler",
+			spec:      "name=healthz",
+			expectErr: `invalid --probe "name=healthz": exactly one of tcp, http or exec must be specified`,
+		},
+		{
+			name:      "two handlers",
+			spec:      "name=healthz,tcp=8080,http=8080/healthz",
+			expectErr: `invalid --probe "name=healthz,tcp=8080,http=8080/healthz": exactly one of tcp, http or exec must be specified`,
+		},
+		{
+			name:      "tcp port out of range",
+			spec:      "name=healthz,tcp=70000",
+			expectErr: `invalid --probe "name=healthz,tcp=70000": port 70000 must be between 1 and 65535`,
+		},
+		{
+			name:      "http port out of range",
+			spec:      "name=healthz,http=0/healthz",
+			expectErr: `invalid --probe "name=healthz,http=0/healthz": port 0 must be between 1 and 65535`,
+		},
+		{
+			name:      "unknown key",
+			spec:      "name=healthz,udp=53",
+			expectErr: `invalid --probe "name=healthz,udp=53": unknown key "udp"`,
+		},
+		{
+			name:      "not a pair",
+			spec:      "name=healthz,tcp",
+			expectErr: `invalid --probe "name=healthz,tcp": "tcp" must be of the form key=value`,
+		},
+		{
+			name:      "invalid period",
+			spec:      "name=healthz,tcp=8080,period=0",
+			expectErr: `invalid --probe "name=healthz,tcp=8080,period=0": period must be a positive number of seconds, got "0"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			probe, err := parseProbe(tc.spec, "main")
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectProbe, probe)
+		})
+	}
+}
+
+func TestParseMarkerStates(t *testing.T) {
+	policies, err := parseMarkerStates([]string{
+		"Succeeded:labels.healthy=true",
+		"Failed:annotations.controller.kubernetes.io/pod-deletion-cost=-10",
+		"Succeeded:annotations.controller.kubernetes.io/pod-deletion-cost=10",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []kruiseappsv1alpha1.ProbeMarkerPolicy{
+		{
+			State:       kruiseappsv1alpha1.ProbeSucceeded,
+			Labels:      map[string]string{"healthy": "true"},
+			Annotations: map[string]string{"controller.kubernetes.io/pod-deletion-cost": "10"},
+		},
+		{
+			State:       kruiseappsv1alpha1.ProbeFailed,
+			Annotations: map[string]string{"controller.kubernetes.io/pod-deletion-cost": "-10"},
+		},
+	}, policies)
+
+	_, err = parseMarkerStates([]string{"Unknown:labels.healthy=false"})
+	assert.EqualError(t, err, `invalid --marker-state "Unknown:labels.healthy=false": the state must be one of Succeeded or Failed`)
+	_, err = parseMarkerStates([]string{"Succeeded:healthy=true"})
+	assert.EqualError(t, err, `invalid --marker-state "Succeeded:healthy=true": must be of the form STATE:labels.KEY=VALUE or STATE:annotations.KEY=VALUE`)
+}
+
+func TestCreatePodProbeMarkerValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		options   *CreatePodProbeMarkerOptions
+		expectErr string
+	}{
+		{
+			name:      "missing selector",
+			options:   &CreatePodProbeMarkerOptions{Probes: []string{"name=healthz,tcp=8080,condition=healthy"}},
+			expectErr: "--selector must be specified",
+		},
+		{
+			name:      "no probe",
+			options:   &CreatePodProbeMarkerOptions{Selector: "app=web"},
+			expectErr: "at least one --probe must be specified, e.g. --probe=name=healthz,tcp=8080",
+		},
+		{
+			name:      "missing container",
+			options:   &CreatePodProbeMarkerOptions{Selector: "app=web", Probes: []string{"name=healthz,tcp=8080,condition=healthy"}},
+			expectErr: `invalid --probe "name=healthz,tcp=8080,condition=healthy": a container must be specified, or --container`,
+		},
+		{
+			name: "duplicate probe name",
+			options: &CreatePodProbeMarkerOptions{Selector: "app=web", Container: "main", MarkerStates: []string{"Succeeded:labels.healthy=true"},
+				Probes: []string{"name=healthz,tcp=8080", "name=healthz,http=8080/healthz"}},
+			expectErr: `duplicate probe name "healthz"`,
+		},
+		{
+			name:      "neither condition nor marker state",
+			options:   &CreatePodProbeMarkerOptions{Selector: "app=web", Container: "main", Probes: []string{"name=healthz,tcp=8080"}},
+			expectErr: `probe "healthz" must set a condition, or --marker-state must be specified`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.options.Validate(), tc.expectErr)
+		})
+	}
+}
+
+func TestRunCreatePodProbeMarker(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdCreatePodProbeMarker(tf, streams)
+	o := NewCreatePodProbeMarkerOptions(streams)
+	o.Selector = "app=web"
+	o.Container = "main"
+	o.Probes = []string{"name=healthz,tcp=8080", "name=ready,http=8080/ready,condition=web.example.com/ready"}
+	o.MarkerStates = []string{"Succeeded:labels.healthy=true"}
+	assert.NoError(t, o.Complete(tf, cmd, []string{"web"}))
+	assert.NoError(t, o.Validate())
+	client := kruisefake.NewSimpleClientset()
+	o.kruisev1alpha1Client = client
+
+	assert.NoError(t, o.Run())
+	assert.Equal(t, "podprobemarker.apps.kruise.io/web created\n", out.String())
+
+	marker, err := client.AppsV1alpha1().PodProbeMarkers("test").Get(context.TODO(), "web", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "web"}, marker.Spec.Selector.MatchLabels)
+	if assert.Len(t, marker.Spec.Probes, 2) {
+		assert.Equal(t, "healthz", marker.Spec.Probes[0].Name)
+		assert.Equal(t, "main", marker.Spec.Probes[0].ContainerName)
+		assert.Equal(t, []kruiseappsv1alpha1.ProbeMarkerPolicy{{State: kruiseappsv1alpha1.ProbeSucceeded, Labels: map[string]string{"healthy": "true"}}}, marker.Spec.Probes[0].MarkerPolicy)
+		assert.Equal(t, "web.example.com/ready", marker.Spec.Probes[1].PodConditionType)
+	}
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	resourceDistributionLong = templates.LongDesc(i18n.T(`
+		Create a resourceDistribution with the specified name, which distributes a configmap or a secret
+		of the current namespace to other namespaces.
+
+		The namespaces are either listed with --to-namespaces, or selected by their labels with
+		--to-selector. The name, labels and data of the resource are copied into the resourceDistribution,
+		later changes of the resource are not distributed.`))
+
+	resourceDistributionExample = templates.Examples(i18n.T(`
+		# Distribute the configmap cfg to the namespaces a, b and c
+		kubectl kruise create resourcedistribution cfg --from=configmap/cfg --to-namespaces=a,b,c
+
+		# Distribute the secret regcred to the namespaces labeled with env=prod
+		kubectl kruise create resourcedistribution regcred --from=secret/regcred --to-selector=env=prod`))
+)
+
+// CreateResourceDistributionOptions is the command line options for 'create resourcedistribution'
+type CreateResourceDistributionOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+
+	PrintObj func(obj runtime.Object) error
+
+	Name         string
+	From         string
+	ToNamespaces []string
+	ToSelector   string
+
+	Namespace            string
+	kruisev1alpha1Client kruiseclientsets.Interface
+	DryRunStrategy       cmdutil.DryRunStrategy
+	Builder              *resource.Builder
+	FieldManager         string
+	CreateAnnotation     bool
+	ServerSideApplyOptions
+
+	genericclioptions.IOStreams
+}
+
+// NewCreateResourceDistributionOptions initializes and returns new CreateResourceDistributionOptions instance
+func NewCreateResourceDistributionOptions(ioStreams genericclioptions.IOStreams) *CreateResourceDistributionOptions {
+	return &CreateResourceDistributionOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("created").WithTypeSetter(internalapi.GetScheme()),
+		IOStreams:  ioStreams,
+	}
+}
+
+// NewCmdCreateResourceDistribution is a command to ease creating ResourceDistributions.
+func NewCmdCreateResourceDistribution(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCreateResourceDistributionOptions(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "resourcedistribution NAME --from=configmap/name|secret/name (--to-namespaces=ns1,ns2 | --to-selector=key=value)",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"resourceDistribution", "distributor"},
+		Short:                 i18n.T("Create a resourceDistribution distributing a configmap or a secret to other namespaces"),
+		Long:                  resourceDistributionLong,
+		Example:               resourceDistributionExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+
+	cmdutil.AddApplyAnnotationFlags(cmd)
+	cmdutil.AddValidateFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().StringVar(&o.From, "from", o.From, "The configmap or secret to distribute, e.g. configmap/cfg or secret/regcred.")
+	cmd.Flags().StringSliceVar(&o.ToNamespaces, "to-namespaces", o.ToNamespaces, "Comma separated names of the namespaces to distribute the resource to.")
+	cmd.Flags().StringVar(&o.ToSelector, "to-selector", o.ToSelector, "Label query over the namespaces to distribute the resource to, e.g. env=prod.")
+	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl kruise-create")
+	o.ServerSideApplyOptions.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *CreateResourceDistributionOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	name, err := NameFromCommandArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	o.Name = name
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.kruisev1alpha1Client, err = kruiseclientsets.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+
+	o.CreateAnnotation = cmdutil.GetFlagBool(cmd, cmdutil.ApplyAnnotationsFlag)
+
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	o.Builder = f.NewBuilder()
+
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	o.ServerSideApplyOptions.CompletePrintFlags(o.PrintFlags)
+	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	o.PrintObj = func(obj runtime.Object) error {
+		return printer.PrintObj(obj, o.Out)
+	}
+
+	return nil
+}
+
+// Validate makes sure provided values and valid ResourceDistribution options
+func (o *CreateResourceDistributionOptions) Validate() error {
+	if err := o.ServerSideApplyOptions.Validate(); err != nil {
+		return err
+	}
+	if len(o.From) == 0 {
+		return fmt.Errorf("--from must be specified, e.g. --from=configmap/cfg")
+	}
+	if !strings.Contains(o.From, "/") {
+		return fmt.Errorf("--from must be of the form TYPE/NAME, e.g. configmap/cfg, got %q", o.From)
+	}
+	if (len(o.ToNamespaces) == 0) == (len(o.ToSelector) == 0) {
+		return fmt.Errorf("exactly one of --to-namespaces or --to-selector must be specified")
+	}
+	for _, namespace := range o.ToNamespaces {
+		if len(namespace) == 0 {
+			return fmt.Errorf("--to-namespaces must not contain empty names")
+		}
+	}
+	if len(o.ToSelector) != 0 {
+		if _, err := metav1.ParseToLabelSelector(o.ToSelector); err != nil {
+			return fmt.Errorf("invalid --to-selector: %v", err)
+		}
+	}
+	return nil
+}
+
+// Run performs the execution of 'create resourcedistribution' sub command
+func (o *CreateResourceDistributionOptions) Run() error {
+	infos, err := o.Builder.
+		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		ResourceTypeOrNameArgs(false, o.From).
+		Flatten().
+		Latest().
+		Do().
+		Infos()
+	if err != nil {
+		return err
+	}
+	if len(infos) != 1 {
+		return fmt.Errorf("from must be an existing configmap or secret")
+	}
+
+	distribution, err := o.createResourceDistribution(infos[0].Object)
+	if err != nil {
+		return err
+	}
+
+	if err := util.CreateOrUpdateAnnotation(o.CreateAnnotation, distribution, scheme.DefaultJSONEncoder()); err != nil {
+		return err
+	}
+
+	if o.DryRunStrategy != cmdutil.DryRunClient {
+		if o.ServerSide {
+			data, patchOptions, err := o.ServerSideApplyOptions.ApplyPatch(distribution, o.FieldManager, o.DryRunStrategy)
+			if err != nil {
+				return err
+			}
+			distribution, err = o.kruisev1alpha1Client.AppsV1alpha1().ResourceDistributions().Patch(context.TODO(), distribution.Name, types.ApplyPatchType, data, patchOptions)
+			if err != nil {
+				return fmt.Errorf("failed to apply resourcedistribution: %v", err)
+			}
+		} else {
+			createOptions := metav1.CreateOptions{}
+			if o.FieldManager != "" {
+				createOptions.FieldManager = o.FieldManager
+			}
+			if o.DryRunStrategy == cmdutil.DryRunServer {
+				createOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			distribution, err = o.kruisev1alpha1Client.AppsV1alpha1().ResourceDistributions().Create(context.TODO(), distribution, createOptions)
+			if err != nil {
+				return fmt.Errorf("failed to create resourcedistribution: %v", err)
+			}
+		}
+	}
+
+	return o.PrintObj(distribution)
+}
+
+// createResourceDistribution returns a ResourceDistribution embedding a copy of obj, which keeps its name, labels
+// and data only, since the other metadata belong to the original resource.
+func (o *CreateResourceDistributionOptions) createResourceDistribution(obj runtime.Object) (*kruiseappsv1alpha1.ResourceDistribution, error) {
+	var distributed runtime.Object
+	switch obj := obj.(type) {
+	case *corev1.ConfigMap:
+		distributed = &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: obj.Name, Labels: obj.Labels},
+			Data:       obj.Data,
+			BinaryData: obj.BinaryData,
+		}
+	case *corev1.Secret:
+		distributed = &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: obj.Name, Labels: obj.Labels},
+			Type:       obj.Type,
+			Data:       obj.Data,
+		}
+	default:
+		return nil, fmt.Errorf("only configmaps and secrets can be distributed, got %T", obj)
+	}
+	raw, err := runtime.Encode(scheme.DefaultJSONEncoder(), distributed)
+	if err != nil {
+		return nil, err
+	}
+
+	distribution := &kruiseappsv1alpha1.ResourceDistribution{
+		// this is ok because we know exactly how we want to be serialized
+		TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "ResourceDistribution"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: o.Name,
+		},
+		Spec: kruiseappsv1alpha1.ResourceDistributionSpec{
+			Resource: runtime.RawExtension{Raw: raw},
+		},
+	}
+	for _, namespace := range o.ToNamespaces {
+		distribution.Spec.Targets.IncludedNamespaces.List = append(distribution.Spec.Targets.IncludedNamespaces.List,
+			kruiseappsv1alpha1.ResourceDistributionNamespace{Name: namespace})
+	}
+	if len(o.ToSelector) != 0 {
+		selector, err := metav1.ParseToLabelSelector(o.ToSelector)
+		if err != nil {
+			return nil, err
+		}
+		distribution.Spec.Targets.NamespaceLabelSelector = *selector
+	}
+	return distribution, nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestRunCreateResourceDistribution(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"/namespaces/test/configmaps/cfg": &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cfg", Namespace: "test", Labels: map[string]string{"app": "web"}, ResourceVersion: "42"},
+			Data:       map[string]string{"level": "debug"},
+		},
+		"/namespaces/test/secrets/regcred": &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "regcred", Namespace: "test", ResourceVersion: "7"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte("{}")},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		from           string
+		toNamespaces   []string
+		toSelector     string
+		expectResource string
+		expectTargets  kruiseappsv1alpha1.ResourceDistributionTargets
+	}{
+		{
+			name:           "configmap to namespaces",
+			from:           "configmap/cfg",
+			toNamespaces:   []string{"a", "b", "c"},
+			expectResource: `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cfg","creationTimestamp":null,"labels":{"app":"web"}},"data":{"level":"debug"}}`,
+			expectTargets: kruiseappsv1alpha1.ResourceDistributionTargets{
+				IncludedNamespaces: kruiseappsv1alpha1.ResourceDistributionTargetNamespaces{
+					List: []kruiseappsv1alpha1.ResourceDistributionNamespace{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+				},
+			},
+		},
+		{
+			name:           "secret to selected namespaces",
+			from:           "secret/regcred",
+			toSelector:     "env=prod",
+			expectResource: `{"kind":"Secret","apiVersion":"v1","metadata":{"name":"regcred","creationTimestamp":null},"data":{".dockerconfigjson":"e30="},"type":"kubernetes.io/dockerconfigjson"}`,
+			expectTargets: kruiseappsv1alpha1.ResourceDistributionTargets{
+				NamespaceLabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}, MatchExpressions: []metav1.LabelSelectorRequirement{}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			tf.Client = &fake.RESTClient{
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					if obj, ok := objs[req.URL.Path]; ok && req.Method == http.MethodGet {
+						codec := scheme.Codecs.LegacyCodec(corev1.SchemeGroupVersion)
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+					}
+					t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+					return nil, nil
+				}),
+			}
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdCreateResourceDistribution(tf, streams)
+			o := NewCreateResourceDistributionOptions(streams)
+			o.From = tc.from
+			o.ToNamespaces = tc.toNamespaces
+			o.ToSelector = tc.toSelector
+			assert.NoError(t, o.Complete(tf, cmd, []string{"shared"}))
+			assert.NoError(t, o.Validate())
+			client := kruisefake.NewSimpleClientset()
+			o.kruisev1alpha1Client = client
+
+			assert.NoError(t, o.Run())
+			assert.Equal(t, "resourcedistribution.apps.kruise.io/shared created\n", out.String())
+
+			distribution, err := client.AppsV1alpha1().ResourceDistributions().Get(context.TODO(), "shared", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.expectResource, string(distribution.Spec.Resource.Raw))
+			assert.Equal(t, tc.expectTargets, distribution.Spec.Targets)
+		})
+	}
+}
+
+func TestCreateResourceDistributionValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		options   *CreateResourceDistributionOptions
+		expectErr string
+	}{
+		{
+			name:      "missing from",
+			options:   &CreateResourceDistributionOptions{ToNamespaces: []string{"a"}},
+			expectErr: "--from must be specified, e.g. --from=configmap/cfg",
+		},
+		{
+			name:      "from without name",
+			options:   &CreateResourceDistributionOptions{From: "configmap", ToNamespaces: []string{"a"}},
+			expectErr: `--from must be of the form TYPE/NAME, e.g. configmap/cfg, got "configmap"`,
+		},
+		{
+			name:      "no target",
+			options:   &CreateResourceDistributionOptions{From: "configmap/cfg"},
+			expectErr: "exactly one of --to-namespaces or --to-selector must be specified",
+		},
+		{
+			name:      "both targets",
+			options:   &CreateResourceDistributionOptions{From: "configmap/cfg", ToNamespaces: []string{"a"}, ToSelector: "env=prod"},
+			expectErr: "exactly one of --to-namespaces or --to-selector must be specified",
+		},
+		{
+			name:      "empty namespace",
+			options:   &CreateResourceDistributionOptions{From: "configmap/cfg", ToNamespaces: []string{"a", ""}},
+			expectErr: "--to-namespaces must not contain empty names",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.options.Validate(), tc.expectErr)
+		})
+	}
+}
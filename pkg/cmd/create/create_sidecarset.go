@@ -0,0 +1,335 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	sidecarSetLong = templates.LongDesc(i18n.T(`
+		Create a sidecarSet with the specified name, injecting a sidecar container into the pods it selects.
+
+		The sidecar container runs --image and is named after it, unless --container-name is given.
+		--selector is required, since a sidecarSet without a selector injects its containers into every
+		pod of the cluster.
+
+		--volume=NAME:MOUNT_PATH adds an emptyDir volume to the pods and mounts it in the sidecar
+		container, e.g. to share the logs the containers of the pod write. --init-container=NAME=IMAGE
+		injects an init container which runs before the containers of the pod.`))
+
+	sidecarSetExample = templates.Examples(i18n.T(`
+		# Inject the agent:v1 image as a container named agent into the pods labeled with app=web
+		kubectl kruise create sidecarset web-agent --image=agent:v1 --selector=app=web --container-name=agent
+
+		# Share a volume mounted at /var/log between the sidecar and the pods it is injected into
+		kubectl kruise create sidecarset web-logs --image=fluent-bit:2.2 --selector=app=web --volume=logs:/var/log
+
+		# Inject an init container preparing the configuration of the sidecar
+		kubectl kruise create sidecarset web-agent --image=agent:v1 --selector=app=web --init-container=init-agent=agent-init:v1
+
+		# Print the generated sidecarset without creating it
+		kubectl kruise create sidecarset web-agent --image=agent:v1 --selector=app=web --dry-run=client -o yaml`))
+)
+
+// CreateSidecarSetOptions is the command line options for 'create sidecarset'
+type CreateSidecarSetOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+
+	PrintObj func(obj runtime.Object) error
+
+	Name           string
+	Image          string
+	Selector       string
+	ContainerName  string
+	Volumes        []string
+	InitContainers []string
+
+	kruisev1alpha1Client kruiseclientsets.Interface
+	DryRunStrategy       cmdutil.DryRunStrategy
+	FieldManager         string
+	CreateAnnotation     bool
+	ServerSideApplyOptions
+
+	genericclioptions.IOStreams
+}
+
+// NewCreateSidecarSetOptions initializes and returns new CreateSidecarSetOptions instance
+func NewCreateSidecarSetOptions(ioStreams genericclioptions.IOStreams) *CreateSidecarSetOptions {
+	return &CreateSidecarSetOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("created").WithTypeSetter(internalapi.GetScheme()),
+		IOStreams:  ioStreams,
+	}
+}
+
+// NewCmdCreateSidecarSet is a command to ease creating SidecarSets.
+func NewCmdCreateSidecarSet(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCreateSidecarSetOptions(ioStreams)
+	cmd := &cobra.Command{
+		Use:                   "sidecarset NAME --image=image --selector=key=value [--container-name=name] [--volume=NAME:MOUNT_PATH] [--init-container=NAME=IMAGE]",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"sidecarSet"},
+		Short:                 i18n.T("Create a sidecarSet with the specified name"),
+		Long:                  sidecarSetLong,
+		Example:               sidecarSetExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+
+	cmdutil.AddApplyAnnotationFlags(cmd)
+	cmdutil.AddValidateFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().StringVar(&o.Image, "image", o.Image, "Image of the sidecar container.")
+	cmd.Flags().StringVar(&o.Selector, "selector", o.Selector, "Label query over the pods to inject the sidecar container into, e.g. app=web.")
+	cmd.Flags().StringVar(&o.ContainerName, "container-name", o.ContainerName, "Name of the sidecar container. Defaults to the name of the image.")
+	cmd.Flags().StringArrayVar(&o.Volumes, "volume", o.Volumes, "An emptyDir volume mounted in the sidecar container, as NAME:MOUNT_PATH, e.g. logs:/var/log. Can be repeated.")
+	cmd.Flags().StringArrayVar(&o.InitContainers, "init-container", o.InitContainers, "An init container to inject, as NAME=IMAGE, e.g. init-agent=agent-init:v1. Can be repeated.")
+	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl kruise-create")
+	o.ServerSideApplyOptions.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *CreateSidecarSetOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	name, err := NameFromCommandArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	o.Name = name
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.kruisev1alpha1Client, err = kruiseclientsets.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+
+	o.CreateAnnotation = cmdutil.GetFlagBool(cmd, cmdutil.ApplyAnnotationsFlag)
+
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	o.ServerSideApplyOptions.CompletePrintFlags(o.PrintFlags)
+	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	o.PrintObj = func(obj runtime.Object) error {
+		return printer.PrintObj(obj, o.Out)
+	}
+
+	return nil
+}
+
+// Validate makes sure provided values and valid SidecarSet options
+func (o *CreateSidecarSetOptions) Validate() error {
+	if err := o.ServerSideApplyOptions.Validate(); err != nil {
+		return err
+	}
+	if len(o.Image) == 0 {
+		return fmt.Errorf("--image must be specified")
+	}
+	selector, err := metav1.ParseToLabelSelector(o.Selector)
+	if err != nil {
+		return fmt.Errorf("invalid --selector: %v", err)
+	}
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		return fmt.Errorf("--selector must be specified, a sidecarSet without a selector is injected into every pod")
+	}
+
+	names := sets.NewString()
+	addName := func(name, flag string) error {
+		if errs := utilvalidation.IsDNS1123Label(name); len(errs) != 0 {
+			return fmt.Errorf("invalid %s: container name %q: %s", flag, name, strings.Join(errs, ", "))
+		}
+		if names.Has(name) {
+			return fmt.Errorf("invalid %s: duplicate container name %q", flag, name)
+		}
+		names.Insert(name)
+		return nil
+	}
+	if err := addName(o.sidecarContainerName(), "--container-name"); err != nil {
+		return err
+	}
+	for _, spec := range o.InitContainers {
+		container, err := parseInitContainer(spec)
+		if err != nil {
+			return err
+		}
+		if err := addName(container.Name, "--init-container"); err != nil {
+			return err
+		}
+	}
+
+	volumes := sets.NewString()
+	for _, spec := range o.Volumes {
+		mount, err := parseSidecarVolume(spec)
+		if err != nil {
+			return err
+		}
+		if volumes.Has(mount.Name) {
+			return fmt.Errorf("invalid --volume %q: duplicate volume name %q", spec, mount.Name)
+		}
+		volumes.Insert(mount.Name)
+	}
+	return nil
+}
+
+// Run performs the execution of 'create sidecarset' sub command
+func (o *CreateSidecarSetOptions) Run() error {
+	sidecarSet, err := o.createSidecarSet()
+	if err != nil {
+		return err
+	}
+
+	if err := util.CreateOrUpdateAnnotation(o.CreateAnnotation, sidecarSet, scheme.DefaultJSONEncoder()); err != nil {
+		return err
+	}
+
+	if o.DryRunStrategy != cmdutil.DryRunClient {
+		if o.ServerSide {
+			data, patchOptions, err := o.ServerSideApplyOptions.ApplyPatch(sidecarSet, o.FieldManager, o.DryRunStrategy)
+			if err != nil {
+				return err
+			}
+			sidecarSet, err = o.kruisev1alpha1Client.AppsV1alpha1().SidecarSets().Patch(context.TODO(), sidecarSet.Name, types.ApplyPatchType, data, patchOptions)
+			if err != nil {
+				return fmt.Errorf("failed to apply sidecarset: %v", err)
+			}
+		} else {
+			createOptions := metav1.CreateOptions{}
+			if o.FieldManager != "" {
+				createOptions.FieldManager = o.FieldManager
+			}
+			if o.DryRunStrategy == cmdutil.DryRunServer {
+				createOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			sidecarSet, err = o.kruisev1alpha1Client.AppsV1alpha1().SidecarSets().Create(context.TODO(), sidecarSet, createOptions)
+			if err != nil {
+				return fmt.Errorf("failed to create sidecarset: %v", err)
+			}
+		}
+	}
+
+	return o.PrintObj(sidecarSet)
+}
+
+func (o *CreateSidecarSetOptions) createSidecarSet() (*kruiseappsv1alpha1.SidecarSet, error) {
+	selector, err := metav1.ParseToLabelSelector(o.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	container := kruiseappsv1alpha1.SidecarContainer{
+		Container: corev1.Container{
+			Name:  o.sidecarContainerName(),
+			Image: o.Image,
+		},
+	}
+	// SidecarSets are cluster scoped, so the namespace of the context is not set
+	sidecarSet := &kruiseappsv1alpha1.SidecarSet{
+		// this is ok because we know exactly how we want to be serialized
+		TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "SidecarSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: o.Name,
+		},
+		Spec: kruiseappsv1alpha1.SidecarSetSpec{
+			Selector: selector,
+		},
+	}
+	for _, spec := range o.Volumes {
+		mount, err := parseSidecarVolume(spec)
+		if err != nil {
+			return nil, err
+		}
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+		sidecarSet.Spec.Volumes = append(sidecarSet.Spec.Volumes, corev1.Volume{
+			Name:         mount.Name,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+	sidecarSet.Spec.Containers = []kruiseappsv1alpha1.SidecarContainer{container}
+	for _, spec := range o.InitContainers {
+		initContainer, err := parseInitContainer(spec)
+		if err != nil {
+			return nil, err
+		}
+		sidecarSet.Spec.InitContainers = append(sidecarSet.Spec.InitContainers, kruiseappsv1alpha1.SidecarContainer{Container: initContainer})
+	}
+	return sidecarSet, nil
+}
+
+// sidecarContainerName returns --container-name, or the name derived from the image of the sidecar container.
+func (o *CreateSidecarSetOptions) sidecarContainerName() string {
+	if len(o.ContainerName) > 0 {
+		return o.ContainerName
+	}
+	return containerNameFromImage(o.Image)
+}
+
+// parseSidecarVolume parses a --volume of the form NAME:MOUNT_PATH into the mount of the volume.
+func parseSidecarVolume(spec string) (corev1.VolumeMount, error) {
+	name, mountPath, found := strings.Cut(spec, ":")
+	if !found || len(name) == 0 || len(mountPath) == 0 {
+		return corev1.VolumeMount{}, fmt.Errorf("invalid --volume %q: must be of the form NAME:MOUNT_PATH", spec)
+	}
+	if errs := utilvalidation.IsDNS1123Label(name); len(errs) != 0 {
+		return corev1.VolumeMount{}, fmt.Errorf("invalid --volume %q: %s", spec, strings.Join(errs, ", "))
+	}
+	if !path.IsAbs(mountPath) {
+		return corev1.VolumeMount{}, fmt.Errorf("invalid --volume %q: the mount path must be absolute", spec)
+	}
+	return corev1.VolumeMount{Name: name, MountPath: mountPath}, nil
+}
+
+// parseInitContainer parses an --init-container of the form NAME=IMAGE.
+func parseInitContainer(spec string) (corev1.Container, error) {
+	name, image, found := strings.Cut(spec, "=")
+	if !found || len(name) == 0 || len(image) == 0 {
+		return corev1.Container{}, fmt.Errorf("invalid --init-container %q: must be of the form NAME=IMAGE", spec)
+	}
+	return corev1.Container{Name: name, Image: image}, nil
+}
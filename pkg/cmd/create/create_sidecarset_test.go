@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestCreateSidecarSetGenerate(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		options              *CreateSidecarSetOptions
+		expectSelector       *metav1.LabelSelector
+		expectContainers     []kruiseappsv1alpha1.SidecarContainer
+		expectInitContainers []kruiseappsv1alpha1.SidecarContainer
+		expectVolumes        []corev1.Volume
+	}{
+		{
+			name:           "container name",
+			options:        &CreateSidecarSetOptions{Image: "agent:v1", Selector: "app=web", ContainerName: "agent"},
+			expectSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}, MatchExpressions: []metav1.LabelSelectorRequirement{}},
+			expectContainers: []kruiseappsv1alpha1.SidecarContainer{
+				{Container: corev1.Container{Name: "agent", Image: "agent:v1"}},
+			},
+		},
+		{
+			name: "container named after the image, with volumes and an init container",
+			options: &CreateSidecarSetOptions{Image: "registry.example.com/fluent-bit:2.2", Selector: "app in (web, api)",
+				Volumes: []string{"logs:/var/log", "buffer:/buffer"}, InitContainers: []string{"init-config=config-init:v1"}},
+			expectSelector: &metav1.LabelSelector{MatchLabels: map[string]string{}, MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"api", "web"}},
+			}},
+			expectContainers: []kruiseappsv1alpha1.SidecarContainer{
+				{Container: corev1.Container{
+					Name:  "fluent-bit",
+					Image: "registry.example.com/fluent-bit:2.2",
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "logs", MountPath: "/var/log"},
+						{Name: "buffer", MountPath: "/buffer"},
+					},
+				}},
+			},
+			expectInitContainers: []kruiseappsv1alpha1.SidecarContainer{
+				{Container: corev1.Container{Name: "init-config", Image: "config-init:v1"}},
+			},
+			expectVolumes: []corev1.Volume{
+				{Name: "logs", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				{Name: "buffer", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.options.Name = "web-agent"
+			assert.NoError(t, tc.options.Validate())
+
+			sidecarSet, err := tc.options.createSidecarSet()
+			assert.NoError(t, err)
+			assert.Equal(t, "web-agent", sidecarSet.Name)
+			assert.Empty(t, sidecarSet.Namespace)
+			assert.Equal(t, tc.expectSelector, sidecarSet.Spec.Selector)
+			assert.Equal(t, tc.expectContainers, sidecarSet.Spec.Containers)
+			assert.Equal(t, tc.expectInitContainers, sidecarSet.Spec.InitContainers)
+			assert.Equal(t, tc.expectVolumes, sidecarSet.Spec.Volumes)
+		})
+	}
+}
+
+func TestCreateSidecarSetValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		options   *CreateSidecarSetOptions
+		expectErr string
+	}{
+		{
+			name:      "missing image",
+			options:   &CreateSidecarSetOptions{Selector: "app=web"},
+			expectErr: "--image must be specified",
+		},
+		{
+			name:      "missing selector",
+			options:   &CreateSidecarSetOptions{Image: "agent:v1"},
+			expectErr: "--selector must be specified, a sidecarSet without a selector is injected into every pod",
+		},
+		{
+			name:      "invalid selector",
+			options:   &CreateSidecarSetOptions{Image: "agent:v1", Selector: "app=web=api"},
+			expectErr: `invalid --selector: couldn't parse the selector string "app=web=api": found '=', expected: ',' or 'end of string'`,
+		},
+		{
+			name:      "invalid container name",
+			options:   &CreateSidecarSetOptions{Image: "agent:v1", Selector: "app=web", ContainerName: "Agent"},
+			expectErr: `invalid --container-name: container name "Agent": a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		},
+		{
+			name:      "init container named as the sidecar",
+			options:   &CreateSidecarSetOptions{Image: "agent:v1", Selector: "app=web", InitContainers: []string{"agent=agent-init:v1"}},
+			expectErr: `invalid --init-container: duplicate container name "agent"`,
+		},
+		{
+			name:      "init container without image",
+			options:   &CreateSidecarSetOptions{Image: "agent:v1", Selector: "app=web", InitContainers: []string{"init-agent"}},
+			expectErr: `invalid --init-container "init-agent": must be of the form NAME=IMAGE`,
+		},
+		{
+			name:      "volume without mount path",
+			options:   &CreateSidecarSetOptions{Image: "agent:v1", Selector: "app=web", Volumes: []string{"logs"}},
+			expectErr: `invalid --volume "logs": must be of the form NAME:MOUNT_PATH`,
+		},
+		{
+			name:      "relative mount path",
+			options:   &CreateSidecarSetOptions{Image: "agent:v1", Selector: "app=web", Volumes: []string{"logs:var/log"}},
+			expectErr: `invalid --volume "logs:var/log": the mount path must be absolute`,
+		},
+		{
+			name:      "duplicate volume",
+			options:   &CreateSidecarSetOptions{Image: "agent:v1", Selector: "app=web", Volumes: []string{"logs:/var/log", "logs:/logs"}},
+			expectErr: `invalid --volume "logs:/logs": duplicate volume name "logs"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.options.Validate(), tc.expectErr)
+		})
+	}
+}
+
+func TestRunCreateSidecarSet(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdCreateSidecarSet(tf, streams)
+	o := NewCreateSidecarSetOptions(streams)
+	o.Image = "agent:v1"
+	o.Selector = "app=web"
+	o.ContainerName = "agent"
+	assert.NoError(t, o.Complete(tf, cmd, []string{"web-agent"}))
+	assert.NoError(t, o.Validate())
+	client := kruisefake.NewSimpleClientset()
+	o.kruisev1alpha1Client = client
+
+	assert.NoError(t, o.Run())
+	assert.Equal(t, "sidecarset.apps.kruise.io/web-agent created\n", out.String())
+
+	sidecarSet, err := client.AppsV1alpha1().SidecarSets().Get(context.TODO(), "web-agent", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "web"}, sidecarSet.Spec.Selector.MatchLabels)
+	if assert.Len(t, sidecarSet.Spec.Containers, 1) {
+		assert.Equal(t, "agent", sidecarSet.Spec.Containers[0].Name)
+		assert.Equal(t, "agent:v1", sidecarSet.Spec.Containers[0].Image)
+	}
+}
@@ -26,11 +26,14 @@ import (
 
 var (
 	describeLong = templates.LongDesc(i18n.T(`
-		Show details of a rollout.`))
+		Show details of a rollout or a cloneset.`))
 
 	describeExample = templates.Examples(`
 		# Describe the rollout named rollout-demo
-		kubectl-kruise describe rollout rollout-demo`)
+		kubectl-kruise describe rollout rollout-demo
+
+		# Describe the cloneset named web
+		kubectl-kruise describe cloneset web`)
 )
 
 // NewCmdRollout returns a Command instance for 'rollout' sub command
@@ -45,6 +48,7 @@ func NewCmdDescibe(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 	}
 	// subcommands
 	cmd.AddCommand(NewCmdDescribeRollout(f, streams))
+	cmd.AddCommand(NewCmdDescribeCloneSet(f, streams))
 
 	return cmd
 }
@@ -0,0 +1,383 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	kruiseappspub "github.com/openkruise/kruise-api/apps/pub"
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	internalpolymorphichelpers "github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/scheme"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	cloneSetLong = templates.LongDesc(i18n.T(`
+		Show details of a cloneset: its replicas and revisions, its update strategy and partition, the
+		readiness gates of its pods, a summary of its pod template, and the rollouts and workloadspreads
+		referencing it.`))
+
+	cloneSetExample = templates.Examples(`
+		# Describe the cloneset named web
+		kubectl-kruise describe cloneset web
+
+		# Describe the clonesets named web and api within namespace prod
+		kubectl-kruise describe cloneset web api -n prod`)
+)
+
+var cloneSetGroupKind = schema.GroupKind{Group: kruiseappsv1alpha1.GroupVersion.Group, Kind: "CloneSet"}
+
+type DescribeCloneSetOptions struct {
+	genericclioptions.IOStreams
+	Builder          func() *resource.Builder
+	Namespace        string
+	EnforceNamespace bool
+	Resources        []string
+}
+
+func NewCmdDescribeCloneSet(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &DescribeCloneSetOptions{IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:                   "cloneset NAME...",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Get details about a cloneset"),
+		Long:                  cloneSetLong,
+		Example:               cloneSetExample,
+		Aliases:               []string{"clonesets", "clone"},
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+	return cmd
+}
+
+func (o *DescribeCloneSetOptions) Complete(f cmdutil.Factory, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("required cloneset name not specified")
+	}
+	o.Resources = args
+
+	var err error
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	o.Builder = f.NewBuilder
+	return nil
+}
+
+func (o *DescribeCloneSetOptions) Run() error {
+	r := o.Builder().
+		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		ResourceNames("clonesets.apps.kruise.io", o.Resources...).
+		ContinueOnError().
+		Latest().
+		Flatten().
+		Do()
+
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	first := true
+	return r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		cloneSet, ok := info.Object.(*kruiseappsv1alpha1.CloneSet)
+		if !ok {
+			return fmt.Errorf("expected *kruiseappsv1alpha1.CloneSet, got %T", info.Object)
+		}
+		if !first {
+			fmt.Fprintln(o.Out)
+		}
+		first = false
+		o.printCloneSet(cloneSet)
+		return nil
+	})
+}
+
+func (o *DescribeCloneSetOptions) printCloneSet(cloneSet *kruiseappsv1alpha1.CloneSet) {
+	fmt.Fprintf(o.Out, tableFormat, "Name:", cloneSet.Name)
+	fmt.Fprintf(o.Out, tableFormat, "Namespace:", cloneSet.Namespace)
+	selector := cloneSet.Status.LabelSelector
+	if len(selector) == 0 && cloneSet.Spec.Selector != nil {
+		selector = labels.Set(cloneSet.Spec.Selector.MatchLabels).String()
+	}
+	fmt.Fprintf(o.Out, tableFormat, "Selector:", valueOrNone(selector))
+
+	// Print replicas
+	desired := int32(1)
+	if cloneSet.Spec.Replicas != nil {
+		desired = *cloneSet.Spec.Replicas
+	}
+	status := cloneSet.Status
+	fmt.Fprint(o.Out, "Replicas:\n")
+	fmt.Fprintf(o.Out, tableFormat, " Desired:", desired)
+	fmt.Fprintf(o.Out, tableFormat, " Current:", status.Replicas)
+	fmt.Fprintf(o.Out, tableFormat, " Updated:", fmt.Sprintf("%d (%d ready)", status.UpdatedReplicas, status.UpdatedReadyReplicas))
+	fmt.Fprintf(o.Out, tableFormat, " Ready:", status.ReadyReplicas)
+	fmt.Fprintf(o.Out, tableFormat, " Available:", status.AvailableReplicas)
+	if status.ExpectedUpdatedReplicas > 0 {
+		fmt.Fprintf(o.Out, tableFormat, " Expected Updated:", status.ExpectedUpdatedReplicas)
+	}
+
+	// Print revisions
+	fmt.Fprintf(o.Out, tableFormat, "Current Revision:", valueOrNone(status.CurrentRevision))
+	fmt.Fprintf(o.Out, tableFormat, "Update Revision:", valueOrNone(status.UpdateRevision))
+
+	o.printUpdateStrategy(cloneSet.Spec.UpdateStrategy)
+	o.printReadinessGates(cloneSet)
+	o.printPodTemplate(&cloneSet.Spec.Template)
+	o.printCloneSetRollouts(cloneSet)
+	o.printCloneSetWorkloadSpreads(cloneSet)
+
+	if len(status.Conditions) > 0 {
+		o.printCloneSetConditions(status.Conditions)
+	}
+}
+
+func (o *DescribeCloneSetOptions) printUpdateStrategy(strategy kruiseappsv1alpha1.CloneSetUpdateStrategy) {
+	strategyType := string(strategy.Type)
+	if len(strategyType) == 0 {
+		// the default of the Kruise webhook
+		strategyType = string(kruiseappsv1alpha1.RecreateCloneSetUpdateStrategyType)
+	}
+	fmt.Fprintf(o.Out, tableFormat, "Update Strategy:", strategyType)
+	// a partition is the number or the percentage of the pods kept at the current revision
+	fmt.Fprintf(o.Out, tableFormat, " Partition:", intOrStringOr(strategy.Partition, "0"))
+	fmt.Fprintf(o.Out, tableFormat, " Max Unavailable:", intOrStringOr(strategy.MaxUnavailable, "<unset>"))
+	fmt.Fprintf(o.Out, tableFormat, " Max Surge:", intOrStringOr(strategy.MaxSurge, "<unset>"))
+	fmt.Fprintf(o.Out, tableFormat, " Paused:", strategy.Paused)
+	if strategy.InPlaceUpdateStrategy != nil && strategy.InPlaceUpdateStrategy.GracePeriodSeconds > 0 {
+		fmt.Fprintf(o.Out, tableFormat, " In-place Grace:", fmt.Sprintf("%ds", strategy.InPlaceUpdateStrategy.GracePeriodSeconds))
+	}
+	if priority := strategy.PriorityStrategy; priority != nil {
+		if len(priority.WeightPriority) > 0 {
+			fmt.Fprintf(o.Out, tableFormat, " Priority:", fmt.Sprintf("by weight of %d terms", len(priority.WeightPriority)))
+		} else if len(priority.OrderPriority) > 0 {
+			var keys []string
+			for _, term := range priority.OrderPriority {
+				keys = append(keys, term.OrderedKey)
+			}
+			fmt.Fprintf(o.Out, tableFormat, " Priority:", "by order of "+strings.Join(keys, ", "))
+		}
+	}
+	if len(strategy.ScatterStrategy) > 0 {
+		var terms []string
+		for _, term := range strategy.ScatterStrategy {
+			terms = append(terms, term.Key+"="+term.Value)
+		}
+		printList(o.Out, " Scatter:", terms)
+	}
+}
+
+// printReadinessGates prints the readiness gates of the pod template, along with the one Kruise adds to the pods of
+// a cloneset which may update them in place, which keeps a pod unready while its containers are restarted.
+func (o *DescribeCloneSetOptions) printReadinessGates(cloneSet *kruiseappsv1alpha1.CloneSet) {
+	var gates []string
+	if cloneSet.Spec.UpdateStrategy.Type == kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType ||
+		cloneSet.Spec.UpdateStrategy.Type == kruiseappsv1alpha1.InPlaceOnlyCloneSetUpdateStrategyType {
+		gates = append(gates, string(kruiseappspub.InPlaceUpdateReady)+" (added by Kruise for in-place updates)")
+	}
+	for _, gate := range cloneSet.Spec.Template.Spec.ReadinessGates {
+		if gate.ConditionType == kruiseappspub.InPlaceUpdateReady && len(gates) > 0 {
+			continue
+		}
+		gates = append(gates, string(gate.ConditionType))
+	}
+	printList(o.Out, "Readiness Gates:", gates)
+}
+
+func (o *DescribeCloneSetOptions) printPodTemplate(template *corev1.PodTemplateSpec) {
+	fmt.Fprint(o.Out, "Pod Template:\n")
+	fmt.Fprintf(o.Out, tableFormat, " Labels:", valueOrNone(labels.Set(template.Labels).String()))
+	if len(template.Spec.ServiceAccountName) > 0 {
+		fmt.Fprintf(o.Out, tableFormat, " Service Account:", template.Spec.ServiceAccountName)
+	}
+	if template.Spec.TerminationGracePeriodSeconds != nil {
+		fmt.Fprintf(o.Out, tableFormat, " Grace Period:", fmt.Sprintf("%ds", *template.Spec.TerminationGracePeriodSeconds))
+	}
+	if len(template.Spec.InitContainers) > 0 {
+		fmt.Fprint(o.Out, " Init Containers:\n")
+		o.printContainers(template.Spec.InitContainers)
+	}
+	fmt.Fprint(o.Out, " Containers:\n")
+	o.printContainers(template.Spec.Containers)
+	if len(template.Spec.Volumes) > 0 {
+		var volumes []string
+		for _, volume := range template.Spec.Volumes {
+			volumes = append(volumes, volume.Name)
+		}
+		fmt.Fprintf(o.Out, tableFormat, " Volumes:", strings.Join(volumes, ", "))
+	}
+}
+
+func (o *DescribeCloneSetOptions) printContainers(containers []corev1.Container) {
+	for _, container := range containers {
+		fmt.Fprintf(o.Out, "  %s:\n", container.Name)
+		fmt.Fprintf(o.Out, tableFormat, "   Image:", container.Image)
+		if len(container.Ports) > 0 {
+			var ports []string
+			for _, port := range container.Ports {
+				protocol := port.Protocol
+				if len(protocol) == 0 {
+					protocol = corev1.ProtocolTCP
+				}
+				ports = append(ports, fmt.Sprintf("%d/%s", port.ContainerPort, protocol))
+			}
+			fmt.Fprintf(o.Out, tableFormat, "   Ports:", strings.Join(ports, ", "))
+		}
+		if len(container.Resources.Requests) > 0 {
+			fmt.Fprintf(o.Out, tableFormat, "   Requests:", formatResourceList(container.Resources.Requests))
+		}
+		if len(container.Resources.Limits) > 0 {
+			fmt.Fprintf(o.Out, tableFormat, "   Limits:", formatResourceList(container.Resources.Limits))
+		}
+	}
+}
+
+// printCloneSetRollouts prints the rollouts referencing the cloneset, with the phase and the step they are at. The
+// rollouts are left out if they cannot be listed, e.g. if Kruise Rollout is not installed.
+func (o *DescribeCloneSetOptions) printCloneSetRollouts(cloneSet *kruiseappsv1alpha1.CloneSet) {
+	infos, err := o.listRelated(cloneSet.Namespace, "rollouts.rollouts.kruise.io")
+	if err != nil {
+		fmt.Fprintf(o.Out, tableFormat, "Rollouts:", fmt.Sprintf("<unknown: %v>", err))
+		return
+	}
+	var rollouts []string
+	for _, info := range infos {
+		gvk, name, err := internalpolymorphichelpers.ResolveWorkloadRef(info.Object)
+		if err != nil || gvk.GroupKind() != cloneSetGroupKind || name != cloneSet.Name {
+			continue
+		}
+		rollout := extractRolloutInfo(info.Object)
+		summary := rollout.Name
+		if len(rollout.Phase) > 0 {
+			summary += fmt.Sprintf(" (%s, step %d/%d)", rollout.Phase, rollout.CurrentStepIndex, len(rollout.Strategy.Steps))
+		}
+		rollouts = append(rollouts, summary)
+	}
+	printList(o.Out, "Rollouts:", rollouts)
+}
+
+// printCloneSetWorkloadSpreads prints the workloadspreads spreading the pods of the cloneset, with their subsets.
+func (o *DescribeCloneSetOptions) printCloneSetWorkloadSpreads(cloneSet *kruiseappsv1alpha1.CloneSet) {
+	infos, err := o.listRelated(cloneSet.Namespace, "workloadspreads.apps.kruise.io")
+	if err != nil {
+		fmt.Fprintf(o.Out, tableFormat, "WorkloadSpreads:", fmt.Sprintf("<unknown: %v>", err))
+		return
+	}
+	var workloadSpreads []string
+	for _, info := range infos {
+		workloadSpread, ok := info.Object.(*kruiseappsv1alpha1.WorkloadSpread)
+		if !ok || workloadSpread.Spec.TargetReference == nil {
+			continue
+		}
+		ref := workloadSpread.Spec.TargetReference
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || gv.WithKind(ref.Kind).GroupKind() != cloneSetGroupKind || ref.Name != cloneSet.Name {
+			continue
+		}
+		var subsets []string
+		for _, subset := range workloadSpread.Spec.Subsets {
+			subsets = append(subsets, subset.Name)
+		}
+		workloadSpreads = append(workloadSpreads, fmt.Sprintf("%s (subsets: %s)", workloadSpread.Name, strings.Join(subsets, ", ")))
+	}
+	printList(o.Out, "WorkloadSpreads:", workloadSpreads)
+}
+
+// listRelated lists the objects of resource in namespace.
+func (o *DescribeCloneSetOptions) listRelated(namespace, resourceType string) ([]*resource.Info, error) {
+	return o.Builder().
+		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		NamespaceParam(namespace).
+		ResourceTypes(resourceType).
+		SelectAllParam(true).
+		Flatten().
+		Do().
+		Infos()
+}
+
+func (o *DescribeCloneSetOptions) printCloneSetConditions(conditions []kruiseappsv1alpha1.CloneSetCondition) {
+	fmt.Fprint(o.Out, "Conditions:\n")
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tLAST TRANSITION\tMESSAGE")
+	for _, condition := range conditions {
+		lastTransition := "<unknown>"
+		if !condition.LastTransitionTime.IsZero() {
+			lastTransition = translateTimestampSince(condition.LastTransitionTime) + " ago"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", condition.Type, condition.Status, condition.Reason, lastTransition, condition.Message)
+	}
+	w.Flush()
+}
+
+// printList prints the values under name, one per line, or <none> if there are none.
+func printList(out io.Writer, name string, values []string) {
+	if len(values) == 0 {
+		fmt.Fprintf(out, tableFormat, name, "<none>")
+		return
+	}
+	for i, value := range values {
+		if i > 0 {
+			name = ""
+		}
+		fmt.Fprintf(out, tableFormat, name, value)
+	}
+}
+
+func formatResourceList(list corev1.ResourceList) string {
+	var resources []string
+	for name, quantity := range list {
+		resources = append(resources, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	sort.Strings(resources)
+	return strings.Join(resources, ", ")
+}
+
+func intOrStringOr(value *intstr.IntOrString, unset string) string {
+	if value == nil {
+		return unset
+	}
+	return value.String()
+}
+
+func valueOrNone(value string) string {
+	if len(value) == 0 {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"net/http"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	rolloutsapiv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/utils/pointer"
+)
+
+func TestDescribeCloneSet(t *testing.T) {
+	cloneSet := &kruiseappsv1alpha1.CloneSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Replicas: pointer.Int32(5),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{
+					ReadinessGates: []corev1.PodReadinessGate{{ConditionType: "example.com/warmed-up"}},
+					Containers: []corev1.Container{{
+						Name:  "nginx",
+						Image: "nginx:1.25",
+						Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+						},
+					}},
+				},
+			},
+			UpdateStrategy: kruiseappsv1alpha1.CloneSetUpdateStrategy{
+				Type:           kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType,
+				Partition:      intOrStrPtr(intstr.FromInt(3)),
+				MaxUnavailable: intOrStrPtr(intstr.FromString("20%")),
+				MaxSurge:       intOrStrPtr(intstr.FromInt(0)),
+			},
+		},
+		Status: kruiseappsv1alpha1.CloneSetStatus{
+			Replicas:             5,
+			UpdatedReplicas:      2,
+			UpdatedReadyReplicas: 2,
+			ReadyReplicas:        5,
+			AvailableReplicas:    5,
+			CurrentRevision:      "web-stable",
+			UpdateRevision:       "web-canary",
+			LabelSelector:        "app=web",
+		},
+	}
+	rollouts := &rolloutsapiv1beta1.RolloutList{
+		TypeMeta: metav1.TypeMeta{APIVersion: rolloutsapiv1beta1.GroupVersion.String(), Kind: "RolloutList"},
+		Items: []rolloutsapiv1beta1.Rollout{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "ro", Namespace: "test"},
+				Spec: rolloutsapiv1beta1.RolloutSpec{
+					WorkloadRef: rolloutsapiv1beta1.ObjectRef{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet", Name: "web"},
+					Strategy: rolloutsapiv1beta1.RolloutStrategy{Canary: &rolloutsapiv1beta1.CanaryStrategy{
+						Steps: []rolloutsapiv1beta1.CanaryStep{{Replicas: intOrStrPtr(intstr.FromInt(1))}, {Replicas: intOrStrPtr(intstr.FromString("100%"))}},
+					}},
+				},
+				Status: rolloutsapiv1beta1.RolloutStatus{
+					Phase:        rolloutsapiv1beta1.RolloutPhaseProgressing,
+					CanaryStatus: &rolloutsapiv1beta1.CanaryStatus{CurrentStepIndex: 1},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test"},
+				Spec: rolloutsapiv1beta1.RolloutSpec{
+					WorkloadRef: rolloutsapiv1beta1.ObjectRef{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+				},
+			},
+		},
+	}
+	workloadSpreads := &kruiseappsv1alpha1.WorkloadSpreadList{
+		TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "WorkloadSpreadList"},
+		Items: []kruiseappsv1alpha1.WorkloadSpread{{
+			ObjectMeta: metav1.ObjectMeta{Name: "ws", Namespace: "test"},
+			Spec: kruiseappsv1alpha1.WorkloadSpreadSpec{
+				TargetReference: &kruiseappsv1alpha1.TargetReference{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet", Name: "web"},
+				Subsets:         []kruiseappsv1alpha1.WorkloadSpreadSubset{{Name: "zone-a"}, {Name: "zone-b"}},
+			},
+		}},
+	}
+	served := map[string]runtime.Object{
+		"/namespaces/test/clonesets/web":   cloneSet,
+		"/namespaces/test/rollouts":        rollouts,
+		"/namespaces/test/workloadspreads": workloadSpreads,
+	}
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Group: "apps.kruise.io", Version: "v1alpha1"},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			obj, ok := served[req.URL.Path]
+			if !ok || req.Method != http.MethodGet {
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+				return nil, nil
+			}
+			codec := scheme.Codecs.LegacyCodec(obj.GetObjectKind().GroupVersionKind().GroupVersion())
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+		}),
+	}
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &DescribeCloneSetOptions{IOStreams: streams}
+	assert.NoError(t, o.Complete(tf, []string{"web"}))
+	assert.NoError(t, o.Run())
+
+	for _, line := range []string{
+		"Name:              web\n",
+		"Selector:          app=web\n",
+		" Desired:          5\n",
+		" Updated:          2 (2 ready)\n",
+		"Current Revision:  web-stable\n",
+		"Update Revision:   web-canary\n",
+		"Update Strategy:   InPlaceIfPossible\n",
+		" Partition:        3\n",
+		" Max Unavailable:  20%\n",
+		" Max Surge:        0\n",
+		" Paused:           false\n",
+		"Readiness Gates:   InPlaceUpdateReady (added by Kruise for in-place updates)\n",
+		"                   example.com/warmed-up\n",
+		" Labels:           app=web\n",
+		"  nginx:\n",
+		"   Image:          nginx:1.25\n",
+		"   Ports:          80/TCP\n",
+		"   Requests:       cpu=100m, memory=128Mi\n",
+		"Rollouts:          ro (Progressing, step 1/2)\n",
+		"WorkloadSpreads:   ws (subsets: zone-a, zone-b)\n",
+	} {
+		assert.Contains(t, out.String(), line)
+	}
+	// the rollout of the deployment of the same name is not the one of the cloneset
+	assert.NotContains(t, out.String(), "other")
+}
@@ -40,6 +40,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -105,17 +106,23 @@ type WorkloadInfo struct {
 }
 
 type RolloutInfo struct {
-	Name               string
-	Namespace          string
-	Phase              string
-	Message            string
-	ObservedGeneration int64
-	Generation         int64
-	CurrentStepIndex   int32
-	CurrentStepState   string
-	Strategy           rolloutsapiv1beta1.CanaryStrategy
-	TrafficRoutingRef  string
-	WorkloadRef        RolloutWorkloadRef
+	Name                string
+	Namespace           string
+	Phase               string
+	Message             string
+	ObservedGeneration  int64
+	Generation          int64
+	CurrentStepIndex    int32
+	CurrentStepState    string
+	Strategy            rolloutsapiv1beta1.CanaryStrategy
+	TrafficRoutingRef   string
+	WorkloadRef         RolloutWorkloadRef
+	Paused              bool
+	Disabled            bool
+	CanaryReplicas      int32
+	CanaryReadyReplicas int32
+	LastUpdateTime      *metav1.Time
+	Conditions          []rolloutsapiv1beta1.RolloutCondition
 }
 
 func NewCmdDescribeRollout(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
@@ -288,15 +295,20 @@ func (o *DescribeRolloutOptions) clearScreen() {
 }
 
 type RolloutWorkloadRef struct {
-	Kind            string
-	Name            string
-	StableRevision  string
-	CanaryRevision  string
-	PodTemplateHash string
+	GroupVersionKind schema.GroupVersionKind
+	Kind             string
+	Name             string
+	StableRevision   string
+	CanaryRevision   string
+	PodTemplateHash  string
 }
 
 func (o *DescribeRolloutOptions) GetResources(rollout RolloutWorkloadRef) (*WorkloadInfo, error) {
 	resources := []string{rollout.Kind + "/" + rollout.Name}
+	if gvk := rollout.GroupVersionKind; len(gvk.Group) > 0 {
+		// qualify the kind, so that e.g. an Advanced StatefulSet is not taken for an apps/v1 StatefulSet
+		resources = []string{fmt.Sprintf("%s.%s.%s/%s", gvk.Kind, gvk.Version, gvk.Group, rollout.Name)}
+	}
 	r := o.Builder().
 		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
 		NamespaceParam(o.Namespace).DefaultNamespace().
@@ -540,17 +552,25 @@ func extractRolloutInfo(obj interface{}) *RolloutInfo {
 		info.Message = r.Status.Message
 		info.ObservedGeneration = r.Status.ObservedGeneration
 		info.Generation = r.GetObjectMeta().GetGeneration()
-		info.CurrentStepIndex = r.Status.CanaryStatus.CurrentStepIndex
-		info.CurrentStepState = string(r.Status.CanaryStatus.CurrentStepState)
+		info.Disabled = r.Spec.Disabled
+		info.Conditions = r.Status.Conditions
 		info.WorkloadRef = RolloutWorkloadRef{
-			Kind:            r.Spec.WorkloadRef.Kind,
-			Name:            r.Spec.WorkloadRef.Name,
-			StableRevision:  r.Status.CanaryStatus.StableRevision,
-			CanaryRevision:  r.Status.CanaryStatus.CanaryRevision,
-			PodTemplateHash: r.Status.CanaryStatus.PodTemplateHash,
+			Kind: r.Spec.WorkloadRef.Kind,
+			Name: r.Spec.WorkloadRef.Name,
+		}
+		if canaryStatus := r.Status.CanaryStatus; canaryStatus != nil {
+			info.CurrentStepIndex = canaryStatus.CurrentStepIndex
+			info.CurrentStepState = string(canaryStatus.CurrentStepState)
+			info.CanaryReplicas = canaryStatus.CanaryReplicas
+			info.CanaryReadyReplicas = canaryStatus.CanaryReadyReplicas
+			info.LastUpdateTime = canaryStatus.LastUpdateTime
+			info.WorkloadRef.StableRevision = canaryStatus.StableRevision
+			info.WorkloadRef.CanaryRevision = canaryStatus.CanaryRevision
+			info.WorkloadRef.PodTemplateHash = canaryStatus.PodTemplateHash
 		}
 
 		if r.Spec.Strategy.Canary != nil {
+			info.Strategy = *r.Spec.Strategy.Canary
 			info.TrafficRoutingRef = r.Spec.Strategy.Canary.TrafficRoutingRef
 		}
 
@@ -561,28 +581,80 @@ func extractRolloutInfo(obj interface{}) *RolloutInfo {
 		info.Message = r.Status.Message
 		info.ObservedGeneration = r.Status.ObservedGeneration
 		info.Generation = r.GetObjectMeta().GetGeneration()
-		info.CurrentStepIndex = r.Status.CanaryStatus.CurrentStepIndex
-		info.CurrentStepState = string(r.Status.CanaryStatus.CurrentStepState)
-		info.WorkloadRef = RolloutWorkloadRef{
-			Kind:            r.Spec.ObjectRef.WorkloadRef.Kind,
-			Name:            r.Spec.ObjectRef.WorkloadRef.Name,
-			StableRevision:  r.Status.CanaryStatus.StableRevision,
-			CanaryRevision:  r.Status.CanaryStatus.CanaryRevision,
-			PodTemplateHash: r.Status.CanaryStatus.PodTemplateHash,
+		info.Paused = r.Spec.Strategy.Paused
+		info.Disabled = r.Spec.Disabled
+		for _, condition := range r.Status.Conditions {
+			info.Conditions = append(info.Conditions, rolloutsapiv1beta1.RolloutCondition{
+				Type:               rolloutsapiv1beta1.RolloutConditionType(condition.Type),
+				Status:             condition.Status,
+				LastUpdateTime:     condition.LastUpdateTime,
+				LastTransitionTime: condition.LastTransitionTime,
+				Reason:             condition.Reason,
+				Message:            condition.Message,
+			})
+		}
+		if ref := r.Spec.ObjectRef.WorkloadRef; ref != nil {
+			info.WorkloadRef = RolloutWorkloadRef{Kind: ref.Kind, Name: ref.Name}
+		}
+		if canaryStatus := r.Status.CanaryStatus; canaryStatus != nil {
+			info.CurrentStepIndex = canaryStatus.CurrentStepIndex
+			info.CurrentStepState = string(canaryStatus.CurrentStepState)
+			info.CanaryReplicas = canaryStatus.CanaryReplicas
+			info.CanaryReadyReplicas = canaryStatus.CanaryReadyReplicas
+			info.LastUpdateTime = canaryStatus.LastUpdateTime
+			info.WorkloadRef.StableRevision = canaryStatus.StableRevision
+			info.WorkloadRef.CanaryRevision = canaryStatus.CanaryRevision
+			info.WorkloadRef.PodTemplateHash = canaryStatus.PodTemplateHash
 		}
 
 		if r.Spec.Strategy.Canary != nil {
 			info.TrafficRoutingRef = r.ObjectMeta.Annotations["rollouts.kruise.io/trafficrouting"]
+			// the steps of a v1alpha1 rollout carry the traffic as a weight
+			for _, step := range r.Spec.Strategy.Canary.Steps {
+				converted := rolloutsapiv1beta1.CanaryStep{
+					Replicas: step.Replicas,
+					Pause:    rolloutsapiv1beta1.RolloutPause{Duration: step.Pause.Duration},
+				}
+				if step.Weight != nil {
+					traffic := fmt.Sprintf("%d%%", *step.Weight)
+					converted.Traffic = &traffic
+				}
+				info.Strategy.Steps = append(info.Strategy.Steps, converted)
+			}
 		}
 	}
 
-	if obj.(*rolloutsapiv1beta1.Rollout).Spec.Strategy.Canary != nil {
-		info.Strategy = *obj.(*rolloutsapiv1beta1.Rollout).Spec.Strategy.Canary
+	if rollout, ok := obj.(runtime.Object); ok {
+		if gvk, _, err := internalpolymorphichelpers.ResolveWorkloadRef(rollout); err == nil {
+			info.WorkloadRef.GroupVersionKind = gvk
+		}
 	}
 
 	return info
 }
 
+// currentStep returns the step the rollout is at, or nil if it is not at any step.
+func (info *RolloutInfo) currentStep() *rolloutsapiv1beta1.CanaryStep {
+	if info.CurrentStepIndex < 1 || int(info.CurrentStepIndex) > len(info.Strategy.Steps) {
+		return nil
+	}
+	return &info.Strategy.Steps[info.CurrentStepIndex-1]
+}
+
+// pauseReason explains why the rollout is paused, or returns an empty string if it is not.
+func (info *RolloutInfo) pauseReason() string {
+	if info.Paused {
+		return fmt.Sprintf("paused by the user, run 'kubectl-kruise rollout resume rollout/%s' to continue", info.Name)
+	}
+	if info.CurrentStepState != string(rolloutsapiv1beta1.CanaryStepStatePaused) {
+		return ""
+	}
+	if step := info.currentStep(); step != nil && step.Pause.Duration != nil {
+		return fmt.Sprintf("for %ds after step %d", *step.Pause.Duration, info.CurrentStepIndex)
+	}
+	return fmt.Sprintf("waiting for approval of step %d, run 'kubectl-kruise rollout approve rollout/%s' to continue", info.CurrentStepIndex, info.Name)
+}
+
 func (o *DescribeRolloutOptions) fetchAndPrintTrafficRoutingRef(ref string) {
 	r := o.Builder().
 		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
@@ -633,6 +705,11 @@ func (o *DescribeRolloutOptions) printRolloutInfo(rollout interface{}) {
 	// Print basic info
 	fmt.Fprintf(o.Out, tableFormat, "Name:", info.Name)
 	fmt.Fprintf(o.Out, tableFormat, "Namespace:", info.Namespace)
+	if gvk := info.WorkloadRef.GroupVersionKind; !gvk.Empty() {
+		fmt.Fprintf(o.Out, tableFormat, "Workload:", fmt.Sprintf("%s/%s (%s)", gvk.Kind, info.WorkloadRef.Name, gvk.GroupVersion()))
+	} else {
+		fmt.Fprintf(o.Out, tableFormat, "Workload:", info.WorkloadRef.Kind+"/"+info.WorkloadRef.Name)
+	}
 
 	if info.ObservedGeneration == info.Generation {
 		fmt.Fprintf(o.Out, tableFormat, "Status:", o.colorizeIcon(info.Phase)+" "+info.Phase)
@@ -640,10 +717,22 @@ func (o *DescribeRolloutOptions) printRolloutInfo(rollout interface{}) {
 			fmt.Fprintf(o.Out, tableFormat, "Message:", info.Message)
 		}
 	}
+	if info.Disabled {
+		fmt.Fprintf(o.Out, tableFormat, "Disabled:", "true")
+	}
+	if reason := info.pauseReason(); reason != "" {
+		fmt.Fprintf(o.Out, tableFormat, "Paused:", reason)
+	}
+	if info.LastUpdateTime != nil && !info.LastUpdateTime.IsZero() {
+		fmt.Fprintf(o.Out, tableFormat, "Last Update:", translateTimestampSince(*info.LastUpdateTime)+" ago")
+	}
 
 	// Print strategy
 	fmt.Fprintf(o.Out, tableFormat, "Strategy:", "Canary")
 	fmt.Fprintf(o.Out, tableFormat, " Step:", strconv.Itoa(int(info.CurrentStepIndex))+"/"+strconv.Itoa(len(info.Strategy.Steps)))
+	if step := info.currentStep(); step != nil && step.Traffic != nil {
+		fmt.Fprintf(o.Out, tableFormat, " Weight:", *step.Traffic)
+	}
 
 	// Print steps
 	fmt.Fprint(o.Out, " Steps:\n")
@@ -680,7 +769,12 @@ func (o *DescribeRolloutOptions) printRolloutInfo(rollout interface{}) {
 
 	// Print replicas
 	if info.ObservedGeneration == info.Generation {
-		o.printReplicas(workloadInfo)
+		o.printReplicas(info, workloadInfo)
+	}
+
+	// Print conditions
+	if len(info.Conditions) > 0 {
+		o.printConditions(info.Conditions)
 	}
 
 	// Print pods
@@ -724,13 +818,40 @@ func (o *DescribeRolloutOptions) printSteps(info *RolloutInfo) {
 	}
 }
 
-func (o *DescribeRolloutOptions) printReplicas(info *WorkloadInfo) {
+func (o *DescribeRolloutOptions) printReplicas(rollout *RolloutInfo, info *WorkloadInfo) {
 	fmt.Fprint(o.Out, "Replicas:\n")
 	fmt.Fprintf(o.Out, tableFormat, " Desired:", info.Replicas.Desired)
 	fmt.Fprintf(o.Out, tableFormat, " Updated:", info.Replicas.Updated)
 	fmt.Fprintf(o.Out, tableFormat, " Current:", info.Replicas.Current)
 	fmt.Fprintf(o.Out, tableFormat, " Ready:", info.Replicas.Ready)
 	fmt.Fprintf(o.Out, tableFormat, " Available:", info.Replicas.Available)
+
+	// the pods which are not canary pods are still at the stable revision
+	stable := info.Replicas.Current - rollout.CanaryReplicas
+	if stable < 0 {
+		stable = 0
+	}
+	fmt.Fprintf(o.Out, tableFormat, " Canary:", fmt.Sprintf("%d (%d ready)", rollout.CanaryReplicas, rollout.CanaryReadyReplicas))
+	fmt.Fprintf(o.Out, tableFormat, " Stable:", stable)
+}
+
+func (o *DescribeRolloutOptions) printConditions(conditions []rolloutsapiv1beta1.RolloutCondition) {
+	fmt.Fprint(o.Out, "Conditions:\n")
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tLAST TRANSITION\tMESSAGE")
+	for _, condition := range conditions {
+		lastTransition := "<unknown>"
+		if !condition.LastTransitionTime.IsZero() {
+			lastTransition = translateTimestampSince(condition.LastTransitionTime) + " ago"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", condition.Type, condition.Status, condition.Reason, lastTransition, condition.Message)
+	}
+	w.Flush()
+}
+
+// translateTimestampSince returns the elapsed time since timestamp in human-readable approximation.
+func translateTimestampSince(timestamp metav1.Time) string {
+	return duration.HumanDuration(time.Since(timestamp.Time))
 }
 
 func (o *DescribeRolloutOptions) printPods(info *WorkloadInfo) {
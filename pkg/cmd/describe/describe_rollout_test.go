@@ -0,0 +1,228 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	rolloutsapiv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	rolloutsapiv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/utils/pointer"
+)
+
+func newDescribeTestFactory(t *testing.T, rollout runtime.Object) *cmdtesting.TestFactory {
+	cloneSet := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Replicas: pointer.Int32(5),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx:1.25"}}},
+			},
+		},
+		Status: kruiseappsv1alpha1.CloneSetStatus{Replicas: 5, UpdatedReplicas: 1, ReadyReplicas: 5, AvailableReplicas: 5},
+	}
+	pods := &corev1.PodList{Items: []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "web-abcde",
+			Namespace:         "test",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+			Labels:            map[string]string{"controller-revision-hash": "web-stable"},
+		},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx"}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{Ready: true}}},
+	}}}
+
+	rolloutCodec := scheme.Codecs.LegacyCodec(rollout.GetObjectKind().GroupVersionKind().GroupVersion())
+	cloneSetCodec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+	podCodec := scheme.Codecs.LegacyCodec(corev1.SchemeGroupVersion)
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Group: "rollouts.kruise.io", Version: "v1beta1"},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/namespaces/test/rollouts/ro" && m == http.MethodGet:
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(rolloutCodec, rollout)}, nil
+			case p == "/namespaces/test/clonesets/web" && m == http.MethodGet:
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(cloneSetCodec, cloneSet)}, nil
+			case p == "/namespaces/test/pods" && m == http.MethodGet:
+				assert.Equal(t, "controller-revision-hash=web-stable", req.URL.Query().Get("labelSelector"))
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(podCodec, pods)}, nil
+			default:
+				t.Fatalf("unexpected request: %s %s", m, p)
+				return nil, nil
+			}
+		}),
+	}
+	return tf
+}
+
+func TestDescribeRollout(t *testing.T) {
+	transitioned := metav1.NewTime(time.Now().Add(-5 * time.Minute))
+
+	v1beta1Rollout := &rolloutsapiv1beta1.Rollout{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rolloutsapiv1beta1.GroupVersion.String(), Kind: "Rollout"},
+		ObjectMeta: metav1.ObjectMeta{Name: "ro", Namespace: "test", Generation: 2},
+		Spec: rolloutsapiv1beta1.RolloutSpec{
+			WorkloadRef: rolloutsapiv1beta1.ObjectRef{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet", Name: "web"},
+			Strategy: rolloutsapiv1beta1.RolloutStrategy{
+				Canary: &rolloutsapiv1beta1.CanaryStrategy{
+					Steps: []rolloutsapiv1beta1.CanaryStep{
+						{
+							TrafficRoutingStrategy: rolloutsapiv1beta1.TrafficRoutingStrategy{Traffic: pointer.String("20%")},
+							Replicas:               intOrStrPtr(intstr.FromInt(1)),
+						},
+						{
+							TrafficRoutingStrategy: rolloutsapiv1beta1.TrafficRoutingStrategy{Traffic: pointer.String("100%")},
+							Replicas:               intOrStrPtr(intstr.FromString("100%")),
+							Pause:                  rolloutsapiv1beta1.RolloutPause{Duration: pointer.Int32(60)},
+						},
+					},
+				},
+			},
+		},
+		Status: rolloutsapiv1beta1.RolloutStatus{
+			ObservedGeneration: 2,
+			Phase:              rolloutsapiv1beta1.RolloutPhaseProgressing,
+			CanaryStatus: &rolloutsapiv1beta1.CanaryStatus{
+				StableRevision:      "web-stable",
+				CanaryRevision:      "web-canary",
+				CanaryReplicas:      1,
+				CanaryReadyReplicas: 1,
+				CurrentStepIndex:    1,
+				CurrentStepState:    rolloutsapiv1beta1.CanaryStepStatePaused,
+			},
+			Conditions: []rolloutsapiv1beta1.RolloutCondition{{
+				Type:               rolloutsapiv1beta1.RolloutConditionProgressing,
+				Status:             corev1.ConditionTrue,
+				Reason:             "InRolling",
+				Message:            "Rollout is in Progressing",
+				LastTransitionTime: transitioned,
+			}},
+		},
+	}
+
+	v1alpha1Rollout := &rolloutsapiv1alpha1.Rollout{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rolloutsapiv1alpha1.GroupVersion.String(), Kind: "Rollout"},
+		ObjectMeta: metav1.ObjectMeta{Name: "ro", Namespace: "test", Generation: 2},
+		Spec: rolloutsapiv1alpha1.RolloutSpec{
+			ObjectRef: rolloutsapiv1alpha1.ObjectRef{
+				WorkloadRef: &rolloutsapiv1alpha1.WorkloadRef{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet", Name: "web"},
+			},
+			Strategy: rolloutsapiv1alpha1.RolloutStrategy{
+				Paused: true,
+				Canary: &rolloutsapiv1alpha1.CanaryStrategy{
+					Steps: []rolloutsapiv1alpha1.CanaryStep{
+						{
+							TrafficRoutingStrategy: rolloutsapiv1alpha1.TrafficRoutingStrategy{Weight: pointer.Int32(20)},
+							Replicas:               intOrStrPtr(intstr.FromInt(1)),
+						},
+					},
+				},
+			},
+		},
+		Status: rolloutsapiv1alpha1.RolloutStatus{
+			ObservedGeneration: 2,
+			Phase:              rolloutsapiv1alpha1.RolloutPhaseProgressing,
+			CanaryStatus: &rolloutsapiv1alpha1.CanaryStatus{
+				StableRevision:      "web-stable",
+				CanaryRevision:      "web-canary",
+				CanaryReplicas:      2,
+				CanaryReadyReplicas: 1,
+				CurrentStepIndex:    1,
+				CurrentStepState:    rolloutsapiv1alpha1.CanaryStepStateUpgrade,
+			},
+			Conditions: []rolloutsapiv1alpha1.RolloutCondition{{
+				Type:               rolloutsapiv1alpha1.RolloutConditionProgressing,
+				Status:             corev1.ConditionTrue,
+				Reason:             "InRolling",
+				LastTransitionTime: transitioned,
+			}},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		rollout     runtime.Object
+		expectLines []string
+	}{
+		{
+			name:    "v1beta1 rollout waiting for approval",
+			rollout: v1beta1Rollout,
+			expectLines: []string{
+				"Workload:          CloneSet/web (apps.kruise.io/v1alpha1)\n",
+				"Status:             Progressing\n",
+				"Paused:            waiting for approval of step 1, run 'kubectl-kruise rollout approve rollout/ro' to continue\n",
+				" Step:             1/2\n",
+				" Weight:           20%\n",
+				"Images:            nginx:1.25\n",
+				" Canary:           1 (1 ready)\n",
+				" Stable:           4\n",
+				"Conditions:\n",
+				"Progressing  True    InRolling  5m ago",
+				"web-abcde",
+			},
+		},
+		{
+			name:    "v1alpha1 rollout paused by the user",
+			rollout: v1alpha1Rollout,
+			expectLines: []string{
+				"Workload:          CloneSet/web (apps.kruise.io/v1alpha1)\n",
+				"Paused:            paused by the user, run 'kubectl-kruise rollout resume rollout/ro' to continue\n",
+				" Step:             1/1\n",
+				" Weight:           20%\n",
+				" Canary:           2 (1 ready)\n",
+				" Stable:           3\n",
+				"Progressing  True    InRolling  5m ago",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newDescribeTestFactory(t, tc.rollout)
+			defer tf.Cleanup()
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			o := &DescribeRolloutOptions{IOStreams: streams, NoColor: true}
+			assert.NoError(t, o.Complete(tf, []string{"ro"}))
+			assert.NoError(t, o.Run())
+
+			for _, line := range tc.expectLines {
+				assert.Contains(t, out.String(), line)
+			}
+		})
+	}
+}
+
+func intOrStrPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/cmd/util/editor"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const editHeader = `# Please edit the object below. Lines beginning with a '#' will be ignored,
+# and an empty file will abort the edit. If an error occurs while saving, a copy
+# of your changes is kept in a temporary file.
+#
+`
+
+var (
+	editLong = templates.LongDesc(i18n.T(`
+		Edit a resource from the default editor.
+
+		The resources are decoded and encoded with the types of Kruise, so that CloneSets,
+		Advanced StatefulSets, SidecarSets and Rollouts are edited without losing the fields
+		that kubectl does not know. The edited object is decoded strictly before it is applied:
+		unknown fields, and a changed kind, name or namespace, are rejected, and the changes are
+		kept in a temporary file.
+
+		The editor is the one of the KUBE_EDITOR or EDITOR environment variables, vi by default.`))
+
+	editExample = templates.Examples(i18n.T(`
+		# Edit the cloneset named web
+		kubectl-kruise edit cloneset/web
+
+		# Edit the rollout named rollout-demo, and print the patch sent to the server
+		kubectl-kruise edit rollout/rollout-demo --output-patch`))
+)
+
+// EditOptions is the command line options for 'edit'
+type EditOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	ToPrinter  func(string) (printers.ResourcePrinter, error)
+
+	Resources        []string
+	OutputPatch      bool
+	Namespace        string
+	EnforceNamespace bool
+	Builder          func() *resource.Builder
+	// Edit opens original in an editor, and returns the edited content with the file it was saved to
+	Edit func(original []byte) ([]byte, string, error)
+
+	resource.FilenameOptions
+	genericclioptions.IOStreams
+}
+
+// NewEditOptions returns an initialized EditOptions instance
+func NewEditOptions(streams genericclioptions.IOStreams) *EditOptions {
+	return &EditOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("edited").WithTypeSetter(internalapi.GetScheme()),
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdEdit returns a Command instance for the 'edit' command
+func NewCmdEdit(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewEditOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "edit (RESOURCE/NAME | -f FILENAME)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Edit a resource with the types of Kruise"),
+		Long:                  editLong,
+		Example:               editExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.OutputPatch, "output-patch", o.OutputPatch, "Output the patch if the resource is edited.")
+	usage := "to use to edit the resource"
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *EditOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.Resources = args
+
+	var err error
+	if o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
+		o.PrintFlags.NamePrintFlags.Operation = operation
+		return o.PrintFlags.ToPrinter()
+	}
+	o.Builder = f.NewBuilder
+	o.Edit = func(original []byte) ([]byte, string, error) {
+		return editor.NewDefaultEditor([]string{"KUBE_EDITOR", "EDITOR"}).LaunchTempFile("kubectl-kruise-edit-", ".yaml", bytes.NewReader(original))
+	}
+	return nil
+}
+
+// Validate makes sure that the options are valid
+func (o *EditOptions) Validate() error {
+	if len(o.Resources) == 0 && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
+		return fmt.Errorf("you must specify the resource to edit, e.g. kubectl-kruise edit cloneset/web")
+	}
+	return nil
+}
+
+// Run edits the resources one after the other
+func (o *EditOptions) Run() error {
+	r := o.Builder().
+		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
+		ResourceTypeOrNameArgs(true, o.Resources...).
+		ContinueOnError().
+		Latest().
+		Flatten().
+		Do()
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	var allErrs []error
+	err := r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := o.edit(info); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		return nil
+	})
+	if err != nil {
+		allErrs = append(allErrs, err)
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// edit opens the object of info in the editor, and patches the changes made to it. The file of the changes is kept
+// if they cannot be applied.
+func (o *EditOptions) edit(info *resource.Info) error {
+	codecs := serializer.NewCodecFactory(internalapi.GetScheme())
+	yamlInfo, ok := runtime.SerializerInfoForMediaType(codecs.SupportedMediaTypes(), runtime.ContentTypeYAML)
+	if !ok {
+		return fmt.Errorf("no serializer for %s", runtime.ContentTypeYAML)
+	}
+	gvk := info.Mapping.GroupVersionKind
+	original, err := runtime.Encode(codecs.EncoderForVersion(yamlInfo.Serializer, gvk.GroupVersion()), info.Object)
+	if err != nil {
+		return err
+	}
+
+	edited, file, err := o.Edit(append([]byte(editHeader), original...))
+	if err != nil {
+		return err
+	}
+	edited = stripComments(edited)
+	if len(bytes.TrimSpace(edited)) == 0 {
+		fmt.Fprintln(o.ErrOut, "Edit cancelled, saved file was empty.")
+		return removeFile(file)
+	}
+	if bytes.Equal(edited, stripComments(original)) {
+		fmt.Fprintln(o.ErrOut, "Edit cancelled, no changes made.")
+		return removeFile(file)
+	}
+
+	obj, err := decodeEdited(yamlInfo.StrictSerializer, edited, info)
+	if err == nil {
+		var operation string
+		var patch []byte
+		operation, patch, err = internalcmdutil.PatchEditedWorkload(info, obj, cmdutil.DryRunNone)
+		if err == nil {
+			if operation == internalcmdutil.PatchOperationUnchanged {
+				fmt.Fprintln(o.ErrOut, "Edit cancelled, no changes made.")
+				return removeFile(file)
+			}
+			if o.OutputPatch {
+				fmt.Fprintf(o.Out, "Patch: %s\n", patch)
+			}
+			printer, printErr := o.ToPrinter("edited")
+			if printErr != nil {
+				return printErr
+			}
+			if printErr = printer.PrintObj(info.Object, o.Out); printErr != nil {
+				return printErr
+			}
+			return removeFile(file)
+		}
+	}
+	if len(file) > 0 {
+		return fmt.Errorf("%v\nA copy of your changes has been stored to %q", err, file)
+	}
+	return err
+}
+
+// decodeEdited decodes the edited object strictly, and makes sure it is still the object of info.
+func decodeEdited(decoder runtime.Decoder, edited []byte, info *resource.Info) (runtime.Object, error) {
+	obj, gvk, err := decoder.Decode(edited, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("the edited %s is invalid: %v", info.Mapping.Resource.GroupResource(), err)
+	}
+	if *gvk != info.Mapping.GroupVersionKind {
+		return nil, fmt.Errorf("the kind of %s/%s cannot be changed to %s", info.Mapping.Resource.GroupResource(), info.Name, gvk.Kind)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	if accessor.GetName() != info.Name || accessor.GetNamespace() != info.Namespace {
+		return nil, fmt.Errorf("the name and namespace of %s/%s cannot be changed", info.Mapping.Resource.GroupResource(), info.Name)
+	}
+	return obj, nil
+}
+
+// stripComments removes the lines starting with a '#', as the header of the edited file
+func stripComments(data []byte) []byte {
+	var out [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			out = append(out, line)
+		}
+	}
+	return bytes.Join(out, []byte("\n"))
+}
+
+func removeFile(file string) error {
+	if len(file) == 0 {
+		return nil
+	}
+	return os.Remove(file)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edit
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestRunEdit(t *testing.T) {
+	cs := &kruiseappsv1alpha1.CloneSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx:1.24"}}},
+			},
+			UpdateStrategy: kruiseappsv1alpha1.CloneSetUpdateStrategy{Type: kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType},
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		edit         func([]byte) []byte
+		outputPatch  bool
+		expectOut    string
+		expectErrOut string
+		expectErr    string
+		expectPatch  string
+	}{
+		{
+			name:         "no-op edit",
+			edit:         func(original []byte) []byte { return original },
+			expectErrOut: "Edit cancelled, no changes made.\n",
+		},
+		{
+			name: "image edit",
+			edit: func(original []byte) []byte {
+				return bytes.Replace(original, []byte("nginx:1.24"), []byte("nginx:1.25"), 1)
+			},
+			outputPatch: true,
+			expectOut: `Patch: {"spec":{"template":{"spec":{"containers":[{"image":"nginx:1.25","name":"nginx","resources":{}}]}}}}` + "\n" +
+				"cloneset.apps.kruise.io/web edited\n",
+			expectPatch: `{"spec":{"template":{"spec":{"containers":[{"image":"nginx:1.25","name":"nginx","resources":{}}]}}}}`,
+		},
+		{
+			name:         "emptied file",
+			edit:         func([]byte) []byte { return nil },
+			expectErrOut: "Edit cancelled, saved file was empty.\n",
+		},
+		{
+			name: "unknown field",
+			edit: func(original []byte) []byte {
+				return bytes.Replace(original, []byte("updateStrategy:"), []byte("updateStrategyy:"), 1)
+			},
+			expectErr: `the edited clonesets.apps.kruise.io is invalid: strict decoding error: unknown field "spec.updateStrategyy"`,
+		},
+		{
+			name: "renamed object",
+			edit: func(original []byte) []byte {
+				return bytes.Replace(original, []byte("name: web"), []byte("name: api"), 1)
+			},
+			expectErr: "the name and namespace of clonesets.apps.kruise.io/web cannot be changed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			var patch string
+			codec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+			tf.Client = &fake.RESTClient{
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					switch p, m := req.URL.Path, req.Method; {
+					case p == "/namespaces/test/clonesets/web" && m == http.MethodGet:
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, cs)}, nil
+					case p == "/namespaces/test/clonesets/web" && m == http.MethodPatch:
+						body, err := io.ReadAll(req.Body)
+						if err != nil {
+							return nil, err
+						}
+						patch = string(body)
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, cs)}, nil
+					}
+					t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+					return nil, nil
+				}),
+			}
+
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdEdit(tf, streams)
+			o := NewEditOptions(streams)
+			o.OutputPatch = tc.outputPatch
+			assert.NoError(t, o.Complete(tf, cmd, []string{"cloneset/web"}))
+			assert.NoError(t, o.Validate())
+			o.Edit = func(original []byte) ([]byte, string, error) {
+				return tc.edit(original), "", nil
+			}
+
+			err := o.Run()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectOut, out.String())
+			assert.Equal(t, tc.expectErrOut, errOut.String())
+			assert.Equal(t, tc.expectPatch, patch)
+		})
+	}
+}
@@ -18,10 +18,15 @@ limitations under the License.
 package expose
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
 
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -31,6 +36,9 @@ import (
 	"k8s.io/client-go/rest/fake"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 	"k8s.io/kubectl/pkg/scheme"
+
+	// register the kruise types into the kubectl scheme
+	_ "github.com/openkruise/kruise-tools/pkg/api"
 )
 
 func TestRunExposeService(t *testing.T) {
@@ -677,3 +685,94 @@ func TestRunExposeService(t *testing.T) {
 		})
 	}
 }
+
+func TestRunExposeCloneSet(t *testing.T) {
+	cloneSet := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test", Labels: map[string]string{"app": "web"}},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web", "tier": "frontend"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web", "tier": "frontend", "version": "v1"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "nginx", Image: "nginx", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		flags  map[string]string
+		expect corev1.ServiceSpec
+	}{
+		{
+			name: "ports from the pod template",
+			expect: corev1.ServiceSpec{
+				Ports:    []corev1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 8080, TargetPort: intstr.FromInt(8080)}},
+				Selector: cloneSet.Spec.Selector.MatchLabels,
+			},
+		},
+		{
+			name:  "port, target port, name and type",
+			flags: map[string]string{"port": "80", "target-port": "8080", "name": "web-lb", "type": "LoadBalancer"},
+			expect: corev1.ServiceSpec{
+				Ports:    []corev1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080)}},
+				Selector: cloneSet.Spec.Selector.MatchLabels,
+				Type:     corev1.ServiceTypeLoadBalancer,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			codec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+			var created *corev1.Service
+			tf.Client = &fake.RESTClient{
+				GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					switch p, m := req.URL.Path, req.Method; {
+					case p == "/namespaces/test/clonesets/web" && m == http.MethodGet:
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, cloneSet)}, nil
+					case p == "/namespaces/test/services" && m == http.MethodPost:
+						data, err := io.ReadAll(req.Body)
+						if err != nil {
+							return nil, err
+						}
+						created = &corev1.Service{}
+						if err := json.Unmarshal(data, created); err != nil {
+							return nil, err
+						}
+						return &http.Response{StatusCode: http.StatusCreated, Header: cmdtesting.DefaultHeader(), Body: io.NopCloser(bytes.NewReader(data))}, nil
+					default:
+						t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+						return nil, nil
+					}
+				}),
+			}
+
+			ioStreams, _, buf, _ := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdExposeService(tf, ioStreams)
+			cmd.SetOutput(buf)
+			for flag, value := range test.flags {
+				cmd.Flags().Set(flag, value)
+			}
+			cmd.Run(cmd, []string{"cloneset", "web"})
+
+			if !assert.NotNil(t, created, buf.String()) {
+				return
+			}
+			name := "web"
+			if len(test.flags["name"]) > 0 {
+				name = test.flags["name"]
+			}
+			assert.Equal(t, name, created.Name)
+			assert.Equal(t, cloneSet.Labels, created.Labels)
+			assert.Equal(t, test.expect, created.Spec)
+			assert.Contains(t, buf.String(), "service/"+name+" exposed")
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"fmt"
+
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// GetOptions contains the input to the get command.
+type GetOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	ToPrinter  func() (printers.ResourcePrinter, error)
+
+	Builder          func() *resource.Builder
+	Resources        []string
+	LabelSelector    string
+	AllNamespaces    bool
+	Namespace        string
+	EnforceNamespace bool
+
+	resource.FilenameOptions
+	genericclioptions.IOStreams
+}
+
+var (
+	getLong = templates.LongDesc(i18n.T(`
+		Display one or many resources.
+
+		Rollouts are printed with the workload they manage, their phase, and the step and
+		traffic weight of the canary. CloneSets are printed with their updated and ready pods, and
+		whether their pods are updated in-place or recreated. Other resources are printed with their
+		name and age.
+		Use -o to print the objects in another format. The objects are then printed as the server serves
+		them, including the fields, e.g. of the status, a newer Kruise Rollout adds.`))
+
+	getExample = templates.Examples(i18n.T(`
+		# List all rollouts in the current namespace
+		kubectl-kruise get rollout
+
+		# List a single rollout in all namespaces
+		kubectl-kruise get rollout rollout-demo --all-namespaces
+
+		# List all clonesets with their updated pods and how the pods are updated
+		kubectl-kruise get cloneset
+
+		# List the rollouts labeled with app=nginx in yaml
+		kubectl-kruise get rollout -l app=nginx -o yaml`))
+)
+
+// NewGetOptions returns a GetOptions with default values.
+func NewGetOptions(streams genericclioptions.IOStreams) *GetOptions {
+	return &GetOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("").WithTypeSetter(internalapi.GetScheme()),
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdGet returns a Command instance for the 'get' command
+func NewCmdGet(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewGetOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "get [(-o|--output=)json|yaml|name|...] (TYPE[.VERSION][.GROUP] [NAME | -l label] | TYPE[.VERSION][.GROUP]/NAME ...) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Display one or many resources"),
+		Long:                  getLong,
+		Example:               getExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "identifying the resource to get from a server.")
+	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *GetOptions) Complete(f cmdutil.Factory, args []string) error {
+	o.Resources = args
+
+	var err error
+	if o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+
+	o.ToPrinter = o.PrintFlags.ToPrinter
+	o.Builder = f.NewBuilder
+	return nil
+}
+
+// Validate makes sure that the options are valid
+func (o *GetOptions) Validate() error {
+	if len(o.Resources) == 0 && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
+		return fmt.Errorf("you must specify the type of resource to get, e.g. kubectl-kruise get rollout")
+	}
+	return nil
+}
+
+// Run prints the resources, as a table unless another output format is requested
+func (o *GetOptions) Run() error {
+	b := o.Builder()
+	if len(*o.PrintFlags.OutputFormat) > 0 {
+		// the objects printed with -o are kept as served, so that the fields of a CRD newer than the types bundled in
+		// the plugin, e.g. in the status of a rollout, are printed rather than dropped by the decoding
+		b = b.Unstructured()
+	} else {
+		b = b.WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	}
+	r := b.
+		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
+		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
+		LabelSelectorParam(o.LabelSelector).
+		ResourceTypeOrNameArgs(true, o.Resources...).
+		ContinueOnError().
+		Flatten().
+		Do()
+
+	var allErrs []error
+	infos, err := r.Infos()
+	if err != nil {
+		// print the resources that could be fetched, and report the others at the end
+		allErrs = append(allErrs, err)
+	}
+
+	if len(*o.PrintFlags.OutputFormat) > 0 {
+		printer, err := o.ToPrinter()
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if err := printer.PrintObj(info.Object, o.Out); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+		return utilerrors.NewAggregate(allErrs)
+	}
+
+	if len(infos) == 0 && len(allErrs) == 0 {
+		if o.AllNamespaces {
+			fmt.Fprintln(o.ErrOut, "No resources found")
+		} else {
+			fmt.Fprintf(o.ErrOut, "No resources found in %s namespace.\n", o.Namespace)
+		}
+		return nil
+	}
+
+	if err := o.printTables(infos); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// printTables prints a table for each kind in the infos, in the order the kinds first appear.
+func (o *GetOptions) printTables(infos []*resource.Info) error {
+	var kinds []schema.GroupVersionKind
+	objs := map[schema.GroupVersionKind][]runtime.Object{}
+	for _, info := range infos {
+		gvk := info.Mapping.GroupVersionKind
+		if _, ok := objs[gvk]; !ok {
+			kinds = append(kinds, gvk)
+		}
+		objs[gvk] = append(objs[gvk], info.Object)
+	}
+
+	for i, gvk := range kinds {
+		table, err := internalapi.ConvertToTable(gvk, objs[gvk]...)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(o.Out)
+		}
+		// a printer per kind, so that every table gets its own headers
+		printer := printers.NewTablePrinter(printers.PrintOptions{
+			WithNamespace: o.AllNamespaces,
+			WithKind:      len(kinds) > 1,
+			Kind:          gvk.GroupKind(),
+		})
+		if err := printer.PrintObj(table, o.Out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
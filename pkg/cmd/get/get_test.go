@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	rolloutsapiv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/utils/pointer"
+)
+
+func newGetTestRollouts() *rolloutsapiv1beta1.RolloutList {
+	return &rolloutsapiv1beta1.RolloutList{Items: []rolloutsapiv1beta1.Rollout{{
+		ObjectMeta: metav1.ObjectMeta{Name: "rollout-demo", Namespace: "test", CreationTimestamp: metav1.NewTime(time.Now().Add(-5 * time.Hour))},
+		Spec: rolloutsapiv1beta1.RolloutSpec{
+			WorkloadRef: rolloutsapiv1beta1.ObjectRef{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet", Name: "web"},
+			Strategy: rolloutsapiv1beta1.RolloutStrategy{
+				Canary: &rolloutsapiv1beta1.CanaryStrategy{
+					Steps: []rolloutsapiv1beta1.CanaryStep{
+						{TrafficRoutingStrategy: rolloutsapiv1beta1.TrafficRoutingStrategy{Traffic: pointer.String("20%")}},
+						{TrafficRoutingStrategy: rolloutsapiv1beta1.TrafficRoutingStrategy{Traffic: pointer.String("100%")}},
+					},
+				},
+			},
+		},
+		Status: rolloutsapiv1beta1.RolloutStatus{
+			Phase:        rolloutsapiv1beta1.RolloutPhaseProgressing,
+			CanaryStatus: &rolloutsapiv1beta1.CanaryStatus{CurrentStepIndex: 1},
+		},
+	}}}
+}
+
+// newGetTestFactory returns a factory whose fake server serves body as the list of the rollouts in the test namespace.
+// The fake builder sends all requests through the unstructured client if there is one, so it is only set for the
+// commands which decode the objects as served.
+func newGetTestFactory(t *testing.T, body []byte, unstructuredClient bool) *cmdtesting.TestFactory {
+	handler := fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+		switch p, m := req.URL.Path, req.Method; {
+		case p == "/namespaces/test/rollouts" && m == http.MethodGet:
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: io.NopCloser(bytes.NewReader(body))}, nil
+		default:
+			t.Fatalf("unexpected request: %s %s", m, p)
+			return nil, nil
+		}
+	})
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Group: "rollouts.kruise.io", Version: "v1beta1"},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client:               handler,
+	}
+	if unstructuredClient {
+		tf.UnstructuredClient = &fake.RESTClient{
+			GroupVersion:         schema.GroupVersion{Group: "rollouts.kruise.io", Version: "v1beta1"},
+			NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+			Client:               handler,
+		}
+	}
+	return tf
+}
+
+// newGetTestRolloutsBody returns the served list of the test rollouts, with a field unknown to the bundled types added
+// to the status of every rollout, as a newer Kruise Rollout may serve.
+func newGetTestRolloutsBody(t *testing.T) []byte {
+	data, err := runtime.Encode(scheme.Codecs.LegacyCodec(rolloutsapiv1beta1.GroupVersion), newGetTestRollouts())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list := &unstructured.UnstructuredList{}
+	if err := list.UnmarshalJSON(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range list.Items {
+		if err := unstructured.SetNestedField(list.Items[i].Object, "canary-20%", "status", "currentStepLabel"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if data, err = list.MarshalJSON(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return data
+}
+
+func TestRunGetRollouts(t *testing.T) {
+	testCases := []struct {
+		name      string
+		output    string
+		expectOut string
+	}{
+		{
+			name: "table",
+			expectOut: "NAME           WORKLOAD       PHASE         STEP   WEIGHT   AGE\n" +
+				"rollout-demo   CloneSet/web   Progressing   1/2    20%      5h\n",
+		},
+		{
+			name:      "name output",
+			output:    "name",
+			expectOut: "rollout.rollouts.kruise.io/rollout-demo\n",
+		},
+		{
+			name:      "jsonpath output of a status field unknown to the bundled types",
+			output:    "jsonpath={.status.currentStepLabel} {.status.canaryStatus.currentStepIndex}",
+			expectOut: "canary-20% 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newGetTestFactory(t, newGetTestRolloutsBody(t), len(tc.output) > 0)
+			defer tf.Cleanup()
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			o := NewGetOptions(streams)
+			assert.NoError(t, o.Complete(tf, []string{"rollouts"}))
+			*o.PrintFlags.OutputFormat = tc.output
+			assert.NoError(t, o.Validate())
+			assert.NoError(t, o.Run())
+			assert.Equal(t, tc.expectOut, out.String())
+		})
+	}
+}
+
+func TestGetValidate(t *testing.T) {
+	o := NewGetOptions(genericclioptions.NewTestIOStreamsDiscard())
+	assert.EqualError(t, o.Validate(), "you must specify the type of resource to get, e.g. kubectl-kruise get rollout")
+}
@@ -0,0 +1,366 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package label
+
+import (
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// LabelOptions is the start of the data required to perform the operation.  As new fields are added, add them here instead of
+// referencing the cmd.Flags()
+type LabelOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	ToPrinter  func(string) (printers.ResourcePrinter, error)
+
+	Builder          func() *resource.Builder
+	Overwrite        bool
+	Template         bool
+	All              bool
+	DryRunStrategy   cmdutil.DryRunStrategy
+	Resources        []string
+	NewLabels        map[string]string
+	RemoveLabels     []string
+	LabelSelector    string
+	Namespace        string
+	EnforceNamespace bool
+
+	resource.FilenameOptions
+	genericclioptions.IOStreams
+}
+
+var (
+	labelLong = templates.LongDesc(`
+		Update the labels on a resource.
+
+		A label key and value must begin with a letter or number, and may contain letters,
+		numbers, hyphens, dots, and underscores, up to 63 characters each. If --overwrite is
+		true, then existing labels can be overwritten, otherwise attempting to overwrite a
+		label will result in an error.
+
+		With --pod-template the labels are written into the pod template of a workload, in
+		spec.template.metadata.labels, instead of the metadata of the workload itself. For a
+		CloneSet or an Advanced StatefulSet with an in-place update strategy, this updates the
+		labels of the existing pods in place. Labels used by the selector of the workload
+		cannot be changed in its pod template.`)
+
+	labelExample = templates.Examples(`
+		# Update cloneset 'web' with the label 'tier' and the value 'frontend'
+		kubectl-kruise label cloneset/web tier=frontend
+
+		# Update the pods of cloneset 'web' in place with the label 'tier' and the value 'frontend'
+		kubectl-kruise label cloneset/web tier=frontend --pod-template
+
+		# Update the pod template of advanced statefulset 'mysql', overwriting any existing value of 'release'
+		kubectl-kruise label --pod-template --overwrite statefulsets.apps.kruise.io/mysql release=stable
+
+		# Remove the label named 'tier' from the pod template of cloneset 'web'
+		kubectl-kruise label cloneset/web tier- --pod-template`)
+)
+
+// NewLabelOptions returns an initialized LabelOptions instance
+func NewLabelOptions(streams genericclioptions.IOStreams) *LabelOptions {
+	return &LabelOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("labeled").WithTypeSetter(internalapi.GetScheme()),
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdLabel returns a Command instance for the 'label' command
+func NewCmdLabel(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewLabelOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "label [--overwrite] [--pod-template] (-f FILENAME | TYPE NAME) KEY_1=VAL_1 ... KEY_N=VAL_N",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Update the labels on a resource or its pod template"),
+		Long:                  labelLong,
+		Example:               labelExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.RunLabel())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.Overwrite, "overwrite", o.Overwrite, "If true, allow labels to be overwritten, otherwise reject label updates that overwrite existing labels.")
+	cmd.Flags().BoolVar(&o.Template, "pod-template", o.Template, "If true, update the labels of the pod template of the workload instead of the workload itself.")
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "Select all resources in the namespace of the specified resource types")
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "identifying the resource to update the labels")
+	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
+	cmdutil.AddDryRunFlag(cmd)
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *LabelOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var labelArgs []string
+	o.Resources, labelArgs = splitLabelArgs(args)
+	var err error
+	o.NewLabels, o.RemoveLabels, err = parseLabels(labelArgs)
+	if err != nil {
+		return err
+	}
+
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	if o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+
+	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
+		o.PrintFlags.NamePrintFlags.Operation = operation
+		cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+		return o.PrintFlags.ToPrinter()
+	}
+
+	o.Builder = f.NewBuilder
+
+	return nil
+}
+
+// Validate checks to the LabelOptions to see if there is sufficient information run the command.
+func (o *LabelOptions) Validate() error {
+	if len(o.Resources) == 0 && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
+		return fmt.Errorf("one or more resources must be specified as <resource> <name> or <resource>/<name>")
+	}
+	if len(o.NewLabels) == 0 && len(o.RemoveLabels) == 0 {
+		return fmt.Errorf("at least one label update is required")
+	}
+	for _, key := range o.RemoveLabels {
+		if _, ok := o.NewLabels[key]; ok {
+			return fmt.Errorf("can not both modify and remove label %q in the same command", key)
+		}
+	}
+	return nil
+}
+
+// RunLabel performs the execution of 'label' command
+func (o *LabelOptions) RunLabel() error {
+	r := o.Builder().
+		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
+		LabelSelectorParam(o.LabelSelector).
+		ResourceTypeOrNameArgs(o.All, o.Resources...).
+		ContinueOnError().
+		Latest().
+		Flatten().
+		Do()
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	var allErrs []error
+	infos, err := r.Infos()
+	if err != nil {
+		// proceed with the valid resources, and report the broken ones at the end
+		allErrs = append(allErrs, err)
+	}
+	if len(infos) == 0 && len(allErrs) == 0 {
+		return fmt.Errorf("no objects passed to label")
+	}
+
+	for _, info := range infos {
+		if err := o.labelInfo(info); err != nil {
+			allErrs = append(allErrs, fmt.Errorf("error: %s/%s %v", info.Mapping.Resource.Resource, info.Name, err))
+		}
+	}
+
+	return utilerrors.NewAggregate(allErrs)
+}
+
+func (o *LabelOptions) labelInfo(info *resource.Info) error {
+	before := info.Object.DeepCopyObject()
+	after := info.Object.DeepCopyObject()
+	if o.Template {
+		if err := o.labelTemplate(after); err != nil {
+			return err
+		}
+	} else {
+		accessor, err := meta.Accessor(after)
+		if err != nil {
+			return err
+		}
+		labels, err := o.updateLabels(accessor.GetLabels())
+		if err != nil {
+			return err
+		}
+		accessor.SetLabels(labels)
+	}
+
+	oldData, err := runtime.Encode(scheme.DefaultJSONEncoder(), before)
+	if err != nil {
+		return err
+	}
+	newData, err := runtime.Encode(scheme.DefaultJSONEncoder(), after)
+	if err != nil {
+		return err
+	}
+	patch, err := jsonpatch.CreateMergePatch(oldData, newData)
+	if err != nil {
+		return err
+	}
+
+	operation := "labeled"
+	if string(patch) == "{}" {
+		operation = "not labeled"
+	}
+	obj := after
+	if o.DryRunStrategy != cmdutil.DryRunClient && string(patch) != "{}" {
+		obj, err = resource.NewHelper(info.Client, info.Mapping).
+			DryRun(o.DryRunStrategy == cmdutil.DryRunServer).
+			Patch(info.Namespace, info.Name, types.MergePatchType, patch, nil)
+		if err != nil {
+			return fmt.Errorf("failed to patch: %v", err)
+		}
+	}
+	if err = info.Refresh(obj, true); err != nil {
+		return err
+	}
+
+	printer, err := o.ToPrinter(operation)
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(info.Object, o.Out)
+}
+
+// labelTemplate updates the labels in the pod template of obj, refusing to change the ones its selector relies on.
+func (o *LabelOptions) labelTemplate(obj runtime.Object) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	if _, found, err := unstructured.NestedMap(content, "spec", "template"); err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("has no pod template to label")
+	}
+	labels, _, err := unstructured.NestedStringMap(content, "spec", "template", "metadata", "labels")
+	if err != nil {
+		return err
+	}
+	selector, _, err := unstructured.NestedStringMap(content, "spec", "selector", "matchLabels")
+	if err != nil {
+		return err
+	}
+	for _, key := range o.changedKeys() {
+		if _, ok := selector[key]; ok {
+			return fmt.Errorf("label %q is used by the selector and cannot be changed in the pod template", key)
+		}
+	}
+
+	labels, err = o.updateLabels(labels)
+	if err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		unstructured.RemoveNestedField(content, "spec", "template", "metadata", "labels")
+	} else if err = unstructured.SetNestedStringMap(content, labels, "spec", "template", "metadata", "labels"); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj)
+}
+
+// updateLabels applies the label changes to labels, and returns the result.
+func (o *LabelOptions) updateLabels(labels map[string]string) (map[string]string, error) {
+	if !o.Overwrite {
+		for key, value := range o.NewLabels {
+			if current, ok := labels[key]; ok && current != value {
+				return nil, fmt.Errorf("'%s' already has a value (%s), and --overwrite is false", key, current)
+			}
+		}
+	}
+	if labels == nil && len(o.NewLabels) > 0 {
+		labels = map[string]string{}
+	}
+	for key, value := range o.NewLabels {
+		labels[key] = value
+	}
+	for _, key := range o.RemoveLabels {
+		delete(labels, key)
+	}
+	return labels, nil
+}
+
+// changedKeys returns the keys of the labels the command sets or removes.
+func (o *LabelOptions) changedKeys() []string {
+	keys := append([]string{}, o.RemoveLabels...)
+	for key := range o.NewLabels {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// splitLabelArgs splits the arguments into the resources and the label changes that follow them.
+func splitLabelArgs(args []string) ([]string, []string) {
+	for i, arg := range args {
+		if strings.Contains(arg, "=") || strings.HasSuffix(arg, "-") {
+			return args[:i], args[i:]
+		}
+	}
+	return args, nil
+}
+
+// parseLabels parses KEY=VALUE arguments into labels to set, and KEY- arguments into labels to remove.
+func parseLabels(args []string) (map[string]string, []string, error) {
+	newLabels := map[string]string{}
+	var removeLabels []string
+	for _, arg := range args {
+		if key := strings.TrimSuffix(arg, "-"); key != arg && !strings.Contains(arg, "=") {
+			if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+				return nil, nil, fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, ";"))
+			}
+			removeLabels = append(removeLabels, key)
+			continue
+		}
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return nil, nil, fmt.Errorf("invalid label spec: %s", arg)
+		}
+		if errs := validation.IsQualifiedName(parts[0]); len(errs) != 0 {
+			return nil, nil, fmt.Errorf("invalid label key %q: %s", parts[0], strings.Join(errs, ";"))
+		}
+		if errs := validation.IsValidLabelValue(parts[1]); len(errs) != 0 {
+			return nil, nil, fmt.Errorf("invalid label value %q: %s", parts[1], strings.Join(errs, ";"))
+		}
+		newLabels[parts[0]] = parts[1]
+	}
+	return newLabels, removeLabels, nil
+}
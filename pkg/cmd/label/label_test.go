@@ -0,0 +1,237 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package label
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func newTestCloneSet() *kruiseappsv1alpha1.CloneSet {
+	return &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test", Labels: map[string]string{"app": "web", "tier": "backend"}},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web", "release": "stable"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}}},
+			},
+		},
+	}
+}
+
+func newLabelTestFactory(t *testing.T, path string, obj runtime.Object, patches *[]string) *cmdtesting.TestFactory {
+	codec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Group: "apps.kruise.io", Version: "v1alpha1"},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == path && m == http.MethodGet:
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+			case p == path && m == http.MethodPatch:
+				assert.Equal(t, string(types.MergePatchType), req.Header.Get("Content-Type"))
+				data, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				*patches = append(*patches, string(data))
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+			default:
+				t.Fatalf("unexpected request: %s %s", m, p)
+				return nil, nil
+			}
+		}),
+	}
+	return tf
+}
+
+func TestRunLabelCloneSet(t *testing.T) {
+	testCases := []struct {
+		name          string
+		args          []string
+		template      bool
+		overwrite     bool
+		dryRun        cmdutil.DryRunStrategy
+		expectPatches []string
+		expectOut     string
+		expectErr     string
+	}{
+		{
+			name:          "workload label",
+			args:          []string{"cloneset/web", "env=prod"},
+			expectPatches: []string{`{"metadata":{"labels":{"env":"prod"}}}`},
+			expectOut:     "cloneset.apps.kruise.io/web labeled\n",
+		},
+		{
+			name:          "template label",
+			args:          []string{"cloneset/web", "env=prod"},
+			template:      true,
+			expectPatches: []string{`{"spec":{"template":{"metadata":{"labels":{"env":"prod"}}}}}`},
+			expectOut:     "cloneset.apps.kruise.io/web labeled\n",
+		},
+		{
+			name:          "remove template label",
+			args:          []string{"cloneset/web", "release-"},
+			template:      true,
+			expectPatches: []string{`{"spec":{"template":{"metadata":{"labels":{"release":null}}}}}`},
+			expectOut:     "cloneset.apps.kruise.io/web labeled\n",
+		},
+		{
+			name:      "workload label without overwrite",
+			args:      []string{"cloneset/web", "tier=frontend"},
+			expectErr: "error: clonesets/web 'tier' already has a value (backend), and --overwrite is false",
+		},
+		{
+			name:          "workload label with overwrite",
+			args:          []string{"cloneset/web", "tier=frontend"},
+			overwrite:     true,
+			expectPatches: []string{`{"metadata":{"labels":{"tier":"frontend"}}}`},
+			expectOut:     "cloneset.apps.kruise.io/web labeled\n",
+		},
+		{
+			name:          "template label is independent of the workload label",
+			args:          []string{"cloneset/web", "tier=frontend"},
+			template:      true,
+			expectPatches: []string{`{"spec":{"template":{"metadata":{"labels":{"tier":"frontend"}}}}}`},
+			expectOut:     "cloneset.apps.kruise.io/web labeled\n",
+		},
+		{
+			name:      "template label without overwrite",
+			args:      []string{"cloneset/web", "release=canary"},
+			template:  true,
+			expectErr: "error: clonesets/web 'release' already has a value (stable), and --overwrite is false",
+		},
+		{
+			name:          "template label with overwrite",
+			args:          []string{"cloneset/web", "release=canary"},
+			template:      true,
+			overwrite:     true,
+			expectPatches: []string{`{"spec":{"template":{"metadata":{"labels":{"release":"canary"}}}}}`},
+			expectOut:     "cloneset.apps.kruise.io/web labeled\n",
+		},
+		{
+			name:      "template label used by the selector",
+			args:      []string{"cloneset/web", "app=api"},
+			template:  true,
+			overwrite: true,
+			expectErr: `error: clonesets/web label "app" is used by the selector and cannot be changed in the pod template`,
+		},
+		{
+			name:      "unchanged label",
+			args:      []string{"cloneset/web", "tier=backend"},
+			expectOut: "cloneset.apps.kruise.io/web not labeled\n",
+		},
+		{
+			name:      "client dry-run does not patch",
+			args:      []string{"cloneset/web", "env=prod"},
+			template:  true,
+			dryRun:    cmdutil.DryRunClient,
+			expectOut: "cloneset.apps.kruise.io/web labeled (dry run)\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var patches []string
+			tf := newLabelTestFactory(t, "/namespaces/test/clonesets/web", newTestCloneSet(), &patches)
+			defer tf.Cleanup()
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdLabel(tf, streams)
+			o := NewLabelOptions(streams)
+			assert.NoError(t, o.Complete(tf, cmd, tc.args))
+			o.Template = tc.template
+			o.Overwrite = tc.overwrite
+			o.DryRunStrategy = tc.dryRun
+			assert.NoError(t, o.Validate())
+
+			err := o.RunLabel()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectPatches, patches)
+			assert.Equal(t, tc.expectOut, out.String())
+		})
+	}
+}
+
+func TestLabelValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      []string
+		expectErr string
+	}{
+		{
+			name:      "no resource",
+			args:      []string{"env=prod"},
+			expectErr: "one or more resources must be specified as <resource> <name> or <resource>/<name>",
+		},
+		{
+			name:      "no label",
+			args:      []string{"cloneset/web"},
+			expectErr: "at least one label update is required",
+		},
+		{
+			name:      "modify and remove",
+			args:      []string{"cloneset/web", "env=prod", "env-"},
+			expectErr: `can not both modify and remove label "env" in the same command`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			streams := genericclioptions.NewTestIOStreamsDiscard()
+			o := NewLabelOptions(streams)
+			assert.NoError(t, o.Complete(tf, NewCmdLabel(tf, streams), tc.args))
+			assert.EqualError(t, o.Validate(), tc.expectErr)
+		})
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	newLabels, removeLabels, err := parseLabels([]string{"env=prod", "apps.kruise.io/tier=web", "release-", "empty="})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "apps.kruise.io/tier": "web", "empty": ""}, newLabels)
+	assert.Equal(t, []string{"release"}, removeLabels)
+
+	_, _, err = parseLabels([]string{"env=prod!"})
+	assert.ErrorContains(t, err, `invalid label value "prod!"`)
+
+	_, _, err = parseLabels([]string{"-env=prod"})
+	assert.ErrorContains(t, err, `invalid label key "-env"`)
+}
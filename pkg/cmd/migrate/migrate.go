@@ -18,6 +18,8 @@ package migrate
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/openkruise/kruise-tools/pkg/api"
 	"github.com/spf13/cobra"
@@ -27,6 +29,8 @@ import (
 )
 
 type migrateOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+
 	Namespace string
 
 	From    string
@@ -41,12 +45,17 @@ type migrateOptions struct {
 	Replicas       int32
 	MaxSurge       int32
 	TimeoutSeconds int32
+	Prune          bool
+	PruneTimeout   time.Duration
 
 	genericclioptions.IOStreams
 }
 
 func newMigrateOptions(ioStreams genericclioptions.IOStreams) *migrateOptions {
-	return &migrateOptions{IOStreams: ioStreams}
+	return &migrateOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("created").WithTypeSetter(api.GetScheme()),
+		IOStreams:  ioStreams,
+	}
 }
 
 func NewCmdMigrate(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
@@ -61,11 +70,17 @@ func NewCmdMigrate(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *co
 	# Create an empty CloneSet from an existing Deployment.
 	kubectl-kruise migrate CloneSet --from Deployment -n default --dst-name deployment-name --create
 
-	# Create a same replicas CloneSet from an existing Deployment.
+	# Create a same replicas CloneSet from an existing Deployment, and scale the Deployment to zero.
 	kubectl-kruise migrate CloneSet --from Deployment -n default --dst-name deployment-name --create --copy
 
+	# Print the CloneSet which would be created from Deployment web with 3 replicas, without creating it.
+	kubectl-kruise migrate CloneSet --from Deployment/web -n default --create --replicas 3 -o yaml
+
 	# Migrate replicas from an existing Deployment to an existing CloneSet.
 	kubectl-kruise migrate CloneSet --from Deployment -n default --src-name cloneset-name --dst-name deployment-name --replicas 10 --max-surge=2
+
+	# Migrate all replicas from Deployment web to CloneSet web, and delete the Deployment once the CloneSet is healthy.
+	kubectl-kruise migrate CloneSet --from Deployment/web -n default --dst-name web --prune --prune-timeout=10m
 `,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
@@ -73,15 +88,18 @@ func NewCmdMigrate(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *co
 		},
 	}
 
-	cmd.Flags().StringVar(&o.From, "from", "", "Type of the source workload (e.g. Deployment).")
+	cmd.Flags().StringVar(&o.From, "from", "", "Type of the source workload (e.g. Deployment), optionally followed by its name (e.g. Deployment/web).")
 	cmd.Flags().StringVar(&o.SrcName, "src-name", "", "Name of the source workload.")
 	cmd.Flags().StringVar(&o.DstName, "dst-name", "", "Name of the destination workload.")
 
-	cmd.Flags().BoolVar(&o.IsCreate, "create", false, "Create dst workload with replicas=0 from src workload.")
-	cmd.Flags().BoolVar(&o.IsCopy, "copy", false, "Copy replicas from src workload when create.")
-	cmd.Flags().Int32Var(&o.Replicas, "replicas", -1, "The replicas needs to migrate, -1 indicates all replicas in src workload.")
+	cmd.Flags().BoolVar(&o.IsCreate, "create", false, "Create a paused dst workload with replicas=0 from src workload.")
+	cmd.Flags().BoolVar(&o.IsCopy, "copy", false, "Copy replicas from src workload when create, and scale src workload to zero.")
+	cmd.Flags().Int32Var(&o.Replicas, "replicas", -1, "The replicas needs to migrate, -1 indicates all replicas in src workload. With --create, the replicas of the created workload.")
 	cmd.Flags().Int32Var(&o.MaxSurge, "max-surge", 1, "Max surge during migration.")
 	cmd.Flags().Int32Var(&o.TimeoutSeconds, "timeout-seconds", -1, "Timeout seconds for migration, -1 indicates no limited.")
+	cmd.Flags().BoolVar(&o.Prune, "prune", false, "Delete the src workload once all replicas of the dst workload are updated and available. If it does not happen within --prune-timeout, the src workload is kept.")
+	cmd.Flags().DurationVar(&o.PruneTimeout, "prune-timeout", 5*time.Minute, "How long to wait for the dst workload to become healthy before pruning the src workload.")
+	o.PrintFlags.AddFlags(cmd)
 
 	return cmd
 }
@@ -104,11 +122,34 @@ func (o *migrateOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []
 	if len(o.From) == 0 {
 		return fmt.Errorf("must specify --from")
 	}
+	if kind, name, found := strings.Cut(o.From, "/"); found {
+		if len(o.SrcName) > 0 && o.SrcName != name {
+			return fmt.Errorf("--from %s conflicts with --src-name %s", o.From, o.SrcName)
+		}
+		o.From, o.SrcName = kind, name
+	}
 	if len(o.SrcName) == 0 {
 		return fmt.Errorf("must specify --src-name")
 	}
-	if len(o.DstName) == 0 && !o.IsCreate {
-		return fmt.Errorf("must specify --dst-name")
+	if len(o.DstName) == 0 {
+		if !o.IsCreate {
+			return fmt.Errorf("must specify --dst-name")
+		}
+		o.DstName = o.SrcName
+	}
+	if o.outputFormatSpecified() && !o.IsCreate {
+		return fmt.Errorf("--output is only supported with --create")
+	}
+	if o.Prune {
+		if o.outputFormatSpecified() {
+			return fmt.Errorf("--prune cannot be used with --output")
+		}
+		if o.IsCreate && !o.IsCopy {
+			return fmt.Errorf("--prune requires --copy with --create, otherwise the created workload has no replicas")
+		}
+		if o.PruneTimeout <= 0 {
+			return fmt.Errorf("--prune-timeout must be greater than zero")
+		}
 	}
 
 	switch args[0] {
@@ -137,3 +178,7 @@ func (o *migrateOptions) Run(f cmdutil.Factory, cmd *cobra.Command) error {
 	}
 	return nil
 }
+
+func (o *migrateOptions) outputFormatSpecified() bool {
+	return o.PrintFlags.OutputFormat != nil && len(*o.PrintFlags.OutputFormat) > 0
+}
@@ -17,15 +17,19 @@ limitations under the License.
 package migrate
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
 	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	"github.com/openkruise/kruise-tools/pkg/creation"
 	clonesetcreation "github.com/openkruise/kruise-tools/pkg/creation/cloneset"
 	"github.com/openkruise/kruise-tools/pkg/migration"
 	clonesetmigration "github.com/openkruise/kruise-tools/pkg/migration/cloneset"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
 
@@ -43,11 +47,30 @@ func (o *migrateOptions) migrateCloneSet(f cmdutil.Factory, cmd *cobra.Command)
 		}
 
 		opts := creation.Options{CopyReplicas: o.IsCopy}
+		if o.Replicas >= 0 {
+			opts.Replicas = &o.Replicas
+		}
+
+		if o.outputFormatSpecified() {
+			dst, err := ctrl.Generate(o.SrcRef, o.DstRef, opts)
+			if err != nil {
+				return err
+			}
+			printer, err := o.PrintFlags.ToPrinter()
+			if err != nil {
+				return err
+			}
+			return printer.PrintObj(dst, o.Out)
+		}
+
 		if err := ctrl.Create(o.SrcRef, o.DstRef, opts); err != nil {
 			return err
 		}
 
 		internalcmdutil.Print(fmt.Sprintf("Successfully created from %s/%s to %s/%s", o.From, o.SrcName, o.To, o.DstName))
+		if o.Prune {
+			return o.pruneCloneSetSource(cfg)
+		}
 
 	} else {
 
@@ -89,6 +112,9 @@ func (o *migrateOptions) migrateCloneSet(f cmdutil.Factory, cmd *cobra.Command)
 			case migration.MigrateSucceeded:
 				internalcmdutil.Print(fmt.Sprintf("Successfully migrated %v replicas from %s/%s to %s/%s",
 					newResult.DstMigratedReplicas, o.From, o.SrcName, o.To, o.DstName))
+				if o.Prune {
+					return o.pruneCloneSetSource(cfg)
+				}
 				return nil
 			case migration.MigrateFailed:
 				return fmt.Errorf("failed to migrate: %v", newResult.Message)
@@ -100,3 +126,21 @@ func (o *migrateOptions) migrateCloneSet(f cmdutil.Factory, cmd *cobra.Command)
 
 	return nil
 }
+
+func (o *migrateOptions) pruneCloneSetSource(cfg *rest.Config) error {
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	kruiseClient, err := kruiseclientsets.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	internalcmdutil.Print(fmt.Sprintf("Waiting up to %v for %s/%s to become healthy before pruning %s/%s", o.PruneTimeout, o.To, o.DstName, o.From, o.SrcName))
+	if err := o.pruneDeployment(context.TODO(), kubeClient, kruiseClient); err != nil {
+		return err
+	}
+	internalcmdutil.Print(fmt.Sprintf("Successfully pruned %s/%s", o.From, o.SrcName))
+	return nil
+}
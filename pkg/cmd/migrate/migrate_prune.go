@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// prunePollInterval is how often the CloneSet is checked while waiting to prune the Deployment.
+var prunePollInterval = time.Second
+
+// pruneDeployment deletes the source Deployment once the destination CloneSet is healthy and the
+// Deployment has no replicas left. If that does not happen within --prune-timeout, the Deployment
+// is kept and the returned error tells what was still missing.
+func (o *migrateOptions) pruneDeployment(ctx context.Context, kubeClient kubernetes.Interface, kruiseClient kruiseclientsets.Interface) error {
+	var notReady string
+	err := wait.PollUntilContextTimeout(ctx, prunePollInterval, o.PruneTimeout, true, func(ctx context.Context) (bool, error) {
+		cs, err := kruiseClient.AppsV1alpha1().CloneSets(o.Namespace).Get(ctx, o.DstName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if notReady = cloneSetNotHealthyReason(cs); notReady != "" {
+			return false, nil
+		}
+
+		deploy, err := kubeClient.AppsV1().Deployments(o.Namespace).Get(ctx, o.SrcName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		replicas := deploy.Status.Replicas
+		if deploy.Spec.Replicas != nil && *deploy.Spec.Replicas > replicas {
+			replicas = *deploy.Spec.Replicas
+		}
+		if replicas > 0 {
+			notReady = fmt.Sprintf("%s/%s still has %d replicas", o.From, o.SrcName, replicas)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		if wait.Interrupted(err) {
+			return fmt.Errorf("keeping %s/%s, it could not be pruned within %v: %s", o.From, o.SrcName, o.PruneTimeout, notReady)
+		}
+		return fmt.Errorf("keeping %s/%s: %v", o.From, o.SrcName, err)
+	}
+
+	if err := kubeClient.AppsV1().Deployments(o.Namespace).Delete(ctx, o.SrcName, metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("failed to prune %s/%s: %v", o.From, o.SrcName, err)
+	}
+	return nil
+}
+
+// cloneSetNotHealthyReason returns why the CloneSet is not healthy yet, or "" once it has observed
+// its latest spec and all of its replicas are updated and available.
+func cloneSetNotHealthyReason(cs *kruiseappsv1alpha1.CloneSet) string {
+	if cs.Status.ObservedGeneration < cs.Generation {
+		return fmt.Sprintf("CloneSet/%s has not observed its latest spec yet", cs.Name)
+	}
+	var replicas int32 = 1
+	if cs.Spec.Replicas != nil {
+		replicas = *cs.Spec.Replicas
+	}
+	if cs.Status.UpdatedReplicas < replicas {
+		return fmt.Sprintf("CloneSet/%s has %d of %d replicas updated", cs.Name, cs.Status.UpdatedReplicas, replicas)
+	}
+	if cs.Status.AvailableReplicas < replicas {
+		return fmt.Sprintf("CloneSet/%s has %d of %d replicas available", cs.Name, cs.Status.AvailableReplicas, replicas)
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func newPruneTestObjects(deployReplicas int32, status kruiseappsv1alpha1.CloneSetStatus) (*appsv1.Deployment, *kruiseappsv1alpha1.CloneSet) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(deployReplicas)},
+		Status:     appsv1.DeploymentStatus{Replicas: deployReplicas},
+	}
+	cs := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 2},
+		Spec:       kruiseappsv1alpha1.CloneSetSpec{Replicas: pointer.Int32(3)},
+		Status:     status,
+	}
+	return deploy, cs
+}
+
+func TestPruneDeployment(t *testing.T) {
+	defer func(interval time.Duration) { prunePollInterval = interval }(prunePollInterval)
+	prunePollInterval = 10 * time.Millisecond
+
+	testCases := []struct {
+		name           string
+		deployReplicas int32
+		status         kruiseappsv1alpha1.CloneSetStatus
+		expectErr      string
+	}{
+		{
+			name:   "healthy",
+			status: kruiseappsv1alpha1.CloneSetStatus{ObservedGeneration: 2, UpdatedReplicas: 3, AvailableReplicas: 3},
+		},
+		{
+			name:      "replicas not available",
+			status:    kruiseappsv1alpha1.CloneSetStatus{ObservedGeneration: 2, UpdatedReplicas: 3, AvailableReplicas: 2},
+			expectErr: "keeping Deployment/web, it could not be pruned within 50ms: CloneSet/web has 2 of 3 replicas available",
+		},
+		{
+			name:      "spec not observed",
+			status:    kruiseappsv1alpha1.CloneSetStatus{ObservedGeneration: 1, UpdatedReplicas: 3, AvailableReplicas: 3},
+			expectErr: "keeping Deployment/web, it could not be pruned within 50ms: CloneSet/web has not observed its latest spec yet",
+		},
+		{
+			name:           "deployment not scaled in",
+			deployReplicas: 1,
+			status:         kruiseappsv1alpha1.CloneSetStatus{ObservedGeneration: 2, UpdatedReplicas: 3, AvailableReplicas: 3},
+			expectErr:      "keeping Deployment/web, it could not be pruned within 50ms: Deployment/web still has 1 replicas",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deploy, cs := newPruneTestObjects(tc.deployReplicas, tc.status)
+			kubeClient := fake.NewSimpleClientset(deploy)
+			kruiseClient := kruisefake.NewSimpleClientset(cs)
+			o := &migrateOptions{
+				Namespace:    "default",
+				From:         "Deployment",
+				SrcName:      "web",
+				To:           "CloneSet",
+				DstName:      "web",
+				Prune:        true,
+				PruneTimeout: 50 * time.Millisecond,
+			}
+
+			err := o.pruneDeployment(context.TODO(), kubeClient, kruiseClient)
+			_, getErr := kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+			if tc.expectErr == "" {
+				assert.NoError(t, err)
+				assert.True(t, errors.IsNotFound(getErr), "expected the deployment to be pruned, got %v", getErr)
+			} else {
+				assert.EqualError(t, err, tc.expectErr)
+				assert.NoError(t, getErr, "expected the deployment to be kept")
+			}
+		})
+	}
+}
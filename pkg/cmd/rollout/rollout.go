@@ -69,6 +69,8 @@ func NewCmdRollout(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 	cmd.AddCommand(NewCmdRolloutStatus(f, streams))
 	cmd.AddCommand(NewCmdRolloutRestart(f, streams))
 	cmd.AddCommand(NewCmdRolloutApprove(f, streams))
+	cmd.AddCommand(NewCmdRolloutSetPartition(f, streams))
+	cmd.AddCommand(NewCmdRolloutDelete(f, streams))
 
 	return cmd
 }
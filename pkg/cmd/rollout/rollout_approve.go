@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -46,6 +47,7 @@ type ApproveOptions struct {
 
 	Builder          func() *resource.Builder
 	Approver         internalpolymorphichelpers.ObjectApproverFunc
+	DryRunStrategy   cmdutil.DryRunStrategy
 	Namespace        string
 	EnforceNamespace bool
 
@@ -64,7 +66,10 @@ var (
 	ApproveExample = templates.Examples(`
 		# approve a kruise rollout resource named "rollout-demo" in "ns-demo" namespace
 		
-		kubectl-kruise rollout approve rollout/rollout-demo -n ns-demo`)
+		kubectl-kruise rollout approve rollout/rollout-demo -n ns-demo
+
+		# check whether the rollout can be approved without approving it
+		kubectl-kruise rollout approve rollout/rollout-demo -n ns-demo --dry-run=server`)
 )
 
 // NewRolloutApproveOptions returns an initialized ApproveOptions instance
@@ -87,13 +92,15 @@ func NewCmdRolloutApprove(f cmdutil.Factory, streams genericclioptions.IOStreams
 		Example:               ApproveExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
-			cmdutil.CheckErr(o.Validate())
+			checkErr(validationError(o.Validate()))
 			cmdutil.CheckErr(o.RunApprove())
 		},
+		ValidArgsFunction: resourceNameCompletionFunc(f, []string{"rollout"}),
 	}
 
 	usage := "identifying the resource to get from a server."
 	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
+	cmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
 }
@@ -105,6 +112,11 @@ func (o *ApproveOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []
 	o.Approver = internalpolymorphichelpers.ObjectApproverFn
 
 	var err error
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
 	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
 	if err != nil {
 		return err
@@ -112,6 +124,7 @@ func (o *ApproveOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []
 
 	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
 		o.PrintFlags.NamePrintFlags.Operation = operation
+		cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
 		return o.PrintFlags.ToPrinter()
 	}
 
@@ -129,15 +142,13 @@ func (o *ApproveOptions) Validate() error {
 
 // RunApprove performs the execution of 'rollout approve' sub command
 func (o ApproveOptions) RunApprove() error {
-	r := o.Builder().
-		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		NamespaceParam(o.Namespace).DefaultNamespace().
-		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
-		ResourceTypeOrNameArgs(true, o.Resources...).
-		ContinueOnError().
-		Latest().
-		Flatten().
-		Do()
+	r := util.BuildResources(o.Builder(), util.ResourceBuilderOptions{
+		Namespace:        o.Namespace,
+		EnforceNamespace: o.EnforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		All:              true,
+		Args:             o.Resources,
+	})
 	if err := r.Err(); err != nil {
 		return err
 	}
@@ -177,13 +188,18 @@ func (o ApproveOptions) RunApprove() error {
 			continue
 		}
 
-		obj, err := util.PatchSubResource(info.Client, info.Mapping.Resource.Resource, "status", info.Namespace, info.Name, info.Namespaced(), types.MergePatchType, patch.Patch, nil)
-		if err != nil {
-			allErrs = append(allErrs, fmt.Errorf("failed to patch: %v", err))
-			continue
+		if o.DryRunStrategy != cmdutil.DryRunClient {
+			patchOptions := &metav1.PatchOptions{}
+			if o.DryRunStrategy == cmdutil.DryRunServer {
+				patchOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			obj, err := util.PatchSubResource(info.Client, info.Mapping.Resource.Resource, "status", info.Namespace, info.Name, info.Namespaced(), types.MergePatchType, patch.Patch, patchOptions)
+			if err != nil {
+				allErrs = append(allErrs, fmt.Errorf("failed to patch: %v", err))
+				continue
+			}
+			info.Refresh(obj, true)
 		}
-
-		info.Refresh(obj, true)
 		printer, err := o.ToPrinter("approved")
 		if err != nil {
 			allErrs = append(allErrs, err)
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	rolloutsapiv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestRunApprove(t *testing.T) {
+	testCases := []struct {
+		name          string
+		stepState     rolloutsapiv1alpha1.CanaryStepState
+		dryRun        cmdutil.DryRunStrategy
+		expectPatch   bool
+		expectDryRun  string
+		expectOut     string
+		expectErrText string
+	}{
+		{
+			name:        "paused rollout is approved",
+			stepState:   rolloutsapiv1alpha1.CanaryStepStatePaused,
+			expectPatch: true,
+			expectOut:   "rollout.rollouts.kruise.io/ro approved\n",
+		},
+		{
+			name:          "rollout which is not paused is rejected",
+			stepState:     rolloutsapiv1alpha1.CanaryStepStateUpgrade,
+			expectErrText: `error: rollouts.rollouts.kruise.io "ro" does not allow to approve, because current canary state is "StepUpgrade" rather than 'StepPaused'`,
+		},
+		{
+			name:      "client dry-run does not patch",
+			stepState: rolloutsapiv1alpha1.CanaryStepStatePaused,
+			dryRun:    cmdutil.DryRunClient,
+			expectOut: "rollout.rollouts.kruise.io/ro approved (dry run)\n",
+		},
+		{
+			name:         "server dry-run patches with dryRun",
+			stepState:    rolloutsapiv1alpha1.CanaryStepStatePaused,
+			dryRun:       cmdutil.DryRunServer,
+			expectPatch:  true,
+			expectDryRun: "All",
+			expectOut:    "rollout.rollouts.kruise.io/ro approved (server dry run)\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rollout := newStatusTestRollout(rolloutsapiv1alpha1.RolloutPhaseProgressing, 1, tc.stepState)
+			codec := scheme.Codecs.LegacyCodec(rolloutsapiv1alpha1.GroupVersion)
+
+			var patched bool
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			tf.Client = &fake.RESTClient{
+				GroupVersion:         schema.GroupVersion{Group: "rollouts.kruise.io", Version: "v1alpha1"},
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					switch p, m := req.URL.Path, req.Method; {
+					case p == "/namespaces/test/rollouts/ro" && m == http.MethodGet:
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, rollout)}, nil
+					case p == "/namespaces/test/rollouts/ro/status" && m == http.MethodPatch:
+						patched = true
+						body, _ := io.ReadAll(req.Body)
+						assert.Contains(t, string(body), `"currentStepState":"StepReady"`)
+						assert.Equal(t, tc.expectDryRun, req.URL.Query().Get("dryRun"))
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, rollout)}, nil
+					default:
+						t.Fatalf("unexpected request: %s %s", m, p)
+						return nil, nil
+					}
+				}),
+			}
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdRolloutApprove(tf, streams)
+			o := NewRolloutApproveOptions(streams)
+			if err := o.Complete(tf, cmd, []string{"rollout/ro"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.DryRunStrategy = tc.dryRun
+
+			err := o.RunApprove()
+			if len(tc.expectErrText) > 0 {
+				assert.EqualError(t, err, tc.expectErrText)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectPatch, patched)
+			assert.Equal(t, tc.expectOut, out.String())
+		})
+	}
+}
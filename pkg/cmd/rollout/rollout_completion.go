@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
+	internalpolymorphichelpers "github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
+	"github.com/spf13/cobra"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+// resourceNameLister returns the names of the resources of a type, e.g. cloneset or asts.
+type resourceNameLister func(resourceType string) ([]string, error)
+
+// resourceNameCompletionFunc returns the completion function of the resource arguments of a rollout command, which
+// completes the types in validArgs, and the names of the resources of a type in the namespace of the command.
+func resourceNameCompletionFunc(f cmdutil.Factory, validArgs []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return completeResourceNames(validArgs, func(resourceType string) ([]string, error) {
+		return listResourceNames(f, resourceType)
+	})
+}
+
+// completeResourceNames returns a completion function completing the types in validArgs, and the names listed by list
+// for arguments of the form TYPE/NAME, or TYPE NAME. Nothing is completed if the names cannot be listed, e.g. if the
+// type is unknown or the cluster cannot be reached.
+func completeResourceNames(validArgs []string, list resourceNameLister) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// the names following a type given as an argument of its own
+		if len(args) > 0 && !strings.Contains(args[0], "/") {
+			names, err := list(args[0])
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return withPrefix(names, "", toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+
+		resourceType, name, found := strings.Cut(toComplete, "/")
+		if !found {
+			return withPrefix(validArgs, "", toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+		names, err := list(resourceType)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return withPrefix(names, resourceType+"/", name), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// withPrefix returns the values starting with toComplete, prepended with prefix.
+func withPrefix(values []string, prefix, toComplete string) []string {
+	var completions []string
+	for _, value := range values {
+		if strings.HasPrefix(value, toComplete) {
+			completions = append(completions, prefix+value)
+		}
+	}
+	return completions
+}
+
+// listResourceNames lists the names of the resources of resourceType in the namespace of f.
+func listResourceNames(f cmdutil.Factory, resourceType string) ([]string, error) {
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, err
+	}
+	infos, err := f.NewBuilder().
+		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		NamespaceParam(namespace).DefaultNamespace().
+		ResourceTypeOrNameArgs(true, resourceType).
+		Flatten().
+		Do().
+		Infos()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	return names, nil
+}
+
+// revisionLister returns the revision numbers of the target given by args, e.g. cloneset/web or cloneset web.
+type revisionLister func(args []string) ([]int64, error)
+
+// toRevisionCompletionFunc returns the completion function of --to-revision, which completes the revision numbers of
+// the target given as arguments.
+func toRevisionCompletionFunc(f cmdutil.Factory) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return completeRevisions(func(args []string) ([]int64, error) {
+		return listRevisions(f, args, internalpolymorphichelpers.RevisionsFn)
+	})
+}
+
+// completeRevisions returns a completion function completing the revisions listed by list for the arguments given so
+// far. Nothing is completed before a target is given, or if its revisions cannot be listed.
+func completeRevisions(list revisionLister) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		revisions, err := list(args)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		values := make([]string, 0, len(revisions))
+		for _, revision := range revisions {
+			values = append(values, strconv.FormatInt(revision, 10))
+		}
+		return withPrefix(values, "", toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// listRevisions lists the revisions of the single target given by args in the namespace of f. The revisions of a
+// rollout are the ones of the workload it references, as for a rollback.
+func listRevisions(f cmdutil.Factory, args []string, revisions internalpolymorphichelpers.RevisionsFunc) ([]int64, error) {
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, err
+	}
+	infos, err := internalcmdutil.BuildResources(f.NewBuilder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace: namespace,
+		Args:      args,
+	}).Infos()
+	if err != nil {
+		return nil, err
+	}
+	// several targets may not have the same revisions
+	if len(infos) != 1 {
+		return nil, fmt.Errorf("revisions are only listed for a single target, got %d", len(infos))
+	}
+	obj := infos[0].Object
+	if gvk := infos[0].Mapping.GroupVersionKind; gvk.Group == "rollouts.kruise.io" && gvk.Kind == "Rollout" {
+		workloadGVK, name, err := internalpolymorphichelpers.ResolveWorkloadRef(obj)
+		if err != nil {
+			return nil, err
+		}
+		if obj, err = f.NewBuilder().
+			WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
+			NamespaceParam(namespace).DefaultNamespace().
+			ResourceNames(fmt.Sprintf("%s.%s.%s", workloadGVK.Kind, workloadGVK.Version, workloadGVK.Group), name).
+			Do().
+			Object(); err != nil {
+			return nil, err
+		}
+	}
+	return revisions(f, obj)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"fmt"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestCompleteResourceNames(t *testing.T) {
+	list := func(resourceType string) ([]string, error) {
+		switch resourceType {
+		case "cloneset":
+			return []string{"web", "worker", "api"}, nil
+		case "asts":
+			return []string{"db"}, nil
+		case "rollout":
+			return []string{"rollout-demo"}, nil
+		}
+		return nil, fmt.Errorf("the server doesn't have a resource type %q", resourceType)
+	}
+	complete := completeResourceNames([]string{"cloneset", "asts", "rollout"}, list)
+
+	testCases := []struct {
+		name              string
+		args              []string
+		toComplete        string
+		expectCompletions []string
+	}{
+		{
+			name:              "types",
+			toComplete:        "",
+			expectCompletions: []string{"cloneset", "asts", "rollout"},
+		},
+		{
+			name:              "type prefix",
+			toComplete:        "ro",
+			expectCompletions: []string{"rollout"},
+		},
+		{
+			name:              "names of a type",
+			toComplete:        "cloneset/",
+			expectCompletions: []string{"cloneset/web", "cloneset/worker", "cloneset/api"},
+		},
+		{
+			name:              "name prefix",
+			toComplete:        "cloneset/w",
+			expectCompletions: []string{"cloneset/web", "cloneset/worker"},
+		},
+		{
+			name:              "names after a type argument",
+			args:              []string{"asts"},
+			toComplete:        "",
+			expectCompletions: []string{"db"},
+		},
+		{
+			name:              "more names after a type/name argument",
+			args:              []string{"cloneset/web"},
+			toComplete:        "rollout/",
+			expectCompletions: []string{"rollout/rollout-demo"},
+		},
+		{
+			name:       "list error",
+			toComplete: "unknown/",
+		},
+		{
+			name: "list error after a type argument",
+			args: []string{"unknown"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			completions, directive := complete(&cobra.Command{}, tc.args, tc.toComplete)
+			assert.Equal(t, tc.expectCompletions, completions)
+			assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+		})
+	}
+}
+
+func TestCompleteRevisions(t *testing.T) {
+	tf := newUndoTestFactory(t, map[string]runtime.Object{
+		"clonesets/web":   newUndoTestCloneSet("web"),
+		"clonesets/other": newUndoTestCloneSet("other"),
+		"rollouts/ro":     newUndoTestRollout("ro", "web"),
+	})
+	defer tf.Cleanup()
+	// the history of web has three revisions, the other workloads have none
+	revisions := func(_ genericclioptions.RESTClientGetter, obj runtime.Object) ([]int64, error) {
+		if cloneSet, ok := obj.(*kruiseappsv1alpha1.CloneSet); ok && cloneSet.Name == "web" {
+			return []int64{1, 2, 3}, nil
+		}
+		return nil, fmt.Errorf("no revision history can be found for %T", obj)
+	}
+	complete := completeRevisions(func(args []string) ([]int64, error) {
+		return listRevisions(tf, args, revisions)
+	})
+
+	testCases := []struct {
+		name              string
+		args              []string
+		toComplete        string
+		expectCompletions []string
+	}{
+		{
+			name: "no target yet",
+		},
+		{
+			name:              "revisions of a target",
+			args:              []string{"cloneset/web"},
+			expectCompletions: []string{"1", "2", "3"},
+		},
+		{
+			name:              "revisions of a type and name",
+			args:              []string{"cloneset", "web"},
+			toComplete:        "2",
+			expectCompletions: []string{"2"},
+		},
+		{
+			name:              "revisions of the workload of a rollout",
+			args:              []string{"rollout/ro"},
+			expectCompletions: []string{"1", "2", "3"},
+		},
+		{
+			name: "several targets",
+			args: []string{"cloneset/web", "cloneset/other"},
+		},
+		{
+			name: "unknown target",
+			args: []string{"cloneset/missing"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			completions, directive := complete(&cobra.Command{}, tc.args, tc.toComplete)
+			assert.Equal(t, tc.expectCompletions, completions)
+			assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+		})
+	}
+}
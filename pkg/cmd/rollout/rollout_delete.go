@@ -0,0 +1,296 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"fmt"
+
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// CascadeOrphan deletes the rollout only, the resources it created are kept
+	CascadeOrphan = "orphan"
+	// CascadeCanary deletes the canary resources owned by the rollout along with it
+	CascadeCanary = "canary"
+)
+
+// canaryKinds are the kinds of the resources a rollout creates for its canary, such as the canary Service and
+// Ingress of the traffic routing, and the canary Deployment of a Deployment workload.
+var canaryKinds = []schema.GroupVersionKind{
+	{Version: "v1", Kind: "Service"},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+}
+
+// DeleteOptions is the start of the data required to perform the operation.  As new fields are added, add them here instead of
+// referencing the cmd.Flags()
+type DeleteOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	ToPrinter  func(string) (printers.ResourcePrinter, error)
+
+	Cascade string
+
+	Builder          func() *resource.Builder
+	Namespace        string
+	EnforceNamespace bool
+	DryRunStrategy   cmdutil.DryRunStrategy
+	Resources        []string
+	RESTMapper       meta.RESTMapper
+	ClientForMapping func(*meta.RESTMapping) (resource.RESTClient, error)
+
+	resource.FilenameOptions
+	genericclioptions.IOStreams
+}
+
+var (
+	deleteLong = templates.LongDesc(`
+		Delete a rollout.
+
+		With --cascade=orphan, the default, only the rollout is deleted, and the resources it created
+		are kept. With --cascade=canary, the canary Services, Ingresses and Deployments owned by the
+		rollout are deleted first. Resources the rollout does not own, such as the stable Service and
+		the workload, are kept either way.`)
+
+	deleteExample = templates.Examples(`
+		# Delete the rollout-demo rollout, keeping the resources it created
+		kubectl-kruise rollout delete rollout/rollout-demo
+
+		# Delete the rollout-demo rollout and its canary resources
+		kubectl-kruise rollout delete rollout/rollout-demo --cascade=canary
+
+		# List the resources that would be deleted without deleting them
+		kubectl-kruise rollout delete rollout/rollout-demo --cascade=canary --dry-run=client`)
+)
+
+// NewRolloutDeleteOptions returns an initialized DeleteOptions instance
+func NewRolloutDeleteOptions(streams genericclioptions.IOStreams) *DeleteOptions {
+	return &DeleteOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("deleted").WithTypeSetter(internalapi.GetScheme()),
+		Cascade:    CascadeOrphan,
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdRolloutDelete returns a Command instance for 'rollout delete' sub command
+func NewCmdRolloutDelete(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewRolloutDeleteOptions(streams)
+
+	validArgs := []string{"rollout"}
+
+	cmd := &cobra.Command{
+		Use:                   "delete RESOURCE",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Delete a rollout"),
+		Long:                  deleteLong,
+		Example:               deleteExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			checkErr(validationError(o.Validate()))
+			cmdutil.CheckErr(o.RunDelete())
+		},
+		ValidArgsFunction: resourceNameCompletionFunc(f, validArgs),
+	}
+
+	cmd.Flags().StringVar(&o.Cascade, "cascade", o.Cascade, `Must be "orphan" or "canary". "canary" also deletes the canary resources owned by the rollout.`)
+	usage := "identifying the resource to get from a server."
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
+	cmdutil.AddDryRunFlag(cmd)
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *DeleteOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.Resources = args
+
+	var err error
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
+		o.PrintFlags.NamePrintFlags.Operation = operation
+		cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+		return o.PrintFlags.ToPrinter()
+	}
+
+	if o.RESTMapper, err = f.ToRESTMapper(); err != nil {
+		return err
+	}
+	o.ClientForMapping = f.ClientForMapping
+	o.Builder = f.NewBuilder
+	return nil
+}
+
+func (o *DeleteOptions) Validate() error {
+	if len(o.Resources) == 0 && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
+		return fmt.Errorf("required resource not specified")
+	}
+	if o.Cascade != CascadeOrphan && o.Cascade != CascadeCanary {
+		return fmt.Errorf("--cascade must be %q or %q, got %q", CascadeOrphan, CascadeCanary, o.Cascade)
+	}
+	return nil
+}
+
+// RunDelete performs the execution of 'rollout delete' sub command
+func (o *DeleteOptions) RunDelete() error {
+	r := internalcmdutil.BuildResources(o.Builder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        o.Namespace,
+		EnforceNamespace: o.EnforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		All:              true,
+		Args:             o.Resources,
+	})
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	var allErrs []error
+	infos, err := r.Infos()
+	if err != nil {
+		// restore previous command behavior where
+		// an error caused by retrieving infos due to
+		// at least a single broken object did not result
+		// in an immediate return, but rather an overall
+		// aggregation of errors.
+		allErrs = append(allErrs, err)
+	}
+
+	for _, info := range infos {
+		gvk := info.Mapping.GroupVersionKind
+		if gvk.Group != "rollouts.kruise.io" || gvk.Kind != "Rollout" {
+			resourceString := info.Mapping.Resource.Resource
+			if len(info.Mapping.Resource.Group) > 0 {
+				resourceString = resourceString + "." + info.Mapping.Resource.Group
+			}
+			allErrs = append(allErrs, fmt.Errorf("error: %s %q only rollouts can be deleted", resourceString, info.Name))
+			continue
+		}
+
+		// the canary resources are deleted first, since they are found by their owner references to the rollout
+		if o.Cascade == CascadeCanary {
+			if err := o.deleteCanaryResources(info); err != nil {
+				allErrs = append(allErrs, fmt.Errorf("failed to delete the canary resources of rollout %q: %v", info.Name, err))
+				continue
+			}
+		}
+
+		// the resources the rollout still owns are orphaned rather than garbage collected
+		policy := metav1.DeletePropagationOrphan
+		if err := o.deleteObject(info.Client, info.Mapping, info.Object, &metav1.DeleteOptions{PropagationPolicy: &policy}); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// deleteCanaryResources deletes the resources of the canary kinds in the namespace of the rollout of info which are
+// owned by it. Kinds which are not served by the cluster are skipped.
+func (o *DeleteOptions) deleteCanaryResources(info *resource.Info) error {
+	accessor, err := meta.Accessor(info.Object)
+	if err != nil {
+		return err
+	}
+
+	for _, gvk := range canaryKinds {
+		mapping, err := o.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			continue
+		}
+		client, err := o.ClientForMapping(mapping)
+		if err != nil {
+			return err
+		}
+		list, err := resource.NewHelper(client, mapping).List(info.Namespace, gvk.GroupVersion().String(), &metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		objs, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			if !isOwnedBy(obj, accessor.GetUID()) {
+				continue
+			}
+			if err := o.deleteObject(client, mapping, obj, &metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteObject deletes obj unless in a client dry-run, and prints it.
+func (o *DeleteOptions) deleteObject(client resource.RESTClient, mapping *meta.RESTMapping, obj runtime.Object, options *metav1.DeleteOptions) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if o.DryRunStrategy != cmdutil.DryRunClient {
+		_, err := resource.NewHelper(client, mapping).
+			DryRun(o.DryRunStrategy == cmdutil.DryRunServer).
+			DeleteWithOptions(accessor.GetNamespace(), accessor.GetName(), options)
+		if err != nil {
+			return err
+		}
+	}
+
+	printer, err := o.ToPrinter("deleted")
+	if err != nil {
+		return err
+	}
+	// the items of a list have no kind set, which the name printer needs
+	obj.GetObjectKind().SetGroupVersionKind(mapping.GroupVersionKind)
+	return printer.PrintObj(obj, o.Out)
+}
+
+// isOwnedBy returns whether obj has an owner reference to the object with uid.
+func isOwnedBy(obj runtime.Object, uid types.UID) bool {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	for _, ref := range accessor.GetOwnerReferences() {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	rolloutsapiv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func newDeleteTestFactory(t *testing.T, deleted *[]string, deleteBodies *[]string) *cmdtesting.TestFactory {
+	rollout := newUndoTestRollout("abc", "web")
+	rollout.UID = "rollout-uid"
+	ownedByRollout := []metav1.OwnerReference{{APIVersion: rolloutsapiv1beta1.GroupVersion.String(), Kind: "Rollout", Name: "abc", UID: "rollout-uid"}}
+	ownedByOther := []metav1.OwnerReference{{APIVersion: rolloutsapiv1beta1.GroupVersion.String(), Kind: "Rollout", Name: "other", UID: "other-uid"}}
+
+	lists := map[string]runtime.Object{
+		"/namespaces/test/services": &corev1.ServiceList{Items: []corev1.Service{
+			{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-canary", Namespace: "test", OwnerReferences: ownedByRollout}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "other-canary", Namespace: "test", OwnerReferences: ownedByOther}},
+		}},
+		"/namespaces/test/ingresses": &networkingv1.IngressList{Items: []networkingv1.Ingress{
+			{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-canary", Namespace: "test", OwnerReferences: ownedByRollout}},
+		}},
+		"/namespaces/test/deployments": &appsv1.DeploymentList{Items: []appsv1.Deployment{
+			{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test"}},
+		}},
+	}
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Group: "rollouts.kruise.io", Version: "v1beta1"},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/namespaces/test/rollouts/abc" && m == http.MethodGet:
+				codec := scheme.Codecs.LegacyCodec(rolloutsapiv1beta1.GroupVersion)
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, rollout)}, nil
+			case lists[p] != nil && m == http.MethodGet:
+				codec := scheme.Codecs.LegacyCodec(corev1.SchemeGroupVersion, networkingv1.SchemeGroupVersion, appsv1.SchemeGroupVersion)
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, lists[p])}, nil
+			case m == http.MethodDelete:
+				*deleted = append(*deleted, p)
+				body, _ := io.ReadAll(req.Body)
+				*deleteBodies = append(*deleteBodies, string(body))
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.StringBody(`{"kind":"Status","apiVersion":"v1","status":"Success"}`)}, nil
+			default:
+				t.Fatalf("unexpected request: %s %s", m, p)
+				return nil, nil
+			}
+		}),
+	}
+	return tf
+}
+
+func TestRunDelete(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cascade       string
+		dryRun        string
+		expectDeleted []string
+		expectOut     string
+	}{
+		{
+			name:          "orphan",
+			cascade:       CascadeOrphan,
+			expectDeleted: []string{"/namespaces/test/rollouts/abc"},
+			expectOut:     "rollout.rollouts.kruise.io/abc deleted\n",
+		},
+		{
+			name:    "canary",
+			cascade: CascadeCanary,
+			expectDeleted: []string{
+				"/namespaces/test/services/web-canary",
+				"/namespaces/test/ingresses/web-canary",
+				"/namespaces/test/rollouts/abc",
+			},
+			expectOut: "service/web-canary deleted\n" +
+				"ingress.networking.k8s.io/web-canary deleted\n" +
+				"rollout.rollouts.kruise.io/abc deleted\n",
+		},
+		{
+			name:    "canary client dry-run",
+			cascade: CascadeCanary,
+			dryRun:  "client",
+			expectOut: "service/web-canary deleted (dry run)\n" +
+				"ingress.networking.k8s.io/web-canary deleted (dry run)\n" +
+				"rollout.rollouts.kruise.io/abc deleted (dry run)\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var deleted, deleteBodies []string
+			tf := newDeleteTestFactory(t, &deleted, &deleteBodies)
+			defer tf.Cleanup()
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdRolloutDelete(tf, streams)
+			if len(tc.dryRun) > 0 {
+				assert.NoError(t, cmd.Flags().Set("dry-run", tc.dryRun))
+			}
+			o := NewRolloutDeleteOptions(streams)
+			o.Cascade = tc.cascade
+			assert.NoError(t, o.Complete(tf, cmd, []string{"rollout/abc"}))
+			assert.NoError(t, o.Validate())
+
+			assert.NoError(t, o.RunDelete())
+			assert.Equal(t, tc.expectDeleted, deleted)
+			assert.Equal(t, tc.expectOut, out.String())
+			if len(deleteBodies) > 0 {
+				// the rollout is deleted last, orphaning the resources it still owns
+				assert.Contains(t, deleteBodies[len(deleteBodies)-1], `"propagationPolicy":"Orphan"`)
+			}
+		})
+	}
+}
+
+func TestDeleteValidate(t *testing.T) {
+	o := NewRolloutDeleteOptions(genericclioptions.NewTestIOStreamsDiscard())
+	assert.EqualError(t, o.Validate(), "required resource not specified")
+
+	o.Resources = []string{"rollout/abc"}
+	o.Cascade = "background"
+	assert.EqualError(t, o.Validate(), `--cascade must be "orphan" or "canary", got "background"`)
+}
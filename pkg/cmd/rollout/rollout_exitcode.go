@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"errors"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	uexec "k8s.io/utils/exec"
+)
+
+// Exit codes of the rollout commands, so that scripts can tell why a command failed.
+const (
+	// ExitCodeSuccess is returned when every target was processed.
+	ExitCodeSuccess = 0
+	// ExitCodeError is returned when the command failed.
+	ExitCodeError = 1
+	// ExitCodeValidation is returned when the arguments or flags of the command are invalid.
+	ExitCodeValidation = 2
+	// ExitCodePartialFailure is returned when some targets of the command were processed and others failed.
+	ExitCodePartialFailure = 3
+)
+
+// exitCodeError is an error the rollout commands exit with a specific code for.
+type exitCodeError struct {
+	err  error
+	code int
+}
+
+var _ uexec.ExitError = &exitCodeError{}
+
+func (e *exitCodeError) Error() string   { return e.err.Error() }
+func (e *exitCodeError) String() string  { return e.err.Error() }
+func (e *exitCodeError) Exited() bool    { return true }
+func (e *exitCodeError) ExitStatus() int { return e.code }
+func (e *exitCodeError) Unwrap() error   { return e.err }
+
+// validationError marks err, if any, as an invalid use of the command.
+func validationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitCodeError{err: err, code: ExitCodeValidation}
+}
+
+// partialFailureError marks err, if any, as the failure of some of the targets of the command.
+func partialFailureError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitCodeError{err: err, code: ExitCodePartialFailure}
+}
+
+// ExitCode returns the code a rollout command exits with when it fails with err.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+	var exitErr uexec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus()
+	}
+	return ExitCodeError
+}
+
+// checkErr prints err and exits like cmdutil.CheckErr, with the exit code carried by err.
+func checkErr(err error) {
+	var exitErr *exitCodeError
+	if !errors.As(err, &exitErr) {
+		cmdutil.CheckErr(err)
+		return
+	}
+	// cmdutil.CheckErr prints the message of an ExitError as is, so it is formatted here
+	// the way cmdutil.CheckErr formats other errors
+	var msg string
+	if agg, ok := exitErr.err.(utilerrors.Aggregate); ok && len(agg.Errors()) > 1 {
+		msg = strings.TrimSuffix(cmdutil.MultipleErrors("", agg.Errors()), "\n")
+	} else {
+		msg = exitErr.err.Error()
+		if standardMsg, ok := cmdutil.StandardErrorMessage(exitErr.err); ok {
+			msg = standardMsg
+		} else if !strings.HasPrefix(msg, "error: ") {
+			msg = "error: " + msg
+		}
+	}
+	cmdutil.CheckErr(uexec.CodeExitError{Err: errors.New(msg), Code: exitErr.code})
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func TestRunUndoExitCode(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"rollouts/ro":   newUndoTestRollout("ro", "bar"),
+		"pods/web": &corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		args        []string
+		errs        map[string]error
+		parallelism int
+		expectCode  int
+	}{
+		{
+			name:       "all targets succeed",
+			args:       []string{"cloneset/foo", "cloneset/bar"},
+			expectCode: ExitCodeSuccess,
+		},
+		{
+			name:       "single target fails",
+			args:       []string{"cloneset/foo"},
+			errs:       map[string]error{"foo": fmt.Errorf("foo failed")},
+			expectCode: ExitCodeError,
+		},
+		{
+			name:       "all targets fail",
+			args:       []string{"cloneset/foo", "cloneset/bar"},
+			errs:       map[string]error{"foo": fmt.Errorf("foo failed"), "bar": fmt.Errorf("bar failed")},
+			expectCode: ExitCodeError,
+		},
+		{
+			name:       "some targets fail",
+			args:       []string{"cloneset/foo", "cloneset/bar"},
+			errs:       map[string]error{"bar": fmt.Errorf("bar failed")},
+			expectCode: ExitCodePartialFailure,
+		},
+		{
+			name:        "some targets fail in parallel",
+			args:        []string{"cloneset/foo", "cloneset/bar"},
+			errs:        map[string]error{"bar": fmt.Errorf("bar failed")},
+			parallelism: 2,
+			expectCode:  ExitCodePartialFailure,
+		},
+		{
+			name:       "the workload of a rollout fails",
+			args:       []string{"cloneset/foo", "rollout/ro"},
+			errs:       map[string]error{"bar": fmt.Errorf("bar failed")},
+			expectCode: ExitCodePartialFailure,
+		},
+		{
+			name:       "duplicate targets",
+			args:       []string{"cloneset/bar", "rollout/ro"},
+			expectCode: ExitCodeSuccess,
+		},
+		{
+			name:       "unsupported kind",
+			args:       []string{"cloneset/foo", "pod/web"},
+			expectCode: ExitCodeError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			o, err := newUndoTestOptions(tf, &concurrentRollbacker{errs: tc.errs}, tc.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.parallelism > 0 {
+				o.Parallelism = tc.parallelism
+			}
+			assert.Equal(t, tc.expectCode, ExitCode(o.RunUndo()))
+		})
+	}
+
+	o := &UndoOptions{Resources: []string{"cloneset/foo"}, ToRevision: -1}
+	assert.Equal(t, ExitCodeValidation, ExitCode(validationError(o.Validate())))
+}
+
+func TestCheckErrExitCode(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		expectMsg  string
+		expectCode int
+	}{
+		{
+			name:       "generic error",
+			err:        fmt.Errorf("failed"),
+			expectMsg:  "error: failed",
+			expectCode: ExitCodeError,
+		},
+		{
+			name:       "validation error",
+			err:        validationError(fmt.Errorf("required resource not specified")),
+			expectMsg:  "error: required resource not specified",
+			expectCode: ExitCodeValidation,
+		},
+		{
+			name:       "partial failure",
+			err:        partialFailureError(utilerrors.NewAggregate([]error{fmt.Errorf("foo failed"), fmt.Errorf("bar failed")})),
+			expectMsg:  "foo failed\nbar failed",
+			expectCode: ExitCodePartialFailure,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var msg string
+			code := -1
+			cmdutil.BehaviorOnFatal(func(m string, c int) {
+				msg, code = m, c
+			})
+			defer cmdutil.DefaultBehaviorOnFatal()
+
+			checkErr(tc.err)
+			assert.Equal(t, tc.expectMsg, msg)
+			assert.Equal(t, tc.expectCode, code)
+		})
+	}
+}
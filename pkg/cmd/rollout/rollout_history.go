@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	internalpolymorphichelpers "github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
 
 	"github.com/spf13/cobra"
@@ -28,7 +29,6 @@ import (
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
-	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
 )
@@ -45,7 +45,10 @@ var (
 		kubectl-kruise rollout history asts/abc
 
 		# View the details of daemonset revision 3
-		kubectl-kruise rollout history daemonset/abc --revision=3`)
+		kubectl-kruise rollout history daemonset/abc --revision=3
+
+		# View the rollout history of a cloneset with the images, update strategy and pods of each revision
+		kubectl-kruise rollout history cloneset/abc -o wide`)
 )
 
 // RolloutHistoryOptions holds the options for 'rollout history' sub command
@@ -54,6 +57,8 @@ type RolloutHistoryOptions struct {
 	ToPrinter  func(string) (printers.ResourcePrinter, error)
 
 	Revision int64
+	// Wide lists the images, update strategy and pods of each revision, it is set by -o wide
+	Wide bool
 
 	Builder          func() *resource.Builder
 	Resources        []string
@@ -89,10 +94,10 @@ func NewCmdRolloutHistory(f cmdutil.Factory, streams genericclioptions.IOStreams
 		Example:               historyExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
-			cmdutil.CheckErr(o.Validate())
+			checkErr(validationError(o.Validate()))
 			cmdutil.CheckErr(o.Run())
 		},
-		ValidArgs: validArgs,
+		ValidArgsFunction: resourceNameCompletionFunc(f, validArgs),
 	}
 
 	cmd.Flags().Int64Var(&o.Revision, "revision", o.Revision, "See the details, including podTemplate of the revision specified")
@@ -114,6 +119,12 @@ func (o *RolloutHistoryOptions) Complete(f cmdutil.Factory, cmd *cobra.Command,
 		return err
 	}
 
+	// wide is not a format of the printer flags, the overview of the revisions is widened instead
+	if o.PrintFlags.OutputFormat != nil && *o.PrintFlags.OutputFormat == "wide" {
+		o.Wide = true
+		*o.PrintFlags.OutputFormat = ""
+	}
+
 	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
 		o.PrintFlags.NamePrintFlags.Operation = operation
 		return o.PrintFlags.ToPrinter()
@@ -134,6 +145,9 @@ func (o *RolloutHistoryOptions) Validate() error {
 	if o.Revision < 0 {
 		return fmt.Errorf("revision must be a positive integer: %v", o.Revision)
 	}
+	if o.Wide && o.Revision > 0 {
+		return fmt.Errorf("--revision cannot be used with -o wide")
+	}
 
 	return nil
 }
@@ -141,15 +155,13 @@ func (o *RolloutHistoryOptions) Validate() error {
 // Run performs the execution of 'rollout history' sub command
 func (o *RolloutHistoryOptions) Run() error {
 
-	r := o.Builder().
-		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		NamespaceParam(o.Namespace).DefaultNamespace().
-		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
-		ResourceTypeOrNameArgs(true, o.Resources...).
-		ContinueOnError().
-		Latest().
-		Flatten().
-		Do()
+	r := internalcmdutil.BuildResources(o.Builder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        o.Namespace,
+		EnforceNamespace: o.EnforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		All:              true,
+		Args:             o.Resources,
+	})
 	if err := r.Err(); err != nil {
 		return err
 	}
@@ -164,7 +176,17 @@ func (o *RolloutHistoryOptions) Run() error {
 		if err != nil {
 			return err
 		}
-		historyInfo, err := historyViewer.ViewHistory(info.Namespace, info.Name, o.Revision)
+		var historyInfo string
+		if o.Wide {
+			wideViewer, ok := historyViewer.(internalpolymorphichelpers.WideHistoryViewer)
+			if !ok {
+				// the revisions of a Deployment are its ReplicaSets, which are listed wide by 'kubectl get'
+				return fmt.Errorf("-o wide is not supported for %s, it is only supported for workloads recording their revisions in ControllerRevisions", mapping.GroupVersionKind.Kind)
+			}
+			historyInfo, err = wideViewer.ViewHistoryWide(info.Namespace, info.Name)
+		} else {
+			historyInfo, err = historyViewer.ViewHistory(info.Namespace, info.Name, o.Revision)
+		}
 		if err != nil {
 			return err
 		}
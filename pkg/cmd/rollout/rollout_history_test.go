@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestRunHistoryWideDeployment(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+	}
+	tf := newUndoTestFactory(t, map[string]runtime.Object{"deployments/web": deployment})
+	defer tf.Cleanup()
+
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdRolloutHistory(tf, streams)
+	o := NewRolloutHistoryOptions(streams)
+	output := "wide"
+	o.PrintFlags.OutputFormat = &output
+	assert.NoError(t, o.Complete(tf, cmd, []string{"deployment/web"}))
+	assert.True(t, o.Wide)
+	assert.NoError(t, o.Validate())
+
+	// the history of a Deployment is only listed compact, its revisions are ReplicaSets
+	assert.EqualError(t, o.Run(), "-o wide is not supported for Deployment, it is only supported for workloads recording their revisions in ControllerRevisions")
+}
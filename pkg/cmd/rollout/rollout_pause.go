@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	internalpolymorphichelpers "github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
 
 	"github.com/spf13/cobra"
@@ -57,7 +58,7 @@ var (
 
 		Paused resources will not be reconciled by a controller.
 		Use "kubectl rollout resume" to resume a paused resource.
-		Currently  deployments, clonesets, rollouts support being paused.`)
+		Currently deployments, clonesets, advanced statefulsets and rollouts support being paused.`)
 
 	pauseExample = templates.Examples(`
 		# Mark the nginx deployment as paused. Any current state of
@@ -67,14 +68,19 @@ var (
 		kubectl-kruise rollout pause deployment/nginx`)
 )
 
-// NewCmdRolloutPause returns a Command instance for 'rollout pause' sub command
-func NewCmdRolloutPause(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
-	o := &PauseOptions{
+// NewRolloutPauseOptions returns an initialized PauseOptions instance
+func NewRolloutPauseOptions(streams genericclioptions.IOStreams) *PauseOptions {
+	return &PauseOptions{
 		PrintFlags: genericclioptions.NewPrintFlags("paused").WithTypeSetter(internalapi.GetScheme()),
 		IOStreams:  streams,
 	}
+}
+
+// NewCmdRolloutPause returns a Command instance for 'rollout pause' sub command
+func NewCmdRolloutPause(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewRolloutPauseOptions(streams)
 
-	validArgs := []string{"deployment", "cloneset", "rollout"}
+	validArgs := []string{"deployment", "cloneset", "advanced statefulset", "rollout"}
 
 	cmd := &cobra.Command{
 		Use:                   "pause RESOURCE",
@@ -84,10 +90,10 @@ func NewCmdRolloutPause(f cmdutil.Factory, streams genericclioptions.IOStreams)
 		Example:               pauseExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
-			cmdutil.CheckErr(o.Validate())
+			checkErr(validationError(o.Validate()))
 			cmdutil.CheckErr(o.RunPause())
 		},
-		ValidArgs: validArgs,
+		ValidArgsFunction: resourceNameCompletionFunc(f, validArgs),
 	}
 
 	o.PrintFlags.AddFlags(cmd)
@@ -127,15 +133,13 @@ func (o *PauseOptions) Validate() error {
 
 // RunPause performs the execution of 'rollout pause' sub command
 func (o *PauseOptions) RunPause() error {
-	r := o.Builder().
-		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		NamespaceParam(o.Namespace).DefaultNamespace().
-		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
-		ResourceTypeOrNameArgs(true, o.Resources...).
-		ContinueOnError().
-		Latest().
-		Flatten().
-		Do()
+	r := internalcmdutil.BuildResources(o.Builder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        o.Namespace,
+		EnforceNamespace: o.EnforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		All:              true,
+		Args:             o.Resources,
+	})
 	if err := r.Err(); err != nil {
 		return err
 	}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func TestRunPauseResumeIsIdempotent(t *testing.T) {
+	paused := newUndoTestCloneSet("paused")
+	paused.Spec.UpdateStrategy.Paused = true
+	objs := map[string]runtime.Object{
+		"clonesets/paused":  paused,
+		"clonesets/running": newUndoTestCloneSet("running"),
+	}
+
+	t.Run("pause an already paused cloneset", func(t *testing.T) {
+		tf := newUndoTestFactory(t, objs)
+		defer tf.Cleanup()
+
+		streams, _, out, _ := genericclioptions.NewTestIOStreams()
+		cmd := NewCmdRolloutPause(tf, streams)
+		o := NewRolloutPauseOptions(streams)
+		if err := o.Complete(tf, cmd, []string{"cloneset/paused"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		assert.NoError(t, o.RunPause())
+		assert.Equal(t, "cloneset.apps.kruise.io/paused already paused\n", out.String())
+	})
+
+	t.Run("resume a running cloneset", func(t *testing.T) {
+		tf := newUndoTestFactory(t, objs)
+		defer tf.Cleanup()
+
+		streams, _, out, _ := genericclioptions.NewTestIOStreams()
+		cmd := NewCmdRolloutResume(tf, streams)
+		o := NewRolloutResumeOptions(streams)
+		if err := o.Complete(tf, cmd, []string{"cloneset/running"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		assert.NoError(t, o.RunResume())
+		assert.Equal(t, "cloneset.apps.kruise.io/running already resumed\n", out.String())
+	})
+}
+
+// TestRunPauseHonorsKubeconfigOverrides makes sure the builder and the patch go to the cluster given by --context or
+// --cluster, rather than to the one of the current context of the kubeconfig.
+func TestRunPauseHonorsKubeconfigOverrides(t *testing.T) {
+	testCases := []struct {
+		name            string
+		configure       func(flags *genericclioptions.ConfigFlags)
+		expectNamespace string
+	}{
+		{
+			name: "context",
+			configure: func(flags *genericclioptions.ConfigFlags) {
+				context := "staging"
+				flags.Context = &context
+			},
+			expectNamespace: "staging",
+		},
+		{
+			name: "cluster",
+			configure: func(flags *genericclioptions.ConfigFlags) {
+				cluster := "staging"
+				flags.ClusterName = &cluster
+			},
+			expectNamespace: "test",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			current := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				t.Errorf("unexpected request to the cluster of the current context: %s %s", req.Method, req.URL.Path)
+				http.Error(w, "unexpected request", http.StatusInternalServerError)
+			}))
+			defer current.Close()
+
+			objPath := "/apis/apps.kruise.io/v1alpha1/namespaces/" + tc.expectNamespace + "/clonesets/web"
+			var patch []byte
+			staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				var obj interface{}
+				switch p, m := req.URL.Path, req.Method; {
+				case p == "/api":
+					obj = &metav1.APIVersions{Versions: []string{"v1"}}
+				case p == "/apis":
+					version := metav1.GroupVersionForDiscovery{GroupVersion: kruiseappsv1alpha1.GroupVersion.String(), Version: "v1alpha1"}
+					obj = &metav1.APIGroupList{Groups: []metav1.APIGroup{
+						{Name: kruiseappsv1alpha1.GroupVersion.Group, Versions: []metav1.GroupVersionForDiscovery{version}, PreferredVersion: version},
+					}}
+				case p == "/api/v1":
+					obj = &metav1.APIResourceList{GroupVersion: "v1"}
+				case p == "/apis/apps.kruise.io/v1alpha1":
+					obj = &metav1.APIResourceList{GroupVersion: kruiseappsv1alpha1.GroupVersion.String(), APIResources: []metav1.APIResource{
+						{Name: "clonesets", SingularName: "cloneset", Namespaced: true, Kind: "CloneSet", Verbs: []string{"get", "list", "patch"}},
+					}}
+				case p == objPath && m == http.MethodGet:
+					obj = newUndoTestCloneSet("web")
+				case p == objPath && m == http.MethodPatch:
+					body, err := io.ReadAll(req.Body)
+					assert.NoError(t, err)
+					patch = body
+					paused := newUndoTestCloneSet("web")
+					paused.Spec.UpdateStrategy.Paused = true
+					obj = paused
+				default:
+					t.Errorf("unexpected request: %s %s", req.Method, req.URL.Path)
+					http.Error(w, "unexpected request", http.StatusNotFound)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				assert.NoError(t, json.NewEncoder(w).Encode(obj))
+			}))
+			defer staging.Close()
+
+			kubeconfig := filepath.Join(t.TempDir(), "config")
+			assert.NoError(t, clientcmd.WriteToFile(clientcmdapi.Config{
+				Clusters: map[string]*clientcmdapi.Cluster{
+					"current": {Server: current.URL},
+					"staging": {Server: staging.URL},
+				},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"user": {}},
+				Contexts: map[string]*clientcmdapi.Context{
+					"current": {Cluster: "current", AuthInfo: "user", Namespace: "test"},
+					"staging": {Cluster: "staging", AuthInfo: "user", Namespace: "staging"},
+				},
+				CurrentContext: "current",
+			}, kubeconfig))
+
+			configFlags := genericclioptions.NewConfigFlags(true)
+			cacheDir := t.TempDir()
+			configFlags.KubeConfig, configFlags.CacheDir = &kubeconfig, &cacheDir
+			tc.configure(configFlags)
+			f := cmdutil.NewFactory(configFlags)
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdRolloutPause(f, streams)
+			o := NewRolloutPauseOptions(streams)
+			if err := o.Complete(f, cmd, []string{"cloneset/web"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assert.Equal(t, tc.expectNamespace, o.Namespace)
+			assert.NoError(t, o.RunPause())
+			assert.JSONEq(t, `{"spec":{"updateStrategy":{"paused":true}}}`, string(patch))
+			assert.Equal(t, "cloneset.apps.kruise.io/web paused\n", out.String())
+		})
+	}
+}
@@ -19,19 +19,16 @@ package rollout
 import (
 	"fmt"
 
-	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
-	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
 	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	internalpolymorphichelpers "github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
 	"github.com/spf13/cobra"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/runtime"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
-	"k8s.io/kubectl/pkg/cmd/set"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
-	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
 )
@@ -48,6 +45,8 @@ type RestartOptions struct {
 	Restarter        internalpolymorphichelpers.ObjectRestarterFunc
 	Namespace        string
 	EnforceNamespace bool
+	DryRunStrategy   cmdutil.DryRunStrategy
+	LabelSelector    string
 
 	resource.FilenameOptions
 	genericclioptions.IOStreams
@@ -57,7 +56,9 @@ var (
 	restartLong = templates.LongDesc(`
 		Restart a resource.
 
-	        Resource will be rollout restarted.`)
+	        Resource will be rollout restarted. The pod template is annotated with the restart time,
+	        and for clonesets, advanced statefulsets and advanced daemonsets a restart env is also
+	        injected into every container, so pods are recreated even if they are updated in place.`)
 
 	restartExample = templates.Examples(`
 		# Restart a deployment
@@ -65,7 +66,13 @@ var (
 		kubectl-kruise rollout restart cloneset/abc
 
 		# Restart a daemonset
-		kubectl-kruise rollout restart daemonset/abc`)
+		kubectl-kruise rollout restart daemonset/abc
+
+		# Restart all clonesets with the label app=nginx
+		kubectl-kruise rollout restart cloneset -l app=nginx
+
+		# Preview the restart of an advanced statefulset without sending it to the server
+		kubectl-kruise rollout restart asts/abc --dry-run=client -o yaml`)
 )
 
 // NewRolloutRestartOptions returns an initialized RestartOptions instance
@@ -80,7 +87,7 @@ func NewRolloutRestartOptions(streams genericclioptions.IOStreams) *RestartOptio
 func NewCmdRolloutRestart(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
 	o := NewRolloutRestartOptions(streams)
 
-	validArgs := []string{"deployment", "daemonset", "statefulset", "cloneset"}
+	validArgs := []string{"deployment", "daemonset", "statefulset", "cloneset", "advanced statefulset", "advanced daemonset"}
 
 	cmd := &cobra.Command{
 		Use:                   "restart RESOURCE",
@@ -90,14 +97,16 @@ func NewCmdRolloutRestart(f cmdutil.Factory, streams genericclioptions.IOStreams
 		Example:               restartExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
-			cmdutil.CheckErr(o.Validate())
+			checkErr(validationError(o.Validate()))
 			cmdutil.CheckErr(o.RunRestart())
 		},
-		ValidArgs: validArgs,
+		ValidArgsFunction: resourceNameCompletionFunc(f, validArgs),
 	}
 
 	usage := "identifying the resource to get from a server."
 	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
+	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
+	cmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
 }
@@ -109,6 +118,11 @@ func (o *RestartOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []
 	o.Restarter = internalpolymorphichelpers.ObjectRestarterFn
 
 	var err error
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
 	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
 	if err != nil {
 		return err
@@ -116,6 +130,7 @@ func (o *RestartOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []
 
 	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
 		o.PrintFlags.NamePrintFlags.Operation = operation
+		cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
 		return o.PrintFlags.ToPrinter()
 	}
 
@@ -126,22 +141,24 @@ func (o *RestartOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []
 
 func (o *RestartOptions) Validate() error {
 	if len(o.Resources) == 0 && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
+		if len(o.LabelSelector) > 0 {
+			return fmt.Errorf("a resource type must be specified along with --selector, e.g. cloneset -l app=nginx")
+		}
 		return fmt.Errorf("required resource not specified")
 	}
 	return nil
 }
 
 // RunRestart performs the execution of 'rollout restart' sub command
-func (o RestartOptions) RunRestart() error {
-	r := o.Builder().
-		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		NamespaceParam(o.Namespace).DefaultNamespace().
-		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
-		ResourceTypeOrNameArgs(true, o.Resources...).
-		ContinueOnError().
-		Latest().
-		Flatten().
-		Do()
+func (o *RestartOptions) RunRestart() error {
+	r := internalcmdutil.BuildResources(o.Builder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        o.Namespace,
+		EnforceNamespace: o.EnforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		LabelSelector:    o.LabelSelector,
+		All:              true,
+		Args:             o.Resources,
+	})
 	if err := r.Err(); err != nil {
 		return err
 	}
@@ -157,67 +174,33 @@ func (o RestartOptions) RunRestart() error {
 		allErrs = append(allErrs, err)
 	}
 
-	switch infos[0].Object.(type) {
-	case *kruiseappsv1alpha1.CloneSet, *kruiseappsv1beta1.StatefulSet, *kruiseappsv1alpha1.DaemonSet:
-
-		obj, err := resource.
-			NewHelper(infos[0].Client, infos[0].Mapping).
-			Get(infos[0].Namespace, infos[0].Name)
-		if err != nil {
+	for _, info := range infos {
+		operation, err := internalcmdutil.PatchWorkload(info, func(obj runtime.Object) error {
+			_, err := o.Restarter(obj)
 			return err
-		}
-		internalpolymorphichelpers.UpdateResourceEnv(obj)
-
-		_, err = resource.
-			NewHelper(infos[0].Client, infos[0].Mapping).
-			Replace(infos[0].Namespace, infos[0].Name, true, obj)
+		}, o.DryRunStrategy)
 		if err != nil {
-			return err
+			resourceString := info.Mapping.Resource.Resource
+			if len(info.Mapping.Resource.Group) > 0 {
+				resourceString = resourceString + "." + info.Mapping.Resource.Group
+			}
+			allErrs = append(allErrs, fmt.Errorf("error: %s %q %v", resourceString, info.Name, err))
+			continue
 		}
+		if operation == internalcmdutil.PatchOperationUnchanged {
+			allErrs = append(allErrs, fmt.Errorf("failed to create patch for %v: empty patch", info.Name))
+			continue
+		}
+
 		printer, err := o.ToPrinter("restarted")
 		if err != nil {
 			allErrs = append(allErrs, err)
+			continue
 		}
-		if err = printer.PrintObj(infos[0].Object, o.Out); err != nil {
+		if err = printer.PrintObj(info.Object, o.Out); err != nil {
 			allErrs = append(allErrs, err)
 		}
-		return utilerrors.NewAggregate(allErrs)
-
-	default:
-		for _, patch := range set.CalculatePatches(infos, scheme.DefaultJSONEncoder(), set.PatchFn(o.Restarter)) {
-			info := patch.Info
-			if patch.Err != nil {
-				resourceString := info.Mapping.Resource.Resource
-				if len(info.Mapping.Resource.Group) > 0 {
-					resourceString = resourceString + "." + info.Mapping.Resource.Group
-				}
-				allErrs = append(allErrs, fmt.Errorf("error: %s %q %v", resourceString, info.Name, patch.Err))
-				continue
-			}
-
-			if string(patch.Patch) == "{}" || len(patch.Patch) == 0 {
-				allErrs = append(allErrs, fmt.Errorf("failed to create patch for %v: empty patch", info.Name))
-			}
-
-			obj, err := resource.NewHelper(info.Client, info.Mapping).Patch(info.Namespace, info.Name, types.MergePatchType, patch.Patch, nil)
-			if err != nil {
-				allErrs = append(allErrs, fmt.Errorf("failed to patch: %v", err))
-				continue
-			}
-
-			info.Refresh(obj, true)
-			printer, err := o.ToPrinter("restarted")
-			if err != nil {
-				allErrs = append(allErrs, err)
-				continue
-			}
-			if err = printer.PrintObj(info.Object, o.Out); err != nil {
-				allErrs = append(allErrs, err)
-			}
-		}
-
-		return utilerrors.NewAggregate(allErrs)
-
 	}
 
+	return utilerrors.NewAggregate(allErrs)
 }
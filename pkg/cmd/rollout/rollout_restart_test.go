@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestRunRestart(t *testing.T) {
+	testCases := []struct {
+		name          string
+		args          []string
+		selector      string
+		dryRun        cmdutil.DryRunStrategy
+		expectPatches []string
+		expectDryRun  string
+		expectOut     string
+	}{
+		{
+			name:          "restart clonesets by name",
+			args:          []string{"cloneset/foo", "cloneset/bar"},
+			expectPatches: []string{"foo", "bar"},
+			expectOut:     "cloneset.apps.kruise.io/foo restarted\ncloneset.apps.kruise.io/bar restarted\n",
+		},
+		{
+			name:          "restart clonesets by selector",
+			args:          []string{"cloneset"},
+			selector:      "app=web",
+			expectPatches: []string{"foo", "bar"},
+			expectOut:     "cloneset.apps.kruise.io/foo restarted\ncloneset.apps.kruise.io/bar restarted\n",
+		},
+		{
+			name:      "client dry-run does not patch",
+			args:      []string{"cloneset/foo"},
+			dryRun:    cmdutil.DryRunClient,
+			expectOut: "cloneset.apps.kruise.io/foo restarted (dry run)\n",
+		},
+		{
+			name:          "server dry-run patches with dryRun",
+			args:          []string{"cloneset/foo"},
+			dryRun:        cmdutil.DryRunServer,
+			expectPatches: []string{"foo"},
+			expectDryRun:  "All",
+			expectOut:     "cloneset.apps.kruise.io/foo restarted (server dry run)\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			foo, bar := newUndoTestCloneSet("foo"), newUndoTestCloneSet("bar")
+			foo.Spec.Template, bar.Spec.Template = *newUndoTestTemplate("nginx"), *newUndoTestTemplate("nginx")
+			list := &kruiseappsv1alpha1.CloneSetList{Items: []kruiseappsv1alpha1.CloneSet{*foo, *bar}}
+			codec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+
+			var patched []string
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			tf.Client = &fake.RESTClient{
+				GroupVersion:         schema.GroupVersion{Group: "apps.kruise.io", Version: "v1alpha1"},
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					switch p, m := req.URL.Path, req.Method; {
+					case p == "/namespaces/test/clonesets" && m == http.MethodGet:
+						assert.Equal(t, tc.selector, req.URL.Query().Get("labelSelector"))
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, list)}, nil
+					case p == "/namespaces/test/clonesets/foo" && m == http.MethodGet:
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, foo)}, nil
+					case p == "/namespaces/test/clonesets/bar" && m == http.MethodGet:
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, bar)}, nil
+					case (p == "/namespaces/test/clonesets/foo" || p == "/namespaces/test/clonesets/bar") && m == http.MethodPatch:
+						name := p[len("/namespaces/test/clonesets/"):]
+						patched = append(patched, name)
+						assert.Equal(t, string(types.MergePatchType), req.Header.Get("Content-Type"))
+						assert.Equal(t, tc.expectDryRun, req.URL.Query().Get("dryRun"))
+						assertRestartPatch(t, req.Body)
+						obj := foo
+						if name == "bar" {
+							obj = bar
+						}
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+					default:
+						t.Fatalf("unexpected request: %s %s", m, p)
+						return nil, nil
+					}
+				}),
+			}
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdRolloutRestart(tf, streams)
+			o := NewRolloutRestartOptions(streams)
+			if err := o.Complete(tf, cmd, tc.args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.LabelSelector = tc.selector
+			o.DryRunStrategy = tc.dryRun
+
+			assert.NoError(t, o.RunRestart())
+			assert.Equal(t, tc.expectPatches, patched)
+			assert.Equal(t, tc.expectOut, out.String())
+		})
+	}
+}
+
+// assertRestartPatch checks that the JSON merge patch annotates the pod template with the restart time, and keeps
+// the containers intact besides the injected restart env.
+func assertRestartPatch(t *testing.T, body io.Reader) {
+	data, err := io.ReadAll(body)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var patch struct {
+		Spec struct {
+			Template struct {
+				Metadata struct {
+					Annotations map[string]string `json:"annotations"`
+				} `json:"metadata"`
+				Spec struct {
+					Containers []struct {
+						Name  string `json:"name"`
+						Image string `json:"image"`
+						Env   []struct {
+							Name string `json:"name"`
+						} `json:"env"`
+					} `json:"containers"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	if !assert.NoError(t, json.Unmarshal(data, &patch), string(data)) {
+		return
+	}
+
+	_, err = time.Parse(time.RFC3339, patch.Spec.Template.Metadata.Annotations["kubectl.kruise.io/restartedAt"])
+	assert.NoError(t, err, string(data))
+	containers := patch.Spec.Template.Spec.Containers
+	if assert.Len(t, containers, 1, string(data)) {
+		assert.Equal(t, "nginx", containers[0].Image)
+		if assert.Len(t, containers[0].Env, 1) {
+			assert.Equal(t, "RESTARTED_AT", containers[0].Env[0].Name)
+		}
+	}
+}
+
+func TestRestartValidateSelectorRequiresResourceType(t *testing.T) {
+	o := &RestartOptions{LabelSelector: "app=web"}
+	assert.EqualError(t, o.Validate(), "a resource type must be specified along with --selector, e.g. cloneset -l app=nginx")
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	internalpolymorphichelpers "github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
 
 	"github.com/spf13/cobra"
@@ -58,7 +59,7 @@ var (
 
 		Paused resources will not be reconciled by a controller. By resuming a
 		resource, we allow it to be reconciled again.
-		Currently deployments, cloneset support being resumed.`)
+		Currently deployments, clonesets, advanced statefulsets and rollouts support being resumed.`)
 
 	resumeExample = templates.Examples(`
 		# Resume an already paused rollout/cloneset/deployment resource
@@ -80,7 +81,7 @@ func NewRolloutResumeOptions(streams genericclioptions.IOStreams) *ResumeOptions
 func NewCmdRolloutResume(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
 	o := NewRolloutResumeOptions(streams)
 
-	validArgs := []string{"deployment", "cloneset", "rollout"}
+	validArgs := []string{"deployment", "cloneset", "advanced statefulset", "rollout"}
 
 	cmd := &cobra.Command{
 		Use:                   "resume RESOURCE",
@@ -90,10 +91,10 @@ func NewCmdRolloutResume(f cmdutil.Factory, streams genericclioptions.IOStreams)
 		Example:               resumeExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
-			cmdutil.CheckErr(o.Validate())
+			checkErr(validationError(o.Validate()))
 			cmdutil.CheckErr(o.RunResume())
 		},
-		ValidArgs: validArgs,
+		ValidArgsFunction: resourceNameCompletionFunc(f, validArgs),
 	}
 
 	usage := "identifying the resource to get from a server."
@@ -133,15 +134,13 @@ func (o *ResumeOptions) Validate() error {
 
 // RunResume performs the execution of 'rollout resume' sub command
 func (o ResumeOptions) RunResume() error {
-	r := o.Builder().
-		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		NamespaceParam(o.Namespace).DefaultNamespace().
-		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
-		ResourceTypeOrNameArgs(true, o.Resources...).
-		ContinueOnError().
-		Latest().
-		Flatten().
-		Do()
+	r := internalcmdutil.BuildResources(o.Builder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        o.Namespace,
+		EnforceNamespace: o.EnforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		All:              true,
+		Args:             o.Resources,
+	})
 	if err := r.Err(); err != nil {
 		return err
 	}
@@ -0,0 +1,241 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"fmt"
+	"strings"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
+	internalpolymorphichelpers "github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// SetPartitionOptions is the start of the data required to perform the operation.  As new fields are added, add them here instead of
+// referencing the cmd.Flags()
+type SetPartitionOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	ToPrinter  func(string) (printers.ResourcePrinter, error)
+
+	Partition string
+
+	Builder          func() *resource.Builder
+	Namespace        string
+	EnforceNamespace bool
+	DryRunStrategy   cmdutil.DryRunStrategy
+	Resources        []string
+
+	resource.FilenameOptions
+	genericclioptions.IOStreams
+}
+
+var (
+	setPartitionLong = templates.LongDesc(`
+		Set the partition of a cloneset.
+
+		The partition is the number of pods kept at the old revision while the cloneset is updated.
+		A percentage is translated into a number of pods based on the current replicas, rounded up
+		so that at least one old pod is kept for a partition above 0%, and at least one pod is
+		updated for a partition below 100%. The partition is clamped to [0, replicas].`)
+
+	setPartitionExample = templates.Examples(`
+		# Keep 20% of the pods of the web cloneset at the old revision
+		kubectl-kruise rollout set-partition cloneset/web 20%
+
+		# Keep 3 pods of the web cloneset at the old revision
+		kubectl-kruise rollout set-partition cloneset/web --partition=3
+
+		# Update all the pods of the web cloneset
+		kubectl-kruise rollout set-partition cloneset/web 0`)
+)
+
+// NewRolloutSetPartitionOptions returns an initialized SetPartitionOptions instance
+func NewRolloutSetPartitionOptions(streams genericclioptions.IOStreams) *SetPartitionOptions {
+	return &SetPartitionOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("partitioned").WithTypeSetter(internalapi.GetScheme()),
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdRolloutSetPartition returns a Command instance for 'rollout set-partition' sub command
+func NewCmdRolloutSetPartition(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewRolloutSetPartitionOptions(streams)
+
+	validArgs := []string{"cloneset"}
+
+	cmd := &cobra.Command{
+		Use:                   "set-partition RESOURCE [PARTITION]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Set the partition of a cloneset"),
+		Long:                  setPartitionLong,
+		Example:               setPartitionExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			checkErr(validationError(o.Validate()))
+			cmdutil.CheckErr(o.RunSetPartition())
+		},
+		ValidArgsFunction: resourceNameCompletionFunc(f, validArgs),
+	}
+
+	cmd.Flags().StringVar(&o.Partition, "partition", o.Partition, "The number or percentage of pods to keep at the old revision, e.g. 3 or 20%.")
+	usage := "identifying the resource to get from a server."
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
+	cmdutil.AddDryRunFlag(cmd)
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *SetPartitionOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	// the partition may be given as the last argument instead of with --partition
+	if len(o.Partition) == 0 && len(args) > 0 && isPartitionValue(args[len(args)-1]) {
+		o.Partition = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+	o.Resources = args
+
+	var err error
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
+		o.PrintFlags.NamePrintFlags.Operation = operation
+		cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+		return o.PrintFlags.ToPrinter()
+	}
+
+	o.Builder = f.NewBuilder
+	return nil
+}
+
+func (o *SetPartitionOptions) Validate() error {
+	if len(o.Resources) == 0 && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
+		return fmt.Errorf("required resource not specified")
+	}
+	if len(o.Partition) == 0 {
+		return fmt.Errorf("a partition must be specified, e.g. cloneset/web 20%% or --partition=3")
+	}
+	if !isPartitionValue(o.Partition) {
+		return fmt.Errorf("invalid partition %q: must be a number or a percentage, e.g. 3 or 20%%", o.Partition)
+	}
+	return nil
+}
+
+// RunSetPartition performs the execution of 'rollout set-partition' sub command
+func (o *SetPartitionOptions) RunSetPartition() error {
+	r := internalcmdutil.BuildResources(o.Builder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        o.Namespace,
+		EnforceNamespace: o.EnforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		All:              true,
+		Args:             o.Resources,
+	})
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	var allErrs []error
+	infos, err := r.Infos()
+	if err != nil {
+		// restore previous command behavior where
+		// an error caused by retrieving infos due to
+		// at least a single broken object did not result
+		// in an immediate return, but rather an overall
+		// aggregation of errors.
+		allErrs = append(allErrs, err)
+	}
+
+	partition := intstr.Parse(o.Partition)
+	for _, info := range infos {
+		operation, err := internalcmdutil.PatchWorkload(info, func(obj runtime.Object) error {
+			cs, ok := obj.(*kruiseappsv1alpha1.CloneSet)
+			if !ok {
+				return fmt.Errorf("setting the partition is not supported")
+			}
+			value, err := partitionForReplicas(partition, cs.Spec.Replicas)
+			if err != nil {
+				return err
+			}
+			p := intstr.FromInt(int(value))
+			cs.Spec.UpdateStrategy.Partition = &p
+			return nil
+		}, o.DryRunStrategy)
+		if err != nil {
+			resourceString := info.Mapping.Resource.Resource
+			if len(info.Mapping.Resource.Group) > 0 {
+				resourceString = resourceString + "." + info.Mapping.Resource.Group
+			}
+			allErrs = append(allErrs, fmt.Errorf("error: %s %q %v", resourceString, info.Name, err))
+			continue
+		}
+
+		// the object of info has the new partition set by now
+		value := info.Object.(*kruiseappsv1alpha1.CloneSet).Spec.UpdateStrategy.Partition.IntVal
+		message := fmt.Sprintf("partition set to %d", value)
+		if operation == internalcmdutil.PatchOperationUnchanged {
+			message = fmt.Sprintf("partition already %d", value)
+		}
+		printer, err := o.ToPrinter(message)
+		if err != nil {
+			allErrs = append(allErrs, err)
+			continue
+		}
+		if err = printer.PrintObj(info.Object, o.Out); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// isPartitionValue returns whether value is a partition, a number or a percentage, rather than a resource.
+func isPartitionValue(value string) bool {
+	partition := intstr.Parse(value)
+	if partition.Type == intstr.Int {
+		return true
+	}
+	if !strings.HasSuffix(value, "%") {
+		return false
+	}
+	_, err := intstr.GetScaledValueFromIntOrPercent(&partition, 100, true)
+	return err == nil
+}
+
+// partitionForReplicas translates partition into the number of pods to keep at the old revision, which is
+// clamped to [0, replicas].
+func partitionForReplicas(partition intstr.IntOrString, replicas *int32) (int32, error) {
+	value, err := internalpolymorphichelpers.CalculatePartitionReplicas(&partition, replicas)
+	return int32(value), err
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/utils/pointer"
+)
+
+func TestPartitionForReplicas(t *testing.T) {
+	testCases := []struct {
+		name      string
+		partition string
+		replicas  *int32
+		expect    int32
+	}{
+		{name: "absolute", partition: "3", replicas: pointer.Int32(10), expect: 3},
+		{name: "absolute above replicas", partition: "12", replicas: pointer.Int32(10), expect: 10},
+		{name: "negative absolute", partition: "-2", replicas: pointer.Int32(10), expect: 0},
+		{name: "percentage", partition: "20%", replicas: pointer.Int32(10), expect: 2},
+		{name: "percentage rounded up", partition: "25%", replicas: pointer.Int32(10), expect: 3},
+		{name: "small percentage keeps an old pod", partition: "1%", replicas: pointer.Int32(10), expect: 1},
+		{name: "large percentage updates a pod", partition: "99%", replicas: pointer.Int32(10), expect: 9},
+		{name: "percentage above 100%", partition: "150%", replicas: pointer.Int32(10), expect: 10},
+		{name: "zero percent", partition: "0%", replicas: pointer.Int32(10), expect: 0},
+		{name: "unset replicas", partition: "50%", expect: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := partitionForReplicas(intstr.Parse(tc.partition), tc.replicas)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, value)
+		})
+	}
+}
+
+func TestSetPartitionValidate(t *testing.T) {
+	testCases := []struct {
+		name            string
+		args            []string
+		partition       string
+		expectResources []string
+		expectPartition string
+		expectErr       string
+	}{
+		{
+			name:            "partition argument",
+			args:            []string{"cloneset/web", "20%"},
+			expectResources: []string{"cloneset/web"},
+			expectPartition: "20%",
+		},
+		{
+			name:            "partition flag",
+			args:            []string{"cloneset", "web"},
+			partition:       "3",
+			expectResources: []string{"cloneset", "web"},
+			expectPartition: "3",
+		},
+		{
+			name:            "missing partition",
+			args:            []string{"cloneset", "web"},
+			expectResources: []string{"cloneset", "web"},
+			expectErr:       "a partition must be specified, e.g. cloneset/web 20% or --partition=3",
+		},
+		{
+			name:            "invalid partition flag",
+			args:            []string{"cloneset/web"},
+			partition:       "half",
+			expectResources: []string{"cloneset/web"},
+			expectPartition: "half",
+			expectErr:       `invalid partition "half": must be a number or a percentage, e.g. 3 or 20%`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, nil)
+			defer tf.Cleanup()
+
+			streams := genericclioptions.NewTestIOStreamsDiscard()
+			cmd := NewCmdRolloutSetPartition(tf, streams)
+			o := NewRolloutSetPartitionOptions(streams)
+			o.Partition = tc.partition
+			assert.NoError(t, o.Complete(tf, cmd, tc.args))
+			assert.Equal(t, tc.expectResources, o.Resources)
+			assert.Equal(t, tc.expectPartition, o.Partition)
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, o.Validate(), tc.expectErr)
+			} else {
+				assert.NoError(t, o.Validate())
+			}
+		})
+	}
+}
+
+func TestRunSetPartition(t *testing.T) {
+	web := newUndoTestCloneSet("web")
+	web.Spec.Replicas = pointer.Int32(5)
+	partitioned := newUndoTestCloneSet("partitioned")
+	partitioned.Spec.Replicas = pointer.Int32(5)
+	two := intstr.FromInt(2)
+	partitioned.Spec.UpdateStrategy.Partition = &two
+	objs := map[string]runtime.Object{
+		"clonesets/web":         web,
+		"clonesets/partitioned": partitioned,
+		"rollouts/ro":           newUndoTestRollout("ro", "web"),
+	}
+
+	testCases := []struct {
+		name      string
+		args      []string
+		expectOut string
+		expectErr string
+	}{
+		{
+			name:      "percentage",
+			args:      []string{"cloneset/web", "40%"},
+			expectOut: "cloneset.apps.kruise.io/web partition set to 2 (dry run)\n",
+		},
+		{
+			name:      "clamped to replicas",
+			args:      []string{"cloneset/web", "8"},
+			expectOut: "cloneset.apps.kruise.io/web partition set to 5 (dry run)\n",
+		},
+		{
+			name:      "unchanged partition",
+			args:      []string{"cloneset/partitioned", "40%"},
+			expectOut: "cloneset.apps.kruise.io/partitioned partition already 2 (dry run)\n",
+		},
+		{
+			name:      "unsupported kind",
+			args:      []string{"rollout/ro", "40%"},
+			expectErr: `error: rollouts.rollouts.kruise.io "ro" setting the partition is not supported`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdRolloutSetPartition(tf, streams)
+			cmd.Flags().Set("dry-run", "client")
+			o := NewRolloutSetPartitionOptions(streams)
+			assert.NoError(t, o.Complete(tf, cmd, tc.args))
+			assert.NoError(t, o.Validate())
+
+			err := o.RunSetPartition()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectOut, out.String())
+		})
+	}
+}
@@ -18,10 +18,14 @@ package rollout
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	internalpolymorphichelpers "github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
 
 	"github.com/spf13/cobra"
@@ -31,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -39,7 +44,6 @@ import (
 	"k8s.io/client-go/tools/cache"
 	watchtools "k8s.io/client-go/tools/watch"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
-	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/interrupt"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -54,7 +58,18 @@ var (
 		you can use --watch=false. Note that if a new rollout starts in-between, then
 		'rollout status' will continue watching the latest revision. If you want to
 		pin to a specific revision and abort if it is rolled over by another revision,
-		use --revision=N where N is the revision you need to watch for.`)
+		use --revision=N where N is the revision you need to watch for.
+
+		For a Kruise Rollout, the current canary step, its weight and the rollout
+		phase are shown until the canary release is completed.
+
+		Several resources can be given at once. Every line of their statuses is then prefixed with
+		the resource it is about, and the watch ends once all of them are done, or as soon as one of
+		them fails.
+
+		With --output-watch-events, every change of the status is printed as a JSON object on a line
+		of its own, with the phase and the canary step of a Kruise Rollout and the time it was
+		observed, and a last line of type summary tells whether the rollout is done.`)
 
 	statusExample = templates.Examples(`
 		# Watch the rollout status of a deployment
@@ -64,7 +79,19 @@ var (
 		kubectl-kruise rollout status cloneset/nginx
 
 		# Watch the rollout status of a advanced statefulset
-		kubectl-kruise rollout status asts/nginx`)
+		kubectl-kruise rollout status asts/nginx
+
+		# Watch the rollout status of an advanced daemonset
+		kubectl-kruise rollout status daemonset.apps.kruise.io/nginx
+
+		# Watch the canary steps of a rollout until it is completed, giving up after 10 minutes
+		kubectl-kruise rollout status rollout/nginx --timeout=10m
+
+		# Watch the canary steps of two rollouts until both are completed
+		kubectl-kruise rollout status rollout/frontend rollout/backend
+
+		# Watch the canary steps of a rollout, printing every status change as a line of JSON
+		kubectl-kruise rollout status rollout/nginx --output-watch-events`)
 )
 
 // RolloutStatusOptions holds the command-line options for 'rollout status' sub command
@@ -75,20 +102,45 @@ type RolloutStatusOptions struct {
 	EnforceNamespace bool
 	BuilderArgs      []string
 
-	Watch    bool
-	Revision int64
-	Timeout  time.Duration
-	Detail   bool
+	Watch             bool
+	Revision          int64
+	Timeout           time.Duration
+	Detail            bool
+	OutputWatchEvents bool
 
 	StatusViewerFn func(*meta.RESTMapping) (internalpolymorphichelpers.StatusViewer, error)
 	Builder        func() *resource.Builder
 	DynamicClient  dynamic.Interface
 	ClientSet      kubernetes.Interface
 
+	// now returns the time a status change is observed at
+	now func() time.Time
+	// printMu serializes the output of the targets watched at the same time
+	printMu sync.Mutex
+
 	FilenameOptions *resource.FilenameOptions
 	genericclioptions.IOStreams
 }
 
+// statusWatchEvent is a line printed with --output-watch-events, either a change of the status or the summary
+// printed once the watch ends
+type statusWatchEvent struct {
+	Type   string `json:"type"`
+	Time   string `json:"time"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Phase  string `json:"phase,omitempty"`
+	Step   *int64 `json:"step,omitempty"`
+	Status string `json:"status,omitempty"`
+	Done   bool   `json:"done"`
+	Error  string `json:"error,omitempty"`
+}
+
+const (
+	statusWatchEventStatus  = "status"
+	statusWatchEventSummary = "summary"
+)
+
 // NewRolloutStatusOptions returns an initialized RolloutStatusOptions instance
 func NewRolloutStatusOptions(streams genericclioptions.IOStreams) *RolloutStatusOptions {
 	return &RolloutStatusOptions{
@@ -98,6 +150,7 @@ func NewRolloutStatusOptions(streams genericclioptions.IOStreams) *RolloutStatus
 		Watch:           true,
 		Timeout:         0,
 		Detail:          false,
+		now:             time.Now,
 	}
 }
 
@@ -105,7 +158,7 @@ func NewRolloutStatusOptions(streams genericclioptions.IOStreams) *RolloutStatus
 func NewCmdRolloutStatus(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
 	o := NewRolloutStatusOptions(streams)
 
-	validArgs := []string{"deployment", "daemonset", "statefulset", "cloneset", "advanced statefulset"}
+	validArgs := []string{"deployment", "daemonset", "statefulset", "cloneset", "advanced statefulset", "rollout"}
 
 	cmd := &cobra.Command{
 		Use:                   "status (TYPE NAME | TYPE/NAME) [flags]",
@@ -115,10 +168,10 @@ func NewCmdRolloutStatus(f cmdutil.Factory, streams genericclioptions.IOStreams)
 		Example:               statusExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, args))
-			cmdutil.CheckErr(o.Validate())
+			checkErr(validationError(o.Validate()))
 			cmdutil.CheckErr(o.Run())
 		},
-		ValidArgs: validArgs,
+		ValidArgsFunction: resourceNameCompletionFunc(f, validArgs),
 	}
 
 	usage := "identifying the resource to get from a server."
@@ -127,6 +180,7 @@ func NewCmdRolloutStatus(f cmdutil.Factory, streams genericclioptions.IOStreams)
 	cmd.Flags().Int64Var(&o.Revision, "revision", o.Revision, "Pin to a specific revision for showing its status. Defaults to 0 (last revision).")
 	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to wait before ending watch, zero means never. Any other values should contain a corresponding time unit (e.g. 1s, 2m, 3h).")
 	cmd.Flags().BoolVarP(&o.Detail, "detail", "d", o.Detail, "Show the detail status of the rollout.")
+	cmd.Flags().BoolVar(&o.OutputWatchEvents, "output-watch-events", o.OutputWatchEvents, "Print every change of the status as a JSON object on a line of its own, followed by a summary once the watch ends.")
 
 	return cmd
 }
@@ -177,14 +231,13 @@ func (o *RolloutStatusOptions) Validate() error {
 
 // Run performs the execution of 'rollout status' sub command
 func (o *RolloutStatusOptions) Run() error {
-	r := o.Builder().
-		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		NamespaceParam(o.Namespace).DefaultNamespace().
-		FilenameParam(o.EnforceNamespace, o.FilenameOptions).
-		ResourceTypeOrNameArgs(true, o.BuilderArgs...).
-		SingleResourceType().
-		Latest().
-		Do()
+	r := internalcmdutil.BuildResources(o.Builder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        o.Namespace,
+		EnforceNamespace: o.EnforceNamespace,
+		FilenameOptions:  o.FilenameOptions,
+		All:              true,
+		Args:             o.BuilderArgs,
+	})
 	err := r.Err()
 	if err != nil {
 		return err
@@ -194,17 +247,59 @@ func (o *RolloutStatusOptions) Run() error {
 	if err != nil {
 		return err
 	}
-	if len(infos) != 1 {
-		return fmt.Errorf("rollout status is only supported on individual resources and resource collections - %d resources were found", len(infos))
+	if len(infos) == 0 {
+		return fmt.Errorf("no resources found")
 	}
-	info := infos[0]
-	mapping := info.ResourceMapping()
-
-	statusViewer, err := o.StatusViewerFn(mapping)
-	if err != nil {
-		return err
+	statusViewers := make([]internalpolymorphichelpers.StatusViewer, len(infos))
+	for i, info := range infos {
+		if statusViewers[i], err = o.StatusViewerFn(info.ResourceMapping()); err != nil {
+			return err
+		}
 	}
 
+	// if the rollout isn't done yet, keep watching deployment status
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), o.Timeout)
+	intr := interrupt.New(nil, cancel)
+	return intr.Run(func() error {
+		if len(infos) == 1 {
+			return o.watchStatus(ctx, infos[0], statusViewers[0], "")
+		}
+
+		// every target is watched on its own, the first one to fail stops the watch of the others. Once the timeout
+		// elapses, all the targets which are not done yet are reported.
+		failCtx, fail := context.WithCancel(ctx)
+		defer fail()
+		var mu sync.Mutex
+		var errs []error
+		var wg sync.WaitGroup
+		for i := range infos {
+			wg.Add(1)
+			go func(info *resource.Info, statusViewer internalpolymorphichelpers.StatusViewer) {
+				defer wg.Done()
+				target := fmt.Sprintf("%s/%s", info.Mapping.Resource.GroupResource(), info.Name)
+				err := o.watchStatus(failCtx, info, statusViewer, target+": ")
+				if err == nil {
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				// the watch of a target stopped by the failure of another one is not a failure of its own
+				if failCtx.Err() != nil && ctx.Err() == nil {
+					return
+				}
+				errs = append(errs, fmt.Errorf("%s: %v", target, err))
+				fail()
+			}(infos[i], statusViewers[i])
+		}
+		wg.Wait()
+		return utilerrors.NewAggregate(errs)
+	})
+}
+
+// watchStatus prints the status of the rollout of info until it is done, or ctx is done. Every line of the status is
+// prefixed with prefix, so that the statuses of several targets can be told apart.
+func (o *RolloutStatusOptions) watchStatus(ctx context.Context, info *resource.Info, statusViewer internalpolymorphichelpers.StatusViewer, prefix string) error {
+	mapping := info.ResourceMapping()
 	fieldSelector := fields.OneTermEqualSelector("metadata.name", info.Name).String()
 	lw := &cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
@@ -231,44 +326,121 @@ func (o *RolloutStatusOptions) Run() error {
 		return false, nil
 	}
 
-	// if the rollout isn't done yet, keep watching deployment status
-	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), o.Timeout)
-	intr := interrupt.New(nil, cancel)
 	var status string
 	var consideredDone bool
-	return intr.Run(func() error {
-		_, err = watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, preconditionFunc, func(e watch.Event) (bool, error) {
-			switch t := e.Type; t {
-			case watch.Added, watch.Modified:
-				if o.Detail {
-					status, consideredDone, err = statusViewer.DetailStatus(o.ClientSet, e.Object.(runtime.Unstructured), o.Detail, o.Revision)
-				} else {
-					status, consideredDone, err = statusViewer.Status(o.ClientSet, e.Object.(runtime.Unstructured), o.Revision)
-				}
-				if err != nil {
-					return false, err
-				}
-				fmt.Fprintf(o.Out, "%s", status)
-				// Quit waiting if the rollout is done
-				if consideredDone {
-					return true, nil
+	// the last status change printed with --output-watch-events
+	var lastEvent *statusWatchEvent
+	_, err := watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, preconditionFunc, func(e watch.Event) (bool, error) {
+		var err error
+		switch t := e.Type; t {
+		case watch.Added, watch.Modified:
+			if o.Detail {
+				status, consideredDone, err = statusViewer.DetailStatus(o.ClientSet, e.Object.(runtime.Unstructured), o.Detail, o.Revision)
+			} else {
+				status, consideredDone, err = statusViewer.Status(o.ClientSet, e.Object.(runtime.Unstructured), o.Revision)
+			}
+			if err != nil {
+				return false, err
+			}
+			if o.OutputWatchEvents {
+				event := o.newStatusWatchEvent(statusWatchEventStatus, info, e.Object.(runtime.Unstructured), status, consideredDone)
+				if lastEvent == nil || !sameStatus(lastEvent, event) {
+					if err := o.printStatusWatchEvent(event); err != nil {
+						return false, err
+					}
+					lastEvent = event
 				}
+			} else {
+				o.printStatus(prefix, status)
+			}
+			// Quit waiting if the rollout is done
+			if consideredDone {
+				return true, nil
+			}
 
-				shouldWatch := o.Watch
-				if !shouldWatch {
-					return true, nil
-				}
+			shouldWatch := o.Watch
+			if !shouldWatch {
+				return true, nil
+			}
 
-				return false, nil
+			return false, nil
 
-			case watch.Deleted:
-				// We need to abort to avoid cases of recreation and not to silently watch the wrong (new) object
-				return true, fmt.Errorf("object has been deleted")
+		case watch.Deleted:
+			// We need to abort to avoid cases of recreation and not to silently watch the wrong (new) object
+			return true, fmt.Errorf("object has been deleted")
 
-			default:
-				return true, fmt.Errorf("internal error: unexpected event %#v", e)
-			}
-		})
-		return err
+		default:
+			return true, fmt.Errorf("internal error: unexpected event %#v", e)
+		}
 	})
+	if !o.OutputWatchEvents {
+		return err
+	}
+
+	// the summary repeats the last status change, and tells whether the watch ended with the rollout done
+	summary := &statusWatchEvent{Kind: mapping.GroupVersionKind.Kind, Name: info.Name}
+	if lastEvent != nil {
+		summary = lastEvent
+	}
+	summary.Type = statusWatchEventSummary
+	summary.Time = o.now().UTC().Format(time.RFC3339)
+	summary.Done = consideredDone && err == nil
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	if printErr := o.printStatusWatchEvent(summary); printErr != nil && err == nil {
+		return printErr
+	}
+	return err
+}
+
+// printStatus prints status with every line prefixed with prefix.
+func (o *RolloutStatusOptions) printStatus(prefix, status string) {
+	o.printMu.Lock()
+	defer o.printMu.Unlock()
+	if len(prefix) == 0 {
+		fmt.Fprintf(o.Out, "%s", status)
+		return
+	}
+	for _, line := range strings.SplitAfter(status, "\n") {
+		if len(line) > 0 {
+			fmt.Fprintf(o.Out, "%s%s", prefix, line)
+		}
+	}
+}
+
+// newStatusWatchEvent returns the event of type eventType for the status of obj. The phase and the canary step are
+// only recorded in the status of a Kruise Rollout.
+func (o *RolloutStatusOptions) newStatusWatchEvent(eventType string, info *resource.Info, obj runtime.Unstructured, status string, done bool) *statusWatchEvent {
+	event := &statusWatchEvent{
+		Type:   eventType,
+		Time:   o.now().UTC().Format(time.RFC3339),
+		Kind:   info.Mapping.GroupVersionKind.Kind,
+		Name:   info.Name,
+		Status: strings.TrimSpace(status),
+		Done:   done,
+	}
+	content := obj.UnstructuredContent()
+	event.Phase, _, _ = unstructured.NestedString(content, "status", "phase")
+	if step, found, _ := unstructured.NestedInt64(content, "status", "canaryStatus", "currentStepIndex"); found {
+		event.Step = &step
+	}
+	return event
+}
+
+// sameStatus returns whether a and b describe the same status, regardless of the time they were observed at.
+func sameStatus(a, b *statusWatchEvent) bool {
+	sameStep := (a.Step == nil) == (b.Step == nil) && (a.Step == nil || *a.Step == *b.Step)
+	return a.Phase == b.Phase && sameStep && a.Status == b.Status && a.Done == b.Done
+}
+
+func (o *RolloutStatusOptions) printStatusWatchEvent(event *statusWatchEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	o.printMu.Lock()
+	defer o.printMu.Unlock()
+	_, err = fmt.Fprintf(o.Out, "%s\n", data)
+	return err
 }
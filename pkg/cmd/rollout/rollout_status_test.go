@@ -0,0 +1,342 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	rolloutsapiv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubetesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+)
+
+func newStatusTestRollout(phase rolloutsapiv1alpha1.RolloutPhase, stepIndex int32, stepState rolloutsapiv1alpha1.CanaryStepState) *rolloutsapiv1alpha1.Rollout {
+	return &rolloutsapiv1alpha1.Rollout{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rolloutsapiv1alpha1.GroupVersion.String(), Kind: "Rollout"},
+		ObjectMeta: metav1.ObjectMeta{Name: "ro", Namespace: "test", Generation: 1},
+		Spec: rolloutsapiv1alpha1.RolloutSpec{
+			Strategy: rolloutsapiv1alpha1.RolloutStrategy{
+				Canary: &rolloutsapiv1alpha1.CanaryStrategy{
+					Steps: []rolloutsapiv1alpha1.CanaryStep{
+						{TrafficRoutingStrategy: rolloutsapiv1alpha1.TrafficRoutingStrategy{Weight: pointer.Int32(20)}},
+						{Replicas: &intstr.IntOrString{Type: intstr.String, StrVal: "100%"}},
+					},
+				},
+			},
+		},
+		Status: rolloutsapiv1alpha1.RolloutStatus{
+			ObservedGeneration: 1,
+			Phase:              phase,
+			CanaryStatus: &rolloutsapiv1alpha1.CanaryStatus{
+				CurrentStepIndex: stepIndex,
+				CurrentStepState: stepState,
+			},
+		},
+	}
+}
+
+func toUnstructured(t *testing.T, obj runtime.Object) *unstructured.Unstructured {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &unstructured.Unstructured{Object: content}
+}
+
+func TestRolloutStatusWatchRollout(t *testing.T) {
+	progressing := newStatusTestRollout(rolloutsapiv1alpha1.RolloutPhaseProgressing, 1, rolloutsapiv1alpha1.CanaryStepStatePaused)
+	completed := newStatusTestRollout(rolloutsapiv1alpha1.RolloutPhaseProgressing, 2, rolloutsapiv1alpha1.CanaryStepStateCompleted)
+
+	testCases := []struct {
+		name      string
+		events    []runtime.Object
+		timeout   time.Duration
+		expectOut string
+		expectErr string
+	}{
+		{
+			name:   "watch until the canary is completed",
+			events: []runtime.Object{completed},
+			expectOut: "Waiting for rollout \"ro\" to finish: step 1 of 2 (weight 20%) in state StepPaused, phase Progressing...\n" +
+				"rollout \"ro\" successfully completed\n",
+		},
+		{
+			name:      "give up when the timeout elapses",
+			timeout:   time.Second,
+			expectOut: "Waiting for rollout \"ro\" to finish: step 1 of 2 (weight 20%) in state StepPaused, phase Progressing...\n",
+			expectErr: "timed out waiting for the condition",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// the fake server encodes a copy of its own, since encoding sets the kind of the object
+			tf := newUndoTestFactory(t, map[string]runtime.Object{"rollouts/ro": progressing.DeepCopy()})
+			defer tf.Cleanup()
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			o := NewRolloutStatusOptions(streams)
+			if err := o.Complete(tf, []string{"rollout/ro"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.Timeout = tc.timeout
+
+			gvr := rolloutsapiv1alpha1.GroupVersion.WithResource("rollouts")
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: "RolloutList"}, toUnstructured(t, progressing))
+			fakeWatch := watch.NewFake()
+			client.PrependWatchReactor("rollouts", kubetesting.DefaultWatchReactor(fakeWatch, nil))
+			o.DynamicClient = client
+
+			// the events are converted before they are sent, so that the watcher does not share them with the test
+			var events []*unstructured.Unstructured
+			for _, event := range tc.events {
+				events = append(events, toUnstructured(t, event))
+			}
+			go func() {
+				for _, event := range events {
+					fakeWatch.Modify(event)
+				}
+			}()
+
+			err := o.Run()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectOut, out.String())
+		})
+	}
+}
+
+func TestRolloutStatusOutputWatchEvents(t *testing.T) {
+	progressing := newStatusTestRollout(rolloutsapiv1alpha1.RolloutPhaseProgressing, 1, rolloutsapiv1alpha1.CanaryStepStatePaused)
+	upgrading := newStatusTestRollout(rolloutsapiv1alpha1.RolloutPhaseProgressing, 2, rolloutsapiv1alpha1.CanaryStepStateUpgrade)
+	completed := newStatusTestRollout(rolloutsapiv1alpha1.RolloutPhaseProgressing, 2, rolloutsapiv1alpha1.CanaryStepStateCompleted)
+
+	testCases := []struct {
+		name      string
+		events    []runtime.Object
+		timeout   time.Duration
+		expectOut string
+		expectErr string
+	}{
+		{
+			name: "status changes until the canary is completed",
+			// the second event repeats the first status, which is only printed once
+			events: []runtime.Object{progressing, upgrading, completed},
+			expectOut: `{"type":"status","time":"2024-05-01T10:00:00Z","kind":"Rollout","name":"ro","phase":"Progressing","step":1,"status":"Waiting for rollout \"ro\" to finish: step 1 of 2 (weight 20%) in state StepPaused, phase Progressing...","done":false}` + "\n" +
+				`{"type":"status","time":"2024-05-01T10:00:00Z","kind":"Rollout","name":"ro","phase":"Progressing","step":2,"status":"Waiting for rollout \"ro\" to finish: step 2 of 2 (weight 100%) in state StepUpgrade, phase Progressing...","done":false}` + "\n" +
+				`{"type":"status","time":"2024-05-01T10:00:00Z","kind":"Rollout","name":"ro","phase":"Progressing","step":2,"status":"rollout \"ro\" successfully completed","done":true}` + "\n" +
+				`{"type":"summary","time":"2024-05-01T10:00:00Z","kind":"Rollout","name":"ro","phase":"Progressing","step":2,"status":"rollout \"ro\" successfully completed","done":true}` + "\n",
+		},
+		{
+			name:    "summary of a timeout",
+			timeout: time.Second,
+			expectOut: `{"type":"status","time":"2024-05-01T10:00:00Z","kind":"Rollout","name":"ro","phase":"Progressing","step":1,"status":"Waiting for rollout \"ro\" to finish: step 1 of 2 (weight 20%) in state StepPaused, phase Progressing...","done":false}` + "\n" +
+				`{"type":"summary","time":"2024-05-01T10:00:00Z","kind":"Rollout","name":"ro","phase":"Progressing","step":1,"status":"Waiting for rollout \"ro\" to finish: step 1 of 2 (weight 20%) in state StepPaused, phase Progressing...","done":false,"error":"timed out waiting for the condition"}` + "\n",
+			expectErr: "timed out waiting for the condition",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// the fake server encodes a copy of its own, since encoding sets the kind of the object
+			tf := newUndoTestFactory(t, map[string]runtime.Object{"rollouts/ro": progressing.DeepCopy()})
+			defer tf.Cleanup()
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			o := NewRolloutStatusOptions(streams)
+			if err := o.Complete(tf, []string{"rollout/ro"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.Timeout = tc.timeout
+			o.OutputWatchEvents = true
+			o.now = func() time.Time { return time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC) }
+
+			gvr := rolloutsapiv1alpha1.GroupVersion.WithResource("rollouts")
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: "RolloutList"}, toUnstructured(t, progressing))
+			fakeWatch := watch.NewFake()
+			client.PrependWatchReactor("rollouts", kubetesting.DefaultWatchReactor(fakeWatch, nil))
+			o.DynamicClient = client
+
+			// the events are converted before they are sent, so that the watcher does not share them with the test
+			var events []*unstructured.Unstructured
+			for _, event := range tc.events {
+				events = append(events, toUnstructured(t, event))
+			}
+			go func() {
+				for _, event := range events {
+					fakeWatch.Modify(event)
+				}
+			}()
+
+			err := o.Run()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectOut, out.String())
+		})
+	}
+}
+
+// notifyingWriter sends on lines whenever a line is written to it
+type notifyingWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	lines chan struct{}
+}
+
+func (w *notifyingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	n, err := w.buf.Write(p)
+	w.mu.Unlock()
+	for i := 0; i < bytes.Count(p, []byte("\n")); i++ {
+		w.lines <- struct{}{}
+	}
+	return n, err
+}
+
+func TestRolloutStatusMultipleTargets(t *testing.T) {
+	newRollout := func(name string, stepIndex int32, stepState rolloutsapiv1alpha1.CanaryStepState) *unstructured.Unstructured {
+		ro := newStatusTestRollout(rolloutsapiv1alpha1.RolloutPhaseProgressing, stepIndex, stepState)
+		ro.Name = name
+		return toUnstructured(t, ro)
+	}
+	frontend := newRollout("frontend", 1, rolloutsapiv1alpha1.CanaryStepStatePaused)
+	backend := newRollout("backend", 1, rolloutsapiv1alpha1.CanaryStepStatePaused)
+
+	testCases := []struct {
+		name string
+		// send sends the events of the watches of frontend and backend, printed tells when n more lines are printed
+		send      func(frontend, backend *watch.FakeWatcher, printed func(n int))
+		expectOut map[string][]string
+		expectErr string
+	}{
+		{
+			name: "watch until both rollouts are completed",
+			// frontend is completed first, the watch goes on until backend is completed too
+			send: func(frontendWatch, backendWatch *watch.FakeWatcher, printed func(n int)) {
+				printed(2)
+				frontendWatch.Modify(newRollout("frontend", 2, rolloutsapiv1alpha1.CanaryStepStateCompleted))
+				printed(1)
+				backendWatch.Modify(newRollout("backend", 2, rolloutsapiv1alpha1.CanaryStepStateUpgrade))
+				backendWatch.Modify(newRollout("backend", 2, rolloutsapiv1alpha1.CanaryStepStateCompleted))
+			},
+			expectOut: map[string][]string{
+				"frontend": {
+					"rollouts.rollouts.kruise.io/frontend: Waiting for rollout \"frontend\" to finish: step 1 of 2 (weight 20%) in state StepPaused, phase Progressing...",
+					"rollouts.rollouts.kruise.io/frontend: rollout \"frontend\" successfully completed",
+				},
+				"backend": {
+					"rollouts.rollouts.kruise.io/backend: Waiting for rollout \"backend\" to finish: step 1 of 2 (weight 20%) in state StepPaused, phase Progressing...",
+					"rollouts.rollouts.kruise.io/backend: Waiting for rollout \"backend\" to finish: step 2 of 2 (weight 100%) in state StepUpgrade, phase Progressing...",
+					"rollouts.rollouts.kruise.io/backend: rollout \"backend\" successfully completed",
+				},
+			},
+		},
+		{
+			name: "stop watching once a rollout fails",
+			send: func(frontendWatch, backendWatch *watch.FakeWatcher, printed func(n int)) {
+				printed(2)
+				backendWatch.Delete(backend)
+			},
+			expectOut: map[string][]string{
+				"frontend": {
+					"rollouts.rollouts.kruise.io/frontend: Waiting for rollout \"frontend\" to finish: step 1 of 2 (weight 20%) in state StepPaused, phase Progressing...",
+				},
+				"backend": {
+					"rollouts.rollouts.kruise.io/backend: Waiting for rollout \"backend\" to finish: step 1 of 2 (weight 20%) in state StepPaused, phase Progressing...",
+				},
+			},
+			expectErr: "rollouts.rollouts.kruise.io/backend: object has been deleted",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, map[string]runtime.Object{"rollouts/frontend": frontend, "rollouts/backend": backend})
+			defer tf.Cleanup()
+
+			streams := genericclioptions.NewTestIOStreamsDiscard()
+			out := &notifyingWriter{lines: make(chan struct{}, 10)}
+			streams.Out = out
+			o := NewRolloutStatusOptions(streams)
+			if err := o.Complete(tf, []string{"rollout/frontend", "rollout/backend"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// every rollout is listed and watched by name
+			gvr := rolloutsapiv1alpha1.GroupVersion.WithResource("rollouts")
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: "RolloutList"})
+			objs := map[string]*unstructured.Unstructured{"frontend": frontend, "backend": backend}
+			watches := map[string]*watch.FakeWatcher{"frontend": watch.NewFake(), "backend": watch.NewFake()}
+			client.PrependReactor("list", "rollouts", func(action kubetesting.Action) (bool, runtime.Object, error) {
+				name, _ := action.(kubetesting.ListAction).GetListRestrictions().Fields.RequiresExactMatch("metadata.name")
+				list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": gvr.GroupVersion().String(), "kind": "RolloutList"}}
+				list.Items = append(list.Items, *objs[name])
+				return true, list, nil
+			})
+			client.PrependWatchReactor("rollouts", func(action kubetesting.Action) (bool, watch.Interface, error) {
+				name, _ := action.(kubetesting.WatchAction).GetWatchRestrictions().Fields.RequiresExactMatch("metadata.name")
+				return true, watches[name], nil
+			})
+			o.DynamicClient = client
+
+			go tc.send(watches["frontend"], watches["backend"], func(n int) {
+				for i := 0; i < n; i++ {
+					<-out.lines
+				}
+			})
+
+			err := o.Run()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			// the statuses of the rollouts are interleaved, every one of them is printed in order
+			lines := map[string][]string{}
+			for _, line := range strings.Split(strings.TrimSuffix(out.buf.String(), "\n"), "\n") {
+				for name := range objs {
+					if strings.HasPrefix(line, "rollouts.rollouts.kruise.io/"+name+": ") {
+						lines[name] = append(lines[name], line)
+					}
+				}
+			}
+			assert.Equal(t, tc.expectOut, lines)
+		})
+	}
+}
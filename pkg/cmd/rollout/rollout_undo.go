@@ -18,22 +18,51 @@ limitations under the License.
 package rollout
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
-	rolloutsapiv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
-	rolloutsapiv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
 	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	internalpolymorphichelpers "github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/set"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
+	"k8s.io/kubectl/pkg/util/term"
+	"sigs.k8s.io/yaml"
 )
 
 // UndoOptions is the start of the data required to perform the operation.  As new fields are added, add them here instead of
@@ -43,12 +72,83 @@ type UndoOptions struct {
 	ToPrinter  func(string) (printers.ResourcePrinter, error)
 
 	Builder          func() *resource.Builder
+	Rollbacker       internalpolymorphichelpers.RollbackerFunc
+	StableRevision   internalpolymorphichelpers.StableRevisionFunc
+	ImageRevision    internalpolymorphichelpers.ImageRevisionFunc
 	ToRevision       int64
+	ToLatestStable   bool
+	ToImage          string
+	WorkloadsOnly    bool
 	DryRunStrategy   cmdutil.DryRunStrategy
+	FieldManager     string
+	Timeout          time.Duration
+	Parallelism      int
+	RecordFlags      *genericclioptions.RecordFlags
+	Recorder         genericclioptions.Recorder
 	Resources        []string
+	FromFile         string
+	LabelSelector    string
+	FieldSelector    string
+	AllNamespaces    bool
 	Namespace        string
 	EnforceNamespace bool
 	RESTClientGetter genericclioptions.RESTClientGetter
+	RESTMapper       meta.RESTMapper
+	ClientForMapping func(*meta.RESTMapping) (resource.RESTClient, error)
+
+	// SummaryFormat is the format of the summary of the rollbacks printed once all are done instead of a line per
+	// rollback, set by --output-format. "table" is the only format.
+	SummaryFormat string
+	// OutputFile is the file the rolled back objects are written to in the format of --output, in addition to the
+	// output of the command
+	OutputFile string
+
+	// PreviousConfigOnly restores only the pod template of the revision, keeping the replicas and update strategy
+	PreviousConfigOnly bool
+	// Full restores the whole revision given with --to-revision, including the rolling update settings an Advanced
+	// DaemonSet or Advanced StatefulSet revision records, e.g. its partition
+	Full bool
+	// FromBackup is the ConfigMap, given as configmap/NAME, holding a manifest of every workload whose pod template is
+	// restored instead of the one of a revision
+	FromBackup string
+
+	// Resume resumes the rolled back workloads which are paused, since their pods are not rolled back until they
+	// are resumed. Resumer is only used to tell whether a workload is paused when Resume is false.
+	Resume  bool
+	Resumer internalpolymorphichelpers.ObjectResumerFunc
+
+	// UpdateLastApplied rewrites the pod template of the last-applied-configuration annotation of the rolled back
+	// workloads to the restored one, so that a later "kubectl apply" of the old manifest is not mistaken for no change
+	UpdateLastApplied bool
+
+	// Wait waits for every rolled back workload to complete its rollout, as told by StatusViewer, within Timeout
+	Wait         bool
+	StatusViewer internalpolymorphichelpers.StatusViewerFunc
+	ClientSet    kubernetes.Interface
+
+	// Quiet suppresses the line printed for every rolled back or skipped target, unless an output format is given
+	Quiet bool
+	// NoCounts suppresses the line counting the targets undone, skipped and failed
+	NoCounts bool
+	// ContinueOnError attempts every target even if some cannot be rolled back, e.g. a target of a kind which cannot be
+	// rolled back is reported as failed instead of failing the command before any rollback
+	ContinueOnError bool
+	// Explain prints the plan of the rollbacks, the targets with their workloads and revisions, and rolls nothing back
+	Explain bool
+
+	// Confirm asks for a confirmation before every rollback, ConfirmNamespaces only before
+	// rollbacks in namespaces matching one of its patterns. Yes skips the confirmations.
+	Confirm           bool
+	ConfirmNamespaces []string
+	Yes               bool
+	IsTerminal        func(in io.Reader) bool
+	// toRevisionSet tells whether --to-revision was given, which --full requires even for the previous revision
+	toRevisionSet bool
+	// confirmReader buffers In, so that the answers to several confirmations can be read from it
+	confirmReader *bufio.Reader
+	printMu       sync.Mutex
+	// rolloutsByNamespace caches the rollouts listed in a namespace, to find the rollouts managing a workload
+	rolloutsByNamespace map[string][]runtime.Object
 
 	resource.FilenameOptions
 	genericclioptions.IOStreams
@@ -56,7 +156,95 @@ type UndoOptions struct {
 
 var (
 	undoLong = templates.LongDesc(`
-		Rollback to a previous rollout.`)
+		Rollback to a previous rollout.
+
+		If a resource given as an argument cannot be rolled back, e.g. a pod, the command fails before
+		rolling back any of the others. With --continue-on-error, every target is attempted regardless
+		of the failures of the others: such a resource is reported as failed, and the errors of all
+		targets are reported once all are done.
+
+		--to-revision takes a revision number, "previous" for the previous revision, which is the
+		default, or "latest-stable" for the last known-good revision. The latest stable revision is the
+		stable revision recorded in the status of the rollout, if a rollout is given, otherwise the
+		current revision recorded in the status of a CloneSet, an Advanced StatefulSet or a StatefulSet.
+
+		--to-image=CONTAINER=IMAGE rolls back to the most recent revision whose container CONTAINER runs
+		IMAGE, instead of a revision number. It fails with the images found in the history if no
+		revision runs IMAGE.
+
+		Rollouts are rolled back through the workload they reference. A rollout has no revisions of
+		its own, so --to-revision selects a revision of that workload, as listed by "rollout history"
+		on the workload. If a rollout and its workload are both given, e.g. as two documents of a
+		manifest read with -f -, the workload is only rolled back once. With --workloads-only a rollout
+		only selects its workload, which is then rolled back as if it was given itself: the stable
+		revision recorded by the rollout is not used, and errors name the workload only. A workload
+		given directly is still rolled back if a rollout manages it, but a warning suggests to roll back
+		the rollout instead, since the rollout controller may conflict with the rollback.
+
+		--from-backup=configmap/NAME restores the pod template of a manifest stored in a ConfigMap of the
+		namespace of the workload, e.g. a snapshot taken before a change, instead of the one of a
+		revision. The manifest, in YAML or JSON, is read from the key named after the workload, or from
+		the only key of the ConfigMap. It must be of the kind of the workload, and only its pod template
+		is restored.
+
+		--from-file reads the targets from a file with one TYPE/NAME per line, in addition to the ones
+		given as arguments. Blank lines and lines starting with # are ignored, and a target listed
+		several times is only rolled back once.
+
+		With --previous-config-only only the pod template of the revision is restored. The replicas and
+		the update strategy, e.g. the partition, of the workload are kept even if the revision records
+		other values.
+
+		The rolling update settings of an Advanced DaemonSet, its maxUnavailable and partition, are kept
+		as they are unless --to-revision is given with --full, since restoring the ones of an old revision
+		may speed the rollback up beyond what the nodes tolerate. The same goes for an Advanced
+		StatefulSet: its partition is kept, so that a rollback meant to revert the pod template does not
+		update the pods of the ordinals the partition holds back. With "--to-revision=3 --full", the
+		partition the revision records is restored as well, which may update all pods at once.
+
+		A paused CloneSet, Advanced StatefulSet or Deployment does not roll its pods back until it is
+		resumed. A warning is printed when one is rolled back, unless --resume is given, which resumes
+		it right after the rollback. A workload rolled back through its rollout is left as the rollout
+		controller set it.
+
+		A workload managed with "kubectl apply" keeps the pod template it was applied with in its
+		last-applied-configuration annotation, which a rollback does not change. With
+		--update-last-applied, the pod template of the annotation is replaced by the rolled back one, so
+		that applying the manifest the rollback reverted updates the pods again. Workloads without the
+		annotation are left as they are, and nothing is updated with --dry-run.
+
+		With --output-format=table, a table of the rolled back workloads is printed once all are done
+		instead of a line per workload, with the revision every workload ran before the rollback and the
+		revision it was rolled back to.
+
+		With --output-file, the rolled back objects are also written to a file in the format of --output,
+		e.g. to keep a record of the rollbacks. A target skipped because it already matches the revision
+		is not written. The rollbacks are not undone when the file cannot be written, the error is reported
+		once all are done.
+
+		With --all-namespaces, the workloads matching the selector are rolled back in every namespace,
+		and a workload is only rolled back once per namespace.
+
+		Resources read with -f or -k that cannot be rolled back, such as the ConfigMaps and Services of
+		a kustomize directory, are skipped with a warning, and the workloads and rollouts among them
+		are rolled back.
+
+		With --wait, the command waits for every rolled back workload to complete its rollout, e.g. for
+		all pods of a CloneSet to be updated and available, until --timeout. A workload which has not
+		completed its rollout by then is reported with the last status observed.
+
+		After several targets, a line counting the targets undone, skipped as duplicates and failed is
+		printed on stderr, unless --no-counts is given. A workload given through a rollout counts once.
+		With --quiet, the line printed for every rolled back target is left out, while the errors, the
+		warnings and the counts are still printed, and so are the objects with -o json or -o yaml.
+
+		With --explain, the targets are resolved as for a rollback, but only read: a table lists every
+		target, the workload it resolves to, e.g. the workload of a rollout, and the revisions it would
+		be rolled back from and to. Unlike --dry-run=server, no rollback is sent to the server.
+
+		The command exits with 0 if every target was rolled back or already matched the revision,
+		2 if its arguments or flags are invalid, 3 if only some of several targets could be rolled
+		back, and 1 on any other failure.`)
 
 	undoExample = templates.Examples(`
 		# Rollback to the previous cloneset
@@ -65,22 +253,105 @@ var (
 		# Rollback to the previous Advanced StatefulSet
 		kubectl-kruise rollout undo asts/abc
 
+		# Rollback an Advanced StatefulSet to revision 3, restoring the partition it recorded as well
+		kubectl-kruise rollout undo asts/abc --to-revision=3 --full
+
+		# Rollback all clonesets labeled with app=nginx to their previous revisions
+		kubectl-kruise rollout undo cloneset -l app=nginx
+
+		# Rollback the workloads listed in targets.txt, one TYPE/NAME per line
+		kubectl-kruise rollout undo --from-file=targets.txt
+
+		# Rollback the clonesets in namespaces other than kube-system
+		kubectl-kruise rollout undo cloneset --all-namespaces --field-selector metadata.namespace!=kube-system
+
+		# Rollback all clonesets labeled with app=nginx in all namespaces
+		kubectl-kruise rollout undo cloneset -l app=nginx --all-namespaces
+
+		# Rollback all clonesets labeled with app=nginx, 5 at a time
+		kubectl-kruise rollout undo cloneset -l app=nginx --parallelism=5
+
+		# Rollback all clonesets labeled with app=nginx, giving up after 30 seconds
+		kubectl-kruise rollout undo cloneset -l app=nginx --timeout=30s
+
 		# Rollback to daemonset revision 3
 		kubectl-kruise rollout undo daemonset/abc --to-revision=3
 
+		# Rollback cloneset/abc to the most recent revision whose nginx container runs nginx:1.24
+		kubectl-kruise rollout undo cloneset/abc --to-image=nginx=nginx:1.24
+
+		# Restore the pod template of cloneset/abc stored in configmap abc-snapshot
+		kubectl-kruise rollout undo cloneset/abc --from-backup=cm/abc-snapshot
+
+		# Rollback the pod template of cloneset/abc, keeping its current replicas and partition
+		kubectl-kruise rollout undo cloneset/abc --previous-config-only
+
+		# Rollback cloneset/abc and wait up to 5 minutes for its pods to be rolled back
+		kubectl-kruise rollout undo cloneset/abc --wait --timeout=5m
+
+		# Rollback cloneset/abc and resume it if it is paused, so that its pods are rolled back
+		kubectl-kruise rollout undo cloneset/abc --resume
+
+		# Rollback cloneset/abc and record the command in its kubernetes.io/change-cause annotation
+		kubectl-kruise rollout undo cloneset/abc --record
+
 		# Rollback to the previous deployment with dry-run
 		kubectl-kruise rollout undo --dry-run=server deployment/abc
+
+		# Show the pod template changes a rollback of cloneset/abc would make
+		kubectl-kruise rollout undo --dry-run=client cloneset/abc
+
+		# List the workloads and revisions a rollback of all clonesets labeled with app=nginx would roll back
+		kubectl-kruise rollout undo cloneset -l app=nginx --explain
 		
+		# Rollback cloneset/abc and print the rolled back object as JSON
+		kubectl-kruise rollout undo cloneset/abc -o json
+
+		# Rollback all clonesets labeled with app=nginx and keep the rolled back objects in rollback.yaml
+		kubectl-kruise rollout undo cloneset -l app=nginx -o yaml --output-file=rollback.yaml
+
+		# Rollback all clonesets labeled with app=nginx and print only the names of the rolled back workloads,
+		# e.g. to pass them to another command. A rollout is printed as the workload it references.
+		kubectl-kruise rollout undo cloneset -l app=nginx -o name
+
+		# Rollback all clonesets labeled with app=nginx and print a table of their revisions before and after
+		kubectl-kruise rollout undo cloneset -l app=nginx --output-format=table
+
+		# Rollback cloneset/abc and print the image it was rolled back to
+		kubectl-kruise rollout undo cloneset/abc -o jsonpath='{.spec.template.spec.containers[0].image}'
+
 		# Rollback to workload via rollout api object
-		kubectl-kruise rollout undo rollout/abc`)
+		kubectl-kruise rollout undo rollout/abc
+
+		# Rollback the workload referenced by rollout/abc to its revision 5
+		kubectl-kruise rollout undo rollout/abc --to-revision=5
+
+		# Rollback the workload referenced by rollout/abc to the stable revision recorded by the rollout
+		kubectl-kruise rollout undo rollout/abc --to-revision=latest-stable
+
+		# Rollback the workload referenced by rollout/abc to the current revision recorded by the workload
+		kubectl-kruise rollout undo rollout/abc --to-revision=latest-stable --workloads-only
+
+		# Rollback the workloads of a manifest read from stdin
+		cat cloneset.yaml | kubectl-kruise rollout undo -f -
+
+		# Rollback the workloads and rollouts of a kustomize directory
+		kubectl-kruise rollout undo -k dir/
+
+		# Ask to type the name of the workload before rolling back in namespaces starting with prod
+		kubectl-kruise rollout undo cloneset/abc -n prod-eu --confirm-namespaces='prod*'`)
 )
 
 // NewRolloutUndoOptions returns an initialized UndoOptions instance
 func NewRolloutUndoOptions(streams genericclioptions.IOStreams) *UndoOptions {
 	return &UndoOptions{
-		PrintFlags: genericclioptions.NewPrintFlags("rolled back").WithTypeSetter(internalapi.GetScheme()),
-		IOStreams:  streams,
-		ToRevision: int64(0),
+		PrintFlags:   genericclioptions.NewPrintFlags("rolled back").WithTypeSetter(internalapi.GetScheme()),
+		IOStreams:    streams,
+		ToRevision:   int64(0),
+		FieldManager: "kubectl-kruise-rollout",
+		Parallelism:  1,
+		RecordFlags:  genericclioptions.NewRecordFlags(),
+		Recorder:     genericclioptions.NoopRecorder{},
 	}
 }
 
@@ -98,16 +369,44 @@ func NewCmdRolloutUndo(f cmdutil.Factory, streams genericclioptions.IOStreams) *
 		Example:               undoExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
-			cmdutil.CheckErr(o.Validate())
-			cmdutil.CheckErr(o.RunUndo())
+			checkErr(validationError(o.Validate()))
+			checkErr(o.RunUndo())
 		},
-		ValidArgs: validArgs,
+		ValidArgsFunction: resourceNameCompletionFunc(f, validArgs),
 	}
 
-	cmd.Flags().Int64Var(&o.ToRevision, "to-revision", o.ToRevision, "The revision to rollback to. Default to 0 (last revision).")
+	cmd.Flags().Var(&toRevisionValue{o: o}, "to-revision", `The revision to rollback to: a revision number, "previous" or "latest-stable". Default to 0 (previous revision).`)
+	cmdutil.CheckErr(cmd.RegisterFlagCompletionFunc("to-revision", toRevisionCompletionFunc(f)))
+	cmd.Flags().StringVar(&o.ToImage, "to-image", o.ToImage, "Rollback to the most recent revision whose container runs an image, given as CONTAINER=IMAGE, e.g. nginx=nginx:1.24.")
+	cmd.Flags().StringVar(&o.FromBackup, "from-backup", o.FromBackup, "A ConfigMap, given as configmap/NAME, holding a manifest of the workload whose pod template is restored instead of the one of a revision.")
+	cmd.Flags().BoolVar(&o.PreviousConfigOnly, "previous-config-only", o.PreviousConfigOnly, "If true, only restore the pod template of the revision, keeping the replicas and update strategy of the workloads.")
+	cmd.Flags().BoolVar(&o.Full, "full", o.Full, "If true, restore the whole revision given with --to-revision, including the rolling update settings, maxUnavailable and partition, of Advanced DaemonSets and Advanced StatefulSets instead of keeping the live ones.")
+	cmd.Flags().BoolVar(&o.Full, "restore-strategy", o.Full, "Deprecated, use --full instead.")
+	cmd.Flags().MarkDeprecated("restore-strategy", "--restore-strategy will be removed in the future, please use --to-revision with --full instead")
+	cmd.Flags().BoolVar(&o.Resume, "resume", o.Resume, "If true, resume the rolled back workloads which are paused, so that their pods are rolled back.")
+	cmd.Flags().BoolVar(&o.UpdateLastApplied, "update-last-applied", o.UpdateLastApplied, "If true, update the pod template of the last-applied-configuration annotation of the rolled back workloads to the rolled back one.")
+	cmd.Flags().BoolVar(&o.WorkloadsOnly, "workloads-only", o.WorkloadsOnly, "If true, a rollout only selects the workload it references, which is rolled back as if it was given directly.")
 	usage := "identifying the resource to get from a server."
 	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
+	cmd.Flags().StringVar(&o.FromFile, "from-file", o.FromFile, "A file listing the targets to roll back, one TYPE/NAME per line. Blank lines and lines starting with # are ignored.")
+	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
+	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='. (e.g. --field-selector metadata.namespace!=kube-system) The server only supports a limited number of field queries per type.")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, roll back the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
 	cmdutil.AddDryRunFlag(cmd)
+	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, o.FieldManager)
+	cmd.Flags().BoolVar(o.RecordFlags.Record, "record", *o.RecordFlags.Record, "If true, record the command line in the kubernetes.io/change-cause annotation of the rolled back workloads.")
+	cmd.Flags().IntVar(&o.Parallelism, "parallelism", o.Parallelism, "The number of workloads to roll back at the same time. Above 1, the output of the workloads is printed sorted by namespace, kind and name.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to wait for the rollbacks before giving up, zero means never. Any other values should contain a corresponding time unit (e.g. 1s, 2m, 3h).")
+	cmd.Flags().StringVar(&o.OutputFile, "output-file", o.OutputFile, "A file to write the rolled back objects to, in the format of --output, in addition to the output of the command, e.g. for an audit trail.")
+	cmd.Flags().StringVar(&o.SummaryFormat, "output-format", o.SummaryFormat, "If set to table, print a table summarizing the rollbacks once all are done, instead of a line per rolled back workload.")
+	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "If true, ask to type the name of every workload before rolling it back.")
+	cmd.Flags().StringSliceVar(&o.ConfirmNamespaces, "confirm-namespaces", o.ConfirmNamespaces, "Ask to type the name of a workload before rolling it back if its namespace matches one of these glob patterns, e.g. 'prod*'.")
+	cmd.Flags().BoolVarP(&o.Yes, "yes", "y", o.Yes, "If true, roll back without asking for confirmation.")
+	cmd.Flags().BoolVar(&o.Wait, "wait", o.Wait, "If true, wait for every rolled back workload to complete its rollout, giving up after --timeout.")
+	cmd.Flags().BoolVar(&o.ContinueOnError, "continue-on-error", o.ContinueOnError, "If true, attempt to roll back every target regardless of the failures of the others, and report the errors once all are done.")
+	cmd.Flags().BoolVar(&o.Explain, "explain", o.Explain, "If true, print the targets, the workloads they resolve to and the revisions they would be rolled back to, without rolling back anything.")
+	cmd.Flags().BoolVarP(&o.Quiet, "quiet", "q", o.Quiet, "If true, do not print a line for every rolled back target. Errors, the counts of the targets and the objects printed with -o are still printed.")
+	cmd.Flags().BoolVar(&o.NoCounts, "no-counts", o.NoCounts, "If true, do not print the line counting the targets undone, skipped and failed after several targets.")
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
 }
@@ -116,6 +415,11 @@ func NewCmdRolloutUndo(f cmdutil.Factory, streams genericclioptions.IOStreams) *
 func (o *UndoOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
 	o.Resources = args
 	var err error
+	if len(o.FromFile) > 0 {
+		if o.Resources, err = appendTargetsFromFile(o.Resources, o.FromFile); err != nil {
+			return err
+		}
+	}
 	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
 	if err != nil {
 		return err
@@ -131,134 +435,1193 @@ func (o *UndoOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []str
 		return o.PrintFlags.ToPrinter()
 	}
 
+	if err = o.RecordFlags.Complete(cmd); err != nil {
+		return err
+	}
+	if o.Recorder, err = o.RecordFlags.ToRecorder(); err != nil {
+		return err
+	}
+
 	o.RESTClientGetter = f
+	if o.RESTMapper, err = f.ToRESTMapper(); err != nil {
+		return err
+	}
+	o.ClientForMapping = f.ClientForMapping
 	o.Builder = f.NewBuilder
+	o.Rollbacker = internalpolymorphichelpers.RollbackerFn
+	o.StableRevision = internalpolymorphichelpers.StableRevisionFn
+	o.ImageRevision = internalpolymorphichelpers.ImageRevisionFn
+	o.Resumer = internalpolymorphichelpers.ObjectResumerFn
+	o.StatusViewer = internalpolymorphichelpers.StatusViewerFn
+	if o.ClientSet, err = f.KubernetesClientSet(); err != nil {
+		return err
+	}
+	o.IsTerminal = func(in io.Reader) bool {
+		return term.IsTerminal(in)
+	}
 
 	return err
 }
 
 func (o *UndoOptions) Validate() error {
 	if len(o.Resources) == 0 && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
+		if len(o.LabelSelector) > 0 {
+			return fmt.Errorf("a resource type must be specified along with --selector, e.g. cloneset -l app=nginx")
+		}
+		if len(o.FieldSelector) > 0 {
+			return fmt.Errorf("a resource type must be specified along with --field-selector, e.g. cloneset --field-selector metadata.name=web")
+		}
 		return fmt.Errorf("required resource not specified")
 	}
+	if len(o.FieldSelector) > 0 {
+		if _, err := fields.ParseSelector(o.FieldSelector); err != nil {
+			return fmt.Errorf("invalid --field-selector: %v", err)
+		}
+	}
+	for _, pattern := range o.ConfirmNamespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid --confirm-namespaces pattern %q: %v", pattern, err)
+		}
+	}
+	// 0 is the default and means the previous revision
+	if o.ToRevision < 0 {
+		return fmt.Errorf("--to-revision must be a non-negative revision number, or 0 for the previous revision, got %d", o.ToRevision)
+	}
+	if len(o.ToImage) > 0 {
+		if o.ToRevision != 0 || o.ToLatestStable {
+			return fmt.Errorf("--to-image and --to-revision cannot be used together")
+		}
+		if _, _, err := o.toImage(); err != nil {
+			return err
+		}
+	}
+	if len(o.FromBackup) > 0 {
+		if o.ToRevision != 0 || o.ToLatestStable || len(o.ToImage) > 0 || o.Full {
+			return fmt.Errorf("--from-backup only restores the pod template of the backup, it cannot be used with --to-revision, --to-image or --full")
+		}
+		if _, err := o.backupName(); err != nil {
+			return err
+		}
+	}
+	// the partition of an old revision may update all pods at once, so it is only restored for a revision asked for
+	if o.Full && !o.toRevisionSet && o.ToRevision == 0 && !o.ToLatestStable {
+		return fmt.Errorf("--full restores the partition of the revision, it can only be used with --to-revision")
+	}
+	if len(o.SummaryFormat) > 0 {
+		if o.SummaryFormat != summaryFormatTable {
+			return fmt.Errorf("--output-format must be %s, got %q", summaryFormatTable, o.SummaryFormat)
+		}
+		if o.outputFormatSpecified() {
+			return fmt.Errorf("--output-format and --output cannot be used together")
+		}
+	}
+	if o.Explain && (o.outputFormatSpecified() || len(o.SummaryFormat) > 0 || len(o.OutputFile) > 0 || o.Wait) {
+		return fmt.Errorf("--explain only prints the plan of the rollbacks, it cannot be used with --output, --output-format, --output-file or --wait")
+	}
+	if len(o.OutputFile) > 0 && o.DryRunStrategy != cmdutil.DryRunNone {
+		return fmt.Errorf("--output-file cannot be used with --dry-run, since no object is rolled back")
+	}
+	if o.Parallelism < 1 {
+		return fmt.Errorf("--parallelism must be at least 1, got %d", o.Parallelism)
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("--timeout must not be negative, got %v", o.Timeout)
+	}
 	return nil
 }
 
+// undoRun is the state of a single RunUndo, shared by the undos of its targets
+type undoRun struct {
+	ctx context.Context
+	// annotations record the command, they are set by the patch of the rollback itself
+	annotations map[string]string
+
+	// fromFiles tells whether the targets are read from files or kustomize directories, which may hold resources of
+	// any kind. resolveErr is the error of the targets which could not be fetched.
+	fromFiles  bool
+	resolveErr error
+
+	// outputFile is written by a single printer, so that a YAML printer separates the objects. fileErr is the first
+	// error writing to it, after which the rolled back objects are not written.
+	outputFile  *os.File
+	filePrinter printers.ResourcePrinter
+	fileErr     error
+
+	// a rollbacker builds its own clients, so the rollbackers are cached by kind for the whole command, which spares
+	// a batch of targets of the same kind from building the same clients again for every target
+	rollbackersMu sync.Mutex
+	rollbackers   map[schema.GroupVersionKind]internalpolymorphichelpers.Rollbacker
+
+	// confirmMu serializes the confirmations of parallel undos, since they share the terminal
+	confirmMu   sync.Mutex
+	unconfirmed int
+
+	// succeeded counts the targets undone without an error, to tell a partial failure from a failure. With duplicates
+	// and failed, it makes the counts printed once all targets are done. Once the deadline is exceeded, the remaining
+	// targets are given up on and the timeout is reported once.
+	mu                            sync.Mutex
+	succeeded, duplicates, failed int
+	timeoutReported               bool
+
+	// deDuplica holds the workloads already undone, by namespace and target
+	deDuplica map[string]struct{}
+	// skipped lists the resources read from files which cannot be rolled back
+	skipped []string
+	// results holds the output of the undos run in parallel
+	results []*undoResult
+	// with --output-format, the rows of the summary are added in the order of the targets and filled by their undos
+	summary []*undoSummaryRow
+}
+
+// countFailed counts a target which failed before its undo, e.g. a rollout whose workload cannot be found
+func (run *undoRun) countFailed(err error) error {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	run.failed++
+	return err
+}
+
+// undoResult is the output of an undo run in parallel, printed once all are done
+type undoResult struct {
+	namespace, kind, name string
+
+	out bytes.Buffer
+	err error
+}
+
+// resolvedTarget is a target to undo: the workload, resolved from the rollout given if any, and the target as given
+type resolvedTarget struct {
+	info *resource.Info
+	// given is the target as given, before a rollout is resolved to its workload
+	given string
+	// rolloutName and rollout are the rollout the workload is undone through, unset with --workloads-only
+	rolloutName string
+	rollout     runtime.Object
+}
+
 // RunUndo performs the execution of 'rollout undo' sub command
 func (o *UndoOptions) RunUndo() error {
-	r := o.Builder().
-		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		NamespaceParam(o.Namespace).DefaultNamespace().
-		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
-		ResourceTypeOrNameArgs(true, o.Resources...).
-		ContinueOnError().
-		Latest().
-		Flatten().Do()
-	if err := r.Err(); err != nil {
+	// the annotations recording the command are set by the patch of the rollback itself
+	updatedAnnotations, err := o.recordedAnnotations()
+	if err != nil {
 		return err
 	}
+	run := &undoRun{
+		annotations: updatedAnnotations,
+		rollbackers: make(map[schema.GroupVersionKind]internalpolymorphichelpers.Rollbacker),
+		deDuplica:   make(map[string]struct{}),
+	}
 
-	// perform undo logic here
-	undoFunc := func(info *resource.Info, err error) error {
-		if err != nil {
+	// the output file is created before any rollback, so that a path which cannot be written fails the command first
+	if len(o.OutputFile) > 0 {
+		if run.filePrinter, err = o.ToPrinter("rolled back"); err != nil {
 			return err
 		}
-		rollbacker, err := internalpolymorphichelpers.RollbackerFn(o.RESTClientGetter, info.ResourceMapping())
-		if err != nil {
-			return err
+		if run.outputFile, err = os.Create(o.OutputFile); err != nil {
+			return fmt.Errorf("failed to create --output-file: %v", err)
 		}
+		defer run.outputFile.Close()
+	}
 
-		result, err := rollbacker.Rollback(info.Object, nil, o.ToRevision, o.DryRunStrategy)
-		if err != nil {
-			return err
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), o.Timeout)
+	defer cancel()
+	run.ctx = ctx
+
+	infos, err := o.resolveTargets(run)
+	if err != nil {
+		return err
+	}
+	err = o.undoTargets(run, infos)
+	return o.reportUndoResults(run, err)
+}
+
+// resolveTargets fetches the targets. They are all resolved before any of them is rolled back, so that a target of a
+// kind which cannot be rolled back fails the command before it has any side effect. Resources read from files are
+// skipped instead, and with --continue-on-error such a target fails on its own.
+func (o *UndoOptions) resolveTargets(run *undoRun) ([]*resource.Info, error) {
+	// the builder reads "-" from os.Stdin, so it is replaced by a stream reading from o.In
+	filenameOptions, fromStdin := o.FilenameOptions, false
+	filenameOptions.Filenames = nil
+	for _, filename := range o.Filenames {
+		if filename == "-" {
+			fromStdin = true
+			continue
 		}
+		filenameOptions.Filenames = append(filenameOptions.Filenames, filename)
+	}
 
-		printer, err := o.ToPrinter(result)
-		if err != nil {
-			return err
+	// files and kustomize directories may hold resources of any kind, the ones that cannot be rolled back are
+	// skipped before they are fetched from the server
+	run.fromFiles = !cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize)
+	b := internalcmdutil.NewResourceBuilder(o.Builder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        o.Namespace,
+		EnforceNamespace: o.EnforceNamespace,
+		AllNamespaces:    o.AllNamespaces,
+		FilenameOptions:  &filenameOptions,
+		LabelSelector:    o.LabelSelector,
+		FieldSelector:    o.FieldSelector,
+		All:              true,
+		Args:             o.Resources,
+		AsRead:           run.fromFiles,
+	})
+	if fromStdin {
+		b = b.StdinInUse().Stream(o.In, "STDIN")
+	}
+	if deadline, ok := run.ctx.Deadline(); ok {
+		// the builder has no context, so its requests are given the time left until the deadline
+		b = b.TransformRequests(func(req *rest.Request) {
+			req.Timeout(timeUntil(deadline))
+		})
+	}
+	r := b.Do()
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	infos, resolveErr := r.Infos()
+	run.resolveErr = resolveErr
+	if !run.fromFiles && !o.ContinueOnError {
+		var unsupported []string
+		for _, info := range infos {
+			if !internalpolymorphichelpers.CanRollback(info.Mapping.GroupVersionKind.GroupKind()) {
+				unsupported = append(unsupported, info.Mapping.Resource.GroupResource().String()+"/"+info.Name)
+			}
+		}
+		if len(unsupported) > 0 {
+			return nil, fmt.Errorf("unsupported kind(s): %s", strings.Join(unsupported, ", "))
+		}
+	}
+	return infos, nil
+}
+
+// resolveTarget resolves a rollout to the workload it references. It returns nil for a target which is not undone:
+// a resource read from a file which cannot be rolled back, or a workload already undone by the command.
+func (o *UndoOptions) resolveTarget(run *undoRun, info *resource.Info) (*resolvedTarget, error) {
+	if run.fromFiles {
+		if !internalpolymorphichelpers.CanRollback(info.Mapping.GroupVersionKind.GroupKind()) {
+			run.skipped = append(run.skipped, info.Mapping.Resource.GroupResource().String()+"/"+info.Name)
+			return nil, nil
 		}
+		if err := resource.RetrieveLatest(info, nil); err != nil {
+			return nil, err
+		}
+	} else if !internalpolymorphichelpers.CanRollback(info.Mapping.GroupVersionKind.GroupKind()) {
+		return nil, fmt.Errorf("unsupported kind: %s/%s", info.Mapping.Resource.GroupResource(), info.Name)
+	}
 
-		return printer.PrintObj(info.Object, o.Out)
+	target := &resolvedTarget{given: info.Mapping.GroupVersionKind.Kind + "/" + info.Name}
+	viaRollout := info.Mapping.GroupVersionKind.Group == "rollouts.kruise.io" && info.Mapping.GroupVersionKind.Kind == "Rollout"
+	if viaRollout {
+		// the referenced workload is resolved right away, so it is undone in the same pass as the other targets
+		target.rolloutName, target.rollout = info.Name, info.Object
+		var err error
+		if info, err = o.getWorkloadInfoFromRollout(info); err != nil {
+			return nil, err
+		}
+		if o.WorkloadsOnly {
+			target.rolloutName, target.rollout = "", nil
+		}
 	}
+	target.info = info
 
-	var refResources []string
 	// deduplication: If a rollout arg references a workload which is also specified as an arg in the same command,
 	// performing multiple undo operations on the workload within a single command is not smart. Such an action could
 	// lead to confusion and yield unintended consequences. Therefore, undo operations in this context are disallowed.
-	// Should such a scenario occur, only the first argument that points to the workload will be executed.
-	deDuplica := make(map[string]struct{})
+	// Should such a scenario occur, only the first argument that points to the workload will be executed, and the
+	// others are reported as skipped on ErrOut.
+	gvk := info.Mapping.GroupVersionKind
+	key := gvk.Kind + "." + gvk.Version + "." + gvk.Group + "/" + info.Name
+	// workloads of the same name in different namespaces are different targets
+	if _, ok := run.deDuplica[info.Namespace+"/"+key]; ok {
+		run.mu.Lock()
+		run.duplicates++
+		run.mu.Unlock()
+		fmt.Fprintf(o.ErrOut, i18n.T("Warning: skipped duplicate target %s: cannot undo the same workload twice in a single command\n"), key)
+		return nil, nil
+	}
+	run.deDuplica[info.Namespace+"/"+key] = struct{}{}
+	if !viaRollout {
+		// the undo is still performed, but the rollout controller may roll the workload forward again
+		for _, name := range o.managingRollouts(info) {
+			fmt.Fprintf(o.ErrOut, "Warning: %s/%s is managed by rollout %q, undoing it directly may conflict with the rollout controller, use \"kubectl-kruise rollout undo rollout/%s\" instead\n",
+				info.Mapping.Resource.GroupResource(), info.Name, name, name)
+		}
+	}
+	return target, nil
+}
 
-	err := r.Visit(func(info *resource.Info, err error) error {
+// undoTargets undoes the targets. With a parallelism above 1, the targets are undone by a bounded pool of workers
+// while the visit goes on. Every undo writes its output to a buffer of its own, which is printed once all are done.
+func (o *UndoOptions) undoTargets(run *undoRun, infos []*resource.Info) error {
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, o.Parallelism)
+	err := resource.ContinueOnErrorVisitor{Visitor: resource.InfoListVisitor(infos)}.Visit(func(info *resource.Info, err error) error {
+		if run.ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
+			return run.countFailed(err)
+		}
+		target, err := o.resolveTarget(run, info)
+		if err != nil {
+			return run.countFailed(err)
+		}
+		if target == nil {
+			return nil
+		}
+
+		var row *undoSummaryRow
+		if len(o.SummaryFormat) > 0 || o.Explain {
+			gvk := target.info.Mapping.GroupVersionKind
+			row = &undoSummaryRow{namespace: target.info.Namespace, target: target.given, kind: gvk.Kind, name: target.info.Name, status: "not started"}
+			run.summary = append(run.summary, row)
+		}
+		if o.Parallelism <= 1 {
+			return o.undoTarget(run, target, o.Out, row)
+		}
+
+		result := &undoResult{namespace: target.info.Namespace, kind: target.info.Mapping.GroupVersionKind.Kind, name: target.info.Name}
+		run.results = append(run.results, result)
+		wg.Add(1)
+		// the visit waits for a free worker, so that no more than Parallelism undos run at once
+		workers <- struct{}{}
+		go func() {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			if run.ctx.Err() != nil {
+				return
+			}
+			result.err = o.undoTarget(run, target, &result.out, row)
+		}()
+		return nil
+	})
+	wg.Wait()
+	return err
+}
+
+// undoTarget undoes a target and counts it as succeeded or failed. The error of a workload undone through a rollout
+// names the rollout.
+func (o *UndoOptions) undoTarget(run *undoRun, target *resolvedTarget, out io.Writer, row *undoSummaryRow) error {
+	err := o.undo(run, target, out, row)
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if err == nil {
+		run.succeeded++
+		return nil
+	}
+	run.failed++
+	if row != nil {
+		row.status = "failed"
+	}
+	if run.ctx.Err() != nil {
+		run.timeoutReported = true
+		err = fmt.Errorf("%s: %v", o.timeoutMessage(), err)
+	}
+	if len(target.rolloutName) > 0 {
+		info := target.info
+		return fmt.Errorf("rollout %q references %s/%s: %v", target.rolloutName, strings.ToLower(info.Mapping.GroupVersionKind.Kind), info.Name, err)
+	}
+	return err
+}
+
+// undo rolls a target back and prints the result to out, or fills row with it
+func (o *UndoOptions) undo(run *undoRun, target *resolvedTarget, out io.Writer, row *undoSummaryRow) error {
+	info, rollout := target.info, target.rollout
+	var toRevision int64
+	var rollbacker internalpolymorphichelpers.Rollbacker
+	// the pod template of a backup is restored in place of a revision, the rollbacker is left out
+	var backup *corev1.PodTemplateSpec
+	var err error
+	if len(o.FromBackup) > 0 {
+		if backup, err = o.backupTemplate(run.ctx, info); err != nil {
+			return err
+		}
+		klog.V(4).Infof("Restoring %v %s/%s from %s", info.Mapping.GroupVersionKind, info.Namespace, info.Name, o.FromBackup)
+	} else {
+		if toRevision, err = o.resolveToRevision(info, rollout); err != nil {
+			return err
+		}
+		klog.V(4).Infof("Rolling back %v %s/%s to revision %d (0 is the previous revision)", info.Mapping.GroupVersionKind, info.Namespace, info.Name, toRevision)
+		if rollbacker, err = o.rollbackerFor(run, info); err != nil {
 			return err
 		}
 
-		if info.Mapping.GroupVersionKind.Group == "rollouts.kruise.io" && info.Mapping.GroupVersionKind.Kind == "Rollout" {
-			obj := info.Object
-			if obj == nil {
-				return fmt.Errorf("Rollout object not found")
+		if row != nil {
+			if resolver, ok := rollbacker.(internalpolymorphichelpers.RollbackRevisionsResolver); ok {
+				if row.from, row.to, err = resolver.RollbackRevisions(info.Object, toRevision); err != nil {
+					return err
+				}
 			}
-			workloadRef, err := getWorkloadRefFromRollout(obj)
-			if err != nil {
-				return err
+		}
+	}
+	// the plan is made of reads only, the rollback itself is left out
+	if o.Explain {
+		row.status = "roll back"
+		if row.from != 0 && row.from == row.to {
+			row.status = "skip, already at revision"
+		}
+		return nil
+	}
+
+	// a client side dry-run shows the changes of the pod template unless a structured output or a summary is requested
+	if o.DryRunStrategy == cmdutil.DryRunClient && !o.outputFormatSpecified() && row == nil && backup == nil {
+		if previewer, ok := rollbacker.(internalpolymorphichelpers.RollbackPreviewer); ok {
+			return o.printRollbackDiff(info, previewer, toRevision, out)
+		}
+	}
+
+	if o.DryRunStrategy == cmdutil.DryRunNone && o.needsConfirmation(info.Namespace) {
+		run.confirmMu.Lock()
+		confirmed, err := o.confirm(info)
+		if err == nil && !confirmed {
+			fmt.Fprintf(o.ErrOut, "Skipped rollback of %s/%s: the name was not confirmed\n", info.Mapping.Resource.GroupResource(), info.Name)
+			if row != nil {
+				row.status = "not confirmed"
 			}
-			gv, err := schema.ParseGroupVersion(workloadRef.APIVersion)
-			if err != nil {
-				return err
+			run.unconfirmed++
+		}
+		run.confirmMu.Unlock()
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+
+	var result string
+	if backup != nil {
+		result, err = o.restoreBackup(info, backup, run.annotations)
+	} else {
+		result, err = rollbacker.Rollback(info.Object, run.annotations, toRevision, o.DryRunStrategy)
+	}
+	if err != nil {
+		return err
+	}
+	// the workload of a rollout is paused by the rollout controller during a release, it is left to the rollout
+	resumed := false
+	if rollout == nil && !strings.HasPrefix(result, "skipped") {
+		if resumed, err = o.resumeIfPaused(info); err != nil {
+			return err
+		}
+		if resumed {
+			result += " and resumed"
+		}
+	}
+	if o.UpdateLastApplied && o.DryRunStrategy == cmdutil.DryRunNone && !strings.HasPrefix(result, "skipped") {
+		if err := o.updateLastApplied(info); err != nil {
+			return err
+		}
+	}
+	if o.Wait && o.DryRunStrategy == cmdutil.DryRunNone && !strings.HasPrefix(result, "skipped") {
+		if err := o.waitForRollout(run.ctx, info); err != nil {
+			return err
+		}
+	}
+	// the rollback is applied on the server, so fetch the rolled back object for structured output
+	writeFile := run.outputFile != nil && !strings.HasPrefix(result, "skipped")
+	if o.DryRunStrategy == cmdutil.DryRunNone && ((row == nil && o.outputFormatSpecified()) || writeFile) {
+		obj, err := resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+		if err != nil {
+			return err
+		}
+		if err = info.Refresh(obj, true); err != nil {
+			return err
+		}
+	}
+	if writeFile {
+		o.writeOutputFile(run, info.Object)
+	}
+
+	if row != nil {
+		row.status = "rolled back"
+		if strings.HasPrefix(result, "skipped") {
+			row.status = "skipped"
+		} else if resumed {
+			row.status = "rolled back and resumed"
+		}
+		if o.DryRunStrategy != cmdutil.DryRunNone {
+			row.status += " (dry run)"
+		}
+		return nil
+	}
+
+	// the objects are still printed when a structured output is requested
+	if o.Quiet && !o.outputFormatSpecified() {
+		return nil
+	}
+	return o.printObj(result, info.Object, out)
+}
+
+// rollbackerFor returns the rollbacker of the kind of info, built on first use. The options the rollbackers are given
+// are the same for every target, so they are set once when the rollbacker is built.
+func (o *UndoOptions) rollbackerFor(run *undoRun, info *resource.Info) (internalpolymorphichelpers.Rollbacker, error) {
+	run.rollbackersMu.Lock()
+	defer run.rollbackersMu.Unlock()
+	gvk := info.Mapping.GroupVersionKind
+	if rollbacker, ok := run.rollbackers[gvk]; ok {
+		return rollbacker, nil
+	}
+	rollbacker, err := o.Rollbacker(o.RESTClientGetter, info.ResourceMapping())
+	if err != nil {
+		return nil, err
+	}
+	if setter, ok := rollbacker.(internalpolymorphichelpers.FieldManagerSetter); ok {
+		setter.SetFieldManager(o.FieldManager)
+	}
+	if setter, ok := rollbacker.(internalpolymorphichelpers.ContextSetter); ok {
+		setter.SetContext(run.ctx)
+	}
+	if setter, ok := rollbacker.(internalpolymorphichelpers.TemplateOnlySetter); ok {
+		setter.SetTemplateOnly(o.PreviousConfigOnly)
+	}
+	if setter, ok := rollbacker.(internalpolymorphichelpers.StrategyRestoreSetter); ok {
+		setter.SetRestoreStrategy(o.Full)
+	}
+	run.rollbackers[gvk] = rollbacker
+	return rollbacker, nil
+}
+
+// writeOutputFile writes a rolled back object to the output file, unless writing an earlier one failed
+func (o *UndoOptions) writeOutputFile(run *undoRun, obj runtime.Object) {
+	o.printMu.Lock()
+	defer o.printMu.Unlock()
+	if run.fileErr == nil {
+		run.fileErr = run.filePrinter.PrintObj(obj, run.outputFile)
+	}
+}
+
+// reportUndoResults prints the output of the parallel undos sorted by the namespace, kind and name of the workloads,
+// so that it does not depend on the order the undos finish in, then the summary and the counts, and returns the errors
+// of all targets given err, the error of the visit.
+func (o *UndoOptions) reportUndoResults(run *undoRun, err error) error {
+	if run.resolveErr != nil {
+		// every target which could not be fetched is an error of its own
+		run.failed += len(utilerrors.Flatten(utilerrors.NewAggregate([]error{run.resolveErr})).Errors())
+		err = utilerrors.Flatten(utilerrors.NewAggregate([]error{run.resolveErr, err}))
+	}
+	if len(run.skipped) > 0 {
+		fmt.Fprintf(o.ErrOut, "Warning: skipped resources that cannot be rolled back: %s\n", strings.Join(run.skipped, ", "))
+		if err == nil && len(run.deDuplica) == 0 {
+			return fmt.Errorf("none of the given resources can be rolled back")
+		}
+	}
+	if len(run.results) > 0 {
+		results := run.results
+		sort.Slice(results, func(i, j int) bool {
+			if results[i].namespace != results[j].namespace {
+				return results[i].namespace < results[j].namespace
 			}
-			deDuplicaKey := workloadRef.Kind + "." + gv.Version + "." + gv.Group + "/" + workloadRef.Name
-			if _, ok := deDuplica[deDuplicaKey]; ok {
-				return nil
+			if results[i].kind != results[j].kind {
+				return results[i].kind < results[j].kind
 			}
-			deDuplica[deDuplicaKey] = struct{}{}
-			refResources = append(refResources, deDuplicaKey)
-			return nil
+			return results[i].name < results[j].name
+		})
+		errs := []error{err}
+		for _, result := range results {
+			if _, writeErr := o.Out.Write(result.out.Bytes()); writeErr != nil {
+				errs = append(errs, writeErr)
+			}
+			errs = append(errs, result.err)
+		}
+		err = utilerrors.Flatten(utilerrors.NewAggregate(errs))
+	}
+	if len(run.summary) > 0 {
+		printSummary := printUndoSummary
+		if o.Explain {
+			printSummary = printUndoPlan
+		}
+		if printErr := printSummary(o.Out, run.summary); printErr != nil {
+			err = utilerrors.Flatten(utilerrors.NewAggregate([]error{err, printErr}))
+		}
+	}
+	if !o.Explain && !o.NoCounts && run.succeeded+run.duplicates+run.failed > 1 {
+		o.printUndoCounts(run.succeeded-run.unconfirmed, run.duplicates, run.unconfirmed, run.failed)
+	}
+	if run.ctx.Err() != nil {
+		// the requests for the remaining targets fail at the deadline too, they are covered by the timeout error
+		err = utilerrors.FilterOut(err, func(err error) bool { return errors.Is(err, context.DeadlineExceeded) })
+		if !run.timeoutReported {
+			err = utilerrors.Flatten(utilerrors.NewAggregate([]error{err, errors.New(o.timeoutMessage())}))
+		}
+	}
+	if run.outputFile != nil {
+		if closeErr := run.outputFile.Close(); run.fileErr == nil {
+			run.fileErr = closeErr
+		}
+		// the rollbacks are done, only their record is incomplete, which is reported apart from the failed rollbacks
+		if run.fileErr != nil {
+			err = utilerrors.Flatten(utilerrors.NewAggregate([]error{err, fmt.Errorf("the rolled back objects could not all be written to %s: %v", o.OutputFile, run.fileErr)}))
+		}
+	}
+	if err != nil && run.succeeded > 0 {
+		return partialFailureError(err)
+	}
+	return err
+}
+
+// printUndoCounts prints how many targets were undone, skipped and failed on ErrOut. The targets not undone because
+// their name was not confirmed are only counted if there are some.
+func (o *UndoOptions) printUndoCounts(undone, duplicates, unconfirmed, failed int) {
+	counts := fmt.Sprintf("undid %d, skipped %d (duplicates)", undone, duplicates)
+	if unconfirmed > 0 {
+		counts += fmt.Sprintf(", skipped %d (not confirmed)", unconfirmed)
+	}
+	counts += fmt.Sprintf(", failed %d", failed)
+	if o.DryRunStrategy != cmdutil.DryRunNone {
+		counts += " (dry run)"
+	}
+	fmt.Fprintln(o.ErrOut, counts)
+}
+
+// appendTargetsFromFile appends the TYPE/NAME targets listed in filename, one per line, to targets. Blank lines,
+// lines starting with # and targets already in targets are skipped.
+func appendTargetsFromFile(targets []string, filename string) ([]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	seen := sets.NewString(targets...)
+	for i, line := range strings.Split(string(data), "\n") {
+		target := strings.TrimSpace(line)
+		if len(target) == 0 || strings.HasPrefix(target, "#") {
+			continue
+		}
+		if parts := strings.Split(target, "/"); len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return nil, fmt.Errorf("invalid target %q on line %d of %s: must be of the form TYPE/NAME", target, i+1, filename)
+		}
+		if seen.Has(target) {
+			continue
+		}
+		seen.Insert(target)
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// recordedAnnotations returns the annotations the recorder sets to record the command, if any.
+func (o *UndoOptions) recordedAnnotations() (map[string]string, error) {
+	if o.Recorder == nil {
+		return nil, nil
+	}
+	obj := &metav1.PartialObjectMetadata{}
+	if err := o.Recorder.Record(obj); err != nil {
+		return nil, err
+	}
+	return obj.Annotations, nil
+}
+
+func (o *UndoOptions) timeoutMessage() string {
+	return fmt.Sprintf("timed out after %v, the remaining targets were not rolled back", o.Timeout)
+}
+
+// timeUntil returns the time left until deadline, at least a nanosecond, since a request timeout of zero means no timeout.
+func timeUntil(deadline time.Time) time.Duration {
+	if d := time.Until(deadline); d > 0 {
+		return d
+	}
+	return time.Nanosecond
+}
+
+// getWorkloadInfoFromRollout fetches the workload referenced by the rollout of info. A rollout can only reference
+// a workload in its own namespace.
+func (o *UndoOptions) getWorkloadInfoFromRollout(info *resource.Info) (*resource.Info, error) {
+	if info.Object == nil {
+		return nil, fmt.Errorf("Rollout object not found")
+	}
+	gvk, name, err := internalpolymorphichelpers.ResolveWorkloadRef(info.Object)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := o.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	client, err := o.ClientForMapping(mapping)
+	if err != nil {
+		return nil, err
+	}
+	klog.V(4).Infof("Rollout %s/%s references %v %s", info.Namespace, info.Name, gvk, name)
+	obj, err := resource.NewHelper(client, mapping).Get(info.Namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return &resource.Info{
+		Client:    client,
+		Mapping:   mapping,
+		Namespace: info.Namespace,
+		Name:      name,
+		Object:    obj,
+	}, nil
+}
+
+// managingRollouts returns the names of the rollouts referencing the workload of info, which are looked up to warn
+// that the rollout controller may undo its rollback. The lookup is best effort: clusters without the rollout CRDs
+// have no rollouts to warn about, so a failed list only shows up in the logs.
+func (o *UndoOptions) managingRollouts(info *resource.Info) []string {
+	rollouts, ok := o.rolloutsByNamespace[info.Namespace]
+	if !ok {
+		var err error
+		if rollouts, err = o.listRollouts(info.Namespace); err != nil {
+			klog.V(4).Infof("Not checking the rollouts in namespace %s, they cannot be listed: %v", info.Namespace, err)
+		}
+		if o.rolloutsByNamespace == nil {
+			o.rolloutsByNamespace = map[string][]runtime.Object{}
+		}
+		o.rolloutsByNamespace[info.Namespace] = rollouts
+	}
+
+	var names []string
+	for _, rollout := range rollouts {
+		gvk, name, err := internalpolymorphichelpers.ResolveWorkloadRef(rollout)
+		if err != nil || gvk.GroupKind() != info.Mapping.GroupVersionKind.GroupKind() || name != info.Name {
+			continue
 		}
-		gvk := info.Mapping.GroupVersionKind
-		deDuplicaKey := gvk.Kind + "." + gvk.Version + "." + gvk.Group + "/" + info.Name
-		if _, ok := deDuplica[deDuplicaKey]; ok {
+		if accessor, err := meta.Accessor(rollout); err == nil {
+			names = append(names, accessor.GetName())
+		}
+	}
+	return names
+}
+
+// listRollouts lists the rollouts in namespace. It fails if the rollouts cannot be listed, e.g. since the rollout CRDs
+// are not installed in the cluster.
+func (o *UndoOptions) listRollouts(namespace string) ([]runtime.Object, error) {
+	mapping, err := o.RESTMapper.RESTMapping(schema.GroupKind{Group: "rollouts.kruise.io", Kind: "Rollout"})
+	if err != nil {
+		return nil, err
+	}
+	client, err := o.ClientForMapping(mapping)
+	if err != nil {
+		return nil, err
+	}
+	list, err := resource.NewHelper(client, mapping).List(namespace, mapping.GroupVersionKind.GroupVersion().String(), &metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return meta.ExtractList(list)
+}
+
+// resolveToRevision returns the revision number the workload of info is rolled back to. The latest stable
+// revision is looked up in the status of rollout if the workload was given through a rollout, otherwise in
+// the status of the workload. With --to-image, it is the most recent revision of the workload running the image.
+func (o *UndoOptions) resolveToRevision(info *resource.Info, rollout runtime.Object) (int64, error) {
+	if len(o.ToImage) > 0 {
+		container, image, err := o.toImage()
+		if err != nil {
+			return 0, err
+		}
+		return o.ImageRevision(o.RESTClientGetter, info.Object, container, image)
+	}
+	if !o.ToLatestStable {
+		return o.ToRevision, nil
+	}
+	var stableRevision string
+	if rollout != nil {
+		if stableRevision = rolloutStableRevision(rollout); len(stableRevision) == 0 {
+			return 0, fmt.Errorf("no stable revision is recorded in the status of the rollout yet")
+		}
+	}
+	revision, err := o.StableRevision(o.RESTClientGetter, info.Object, stableRevision)
+	if err != nil {
+		return 0, fmt.Errorf("unable to resolve the latest stable revision: %v", err)
+	}
+	return revision, nil
+}
+
+// toImage returns the container and the image of --to-image.
+func (o *UndoOptions) toImage() (string, string, error) {
+	container, image, found := strings.Cut(o.ToImage, "=")
+	if !found || len(container) == 0 || len(image) == 0 {
+		return "", "", fmt.Errorf("--to-image must be of the form CONTAINER=IMAGE, e.g. nginx=nginx:1.24, got %q", o.ToImage)
+	}
+	return container, image, nil
+}
+
+// backupName returns the name of the ConfigMap of --from-backup.
+func (o *UndoOptions) backupName() (string, error) {
+	kind, name, found := strings.Cut(o.FromBackup, "/")
+	switch strings.ToLower(kind) {
+	case "configmap", "configmaps", "cm":
+		if found && len(name) > 0 && !strings.Contains(name, "/") {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("--from-backup must be a ConfigMap given as configmap/NAME, e.g. cm/web-snapshot, got %q", o.FromBackup)
+}
+
+// backupTemplate returns the pod template of the manifest of the workload of info stored in the ConfigMap of
+// --from-backup, which is read from the namespace of the workload. The manifest is the one of the key named after the
+// workload, or of the only key of the ConfigMap, and must be of the kind of the workload.
+func (o *UndoOptions) backupTemplate(ctx context.Context, info *resource.Info) (*corev1.PodTemplateSpec, error) {
+	name, err := o.backupName()
+	if err != nil {
+		return nil, err
+	}
+	cm, err := o.ClientSet.CoreV1().ConfigMaps(info.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the backup of %s/%s: %v", info.Mapping.Resource.GroupResource(), info.Name, err)
+	}
+	key := info.Name
+	if _, ok := cm.Data[key]; !ok {
+		if len(cm.Data) != 1 {
+			return nil, fmt.Errorf("configmap %s has no key %s holding the backup of %s/%s", name, key, info.Mapping.Resource.GroupResource(), info.Name)
+		}
+		for k := range cm.Data {
+			key = k
+		}
+	}
+	data := cm.Data[key]
+
+	manifest, err := yaml.YAMLToJSON([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("the key %s of configmap %s is not a manifest: %v", key, name, err)
+	}
+	stored := &unstructured.Unstructured{}
+	if err := stored.UnmarshalJSON(manifest); err != nil {
+		return nil, fmt.Errorf("the key %s of configmap %s is not a manifest: %v", key, name, err)
+	}
+	storedKind := stored.GroupVersionKind().GroupKind()
+	if storedKind != info.Mapping.GroupVersionKind.GroupKind() {
+		return nil, fmt.Errorf("the key %s of configmap %s holds a %s, which cannot be restored to %s/%s", key, name, storedKind, info.Mapping.Resource.GroupResource(), info.Name)
+	}
+	content, found, err := unstructured.NestedMap(stored.Object, "spec", "template")
+	if err != nil || !found {
+		return nil, fmt.Errorf("the %s of the key %s of configmap %s has no pod template", storedKind, key, name)
+	}
+	template := &corev1.PodTemplateSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, template); err != nil {
+		return nil, fmt.Errorf("the pod template of the key %s of configmap %s is invalid: %v", key, name, err)
+	}
+	return template, nil
+}
+
+// restoreBackup replaces the pod template of the workload of info by template, and sets the given annotations. The
+// other fields of the workload, e.g. the replicas, are kept.
+func (o *UndoOptions) restoreBackup(info *resource.Info, template *corev1.PodTemplateSpec, annotations map[string]string) (string, error) {
+	restored, err := runtime.DefaultUnstructuredConverter.ToUnstructured(template)
+	if err != nil {
+		return "", err
+	}
+	operation, err := internalcmdutil.PatchWorkload(info, func(obj runtime.Object) error {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedMap(content, restored, "spec", "template"); err != nil {
+			return err
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj); err != nil {
+			return err
+		}
+		if len(annotations) == 0 {
 			return nil
 		}
-		deDuplica[deDuplicaKey] = struct{}{}
-		return undoFunc(info, nil)
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		updated := accessor.GetAnnotations()
+		if updated == nil {
+			updated = map[string]string{}
+		}
+		for k, v := range annotations {
+			updated[k] = v
+		}
+		accessor.SetAnnotations(updated)
+		return nil
+	}, o.DryRunStrategy)
+	if err != nil {
+		return "", err
+	}
+	if operation == internalcmdutil.PatchOperationUnchanged {
+		return fmt.Sprintf("skipped rollback (current template already matches %s)", o.FromBackup), nil
+	}
+	return "rolled back", nil
+}
+
+// needsConfirmation returns whether a rollback in namespace has to be confirmed. Confirmations are
+// only asked for on a terminal, so that scripts piping to stdin are not blocked.
+func (o *UndoOptions) needsConfirmation(namespace string) bool {
+	if o.Yes || !o.IsTerminal(o.In) {
+		return false
+	}
+	if o.Confirm {
+		return true
+	}
+	for _, pattern := range o.ConfirmNamespaces {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// confirm asks on ErrOut to type the name of the workload of info, and reads the answer from In.
+func (o *UndoOptions) confirm(info *resource.Info) (bool, error) {
+	if o.confirmReader == nil {
+		o.confirmReader = bufio.NewReader(o.In)
+	}
+	fmt.Fprintf(o.ErrOut, "You are about to roll back %s/%s in namespace %s.\nType the name of the workload to confirm: ",
+		info.Mapping.Resource.GroupResource(), info.Name, info.Namespace)
+	answer, err := o.confirmReader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return strings.TrimSpace(answer) == info.Name, nil
+}
+
+func (o *UndoOptions) outputFormatSpecified() bool {
+	return o.PrintFlags.OutputFormat != nil && len(*o.PrintFlags.OutputFormat) > 0
+}
+
+// waitPollInterval is the time between two polls of the status of a workload with --wait
+var waitPollInterval = time.Second
+
+// waitForRollout polls the status of the workload of info until its rollout is complete, or ctx is done, in which
+// case the last status observed is returned in the error.
+func (o *UndoOptions) waitForRollout(ctx context.Context, info *resource.Info) error {
+	statusViewer, err := o.StatusViewer(info.Mapping)
+	if err != nil {
+		return err
+	}
+	helper := resource.NewHelper(info.Client, info.Mapping)
+	var status string
+	err = wait.PollUntilContextCancel(ctx, waitPollInterval, true, func(context.Context) (bool, error) {
+		obj, err := helper.Get(info.Namespace, info.Name)
+		if errors.Is(err, context.DeadlineExceeded) {
+			// the request may be cut at the deadline just before ctx is done, the poll ends with ctx then
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return false, err
+		}
+		var done bool
+		status, done, err = statusViewer.Status(o.ClientSet, &unstructured.Unstructured{Object: content}, 0)
+		return done, err
 	})
+	if err == nil {
+		return nil
+	}
+	if wait.Interrupted(err) {
+		return fmt.Errorf("%s/%s was rolled back but did not complete its rollout, last status: %s", info.Mapping.Resource.GroupResource(), info.Name, strings.TrimSpace(status))
+	}
+	return fmt.Errorf("%s/%s was rolled back but its rollout could not be waited for: %v", info.Mapping.Resource.GroupResource(), info.Name, err)
+}
+
+// resumeIfPaused resumes the rolled back workload of info if it is paused and --resume is given, otherwise it warns
+// that the rollback does not take effect until the workload is resumed. It returns whether the workload was resumed.
+func (o *UndoOptions) resumeIfPaused(info *resource.Info) (bool, error) {
+	// the resumer unpauses the object it is given, while info.Object is still printed as it was rolled back
+	resumed := *info
+	resumed.Object = info.Object.DeepCopyObject()
+	patch := &set.Patch{Info: &resumed}
+	set.CalculatePatch(patch, scheme.DefaultJSONEncoder(), set.PatchFn(o.Resumer))
+	// workloads which cannot be paused, e.g. DaemonSets, cannot be resumed either
+	if patch.Err != nil || len(patch.Patch) == 0 || string(patch.Patch) == "{}" {
+		return false, nil
+	}
 
-	if len(refResources) < 1 {
+	target := fmt.Sprintf("%s/%s", info.Mapping.Resource.GroupResource(), info.Name)
+	if !o.Resume {
+		o.printMu.Lock()
+		defer o.printMu.Unlock()
+		fmt.Fprintf(o.ErrOut, "Warning: %s is paused, the rollback takes effect once it is resumed, e.g. with --resume or \"kubectl-kruise rollout resume %s\"\n", target, target)
+		return false, nil
+	}
+	if o.DryRunStrategy == cmdutil.DryRunClient {
+		return true, nil
+	}
+	_, err := resource.NewHelper(info.Client, info.Mapping).
+		DryRun(o.DryRunStrategy == cmdutil.DryRunServer).
+		WithFieldManager(o.FieldManager).
+		Patch(info.Namespace, info.Name, types.MergePatchType, patch.Patch, nil)
+	if err != nil {
+		return false, fmt.Errorf("%s was rolled back but could not be resumed: %v", target, err)
+	}
+	return true, nil
+}
+
+// updateLastApplied replaces the pod template of the last-applied-configuration annotation of the workload of info by
+// the one it was rolled back to. The rest of the applied configuration, e.g. the replicas, is kept as it was applied.
+func (o *UndoOptions) updateLastApplied(info *resource.Info) error {
+	target := fmt.Sprintf("%s/%s", info.Mapping.Resource.GroupResource(), info.Name)
+	helper := resource.NewHelper(info.Client, info.Mapping)
+	// info.Object is the workload before the rollback, the restored pod template is read back from the server
+	obj, err := helper.Get(info.Namespace, info.Name)
+	if err != nil {
+		return fmt.Errorf("%s was rolled back but its last-applied configuration could not be updated: %v", target, err)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
 		return err
 	}
+	lastApplied, ok := accessor.GetAnnotations()[corev1.LastAppliedConfigAnnotation]
+	if !ok {
+		klog.V(4).Infof("%s has no %s annotation, left as it is", target, corev1.LastAppliedConfigAnnotation)
+		return nil
+	}
 
-	var aggErrs []error
-	aggErrs = append(aggErrs, err)
-	r2 := o.Builder().
-		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		NamespaceParam(o.Namespace).DefaultNamespace().
-		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
-		ResourceTypeOrNameArgs(true, refResources...).
-		ContinueOnError().
-		Latest().
-		Flatten().Do()
-
-	if err = r2.Err(); err != nil {
-		aggErrs = append(aggErrs, err)
-		return errors.NewAggregate(aggErrs)
-	}
-	err = r2.Visit(undoFunc)
-	aggErrs = append(aggErrs, err)
-	return errors.NewAggregate(aggErrs)
-}
-
-func getWorkloadRefFromRollout(obj interface{}) (workloadRef *rolloutsapiv1beta1.ObjectRef, err error) {
-	switch rollout := obj.(type) {
-	case *rolloutsapiv1alpha1.Rollout:
-		workloadRef = &rolloutsapiv1beta1.ObjectRef{
-			Kind:       rollout.Spec.ObjectRef.WorkloadRef.Kind,
-			APIVersion: rollout.Spec.ObjectRef.WorkloadRef.APIVersion,
-			Name:       rollout.Spec.ObjectRef.WorkloadRef.Name,
-		}
-	case *rolloutsapiv1beta1.Rollout:
-		workloadRef = &rollout.Spec.WorkloadRef
+	applied := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lastApplied), &applied); err != nil {
+		return fmt.Errorf("%s was rolled back but its last-applied configuration could not be read: %v", target, err)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	template, found, err := unstructured.NestedFieldCopy(content, "spec", "template")
+	if err != nil || !found {
+		return fmt.Errorf("%s was rolled back but has no pod template to update its last-applied configuration with", target)
+	}
+	if err := unstructured.SetNestedField(applied, template, "spec", "template"); err != nil {
+		return err
+	}
+	updated, err := json.Marshal(applied)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{corev1.LastAppliedConfigAnnotation: string(updated)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := helper.WithFieldManager(o.FieldManager).Patch(info.Namespace, info.Name, types.MergePatchType, patch, nil); err != nil {
+		return fmt.Errorf("%s was rolled back but its last-applied configuration could not be updated: %v", target, err)
+	}
+	return nil
+}
+
+// printObj prints obj to out with the printer of operation. The printers share the print flags and the type setter
+// of the command, so parallel undos print one at a time.
+func (o *UndoOptions) printObj(operation string, obj runtime.Object, out io.Writer) error {
+	o.printMu.Lock()
+	defer o.printMu.Unlock()
+	printer, err := o.ToPrinter(operation)
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(obj, out)
+}
+
+// printRollbackDiff writes a unified diff between the live pod template of info and the one it would be rolled back to.
+func (o *UndoOptions) printRollbackDiff(info *resource.Info, previewer internalpolymorphichelpers.RollbackPreviewer, toRevision int64, out io.Writer) error {
+	live, target, err := previewer.PreviewRollback(info.Object, toRevision)
+	if err != nil {
+		return err
+	}
+	liveYAML, err := yaml.Marshal(live)
+	if err != nil {
+		return err
+	}
+	targetYAML, err := yaml.Marshal(target)
+	if err != nil {
+		return err
+	}
+
+	name := info.Mapping.Resource.GroupResource().String() + "/" + info.Name
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimSuffix(string(liveYAML), "\n")),
+		B:        difflib.SplitLines(strings.TrimSuffix(string(targetYAML), "\n")),
+		FromFile: "live/" + name,
+		ToFile:   "rolled-back/" + name,
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	if len(diff) == 0 {
+		return o.printObj(fmt.Sprintf("skipped rollback (current template already matches revision %d)", toRevision), info.Object, out)
+	}
+	_, err = fmt.Fprint(out, diff)
+	return err
+}
+
+// summaryFormatTable is the --output-format printing the summary of the rollbacks as a table.
+const summaryFormatTable = "table"
+
+// undoSummaryRow is the row of a target in the summary of the rollbacks. The revisions are 0 if the rollbacker of the
+// target cannot tell them.
+type undoSummaryRow struct {
+	namespace string
+	// target is the target as given, e.g. the rollout whose workload is rolled back, as KIND/NAME
+	target string
+	kind   string
+	name   string
+	from   int64
+	to     int64
+	status string
+}
+
+// printUndoSummary writes the rows of the summary of the rollbacks as a table.
+func printUndoSummary(out io.Writer, rows []*undoSummaryRow) error {
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintf(w, "NAMESPACE\tKIND\tNAME\tFROM-REVISION\tTO-REVISION\tSTATUS\n")
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", row.namespace, row.kind, row.name, summaryRevision(row.from), summaryRevision(row.to), row.status)
+	}
+	return w.Flush()
+}
+
+// printUndoPlan writes the rows of the plan printed with --explain as a table, with the workload every target
+// resolves to.
+func printUndoPlan(out io.Writer, rows []*undoSummaryRow) error {
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintf(w, "NAMESPACE\tTARGET\tWORKLOAD\tFROM-REVISION\tTO-REVISION\tPLAN\n")
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\t%s\t%s\n", row.namespace, row.target, row.kind, row.name, summaryRevision(row.from), summaryRevision(row.to), row.status)
+	}
+	return w.Flush()
+}
+
+func summaryRevision(revision int64) string {
+	if revision == 0 {
+		return "<unknown>"
+	}
+	return strconv.FormatInt(revision, 10)
+}
+
+const (
+	revisionPrevious     = "previous"
+	revisionLatestStable = "latest-stable"
+)
+
+// toRevisionValue is the value of --to-revision, which takes a revision number or one of the symbolic
+// revisions "previous" and "latest-stable".
+type toRevisionValue struct {
+	o *UndoOptions
+}
+
+func (v *toRevisionValue) String() string {
+	if v.o.ToLatestStable {
+		return revisionLatestStable
+	}
+	return strconv.FormatInt(v.o.ToRevision, 10)
+}
+
+func (v *toRevisionValue) Set(value string) error {
+	v.o.toRevisionSet = true
+	switch value {
+	case revisionPrevious:
+		v.o.ToRevision, v.o.ToLatestStable = 0, false
+	case revisionLatestStable:
+		v.o.ToRevision, v.o.ToLatestStable = 0, true
 	default:
-		return nil, fmt.Errorf("unsupported version of Rollout")
+		revision, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be a revision number, %q or %q", revisionPrevious, revisionLatestStable)
+		}
+		v.o.ToRevision, v.o.ToLatestStable = revision, false
 	}
-	return workloadRef, nil
+	return nil
+}
+
+func (v *toRevisionValue) Type() string {
+	return "string"
 }
@@ -0,0 +1,2563 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	rolloutsapiv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+
+	internalpolymorphichelpers "github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
+)
+
+type fakeRollbacker struct {
+	calls      []string
+	namespaces []string
+	errs       map[string]error
+	// revisions, if set, lists the revisions found in the history of every workload
+	revisions    []int64
+	toRevisions  []int64
+	fieldManager string
+	annotations  []map[string]string
+	templateOnly bool
+}
+
+func (r *fakeRollbacker) SetFieldManager(fieldManager string) {
+	r.fieldManager = fieldManager
+}
+
+func (r *fakeRollbacker) SetTemplateOnly(templateOnly bool) {
+	r.templateOnly = templateOnly
+}
+
+func (r *fakeRollbacker) Rollback(obj runtime.Object, updatedAnnotations map[string]string, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	r.calls = append(r.calls, accessor.GetName())
+	r.namespaces = append(r.namespaces, accessor.GetNamespace())
+	r.toRevisions = append(r.toRevisions, toRevision)
+	r.annotations = append(r.annotations, updatedAnnotations)
+	if err := r.errs[accessor.GetName()]; err != nil {
+		return "", err
+	}
+	if r.revisions != nil && toRevision > 0 {
+		found := false
+		for _, revision := range r.revisions {
+			found = found || revision == toRevision
+		}
+		if !found {
+			return "", fmt.Errorf("unable to find specified revision %d in history", toRevision)
+		}
+	}
+	return "rolled back", nil
+}
+
+// fakePreviewRollbacker additionally previews a rollback from the live template to the target template.
+type fakePreviewRollbacker struct {
+	fakeRollbacker
+	live, target *corev1.PodTemplateSpec
+}
+
+func (r *fakePreviewRollbacker) PreviewRollback(obj runtime.Object, toRevision int64) (*corev1.PodTemplateSpec, *corev1.PodTemplateSpec, error) {
+	return r.live, r.target, nil
+}
+
+// revisionsRollbacker additionally tells the revisions of a rollback, every workload runs the revision in current
+// and is rolled back to the revision before it unless another one is requested.
+type revisionsRollbacker struct {
+	fakeRollbacker
+	current map[string]int64
+}
+
+func (r *revisionsRollbacker) RollbackRevisions(obj runtime.Object, toRevision int64) (int64, int64, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return 0, 0, err
+	}
+	from := r.current[accessor.GetName()]
+	if toRevision == 0 {
+		toRevision = from - 1
+	}
+	return from, toRevision, nil
+}
+
+// slowRollbacker rolls back the workloads named in slow only when its context is done, like a stuck API server.
+type slowRollbacker struct {
+	fakeRollbacker
+	ctx  context.Context
+	slow map[string]bool
+}
+
+func (r *slowRollbacker) SetContext(ctx context.Context) {
+	r.ctx = ctx
+}
+
+func (r *slowRollbacker) Rollback(obj runtime.Object, updatedAnnotations map[string]string, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	if r.slow[accessor.GetName()] {
+		<-r.ctx.Done()
+		return "", r.ctx.Err()
+	}
+	return r.fakeRollbacker.Rollback(obj, updatedAnnotations, toRevision, dryRunStrategy)
+}
+
+// concurrentRollbacker can be shared by parallel undos, it delays the rollback of the workloads named in delays
+// and records how many rollbacks ran at the same time.
+type concurrentRollbacker struct {
+	mu             sync.Mutex
+	delays         map[string]time.Duration
+	errs           map[string]error
+	calls          []string
+	running        int
+	maxConcurrency int
+}
+
+func (r *concurrentRollbacker) Rollback(obj runtime.Object, updatedAnnotations map[string]string, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	r.calls = append(r.calls, accessor.GetName())
+	r.running++
+	if r.running > r.maxConcurrency {
+		r.maxConcurrency = r.running
+	}
+	r.mu.Unlock()
+
+	time.Sleep(r.delays[accessor.GetName()])
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.running--
+	if err := r.errs[accessor.GetName()]; err != nil {
+		return "", err
+	}
+	return "rolled back", nil
+}
+
+func newUndoTestTemplate(image string) *corev1.PodTemplateSpec {
+	return &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "foo"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main", Image: image}},
+		},
+	}
+}
+
+func newUndoTestCloneSet(name string) *kruiseappsv1alpha1.CloneSet {
+	return &kruiseappsv1alpha1.CloneSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+	}
+}
+
+func newUndoTestRollout(name, workload string) *rolloutsapiv1beta1.Rollout {
+	return &rolloutsapiv1beta1.Rollout{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rolloutsapiv1beta1.GroupVersion.String(), Kind: "Rollout"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+		Spec: rolloutsapiv1beta1.RolloutSpec{
+			WorkloadRef: rolloutsapiv1beta1.ObjectRef{
+				APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(),
+				Kind:       "CloneSet",
+				Name:       workload,
+			},
+		},
+	}
+}
+
+// newUndoTestFactory returns a factory whose fake server serves the given objects by "resource/name" path,
+// lists are served by "resource?query". Paths starting with a "/" are served as is rather than in the test namespace.
+func newUndoTestFactory(t testing.TB, objs map[string]runtime.Object) *cmdtesting.TestFactory {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Group: "apps.kruise.io", Version: "v1alpha1"},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet {
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+				return nil, nil
+			}
+			// the timeout of a request is not part of what it requests
+			query := req.URL.Query()
+			query.Del("timeout")
+			requested := req.URL.Path
+			if len(query) > 0 {
+				requested += "?" + query.Encode()
+			}
+			for path, obj := range objs {
+				if requested == "/namespaces/test/"+path || requested == path {
+					codec := scheme.Codecs.LegacyCodec(obj.GetObjectKind().GroupVersionKind().GroupVersion())
+					return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+				}
+			}
+			return &http.Response{StatusCode: http.StatusNotFound, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.StringBody("")}, nil
+		}),
+	}
+	return tf
+}
+
+func newUndoTestOptions(tf *cmdtesting.TestFactory, rollbacker internalpolymorphichelpers.Rollbacker, args ...string) (*UndoOptions, error) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdRolloutUndo(tf, streams)
+	o := NewRolloutUndoOptions(streams)
+	if err := o.Complete(tf, cmd, args); err != nil {
+		return nil, err
+	}
+	o.Rollbacker = func(genericclioptions.RESTClientGetter, *meta.RESTMapping) (internalpolymorphichelpers.Rollbacker, error) {
+		return rollbacker, nil
+	}
+	return o, nil
+}
+
+func TestRunUndoAggregatesErrors(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"rollouts/ro":   newUndoTestRollout("ro", "bar"),
+	}
+
+	testCases := []struct {
+		name          string
+		errs          map[string]error
+		expectCalls   []string
+		expectErr     bool
+		expectErrText []string
+	}{
+		{
+			name:        "all targets succeed",
+			expectCalls: []string{"foo", "bar"},
+		},
+		{
+			name:          "direct target fails",
+			errs:          map[string]error{"foo": fmt.Errorf("foo failed")},
+			expectCalls:   []string{"foo", "bar"},
+			expectErr:     true,
+			expectErrText: []string{"foo failed"},
+		},
+		{
+			name:          "referenced workload fails",
+			errs:          map[string]error{"bar": fmt.Errorf("bar failed")},
+			expectCalls:   []string{"foo", "bar"},
+			expectErr:     true,
+			expectErrText: []string{"bar failed"},
+		},
+		{
+			name:          "both passes fail",
+			errs:          map[string]error{"foo": fmt.Errorf("foo failed"), "bar": fmt.Errorf("bar failed")},
+			expectCalls:   []string{"foo", "bar"},
+			expectErr:     true,
+			expectErrText: []string{"foo failed", "bar failed"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{errs: tc.errs}
+			o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo", "rollout/ro")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			err = o.RunUndo()
+			assert.Equal(t, tc.expectCalls, rollbacker.calls)
+			if !tc.expectErr {
+				assert.NoError(t, err)
+				return
+			}
+			if !assert.Error(t, err) {
+				return
+			}
+			for _, text := range tc.expectErrText {
+				assert.Equal(t, 1, strings.Count(err.Error(), text), "error %q should report %q once", err.Error(), text)
+			}
+		})
+	}
+}
+
+func TestRunUndoReportsTargetErrors(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"rollouts/ro":   newUndoTestRollout("ro", "bar"),
+		"rollouts/gone": newUndoTestRollout("gone", "missing"),
+	}
+
+	for _, parallelism := range []int{1, 3} {
+		t.Run(fmt.Sprintf("parallelism %d", parallelism), func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &concurrentRollbacker{errs: map[string]error{"bar": fmt.Errorf("bar failed")}}
+			o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo", "rollout/ro", "rollout/gone")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.Parallelism = parallelism
+
+			err = o.RunUndo()
+			assert.ElementsMatch(t, []string{"foo", "bar"}, rollbacker.calls)
+			// the workload of a rollout which cannot be found fails before its undo, and is counted as failed too
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), `rollout "ro" references cloneset/bar: bar failed`)
+				assert.Contains(t, err.Error(), "missing")
+			}
+			assert.Equal(t, ExitCodePartialFailure, ExitCode(err))
+			assert.Equal(t, "undid 1, skipped 0 (duplicates), failed 2\n", o.ErrOut.(*bytes.Buffer).String())
+		})
+	}
+}
+
+func TestRunUndoRejectsUnsupportedKinds(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"pods/web": &corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		},
+	}
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	rollbacker := &fakeRollbacker{}
+	o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo", "pod/web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the supported cloneset is not rolled back either
+	assert.EqualError(t, o.RunUndo(), "unsupported kind(s): pods/web")
+	assert.Empty(t, rollbacker.calls)
+}
+
+func TestRunUndoContinueOnError(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"clonesets/baz": newUndoTestCloneSet("baz"),
+		"pods/web": &corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		args        []string
+		errs        map[string]error
+		expectCalls []string
+		expectErr   string
+	}{
+		{
+			name:        "the first target cannot be rolled back",
+			args:        []string{"pod/web", "cloneset/foo", "cloneset/bar"},
+			expectCalls: []string{"foo", "bar"},
+			expectErr:   "unsupported kind: pods/web",
+		},
+		{
+			name:        "the rollback of the first target fails",
+			args:        []string{"cloneset/baz", "cloneset/foo", "cloneset/bar"},
+			errs:        map[string]error{"baz": fmt.Errorf("no revision found")},
+			expectCalls: []string{"baz", "foo", "bar"},
+			expectErr:   "no revision found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{errs: tc.errs}
+			o, err := newUndoTestOptions(tf, rollbacker, tc.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.ContinueOnError = true
+			errOut := &bytes.Buffer{}
+			o.ErrOut = errOut
+
+			err = o.RunUndo()
+			assert.EqualError(t, err, tc.expectErr)
+			assert.Equal(t, ExitCodePartialFailure, ExitCode(err))
+			assert.Equal(t, tc.expectCalls, rollbacker.calls)
+			assert.Equal(t, "undid 2, skipped 0 (duplicates), failed 1\n", errOut.String())
+		})
+	}
+}
+
+func TestRunUndoTimeout(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"clonesets/baz": newUndoTestCloneSet("baz"),
+	}
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	rollbacker := &slowRollbacker{slow: map[string]bool{"bar": true}}
+	o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo", "cloneset/bar", "cloneset/baz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := &bytes.Buffer{}
+	o.Out = out
+	o.Timeout = 100 * time.Millisecond
+
+	err = o.RunUndo()
+	assert.EqualError(t, err, "timed out after 100ms, the remaining targets were not rolled back: context deadline exceeded")
+	assert.Equal(t, ExitCodePartialFailure, ExitCode(err))
+	// the target rolled back before the deadline is reported, the one after it is not attempted
+	assert.Equal(t, "cloneset.apps.kruise.io/foo rolled back\n", out.String())
+	assert.Equal(t, []string{"foo"}, rollbacker.calls)
+}
+
+func TestRunUndoParallelism(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"clonesets/baz": newUndoTestCloneSet("baz"),
+		"clonesets/qux": newUndoTestCloneSet("qux"),
+		"rollouts/ro":   newUndoTestRollout("ro", "foo"),
+	}
+	// the targets are not given sorted, and the first of them take the longest, so that they finish last
+	delays := map[string]time.Duration{"qux": 300 * time.Millisecond, "foo": 200 * time.Millisecond, "baz": 100 * time.Millisecond, "bar": 100 * time.Millisecond}
+
+	testCases := []struct {
+		name        string
+		parallelism int
+		errs        map[string]error
+		expectOut   string
+		expectErr   string
+	}{
+		{
+			name:        "serial",
+			parallelism: 1,
+			expectOut: "cloneset.apps.kruise.io/qux rolled back\ncloneset.apps.kruise.io/foo rolled back\n" +
+				"cloneset.apps.kruise.io/baz rolled back\ncloneset.apps.kruise.io/bar rolled back\n",
+		},
+		{
+			name:        "parallel",
+			parallelism: 3,
+			expectOut: "cloneset.apps.kruise.io/bar rolled back\ncloneset.apps.kruise.io/baz rolled back\n" +
+				"cloneset.apps.kruise.io/foo rolled back\ncloneset.apps.kruise.io/qux rolled back\n",
+		},
+		{
+			name:        "parallel with failures",
+			parallelism: 3,
+			errs:        map[string]error{"foo": fmt.Errorf("foo failed"), "baz": fmt.Errorf("baz failed")},
+			expectOut:   "cloneset.apps.kruise.io/bar rolled back\ncloneset.apps.kruise.io/qux rolled back\n",
+			expectErr:   "[baz failed, foo failed]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &concurrentRollbacker{delays: delays, errs: tc.errs}
+			o, err := newUndoTestOptions(tf, nil, "cloneset/qux", "cloneset/foo", "cloneset/baz", "cloneset/bar", "rollout/ro")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.Rollbacker = func(genericclioptions.RESTClientGetter, *meta.RESTMapping) (internalpolymorphichelpers.Rollbacker, error) {
+				return rollbacker, nil
+			}
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			o.Out, o.ErrOut = out, errOut
+			o.Parallelism = tc.parallelism
+
+			err = o.RunUndo()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectOut, out.String())
+			assert.Contains(t, errOut.String(), "skipped duplicate target CloneSet.v1alpha1.apps.kruise.io/foo")
+			assert.ElementsMatch(t, []string{"foo", "bar", "baz", "qux"}, rollbacker.calls)
+			if tc.parallelism > 1 {
+				assert.Greater(t, rollbacker.maxConcurrency, 1)
+			}
+			assert.LessOrEqual(t, rollbacker.maxConcurrency, tc.parallelism)
+		})
+	}
+}
+
+func TestRunUndoRecord(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+	}
+
+	testCases := []struct {
+		name              string
+		record            bool
+		expectAnnotations []map[string]string
+	}{
+		{
+			name:              "record disabled",
+			expectAnnotations: []map[string]string{nil},
+		},
+		{
+			name:              "record enabled",
+			record:            true,
+			expectAnnotations: []map[string]string{{"kubernetes.io/change-cause": "kubectl-kruise rollout undo cloneset/foo --record=true"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			*o.RecordFlags.Record = tc.record
+			assert.NoError(t, o.RecordFlags.CompleteWithChangeCause("kubectl-kruise rollout undo cloneset/foo --record=true"))
+			if o.Recorder, err = o.RecordFlags.ToRecorder(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assert.NoError(t, o.RunUndo())
+			assert.Equal(t, tc.expectAnnotations, rollbacker.annotations)
+		})
+	}
+}
+
+func TestRunUndoWarnsOnDuplicateTarget(t *testing.T) {
+	testCases := []struct {
+		name        string
+		args        []string
+		parallelism int
+	}{
+		{
+			name: "workload before its rollout",
+			args: []string{"cloneset/foo", "rollout/ro"},
+		},
+		{
+			name: "rollout before its workload",
+			args: []string{"rollout/ro", "cloneset/foo"},
+		},
+		{
+			name:        "rollout before its workload in parallel",
+			args:        []string{"rollout/ro", "cloneset/foo"},
+			parallelism: 2,
+		},
+		{
+			name: "two rollouts of the same workload",
+			args: []string{"rollout/ro", "rollout/other"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			objs := map[string]runtime.Object{
+				"clonesets/foo":  newUndoTestCloneSet("foo"),
+				"rollouts/ro":    newUndoTestRollout("ro", "foo"),
+				"rollouts/other": newUndoTestRollout("other", "foo"),
+			}
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, tc.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.parallelism > 0 {
+				o.Parallelism = tc.parallelism
+			}
+
+			assert.NoError(t, o.RunUndo())
+			// the workload is undone once, whether it is named directly or through the rollout first
+			assert.Equal(t, []string{"foo"}, rollbacker.calls)
+
+			out := o.Out.(*bytes.Buffer).String()
+			errOut := o.ErrOut.(*bytes.Buffer).String()
+			assert.Equal(t, "cloneset.apps.kruise.io/foo rolled back\n", out)
+			assert.Equal(t, "Warning: skipped duplicate target CloneSet.v1alpha1.apps.kruise.io/foo: cannot undo the same workload twice in a single command\nundid 1, skipped 1 (duplicates), failed 0\n", errOut)
+		})
+	}
+}
+
+func TestRunUndoFieldManager(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+	}
+
+	testCases := []struct {
+		name               string
+		fieldManager       string
+		expectFieldManager string
+	}{
+		{
+			name:               "default field manager",
+			expectFieldManager: "kubectl-kruise-rollout",
+		},
+		{
+			name:               "configured field manager",
+			fieldManager:       "team-a",
+			expectFieldManager: "team-a",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tc.fieldManager) > 0 {
+				o.FieldManager = tc.fieldManager
+			}
+
+			assert.NoError(t, o.RunUndo())
+			assert.Equal(t, []string{"foo"}, rollbacker.calls)
+			assert.Equal(t, tc.expectFieldManager, rollbacker.fieldManager)
+		})
+	}
+
+	cmd := NewCmdRolloutUndo(cmdtesting.NewTestFactory(), genericclioptions.NewTestIOStreamsDiscard())
+	assert.Equal(t, "kubectl-kruise-rollout", cmd.Flags().Lookup("field-manager").DefValue)
+}
+
+func TestRunUndoConfirmation(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+	}
+	const prompt = "You are about to roll back clonesets.apps.kruise.io/foo in namespace test.\nType the name of the workload to confirm: "
+
+	testCases := []struct {
+		name              string
+		confirm           bool
+		confirmNamespaces []string
+		yes               bool
+		terminal          bool
+		answer            string
+		expectCalls       []string
+		expectErrOut      string
+	}{
+		{
+			name:              "the name is typed",
+			confirmNamespaces: []string{"prod*", "te*"},
+			terminal:          true,
+			answer:            "foo\n",
+			expectCalls:       []string{"foo"},
+			expectErrOut:      prompt,
+		},
+		{
+			name:              "the name is not typed",
+			confirmNamespaces: []string{"te*"},
+			terminal:          true,
+			answer:            "no\n",
+			expectErrOut:      prompt + "Skipped rollback of clonesets.apps.kruise.io/foo: the name was not confirmed\n",
+		},
+		{
+			name:         "confirm asks in every namespace",
+			confirm:      true,
+			terminal:     true,
+			answer:       "",
+			expectErrOut: prompt + "Skipped rollback of clonesets.apps.kruise.io/foo: the name was not confirmed\n",
+		},
+		{
+			name:              "namespace does not match",
+			confirmNamespaces: []string{"prod*"},
+			terminal:          true,
+			expectCalls:       []string{"foo"},
+		},
+		{
+			name:              "yes skips the confirmation",
+			confirmNamespaces: []string{"te*"},
+			yes:               true,
+			terminal:          true,
+			expectCalls:       []string{"foo"},
+		},
+		{
+			name:              "stdin is not a terminal",
+			confirmNamespaces: []string{"te*"},
+			answer:            "no\n",
+			expectCalls:       []string{"foo"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.Confirm = tc.confirm
+			o.ConfirmNamespaces = tc.confirmNamespaces
+			o.Yes = tc.yes
+			o.IsTerminal = func(io.Reader) bool { return tc.terminal }
+			o.In.(*bytes.Buffer).WriteString(tc.answer)
+			assert.NoError(t, o.Validate())
+
+			assert.NoError(t, o.RunUndo())
+			assert.Equal(t, tc.expectCalls, rollbacker.calls)
+			assert.Equal(t, tc.expectErrOut, o.ErrOut.(*bytes.Buffer).String())
+		})
+	}
+
+	o := &UndoOptions{Resources: []string{"cloneset/foo"}, ConfirmNamespaces: []string{"prod["}}
+	assert.EqualError(t, o.Validate(), `invalid --confirm-namespaces pattern "prod[": syntax error in pattern`)
+}
+
+func TestRunUndoClientDryRunDiff(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+	}
+
+	testCases := []struct {
+		name         string
+		outputFormat string
+		target       *corev1.PodTemplateSpec
+		expectCalls  []string
+		expectOut    string
+	}{
+		{
+			name:   "template changes are rendered as a diff",
+			target: newUndoTestTemplate("nginx:1.24"),
+			expectOut: `--- live/clonesets.apps.kruise.io/foo
++++ rolled-back/clonesets.apps.kruise.io/foo
+@@ -4,6 +4,6 @@
+     app: foo
+ spec:
+   containers:
+-  - image: nginx:1.25
++  - image: nginx:1.24
+     name: main
+     resources: {}
+`,
+		},
+		{
+			name:      "identical templates are reported as skipped",
+			target:    newUndoTestTemplate("nginx:1.25"),
+			expectOut: "cloneset.apps.kruise.io/foo skipped rollback (current template already matches revision 0) (dry run)\n",
+		},
+		{
+			name:         "structured output falls back to the rollbacker",
+			outputFormat: "name",
+			target:       newUndoTestTemplate("nginx:1.24"),
+			expectCalls:  []string{"foo"},
+			expectOut:    "cloneset.apps.kruise.io/foo\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakePreviewRollbacker{live: newUndoTestTemplate("nginx:1.25"), target: tc.target}
+			o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.DryRunStrategy = cmdutil.DryRunClient
+			o.PrintFlags.OutputFormat = &tc.outputFormat
+
+			assert.NoError(t, o.RunUndo())
+			assert.Equal(t, tc.expectCalls, rollbacker.calls)
+			assert.Equal(t, tc.expectOut, o.Out.(*bytes.Buffer).String())
+		})
+	}
+}
+
+func TestRunUndoBySelector(t *testing.T) {
+	labeled := func(cs *kruiseappsv1alpha1.CloneSet) *kruiseappsv1alpha1.CloneSet {
+		cs.Labels = map[string]string{"app": "web"}
+		return cs
+	}
+	objs := map[string]runtime.Object{
+		"clonesets/a": labeled(newUndoTestCloneSet("a")),
+		"clonesets/b": labeled(newUndoTestCloneSet("b")),
+		"clonesets/c": labeled(newUndoTestCloneSet("c")),
+		"clonesets?labelSelector=app%3Dweb": &kruiseappsv1alpha1.CloneSetList{
+			TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSetList"},
+			Items:    []kruiseappsv1alpha1.CloneSet{*labeled(newUndoTestCloneSet("a")), *labeled(newUndoTestCloneSet("b")), *labeled(newUndoTestCloneSet("c"))},
+		},
+		"rollouts/ro": newUndoTestRollout("ro", "a"),
+		"rollouts?labelSelector=app%3Dweb": &rolloutsapiv1beta1.RolloutList{
+			TypeMeta: metav1.TypeMeta{APIVersion: rolloutsapiv1beta1.GroupVersion.String(), Kind: "RolloutList"},
+			Items:    []rolloutsapiv1beta1.Rollout{*newUndoTestRollout("ro", "a")},
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		args         []string
+		expectCalls  []string
+		expectErrOut string
+	}{
+		{
+			name:         "selector matches three clonesets",
+			args:         []string{"cloneset"},
+			expectCalls:  []string{"a", "b", "c"},
+			expectErrOut: "undid 3, skipped 0 (duplicates), failed 0\n",
+		},
+		{
+			name:        "rollout referencing a selected cloneset is deduplicated",
+			args:        []string{"cloneset,rollout"},
+			expectCalls: []string{"a", "b", "c"},
+			expectErrOut: "Warning: skipped duplicate target CloneSet.v1alpha1.apps.kruise.io/a: cannot undo the same workload twice in a single command\n" +
+				"undid 3, skipped 1 (duplicates), failed 0\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, tc.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.LabelSelector = "app=web"
+
+			assert.NoError(t, o.Validate())
+			assert.NoError(t, o.RunUndo())
+			assert.Equal(t, tc.expectCalls, rollbacker.calls)
+			assert.Equal(t, tc.expectErrOut, o.ErrOut.(*bytes.Buffer).String())
+		})
+	}
+}
+
+func TestUndoValidateSelectorRequiresResourceType(t *testing.T) {
+	o := NewRolloutUndoOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.LabelSelector = "app=web"
+	assert.EqualError(t, o.Validate(), "a resource type must be specified along with --selector, e.g. cloneset -l app=nginx")
+}
+
+func TestUndoValidateParallelism(t *testing.T) {
+	o := NewRolloutUndoOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Resources = []string{"cloneset/foo"}
+	o.Parallelism = 0
+	assert.EqualError(t, o.Validate(), "--parallelism must be at least 1, got 0")
+}
+
+func TestUndoValidateTimeout(t *testing.T) {
+	o := NewRolloutUndoOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Resources = []string{"cloneset/foo"}
+	o.Timeout = -time.Second
+	assert.EqualError(t, o.Validate(), "--timeout must not be negative, got -1s")
+}
+
+func TestUndoValidateToRevision(t *testing.T) {
+	testCases := []struct {
+		toRevision    int64
+		expectErrText string
+	}{
+		{toRevision: -1, expectErrText: "--to-revision must be a non-negative revision number, or 0 for the previous revision, got -1"},
+		{toRevision: 0},
+		{toRevision: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("revision %d", tc.toRevision), func(t *testing.T) {
+			o := NewRolloutUndoOptions(genericclioptions.NewTestIOStreamsDiscard())
+			o.Resources = []string{"cloneset/foo"}
+			o.ToRevision = tc.toRevision
+			if len(tc.expectErrText) > 0 {
+				assert.EqualError(t, o.Validate(), tc.expectErrText)
+			} else {
+				assert.NoError(t, o.Validate())
+			}
+		})
+	}
+}
+
+func TestUndoToRevisionFlag(t *testing.T) {
+	testCases := []struct {
+		value              string
+		expectRevision     int64
+		expectLatestStable bool
+		expectErrText      string
+	}{
+		{value: "3", expectRevision: 3},
+		{value: "0", expectRevision: 0},
+		{value: "previous", expectRevision: 0},
+		{value: "latest-stable", expectLatestStable: true},
+		{value: "latest", expectErrText: `must be a revision number, "previous" or "latest-stable"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			o := NewRolloutUndoOptions(genericclioptions.NewTestIOStreamsDiscard())
+			o.ToRevision, o.ToLatestStable = 5, true
+			err := (&toRevisionValue{o: o}).Set(tc.value)
+			if len(tc.expectErrText) > 0 {
+				assert.EqualError(t, err, tc.expectErrText)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectRevision, o.ToRevision)
+			assert.Equal(t, tc.expectLatestStable, o.ToLatestStable)
+		})
+	}
+}
+
+func TestRunUndoToLatestStable(t *testing.T) {
+	rollout := newUndoTestRollout("ro", "bar")
+	rollout.Status.CanaryStatus = &rolloutsapiv1beta1.CanaryStatus{StableRevision: "bar-5d4b"}
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"rollouts/ro":   rollout,
+		"rollouts/new":  newUndoTestRollout("new", "foo"),
+	}
+
+	testCases := []struct {
+		name                 string
+		args                 []string
+		value                string
+		expectStableRevision []string
+		expectToRevisions    []int64
+		expectErr            string
+	}{
+		{
+			name:              "previous",
+			args:              []string{"rollout/ro"},
+			value:             "previous",
+			expectToRevisions: []int64{0},
+		},
+		{
+			name:                 "latest-stable of a rollout",
+			args:                 []string{"rollout/ro"},
+			value:                "latest-stable",
+			expectStableRevision: []string{"bar-5d4b"},
+			expectToRevisions:    []int64{7},
+		},
+		{
+			name:                 "latest-stable of a workload",
+			args:                 []string{"cloneset/foo"},
+			value:                "latest-stable",
+			expectStableRevision: []string{""},
+			expectToRevisions:    []int64{7},
+		},
+		{
+			name:      "rollout without a stable revision",
+			args:      []string{"rollout/new"},
+			value:     "latest-stable",
+			expectErr: "no stable revision is recorded in the status of the rollout yet",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, tc.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.NoError(t, (&toRevisionValue{o: o}).Set(tc.value))
+			var stableRevisions []string
+			o.StableRevision = func(_ genericclioptions.RESTClientGetter, _ runtime.Object, stableRevision string) (int64, error) {
+				stableRevisions = append(stableRevisions, stableRevision)
+				return 7, nil
+			}
+
+			err = o.RunUndo()
+			if len(tc.expectErr) > 0 {
+				assert.ErrorContains(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectStableRevision, stableRevisions)
+			assert.Equal(t, tc.expectToRevisions, rollbacker.toRevisions)
+		})
+	}
+}
+
+func TestRunUndoToImage(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"rollouts/ro":   newUndoTestRollout("ro", "foo"),
+	}
+
+	testCases := []struct {
+		name              string
+		args              []string
+		imageRevisionErr  error
+		expectToRevisions []int64
+		expectErr         string
+	}{
+		{
+			name:              "matching revision",
+			args:              []string{"cloneset/foo"},
+			expectToRevisions: []int64{4},
+		},
+		{
+			name:              "matching revision of the workload of a rollout",
+			args:              []string{"rollout/ro"},
+			expectToRevisions: []int64{4},
+		},
+		{
+			name:             "no matching revision",
+			args:             []string{"cloneset/foo"},
+			imageRevisionErr: fmt.Errorf(`no revision in the history runs image "nginx:1.24" in container "nginx", available images: nginx:1.22, nginx:1.23`),
+			expectErr:        `no revision in the history runs image "nginx:1.24" in container "nginx", available images: nginx:1.22, nginx:1.23`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, tc.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.ToImage = "nginx=nginx:1.24"
+			assert.NoError(t, o.Validate())
+			var images []string
+			o.ImageRevision = func(_ genericclioptions.RESTClientGetter, obj runtime.Object, container, image string) (int64, error) {
+				images = append(images, container+"="+image)
+				return 4, tc.imageRevisionErr
+			}
+
+			err = o.RunUndo()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, []string{"nginx=nginx:1.24"}, images)
+			assert.Equal(t, tc.expectToRevisions, rollbacker.toRevisions)
+		})
+	}
+}
+
+func TestUndoValidateToImage(t *testing.T) {
+	o := NewRolloutUndoOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Resources = []string{"cloneset/foo"}
+	o.ToImage = "nginx:1.24"
+	assert.EqualError(t, o.Validate(), `--to-image must be of the form CONTAINER=IMAGE, e.g. nginx=nginx:1.24, got "nginx:1.24"`)
+
+	o.ToImage = "nginx=nginx:1.24"
+	o.ToRevision = 3
+	assert.EqualError(t, o.Validate(), "--to-image and --to-revision cannot be used together")
+}
+
+func TestRunUndoWorkloadsOnly(t *testing.T) {
+	rollout := newUndoTestRollout("ro", "bar")
+	rollout.Status.CanaryStatus = &rolloutsapiv1beta1.CanaryStatus{StableRevision: "bar-5d4b"}
+	objs := map[string]runtime.Object{
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"rollouts/ro":   rollout,
+	}
+
+	testCases := []struct {
+		name                 string
+		workloadsOnly        bool
+		expectStableRevision []string
+		expectErr            string
+	}{
+		{
+			name:                 "rollout",
+			expectStableRevision: []string{"bar-5d4b"},
+			expectErr:            `rollout "ro" references cloneset/bar: bar failed`,
+		},
+		{
+			name:                 "workloads only",
+			workloadsOnly:        true,
+			expectStableRevision: []string{""},
+			expectErr:            "bar failed",
+		},
+	}
+
+	requestCounts := map[bool]int{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+			client := tf.Client.(*fake.RESTClient).Client
+			tf.Client.(*fake.RESTClient).Client = fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				requestCounts[tc.workloadsOnly]++
+				return client.Do(req)
+			})
+
+			o, err := newUndoTestOptions(tf, &fakeRollbacker{errs: map[string]error{"bar": fmt.Errorf("bar failed")}}, "rollout/ro")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.WorkloadsOnly = tc.workloadsOnly
+			o.ToLatestStable = true
+			var stableRevisions []string
+			o.StableRevision = func(_ genericclioptions.RESTClientGetter, _ runtime.Object, stableRevision string) (int64, error) {
+				stableRevisions = append(stableRevisions, stableRevision)
+				return 7, nil
+			}
+
+			assert.EqualError(t, o.RunUndo(), tc.expectErr)
+			assert.Equal(t, tc.expectStableRevision, stableRevisions)
+		})
+	}
+	// the workload of a rollout is resolved in the same pass either way, one request for each object
+	assert.Equal(t, map[bool]int{false: 2, true: 2}, requestCounts)
+}
+
+func TestRunUndoToRevisionForRollout(t *testing.T) {
+	testCases := []struct {
+		name        string
+		toRevision  int64
+		expectErr   string
+		expectOut   string
+		expectCalls []string
+	}{
+		{
+			name:        "revision of the referenced workload",
+			toRevision:  5,
+			expectOut:   "cloneset.apps.kruise.io/bar rolled back\n",
+			expectCalls: []string{"bar"},
+		},
+		{
+			name:        "previous revision",
+			expectOut:   "cloneset.apps.kruise.io/bar rolled back\n",
+			expectCalls: []string{"bar"},
+		},
+		{
+			name:        "missing revision",
+			toRevision:  7,
+			expectErr:   `rollout "ro" references cloneset/bar: unable to find specified revision 7 in history`,
+			expectCalls: []string{"bar"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			objs := map[string]runtime.Object{
+				"clonesets/bar": newUndoTestCloneSet("bar"),
+				"rollouts/ro":   newUndoTestRollout("ro", "bar"),
+			}
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{revisions: []int64{3, 4, 5}}
+			o, err := newUndoTestOptions(tf, rollbacker, "rollout/ro")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.ToRevision = tc.toRevision
+
+			err = o.RunUndo()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectCalls, rollbacker.calls)
+			assert.Equal(t, []int64{tc.toRevision}, rollbacker.toRevisions)
+			assert.Equal(t, tc.expectOut, o.Out.(*bytes.Buffer).String())
+		})
+	}
+}
+
+func TestRunUndoResolvesRolloutWorkloadInOnePass(t *testing.T) {
+	other := newUndoTestRollout("ro", "bar")
+	other.Namespace = "other"
+	manifest, err := yaml.Marshal(other)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filename := filepath.Join(t.TempDir(), "rollout.yaml")
+	if err := os.WriteFile(filename, manifest, 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	labeled := newUndoTestRollout("ro", "foo")
+	labeled.Labels = map[string]string{"app": "web"}
+	objs := map[string]runtime.Object{
+		"/namespaces/other/rollouts/ro":   other,
+		"/namespaces/other/clonesets/bar": newUndoTestCloneSet("bar"),
+		"/namespaces/test/rollouts?labelSelector=app%3Dweb": &rolloutsapiv1beta1.RolloutList{
+			TypeMeta: metav1.TypeMeta{APIVersion: rolloutsapiv1beta1.GroupVersion.String(), Kind: "RolloutList"},
+			Items:    []rolloutsapiv1beta1.Rollout{*labeled},
+		},
+		"/namespaces/test/clonesets/foo": newUndoTestCloneSet("foo"),
+	}
+
+	testCases := []struct {
+		name           string
+		args           []string
+		filename       string
+		selector       string
+		expectCalls    []string
+		expectRequests []string
+	}{
+		{
+			name:        "workload is fetched from the namespace of the rollout",
+			filename:    filename,
+			expectCalls: []string{"bar"},
+			expectRequests: []string{
+				"/namespaces/other/rollouts/ro",
+				"/namespaces/other/clonesets/bar",
+			},
+		},
+		{
+			name:        "rollouts are listed once",
+			args:        []string{"rollout"},
+			selector:    "app=web",
+			expectCalls: []string{"foo"},
+			expectRequests: []string{
+				"/namespaces/test/rollouts?labelSelector=app%3Dweb",
+				"/namespaces/test/clonesets/foo",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			tf.Client = &fake.RESTClient{
+				GroupVersion:         schema.GroupVersion{Group: "apps.kruise.io", Version: "v1alpha1"},
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					requested := req.URL.Path
+					if len(req.URL.RawQuery) > 0 {
+						requested += "?" + req.URL.RawQuery
+					}
+					requests = append(requests, requested)
+					if obj, ok := objs[requested]; ok && req.Method == http.MethodGet {
+						codec := scheme.Codecs.LegacyCodec(obj.GetObjectKind().GroupVersionKind().GroupVersion())
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+					}
+					t.Fatalf("unexpected request: %s %s", req.Method, requested)
+					return nil, nil
+				}),
+			}
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, tc.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tc.filename) > 0 {
+				// as if --namespace was not given, so the namespace of the manifest is used
+				o.Filenames, o.EnforceNamespace = []string{tc.filename}, false
+			}
+			o.LabelSelector = tc.selector
+
+			assert.NoError(t, o.RunUndo())
+			assert.Equal(t, tc.expectCalls, rollbacker.calls)
+			assert.Equal(t, tc.expectRequests, requests)
+		})
+	}
+}
+
+// serverRollbacker replaces the objects served by the fake server with rolled back ones, as a rollback would.
+type serverRollbacker struct {
+	fakeRollbacker
+	objs       map[string]runtime.Object
+	rolledBack map[string]runtime.Object
+}
+
+func (r *serverRollbacker) Rollback(obj runtime.Object, updatedAnnotations map[string]string, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
+	result, err := r.fakeRollbacker.Rollback(obj, updatedAnnotations, toRevision, dryRunStrategy)
+	if err != nil {
+		return "", err
+	}
+	for path, rolledBack := range r.rolledBack {
+		r.objs[path] = rolledBack
+	}
+	return result, nil
+}
+
+func TestRunUndoPrintsRolledBackObject(t *testing.T) {
+	live := newUndoTestCloneSet("foo")
+	live.Spec.Template = *newUndoTestTemplate("nginx:v2")
+	rolledBack := newUndoTestCloneSet("foo")
+	rolledBack.Spec.Template = *newUndoTestTemplate("nginx:v1")
+	objs := map[string]runtime.Object{"clonesets/foo": live}
+
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	rollbacker := &serverRollbacker{objs: objs, rolledBack: map[string]runtime.Object{"clonesets/foo": rolledBack}}
+	o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := "json"
+	o.PrintFlags.OutputFormat = &output
+
+	assert.NoError(t, o.RunUndo())
+	assert.Equal(t, []string{"foo"}, rollbacker.calls)
+
+	expected, err := os.ReadFile("../../../testdata/rollout/undo-cloneset.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, string(expected), o.Out.(*bytes.Buffer).String())
+}
+
+func TestRunUndoPrintsNames(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"rollouts/ro":   newUndoTestRollout("ro", "bar"),
+	}
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	rollbacker := &fakeRollbacker{}
+	// bar is given both through the rollout and directly, it is only undone and printed once
+	o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo", "rollout/ro", "cloneset/bar")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := "name"
+	o.PrintFlags.OutputFormat = &output
+
+	assert.NoError(t, o.RunUndo())
+	assert.Equal(t, []string{"foo", "bar"}, rollbacker.calls)
+	// the workload referenced by the rollout is printed, not the rollout
+	assert.Equal(t, "cloneset.apps.kruise.io/foo\ncloneset.apps.kruise.io/bar\n", o.Out.(*bytes.Buffer).String())
+}
+
+func TestRunUndoFromStdin(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"rollouts/ro":   newUndoTestRollout("ro", "foo"),
+	}
+	var documents []string
+	for _, obj := range []runtime.Object{objs["clonesets/foo"], objs["rollouts/ro"]} {
+		manifest, err := yaml.Marshal(obj)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		documents = append(documents, string(manifest))
+	}
+
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	rollbacker := &fakeRollbacker{}
+	o, err := newUndoTestOptions(tf, rollbacker)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o.Filenames = []string{"-"}
+	o.In = strings.NewReader(strings.Join(documents, "---\n"))
+
+	assert.NoError(t, o.Validate())
+	assert.NoError(t, o.RunUndo())
+	assert.Equal(t, []string{"foo"}, rollbacker.calls)
+	assert.Equal(t, "cloneset.apps.kruise.io/foo rolled back\n", o.Out.(*bytes.Buffer).String())
+	assert.Equal(t, "Warning: skipped duplicate target CloneSet.v1alpha1.apps.kruise.io/foo: cannot undo the same workload twice in a single command\nundid 1, skipped 1 (duplicates), failed 0\n", o.ErrOut.(*bytes.Buffer).String())
+}
+
+func TestRunUndoKustomizeSkipsUnsupportedKinds(t *testing.T) {
+	var requests []string
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+	}
+	configMap := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "test"},
+		Data:       map[string]string{"key": "value"},
+	}
+
+	dir := t.TempDir()
+	files := map[string]runtime.Object{"configmap.yaml": configMap, "cloneset.yaml": objs["clonesets/foo"]}
+	for name, obj := range files {
+		manifest, err := yaml.Marshal(obj)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), manifest, 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	kustomization := "resources:\n- configmap.yaml\n- cloneset.yaml\n"
+	if err := os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(kustomization), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+	client := tf.Client.(*fake.RESTClient).Client
+	tf.Client.(*fake.RESTClient).Client = fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.URL.Path)
+		return client.Do(req)
+	})
+
+	rollbacker := &fakeRollbacker{}
+	o, err := newUndoTestOptions(tf, rollbacker)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o.Kustomize = dir
+
+	assert.NoError(t, o.Validate())
+	assert.NoError(t, o.RunUndo())
+	assert.Equal(t, []string{"foo"}, rollbacker.calls)
+	// the config map is not fetched from the server, the rollouts are listed to find the ones managing the cloneset
+	assert.Equal(t, []string{"/namespaces/test/clonesets/foo", "/namespaces/test/rollouts"}, requests)
+	assert.Equal(t, "cloneset.apps.kruise.io/foo rolled back\n", o.Out.(*bytes.Buffer).String())
+	assert.Equal(t, "Warning: skipped resources that cannot be rolled back: configmaps/settings\n", o.ErrOut.(*bytes.Buffer).String())
+
+	// a directory without any workload is an error
+	if err := os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources:\n- configmap.yaml\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o, err = newUndoTestOptions(tf, rollbacker)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o.Kustomize = dir
+	assert.EqualError(t, o.RunUndo(), "none of the given resources can be rolled back")
+}
+
+func TestRunUndoPrintsJSONPath(t *testing.T) {
+	cmd := NewCmdRolloutUndo(cmdtesting.NewTestFactory(), genericclioptions.NewTestIOStreamsDiscard())
+	for _, flag := range []string{"output", "template", "allow-missing-template-keys"} {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), "undo should register --%s", flag)
+	}
+
+	testCases := []struct {
+		name             string
+		template         string
+		allowMissingKeys bool
+		expectOut        string
+		expectErr        string
+	}{
+		{
+			name:      "rolled back image",
+			template:  "{.metadata.name} {.spec.template.spec.containers[0].image}",
+			expectOut: "foo nginx:v1",
+		},
+		{
+			name:             "missing key allowed",
+			template:         "{.metadata.name}{.status.missing}",
+			allowMissingKeys: true,
+			expectOut:        "foo",
+		},
+		{
+			name:      "missing key rejected",
+			template:  "{.metadata.name}{.status.missing}",
+			expectErr: "error executing jsonpath \"{.metadata.name}{.status.missing}\": Error executing template: missing is not found. Printing more information for debugging the template:\n\ttemplate was:\n\t\t{.metadata.name}{.status.missing}\n\tobject given to jsonpath engine was:\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			live := newUndoTestCloneSet("foo")
+			live.Spec.Template = *newUndoTestTemplate("nginx:v2")
+			rolledBack := newUndoTestCloneSet("foo")
+			rolledBack.Spec.Template = *newUndoTestTemplate("nginx:v1")
+			objs := map[string]runtime.Object{"clonesets/foo": live}
+
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &serverRollbacker{objs: objs, rolledBack: map[string]runtime.Object{"clonesets/foo": rolledBack}}
+			o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			output := "jsonpath=" + tc.template
+			o.PrintFlags.OutputFormat = &output
+			*o.PrintFlags.TemplatePrinterFlags.AllowMissingKeys = tc.allowMissingKeys
+
+			err = o.RunUndo()
+			if len(tc.expectErr) > 0 {
+				if assert.Error(t, err) {
+					assert.True(t, strings.HasPrefix(err.Error(), tc.expectErr), err.Error())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectOut, o.Out.(*bytes.Buffer).String())
+		})
+	}
+}
+
+func TestRunUndoWarnsAboutManagingRollouts(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/web":   newUndoTestCloneSet("web"),
+		"clonesets/other": newUndoTestCloneSet("other"),
+		"rollouts/ro":     newUndoTestRollout("ro", "web"),
+		"rollouts": &rolloutsapiv1beta1.RolloutList{
+			TypeMeta: metav1.TypeMeta{APIVersion: rolloutsapiv1beta1.GroupVersion.String(), Kind: "RolloutList"},
+			Items:    []rolloutsapiv1beta1.Rollout{*newUndoTestRollout("ro", "web")},
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		args         []string
+		expectCalls  []string
+		expectErrOut string
+	}{
+		{
+			name:         "workload managed by a rollout",
+			args:         []string{"cloneset/web"},
+			expectCalls:  []string{"web"},
+			expectErrOut: "Warning: clonesets.apps.kruise.io/web is managed by rollout \"ro\", undoing it directly may conflict with the rollout controller, use \"kubectl-kruise rollout undo rollout/ro\" instead\n",
+		},
+		{
+			name:        "workload not managed by a rollout",
+			args:        []string{"cloneset/other"},
+			expectCalls: []string{"other"},
+		},
+		{
+			name:        "workload given through its rollout",
+			args:        []string{"rollout/ro"},
+			expectCalls: []string{"web"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, tc.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assert.NoError(t, o.RunUndo())
+			assert.Equal(t, tc.expectCalls, rollbacker.calls)
+			assert.Equal(t, tc.expectErrOut, o.ErrOut.(*bytes.Buffer).String())
+		})
+	}
+}
+
+func TestRunUndoPreviousConfigOnly(t *testing.T) {
+	for _, previousConfigOnly := range []bool{false, true} {
+		t.Run(fmt.Sprintf("previous-config-only=%v", previousConfigOnly), func(t *testing.T) {
+			tf := newUndoTestFactory(t, map[string]runtime.Object{"clonesets/foo": newUndoTestCloneSet("foo")})
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.PreviousConfigOnly = previousConfigOnly
+
+			assert.NoError(t, o.RunUndo())
+			assert.Equal(t, []string{"foo"}, rollbacker.calls)
+			assert.Equal(t, previousConfigOnly, rollbacker.templateOnly)
+		})
+	}
+}
+
+func TestRunUndoFromFile(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"clonesets/baz": newUndoTestCloneSet("baz"),
+	}
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	targets := filepath.Join(t.TempDir(), "targets.txt")
+	content := "# web tier\ncloneset/foo\n\n  cloneset/bar  \n# cloneset/skipped\ncloneset/baz\ncloneset/foo\n"
+	assert.NoError(t, os.WriteFile(targets, []byte(content), 0644))
+
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdRolloutUndo(tf, streams)
+	o := NewRolloutUndoOptions(streams)
+	o.FromFile = targets
+	assert.NoError(t, o.Complete(tf, cmd, nil))
+	assert.Equal(t, []string{"cloneset/foo", "cloneset/bar", "cloneset/baz"}, o.Resources)
+	assert.NoError(t, o.Validate())
+	rollbacker := &fakeRollbacker{}
+	o.Rollbacker = func(genericclioptions.RESTClientGetter, *meta.RESTMapping) (internalpolymorphichelpers.Rollbacker, error) {
+		return rollbacker, nil
+	}
+
+	assert.NoError(t, o.RunUndo())
+	assert.Equal(t, []string{"foo", "bar", "baz"}, rollbacker.calls)
+	assert.Equal(t, "cloneset.apps.kruise.io/foo rolled back\ncloneset.apps.kruise.io/bar rolled back\ncloneset.apps.kruise.io/baz rolled back\n", out.String())
+	assert.Equal(t, "undid 3, skipped 0 (duplicates), failed 0\n", errOut.String())
+}
+
+func TestAppendTargetsFromFileInvalidTarget(t *testing.T) {
+	targets := filepath.Join(t.TempDir(), "targets.txt")
+	assert.NoError(t, os.WriteFile(targets, []byte("cloneset/foo\ncloneset bar\n"), 0644))
+
+	_, err := appendTargetsFromFile(nil, targets)
+	assert.EqualError(t, err, fmt.Sprintf(`invalid target "cloneset bar" on line 2 of %s: must be of the form TYPE/NAME`, targets))
+}
+
+func TestRunUndoAllNamespaces(t *testing.T) {
+	inNamespace := func(cs *kruiseappsv1alpha1.CloneSet, namespace string) *kruiseappsv1alpha1.CloneSet {
+		cs.Namespace = namespace
+		cs.Labels = map[string]string{"app": "web"}
+		return cs
+	}
+	objs := map[string]runtime.Object{
+		"/clonesets?labelSelector=app%3Dweb": &kruiseappsv1alpha1.CloneSetList{
+			TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSetList"},
+			Items: []kruiseappsv1alpha1.CloneSet{
+				*inNamespace(newUndoTestCloneSet("web"), "east"),
+				*inNamespace(newUndoTestCloneSet("web"), "west"),
+			},
+		},
+	}
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	rollbacker := &fakeRollbacker{}
+	o, err := newUndoTestOptions(tf, rollbacker, "cloneset")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o.LabelSelector = "app=web"
+	o.AllNamespaces = true
+
+	assert.NoError(t, o.Validate())
+	assert.NoError(t, o.RunUndo())
+	// the clonesets share a name, but are rolled back once in each namespace
+	assert.Equal(t, []string{"web", "web"}, rollbacker.calls)
+	assert.Equal(t, []string{"east", "west"}, rollbacker.namespaces)
+	assert.Equal(t, "cloneset.apps.kruise.io/web rolled back\ncloneset.apps.kruise.io/web rolled back\n", o.Out.(*bytes.Buffer).String())
+	assert.Equal(t, "undid 2, skipped 0 (duplicates), failed 0\n", o.ErrOut.(*bytes.Buffer).String())
+}
+
+func TestRunUndoSameNameInTwoNamespaces(t *testing.T) {
+	east, west := newUndoTestCloneSet("web"), newUndoTestCloneSet("web")
+	east.Namespace, west.Namespace = "east", "west"
+	objs := map[string]runtime.Object{
+		"/namespaces/east/clonesets/web": east,
+		"/namespaces/west/clonesets/web": west,
+	}
+	// the cloneset of the east namespace is listed twice, it is the only duplicate
+	var documents []string
+	for _, obj := range []runtime.Object{east, west, east} {
+		manifest, err := yaml.Marshal(obj)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		documents = append(documents, string(manifest))
+	}
+
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	rollbacker := &fakeRollbacker{}
+	o, err := newUndoTestOptions(tf, rollbacker)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o.Filenames = []string{"-"}
+	o.In = strings.NewReader(strings.Join(documents, "---\n"))
+	// without --namespace, the objects read from a manifest are undone in their own namespaces
+	o.EnforceNamespace = false
+
+	assert.NoError(t, o.Validate())
+	assert.NoError(t, o.RunUndo())
+	assert.Equal(t, []string{"web", "web"}, rollbacker.calls)
+	assert.Equal(t, []string{"east", "west"}, rollbacker.namespaces)
+	assert.Equal(t, "Warning: skipped duplicate target CloneSet.v1alpha1.apps.kruise.io/web: cannot undo the same workload twice in a single command\n"+
+		"undid 2, skipped 1 (duplicates), failed 0\n", o.ErrOut.(*bytes.Buffer).String())
+}
+
+// newUndoTestBatch returns the objects of n clonesets labeled app=web, listed by the label.
+func newUndoTestBatch(n int) map[string]runtime.Object {
+	list := &kruiseappsv1alpha1.CloneSetList{
+		TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSetList"},
+	}
+	for i := 0; i < n; i++ {
+		cs := newUndoTestCloneSet(fmt.Sprintf("web-%d", i))
+		cs.Labels = map[string]string{"app": "web"}
+		list.Items = append(list.Items, *cs)
+	}
+	return map[string]runtime.Object{"clonesets?labelSelector=app%3Dweb": list}
+}
+
+// runUndoBatch undoes the clonesets of newUndoTestBatch, and returns how many rollbackers were built for them.
+func runUndoBatch(tb testing.TB, tf *cmdtesting.TestFactory) (int, *fakeRollbacker) {
+	rollbacker := &fakeRollbacker{}
+	o, err := newUndoTestOptions(tf, rollbacker, "cloneset")
+	if err != nil {
+		tb.Fatalf("unexpected error: %v", err)
+	}
+	o.LabelSelector = "app=web"
+	built := 0
+	o.Rollbacker = func(genericclioptions.RESTClientGetter, *meta.RESTMapping) (internalpolymorphichelpers.Rollbacker, error) {
+		built++
+		return rollbacker, nil
+	}
+	if err := o.RunUndo(); err != nil {
+		tb.Fatalf("unexpected error: %v", err)
+	}
+	return built, rollbacker
+}
+
+func TestRunUndoBuildsRollbackerOncePerKind(t *testing.T) {
+	tf := newUndoTestFactory(t, newUndoTestBatch(50))
+	defer tf.Cleanup()
+
+	built, rollbacker := runUndoBatch(t, tf)
+	assert.Equal(t, 1, built)
+	assert.Len(t, rollbacker.calls, 50)
+}
+
+func BenchmarkRunUndoSameKind(b *testing.B) {
+	tf := newUndoTestFactory(b, newUndoTestBatch(50))
+	defer tf.Cleanup()
+
+	built := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n, _ := runUndoBatch(b, tf)
+		built += n
+	}
+	b.ReportMetric(float64(built)/float64(b.N), "rollbackers/op")
+}
+
+func TestRunUndoSummaryTable(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"clonesets/baz": newUndoTestCloneSet("baz"),
+	}
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	rollbacker := &revisionsRollbacker{
+		fakeRollbacker: fakeRollbacker{errs: map[string]error{"bar": fmt.Errorf("boom")}},
+		current:        map[string]int64{"foo": 5, "bar": 3, "baz": 12},
+	}
+	o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo", "cloneset/bar", "cloneset/baz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o.SummaryFormat = "table"
+	assert.NoError(t, o.Validate())
+
+	err = o.RunUndo()
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, []string{"foo", "bar", "baz"}, rollbacker.calls)
+	expected := `NAMESPACE   KIND       NAME   FROM-REVISION   TO-REVISION   STATUS
+test        CloneSet   foo    5               4             rolled back
+test        CloneSet   bar    3               2             failed
+test        CloneSet   baz    12              11            rolled back
+`
+	assert.Equal(t, expected, o.Out.(*bytes.Buffer).String())
+}
+
+func TestRunUndoExplain(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"rollouts/ro":   newUndoTestRollout("ro", "bar"),
+	}
+	// the factory fails the test on any request other than a read
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	rollbacker := &revisionsRollbacker{current: map[string]int64{"foo": 5, "bar": 3}}
+	o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo", "rollout/ro")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o.ToRevision = 3
+	o.Explain = true
+	assert.NoError(t, o.Validate())
+
+	assert.NoError(t, o.RunUndo())
+	assert.Empty(t, rollbacker.calls)
+	expected := `NAMESPACE   TARGET         WORKLOAD       FROM-REVISION   TO-REVISION   PLAN
+test        CloneSet/foo   CloneSet/foo   5               3             roll back
+test        Rollout/ro     CloneSet/bar   3               3             skip, already at revision
+`
+	assert.Equal(t, expected, o.Out.(*bytes.Buffer).String())
+	// nothing is undone, so nothing is counted
+	assert.Empty(t, o.ErrOut.(*bytes.Buffer).String())
+
+	o.Explain, o.Wait = true, true
+	assert.EqualError(t, o.Validate(), "--explain only prints the plan of the rollbacks, it cannot be used with --output, --output-format, --output-file or --wait")
+}
+
+func TestUndoValidateSummaryFormat(t *testing.T) {
+	o := NewRolloutUndoOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Resources = []string{"cloneset/foo"}
+
+	o.SummaryFormat = "wide"
+	assert.EqualError(t, o.Validate(), `--output-format must be table, got "wide"`)
+
+	o.SummaryFormat = "table"
+	output := "name"
+	o.PrintFlags.OutputFormat = &output
+	assert.EqualError(t, o.Validate(), "--output-format and --output cannot be used together")
+}
+
+func TestRunUndoPausedWorkload(t *testing.T) {
+	paused := newUndoTestCloneSet("web")
+	paused.Spec.UpdateStrategy.Paused = true
+	objs := map[string]runtime.Object{
+		"clonesets/web":   paused,
+		"clonesets/other": newUndoTestCloneSet("other"),
+		"rollouts/ro":     newUndoTestRollout("ro", "web"),
+	}
+
+	testCases := []struct {
+		name          string
+		args          []string
+		resume        bool
+		dryRun        cmdutil.DryRunStrategy
+		expectOut     string
+		expectErrOut  string
+		expectPatches []string
+	}{
+		{
+			name:      "paused workload",
+			args:      []string{"cloneset/web", "cloneset/other"},
+			expectOut: "cloneset.apps.kruise.io/web rolled back\ncloneset.apps.kruise.io/other rolled back\n",
+			expectErrOut: "Warning: clonesets.apps.kruise.io/web is paused, the rollback takes effect once it is resumed, e.g. with --resume or \"kubectl-kruise rollout resume clonesets.apps.kruise.io/web\"\n" +
+				"undid 2, skipped 0 (duplicates), failed 0\n",
+		},
+		{
+			name:          "paused workload with --resume",
+			args:          []string{"cloneset/web", "cloneset/other"},
+			resume:        true,
+			expectOut:     "cloneset.apps.kruise.io/web rolled back and resumed\ncloneset.apps.kruise.io/other rolled back\n",
+			expectErrOut:  "undid 2, skipped 0 (duplicates), failed 0\n",
+			expectPatches: []string{`/namespaces/test/clonesets/web?fieldManager=kubectl-kruise-rollout {"spec":{"updateStrategy":{"paused":null}}}`},
+		},
+		{
+			name:          "paused workload with --resume and a server dry-run",
+			args:          []string{"cloneset/web"},
+			resume:        true,
+			dryRun:        cmdutil.DryRunServer,
+			expectOut:     "cloneset.apps.kruise.io/web rolled back and resumed (server dry run)\n",
+			expectPatches: []string{`/namespaces/test/clonesets/web?dryRun=All&fieldManager=kubectl-kruise-rollout {"spec":{"updateStrategy":{"paused":null}}}`},
+		},
+		{
+			name:      "paused workload of a rollout",
+			args:      []string{"rollout/ro"},
+			resume:    true,
+			expectOut: "cloneset.apps.kruise.io/web rolled back\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+			var patches []string
+			get := tf.Client.(*fake.RESTClient).Client
+			tf.Client.(*fake.RESTClient).Client = fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				if req.Method != http.MethodPatch {
+					return get.Do(req)
+				}
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				requested := req.URL.Path
+				if len(req.URL.RawQuery) > 0 {
+					requested += "?" + req.URL.RawQuery
+				}
+				patches = append(patches, requested+" "+string(body))
+				codec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, newUndoTestCloneSet("web"))}, nil
+			})
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, tc.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.Resume = tc.resume
+			o.DryRunStrategy = tc.dryRun
+
+			assert.NoError(t, o.RunUndo())
+			assert.Equal(t, tc.expectOut, o.Out.(*bytes.Buffer).String())
+			assert.Equal(t, tc.expectErrOut, o.ErrOut.(*bytes.Buffer).String())
+			assert.Equal(t, tc.expectPatches, patches)
+		})
+	}
+}
+
+func TestRunUndoCounts(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/web": newUndoTestCloneSet("web"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+		"clonesets/baz": newUndoTestCloneSet("baz"),
+		"rollouts/ro":   newUndoTestRollout("ro", "web"),
+	}
+
+	testCases := []struct {
+		name         string
+		parallelism  int
+		quiet        bool
+		noCounts     bool
+		expectErrOut string
+	}{
+		{
+			name:         "serial",
+			parallelism:  1,
+			expectErrOut: "undid 2, skipped 1 (duplicates), failed 2\n",
+		},
+		{
+			name:         "parallel",
+			parallelism:  3,
+			expectErrOut: "undid 2, skipped 1 (duplicates), failed 2\n",
+		},
+		{
+			name:         "quiet",
+			parallelism:  1,
+			quiet:        true,
+			expectErrOut: "undid 2, skipped 1 (duplicates), failed 2\n",
+		},
+		{
+			name:        "no counts",
+			parallelism: 1,
+			noCounts:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &concurrentRollbacker{errs: map[string]error{"bar": fmt.Errorf("boom")}}
+			// web is undone through the rollout, so its second target is a duplicate, and missing cannot be found
+			o, err := newUndoTestOptions(tf, rollbacker, "rollout/ro", "cloneset/web", "cloneset/bar", "cloneset/baz", "cloneset/missing")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.Parallelism = tc.parallelism
+			o.Quiet = tc.quiet
+			o.NoCounts = tc.noCounts
+
+			assert.Error(t, o.RunUndo())
+			errOut := o.ErrOut.(*bytes.Buffer).String()
+			assert.Contains(t, errOut, "Warning: skipped duplicate target CloneSet.v1alpha1.apps.kruise.io/web")
+			counts := errOut[strings.Index(errOut, "command\n")+len("command\n"):]
+			assert.Equal(t, tc.expectErrOut, counts)
+		})
+	}
+}
+
+func TestRunUndoQuiet(t *testing.T) {
+	objs := map[string]runtime.Object{
+		"clonesets/foo": newUndoTestCloneSet("foo"),
+		"clonesets/bar": newUndoTestCloneSet("bar"),
+	}
+
+	testCases := []struct {
+		name         string
+		outputFormat string
+		expectOut    string
+	}{
+		{
+			name: "names left out",
+		},
+		{
+			name:         "structured output still printed",
+			outputFormat: "name",
+			expectOut:    "cloneset.apps.kruise.io/foo\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{errs: map[string]error{"bar": fmt.Errorf("boom")}}
+			o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo", "cloneset/bar")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.Quiet = true
+			o.PrintFlags.OutputFormat = &tc.outputFormat
+
+			assert.EqualError(t, o.RunUndo(), "boom")
+			assert.Equal(t, []string{"foo", "bar"}, rollbacker.calls)
+			assert.Equal(t, tc.expectOut, o.Out.(*bytes.Buffer).String())
+			// the failure is reported and counted
+			assert.Equal(t, "undid 1, skipped 0 (duplicates), failed 1\n", o.ErrOut.(*bytes.Buffer).String())
+		})
+	}
+}
+
+// progressingStatusViewer reports a rollout in progress until it was asked doneAfter times.
+type progressingStatusViewer struct {
+	calls     int
+	doneAfter int
+}
+
+func (v *progressingStatusViewer) Status(c kubernetes.Interface, obj runtime.Unstructured, revision int64) (string, bool, error) {
+	v.calls++
+	if v.doneAfter > 0 && v.calls >= v.doneAfter {
+		return fmt.Sprintf("cloneset %q successfully rolled out\n", obj.(*unstructured.Unstructured).GetName()), true, nil
+	}
+	return fmt.Sprintf("Waiting for cloneset %q rollout to finish: %d out of 3 new pods have been updated...\n", obj.(*unstructured.Unstructured).GetName(), v.calls), false, nil
+}
+
+func (v *progressingStatusViewer) DetailStatus(c kubernetes.Interface, obj runtime.Unstructured, detail bool, revision int64) (string, bool, error) {
+	return v.Status(c, obj, revision)
+}
+
+func TestRunUndoWait(t *testing.T) {
+	defer func(interval time.Duration) { waitPollInterval = interval }(waitPollInterval)
+	waitPollInterval = time.Millisecond
+
+	testCases := []struct {
+		name      string
+		doneAfter int
+		timeout   time.Duration
+		expectErr string
+		expectOut string
+	}{
+		{
+			name:      "rollout completes",
+			doneAfter: 3,
+			expectOut: "cloneset.apps.kruise.io/web rolled back\n",
+		},
+		{
+			name:    "rollout does not complete in time",
+			timeout: 50 * time.Millisecond,
+			expectErr: "timed out after 50ms, the remaining targets were not rolled back: " +
+				`clonesets.apps.kruise.io/web was rolled back but did not complete its rollout, last status: Waiting for cloneset "web" rollout to finish: `,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, map[string]runtime.Object{"clonesets/web": newUndoTestCloneSet("web")})
+			defer tf.Cleanup()
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, "cloneset/web")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			viewer := &progressingStatusViewer{doneAfter: tc.doneAfter}
+			o.StatusViewer = func(*meta.RESTMapping) (internalpolymorphichelpers.StatusViewer, error) {
+				return viewer, nil
+			}
+			o.Wait = true
+			o.Timeout = tc.timeout
+
+			err = o.RunUndo()
+			if len(tc.expectErr) > 0 {
+				if assert.Error(t, err) {
+					assert.True(t, strings.HasPrefix(err.Error(), tc.expectErr), err.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.doneAfter, viewer.calls)
+			}
+			assert.Equal(t, tc.expectOut, o.Out.(*bytes.Buffer).String())
+		})
+	}
+}
+
+func TestRunUndoByFieldSelector(t *testing.T) {
+	objs := map[string]runtime.Object{
+		// the fake server only serves the list requested with the field selector
+		"clonesets?fieldSelector=metadata.name%3Db": &kruiseappsv1alpha1.CloneSetList{
+			TypeMeta: metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSetList"},
+			Items:    []kruiseappsv1alpha1.CloneSet{*newUndoTestCloneSet("b")},
+		},
+	}
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	rollbacker := &fakeRollbacker{}
+	o, err := newUndoTestOptions(tf, rollbacker, "cloneset")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o.FieldSelector = "metadata.name=b"
+
+	assert.NoError(t, o.Validate())
+	assert.NoError(t, o.RunUndo())
+	assert.Equal(t, []string{"b"}, rollbacker.calls)
+}
+
+func TestUndoValidateFieldSelector(t *testing.T) {
+	o := NewRolloutUndoOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.FieldSelector = "metadata.name=web"
+	assert.EqualError(t, o.Validate(), "a resource type must be specified along with --field-selector, e.g. cloneset --field-selector metadata.name=web")
+
+	o.Resources = []string{"cloneset"}
+	o.FieldSelector = "metadata.name"
+	assert.EqualError(t, o.Validate(), `invalid --field-selector: invalid selector: 'metadata.name'; can't understand 'metadata.name'`)
+}
+
+func TestRunUndoOutputFile(t *testing.T) {
+	foo, bar := newUndoTestCloneSet("foo"), newUndoTestCloneSet("bar")
+	foo.Labels = map[string]string{"app": "foo"}
+	bar.Spec.MinReadySeconds = 10
+	objs := map[string]runtime.Object{
+		"clonesets/foo": foo,
+		"clonesets/bar": bar,
+	}
+	tf := newUndoTestFactory(t, objs)
+	defer tf.Cleanup()
+
+	rollbacker := &fakeRollbacker{}
+	o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo", "cloneset/bar")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := "yaml"
+	o.PrintFlags.OutputFormat = &output
+	o.OutputFile = filepath.Join(t.TempDir(), "rollback.yaml")
+
+	assert.NoError(t, o.Validate())
+	assert.NoError(t, o.RunUndo())
+
+	data, err := os.ReadFile(o.OutputFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the objects are separated as the documents of a single YAML stream
+	documents := strings.Split(string(data), "---\n")
+	if assert.Len(t, documents, 2) {
+		for i, expected := range []*kruiseappsv1alpha1.CloneSet{foo, bar} {
+			written := &kruiseappsv1alpha1.CloneSet{}
+			assert.NoError(t, yaml.Unmarshal([]byte(documents[i]), written))
+			assert.Equal(t, expected, written)
+		}
+	}
+	// the output of the command is unchanged
+	assert.Equal(t, 2, strings.Count(o.Out.(*bytes.Buffer).String(), "kind: CloneSet\n"))
+}
+
+func TestRunUndoOutputFileErrors(t *testing.T) {
+	tf := newUndoTestFactory(t, map[string]runtime.Object{"clonesets/foo": newUndoTestCloneSet("foo")})
+	defer tf.Cleanup()
+
+	rollbacker := &fakeRollbacker{}
+	o, err := newUndoTestOptions(tf, rollbacker, "cloneset/foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o.OutputFile = filepath.Join(t.TempDir(), "missing", "rollback.yaml")
+
+	// nothing is rolled back when the file cannot be created
+	assert.ErrorContains(t, o.RunUndo(), "failed to create --output-file: open "+o.OutputFile)
+	assert.Empty(t, rollbacker.calls)
+
+	o.DryRunStrategy = cmdutil.DryRunServer
+	assert.EqualError(t, o.Validate(), "--output-file cannot be used with --dry-run, since no object is rolled back")
+}
+
+func TestRunUndoUpdateLastApplied(t *testing.T) {
+	// the template served is the rolled back one, while the annotation records the template applied before
+	applied := `{"apiVersion":"apps.kruise.io/v1alpha1","kind":"CloneSet","metadata":{"name":"web","namespace":"test"},` +
+		`"spec":{"replicas":3,"template":{"metadata":{"labels":{"app":"foo"}},"spec":{"containers":[{"image":"nginx:1.25","name":"main"}]}}}}`
+	web := newUndoTestCloneSet("web")
+	web.Annotations = map[string]string{corev1.LastAppliedConfigAnnotation: applied}
+	web.Spec.Template = *newUndoTestTemplate("nginx:1.24")
+	objs := map[string]runtime.Object{
+		"clonesets/web":   web,
+		"clonesets/other": newUndoTestCloneSet("other"),
+	}
+
+	testCases := []struct {
+		name              string
+		args              []string
+		updateLastApplied bool
+		dryRun            cmdutil.DryRunStrategy
+		expectPatched     bool
+	}{
+		{
+			name:              "annotated workload",
+			args:              []string{"cloneset/web"},
+			updateLastApplied: true,
+			expectPatched:     true,
+		},
+		{
+			name:              "workload without the annotation",
+			args:              []string{"cloneset/other"},
+			updateLastApplied: true,
+		},
+		{
+			name: "without --update-last-applied",
+			args: []string{"cloneset/web"},
+		},
+		{
+			name:              "server dry-run",
+			args:              []string{"cloneset/web"},
+			updateLastApplied: true,
+			dryRun:            cmdutil.DryRunServer,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+			var patches []string
+			get := tf.Client.(*fake.RESTClient).Client
+			tf.Client.(*fake.RESTClient).Client = fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				if req.Method != http.MethodPatch {
+					return get.Do(req)
+				}
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				patches = append(patches, req.URL.Path+"?"+req.URL.RawQuery+" "+string(body))
+				codec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, web)}, nil
+			})
+
+			o, err := newUndoTestOptions(tf, &fakeRollbacker{}, tc.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.UpdateLastApplied = tc.updateLastApplied
+			o.DryRunStrategy = tc.dryRun
+
+			assert.NoError(t, o.RunUndo())
+			if !tc.expectPatched {
+				assert.Empty(t, patches)
+				return
+			}
+			if !assert.Len(t, patches, 1) {
+				return
+			}
+			prefix := "/namespaces/test/clonesets/web?fieldManager=kubectl-kruise-rollout "
+			if !assert.True(t, strings.HasPrefix(patches[0], prefix), patches[0]) {
+				return
+			}
+			patch := &metav1.PartialObjectMetadata{}
+			assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(patches[0], prefix)), patch))
+			updated := &kruiseappsv1alpha1.CloneSet{}
+			assert.NoError(t, json.Unmarshal([]byte(patch.Annotations[corev1.LastAppliedConfigAnnotation]), updated))
+			// the pod template is the rolled back one, the rest is kept as it was applied
+			assert.Equal(t, web.Spec.Template, updated.Spec.Template)
+			if assert.NotNil(t, updated.Spec.Replicas) {
+				assert.Equal(t, int32(3), *updated.Spec.Replicas)
+			}
+			assert.Equal(t, "web", updated.Name)
+		})
+	}
+}
+
+func TestRunUndoFromBackup(t *testing.T) {
+	web := newUndoTestCloneSet("web")
+	web.Spec.Replicas = pointer.Int32(5)
+	web.Spec.Template = *newUndoTestTemplate("nginx:1.25")
+	// the snapshot was taken before the image and the replicas were changed
+	snapshot := `apiVersion: apps.kruise.io/v1alpha1
+kind: CloneSet
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    metadata:
+      labels:
+        app: foo
+    spec:
+      containers:
+      - name: main
+        image: nginx:1.24
+`
+	backup := func(name string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Data:       data,
+		}
+	}
+	objs := map[string]runtime.Object{
+		"clonesets/web": web,
+		"/api/v1/namespaces/test/configmaps/web-snapshot":  backup("web-snapshot", map[string]string{"web": snapshot, "other": "kind: CloneSet"}),
+		"/api/v1/namespaces/test/configmaps/only-snapshot": backup("only-snapshot", map[string]string{"cloneset.yaml": snapshot}),
+		"/api/v1/namespaces/test/configmaps/asts-snapshot": backup("asts-snapshot", map[string]string{
+			"web": strings.Replace(snapshot, "apiVersion: apps.kruise.io/v1alpha1\nkind: CloneSet", "apiVersion: apps.kruise.io/v1beta1\nkind: StatefulSet", 1),
+		}),
+		"/api/v1/namespaces/test/configmaps/many-snapshots": backup("many-snapshots", map[string]string{"a": snapshot, "b": snapshot}),
+	}
+
+	testCases := []struct {
+		name          string
+		fromBackup    string
+		dryRun        cmdutil.DryRunStrategy
+		expectPatched bool
+		expectErr     string
+	}{
+		{
+			name:          "key named after the workload",
+			fromBackup:    "cm/web-snapshot",
+			expectPatched: true,
+		},
+		{
+			name:          "only key of the configmap",
+			fromBackup:    "configmap/only-snapshot",
+			expectPatched: true,
+		},
+		{
+			name:       "client dry-run",
+			fromBackup: "cm/web-snapshot",
+			dryRun:     cmdutil.DryRunClient,
+		},
+		{
+			name:       "backup of another kind",
+			fromBackup: "cm/asts-snapshot",
+			expectErr:  "the key web of configmap asts-snapshot holds a StatefulSet.apps.kruise.io, which cannot be restored to clonesets.apps.kruise.io/web",
+		},
+		{
+			name:       "no key for the workload",
+			fromBackup: "cm/many-snapshots",
+			expectErr:  "configmap many-snapshots has no key web holding the backup of clonesets.apps.kruise.io/web",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := newUndoTestFactory(t, objs)
+			defer tf.Cleanup()
+			var patches [][]byte
+			get := tf.Client.(*fake.RESTClient).Client
+			tf.Client.(*fake.RESTClient).Client = fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				if req.Method != http.MethodPatch {
+					return get.Do(req)
+				}
+				assert.Equal(t, "/namespaces/test/clonesets/web", req.URL.Path)
+				assert.Equal(t, string(types.MergePatchType), req.Header.Get("Content-Type"))
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				patches = append(patches, body)
+				codec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, web)}, nil
+			})
+
+			rollbacker := &fakeRollbacker{}
+			o, err := newUndoTestOptions(tf, rollbacker, "cloneset/web")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o.FromBackup = tc.fromBackup
+			o.DryRunStrategy = tc.dryRun
+			assert.NoError(t, o.Validate())
+
+			err = o.RunUndo()
+			// the revisions of the workload are left out
+			assert.Empty(t, rollbacker.calls)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				assert.Empty(t, patches)
+				return
+			}
+			assert.NoError(t, err)
+			if !tc.expectPatched {
+				assert.Empty(t, patches)
+				return
+			}
+			if !assert.Len(t, patches, 1) {
+				return
+			}
+			restored := &kruiseappsv1alpha1.CloneSet{}
+			assert.NoError(t, json.Unmarshal(patches[0], restored))
+			// only the pod template is restored, the replicas are kept
+			assert.Equal(t, []corev1.Container{{Name: "main", Image: "nginx:1.24"}}, restored.Spec.Template.Spec.Containers)
+			assert.Nil(t, restored.Spec.Replicas)
+		})
+	}
+}
+
+func TestUndoFullFlag(t *testing.T) {
+	testCases := []struct {
+		flag         string
+		expectErrOut string
+	}{
+		{flag: "--full"},
+		{
+			flag:         "--restore-strategy",
+			expectErrOut: "Flag --restore-strategy has been deprecated, --restore-strategy will be removed in the future, please use --to-revision with --full instead\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.flag, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			cmd := NewCmdRolloutUndo(tf, genericclioptions.NewTestIOStreamsDiscard())
+			// cobra prints the deprecation notice of a flag to the output of the command, stderr by default
+			errOut := &bytes.Buffer{}
+			cmd.SetOut(errOut)
+			assert.NoError(t, cmd.ParseFlags([]string{tc.flag}))
+			// --restore-strategy is the deprecated spelling of --full
+			assert.Equal(t, "true", cmd.Flags().Lookup("full").Value.String())
+			assert.Equal(t, tc.expectErrOut, errOut.String())
+		})
+	}
+}
+
+func TestUndoValidateFull(t *testing.T) {
+	testCases := []struct {
+		name       string
+		toRevision string
+		expectErr  string
+	}{
+		{
+			name:      "without --to-revision",
+			expectErr: "--full restores the partition of the revision, it can only be used with --to-revision",
+		},
+		{
+			name:       "with a revision number",
+			toRevision: "3",
+		},
+		{
+			name:       "with the previous revision",
+			toRevision: "previous",
+		},
+		{
+			name:       "with the latest stable revision",
+			toRevision: "latest-stable",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := NewRolloutUndoOptions(genericclioptions.NewTestIOStreamsDiscard())
+			o.Resources = []string{"asts/web"}
+			o.Full = true
+			if len(tc.toRevision) > 0 {
+				assert.NoError(t, (&toRevisionValue{o: o}).Set(tc.toRevision))
+			}
+			err := o.Validate()
+			if tc.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectErr)
+			assert.Equal(t, ExitCodeValidation, ExitCode(validationError(err)))
+		})
+	}
+}
+
+func TestValidateUndoFromBackup(t *testing.T) {
+	testCases := []struct {
+		name       string
+		fromBackup string
+		toRevision int64
+		full       bool
+		expectErr  string
+	}{
+		{
+			name:       "configmap",
+			fromBackup: "configmaps/web-snapshot",
+		},
+		{
+			name:       "not a configmap",
+			fromBackup: "secret/web-snapshot",
+			expectErr:  `--from-backup must be a ConfigMap given as configmap/NAME, e.g. cm/web-snapshot, got "secret/web-snapshot"`,
+		},
+		{
+			name:       "no name",
+			fromBackup: "cm/",
+			expectErr:  `--from-backup must be a ConfigMap given as configmap/NAME, e.g. cm/web-snapshot, got "cm/"`,
+		},
+		{
+			name:       "with a revision",
+			fromBackup: "cm/web-snapshot",
+			toRevision: 2,
+			expectErr:  "--from-backup only restores the pod template of the backup, it cannot be used with --to-revision, --to-image or --full",
+		},
+		{
+			name:       "with --full",
+			fromBackup: "cm/web-snapshot",
+			full:       true,
+			expectErr:  "--from-backup only restores the pod template of the backup, it cannot be used with --to-revision, --to-image or --full",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := NewRolloutUndoOptions(genericclioptions.NewTestIOStreamsDiscard())
+			o.Resources = []string{"cloneset/web"}
+			o.FromBackup = tc.fromBackup
+			o.ToRevision = tc.toRevision
+			o.Full = tc.full
+			err := o.Validate()
+			if tc.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectErr)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	rolloutsapiv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	rolloutsapiv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// rolloutStableRevision returns the stable revision recorded in the status of a v1alpha1 or v1beta1 Rollout, which is
+// the hash of the revision of its workload, or an empty string if none is recorded yet.
+func rolloutStableRevision(obj runtime.Object) string {
+	switch rollout := obj.(type) {
+	case *rolloutsapiv1alpha1.Rollout:
+		if rollout.Status.CanaryStatus != nil {
+			return rollout.Status.CanaryStatus.StableRevision
+		}
+	case *rolloutsapiv1beta1.Rollout:
+		if rollout.Status.CanaryStatus != nil {
+			return rollout.Status.CanaryStatus.StableRevision
+		}
+	}
+	return ""
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scale
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// ScaleOptions is the start of the data required to perform the operation.  As new fields are added, add them here instead of
+// referencing the cmd.Flags()
+type ScaleOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	ToPrinter  func(string) (printers.ResourcePrinter, error)
+
+	Builder            func() *resource.Builder
+	Replicas           int
+	CurrentReplicas    int
+	ResourceVersion    string
+	KeepPartitionRatio bool
+	Rebalance          bool
+	Subset             string
+	All                bool
+	DryRunStrategy     cmdutil.DryRunStrategy
+	Resources          []string
+	LabelSelector      string
+	Namespace          string
+	EnforceNamespace   bool
+	RESTMapper         meta.RESTMapper
+	ClientForMapping   func(*meta.RESTMapping) (resource.RESTClient, error)
+
+	// workloadSpreadsByNamespace caches the WorkloadSpreads listed in a namespace
+	workloadSpreadsByNamespace map[string][]*kruiseappsv1alpha1.WorkloadSpread
+
+	resource.FilenameOptions
+	genericclioptions.IOStreams
+}
+
+var (
+	scaleLong = templates.LongDesc(`
+		Set a new size for a Deployment, ReplicaSet, CloneSet, or Advanced StatefulSet.
+
+		Scale also allows users to specify one or more preconditions for the scale action.
+		If --current-replicas or --resource-version is specified, it is validated before the
+		scale is attempted, and it is guaranteed that the precondition holds true when the
+		scale is sent to the server.
+
+		The partition of a CloneSet is the number of pods kept at the old revision during an
+		update. With --keep-partition-ratio an integer partition is scaled along with the
+		replicas, so the same share of pods stays at the old revision. Otherwise a warning is
+		printed when the replicas drop below the partition, since no pod would be updated.
+
+		A warning is printed as well when the subsets of a WorkloadSpread of the workload cannot
+		hold the new replicas. With --rebalance the integer maxReplicas of the subsets are scaled
+		along with the replicas instead, and the last of them is raised until the subsets hold all
+		the replicas. The WorkloadSpread is patched once the workload is scaled.
+
+		With --subset the replicas of a subset of a UnitedDeployment are set instead of the replicas
+		of the UnitedDeployment itself. The subset is looked up by name in spec.topology.subsets.`)
+
+	scaleExample = templates.Examples(`
+		# Scale a cloneset named 'web' to 10
+		kubectl-kruise scale --replicas=10 cloneset/web
+
+		# Scale cloneset 'web' to 10 and keep the share of pods held back by its partition
+		kubectl-kruise scale --replicas=10 --keep-partition-ratio cloneset/web
+
+		# Scale cloneset 'web' to 20 and raise the maxReplicas of the subsets of its workloadspread
+		kubectl-kruise scale --replicas=20 --rebalance cloneset/web
+
+		# Scale the beijing subset of uniteddeployment 'web' to 5
+		kubectl-kruise scale --replicas=5 --subset=beijing uniteddeployment/web
+
+		# If the advanced statefulset named mysql's current size is 2, scale mysql to 3
+		kubectl-kruise scale --current-replicas=2 --replicas=3 statefulsets.apps.kruise.io/mysql
+
+		# Scale all clonesets labeled with app=nginx to 3
+		kubectl-kruise scale --replicas=3 cloneset -l app=nginx`)
+)
+
+// NewScaleOptions returns an initialized ScaleOptions instance
+func NewScaleOptions(streams genericclioptions.IOStreams) *ScaleOptions {
+	return &ScaleOptions{
+		PrintFlags:      genericclioptions.NewPrintFlags("scaled").WithTypeSetter(internalapi.GetScheme()),
+		CurrentReplicas: -1,
+		IOStreams:       streams,
+	}
+}
+
+// NewCmdScale returns a Command instance for the 'scale' command
+func NewCmdScale(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewScaleOptions(streams)
+
+	validArgs := []string{"deployment", "replicaset", "statefulset", "cloneset", "advanced statefulset", "uniteddeployment"}
+
+	cmd := &cobra.Command{
+		Use:                   "scale [--resource-version=version] [--current-replicas=count] --replicas=COUNT (-f FILENAME | TYPE NAME)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Set a new size for a Deployment, ReplicaSet, CloneSet, or Advanced StatefulSet"),
+		Long:                  scaleLong,
+		Example:               scaleExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.RunScale())
+		},
+		ValidArgs: validArgs,
+	}
+
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "Select all resources in the namespace of the specified resource types")
+	cmd.Flags().StringVar(&o.ResourceVersion, "resource-version", o.ResourceVersion, i18n.T("Precondition for resource version. Requires that the current resource version match this value in order to scale."))
+	cmd.Flags().IntVar(&o.CurrentReplicas, "current-replicas", o.CurrentReplicas, "Precondition for current size. Requires that the current size of the resource match this value in order to scale. -1 (default) for no condition.")
+	cmd.Flags().IntVar(&o.Replicas, "replicas", o.Replicas, "The new desired number of replicas. Required.")
+	cmd.MarkFlagRequired("replicas")
+	cmd.Flags().BoolVar(&o.KeepPartitionRatio, "keep-partition-ratio", o.KeepPartitionRatio, "Scale the integer partition of a CloneSet in proportion to its replicas.")
+	cmd.Flags().BoolVar(&o.Rebalance, "rebalance", o.Rebalance, "Scale the maxReplicas of the subsets of a WorkloadSpread of the workload if they cannot hold the new replicas.")
+	cmd.Flags().StringVar(&o.Subset, "subset", o.Subset, "The name of the subset of a UnitedDeployment to set the replicas of, instead of the UnitedDeployment itself.")
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "identifying the resource to set a new size")
+	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
+	cmdutil.AddDryRunFlag(cmd)
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *ScaleOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.Resources = args
+	var err error
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	if o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+
+	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
+		o.PrintFlags.NamePrintFlags.Operation = operation
+		cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+		return o.PrintFlags.ToPrinter()
+	}
+
+	if o.RESTMapper, err = f.ToRESTMapper(); err != nil {
+		return err
+	}
+	o.ClientForMapping = f.ClientForMapping
+	o.Builder = f.NewBuilder
+
+	return nil
+}
+
+func (o *ScaleOptions) Validate() error {
+	if len(o.Resources) == 0 && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
+		if len(o.LabelSelector) > 0 {
+			return fmt.Errorf("a resource type must be specified along with --selector, e.g. cloneset -l app=nginx")
+		}
+		return fmt.Errorf("required resource not specified")
+	}
+	if o.Replicas < 0 {
+		return fmt.Errorf("the --replicas=COUNT flag is required, and COUNT must be greater than or equal to 0")
+	}
+	if o.CurrentReplicas < -1 {
+		return fmt.Errorf("the --current-replicas must specify an integer of -1 or greater")
+	}
+	return nil
+}
+
+// RunScale performs the execution of 'scale' command
+func (o *ScaleOptions) RunScale() error {
+	r := o.Builder().
+		WithScheme(internalapi.GetScheme(), scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
+		LabelSelectorParam(o.LabelSelector).
+		ResourceTypeOrNameArgs(o.All, o.Resources...).
+		ContinueOnError().
+		Latest().
+		Flatten().
+		Do()
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	var allErrs []error
+	infos, err := r.Infos()
+	if err != nil {
+		// proceed with the valid resources, and report the broken ones at the end
+		allErrs = append(allErrs, err)
+	}
+	if len(o.ResourceVersion) != 0 && len(infos) > 1 {
+		return fmt.Errorf("cannot use --resource-version with multiple resources")
+	}
+	if len(infos) == 0 && len(allErrs) == 0 {
+		return fmt.Errorf("no objects passed to scale")
+	}
+
+	for _, info := range infos {
+		if err := o.scaleInfo(info); err != nil {
+			allErrs = append(allErrs, fmt.Errorf("error: %s/%s %v", info.Mapping.Resource.Resource, info.Name, err))
+		}
+	}
+
+	return utilerrors.NewAggregate(allErrs)
+}
+
+func (o *ScaleOptions) scaleInfo(info *resource.Info) error {
+	if len(o.ResourceVersion) != 0 && info.ResourceVersion != o.ResourceVersion {
+		return fmt.Errorf("expected resource version %s, got %s", o.ResourceVersion, info.ResourceVersion)
+	}
+
+	before := info.Object.DeepCopyObject()
+	after := info.Object.DeepCopyObject()
+	err := o.scaleObject(info, after)
+	if err != nil {
+		return err
+	}
+	// the subsets are rebalanced before anything is patched, so that a WorkloadSpread which cannot be rebalanced fails
+	// the scale, but only patched once the workload is scaled, so that a failed scale leaves them alone. The replicas
+	// of a subset of a UnitedDeployment are only part of the replicas a WorkloadSpread would spread.
+	var rebalances []spreadRebalance
+	if len(o.Subset) == 0 {
+		if rebalances, err = o.checkWorkloadSpreads(info); err != nil {
+			return err
+		}
+	}
+
+	if len(o.ResourceVersion) != 0 || o.CurrentReplicas != -1 {
+		// the resource version is part of the patch, so the server rejects it if the object changed since the
+		// preconditions were checked
+		accessor, err := meta.Accessor(before)
+		if err != nil {
+			return err
+		}
+		accessor.SetResourceVersion("")
+	}
+	oldData, err := runtime.Encode(scheme.DefaultJSONEncoder(), before)
+	if err != nil {
+		return err
+	}
+	newData, err := runtime.Encode(scheme.DefaultJSONEncoder(), after)
+	if err != nil {
+		return err
+	}
+	patch, err := jsonpatch.CreateMergePatch(oldData, newData)
+	if err != nil {
+		return err
+	}
+
+	obj := after
+	if o.DryRunStrategy != cmdutil.DryRunClient && string(patch) != "{}" {
+		obj, err = resource.NewHelper(info.Client, info.Mapping).
+			DryRun(o.DryRunStrategy == cmdutil.DryRunServer).
+			Patch(info.Namespace, info.Name, types.MergePatchType, patch, nil)
+		if err != nil {
+			return fmt.Errorf("failed to patch: %v", err)
+		}
+	}
+	if err = info.Refresh(obj, true); err != nil {
+		return err
+	}
+
+	printer, err := o.ToPrinter("scaled")
+	if err != nil {
+		return err
+	}
+	if err := printer.PrintObj(info.Object, o.Out); err != nil {
+		return err
+	}
+	for _, r := range rebalances {
+		if err := o.patchWorkloadSpread(r); err != nil {
+			return fmt.Errorf("scaled, but failed to rebalance workloadspread %q: %v", r.ws.Name, err)
+		}
+	}
+	return nil
+}
+
+// scaleObject sets the replicas of obj, and keeps the partition of a CloneSet in line with them.
+func (o *ScaleOptions) scaleObject(info *resource.Info, obj runtime.Object) error {
+	replicas := int32(o.Replicas)
+
+	if len(o.Subset) > 0 {
+		ud, ok := obj.(*kruiseappsv1alpha1.UnitedDeployment)
+		if !ok {
+			return fmt.Errorf("--subset is only supported by uniteddeployments")
+		}
+		return o.scaleSubset(ud)
+	}
+
+	switch obj := obj.(type) {
+	case *kruiseappsv1alpha1.CloneSet:
+		// an unset replicas defaults to 1
+		current := int32(1)
+		if obj.Spec.Replicas != nil {
+			current = *obj.Spec.Replicas
+		}
+		if o.CurrentReplicas != -1 && int32(o.CurrentReplicas) != current {
+			return fmt.Errorf("expected replicas to be %d, was %d", o.CurrentReplicas, current)
+		}
+		obj.Spec.Replicas = &replicas
+
+		// a percentage partition is relative to the replicas already
+		partition := obj.Spec.UpdateStrategy.Partition
+		if partition == nil || partition.Type != intstr.Int {
+			return nil
+		}
+		if o.KeepPartitionRatio && current > 0 {
+			ratio := float64(partition.IntVal) / float64(current)
+			scaled := intstr.FromInt(int(math.Round(ratio * float64(replicas))))
+			obj.Spec.UpdateStrategy.Partition = &scaled
+			return nil
+		}
+		if replicas < partition.IntVal {
+			fmt.Fprintf(o.ErrOut, "Warning: %s/%s is scaled to %d replicas, below its partition %d, so no pods will be updated to the latest revision\n",
+				info.Mapping.Resource.Resource, info.Name, replicas, partition.IntVal)
+		}
+		return nil
+	default:
+		// other workloads only have their spec.replicas set
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		current, found, err := unstructured.NestedInt64(content, "spec", "replicas")
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("scaling is not supported")
+		}
+		if o.CurrentReplicas != -1 && int64(o.CurrentReplicas) != current {
+			return fmt.Errorf("expected replicas to be %d, was %d", o.CurrentReplicas, current)
+		}
+		if err = unstructured.SetNestedField(content, int64(replicas), "spec", "replicas"); err != nil {
+			return err
+		}
+		return runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj)
+	}
+}
+
+// scaleSubset sets the replicas of the subset of ud named by --subset.
+func (o *ScaleOptions) scaleSubset(ud *kruiseappsv1alpha1.UnitedDeployment) error {
+	var names []string
+	for i := range ud.Spec.Topology.Subsets {
+		subset := &ud.Spec.Topology.Subsets[i]
+		if subset.Name != o.Subset {
+			names = append(names, subset.Name)
+			continue
+		}
+		if o.CurrentReplicas != -1 {
+			// a subset without replicas, or with a percentage, does not have a size of its own
+			current := "unset"
+			if subset.Replicas != nil {
+				current = subset.Replicas.String()
+			}
+			if subset.Replicas == nil || subset.Replicas.Type != intstr.Int || int(subset.Replicas.IntVal) != o.CurrentReplicas {
+				return fmt.Errorf("expected replicas of subset %q to be %d, was %s", o.Subset, o.CurrentReplicas, current)
+			}
+		}
+		replicas := intstr.FromInt(o.Replicas)
+		subset.Replicas = &replicas
+		return nil
+	}
+	return fmt.Errorf("unknown subset %q, valid subsets are: %s", o.Subset, strings.Join(names, ", "))
+}
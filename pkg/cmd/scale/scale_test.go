@@ -0,0 +1,481 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scale
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/utils/pointer"
+)
+
+func newTestCloneSet(replicas int32, partition *intstr.IntOrString) *kruiseappsv1alpha1.CloneSet {
+	return &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test", ResourceVersion: "10"},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Replicas:       pointer.Int32(replicas),
+			UpdateStrategy: kruiseappsv1alpha1.CloneSetUpdateStrategy{Partition: partition},
+		},
+	}
+}
+
+func newScaleTestFactory(t *testing.T, path string, codec runtime.Codec, obj runtime.Object, patches *[]string) *cmdtesting.TestFactory {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Group: "apps.kruise.io", Version: "v1alpha1"},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == path && m == http.MethodGet:
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+			case p == path && m == http.MethodPatch:
+				assert.Equal(t, string(types.MergePatchType), req.Header.Get("Content-Type"))
+				data, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				*patches = append(*patches, string(data))
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+			case p == "/namespaces/test/workloadspreads" && m == http.MethodGet:
+				// no workloadspread targets the workload
+				spreadCodec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(spreadCodec, &kruiseappsv1alpha1.WorkloadSpreadList{})}, nil
+			default:
+				t.Fatalf("unexpected request: %s %s", m, p)
+				return nil, nil
+			}
+		}),
+	}
+	return tf
+}
+
+func TestRunScaleCloneSet(t *testing.T) {
+	percent := intstr.FromString("40%")
+
+	testCases := []struct {
+		name               string
+		cloneSet           *kruiseappsv1alpha1.CloneSet
+		replicas           int
+		currentReplicas    int
+		resourceVersion    string
+		keepPartitionRatio bool
+		dryRun             cmdutil.DryRunStrategy
+		expectPatches      []string
+		expectOut          string
+		expectErrOut       string
+		expectErr          string
+	}{
+		{
+			name:          "scale up",
+			cloneSet:      newTestCloneSet(5, intOrStrPtr(2)),
+			replicas:      10,
+			expectPatches: []string{`{"spec":{"replicas":10}}`},
+			expectOut:     "cloneset.apps.kruise.io/web scaled\n",
+		},
+		{
+			name:          "scale down crossing partition",
+			cloneSet:      newTestCloneSet(5, intOrStrPtr(3)),
+			replicas:      2,
+			expectPatches: []string{`{"spec":{"replicas":2}}`},
+			expectOut:     "cloneset.apps.kruise.io/web scaled\n",
+			expectErrOut:  "Warning: clonesets/web is scaled to 2 replicas, below its partition 3, so no pods will be updated to the latest revision\n",
+		},
+		{
+			name:          "scale down to partition",
+			cloneSet:      newTestCloneSet(5, intOrStrPtr(3)),
+			replicas:      3,
+			expectPatches: []string{`{"spec":{"replicas":3}}`},
+			expectOut:     "cloneset.apps.kruise.io/web scaled\n",
+		},
+		{
+			name:               "keep partition ratio on scale down",
+			cloneSet:           newTestCloneSet(10, intOrStrPtr(4)),
+			replicas:           5,
+			keepPartitionRatio: true,
+			expectPatches:      []string{`{"spec":{"replicas":5,"updateStrategy":{"partition":2}}}`},
+			expectOut:          "cloneset.apps.kruise.io/web scaled\n",
+		},
+		{
+			name:               "keep partition ratio on scale up",
+			cloneSet:           newTestCloneSet(4, intOrStrPtr(3)),
+			replicas:           10,
+			keepPartitionRatio: true,
+			expectPatches:      []string{`{"spec":{"replicas":10,"updateStrategy":{"partition":8}}}`},
+			expectOut:          "cloneset.apps.kruise.io/web scaled\n",
+		},
+		{
+			name:               "keep partition ratio leaves a percentage alone",
+			cloneSet:           newTestCloneSet(10, &percent),
+			replicas:           5,
+			keepPartitionRatio: true,
+			expectPatches:      []string{`{"spec":{"replicas":5}}`},
+			expectOut:          "cloneset.apps.kruise.io/web scaled\n",
+		},
+		{
+			name:      "client dry-run does not patch",
+			cloneSet:  newTestCloneSet(5, nil),
+			replicas:  3,
+			dryRun:    cmdutil.DryRunClient,
+			expectOut: "cloneset.apps.kruise.io/web scaled (dry run)\n",
+		},
+		{
+			name:            "current replicas precondition",
+			cloneSet:        newTestCloneSet(5, nil),
+			replicas:        3,
+			currentReplicas: 4,
+			expectErr:       "error: clonesets/web expected replicas to be 4, was 5",
+		},
+		{
+			name:            "current replicas precondition holds",
+			cloneSet:        newTestCloneSet(5, nil),
+			replicas:        3,
+			currentReplicas: 5,
+			expectPatches:   []string{`{"metadata":{"resourceVersion":"10"},"spec":{"replicas":3}}`},
+			expectOut:       "cloneset.apps.kruise.io/web scaled\n",
+		},
+		{
+			name:            "resource version precondition",
+			cloneSet:        newTestCloneSet(5, nil),
+			replicas:        3,
+			resourceVersion: "10",
+			expectPatches:   []string{`{"metadata":{"resourceVersion":"10"},"spec":{"replicas":3}}`},
+			expectOut:       "cloneset.apps.kruise.io/web scaled\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var patches []string
+			codec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+			tf := newScaleTestFactory(t, "/namespaces/test/clonesets/web", codec, tc.cloneSet, &patches)
+			defer tf.Cleanup()
+
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdScale(tf, streams)
+			o := NewScaleOptions(streams)
+			assert.NoError(t, o.Complete(tf, cmd, []string{"cloneset/web"}))
+			o.Replicas = tc.replicas
+			o.CurrentReplicas = -1
+			if tc.currentReplicas != 0 {
+				o.CurrentReplicas = tc.currentReplicas
+			}
+			o.ResourceVersion = tc.resourceVersion
+			o.KeepPartitionRatio = tc.keepPartitionRatio
+			o.DryRunStrategy = tc.dryRun
+			assert.NoError(t, o.Validate())
+
+			err := o.RunScale()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectPatches, patches)
+			assert.Equal(t, tc.expectOut, out.String())
+			assert.Equal(t, tc.expectErrOut, errOut.String())
+		})
+	}
+}
+
+func TestRunScaleDeployment(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(1)},
+	}
+
+	var patches []string
+	tf := newScaleTestFactory(t, "/namespaces/test/deployments/web", scheme.Codecs.LegacyCodec(appsv1.SchemeGroupVersion), deploy, &patches)
+	defer tf.Cleanup()
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdScale(tf, streams)
+	o := NewScaleOptions(streams)
+	assert.NoError(t, o.Complete(tf, cmd, []string{"deployment/web"}))
+	o.Replicas = 3
+
+	assert.NoError(t, o.RunScale())
+	assert.Equal(t, []string{`{"spec":{"replicas":3}}`}, patches)
+	assert.Equal(t, "deployment.apps/web scaled\n", out.String())
+}
+
+func TestRunScaleUnitedDeploymentSubset(t *testing.T) {
+	ud := &kruiseappsv1alpha1.UnitedDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec: kruiseappsv1alpha1.UnitedDeploymentSpec{
+			Replicas: pointer.Int32(10),
+			Topology: kruiseappsv1alpha1.Topology{Subsets: []kruiseappsv1alpha1.Subset{
+				{Name: "beijing", Replicas: intOrStrPtr(2)},
+				{Name: "hangzhou"},
+			}},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		subset          string
+		currentReplicas int
+		dryRun          cmdutil.DryRunStrategy
+		expectPatches   []string
+		expectOut       string
+		expectErr       string
+	}{
+		{
+			name:            "scale a subset",
+			subset:          "beijing",
+			currentReplicas: -1,
+			expectPatches:   []string{`{"spec":{"topology":{"subsets":[{"name":"beijing","nodeSelectorTerm":{},"patch":null,"replicas":5},{"name":"hangzhou","nodeSelectorTerm":{},"patch":null}]}}}`},
+			expectOut:       "uniteddeployment.apps.kruise.io/web scaled\n",
+		},
+		{
+			name:            "scale a subset with client dry-run",
+			subset:          "beijing",
+			currentReplicas: -1,
+			dryRun:          cmdutil.DryRunClient,
+			expectOut:       "uniteddeployment.apps.kruise.io/web scaled (dry run)\n",
+		},
+		{
+			name:            "unknown subset",
+			subset:          "shanghai",
+			currentReplicas: -1,
+			expectErr:       `error: uniteddeployments/web unknown subset "shanghai", valid subsets are: beijing, hangzhou`,
+		},
+		{
+			name:            "current replicas of a subset without replicas",
+			subset:          "hangzhou",
+			currentReplicas: 3,
+			expectErr:       `error: uniteddeployments/web expected replicas of subset "hangzhou" to be 3, was unset`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var patches []string
+			tf := newScaleTestFactory(t, "/namespaces/test/uniteddeployments/web", scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion), ud, &patches)
+			defer tf.Cleanup()
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdScale(tf, streams)
+			o := NewScaleOptions(streams)
+			assert.NoError(t, o.Complete(tf, cmd, []string{"uniteddeployment/web"}))
+			o.Replicas = 5
+			o.Subset = tc.subset
+			o.CurrentReplicas = tc.currentReplicas
+			o.DryRunStrategy = tc.dryRun
+
+			err := o.RunScale()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectPatches, patches)
+			assert.Equal(t, tc.expectOut, out.String())
+		})
+	}
+}
+
+func TestRunScaleSubsetRequiresUnitedDeployment(t *testing.T) {
+	var patches []string
+	tf := newScaleTestFactory(t, "/namespaces/test/clonesets/web", scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion), newTestCloneSet(5, nil), &patches)
+	defer tf.Cleanup()
+
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdScale(tf, streams)
+	o := NewScaleOptions(streams)
+	assert.NoError(t, o.Complete(tf, cmd, []string{"cloneset/web"}))
+	o.Replicas = 5
+	o.Subset = "beijing"
+
+	assert.EqualError(t, o.RunScale(), "error: clonesets/web --subset is only supported by uniteddeployments")
+	assert.Empty(t, patches)
+}
+
+func TestRunScaleWorkloadSpread(t *testing.T) {
+	newSpread := func(target string, maxReplicas ...*intstr.IntOrString) kruiseappsv1alpha1.WorkloadSpread {
+		ws := kruiseappsv1alpha1.WorkloadSpread{
+			ObjectMeta: metav1.ObjectMeta{Name: "ws", Namespace: "test"},
+			Spec: kruiseappsv1alpha1.WorkloadSpreadSpec{
+				TargetReference: &kruiseappsv1alpha1.TargetReference{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet", Name: target},
+			},
+		}
+		for i, m := range maxReplicas {
+			ws.Spec.Subsets = append(ws.Spec.Subsets, kruiseappsv1alpha1.WorkloadSpreadSubset{Name: string(rune('a' + i)), MaxReplicas: m})
+		}
+		return ws
+	}
+	percent := func(v string) *intstr.IntOrString {
+		p := intstr.FromString(v)
+		return &p
+	}
+
+	testCases := []struct {
+		name                string
+		current             int32
+		replicas            int
+		spread              kruiseappsv1alpha1.WorkloadSpread
+		rebalance           bool
+		expectPatches       []string
+		expectSpreadPatches []string
+		expectOut           string
+		expectErrOut        string
+		expectErr           string
+	}{
+		{
+			name:          "no workloadspread targets the cloneset",
+			current:       5,
+			replicas:      10,
+			spread:        newSpread("api", intOrStrPtr(2), intOrStrPtr(3)),
+			expectPatches: []string{`{"spec":{"replicas":10}}`},
+			expectOut:     "cloneset.apps.kruise.io/web scaled\n",
+		},
+		{
+			name:      "subsets hold the new replicas",
+			current:   5,
+			replicas:  5,
+			spread:    newSpread("web", intOrStrPtr(2), intOrStrPtr(3)),
+			expectOut: "cloneset.apps.kruise.io/web scaled\n",
+		},
+		{
+			name:          "last subset is unlimited",
+			current:       5,
+			replicas:      10,
+			spread:        newSpread("web", intOrStrPtr(2), nil),
+			expectPatches: []string{`{"spec":{"replicas":10}}`},
+			expectOut:     "cloneset.apps.kruise.io/web scaled\n",
+		},
+		{
+			name:          "overflow warning",
+			current:       5,
+			replicas:      10,
+			spread:        newSpread("web", intOrStrPtr(2), intOrStrPtr(3)),
+			expectPatches: []string{`{"spec":{"replicas":10}}`},
+			expectOut:     "cloneset.apps.kruise.io/web scaled\n",
+			expectErrOut:  "Warning: clonesets/web is scaled to 10 replicas, but the subsets of workloadspread \"ws\" can only hold 5 of them, use --rebalance to raise their maxReplicas\n",
+		},
+		{
+			name:          "overflow warning with percentages",
+			current:       10,
+			replicas:      20,
+			spread:        newSpread("web", percent("20%"), percent("30%")),
+			expectPatches: []string{`{"spec":{"replicas":20}}`},
+			expectOut:     "cloneset.apps.kruise.io/web scaled\n",
+			expectErrOut:  "Warning: clonesets/web is scaled to 20 replicas, but the subsets of workloadspread \"ws\" can only hold 10 of them, use --rebalance to raise their maxReplicas\n",
+		},
+		{
+			name:                "rebalance in proportion",
+			current:             5,
+			replicas:            10,
+			spread:              newSpread("web", intOrStrPtr(2), intOrStrPtr(3)),
+			rebalance:           true,
+			expectPatches:       []string{`{"spec":{"replicas":10}}`},
+			expectSpreadPatches: []string{`{"spec":{"subsets":[{"maxReplicas":4,"name":"a","patch":null},{"maxReplicas":6,"name":"b","patch":null}]}}`},
+			expectOut:           "cloneset.apps.kruise.io/web scaled\nworkloadspread.apps.kruise.io/ws rebalanced\n",
+		},
+		{
+			name:                "rebalance raises the last subset after rounding",
+			current:             3,
+			replicas:            10,
+			spread:              newSpread("web", intOrStrPtr(1), intOrStrPtr(1), percent("10%")),
+			rebalance:           true,
+			expectPatches:       []string{`{"spec":{"replicas":10}}`},
+			expectSpreadPatches: []string{`{"spec":{"subsets":[{"maxReplicas":3,"name":"a","patch":null},{"maxReplicas":6,"name":"b","patch":null},{"maxReplicas":"10%","name":"c","patch":null}]}}`},
+			expectOut:           "cloneset.apps.kruise.io/web scaled\nworkloadspread.apps.kruise.io/ws rebalanced\n",
+		},
+		{
+			name:      "rebalance percentages only",
+			current:   10,
+			replicas:  20,
+			spread:    newSpread("web", percent("20%"), percent("30%")),
+			rebalance: true,
+			expectErr: `error: clonesets/web failed to rebalance workloadspread "ws": its subsets only have percentage maxReplicas`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var patches, spreadPatches []string
+			codec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+			tf := newScaleTestFactory(t, "/namespaces/test/clonesets/web", codec, newTestCloneSet(tc.current, nil), &patches)
+			defer tf.Cleanup()
+			client := tf.Client.(*fake.RESTClient).Client
+			tf.Client.(*fake.RESTClient).Client = fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				switch p, m := req.URL.Path, req.Method; {
+				case p == "/namespaces/test/workloadspreads" && m == http.MethodGet:
+					list := &kruiseappsv1alpha1.WorkloadSpreadList{Items: []kruiseappsv1alpha1.WorkloadSpread{tc.spread}}
+					return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, list)}, nil
+				case p == "/namespaces/test/workloadspreads/ws" && m == http.MethodPatch:
+					data, err := io.ReadAll(req.Body)
+					if err != nil {
+						return nil, err
+					}
+					spreadPatches = append(spreadPatches, string(data))
+					return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &tc.spread)}, nil
+				}
+				return client.Do(req)
+			})
+
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdScale(tf, streams)
+			o := NewScaleOptions(streams)
+			assert.NoError(t, o.Complete(tf, cmd, []string{"cloneset/web"}))
+			o.Replicas = tc.replicas
+			o.Rebalance = tc.rebalance
+			assert.NoError(t, o.Validate())
+
+			err := o.RunScale()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectPatches, patches)
+			assert.Equal(t, tc.expectSpreadPatches, spreadPatches)
+			assert.Equal(t, tc.expectOut, out.String())
+			assert.Equal(t, tc.expectErrOut, errOut.String())
+		})
+	}
+}
+
+func TestScaleValidate(t *testing.T) {
+	o := &ScaleOptions{LabelSelector: "app=web"}
+	assert.EqualError(t, o.Validate(), "a resource type must be specified along with --selector, e.g. cloneset -l app=nginx")
+
+	o = &ScaleOptions{Resources: []string{"cloneset/web"}, Replicas: -1}
+	assert.EqualError(t, o.Validate(), "the --replicas=COUNT flag is required, and COUNT must be greater than or equal to 0")
+
+	o = &ScaleOptions{Resources: []string{"cloneset/web"}, CurrentReplicas: -2}
+	assert.EqualError(t, o.Validate(), "the --current-replicas must specify an integer of -1 or greater")
+}
+
+func intOrStrPtr(i int) *intstr.IntOrString {
+	v := intstr.FromInt(i)
+	return &v
+}
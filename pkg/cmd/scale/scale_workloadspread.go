@@ -0,0 +1,238 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scale
+
+import (
+	"fmt"
+	"math"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+// spreadRebalance is a WorkloadSpread of a workload, and the same WorkloadSpread with its subsets rebalanced to the
+// new replicas of the workload.
+type spreadRebalance struct {
+	ws, rebalanced *kruiseappsv1alpha1.WorkloadSpread
+}
+
+// checkWorkloadSpreads warns about the WorkloadSpreads of the workload of info whose subsets cannot hold the new
+// replicas. With --rebalance, it returns them with their subsets rebalanced instead, which are patched once the
+// workload is scaled. Workloads without a WorkloadSpread are not affected.
+func (o *ScaleOptions) checkWorkloadSpreads(info *resource.Info) ([]spreadRebalance, error) {
+	spreads := o.workloadSpreads(info)
+	if len(spreads) == 0 {
+		return nil, nil
+	}
+	current, err := currentReplicas(info.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	var rebalances []spreadRebalance
+	for _, ws := range spreads {
+		capacity, limited, err := subsetCapacity(ws, o.Replicas)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxReplicas in workloadspread %q: %v", ws.Name, err)
+		}
+		if !limited || capacity >= o.Replicas {
+			continue
+		}
+		if !o.Rebalance {
+			fmt.Fprintf(o.ErrOut, "Warning: %s/%s is scaled to %d replicas, but the subsets of workloadspread %q can only hold %d of them, use --rebalance to raise their maxReplicas\n",
+				info.Mapping.Resource.Resource, info.Name, o.Replicas, ws.Name, capacity)
+			continue
+		}
+		rebalanced := ws.DeepCopy()
+		if err := rebalanceSubsets(rebalanced, current, o.Replicas); err != nil {
+			return nil, fmt.Errorf("failed to rebalance workloadspread %q: %v", ws.Name, err)
+		}
+		rebalances = append(rebalances, spreadRebalance{ws: ws, rebalanced: rebalanced})
+	}
+	return rebalances, nil
+}
+
+// workloadSpreads returns the WorkloadSpreads targeting the workload of info. A namespace is only listed by the first
+// workload in it; when the list fails, e.g. without the WorkloadSpread CRD, the workloads there are scaled unchecked.
+func (o *ScaleOptions) workloadSpreads(info *resource.Info) []*kruiseappsv1alpha1.WorkloadSpread {
+	spreads, ok := o.workloadSpreadsByNamespace[info.Namespace]
+	if !ok {
+		var err error
+		if spreads, err = o.listWorkloadSpreads(info.Namespace); err != nil {
+			klog.V(4).Infof("Not checking the workloadspreads in namespace %s, they cannot be listed: %v", info.Namespace, err)
+		}
+		if o.workloadSpreadsByNamespace == nil {
+			o.workloadSpreadsByNamespace = map[string][]*kruiseappsv1alpha1.WorkloadSpread{}
+		}
+		o.workloadSpreadsByNamespace[info.Namespace] = spreads
+	}
+
+	var targeting []*kruiseappsv1alpha1.WorkloadSpread
+	gvk := info.Mapping.GroupVersionKind
+	for _, ws := range spreads {
+		ref := ws.Spec.TargetReference
+		if ref == nil || ref.Kind != gvk.Kind || ref.Name != info.Name {
+			continue
+		}
+		if gv, err := schema.ParseGroupVersion(ref.APIVersion); err == nil && gv.Group == gvk.Group {
+			targeting = append(targeting, ws)
+		}
+	}
+	return targeting
+}
+
+func (o *ScaleOptions) listWorkloadSpreads(namespace string) ([]*kruiseappsv1alpha1.WorkloadSpread, error) {
+	helper, err := o.workloadSpreadHelper()
+	if err != nil {
+		return nil, err
+	}
+	obj, err := helper.List(namespace, kruiseappsv1alpha1.SchemeGroupVersion.String(), &metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	list, ok := obj.(*kruiseappsv1alpha1.WorkloadSpreadList)
+	if !ok {
+		return nil, fmt.Errorf("unexpected list type %T", obj)
+	}
+	spreads := make([]*kruiseappsv1alpha1.WorkloadSpread, 0, len(list.Items))
+	for i := range list.Items {
+		spreads = append(spreads, &list.Items[i])
+	}
+	return spreads, nil
+}
+
+func (o *ScaleOptions) workloadSpreadHelper() (*resource.Helper, error) {
+	gvk := kruiseappsv1alpha1.SchemeGroupVersion.WithKind("WorkloadSpread")
+	mapping, err := o.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	client, err := o.ClientForMapping(mapping)
+	if err != nil {
+		return nil, err
+	}
+	return resource.NewHelper(client, mapping), nil
+}
+
+// patchWorkloadSpread patches the WorkloadSpread of r with its rebalanced subsets, unless in a client dry-run.
+func (o *ScaleOptions) patchWorkloadSpread(r spreadRebalance) error {
+	ws, rebalanced := r.ws, r.rebalanced
+	obj := runtime.Object(rebalanced)
+	if o.DryRunStrategy != cmdutil.DryRunClient {
+		oldData, err := runtime.Encode(scheme.DefaultJSONEncoder(), ws)
+		if err != nil {
+			return err
+		}
+		newData, err := runtime.Encode(scheme.DefaultJSONEncoder(), rebalanced)
+		if err != nil {
+			return err
+		}
+		patch, err := jsonpatch.CreateMergePatch(oldData, newData)
+		if err != nil {
+			return err
+		}
+		helper, err := o.workloadSpreadHelper()
+		if err != nil {
+			return err
+		}
+		if obj, err = helper.DryRun(o.DryRunStrategy == cmdutil.DryRunServer).
+			Patch(ws.Namespace, ws.Name, types.MergePatchType, patch, nil); err != nil {
+			return err
+		}
+	}
+
+	printer, err := o.ToPrinter("rebalanced")
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(obj, o.Out)
+}
+
+// subsetCapacity returns the number of pods the subsets of ws can hold for a workload with the given replicas. It
+// returns false if a subset has no maxReplicas, since that subset can hold any number of pods.
+func subsetCapacity(ws *kruiseappsv1alpha1.WorkloadSpread, replicas int) (int, bool, error) {
+	capacity := 0
+	for _, subset := range ws.Spec.Subsets {
+		if subset.MaxReplicas == nil {
+			return 0, false, nil
+		}
+		maxReplicas, err := intstr.GetScaledValueFromIntOrPercent(subset.MaxReplicas, replicas, true)
+		if err != nil {
+			return 0, false, err
+		}
+		capacity += maxReplicas
+	}
+	return capacity, true, nil
+}
+
+// rebalanceSubsets scales the integer maxReplicas of the subsets of ws from the current replicas of the workload to
+// the new ones, and raises the maxReplicas of the last subset with an integer one until the subsets hold all the
+// new replicas. Percentages are relative to the replicas already, and are left alone.
+func rebalanceSubsets(ws *kruiseappsv1alpha1.WorkloadSpread, current, replicas int) error {
+	last := -1
+	for i := range ws.Spec.Subsets {
+		maxReplicas := ws.Spec.Subsets[i].MaxReplicas
+		if maxReplicas == nil || maxReplicas.Type != intstr.Int {
+			continue
+		}
+		last = i
+		if current > 0 {
+			ratio := float64(maxReplicas.IntVal) / float64(current)
+			scaled := intstr.FromInt(int(math.Round(ratio * float64(replicas))))
+			ws.Spec.Subsets[i].MaxReplicas = &scaled
+		}
+	}
+	if last == -1 {
+		return fmt.Errorf("its subsets only have percentage maxReplicas")
+	}
+
+	capacity, _, err := subsetCapacity(ws, replicas)
+	if err != nil {
+		return err
+	}
+	if capacity < replicas {
+		raised := intstr.FromInt(int(ws.Spec.Subsets[last].MaxReplicas.IntVal) + replicas - capacity)
+		ws.Spec.Subsets[last].MaxReplicas = &raised
+	}
+	return nil
+}
+
+// currentReplicas returns the replicas of a workload, an unset replicas defaults to 1.
+func currentReplicas(obj runtime.Object) (int, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return 0, err
+	}
+	replicas, found, err := unstructured.NestedInt64(content, "spec", "replicas")
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 1, nil
+	}
+	return int(replicas), nil
+}
@@ -17,10 +17,13 @@ limitations under the License.
 package set
 
 import (
+	"reflect"
 	"strings"
 
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -109,6 +112,16 @@ func CalculatePatch(patch *Patch, encoder runtime.Encoder, mutateFn PatchFn) boo
 	return true
 }
 
+// PatchData returns the type and the data of the request sending patch to the server. Kruise workloads are custom
+// resources, which do not support strategic merge patches, so the whole updated object is sent as a merge patch for
+// them, while the strategic merge patch is sent for any other object.
+func (p *Patch) PatchData() (types.PatchType, []byte) {
+	if p.Info.Mapping.GroupVersionKind.Group == kruiseappsv1alpha1.GroupVersion.Group {
+		return types.MergePatchType, p.After
+	}
+	return types.StrategicMergePatchType, p.Patch
+}
+
 // CalculatePatches calculates patches on each provided info object. If the provided mutateFn
 // makes no change in an object, the object is not included in the final list of patches.
 func CalculatePatches(infos []*resource.Info, encoder runtime.Encoder, mutateFn PatchFn) []*Patch {
@@ -155,3 +168,22 @@ func updateEnv(existing []v1.EnvVar, env []v1.EnvVar, remove []string) []v1.EnvV
 	}
 	return out
 }
+
+// updateEnvFrom appends the env sources that are not referenced by existing yet. Two sources are
+// the same if they point at the same config map or secret with the same prefix.
+func updateEnvFrom(existing []v1.EnvFromSource, envFrom []v1.EnvFromSource) []v1.EnvFromSource {
+	out := existing
+	for _, e := range envFrom {
+		found := false
+		for _, o := range out {
+			if reflect.DeepEqual(o, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -48,6 +48,8 @@ func NewCmdSet(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Co
 	cmd.AddCommand(NewCmdSubject(f, streams))
 	cmd.AddCommand(NewCmdServiceAccount(f, streams))
 	cmd.AddCommand(NewCmdEnv(f, streams))
+	cmd.AddCommand(NewCmdProbe(f, streams))
+	cmd.AddCommand(NewCmdTerminationGracePeriod(f, streams))
 
 	return cmd
 }
@@ -23,16 +23,14 @@ import (
 	"sort"
 	"strings"
 
-	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
-	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
 	"github.com/openkruise/kruise-tools/pkg/api"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	"github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
 	"github.com/spf13/cobra"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
@@ -89,6 +87,9 @@ var (
       # Import specific keys from a config map
       kubectl-kruise set env --keys=my-example-key --from=configmap/myconfigmap cloneset/sample
 
+	  # Reference a whole secret through envFrom in container 'web' of an advanced daemonset
+	  kubectl-kruise set env --from=secret/mysecret --containers=web daemonsets.apps.kruise.io/sample
+
 	  # Remove the environment variable ENV from container 'c1' in all deployment configs
 	  kubectl-kruise set env clonesets --all --containers="c1" ENV-
 
@@ -251,6 +252,10 @@ func (o *EnvOptions) Validate() error {
 	if o.Local && o.dryRunStrategy == cmdutil.DryRunServer {
 		return fmt.Errorf("cannot specify --local and --dry-run=server - did you mean --dry-run=client?")
 	}
+	// --local cannot query the api server for the resources given as arguments
+	if o.Local && len(o.resources) > 0 {
+		return resource.LocalResourceError
+	}
 	if len(o.Filenames) == 0 && len(o.resources) < 1 {
 		return fmt.Errorf("one or more resources must be specified as <resource> <name> or <resource>/<name>")
 	}
@@ -271,22 +276,18 @@ func (o *EnvOptions) RunEnv() error {
 		return err
 	}
 
-	if len(o.From) != 0 {
-		b := o.builder().
-			WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
-			LocalParam(o.Local).
-			ContinueOnError().
-			NamespaceParam(o.namespace).DefaultNamespace().
-			FilenameParam(o.enforceNamespace, &o.FilenameOptions).
-			Flatten()
-		if !o.Local {
-			b = b.
-				LabelSelectorParam(o.Selector).
-				ResourceTypeOrNameArgs(o.All, o.From).
-				Latest()
-		}
+	var envFrom []v1.EnvFromSource
 
-		infos, err := b.Do().Infos()
+	if len(o.From) != 0 {
+		infos, err := internalcmdutil.BuildResources(o.builder(), internalcmdutil.ResourceBuilderOptions{
+			Namespace:        o.namespace,
+			EnforceNamespace: o.enforceNamespace,
+			FilenameOptions:  &o.FilenameOptions,
+			LabelSelector:    o.Selector,
+			All:              o.All,
+			Args:             []string{o.From},
+			Local:            o.Local,
+		}).Infos()
 		if err != nil {
 			return err
 		}
@@ -294,6 +295,14 @@ func (o *EnvOptions) RunEnv() error {
 		for _, info := range infos {
 			switch from := info.Object.(type) {
 			case *v1.Secret:
+				// the whole secret is injected, unless only some of its keys are requested
+				if len(o.Keys) == 0 {
+					envFrom = append(envFrom, v1.EnvFromSource{
+						Prefix:    o.Prefix,
+						SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: from.Name}},
+					})
+					continue
+				}
 				for key := range from.Data {
 					if contains(key, o.Keys) {
 						envVar := v1.EnvVar{
@@ -311,6 +320,14 @@ func (o *EnvOptions) RunEnv() error {
 					}
 				}
 			case *v1.ConfigMap:
+				// the whole config map is injected, unless only some of its keys are requested
+				if len(o.Keys) == 0 {
+					envFrom = append(envFrom, v1.EnvFromSource{
+						Prefix:       o.Prefix,
+						ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: from.Name}},
+					})
+					continue
+				}
 				for key := range from.Data {
 					if contains(key, o.Keys) {
 						envVar := v1.EnvVar{
@@ -339,21 +356,15 @@ func (o *EnvOptions) RunEnv() error {
 		}
 	}
 
-	b := o.builder().
-		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		LocalParam(o.Local).
-		ContinueOnError().
-		NamespaceParam(o.namespace).DefaultNamespace().
-		FilenameParam(o.enforceNamespace, &o.FilenameOptions).
-		Flatten()
-
-	if !o.Local {
-		b.LabelSelectorParam(o.Selector).
-			ResourceTypeOrNameArgs(o.All, o.resources...).
-			Latest()
-	}
-
-	infos, err := b.Do().Infos()
+	infos, err := internalcmdutil.BuildResources(o.builder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        o.namespace,
+		EnforceNamespace: o.enforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		LabelSelector:    o.Selector,
+		All:              o.All,
+		Args:             o.resources,
+		Local:            o.Local,
+	}).Infos()
 	if err != nil {
 		return err
 	}
@@ -362,438 +373,165 @@ func (o *EnvOptions) RunEnv() error {
 		return nil
 	}
 
-	switch infos[0].Object.(type) {
-	case *kruiseappsv1alpha1.CloneSet:
-
-		obj, err := resource.
-			NewHelper(infos[0].Client, infos[0].Mapping).
-			Get(o.namespace, infos[0].Name)
-		if err != nil {
-			return err
-		}
-		res := obj.(*kruiseappsv1alpha1.CloneSet)
-
-		resolutionErrorsEncountered := false
-		containers, _ := selectContainers(res.Spec.Template.Spec.Containers, o.ContainerSelector)
-
-		objName, err := meta.NewAccessor().Name(res)
-		if err != nil {
-			return err
-		}
-
-		gvks, _, err := scheme.Scheme.ObjectKinds(res)
-		if err != nil {
-			return err
-		}
-
-		objKind := res.GetObjectKind().GroupVersionKind().Kind
-		if len(objKind) == 0 {
-			for _, gvk := range gvks {
-				if len(gvk.Kind) == 0 {
-					continue
-				}
-				if len(gvk.Version) == 0 || gvk.Version == runtime.APIVersionInternal {
-					continue
-				}
-
-				objKind = gvk.Kind
-				break
-			}
-		}
-
-		if len(containers) == 0 {
-			if gvks, _, err := scheme.Scheme.ObjectKinds(res); err == nil {
-				objKind := res.GetObjectKind().GroupVersionKind().Kind
-				if len(objKind) == 0 {
-					for _, gvk := range gvks {
-						if len(gvk.Kind) == 0 {
-							continue
-						}
-						if len(gvk.Version) == 0 || gvk.Version == runtime.APIVersionInternal {
-							continue
-						}
-
-						objKind = gvk.Kind
-						break
-					}
-				}
-
-				fmt.Fprintf(o.ErrOut, "warning: %s/%s does not have any containers matching %q\n", objKind, objName, o.ContainerSelector)
+	patches := CalculatePatches(infos, scheme.DefaultJSONEncoder(), func(obj runtime.Object) ([]byte, error) {
+		_, err := o.updatePodSpecForObject(obj, func(spec *v1.PodSpec) error {
+			resolutionErrorsEncountered := false
+			containers, _ := selectContainers(spec.Containers, o.ContainerSelector)
+			objName, err := meta.NewAccessor().Name(obj)
+			if err != nil {
+				return err
 			}
-			return nil
-		}
 
-		for _, c := range containers {
-			if !o.Overwrite {
-				if err := validateNoOverwrites(c.Env, env); err != nil {
-					return err
-				}
+			gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+			if err != nil {
+				return err
 			}
-
-			c.Env = updateEnv(c.Env, env, remove)
-			if o.List {
-				resolveErrors := map[string][]string{}
-				store := envutil.NewResourceStore()
-
-				fmt.Fprintf(o.Out, "# %s %s, container %s\n", objKind, objName, c.Name)
-				for _, env := range c.Env {
-					// Print the simple value
-					if env.ValueFrom == nil {
-						fmt.Fprintf(o.Out, "%s=%s\n", env.Name, env.Value)
-						continue
-					}
-
-					// Print the reference version
-					if !o.Resolve {
-						fmt.Fprintf(o.Out, "# %s from %s\n", env.Name, envutil.GetEnvVarRefString(env.ValueFrom))
+			objKind := obj.GetObjectKind().GroupVersionKind().Kind
+			if len(objKind) == 0 {
+				for _, gvk := range gvks {
+					if len(gvk.Kind) == 0 {
 						continue
 					}
-
-					value, err := envutil.GetEnvVarRefValue(o.clientset, o.namespace, store, env.ValueFrom, res, c)
-					// Print the resolved value
-					if err == nil {
-						fmt.Fprintf(o.Out, "%s=%s\n", env.Name, value)
+					if len(gvk.Version) == 0 || gvk.Version == runtime.APIVersionInternal {
 						continue
 					}
 
-					// Print the reference version and save the resolve error
-					fmt.Fprintf(o.Out, "# %s from %s\n", env.Name, envutil.GetEnvVarRefString(env.ValueFrom))
-					errString := err.Error()
-					resolveErrors[errString] = append(resolveErrors[errString], env.Name)
-					resolutionErrorsEncountered = true
+					objKind = gvk.Kind
+					break
 				}
-
-				// Print any resolution errors
-				var errs []string
-				for err, vars := range resolveErrors {
-					sort.Strings(vars)
-					errs = append(errs, fmt.Sprintf("error retrieving reference for %s: %v", strings.Join(vars, ", "), err))
-				}
-				sort.Strings(errs)
-				for _, err := range errs {
-					_, _ = fmt.Fprintln(o.ErrOut, err)
-				}
-			}
-		}
-
-		if !o.Local {
-			_, err := resource.
-				NewHelper(infos[0].Client, infos[0].Mapping).
-				DryRun(o.dryRunStrategy == cmdutil.DryRunServer).
-				Replace(infos[0].Namespace, infos[0].Name, true, res)
-			if err != nil {
-				return fmt.Errorf("failed to patch env update to pod template: %v", err)
 			}
-		}
-
-		if resolutionErrorsEncountered {
-			return errors.New("failed to retrieve valueFrom references")
-		}
-
-		if o.List {
-			return nil
-		}
-
-		if err := o.PrintObj(res, o.Out); err != nil {
-			return errors.New(err.Error())
-		}
 
-		return nil
-	case *kruiseappsv1beta1.StatefulSet:
-		obj, err := resource.
-			NewHelper(infos[0].Client, infos[0].Mapping).
-			DryRun(o.dryRunStrategy == cmdutil.DryRunServer).
-			Get(o.namespace, infos[0].Name)
-		if err != nil {
-			return err
-		}
-		res := obj.(*kruiseappsv1beta1.StatefulSet)
-
-		resolutionErrorsEncountered := false
-		containers, _ := selectContainers(res.Spec.Template.Spec.Containers, o.ContainerSelector)
-
-		objName, err := meta.NewAccessor().Name(res)
-		if err != nil {
-			return err
-		}
-
-		gvks, _, err := scheme.Scheme.ObjectKinds(res)
-		if err != nil {
-			return err
-		}
-
-		objKind := res.GetObjectKind().GroupVersionKind().Kind
-		if len(objKind) == 0 {
-			for _, gvk := range gvks {
-				if len(gvk.Kind) == 0 {
-					continue
-				}
-				if len(gvk.Version) == 0 || gvk.Version == runtime.APIVersionInternal {
-					continue
-				}
-
-				objKind = gvk.Kind
-				break
-			}
-		}
+			if len(containers) == 0 {
+				if gvks, _, err := scheme.Scheme.ObjectKinds(obj); err == nil {
+					objKind := obj.GetObjectKind().GroupVersionKind().Kind
+					if len(objKind) == 0 {
+						for _, gvk := range gvks {
+							if len(gvk.Kind) == 0 {
+								continue
+							}
+							if len(gvk.Version) == 0 || gvk.Version == runtime.APIVersionInternal {
+								continue
+							}
 
-		if len(containers) == 0 {
-			if gvks, _, err := scheme.Scheme.ObjectKinds(res); err == nil {
-				objKind := res.GetObjectKind().GroupVersionKind().Kind
-				if len(objKind) == 0 {
-					for _, gvk := range gvks {
-						if len(gvk.Kind) == 0 {
-							continue
-						}
-						if len(gvk.Version) == 0 || gvk.Version == runtime.APIVersionInternal {
-							continue
+							objKind = gvk.Kind
+							break
 						}
-
-						objKind = gvk.Kind
-						break
 					}
-				}
-
-				fmt.Fprintf(o.ErrOut, "warning: %s/%s does not have any containers matching %q\n", objKind, objName, o.ContainerSelector)
-			}
-			return nil
-		}
 
-		for _, c := range containers {
-			if !o.Overwrite {
-				if err := validateNoOverwrites(c.Env, env); err != nil {
-					return err
+					fmt.Fprintf(o.ErrOut, "warning: %s/%s does not have any containers matching %q\n", objKind, objName, o.ContainerSelector)
 				}
+				return nil
 			}
-
-			c.Env = updateEnv(c.Env, env, remove)
-			if o.List {
-				resolveErrors := map[string][]string{}
-				store := envutil.NewResourceStore()
-
-				fmt.Fprintf(o.Out, "# %s %s, container %s\n", objKind, objName, c.Name)
-				for _, env := range c.Env {
-					// Print the simple value
-					if env.ValueFrom == nil {
-						fmt.Fprintf(o.Out, "%s=%s\n", env.Name, env.Value)
-						continue
-					}
-
-					// Print the reference version
-					if !o.Resolve {
-						fmt.Fprintf(o.Out, "# %s from %s\n", env.Name, envutil.GetEnvVarRefString(env.ValueFrom))
-						continue
-					}
-
-					value, err := envutil.GetEnvVarRefValue(o.clientset, o.namespace, store, env.ValueFrom, res, c)
-					// Print the resolved value
-					if err == nil {
-						fmt.Fprintf(o.Out, "%s=%s\n", env.Name, value)
-						continue
+			for _, c := range containers {
+				if !o.Overwrite {
+					if err := validateNoOverwrites(c.Env, env); err != nil {
+						return err
 					}
-
-					// Print the reference version and save the resolve error
-					fmt.Fprintf(o.Out, "# %s from %s\n", env.Name, envutil.GetEnvVarRefString(env.ValueFrom))
-					errString := err.Error()
-					resolveErrors[errString] = append(resolveErrors[errString], env.Name)
-					resolutionErrorsEncountered = true
 				}
 
-				// Print any resolution errors
-				var errs []string
-				for err, vars := range resolveErrors {
-					sort.Strings(vars)
-					errs = append(errs, fmt.Sprintf("error retrieving reference for %s: %v", strings.Join(vars, ", "), err))
-				}
-				sort.Strings(errs)
-				for _, err := range errs {
-					_, _ = fmt.Fprintln(o.ErrOut, err)
-				}
-			}
-		}
-
-		if !o.Local {
-			_, err := resource.
-				NewHelper(infos[0].Client, infos[0].Mapping).
-				DryRun(o.dryRunStrategy == cmdutil.DryRunServer).
-				Replace(infos[0].Namespace, infos[0].Name, true, res)
-			if err != nil {
-				return fmt.Errorf("failed to patch env update to pod template: %v", err)
-			}
-		}
-
-		if resolutionErrorsEncountered {
-			return errors.New("failed to retrieve valueFrom references")
-		}
-
-		if o.List {
-			return nil
-		}
-
-		if err := o.PrintObj(res, o.Out); err != nil {
-			return errors.New(err.Error())
-		}
-
-		return nil
-	default:
-		patches := CalculatePatches(infos, scheme.DefaultJSONEncoder(), func(obj runtime.Object) ([]byte, error) {
-			_, err := o.updatePodSpecForObject(obj, func(spec *v1.PodSpec) error {
-				resolutionErrorsEncountered := false
-				containers, _ := selectContainers(spec.Containers, o.ContainerSelector)
-				objName, err := meta.NewAccessor().Name(obj)
-				if err != nil {
-					return err
-				}
-
-				gvks, _, err := scheme.Scheme.ObjectKinds(obj)
-				if err != nil {
-					return err
-				}
-				objKind := obj.GetObjectKind().GroupVersionKind().Kind
-				if len(objKind) == 0 {
-					for _, gvk := range gvks {
-						if len(gvk.Kind) == 0 {
+				c.Env = updateEnv(c.Env, env, remove)
+				c.EnvFrom = updateEnvFrom(c.EnvFrom, envFrom)
+				if o.List {
+					resolveErrors := map[string][]string{}
+					store := envutil.NewResourceStore()
+
+					fmt.Fprintf(o.Out, "# %s %s, container %s\n", objKind, objName, c.Name)
+					for _, env := range c.Env {
+						// Print the simple value
+						if env.ValueFrom == nil {
+							fmt.Fprintf(o.Out, "%s=%s\n", env.Name, env.Value)
 							continue
 						}
-						if len(gvk.Version) == 0 || gvk.Version == runtime.APIVersionInternal {
+
+						// Print the reference version
+						if !o.Resolve {
+							fmt.Fprintf(o.Out, "# %s from %s\n", env.Name, envutil.GetEnvVarRefString(env.ValueFrom))
 							continue
 						}
 
-						objKind = gvk.Kind
-						break
-					}
-				}
-
-				if len(containers) == 0 {
-					if gvks, _, err := scheme.Scheme.ObjectKinds(obj); err == nil {
-						objKind := obj.GetObjectKind().GroupVersionKind().Kind
-						if len(objKind) == 0 {
-							for _, gvk := range gvks {
-								if len(gvk.Kind) == 0 {
-									continue
-								}
-								if len(gvk.Version) == 0 || gvk.Version == runtime.APIVersionInternal {
-									continue
-								}
-
-								objKind = gvk.Kind
-								break
-							}
+						value, err := envutil.GetEnvVarRefValue(o.clientset, o.namespace, store, env.ValueFrom, obj, c)
+						// Print the resolved value
+						if err == nil {
+							fmt.Fprintf(o.Out, "%s=%s\n", env.Name, value)
+							continue
 						}
 
-						fmt.Fprintf(o.ErrOut, "warning: %s/%s does not have any containers matching %q\n", objKind, objName, o.ContainerSelector)
-					}
-					return nil
-				}
-				for _, c := range containers {
-					if !o.Overwrite {
-						if err := validateNoOverwrites(c.Env, env); err != nil {
-							return err
-						}
+						// Print the reference version and save the resolve error
+						fmt.Fprintf(o.Out, "# %s from %s\n", env.Name, envutil.GetEnvVarRefString(env.ValueFrom))
+						errString := err.Error()
+						resolveErrors[errString] = append(resolveErrors[errString], env.Name)
+						resolutionErrorsEncountered = true
 					}
 
-					c.Env = updateEnv(c.Env, env, remove)
-					if o.List {
-						resolveErrors := map[string][]string{}
-						store := envutil.NewResourceStore()
-
-						fmt.Fprintf(o.Out, "# %s %s, container %s\n", objKind, objName, c.Name)
-						for _, env := range c.Env {
-							// Print the simple value
-							if env.ValueFrom == nil {
-								fmt.Fprintf(o.Out, "%s=%s\n", env.Name, env.Value)
-								continue
-							}
-
-							// Print the reference version
-							if !o.Resolve {
-								fmt.Fprintf(o.Out, "# %s from %s\n", env.Name, envutil.GetEnvVarRefString(env.ValueFrom))
-								continue
-							}
-
-							value, err := envutil.GetEnvVarRefValue(o.clientset, o.namespace, store, env.ValueFrom, obj, c)
-							// Print the resolved value
-							if err == nil {
-								fmt.Fprintf(o.Out, "%s=%s\n", env.Name, value)
-								continue
-							}
-
-							// Print the reference version and save the resolve error
-							fmt.Fprintf(o.Out, "# %s from %s\n", env.Name, envutil.GetEnvVarRefString(env.ValueFrom))
-							errString := err.Error()
-							resolveErrors[errString] = append(resolveErrors[errString], env.Name)
-							resolutionErrorsEncountered = true
-						}
-
-						// Print any resolution errors
-						var errs []string
-						for err, vars := range resolveErrors {
-							sort.Strings(vars)
-							errs = append(errs, fmt.Sprintf("error retrieving reference for %s: %v", strings.Join(vars, ", "), err))
-						}
-						sort.Strings(errs)
-						for _, err := range errs {
-							_, _ = fmt.Fprintln(o.ErrOut, err)
-						}
+					// Print any resolution errors
+					var errs []string
+					for err, vars := range resolveErrors {
+						sort.Strings(vars)
+						errs = append(errs, fmt.Sprintf("error retrieving reference for %s: %v", strings.Join(vars, ", "), err))
+					}
+					sort.Strings(errs)
+					for _, err := range errs {
+						_, _ = fmt.Fprintln(o.ErrOut, err)
 					}
 				}
-				if resolutionErrorsEncountered {
-					return errors.New("failed to retrieve valueFrom references")
-				}
-				return nil
-			})
-
-			if err == nil {
-				return runtime.Encode(scheme.DefaultJSONEncoder(), obj)
 			}
-			return nil, err
+			if resolutionErrorsEncountered {
+				return errors.New("failed to retrieve valueFrom references")
+			}
+			return nil
 		})
 
-		if o.List {
-			return nil
+		if err == nil {
+			return runtime.Encode(scheme.DefaultJSONEncoder(), obj)
 		}
+		return nil, err
+	})
 
-		var allErrs []error
+	if o.List {
+		return nil
+	}
 
-		for _, patch := range patches {
-			info := patch.Info
-			if patch.Err != nil {
-				name := info.ObjectName()
-				allErrs = append(allErrs, fmt.Errorf("error: %s %v\n", name, patch.Err))
-				continue
-			}
+	var allErrs []error
 
-			// no changes
-			if string(patch.Patch) == "{}" || len(patch.Patch) == 0 {
-				continue
-			}
+	for _, patch := range patches {
+		info := patch.Info
+		if patch.Err != nil {
+			name := info.ObjectName()
+			allErrs = append(allErrs, fmt.Errorf("error: %s %v\n", name, patch.Err))
+			continue
+		}
 
-			if o.Local || o.dryRunStrategy == cmdutil.DryRunClient {
-				if err := o.PrintObj(info.Object, o.Out); err != nil {
-					allErrs = append(allErrs, err)
-				}
-				continue
-			}
+		// no changes
+		if string(patch.Patch) == "{}" || len(patch.Patch) == 0 {
+			continue
+		}
 
-			actual, err := resource.
-				NewHelper(info.Client, info.Mapping).
-				DryRun(o.dryRunStrategy == cmdutil.DryRunServer).
-				Patch(info.Namespace, info.Name, types.StrategicMergePatchType, patch.Patch, nil)
-			if err != nil {
-				allErrs = append(allErrs, fmt.Errorf("failed to patch env update to pod template: %v", err))
-				continue
+		if o.Local || o.dryRunStrategy == cmdutil.DryRunClient {
+			if err := o.PrintObj(info.Object, o.Out); err != nil {
+				allErrs = append(allErrs, err)
 			}
+			continue
+		}
 
-			// make sure arguments to set or replace environment variables are set
-			// before returning a successful message
-			if len(env) == 0 && len(o.envArgs) == 0 {
-				return fmt.Errorf("at least one environment variable must be provided")
-			}
+		patchType, data := patch.PatchData()
+		actual, err := resource.
+			NewHelper(info.Client, info.Mapping).
+			DryRun(o.dryRunStrategy == cmdutil.DryRunServer).
+			Patch(info.Namespace, info.Name, patchType, data, nil)
+		if err != nil {
+			allErrs = append(allErrs, fmt.Errorf("failed to patch env update to pod template: %v", err))
+			continue
+		}
 
-			if err := o.PrintObj(actual, o.Out); err != nil {
-				allErrs = append(allErrs, err)
-			}
+		// make sure arguments to set or replace environment variables are set
+		// before returning a successful message
+		if len(env) == 0 && len(envFrom) == 0 && len(o.envArgs) == 0 {
+			return fmt.Errorf("at least one environment variable must be provided")
+		}
+
+		if err := o.PrintObj(actual, o.Out); err != nil {
+			allErrs = append(allErrs, err)
 		}
-		return utilerrors.NewAggregate(allErrs)
 	}
+	return utilerrors.NewAggregate(allErrs)
 }
@@ -17,12 +17,14 @@ limitations under the License.
 package set
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"testing"
 
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	appsv1beta1 "k8s.io/api/apps/v1beta1"
@@ -33,6 +35,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	restclient "k8s.io/client-go/rest"
@@ -554,11 +557,9 @@ func TestSetEnvFromResource(t *testing.T) {
 			from: "configmap/testconfigmap",
 			keys: []string{},
 			assertIncludes: []string{
-				`{"name":"ENV","valueFrom":{"configMapKeyRef":{"key":"env","name":"testconfigmap"}}}`,
-				`{"name":"TEST_KEY","valueFrom":{"configMapKeyRef":{"key":"test-key","name":"testconfigmap"}}}`,
-				`{"name":"TEST_KEY_TWO","valueFrom":{"configMapKeyRef":{"key":"test-key-two","name":"testconfigmap"}}}`,
+				`"envFrom":[{"configMapRef":{"name":"testconfigmap"}}]`,
 			},
-			assertExcludes: []string{},
+			assertExcludes: []string{`"valueFrom"`},
 		},
 		{
 			name: "test from secret",
@@ -566,11 +567,9 @@ func TestSetEnvFromResource(t *testing.T) {
 			from: "secret/testsecret",
 			keys: []string{},
 			assertIncludes: []string{
-				`{"name":"ENV","valueFrom":{"secretKeyRef":{"key":"env","name":"testsecret"}}}`,
-				`{"name":"TEST_KEY","valueFrom":{"secretKeyRef":{"key":"test-key","name":"testsecret"}}}`,
-				`{"name":"TEST_KEY_TWO","valueFrom":{"secretKeyRef":{"key":"test-key-two","name":"testsecret"}}}`,
+				`"envFrom":[{"secretRef":{"name":"testsecret"}}]`,
 			},
-			assertExcludes: []string{},
+			assertExcludes: []string{`"valueFrom"`},
 		},
 		{
 			name: "test from configmap with keys",
@@ -666,3 +665,134 @@ func TestSetEnvFromResource(t *testing.T) {
 		})
 	}
 }
+
+func TestSetEnvKruiseWorkloads(t *testing.T) {
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "nginx", Image: "nginx", Env: []corev1.EnvVar{{Name: "FOO", Value: "bar"}}},
+			{Name: "sidecar", Image: "envoy"},
+		},
+	}
+	workloads := newKruiseTestWorkloads(corev1.PodTemplateSpec{Spec: podSpec})
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cfg"},
+		Data:       map[string]string{"key": "value"},
+	}
+	testCases := []struct {
+		name          string
+		env           []string
+		from          string
+		containers    string
+		dryRun        string
+		expectPatch   bool
+		expectEnv     map[string][]corev1.EnvVar
+		expectEnvFrom map[string][]corev1.EnvFromSource
+	}{
+		{
+			name:        "add",
+			env:         []string{"KEY=VALUE"},
+			containers:  "nginx",
+			expectPatch: true,
+			expectEnv:   map[string][]corev1.EnvVar{"nginx": {{Name: "FOO", Value: "bar"}, {Name: "KEY", Value: "VALUE"}}},
+		},
+		{
+			name:        "overwrite",
+			env:         []string{"FOO=baz"},
+			expectPatch: true,
+			expectEnv:   map[string][]corev1.EnvVar{"nginx": {{Name: "FOO", Value: "baz"}}, "sidecar": {{Name: "FOO", Value: "baz"}}},
+		},
+		{
+			name:        "remove",
+			env:         []string{"FOO-"},
+			expectPatch: true,
+			expectEnv:   map[string][]corev1.EnvVar{},
+		},
+		{
+			name:        "env from configmap",
+			from:        "configmap/cfg",
+			containers:  "sidecar",
+			expectPatch: true,
+			expectEnv:   map[string][]corev1.EnvVar{"nginx": {{Name: "FOO", Value: "bar"}}},
+			expectEnvFrom: map[string][]corev1.EnvFromSource{
+				"sidecar": {{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cfg"}}}},
+			},
+		},
+		{
+			name:   "client dry-run",
+			env:    []string{"KEY=VALUE"},
+			dryRun: "client",
+		},
+	}
+
+	for _, workload := range workloads {
+		for _, tc := range testCases {
+			t.Run(workload.name+" "+tc.name, func(t *testing.T) {
+				tf := cmdtesting.NewTestFactory().WithNamespace("test")
+				defer tf.Cleanup()
+
+				var patched bool
+				tf.Client = &fake.RESTClient{
+					GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+					NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+					Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+						switch p, m := req.URL.Path, req.Method; {
+						case p == "/namespaces/test/configmaps/cfg" && m == http.MethodGet:
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(configMap)}, nil
+						case p == workload.path && m == http.MethodGet:
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(workload.object)}, nil
+						case p == workload.path && m == http.MethodPatch:
+							patched = true
+							assert.Equal(t, string(types.MergePatchType), req.Header.Get("Content-Type"))
+							body, err := ioutil.ReadAll(req.Body)
+							if err != nil {
+								return nil, err
+							}
+							var patch struct {
+								Spec struct {
+									Template corev1.PodTemplateSpec `json:"template"`
+								} `json:"spec"`
+							}
+							assert.NoError(t, json.Unmarshal(body, &patch))
+							env, envFrom := map[string][]corev1.EnvVar{}, map[string][]corev1.EnvFromSource{}
+							for _, c := range patch.Spec.Template.Spec.Containers {
+								if len(c.Env) > 0 {
+									env[c.Name] = c.Env
+								}
+								if len(c.EnvFrom) > 0 {
+									envFrom[c.Name] = c.EnvFrom
+								}
+							}
+							assert.Equal(t, tc.expectEnv, env)
+							if tc.expectEnvFrom == nil {
+								assert.Empty(t, envFrom)
+							} else {
+								assert.Equal(t, tc.expectEnvFrom, envFrom)
+							}
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(workload.object)}, nil
+						default:
+							t.Errorf("unexpected request: %s %#v", req.Method, req.URL)
+							return nil, fmt.Errorf("unexpected request")
+						}
+					}),
+				}
+
+				streams := genericclioptions.NewTestIOStreamsDiscard()
+				cmd := NewCmdEnv(tf, streams)
+				if len(tc.dryRun) > 0 {
+					cmd.Flags().Set("dry-run", tc.dryRun)
+				}
+				opts := NewEnvOptions(streams)
+				opts.From = tc.from
+				opts.ContainerSelector = "*"
+				if len(tc.containers) > 0 {
+					opts.ContainerSelector = tc.containers
+				}
+				assert.NoError(t, opts.Complete(tf, cmd, append([]string{workload.arg}, tc.env...)))
+				assert.NoError(t, opts.Validate())
+
+				assert.NoError(t, opts.RunEnv())
+				assert.Equal(t, tc.expectPatch, patched)
+			})
+		}
+	}
+}
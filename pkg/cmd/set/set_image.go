@@ -18,8 +18,12 @@ package set
 
 import (
 	"fmt"
+	"io"
+	"sort"
+	"strings"
 
 	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	"github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
@@ -59,16 +63,29 @@ type SetImageOptions struct {
 	Resources              []string
 	ContainerImages        map[string]string
 
+	// Source is the workload, as TYPE/NAME, whose container images are copied to the containers of the same name
+	Source string
+
 	genericclioptions.IOStreams
 }
 
 var (
 	imageResources = `
-  	pod (po), deployment (deploy), statefulset (sts), daemonset (ds), replicaset (rs), cloneset (clone), statefulset.apps.kruise.io (asts), daemonset.apps.kruise.io (ads)`
+  	pod (po), deployment (deploy), statefulset (sts), daemonset (ds), replicaset (rs), cloneset (clone), statefulset.apps.kruise.io (asts), daemonset.apps.kruise.io (ads), sidecarset
+
+	The images of a sidecarset are updated in place in the pods it was injected into, unless its update
+	strategy is NotUpdate or it is paused, in which case a warning is printed.`
 
 	imageLong = templates.LongDesc(`
 		Update existing container image(s) of resources.
 
+		With --source, the images of the containers of another workload, e.g. a Deployment being
+		migrated to a CloneSet, are copied to the containers of the same name. The containers of the
+		source which the resources do not have are reported in a warning.
+
+		With --dry-run=client, the old and new images of the containers are printed to stderr as a
+		table, and the containers already running the requested image are reported as unchanged.
+
 		Possible resources include (case insensitive):
 		` + imageResources)
 
@@ -82,6 +99,12 @@ var (
 		# Update image of all containers of cloneset sample to 'nginx:1.9.1'
 		kubectl-kruise set image cloneset sample *=nginx:1.9.1
 
+		# Copy the images of the containers of deployment web to the containers of the same name of cloneset web
+		kubectl-kruise set image cloneset/web --source=deployment/web
+
+		# Update the agent container of sidecarset log-agent to 'agent:v2', printing the result without changing it
+		kubectl-kruise set image sidecarset/log-agent agent=agent:v2 --dry-run=client -o yaml
+
 		# Print result (in yaml format) of updating nginx container image from local file, without hitting the server
 		kubectl-kruise set image -f path/to/file.yaml nginx=nginx:1.9.1 --local -o yaml`)
 )
@@ -123,6 +146,7 @@ func NewCmdImage(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.
 	cmd.Flags().BoolVar(&o.All, "all", o.All, "Select all resources, including uninitialized ones, in the namespace of the specified resource types")
 	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on, not including uninitialized ones, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
 	cmd.Flags().BoolVar(&o.Local, "local", o.Local, "If true, set image will NOT contact api-server but run locally.")
+	cmd.Flags().StringVar(&o.Source, "source", o.Source, "A workload, e.g. deployment/web, whose container images are copied to the containers of the same name, instead of CONTAINER_NAME=CONTAINER_IMAGE pairs.")
 	cmdutil.AddDryRunFlag(cmd)
 	return cmd
 }
@@ -166,29 +190,34 @@ func (o *SetImageOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args [
 	if err != nil {
 		return err
 	}
-
-	builder := f.NewBuilder().
-		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		LocalParam(o.Local).
-		ContinueOnError().
-		NamespaceParam(cmdNamespace).DefaultNamespace().
-		FilenameParam(enforceNamespace, &o.FilenameOptions).
-		Flatten()
-
-	if !o.Local {
-		builder.LabelSelectorParam(o.Selector).
-			ResourceTypeOrNameArgs(o.All, o.Resources...).
-			Latest()
-	} else {
-		// if a --local flag was provided, and a resource was specified in the form
-		// <resource>/<name>, fail immediately as --local cannot query the api server
-		// for the specified resource.
-		if len(o.Resources) > 0 {
-			return resource.LocalResourceError
+	if len(o.Source) > 0 {
+		if o.Local {
+			return fmt.Errorf("--source cannot be used with --local, since the source is read from the server")
 		}
+		if len(o.ContainerImages) > 0 {
+			return fmt.Errorf("--source cannot be used with CONTAINER_NAME=CONTAINER_IMAGE pairs")
+		}
+		if o.ContainerImages, err = sourceImages(f.NewBuilder(), cmdNamespace, o.Source); err != nil {
+			return err
+		}
+	}
+
+	// if a --local flag was provided, and a resource was specified in the form
+	// <resource>/<name>, fail immediately as --local cannot query the api server
+	// for the specified resource.
+	if o.Local && len(o.Resources) > 0 {
+		return resource.LocalResourceError
 	}
 
-	o.Infos, err = builder.Do().Infos()
+	o.Infos, err = internalcmdutil.BuildResources(f.NewBuilder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        cmdNamespace,
+		EnforceNamespace: enforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		LabelSelector:    o.Selector,
+		All:              o.All,
+		Args:             o.Resources,
+		Local:            o.Local,
+	}).Infos()
 	if err != nil {
 		return err
 	}
@@ -220,8 +249,15 @@ func (o *SetImageOptions) Validate() error {
 func (o *SetImageOptions) Run() error {
 	var allErrs []error
 
+	changes := map[runtime.Object][]imageChange{}
+	// the containers of the source an object does not have, whose images are not copied
+	missing := map[runtime.Object][]string{}
 	patches := CalculatePatches(o.Infos, scheme.DefaultJSONEncoder(), func(obj runtime.Object) ([]byte, error) {
 		_, err := o.UpdatePodSpecForObject(obj, func(spec *corev1.PodSpec) error {
+			before := containersOf(obj, spec)
+			defer func() {
+				changes[obj] = o.imageChanges(before, containersOf(obj, spec))
+			}()
 			for name, image := range o.ContainerImages {
 				resolvedImageName, err := o.ResolveImage(image)
 				if err != nil {
@@ -232,16 +268,31 @@ func (o *SetImageOptions) Run() error {
 					continue
 				}
 				var initContainerFound, containerFound bool
+				var containerNames []string
 				// Check if the type is kruiseappsv1alpha1.SidecarSet, and if the placeholder is nil.
 				if t, ok := obj.(*kruiseappsv1alpha1.SidecarSet); ok && spec == nil {
 					initContainerFound = setSideCarImage(t.Spec.InitContainers, name, resolvedImageName)
 					containerFound = setSideCarImage(t.Spec.Containers, name, resolvedImageName)
+					for _, c := range t.Spec.InitContainers {
+						containerNames = append(containerNames, c.Name)
+					}
+					for _, c := range t.Spec.Containers {
+						containerNames = append(containerNames, c.Name)
+					}
 				} else {
 					initContainerFound = setImage(spec.InitContainers, name, resolvedImageName)
 					containerFound = setImage(spec.Containers, name, resolvedImageName)
+					for _, c := range spec.InitContainers {
+						containerNames = append(containerNames, c.Name)
+					}
+					for _, c := range spec.Containers {
+						containerNames = append(containerNames, c.Name)
+					}
 				}
-				if !containerFound && !initContainerFound {
-					allErrs = append(allErrs, fmt.Errorf("error: unable to find container named %q", name))
+				if !containerFound && !initContainerFound && len(o.Source) > 0 {
+					missing[obj] = append(missing[obj], name)
+				} else if !containerFound && !initContainerFound {
+					allErrs = append(allErrs, fmt.Errorf("error: unable to find container named %q, valid containers are: %s", name, strings.Join(containerNames, ", ")))
 				}
 			}
 			return nil
@@ -257,6 +308,12 @@ func (o *SetImageOptions) Run() error {
 		return runtime.Encode(scheme.DefaultJSONEncoder(), obj)
 	})
 
+	if o.DryRunStrategy == cmdutil.DryRunClient {
+		if err := printImageChanges(o.ErrOut, patches, changes); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
 	for _, patch := range patches {
 		info := patch.Info
 		if patch.Err != nil {
@@ -265,11 +322,21 @@ func (o *SetImageOptions) Run() error {
 			continue
 		}
 
+		if names := missing[info.Object]; len(names) > 0 {
+			sort.Strings(names)
+			fmt.Fprintf(o.ErrOut, "Warning: %s has no container named %s, the images of these containers of %s are not copied\n",
+				info.ObjectName(), strings.Join(names, ", "), o.Source)
+		}
+
 		// no changes
 		if string(patch.Patch) == "{}" || len(patch.Patch) == 0 {
 			continue
 		}
 
+		if warning := sidecarSetUpdateWarning(info.Object); len(warning) > 0 {
+			fmt.Fprintf(o.ErrOut, "Warning: %s %s\n", info.ObjectName(), warning)
+		}
+
 		if o.Local || o.DryRunStrategy == cmdutil.DryRunClient {
 			if err := o.PrintObj(info.Object, o.Out); err != nil {
 				allErrs = append(allErrs, err)
@@ -320,7 +387,110 @@ func setSideCarImage(containers []kruiseappsv1alpha1.SidecarContainer, container
 	return containerFound
 }
 
+// imageChange is the image of a container before and after 'set image'.
+type imageChange struct {
+	container string
+	oldImage  string
+	newImage  string
+}
+
+// containersOf returns a copy of the init containers and containers of spec, or of the sidecarset obj if spec is nil.
+func containersOf(obj runtime.Object, spec *corev1.PodSpec) []corev1.Container {
+	var containers []corev1.Container
+	if t, ok := obj.(*kruiseappsv1alpha1.SidecarSet); ok && spec == nil {
+		for _, c := range t.Spec.InitContainers {
+			containers = append(containers, c.Container)
+		}
+		for _, c := range t.Spec.Containers {
+			containers = append(containers, c.Container)
+		}
+		return containers
+	}
+	return append(append(containers, spec.InitContainers...), spec.Containers...)
+}
+
+// imageChanges returns the images before and after of the containers the images are set for, in order.
+func (o *SetImageOptions) imageChanges(before, after []corev1.Container) []imageChange {
+	var changes []imageChange
+	for i, c := range after {
+		if _, ok := o.ContainerImages[c.Name]; !ok && !hasWildcardKey(o.ContainerImages) {
+			continue
+		}
+		changes = append(changes, imageChange{container: c.Name, oldImage: before[i].Image, newImage: c.Image})
+	}
+	return changes
+}
+
+// printImageChanges prints a table of the old and new images of the containers of the patched objects. A container
+// whose image is already the requested one is reported as unchanged.
+func printImageChanges(w io.Writer, patches []*Patch, changes map[runtime.Object][]imageChange) error {
+	tw := printers.GetNewTabWriter(w)
+	printedHeader := false
+	for _, patch := range patches {
+		if patch.Err != nil {
+			continue
+		}
+		for _, change := range changes[patch.Info.Object] {
+			if !printedHeader {
+				fmt.Fprintln(tw, "RESOURCE\tCONTAINER\tOLD IMAGE\tNEW IMAGE")
+				printedHeader = true
+			}
+			newImage := change.newImage
+			if newImage == change.oldImage {
+				newImage = "unchanged"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", patch.Info.ObjectName(), change.container, change.oldImage, newImage)
+		}
+	}
+	return tw.Flush()
+}
+
+// sidecarSetUpdateWarning explains why the pods a sidecarset was injected into do not get its new images in place,
+// or returns an empty string if they do or obj is not a sidecarset.
+func sidecarSetUpdateWarning(obj runtime.Object) string {
+	sidecarSet, ok := obj.(*kruiseappsv1alpha1.SidecarSet)
+	if !ok {
+		return ""
+	}
+	switch strategy := sidecarSet.Spec.UpdateStrategy; {
+	case strategy.Type == kruiseappsv1alpha1.NotUpdateSidecarSetStrategyType:
+		return fmt.Sprintf("has the %s update strategy, the new images are only injected into new pods", strategy.Type)
+	case strategy.Paused:
+		return "is paused, the pods it was injected into are updated once it is resumed"
+	}
+	return ""
+}
+
 // getResourcesAndImages retrieves resources and container name:images pair from given args
+// sourceImages returns the images of the init containers and containers of the workload source, by container name.
+func sourceImages(builder *resource.Builder, namespace, source string) (map[string]string, error) {
+	infos, err := builder.
+		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		NamespaceParam(namespace).DefaultNamespace().
+		ResourceTypeOrNameArgs(false, source).
+		SingleResourceType().
+		Latest().
+		Flatten().
+		Do().Infos()
+	if err != nil {
+		return nil, err
+	}
+	if len(infos) != 1 {
+		return nil, fmt.Errorf("--source must be a single workload, e.g. deployment/web, got %q", source)
+	}
+	images := map[string]string{}
+	obj := infos[0].Object
+	if _, err := polymorphichelpers.UpdatePodSpecForObjectFn(obj, func(spec *corev1.PodSpec) error {
+		for _, c := range containersOf(obj, spec) {
+			images[c.Name] = c.Image
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("cannot read the images of --source %s: %v", source, err)
+	}
+	return images, nil
+}
+
 func getResourcesAndImages(args []string) (resources []string, containerImages map[string]string, err error) {
 	pairType := "image"
 	resources, imageArgs, err := cmdutil.GetResourcesAndPairs(args, pairType)
@@ -17,6 +17,7 @@ limitations under the License.
 package set
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -24,6 +25,7 @@ import (
 	"testing"
 
 	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
 
 	"github.com/stretchr/testify/assert"
 
@@ -33,6 +35,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -41,6 +44,7 @@ import (
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/rest/fake"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 )
 
@@ -792,3 +796,419 @@ func TestSetImageRemoteWithSpecificContainers(t *testing.T) {
 		})
 	}
 }
+
+func TestSetImageKruiseWorkloads(t *testing.T) {
+	podSpec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init", Image: "busybox"}},
+		Containers:     []corev1.Container{{Name: "nginx", Image: "nginx"}, {Name: "sidecar", Image: "envoy"}},
+	}
+	workloads := newKruiseTestWorkloads(corev1.PodTemplateSpec{Spec: podSpec})
+	testCases := []struct {
+		name         string
+		images       []string
+		dryRun       string
+		expectPatch  bool
+		expectImages map[string]string
+		expectErr    string
+	}{
+		{
+			name:         "single container",
+			images:       []string{"nginx=nginx:1.25"},
+			expectPatch:  true,
+			expectImages: map[string]string{"init": "busybox", "nginx": "nginx:1.25", "sidecar": "envoy"},
+		},
+		{
+			name:         "multiple containers",
+			images:       []string{"nginx=nginx:1.25", "sidecar=envoy:1.29"},
+			expectPatch:  true,
+			expectImages: map[string]string{"init": "busybox", "nginx": "nginx:1.25", "sidecar": "envoy:1.29"},
+		},
+		{
+			name:         "all containers",
+			images:       []string{"*=thingy"},
+			expectPatch:  true,
+			expectImages: map[string]string{"init": "thingy", "nginx": "thingy", "sidecar": "thingy"},
+		},
+		{
+			name:      "unknown container",
+			images:    []string{"missing=thingy"},
+			expectErr: `error: unable to find container named "missing", valid containers are: init, nginx, sidecar`,
+		},
+		{
+			name:   "client dry-run",
+			images: []string{"nginx=nginx:1.25"},
+			dryRun: "client",
+		},
+	}
+
+	for _, workload := range workloads {
+		for _, tc := range testCases {
+			t.Run(workload.name+" "+tc.name, func(t *testing.T) {
+				tf := cmdtesting.NewTestFactory().WithNamespace("test")
+				defer tf.Cleanup()
+
+				var patched bool
+				tf.Client = &fake.RESTClient{
+					GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+					NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+					Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+						switch p, m := req.URL.Path, req.Method; {
+						case p == workload.path && m == http.MethodGet:
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(workload.object)}, nil
+						case p == workload.path && m == http.MethodPatch:
+							patched = true
+							body, err := ioutil.ReadAll(req.Body)
+							if err != nil {
+								return nil, err
+							}
+							var patch struct {
+								Spec struct {
+									Template corev1.PodTemplateSpec `json:"template"`
+								} `json:"spec"`
+							}
+							assert.NoError(t, json.Unmarshal(body, &patch))
+							images := map[string]string{}
+							for _, c := range patch.Spec.Template.Spec.InitContainers {
+								images[c.Name] = c.Image
+							}
+							for _, c := range patch.Spec.Template.Spec.Containers {
+								images[c.Name] = c.Image
+							}
+							assert.Equal(t, tc.expectImages, images)
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(workload.object)}, nil
+						default:
+							t.Errorf("unexpected request: %s %#v", req.Method, req.URL)
+							return nil, fmt.Errorf("unexpected request")
+						}
+					}),
+				}
+
+				streams := genericclioptions.NewTestIOStreamsDiscard()
+				cmd := NewCmdImage(tf, streams)
+				if len(tc.dryRun) > 0 {
+					cmd.Flags().Set("dry-run", tc.dryRun)
+				}
+				opts := NewImageOptions(streams)
+				assert.NoError(t, opts.Complete(tf, cmd, append([]string{workload.arg}, tc.images...)))
+				assert.NoError(t, opts.Validate())
+
+				err := opts.Run()
+				if len(tc.expectErr) > 0 {
+					assert.EqualError(t, err, tc.expectErr)
+				} else {
+					assert.NoError(t, err)
+				}
+				assert.Equal(t, tc.expectPatch, patched)
+			})
+		}
+	}
+}
+
+func TestSetImageFromSource(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate", Image: "migrate:v3"}},
+			Containers:     []corev1.Container{{Name: "nginx", Image: "nginx:1.25"}, {Name: "sidecar", Image: "envoy:1.29"}},
+		}}},
+	}
+	cloneSet := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "nginx", Image: "nginx:1.20"}, {Name: "sidecar", Image: "envoy:1.20"}, {Name: "agent", Image: "agent:v1"}},
+		}}},
+	}
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	var patched bool
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/namespaces/test/deployments/web" && m == http.MethodGet:
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(deployment)}, nil
+			case p == "/namespaces/test/clonesets/web" && m == http.MethodGet:
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(cloneSet)}, nil
+			case p == "/namespaces/test/clonesets/web" && m == http.MethodPatch:
+				patched = true
+				body, err := ioutil.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				patch := &kruiseappsv1alpha1.CloneSet{}
+				assert.NoError(t, json.Unmarshal(body, patch))
+				images := map[string]string{}
+				for _, c := range patch.Spec.Template.Spec.Containers {
+					images[c.Name] = c.Image
+				}
+				// the images of both containers are copied, the container missing from the deployment is unchanged
+				assert.Equal(t, map[string]string{"nginx": "nginx:1.25", "sidecar": "envoy:1.29", "agent": "agent:v1"}, images)
+				assert.Empty(t, patch.Spec.Template.Spec.InitContainers)
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(cloneSet)}, nil
+			default:
+				t.Errorf("unexpected request: %s %#v", req.Method, req.URL)
+				return nil, fmt.Errorf("unexpected request")
+			}
+		}),
+	}
+
+	streams, _, _, errOut := genericclioptions.NewTestIOStreams()
+	cmd := NewCmdImage(tf, streams)
+	opts := NewImageOptions(streams)
+	opts.Source = "deployment/web"
+	assert.NoError(t, opts.Complete(tf, cmd, []string{"cloneset/web"}))
+	assert.Equal(t, map[string]string{"migrate": "migrate:v3", "nginx": "nginx:1.25", "sidecar": "envoy:1.29"}, opts.ContainerImages)
+	assert.NoError(t, opts.Validate())
+	assert.NoError(t, opts.Run())
+	assert.True(t, patched)
+	assert.Equal(t, "Warning: clonesets/web has no container named migrate, the images of these containers of deployment/web are not copied\n", errOut.String())
+
+	// the images are either copied from the source or given as arguments
+	opts = NewImageOptions(streams)
+	opts.Source = "deployment/web"
+	assert.EqualError(t, opts.Complete(tf, cmd, []string{"cloneset/web", "nginx=nginx:1.25"}), "--source cannot be used with CONTAINER_NAME=CONTAINER_IMAGE pairs")
+}
+
+func TestSetImageSidecarSet(t *testing.T) {
+	newSidecarSet := func(strategy kruiseappsv1alpha1.SidecarSetUpdateStrategy) *kruiseappsv1alpha1.SidecarSet {
+		return &kruiseappsv1alpha1.SidecarSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "SidecarSet"},
+			ObjectMeta: metav1.ObjectMeta{Name: "log-agent"},
+			Spec: kruiseappsv1alpha1.SidecarSetSpec{
+				InitContainers: []kruiseappsv1alpha1.SidecarContainer{{Container: corev1.Container{Name: "init", Image: "busybox"}}},
+				Containers:     []kruiseappsv1alpha1.SidecarContainer{{Container: corev1.Container{Name: "agent", Image: "agent:v1"}}},
+				UpdateStrategy: strategy,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		strategy     kruiseappsv1alpha1.SidecarSetUpdateStrategy
+		images       []string
+		dryRun       string
+		output       string
+		expectImages map[string]string
+		expectOut    string
+		expectErrOut string
+		expectErr    string
+	}{
+		{
+			name:         "rolling update",
+			images:       []string{"agent=agent:v2"},
+			expectImages: map[string]string{"init": "busybox", "agent": "agent:v2"},
+		},
+		{
+			name:         "not update",
+			strategy:     kruiseappsv1alpha1.SidecarSetUpdateStrategy{Type: kruiseappsv1alpha1.NotUpdateSidecarSetStrategyType},
+			images:       []string{"agent=agent:v2"},
+			expectImages: map[string]string{"init": "busybox", "agent": "agent:v2"},
+			expectErrOut: "Warning: sidecarsets/log-agent has the NotUpdate update strategy, the new images are only injected into new pods\n",
+		},
+		{
+			name:         "paused",
+			strategy:     kruiseappsv1alpha1.SidecarSetUpdateStrategy{Paused: true},
+			images:       []string{"agent=agent:v2"},
+			expectImages: map[string]string{"init": "busybox", "agent": "agent:v2"},
+			expectErrOut: "Warning: sidecarsets/log-agent is paused, the pods it was injected into are updated once it is resumed\n",
+		},
+		{
+			name:      "unknown container",
+			images:    []string{"missing=agent:v2"},
+			expectErr: `error: unable to find container named "missing", valid containers are: init, agent`,
+		},
+		{
+			name:      "client dry-run yaml",
+			images:    []string{"agent=agent:v2"},
+			dryRun:    "client",
+			output:    "yaml",
+			expectOut: "image: agent:v2",
+			expectErrOut: "RESOURCE                CONTAINER   OLD IMAGE   NEW IMAGE\n" +
+				"sidecarsets/log-agent   agent       agent:v1    agent:v2\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			// the mapper of the test factory maps every Kruise kind to a namespaced resource, so SidecarSets, which are
+			// cluster-scoped, are mapped with the root scope ahead of it
+			mapper, err := tf.ToRESTMapper()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sidecarSetMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{kruiseappsv1alpha1.SchemeGroupVersion})
+			sidecarSetMapper.Add(kruiseappsv1alpha1.SchemeGroupVersion.WithKind("SidecarSet"), meta.RESTScopeRoot)
+			tf.Factory = cmdutil.NewFactory(genericclioptions.NewTestConfigFlags().
+				WithClientConfig(tf.ToRawKubeConfigLoader()).
+				WithRESTMapper(meta.FirstHitRESTMapper{MultiRESTMapper: meta.MultiRESTMapper{sidecarSetMapper, mapper}}))
+
+			sidecarSet := newSidecarSet(tc.strategy)
+			var images map[string]string
+			tf.Client = &fake.RESTClient{
+				GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					switch p, m := req.URL.Path, req.Method; {
+					case p == "/sidecarsets/log-agent" && m == http.MethodGet:
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(sidecarSet)}, nil
+					case p == "/sidecarsets/log-agent" && m == http.MethodPatch:
+						body, err := ioutil.ReadAll(req.Body)
+						if err != nil {
+							return nil, err
+						}
+						var patch struct {
+							Spec kruiseappsv1alpha1.SidecarSetSpec `json:"spec"`
+						}
+						assert.NoError(t, json.Unmarshal(body, &patch))
+						images = map[string]string{}
+						for _, c := range append(patch.Spec.InitContainers, patch.Spec.Containers...) {
+							images[c.Name] = c.Image
+						}
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(sidecarSet)}, nil
+					default:
+						t.Errorf("unexpected request: %s %#v", req.Method, req.URL)
+						return nil, fmt.Errorf("unexpected request")
+					}
+				}),
+			}
+
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdImage(tf, streams)
+			if len(tc.dryRun) > 0 {
+				cmd.Flags().Set("dry-run", tc.dryRun)
+			}
+			if len(tc.output) > 0 {
+				cmd.Flags().Set("output", tc.output)
+			}
+			opts := NewImageOptions(streams)
+			if len(tc.output) > 0 {
+				opts.PrintFlags = genericclioptions.NewPrintFlags("").WithDefaultOutput(tc.output).WithTypeSetter(scheme.Scheme)
+			}
+			assert.NoError(t, opts.Complete(tf, cmd, append([]string{"sidecarset/log-agent"}, tc.images...)))
+			assert.NoError(t, opts.Validate())
+
+			err = opts.Run()
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectImages, images)
+			assert.Contains(t, out.String(), tc.expectOut)
+			assert.Equal(t, tc.expectErrOut, errOut.String())
+		})
+	}
+}
+
+func TestSetImageClientDryRunPrintsImageChanges(t *testing.T) {
+	cloneSet := &kruiseappsv1alpha1.CloneSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init", Image: "busybox"}},
+			Containers:     []corev1.Container{{Name: "nginx", Image: "nginx:1.24"}, {Name: "sidecar", Image: "envoy:1.29"}},
+		}}},
+	}
+
+	testCases := []struct {
+		name         string
+		images       []string
+		expectErrOut string
+		expectOut    string
+	}{
+		{
+			name:   "changed and unchanged containers",
+			images: []string{"nginx=nginx:1.25", "sidecar=envoy:1.29"},
+			expectErrOut: "RESOURCE        CONTAINER   OLD IMAGE    NEW IMAGE\n" +
+				"clonesets/web   nginx       nginx:1.24   nginx:1.25\n" +
+				"clonesets/web   sidecar     envoy:1.29   unchanged\n",
+			expectOut: "cloneset.apps.kruise.io/web image updated (dry run)\n",
+		},
+		{
+			name:   "no-op image",
+			images: []string{"nginx=nginx:1.24"},
+			expectErrOut: "RESOURCE        CONTAINER   OLD IMAGE    NEW IMAGE\n" +
+				"clonesets/web   nginx       nginx:1.24   unchanged\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			tf.Client = &fake.RESTClient{
+				GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path == "/namespaces/test/clonesets/web" && req.Method == http.MethodGet {
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(cloneSet)}, nil
+					}
+					t.Errorf("unexpected request: %s %#v", req.Method, req.URL)
+					return nil, fmt.Errorf("unexpected request")
+				}),
+			}
+
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			cmd := NewCmdImage(tf, streams)
+			cmd.Flags().Set("dry-run", "client")
+			opts := NewImageOptions(streams)
+			assert.NoError(t, opts.Complete(tf, cmd, append([]string{"cloneset/web"}, tc.images...)))
+			assert.NoError(t, opts.Validate())
+
+			assert.NoError(t, opts.Run())
+			assert.Equal(t, tc.expectErrOut, errOut.String())
+			assert.Equal(t, tc.expectOut, out.String())
+		})
+	}
+}
+
+// kruiseTestWorkload is a Kruise workload served by the fake server of a test at path, and given to a command as arg.
+type kruiseTestWorkload struct {
+	name   string
+	object runtime.Object
+	path   string
+	arg    string
+}
+
+// newKruiseTestWorkloads returns a CloneSet, an Advanced StatefulSet and an Advanced DaemonSet named web in the test
+// namespace, all with the given pod template.
+func newKruiseTestWorkloads(template corev1.PodTemplateSpec) []kruiseTestWorkload {
+	return []kruiseTestWorkload{
+		{
+			name: "cloneset",
+			object: &kruiseappsv1alpha1.CloneSet{
+				TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec:       kruiseappsv1alpha1.CloneSetSpec{Template: *template.DeepCopy()},
+			},
+			path: "/namespaces/test/clonesets/web",
+			arg:  "cloneset/web",
+		},
+		{
+			name: "advanced statefulset",
+			object: &kruiseappsv1beta1.StatefulSet{
+				TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1beta1.SchemeGroupVersion.String(), Kind: "StatefulSet"},
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec:       kruiseappsv1beta1.StatefulSetSpec{Template: *template.DeepCopy()},
+			},
+			path: "/namespaces/test/statefulsets/web",
+			arg:  "statefulsets.apps.kruise.io/web",
+		},
+		{
+			name: "advanced daemonset",
+			object: &kruiseappsv1alpha1.DaemonSet{
+				TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "DaemonSet"},
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec:       kruiseappsv1alpha1.DaemonSetSpec{Template: *template.DeepCopy()},
+			},
+			path: "/namespaces/test/daemonsets/web",
+			arg:  "daemonsets.apps.kruise.io/web",
+		},
+	}
+}
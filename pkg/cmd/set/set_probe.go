@@ -0,0 +1,477 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
+	"github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	probeLong = templates.LongDesc(i18n.T(`
+		Set or remove a liveness, readiness or startup probe on the containers of pod template resources.
+
+		The probe is an HTTP GET of --get-url, a TCP connection to the port of --open-tcp, or the
+		command given after --. The parameters of the probe, e.g. --period, are set along with it, or
+		alone to update the existing probe. --remove removes the selected probes.
+
+		Possible resources include (case insensitive):
+		` + resourcesResources))
+
+	probeExample = templates.Examples(i18n.T(`
+		# Set an HTTP liveness probe on the nginx container of cloneset web, checked every 10 seconds
+		kubectl-kruise set probe cloneset/web -c nginx --liveness --get-url=http://:8080/healthz --period=10
+
+		# Set a readiness probe opening a TCP connection to port 3306 on all the containers of asts/db
+		kubectl-kruise set probe asts/db --readiness --open-tcp=3306
+
+		# Set a startup probe running a command, failing after 30 attempts
+		kubectl-kruise set probe cloneset/web -c nginx --startup --failure-threshold=30 -- cat /tmp/started
+
+		# Only update the timeout of the existing readiness probe
+		kubectl-kruise set probe cloneset/web --readiness --timeout=3
+
+		# Remove the liveness and readiness probes of the nginx container
+		kubectl-kruise set probe cloneset/web -c nginx --liveness --readiness --remove`))
+)
+
+// SetProbeOptions is the start of the data required to perform the operation. As new fields are added, add them here instead of
+// referencing the cmd.Flags
+type SetProbeOptions struct {
+	resource.FilenameOptions
+
+	PrintFlags  *genericclioptions.PrintFlags
+	RecordFlags *genericclioptions.RecordFlags
+
+	Infos             []*resource.Info
+	Selector          string
+	ContainerSelector string
+	All               bool
+	Local             bool
+
+	DryRunStrategy cmdutil.DryRunStrategy
+
+	PrintObj printers.ResourcePrinterFunc
+	Recorder genericclioptions.Recorder
+
+	Liveness  bool
+	Readiness bool
+	Startup   bool
+	Remove    bool
+
+	GetURL  string
+	OpenTCP string
+	Command []string
+
+	// the parameters below are left as they are when negative
+	InitialDelaySeconds int
+	TimeoutSeconds      int
+	PeriodSeconds       int
+	SuccessThreshold    int
+	FailureThreshold    int
+
+	// handler is the probe handler built from --get-url, --open-tcp or the command, if any
+	handler *corev1.ProbeHandler
+
+	UpdatePodSpecForObject polymorphichelpers.UpdatePodSpecForObjectFunc
+
+	genericclioptions.IOStreams
+}
+
+// NewProbeOptions returns a SetProbeOptions indicating all containers in the selected
+// pod templates are selected by default.
+func NewProbeOptions(streams genericclioptions.IOStreams) *SetProbeOptions {
+	return &SetProbeOptions{
+		PrintFlags:  genericclioptions.NewPrintFlags("probes updated").WithTypeSetter(scheme.Scheme),
+		RecordFlags: genericclioptions.NewRecordFlags(),
+
+		Recorder: genericclioptions.NoopRecorder{},
+
+		ContainerSelector: "*",
+
+		InitialDelaySeconds: -1,
+		TimeoutSeconds:      -1,
+		PeriodSeconds:       -1,
+		SuccessThreshold:    -1,
+		FailureThreshold:    -1,
+
+		IOStreams: streams,
+	}
+}
+
+// NewCmdProbe returns initialized Command instance for the 'set probe' sub command
+func NewCmdProbe(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewProbeOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "probe (-f FILENAME | TYPE NAME) (--liveness | --readiness | --startup) (--get-url=URL | --open-tcp=PORT | --remove | -- COMMAND)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Update the probes of the containers of objects with pod templates"),
+		Long:                  probeLong,
+		Example:               probeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+	o.RecordFlags.AddFlags(cmd)
+
+	usage := "identifying the resource to get from a server."
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "Select all resources, including uninitialized ones, in the namespace of the specified resource types")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on, not including uninitialized ones,supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().StringVarP(&o.ContainerSelector, "containers", "c", o.ContainerSelector, "The names of containers in the selected pod templates to change, all containers are selected by default - may use wildcards")
+	cmd.Flags().BoolVar(&o.Local, "local", o.Local, "If true, set probe will NOT contact api-server but run locally.")
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().BoolVar(&o.Liveness, "liveness", o.Liveness, "Set or remove the liveness probe, which restarts the container when it fails.")
+	cmd.Flags().BoolVar(&o.Readiness, "readiness", o.Readiness, "Set or remove the readiness probe, which removes the pod from the endpoints of its services when it fails.")
+	cmd.Flags().BoolVar(&o.Startup, "startup", o.Startup, "Set or remove the startup probe, which holds the other probes until it succeeds.")
+	cmd.Flags().BoolVar(&o.Remove, "remove", o.Remove, "If true, remove the selected probes.")
+	cmd.Flags().StringVar(&o.GetURL, "get-url", o.GetURL, "The URL of an HTTP GET probe, e.g. http://:8080/healthz. The host defaults to the IP of the pod.")
+	cmd.Flags().StringVar(&o.OpenTCP, "open-tcp", o.OpenTCP, "The port, number or name, of a TCP probe which succeeds when the connection is opened.")
+	cmd.Flags().IntVar(&o.InitialDelaySeconds, "initial-delay", o.InitialDelaySeconds, "The seconds to wait after the container started before probing it.")
+	cmd.Flags().IntVar(&o.TimeoutSeconds, "timeout", o.TimeoutSeconds, "The seconds after which a probe times out.")
+	cmd.Flags().IntVar(&o.PeriodSeconds, "period", o.PeriodSeconds, "The seconds between two probes.")
+	cmd.Flags().IntVar(&o.SuccessThreshold, "success-threshold", o.SuccessThreshold, "The consecutive successes after a failure for the probe to succeed.")
+	cmd.Flags().IntVar(&o.FailureThreshold, "failure-threshold", o.FailureThreshold, "The consecutive failures for the probe to fail.")
+	return cmd
+}
+
+// Complete completes all required options
+func (o *SetProbeOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+
+	// the command of an exec probe is given after --
+	if n := cmd.ArgsLenAtDash(); n >= 0 {
+		o.Command = args[n:]
+		args = args[:n]
+	}
+
+	err = o.RecordFlags.Complete(cmd)
+	if err != nil {
+		return err
+	}
+
+	o.Recorder, err = o.RecordFlags.ToRecorder()
+	if err != nil {
+		return err
+	}
+
+	o.UpdatePodSpecForObject = polymorphichelpers.UpdatePodSpecForObjectFn
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	o.PrintObj = printer.PrintObj
+
+	cmdNamespace, enforceNamespace, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	// --local cannot query the api server for the resources given as arguments
+	if o.Local && len(args) > 0 {
+		return resource.LocalResourceError
+	}
+
+	o.Infos, err = internalcmdutil.BuildResources(f.NewBuilder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        cmdNamespace,
+		EnforceNamespace: enforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		LabelSelector:    o.Selector,
+		All:              o.All,
+		Args:             args,
+		Local:            o.Local,
+	}).Infos()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Validate makes sure that provided values in SetProbeOptions are valid
+func (o *SetProbeOptions) Validate() error {
+	if o.Local && o.DryRunStrategy == cmdutil.DryRunServer {
+		return fmt.Errorf("cannot specify --local and --dry-run=server - did you mean --dry-run=client?")
+	}
+	if o.All && len(o.Selector) > 0 {
+		return fmt.Errorf("cannot set --all and --selector at the same time")
+	}
+	if !o.Liveness && !o.Readiness && !o.Startup {
+		return fmt.Errorf("you must specify the probes to update with --liveness, --readiness or --startup")
+	}
+
+	handlers := 0
+	for _, set := range []bool{len(o.GetURL) > 0, len(o.OpenTCP) > 0, len(o.Command) > 0} {
+		if set {
+			handlers++
+		}
+	}
+	paramsSet := o.InitialDelaySeconds >= 0 || o.TimeoutSeconds >= 0 || o.PeriodSeconds >= 0 || o.SuccessThreshold >= 0 || o.FailureThreshold >= 0
+	if o.Remove {
+		if handlers > 0 || paramsSet {
+			return fmt.Errorf("--remove cannot be combined with a probe or its parameters")
+		}
+		return nil
+	}
+	if handlers > 1 {
+		return fmt.Errorf("only one of --get-url, --open-tcp or a command can be specified")
+	}
+	if handlers == 0 && !paramsSet {
+		return fmt.Errorf("you must specify a probe with --get-url, --open-tcp or a command after --, its parameters, or --remove")
+	}
+
+	if o.InitialDelaySeconds < -1 {
+		return fmt.Errorf("--initial-delay must be 0 or greater")
+	}
+	for _, param := range []struct {
+		flag  string
+		value int
+	}{
+		{"--timeout", o.TimeoutSeconds},
+		{"--period", o.PeriodSeconds},
+		{"--success-threshold", o.SuccessThreshold},
+		{"--failure-threshold", o.FailureThreshold},
+	} {
+		if param.value == 0 || param.value < -1 {
+			return fmt.Errorf("%s must be 1 or greater", param.flag)
+		}
+	}
+	if o.SuccessThreshold > 1 && (o.Liveness || o.Startup) {
+		return fmt.Errorf("--success-threshold must be 1 for liveness and startup probes")
+	}
+
+	var err error
+	switch {
+	case len(o.GetURL) > 0:
+		o.handler, err = httpGetHandler(o.GetURL)
+		if err != nil {
+			return fmt.Errorf("invalid --get-url: %v", err)
+		}
+	case len(o.OpenTCP) > 0:
+		port, err := probePort(o.OpenTCP)
+		if err != nil {
+			return fmt.Errorf("invalid --open-tcp: %v", err)
+		}
+		o.handler = &corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: port}}
+	case len(o.Command) > 0:
+		o.handler = &corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: o.Command}}
+	}
+	return nil
+}
+
+// Run performs the execution of 'set probe' sub command
+func (o *SetProbeOptions) Run() error {
+	var allErrs []error
+	patches := CalculatePatches(o.Infos, scheme.DefaultJSONEncoder(), func(obj runtime.Object) ([]byte, error) {
+		transformed := false
+		_, err := o.UpdatePodSpecForObject(obj, func(spec *corev1.PodSpec) error {
+			containers, _ := selectContainers(spec.Containers, o.ContainerSelector)
+			if len(containers) == 0 {
+				return fmt.Errorf("unable to find container named %s", o.ContainerSelector)
+			}
+			for _, c := range containers {
+				for _, probe := range o.selectedProbes(c) {
+					updated, err := o.updateProbe(*probe.probe)
+					if err != nil {
+						return fmt.Errorf("container %s: %s probe: %v", c.Name, probe.kind, err)
+					}
+					*probe.probe = updated
+				}
+				transformed = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !transformed {
+			return nil, nil
+		}
+		// record this change (for rollout history)
+		if err := o.Recorder.Record(obj); err != nil {
+			klog.V(4).Infof("error recording current command: %v", err)
+		}
+
+		return runtime.Encode(scheme.DefaultJSONEncoder(), obj)
+	})
+
+	for _, patch := range patches {
+		info := patch.Info
+		name := info.ObjectName()
+		if patch.Err != nil {
+			allErrs = append(allErrs, fmt.Errorf("error: %s %v\n", name, patch.Err))
+			continue
+		}
+
+		//no changes
+		if string(patch.Patch) == "{}" || len(patch.Patch) == 0 {
+			continue
+		}
+
+		if o.Local || o.DryRunStrategy == cmdutil.DryRunClient {
+			if err := o.PrintObj(info.Object, o.Out); err != nil {
+				allErrs = append(allErrs, err)
+			}
+			continue
+		}
+
+		patchType, data := patch.PatchData()
+		actual, err := resource.
+			NewHelper(info.Client, info.Mapping).
+			DryRun(o.DryRunStrategy == cmdutil.DryRunServer).
+			Patch(info.Namespace, info.Name, patchType, data, nil)
+		if err != nil {
+			allErrs = append(allErrs, fmt.Errorf("failed to patch probes update to pod template %v", err))
+			continue
+		}
+
+		if err := o.PrintObj(actual, o.Out); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+type containerProbe struct {
+	kind  string
+	probe **corev1.Probe
+}
+
+// selectedProbes returns the probes of c selected by --liveness, --readiness and --startup.
+func (o *SetProbeOptions) selectedProbes(c *corev1.Container) []containerProbe {
+	var probes []containerProbe
+	if o.Liveness {
+		probes = append(probes, containerProbe{kind: "liveness", probe: &c.LivenessProbe})
+	}
+	if o.Readiness {
+		probes = append(probes, containerProbe{kind: "readiness", probe: &c.ReadinessProbe})
+	}
+	if o.Startup {
+		probes = append(probes, containerProbe{kind: "startup", probe: &c.StartupProbe})
+	}
+	return probes
+}
+
+// updateProbe returns the probe updated with the handler and the parameters of the options, or nil with --remove.
+// The existing probe is updated in place of a new one, so that its parameters not given are kept.
+func (o *SetProbeOptions) updateProbe(probe *corev1.Probe) (*corev1.Probe, error) {
+	if o.Remove {
+		return nil, nil
+	}
+	if probe == nil {
+		if o.handler == nil {
+			return nil, fmt.Errorf("there is no probe to update, specify one with --get-url, --open-tcp or a command after --")
+		}
+		probe = &corev1.Probe{}
+	}
+	if o.handler != nil {
+		probe.ProbeHandler = *o.handler.DeepCopy()
+	}
+	if o.InitialDelaySeconds >= 0 {
+		probe.InitialDelaySeconds = int32(o.InitialDelaySeconds)
+	}
+	if o.TimeoutSeconds >= 0 {
+		probe.TimeoutSeconds = int32(o.TimeoutSeconds)
+	}
+	if o.PeriodSeconds >= 0 {
+		probe.PeriodSeconds = int32(o.PeriodSeconds)
+	}
+	if o.SuccessThreshold >= 0 {
+		probe.SuccessThreshold = int32(o.SuccessThreshold)
+	}
+	if o.FailureThreshold >= 0 {
+		probe.FailureThreshold = int32(o.FailureThreshold)
+	}
+	return probe, nil
+}
+
+// httpGetHandler parses the URL of an HTTP GET probe. The port is required, the host is left empty for the IP of the
+// pod.
+func httpGetHandler(rawURL string) (*corev1.ProbeHandler, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	var uriScheme corev1.URIScheme
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		uriScheme = corev1.URISchemeHTTP
+	case "https":
+		uriScheme = corev1.URISchemeHTTPS
+	default:
+		return nil, fmt.Errorf("the scheme must be http or https, got %q", u.Scheme)
+	}
+	if len(u.Port()) == 0 {
+		return nil, fmt.Errorf("a port must be specified, e.g. http://:8080/healthz")
+	}
+	port, err := probePort(u.Port())
+	if err != nil {
+		return nil, err
+	}
+	path := u.EscapedPath()
+	if len(u.RawQuery) > 0 {
+		path += "?" + u.RawQuery
+	}
+	if len(path) == 0 {
+		path = "/"
+	}
+	return &corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Scheme: uriScheme, Host: u.Hostname(), Port: port, Path: path}}, nil
+}
+
+// probePort parses a port number, or the name of a port of the container.
+func probePort(port string) (intstr.IntOrString, error) {
+	p := intstr.Parse(port)
+	if p.Type == intstr.Int {
+		if errs := validation.IsValidPortNum(p.IntValue()); len(errs) > 0 {
+			return p, fmt.Errorf("invalid port %s: %s", port, strings.Join(errs, ", "))
+		}
+		return p, nil
+	}
+	if errs := validation.IsValidPortName(port); len(errs) > 0 {
+		return p, fmt.Errorf("invalid port name %q: %s", port, strings.Join(errs, ", "))
+	}
+	return p, nil
+}
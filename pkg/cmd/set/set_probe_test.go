@@ -0,0 +1,272 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestSetProbeCloneSet(t *testing.T) {
+	liveness := &corev1.Probe{
+		ProbeHandler:   corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(80)}},
+		PeriodSeconds:  5,
+		TimeoutSeconds: 2,
+	}
+	testCases := []struct {
+		name           string
+		liveness       *corev1.Probe
+		configure      func(o *SetProbeOptions)
+		expectErr      string
+		expectPatch    bool
+		expectLiveness *corev1.Probe
+	}{
+		{
+			name: "add an HTTP liveness probe",
+			configure: func(o *SetProbeOptions) {
+				o.Liveness = true
+				o.GetURL = "http://:8080/healthz"
+				o.PeriodSeconds = 10
+			},
+			expectPatch: true,
+			expectLiveness: &corev1.Probe{
+				ProbeHandler:  corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Scheme: corev1.URISchemeHTTP, Port: intstr.FromInt(8080), Path: "/healthz"}},
+				PeriodSeconds: 10,
+			},
+		},
+		{
+			name:     "replace the handler of the liveness probe and keep its parameters",
+			liveness: liveness,
+			configure: func(o *SetProbeOptions) {
+				o.Liveness = true
+				o.GetURL = "https://localhost:8443/healthz?verbose=1"
+			},
+			expectPatch: true,
+			expectLiveness: &corev1.Probe{
+				ProbeHandler:   corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Scheme: corev1.URISchemeHTTPS, Host: "localhost", Port: intstr.FromInt(8443), Path: "/healthz?verbose=1"}},
+				PeriodSeconds:  5,
+				TimeoutSeconds: 2,
+			},
+		},
+		{
+			name:     "update the parameters of the liveness probe",
+			liveness: liveness,
+			configure: func(o *SetProbeOptions) {
+				o.Liveness = true
+				o.FailureThreshold = 6
+			},
+			expectPatch: true,
+			expectLiveness: &corev1.Probe{
+				ProbeHandler:     liveness.ProbeHandler,
+				PeriodSeconds:    5,
+				TimeoutSeconds:   2,
+				FailureThreshold: 6,
+			},
+		},
+		{
+			name:     "remove the liveness probe",
+			liveness: liveness,
+			configure: func(o *SetProbeOptions) {
+				o.Liveness = true
+				o.Remove = true
+			},
+			expectPatch: true,
+		},
+		{
+			name: "remove a missing probe",
+			configure: func(o *SetProbeOptions) {
+				o.Liveness = true
+				o.Remove = true
+			},
+		},
+		{
+			name: "update the parameters of a missing probe",
+			configure: func(o *SetProbeOptions) {
+				o.Readiness = true
+				o.PeriodSeconds = 10
+			},
+			expectErr: "container nginx: readiness probe: there is no probe to update, specify one with --get-url, --open-tcp or a command after --",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &kruiseappsv1alpha1.CloneSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec: kruiseappsv1alpha1.CloneSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "nginx", Image: "nginx", LivenessProbe: tc.liveness.DeepCopy()},
+						{Name: "sidecar", Image: "envoy"},
+					},
+				}}},
+			}
+
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			var patched bool
+			tf.Client = &fake.RESTClient{
+				GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					switch p, m := req.URL.Path, req.Method; {
+					case p == "/namespaces/test/clonesets/web" && m == http.MethodGet:
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(cs)}, nil
+					case p == "/namespaces/test/clonesets/web" && m == http.MethodPatch:
+						patched = true
+						assert.Equal(t, string(types.MergePatchType), req.Header.Get("Content-Type"))
+						body, err := ioutil.ReadAll(req.Body)
+						if err != nil {
+							return nil, err
+						}
+						patch := &kruiseappsv1alpha1.CloneSet{}
+						assert.NoError(t, json.Unmarshal(body, patch))
+						containers := patch.Spec.Template.Spec.Containers
+						if assert.Len(t, containers, 2) {
+							assert.Equal(t, tc.expectLiveness, containers[0].LivenessProbe)
+							assert.Nil(t, containers[1].LivenessProbe)
+						}
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(cs)}, nil
+					default:
+						t.Errorf("unexpected request: %s %#v", req.Method, req.URL)
+						return nil, fmt.Errorf("unexpected request")
+					}
+				}),
+			}
+
+			streams := genericclioptions.NewTestIOStreamsDiscard()
+			cmd := NewCmdProbe(tf, streams)
+			opts := NewProbeOptions(streams)
+			opts.ContainerSelector = "nginx"
+			tc.configure(opts)
+			assert.NoError(t, opts.Complete(tf, cmd, []string{"cloneset/web"}))
+			assert.NoError(t, opts.Validate())
+
+			err := opts.Run()
+			if len(tc.expectErr) > 0 {
+				assert.ErrorContains(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectPatch, patched)
+		})
+	}
+}
+
+func TestSetProbeValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		configure func(o *SetProbeOptions)
+		expectErr string
+	}{
+		{
+			name:      "no probe",
+			configure: func(o *SetProbeOptions) { o.GetURL = "http://:8080/healthz" },
+			expectErr: "you must specify the probes to update with --liveness, --readiness or --startup",
+		},
+		{
+			name:      "no update",
+			configure: func(o *SetProbeOptions) { o.Liveness = true },
+			expectErr: "you must specify a probe with --get-url, --open-tcp or a command after --, its parameters, or --remove",
+		},
+		{
+			name: "several handlers",
+			configure: func(o *SetProbeOptions) {
+				o.Liveness, o.GetURL, o.OpenTCP = true, "http://:8080/healthz", "8080"
+			},
+			expectErr: "only one of --get-url, --open-tcp or a command can be specified",
+		},
+		{
+			name: "remove with a handler",
+			configure: func(o *SetProbeOptions) {
+				o.Liveness, o.Remove, o.Command = true, true, []string{"true"}
+			},
+			expectErr: "--remove cannot be combined with a probe or its parameters",
+		},
+		{
+			name: "remove with parameters",
+			configure: func(o *SetProbeOptions) {
+				o.Readiness, o.Remove, o.PeriodSeconds = true, true, 10
+			},
+			expectErr: "--remove cannot be combined with a probe or its parameters",
+		},
+		{
+			name: "URL without port",
+			configure: func(o *SetProbeOptions) {
+				o.Liveness, o.GetURL = true, "http://localhost/healthz"
+			},
+			expectErr: "invalid --get-url: a port must be specified, e.g. http://:8080/healthz",
+		},
+		{
+			name: "URL of another scheme",
+			configure: func(o *SetProbeOptions) {
+				o.Liveness, o.GetURL = true, "tcp://:8080"
+			},
+			expectErr: `invalid --get-url: the scheme must be http or https, got "tcp"`,
+		},
+		{
+			name: "invalid port",
+			configure: func(o *SetProbeOptions) {
+				o.Readiness, o.OpenTCP = true, "70000"
+			},
+			expectErr: "invalid --open-tcp: invalid port 70000: must be between 1 and 65535, inclusive",
+		},
+		{
+			name: "zero period",
+			configure: func(o *SetProbeOptions) {
+				o.Readiness, o.OpenTCP, o.PeriodSeconds = true, "mysql", 0
+			},
+			expectErr: "--period must be 1 or greater",
+		},
+		{
+			name: "negative initial delay",
+			configure: func(o *SetProbeOptions) {
+				o.Readiness, o.OpenTCP, o.InitialDelaySeconds = true, "mysql", -5
+			},
+			expectErr: "--initial-delay must be 0 or greater",
+		},
+		{
+			name: "success threshold of a liveness probe",
+			configure: func(o *SetProbeOptions) {
+				o.Liveness, o.OpenTCP, o.SuccessThreshold = true, "8080", 2
+			},
+			expectErr: "--success-threshold must be 1 for liveness and startup probes",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := NewProbeOptions(genericclioptions.NewTestIOStreamsDiscard())
+			tc.configure(opts)
+			assert.EqualError(t, opts.Validate(), tc.expectErr)
+		})
+	}
+}
@@ -17,25 +17,22 @@ limitations under the License.
 package set
 
 import (
-	"errors"
 	"fmt"
+	"strings"
 
-	appsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
-	appsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	"github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
 	"github.com/spf13/cobra"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/meta"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/klog/v2"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
-	generateversioned "k8s.io/kubectl/pkg/generate/versioned"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -50,6 +47,8 @@ var (
 
 		for each compute resource, if a limit is specified and a request is omitted, the request will default to the limit.
 
+		A limit or request is removed by giving its resource name followed by a dash, e.g. --limits=cpu-.
+
 		Possible resources include (case insensitive): 
 		` + resourcesResources)
 
@@ -60,8 +59,8 @@ var (
 		# Set the resource request and limits for all containers in nginx
 		kubectl-kruise set resources cloneset sample --limits=cpu=200m,memory=512Mi --requests=cpu=100m,memory=256Mi
 
-		# Remove the resource requests for resources on containers in nginx
-		kubectl-kruise set resources cloneset sample --limits=cpu=0,memory=0 --requests=cpu=0,memory=0
+		# Remove the cpu limit and the memory request from the nginx container
+		kubectl-kruise set resources cloneset sample -c=nginx --limits=cpu- --requests=memory-
 
 		# Print the result (in yaml format) of updating nginx container limits from a local, without hitting the server
 		kubectl-kruise set resources -f path/to/file.yaml --limits=cpu=200m,memory=512Mi --local -o yaml`)
@@ -90,6 +89,8 @@ type SetResourcesOptions struct {
 	Limits               string
 	Requests             string
 	ResourceRequirements corev1.ResourceRequirements
+	limitsToRemove       []corev1.ResourceName
+	requestsToRemove     []corev1.ResourceName
 
 	UpdatePodSpecForObject polymorphichelpers.UpdatePodSpecForObjectFunc
 	Resources              []string
@@ -179,30 +180,22 @@ func (o *SetResourcesOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, ar
 		return err
 	}
 
-	builder := f.NewBuilder().
-		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		LocalParam(o.Local).
-		ContinueOnError().
-		NamespaceParam(cmdNamespace).DefaultNamespace().
-		FilenameParam(enforceNamespace, &o.FilenameOptions).
-		Flatten()
-
-	if !o.Local {
-		builder.LabelSelectorParam(o.Selector).
-			ResourceTypeOrNameArgs(o.All, args...).
-			Latest()
-	} else {
-		// if a --local flag was provided, and a resource was specified in the form
-		// <resource>/<name>, fail immediately as --local cannot query the api server
-		// for the specified resource.
-		// TODO: this should be in the builder - if someone specifies tuples, fail when
-		// local is true
-		if len(args) > 0 {
-			return resource.LocalResourceError
-		}
+	// if a --local flag was provided, and a resource was specified in the form
+	// <resource>/<name>, fail immediately as --local cannot query the api server
+	// for the specified resource.
+	if o.Local && len(args) > 0 {
+		return resource.LocalResourceError
 	}
 
-	o.Infos, err = builder.Do().Infos()
+	o.Infos, err = internalcmdutil.BuildResources(f.NewBuilder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        cmdNamespace,
+		EnforceNamespace: enforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		LabelSelector:    o.Selector,
+		All:              o.All,
+		Args:             args,
+		Local:            o.Local,
+	}).Infos()
 	if err != nil {
 		return err
 	}
@@ -222,9 +215,13 @@ func (o *SetResourcesOptions) Validate() error {
 		return fmt.Errorf("you must specify an update to requests or limits (in the form of --requests/--limits)")
 	}
 
-	o.ResourceRequirements, err = generateversioned.HandleResourceRequirementsV1(map[string]string{"limits": o.Limits, "requests": o.Requests})
+	o.ResourceRequirements.Limits, o.limitsToRemove, err = parseResourceList(o.Limits)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid --limits: %v", err)
+	}
+	o.ResourceRequirements.Requests, o.requestsToRemove, err = parseResourceList(o.Requests)
+	if err != nil {
+		return fmt.Errorf("invalid --requests: %v", err)
 	}
 
 	return nil
@@ -232,213 +229,113 @@ func (o *SetResourcesOptions) Validate() error {
 
 // Run performs the execution of 'set resources' sub command
 func (o *SetResourcesOptions) Run() error {
-
-	if len(o.Infos) == 0 {
-		return nil
-	}
-
-	switch o.Infos[0].Object.(type) {
-	case *appsv1alpha1.CloneSet:
-		var allErrs []error
+	var allErrs []error
+	patches := CalculatePatches(o.Infos, scheme.DefaultJSONEncoder(), func(obj runtime.Object) ([]byte, error) {
 		transformed := false
-
-		obj, err := resource.
-			NewHelper(o.Infos[0].Client, o.Infos[0].Mapping).
-			Get(o.Infos[0].Namespace, o.Infos[0].Name)
-		if err != nil {
-			return err
-		}
-		res := obj.(*appsv1alpha1.CloneSet)
-
-		containers, _ := selectContainers(res.Spec.Template.Spec.Containers, o.ContainerSelector)
-
-		_, err = meta.NewAccessor().Name(res)
-		if err != nil {
-			return err
-		}
-
-		if len(containers) != 0 {
+		_, err := o.UpdatePodSpecForObject(obj, func(spec *corev1.PodSpec) error {
+			containers, _ := selectContainers(spec.Containers, o.ContainerSelector)
+			if len(containers) == 0 {
+				return fmt.Errorf("unable to find container named %s", o.ContainerSelector)
+			}
 			for i := range containers {
-				if len(o.Limits) != 0 && len(containers[i].Resources.Limits) == 0 {
-					containers[i].Resources.Limits = make(corev1.ResourceList)
-				}
-				for key, value := range o.ResourceRequirements.Limits {
-					containers[i].Resources.Limits[key] = value
-				}
-
-				if len(o.Requests) != 0 && len(containers[i].Resources.Requests) == 0 {
-					containers[i].Resources.Requests = make(corev1.ResourceList)
-				}
-				for key, value := range o.ResourceRequirements.Requests {
-					containers[i].Resources.Requests[key] = value
-				}
+				containers[i].Resources.Limits = updateResourceList(containers[i].Resources.Limits, o.ResourceRequirements.Limits, o.limitsToRemove)
+				containers[i].Resources.Requests = updateResourceList(containers[i].Resources.Requests, o.ResourceRequirements.Requests, o.requestsToRemove)
 				transformed = true
 			}
-		} else {
-			allErrs = append(allErrs, fmt.Errorf("error: unable to find container named %s", o.ContainerSelector))
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 		if !transformed {
-			return nil
+			return nil, nil
 		}
-
 		// record this change (for rollout history)
-		if err := o.Recorder.Record(res); err != nil {
+		if err := o.Recorder.Record(obj); err != nil {
 			klog.V(4).Infof("error recording current command: %v", err)
 		}
 
-		if !o.Local {
-			_, err = resource.
-				NewHelper(o.Infos[0].Client, o.Infos[0].Mapping).
-				Replace(o.Infos[0].Namespace, o.Infos[0].Name, true, res)
-			if err != nil {
-				return err
-			}
-		}
+		return runtime.Encode(scheme.DefaultJSONEncoder(), obj)
+	})
 
-		if err := o.PrintObj(res, o.Out); err != nil {
-			return errors.New(err.Error())
+	for _, patch := range patches {
+		info := patch.Info
+		name := info.ObjectName()
+		if patch.Err != nil {
+			allErrs = append(allErrs, fmt.Errorf("error: %s %v\n", name, patch.Err))
+			continue
 		}
 
-		return utilerrors.NewAggregate(allErrs)
-	case *appsv1beta1.StatefulSet:
-		var allErrs []error
-		transformed := false
-
-		obj, err := resource.
-			NewHelper(o.Infos[0].Client, o.Infos[0].Mapping).
-			Get(o.Infos[0].Namespace, o.Infos[0].Name)
-		if err != nil {
-			return err
+		//no changes
+		if string(patch.Patch) == "{}" || len(patch.Patch) == 0 {
+			continue
 		}
-		res := obj.(*appsv1beta1.StatefulSet)
 
-		containers, _ := selectContainers(res.Spec.Template.Spec.Containers, o.ContainerSelector)
+		if o.Local || o.DryRunStrategy == cmdutil.DryRunClient {
+			if err := o.PrintObj(info.Object, o.Out); err != nil {
+				allErrs = append(allErrs, err)
+			}
+			continue
+		}
 
-		_, err = meta.NewAccessor().Name(res)
+		patchType, data := patch.PatchData()
+		actual, err := resource.
+			NewHelper(info.Client, info.Mapping).
+			DryRun(o.DryRunStrategy == cmdutil.DryRunServer).
+			Patch(info.Namespace, info.Name, patchType, data, nil)
 		if err != nil {
-			return err
+			allErrs = append(allErrs, fmt.Errorf("failed to patch resources update to pod template %v", err))
+			continue
 		}
 
-		if len(containers) != 0 {
-			for i := range containers {
-				if len(o.Limits) != 0 && len(containers[i].Resources.Limits) == 0 {
-					containers[i].Resources.Limits = make(corev1.ResourceList)
-				}
-				for key, value := range o.ResourceRequirements.Limits {
-					containers[i].Resources.Limits[key] = value
-				}
-
-				if len(o.Requests) != 0 && len(containers[i].Resources.Requests) == 0 {
-					containers[i].Resources.Requests = make(corev1.ResourceList)
-				}
-				for key, value := range o.ResourceRequirements.Requests {
-					containers[i].Resources.Requests[key] = value
-				}
-				transformed = true
-			}
-		} else {
-			allErrs = append(allErrs, fmt.Errorf("error: unable to find container named %s", o.ContainerSelector))
-		}
-		if !transformed {
-			return nil
+		if err := o.PrintObj(actual, o.Out); err != nil {
+			allErrs = append(allErrs, err)
 		}
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
 
-		// record this change (for rollout history)
-		if err := o.Recorder.Record(res); err != nil {
-			klog.V(4).Infof("error recording current command: %v", err)
+// parseResourceList parses a comma separated list of resource=quantity pairs. A resource
+// name followed by a dash, e.g. "cpu-", is returned in the list of resources to remove.
+func parseResourceList(spec string) (corev1.ResourceList, []corev1.ResourceName, error) {
+	if len(spec) == 0 {
+		return nil, nil, nil
+	}
+	list := corev1.ResourceList{}
+	var remove []corev1.ResourceName
+	for _, item := range strings.Split(spec, ",") {
+		if name := strings.TrimSuffix(item, "-"); name != item && len(name) > 0 && !strings.Contains(name, "=") {
+			remove = append(remove, corev1.ResourceName(name))
+			continue
 		}
-
-		if !o.Local {
-			_, err = resource.
-				NewHelper(o.Infos[0].Client, o.Infos[0].Mapping).
-				Replace(o.Infos[0].Namespace, o.Infos[0].Name, true, res)
-			if err != nil {
-				return err
-			}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, nil, fmt.Errorf("invalid resource %q, expected <resource>=<quantity> or <resource>-", item)
 		}
-
-		if err := o.PrintObj(res, o.Out); err != nil {
-			return errors.New(err.Error())
+		quantity, err := apiresource.ParseQuantity(parts[1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid quantity %q for resource %s: %v", parts[1], parts[0], err)
 		}
-
-		return utilerrors.NewAggregate(allErrs)
-	default:
-
-		var allErrs []error
-		patches := CalculatePatches(o.Infos, scheme.DefaultJSONEncoder(), func(obj runtime.Object) ([]byte, error) {
-			transformed := false
-			_, err := o.UpdatePodSpecForObject(obj, func(spec *corev1.PodSpec) error {
-				containers, _ := selectContainers(spec.Containers, o.ContainerSelector)
-				if len(containers) != 0 {
-					for i := range containers {
-						if len(o.Limits) != 0 && len(containers[i].Resources.Limits) == 0 {
-							containers[i].Resources.Limits = make(corev1.ResourceList)
-						}
-						for key, value := range o.ResourceRequirements.Limits {
-							containers[i].Resources.Limits[key] = value
-						}
-
-						if len(o.Requests) != 0 && len(containers[i].Resources.Requests) == 0 {
-							containers[i].Resources.Requests = make(corev1.ResourceList)
-						}
-						for key, value := range o.ResourceRequirements.Requests {
-							containers[i].Resources.Requests[key] = value
-						}
-						transformed = true
-					}
-				} else {
-					allErrs = append(allErrs, fmt.Errorf("error: unable to find container named %s", o.ContainerSelector))
-				}
-				return nil
-			})
-			if err != nil {
-				return nil, err
-			}
-			if !transformed {
-				return nil, nil
-			}
-			// record this change (for rollout history)
-			if err := o.Recorder.Record(obj); err != nil {
-				klog.V(4).Infof("error recording current command: %v", err)
-			}
-
-			return runtime.Encode(scheme.DefaultJSONEncoder(), obj)
-		})
-
-		for _, patch := range patches {
-			info := patch.Info
-			name := info.ObjectName()
-			if patch.Err != nil {
-				allErrs = append(allErrs, fmt.Errorf("error: %s %v\n", name, patch.Err))
-				continue
-			}
-
-			//no changes
-			if string(patch.Patch) == "{}" || len(patch.Patch) == 0 {
-				continue
-			}
-
-			if o.Local || o.DryRunStrategy == cmdutil.DryRunClient {
-				if err := o.PrintObj(info.Object, o.Out); err != nil {
-					allErrs = append(allErrs, err)
-				}
-				continue
-			}
-
-			actual, err := resource.
-				NewHelper(info.Client, info.Mapping).
-				DryRun(o.DryRunStrategy == cmdutil.DryRunServer).
-				Patch(info.Namespace, info.Name, types.StrategicMergePatchType, patch.Patch, nil)
-			if err != nil {
-				allErrs = append(allErrs, fmt.Errorf("failed to patch resources update to pod template %v", err))
-				continue
-			}
-
-			if err := o.PrintObj(actual, o.Out); err != nil {
-				allErrs = append(allErrs, err)
-			}
+		list[corev1.ResourceName(parts[0])] = quantity
+	}
+	for _, name := range remove {
+		if _, ok := list[name]; ok {
+			return nil, nil, fmt.Errorf("resource %s cannot be both set and removed", name)
 		}
-		return utilerrors.NewAggregate(allErrs)
+	}
+	return list, remove, nil
+}
 
+// updateResourceList sets the given quantities in current and removes the given resources from it.
+func updateResourceList(current, set corev1.ResourceList, remove []corev1.ResourceName) corev1.ResourceList {
+	if len(set) > 0 && current == nil {
+		current = corev1.ResourceList{}
+	}
+	for name, quantity := range set {
+		current[name] = quantity
+	}
+	for _, name := range remove {
+		delete(current, name)
 	}
+	return current
 }
@@ -17,12 +17,14 @@ limitations under the License.
 package set
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"testing"
 
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	appsv1beta1 "k8s.io/api/apps/v1beta1"
@@ -30,9 +32,12 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	restclient "k8s.io/client-go/rest"
@@ -516,3 +521,188 @@ func TestSetResourcesRemote(t *testing.T) {
 		})
 	}
 }
+
+func TestSetResourcesKruiseWorkloads(t *testing.T) {
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  "nginx",
+				Image: "nginx",
+				Resources: corev1.ResourceRequirements{
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: apiresource.MustParse("1"), corev1.ResourceMemory: apiresource.MustParse("1Gi")},
+					Requests: corev1.ResourceList{corev1.ResourceCPU: apiresource.MustParse("500m")},
+				},
+			},
+			{Name: "sidecar", Image: "envoy"},
+		},
+	}
+	workloads := newKruiseTestWorkloads(corev1.PodTemplateSpec{Spec: podSpec})
+	testCases := []struct {
+		name           string
+		limits         string
+		requests       string
+		containers     string
+		expectErr      string
+		expectPatch    bool
+		expectLimits   map[string]corev1.ResourceList
+		expectRequests map[string]corev1.ResourceList
+	}{
+		{
+			name:        "set limits",
+			limits:      "cpu=500m,memory=256Mi",
+			containers:  "nginx",
+			expectPatch: true,
+			expectLimits: map[string]corev1.ResourceList{
+				"nginx": {corev1.ResourceCPU: apiresource.MustParse("500m"), corev1.ResourceMemory: apiresource.MustParse("256Mi")},
+			},
+			expectRequests: map[string]corev1.ResourceList{
+				"nginx": {corev1.ResourceCPU: apiresource.MustParse("500m")},
+			},
+		},
+		{
+			name:        "set requests",
+			requests:    "cpu=250m",
+			containers:  "sidecar",
+			expectPatch: true,
+			expectLimits: map[string]corev1.ResourceList{
+				"nginx": {corev1.ResourceCPU: apiresource.MustParse("1"), corev1.ResourceMemory: apiresource.MustParse("1Gi")},
+			},
+			expectRequests: map[string]corev1.ResourceList{
+				"nginx":   {corev1.ResourceCPU: apiresource.MustParse("500m")},
+				"sidecar": {corev1.ResourceCPU: apiresource.MustParse("250m")},
+			},
+		},
+		{
+			name:        "wildcard",
+			limits:      "memory=2Gi",
+			containers:  "*",
+			expectPatch: true,
+			expectLimits: map[string]corev1.ResourceList{
+				"nginx":   {corev1.ResourceCPU: apiresource.MustParse("1"), corev1.ResourceMemory: apiresource.MustParse("2Gi")},
+				"sidecar": {corev1.ResourceMemory: apiresource.MustParse("2Gi")},
+			},
+			expectRequests: map[string]corev1.ResourceList{
+				"nginx": {corev1.ResourceCPU: apiresource.MustParse("500m")},
+			},
+		},
+		{
+			name:        "remove",
+			limits:      "cpu-",
+			requests:    "cpu-",
+			containers:  "nginx",
+			expectPatch: true,
+			expectLimits: map[string]corev1.ResourceList{
+				"nginx": {corev1.ResourceMemory: apiresource.MustParse("1Gi")},
+			},
+			expectRequests: map[string]corev1.ResourceList{},
+		},
+		{
+			name:       "unknown container",
+			limits:     "cpu=500m",
+			containers: "app",
+			expectErr:  "unable to find container named app",
+		},
+	}
+
+	for _, workload := range workloads {
+		for _, tc := range testCases {
+			t.Run(workload.name+" "+tc.name, func(t *testing.T) {
+				tf := cmdtesting.NewTestFactory().WithNamespace("test")
+				defer tf.Cleanup()
+
+				var patched bool
+				tf.Client = &fake.RESTClient{
+					GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+					NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+					Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+						switch p, m := req.URL.Path, req.Method; {
+						case p == workload.path && m == http.MethodGet:
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(workload.object)}, nil
+						case p == workload.path && m == http.MethodPatch:
+							patched = true
+							assert.Equal(t, string(types.MergePatchType), req.Header.Get("Content-Type"))
+							body, err := ioutil.ReadAll(req.Body)
+							if err != nil {
+								return nil, err
+							}
+							var patch struct {
+								Spec struct {
+									Template corev1.PodTemplateSpec `json:"template"`
+								} `json:"spec"`
+							}
+							assert.NoError(t, json.Unmarshal(body, &patch))
+							limits, requests := map[string]corev1.ResourceList{}, map[string]corev1.ResourceList{}
+							for _, c := range patch.Spec.Template.Spec.Containers {
+								if len(c.Resources.Limits) > 0 {
+									limits[c.Name] = c.Resources.Limits
+								}
+								if len(c.Resources.Requests) > 0 {
+									requests[c.Name] = c.Resources.Requests
+								}
+							}
+							assert.True(t, apiequality.Semantic.DeepEqual(tc.expectLimits, limits), "unexpected limits %v", limits)
+							assert.True(t, apiequality.Semantic.DeepEqual(tc.expectRequests, requests), "unexpected requests %v", requests)
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(workload.object)}, nil
+						default:
+							t.Errorf("unexpected request: %s %#v", req.Method, req.URL)
+							return nil, fmt.Errorf("unexpected request")
+						}
+					}),
+				}
+
+				streams := genericclioptions.NewTestIOStreamsDiscard()
+				cmd := NewCmdResources(tf, streams)
+				opts := NewResourcesOptions(streams)
+				opts.Limits = tc.limits
+				opts.Requests = tc.requests
+				opts.ContainerSelector = tc.containers
+				assert.NoError(t, opts.Complete(tf, cmd, []string{workload.arg}))
+				assert.NoError(t, opts.Validate())
+
+				err := opts.Run()
+				if len(tc.expectErr) > 0 {
+					assert.ErrorContains(t, err, tc.expectErr)
+				} else {
+					assert.NoError(t, err)
+				}
+				assert.Equal(t, tc.expectPatch, patched)
+			})
+		}
+	}
+}
+
+func TestSetResourcesValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		limits    string
+		requests  string
+		expectErr string
+	}{
+		{
+			name:      "no update",
+			expectErr: "you must specify an update to requests or limits (in the form of --requests/--limits)",
+		},
+		{
+			name:      "invalid quantity",
+			limits:    "cpu=lots",
+			expectErr: `invalid --limits: invalid quantity "lots" for resource cpu: quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'`,
+		},
+		{
+			name:      "missing quantity",
+			requests:  "memory",
+			expectErr: `invalid --requests: invalid resource "memory", expected <resource>=<quantity> or <resource>-`,
+		},
+		{
+			name:      "set and remove",
+			limits:    "cpu=1,cpu-",
+			expectErr: "invalid --limits: resource cpu cannot be both set and removed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &SetResourcesOptions{Limits: tc.limits, Requests: tc.requests}
+			assert.EqualError(t, opts.Validate(), tc.expectErr)
+		})
+	}
+}
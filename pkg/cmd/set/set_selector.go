@@ -17,12 +17,20 @@ limitations under the License.
 package set
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -68,7 +76,8 @@ var (
 
 		A selector must begin with a letter or number, and may contain letters, numbers, hyphens, dots, and underscores, up to %[1]d characters.
 		If --resource-version is specified, then updates will use this resource version, otherwise the existing resource-version will be used.
-        Note: currently selectors can only be set on Service objects.`)
+        Note: currently selectors can only be set on Service objects. The selector of a workload, such as a CloneSet or a Deployment,
+        is immutable after creation, so the command fails before sending any request for it.`)
 	selectorExample = templates.Examples(`
         # set the labels and selector before creating a deployment/service pair.
         kubectl create service clusterip my-svc --clusterip="None" -o yaml --dry-run=client | kubectl-kruise set selector --local -f - 'environment=qa' -o yaml | kubectl create -f -
@@ -218,7 +227,27 @@ func (o *SetSelectorOptions) RunSelector() error {
 	})
 }
 
+// errSelectorImmutable is returned for the workloads whose selector is rejected by the API server once they are created.
+var errSelectorImmutable = errors.New("selector is immutable after creation")
+
+// hasImmutableSelector returns whether spec.selector of obj cannot be changed once obj is created.
+func hasImmutableSelector(obj runtime.Object) bool {
+	switch obj.(type) {
+	case *appsv1.Deployment, *appsv1.StatefulSet, *appsv1.DaemonSet, *appsv1.ReplicaSet,
+		*appsv1beta2.Deployment, *appsv1beta2.StatefulSet, *appsv1beta2.DaemonSet, *appsv1beta2.ReplicaSet,
+		*appsv1beta1.Deployment, *appsv1beta1.StatefulSet,
+		*extensionsv1beta1.Deployment, *extensionsv1beta1.DaemonSet, *extensionsv1beta1.ReplicaSet,
+		*batchv1.Job,
+		*kruiseappsv1alpha1.CloneSet, *kruiseappsv1alpha1.StatefulSet, *kruiseappsv1beta1.StatefulSet, *kruiseappsv1alpha1.DaemonSet:
+		return true
+	}
+	return false
+}
+
 func updateSelectorForObject(obj runtime.Object, selector metav1.LabelSelector) error {
+	if hasImmutableSelector(obj) {
+		return errSelectorImmutable
+	}
 	copyOldSelector := func() (map[string]string, error) {
 		if len(selector.MatchExpressions) > 0 {
 			return nil, fmt.Errorf("match expression %v not supported on this object", selector.MatchExpressions)
@@ -21,7 +21,9 @@ import (
 	"strings"
 	"testing"
 
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
@@ -340,3 +342,40 @@ func TestSelectorTest(t *testing.T) {
 		t.Errorf("did not set selector: %s", buf.String())
 	}
 }
+
+func TestSelectorImmutableWorkload(t *testing.T) {
+	workloads := []runtime.Object{
+		&kruiseappsv1alpha1.CloneSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "some-ns", Name: "web"},
+			Spec:       kruiseappsv1alpha1.CloneSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		},
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "some-ns", Name: "web"},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		},
+	}
+
+	labelToSet, err := metav1.ParseToLabelSelector("environment=qa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, workload := range workloads {
+		t.Run(workload.GetObjectKind().GroupVersionKind().Kind, func(t *testing.T) {
+			iostreams, _, buf, _ := genericclioptions.NewTestIOStreams()
+			o := &SetSelectorOptions{
+				selector: labelToSet,
+				// the info has no client, so any request to the server would fail with another error
+				ResourceFinder: genericclioptions.NewSimpleFakeResourceFinder(&resource.Info{Object: workload}),
+				WriteToServer:  true,
+				Recorder:       genericclioptions.NoopRecorder{},
+				PrintObj:       (&printers.NamePrinter{}).PrintObj,
+				IOStreams:      iostreams,
+			}
+
+			assert.EqualError(t, o.RunSelector(), "selector is immutable after creation")
+			assert.Empty(t, buf.String())
+		})
+	}
+}
@@ -19,7 +19,9 @@ package set
 import (
 	"errors"
 	"fmt"
+	"strings"
 
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	"github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
 	"github.com/spf13/cobra"
 
@@ -27,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -39,7 +42,8 @@ import (
 
 var (
 	serviceaccountResources = `
-	replicationcontroller (rc), deployment (deploy), daemonset (ds), job, replicaset (rs), statefulset, cloneset (cs)`
+	replicationcontroller (rc), deployment (deploy), daemonset (ds), job, replicaset (rs), statefulset, cloneset (cs),
+	statefulset.apps.kruise.io (asts), daemonset.apps.kruise.io (ads)`
 
 	serviceaccountLong = templates.LongDesc(i18n.T(`
 	Update ServiceAccount of pod template resources.
@@ -51,6 +55,9 @@ var (
 	# Set Deployment nginx-deployment's ServiceAccount to serviceaccount1
 	kubectl-kruise set serviceaccount cloneset sample serviceaccount1
 
+	# Set the ServiceAccount of Advanced StatefulSet web to serviceaccount2, and print the result without updating it
+	kubectl-kruise set serviceaccount asts/web serviceaccount2 --dry-run=server -o yaml
+
 	# Print the result (in yaml format) of updated cloneset with serviceaccount from local file, without hitting apiserver
 	kubectl-kruise set sa -f CloneSet.yaml serviceaccount1 --local --dry-run=client -o yaml
 	`))
@@ -154,19 +161,18 @@ func (o *SetServiceAccountOptions) Complete(f cmdutil.Factory, cmd *cobra.Comman
 		return errors.New("serviceaccount is required")
 	}
 	o.serviceAccountName = args[len(args)-1]
-	resources := args[:len(args)-1]
-	builder := f.NewBuilder().
-		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		LocalParam(o.local).
-		ContinueOnError().
-		NamespaceParam(cmdNamespace).DefaultNamespace().
-		FilenameParam(enforceNamespace, &o.fileNameOptions).
-		Flatten()
-	if !o.local {
-		builder.ResourceTypeOrNameArgs(o.all, resources...).
-			Latest()
+	if errs := validation.IsDNS1123Subdomain(o.serviceAccountName); len(errs) > 0 {
+		return fmt.Errorf("invalid serviceaccount name %q: %s", o.serviceAccountName, strings.Join(errs, ", "))
 	}
-	o.infos, err = builder.Do().Infos()
+	resources := args[:len(args)-1]
+	o.infos, err = internalcmdutil.BuildResources(f.NewBuilder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        cmdNamespace,
+		EnforceNamespace: enforceNamespace,
+		FilenameOptions:  &o.fileNameOptions,
+		All:              o.all,
+		Args:             resources,
+		Local:            o.local,
+	}).Infos()
 	if err != nil {
 		return err
 	}
@@ -178,6 +184,10 @@ func (o *SetServiceAccountOptions) Run() error {
 	var patchErrs []error
 	patchFn := func(obj runtime.Object) ([]byte, error) {
 		_, err := o.updatePodSpecForObject(obj, func(podSpec *corev1.PodSpec) error {
+			// a sidecarset has containers to inject, but no pod spec of its own
+			if podSpec == nil {
+				return fmt.Errorf("a sidecarset has no pod template to set the serviceaccount of")
+			}
 			podSpec.ServiceAccountName = o.serviceAccountName
 			return nil
 		})
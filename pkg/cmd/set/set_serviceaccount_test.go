@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"testing"
 
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	appsv1beta1 "k8s.io/api/apps/v1beta1"
@@ -365,6 +366,113 @@ func TestSetServiceAccountRemote(t *testing.T) {
 	}
 }
 
+func TestSetServiceAccountKruiseWorkloads(t *testing.T) {
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		ServiceAccountName: "old-sa",
+		Containers:         []corev1.Container{{Name: "nginx", Image: "nginx"}},
+	}}
+	workloads := newKruiseTestWorkloads(template)
+	testCases := []struct {
+		name        string
+		dryRun      string
+		output      string
+		expectQuery string
+		expectOut   string
+	}{
+		{
+			name:      "patch",
+			expectOut: "web serviceaccount updated\n",
+		},
+		{
+			name:      "client dry-run",
+			dryRun:    "client",
+			output:    "yaml",
+			expectOut: "serviceAccountName: new-sa",
+		},
+		{
+			name:        "server dry-run",
+			dryRun:      "server",
+			expectQuery: "dryRun=All",
+			expectOut:   "web serviceaccount updated (server dry run)\n",
+		},
+	}
+
+	for _, workload := range workloads {
+		for _, tc := range testCases {
+			t.Run(workload.name+" "+tc.name, func(t *testing.T) {
+				tf := cmdtesting.NewTestFactory().WithNamespace("test")
+				defer tf.Cleanup()
+
+				var patched bool
+				tf.Client = &fake.RESTClient{
+					GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+					NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+					Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+						switch p, m := req.URL.Path, req.Method; {
+						case p == workload.path && m == http.MethodGet:
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(workload.object)}, nil
+						case p == workload.path && m == http.MethodPatch:
+							patched = true
+							body, err := ioutil.ReadAll(req.Body)
+							if err != nil {
+								return nil, err
+							}
+							assert.Contains(t, string(body), `"serviceAccountName":"new-sa"`)
+							assert.Equal(t, tc.expectQuery, req.URL.RawQuery)
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(workload.object)}, nil
+						default:
+							t.Errorf("unexpected request: %s %#v", req.Method, req.URL)
+							return nil, fmt.Errorf("unexpected request")
+						}
+					}),
+				}
+
+				streams, _, out, _ := genericclioptions.NewTestIOStreams()
+				cmd := NewCmdServiceAccount(tf, streams)
+				if len(tc.dryRun) > 0 {
+					cmd.Flags().Set("dry-run", tc.dryRun)
+				}
+				if len(tc.output) > 0 {
+					cmd.Flags().Set("output", tc.output)
+				}
+				opts := NewSetServiceAccountOptions(streams)
+				opts.PrintFlags.OutputFormat = &tc.output
+				assert.NoError(t, opts.Complete(tf, cmd, []string{workload.arg, "new-sa"}))
+
+				assert.NoError(t, opts.Run())
+				assert.Equal(t, tc.dryRun != "client", patched)
+				assert.Contains(t, out.String(), tc.expectOut)
+			})
+		}
+	}
+}
+
+func TestSetServiceAccountSidecarSet(t *testing.T) {
+	sidecarSet := &kruiseappsv1alpha1.SidecarSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "SidecarSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "log-agent"},
+	}
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet {
+				t.Errorf("unexpected request: %s %#v", req.Method, req.URL)
+				return nil, fmt.Errorf("unexpected request")
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(sidecarSet)}, nil
+		}),
+	}
+
+	streams := genericclioptions.NewTestIOStreamsDiscard()
+	cmd := NewCmdServiceAccount(tf, streams)
+	opts := NewSetServiceAccountOptions(streams)
+	assert.NoError(t, opts.Complete(tf, cmd, []string{"sidecarset/log-agent", "new-sa"}))
+	assert.EqualError(t, opts.Run(), "error: sidecarsets/log-agent a sidecarset has no pod template to set the serviceaccount of\n")
+}
+
 func TestServiceAccountValidation(t *testing.T) {
 	inputs := []struct {
 		name        string
@@ -373,6 +481,11 @@ func TestServiceAccountValidation(t *testing.T) {
 	}{
 		{name: "test service account missing", args: []string{}, errorString: serviceAccountMissingErrString},
 		{name: "test service account resource missing", args: []string{serviceAccount}, errorString: resourceMissingErrString},
+		{
+			name:        "test service account name invalid",
+			args:        []string{"cloneset/web", "New_SA"},
+			errorString: `invalid serviceaccount name "New_SA": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+		},
 	}
 	for _, input := range inputs {
 		t.Run(input.name, func(t *testing.T) {
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strings"
 
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
 	"github.com/spf13/cobra"
 
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -139,29 +140,22 @@ func (o *SubjectOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []
 		return err
 	}
 
-	builder := f.NewBuilder().
-		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
-		LocalParam(o.Local).
-		ContinueOnError().
-		NamespaceParam(o.namespace).DefaultNamespace().
-		FilenameParam(enforceNamespace, &o.FilenameOptions).
-		Flatten()
-
-	if o.Local {
-		// if a --local flag was provided, and a resource was specified in the form
-		// <resource>/<name>, fail immediately as --local cannot query the api server
-		// for the specified resource.
-		if len(args) > 0 {
-			return resource.LocalResourceError
-		}
-	} else {
-		builder = builder.
-			LabelSelectorParam(o.Selector).
-			ResourceTypeOrNameArgs(o.All, args...).
-			Latest()
+	// if a --local flag was provided, and a resource was specified in the form
+	// <resource>/<name>, fail immediately as --local cannot query the api server
+	// for the specified resource.
+	if o.Local && len(args) > 0 {
+		return resource.LocalResourceError
 	}
 
-	o.Infos, err = builder.Do().Infos()
+	o.Infos, err = internalcmdutil.BuildResources(f.NewBuilder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        o.namespace,
+		EnforceNamespace: enforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		LabelSelector:    o.Selector,
+		All:              o.All,
+		Args:             args,
+		Local:            o.Local,
+	}).Infos()
 	if err != nil {
 		return err
 	}
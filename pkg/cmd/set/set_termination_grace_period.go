@@ -0,0 +1,264 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	internalcmdutil "github.com/openkruise/kruise-tools/pkg/cmd/util"
+	"github.com/openkruise/kruise-tools/pkg/internal/polymorphichelpers"
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	terminationGracePeriodLong = templates.LongDesc(i18n.T(`
+		Update the termination grace period of pod template resources.
+
+		The termination grace period is the number of seconds the containers of a pod are given to
+		shut down once they are sent a termination signal, before they are killed. 0 kills them
+		right away.
+
+		Possible resources include (case insensitive):
+		` + resourcesResources))
+
+	terminationGracePeriodExample = templates.Examples(i18n.T(`
+		# Give the pods of cloneset web 60 seconds to shut down
+		kubectl-kruise set termination-grace-period cloneset/web 60
+
+		# Set the termination grace period of the pods of asts/db, and print the result without updating it
+		kubectl-kruise set termination-grace-period asts/db 120 --dry-run=server -o yaml
+
+		# Print the advanced daemonset of a local file with the termination grace period updated, without hitting the apiserver
+		kubectl-kruise set termination-grace-period -f daemonset.yaml 30 --local -o yaml`))
+)
+
+// SetTerminationGracePeriodOptions is the start of the data required to perform the operation. As new fields are
+// added, add them here instead of referencing the cmd.Flags
+type SetTerminationGracePeriodOptions struct {
+	resource.FilenameOptions
+
+	PrintFlags  *genericclioptions.PrintFlags
+	RecordFlags *genericclioptions.RecordFlags
+
+	Infos    []*resource.Info
+	Selector string
+	All      bool
+	Local    bool
+
+	DryRunStrategy cmdutil.DryRunStrategy
+
+	PrintObj printers.ResourcePrinterFunc
+	Recorder genericclioptions.Recorder
+
+	// Seconds is the termination grace period to set, given as the last argument
+	Seconds int64
+
+	UpdatePodSpecForObject polymorphichelpers.UpdatePodSpecForObjectFunc
+
+	genericclioptions.IOStreams
+}
+
+// NewTerminationGracePeriodOptions returns an initialized SetTerminationGracePeriodOptions instance
+func NewTerminationGracePeriodOptions(streams genericclioptions.IOStreams) *SetTerminationGracePeriodOptions {
+	return &SetTerminationGracePeriodOptions{
+		PrintFlags:  genericclioptions.NewPrintFlags("termination grace period updated").WithTypeSetter(scheme.Scheme),
+		RecordFlags: genericclioptions.NewRecordFlags(),
+
+		Recorder: genericclioptions.NoopRecorder{},
+
+		IOStreams: streams,
+	}
+}
+
+// NewCmdTerminationGracePeriod returns initialized Command instance for the 'set termination-grace-period' sub command
+func NewCmdTerminationGracePeriod(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewTerminationGracePeriodOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "termination-grace-period (-f FILENAME | TYPE NAME) SECONDS",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Update the termination grace period of objects with pod templates"),
+		Long:                  terminationGracePeriodLong,
+		Example:               terminationGracePeriodExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+	o.RecordFlags.AddFlags(cmd)
+
+	usage := "identifying the resource to get from a server."
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "Select all resources, including uninitialized ones, in the namespace of the specified resource types")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on, not including uninitialized ones,supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVar(&o.Local, "local", o.Local, "If true, set termination-grace-period will NOT contact api-server but run locally.")
+	cmdutil.AddDryRunFlag(cmd)
+	return cmd
+}
+
+// Complete completes all required options
+func (o *SetTerminationGracePeriodOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+
+	if len(args) == 0 {
+		return errors.New("the termination grace period in seconds is required")
+	}
+	o.Seconds, err = strconv.ParseInt(args[len(args)-1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid termination grace period %q: must be a number of seconds", args[len(args)-1])
+	}
+	args = args[:len(args)-1]
+
+	err = o.RecordFlags.Complete(cmd)
+	if err != nil {
+		return err
+	}
+
+	o.Recorder, err = o.RecordFlags.ToRecorder()
+	if err != nil {
+		return err
+	}
+
+	o.UpdatePodSpecForObject = polymorphichelpers.UpdatePodSpecForObjectFn
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	o.PrintObj = printer.PrintObj
+
+	cmdNamespace, enforceNamespace, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	// --local cannot query the api server for the resources given as arguments
+	if o.Local && len(args) > 0 {
+		return resource.LocalResourceError
+	}
+
+	o.Infos, err = internalcmdutil.BuildResources(f.NewBuilder(), internalcmdutil.ResourceBuilderOptions{
+		Namespace:        cmdNamespace,
+		EnforceNamespace: enforceNamespace,
+		FilenameOptions:  &o.FilenameOptions,
+		LabelSelector:    o.Selector,
+		All:              o.All,
+		Args:             args,
+		Local:            o.Local,
+	}).Infos()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Validate makes sure that provided values in SetTerminationGracePeriodOptions are valid
+func (o *SetTerminationGracePeriodOptions) Validate() error {
+	if o.Local && o.DryRunStrategy == cmdutil.DryRunServer {
+		return fmt.Errorf("cannot specify --local and --dry-run=server - did you mean --dry-run=client?")
+	}
+	if o.All && len(o.Selector) > 0 {
+		return fmt.Errorf("cannot set --all and --selector at the same time")
+	}
+	if o.Seconds < 0 {
+		return fmt.Errorf("the termination grace period must be 0 or greater, got %d", o.Seconds)
+	}
+	return nil
+}
+
+// Run performs the execution of 'set termination-grace-period' sub command
+func (o *SetTerminationGracePeriodOptions) Run() error {
+	var allErrs []error
+	patches := CalculatePatches(o.Infos, scheme.DefaultJSONEncoder(), func(obj runtime.Object) ([]byte, error) {
+		_, err := o.UpdatePodSpecForObject(obj, func(spec *corev1.PodSpec) error {
+			// a sidecarset has containers to inject, but no pod spec of its own
+			if spec == nil {
+				return fmt.Errorf("a sidecarset has no pod template to set the termination grace period of")
+			}
+			seconds := o.Seconds
+			spec.TerminationGracePeriodSeconds = &seconds
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		// record this change (for rollout history)
+		if err := o.Recorder.Record(obj); err != nil {
+			klog.V(4).Infof("error recording current command: %v", err)
+		}
+
+		return runtime.Encode(scheme.DefaultJSONEncoder(), obj)
+	})
+
+	for _, patch := range patches {
+		info := patch.Info
+		name := info.ObjectName()
+		if patch.Err != nil {
+			allErrs = append(allErrs, fmt.Errorf("error: %s %v\n", name, patch.Err))
+			continue
+		}
+
+		if o.Local || o.DryRunStrategy == cmdutil.DryRunClient {
+			if err := o.PrintObj(info.Object, o.Out); err != nil {
+				allErrs = append(allErrs, err)
+			}
+			continue
+		}
+
+		//no changes
+		if string(patch.Patch) == "{}" || len(patch.Patch) == 0 {
+			continue
+		}
+
+		patchType, data := patch.PatchData()
+		actual, err := resource.
+			NewHelper(info.Client, info.Mapping).
+			DryRun(o.DryRunStrategy == cmdutil.DryRunServer).
+			Patch(info.Namespace, info.Name, patchType, data, nil)
+		if err != nil {
+			allErrs = append(allErrs, fmt.Errorf("failed to patch termination grace period update to pod template %v", err))
+			continue
+		}
+
+		if err := o.PrintObj(actual, o.Out); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestSetTerminationGracePeriodKruiseWorkloads(t *testing.T) {
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}},
+	}}
+	workloads := newKruiseTestWorkloads(template)
+	testCases := []struct {
+		name        string
+		dryRun      cmdutil.DryRunStrategy
+		output      string
+		expectQuery string
+		expectOut   string
+	}{
+		{
+			name:      "patch",
+			expectOut: "web termination grace period updated\n",
+		},
+		{
+			name:      "client dry-run",
+			dryRun:    cmdutil.DryRunClient,
+			output:    "yaml",
+			expectOut: "terminationGracePeriodSeconds: 60",
+		},
+		{
+			name:        "server dry-run",
+			dryRun:      cmdutil.DryRunServer,
+			expectQuery: "dryRun=All",
+			expectOut:   "web termination grace period updated (server dry run)\n",
+		},
+	}
+
+	for _, workload := range workloads {
+		for _, tc := range testCases {
+			t.Run(workload.name+" "+tc.name, func(t *testing.T) {
+				tf := cmdtesting.NewTestFactory().WithNamespace("test")
+				defer tf.Cleanup()
+
+				var patched bool
+				tf.Client = &fake.RESTClient{
+					GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+					NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+					Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+						switch p, m := req.URL.Path, req.Method; {
+						case p == workload.path && m == http.MethodGet:
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(workload.object)}, nil
+						case p == workload.path && m == http.MethodPatch:
+							patched = true
+							assert.Equal(t, string(types.MergePatchType), req.Header.Get("Content-Type"))
+							assert.Equal(t, tc.expectQuery, req.URL.RawQuery)
+							body, err := io.ReadAll(req.Body)
+							if err != nil {
+								return nil, err
+							}
+							patch := &struct {
+								Spec struct {
+									Template corev1.PodTemplateSpec `json:"template"`
+								} `json:"spec"`
+							}{}
+							assert.NoError(t, json.Unmarshal(body, patch))
+							if assert.NotNil(t, patch.Spec.Template.Spec.TerminationGracePeriodSeconds) {
+								assert.Equal(t, int64(60), *patch.Spec.Template.Spec.TerminationGracePeriodSeconds)
+							}
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(workload.object)}, nil
+						default:
+							t.Errorf("unexpected request: %s %#v", req.Method, req.URL)
+							return nil, fmt.Errorf("unexpected request")
+						}
+					}),
+				}
+
+				streams, _, out, _ := genericclioptions.NewTestIOStreams()
+				cmd := NewCmdTerminationGracePeriod(tf, streams)
+				switch tc.dryRun {
+				case cmdutil.DryRunClient:
+					cmd.Flags().Set("dry-run", "client")
+				case cmdutil.DryRunServer:
+					cmd.Flags().Set("dry-run", "server")
+				}
+				opts := NewTerminationGracePeriodOptions(streams)
+				opts.PrintFlags.OutputFormat = &tc.output
+				assert.NoError(t, opts.Complete(tf, cmd, []string{workload.arg, "60"}))
+				assert.NoError(t, opts.Validate())
+
+				assert.NoError(t, opts.Run())
+				assert.Equal(t, tc.dryRun != cmdutil.DryRunClient, patched)
+				assert.Contains(t, out.String(), tc.expectOut)
+			})
+		}
+	}
+}
+
+func TestSetTerminationGracePeriodValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      []string
+		expectErr string
+	}{
+		{
+			name:      "missing period",
+			expectErr: "the termination grace period in seconds is required",
+		},
+		{
+			name:      "not a number",
+			args:      []string{"cloneset/web", "1m"},
+			expectErr: `invalid termination grace period "1m": must be a number of seconds`,
+		},
+		{
+			name:      "negative period",
+			args:      []string{"cloneset/web", "-5"},
+			expectErr: "the termination grace period must be 0 or greater, got -5",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			cs := &kruiseappsv1alpha1.CloneSet{
+				TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+			}
+			tf.Client = &fake.RESTClient{
+				GroupVersion:         kruiseappsv1alpha1.SchemeGroupVersion,
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					// nothing is patched once the period is rejected
+					if req.Method != http.MethodGet {
+						t.Errorf("unexpected request: %s %#v", req.Method, req.URL)
+						return nil, fmt.Errorf("unexpected request")
+					}
+					return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: objBody(cs)}, nil
+				}),
+			}
+
+			streams := genericclioptions.NewTestIOStreamsDiscard()
+			cmd := NewCmdTerminationGracePeriod(tf, streams)
+			opts := NewTerminationGracePeriodOptions(streams)
+			err := opts.Complete(tf, cmd, tc.args)
+			if err == nil {
+				err = opts.Validate()
+			}
+			assert.EqualError(t, err, tc.expectErr)
+		})
+	}
+}
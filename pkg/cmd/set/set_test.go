@@ -17,12 +17,22 @@ limitations under the License.
 package set
 
 import (
+	"net/http"
 	"testing"
 
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
 	"github.com/spf13/cobra"
-
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cliresource "k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 	clientcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/yaml"
 )
 
 func TestLocalAndDryRunFlags(t *testing.T) {
@@ -43,3 +53,103 @@ func ensureLocalAndDryRunFlagsOnChildren(t *testing.T, c *cobra.Command, prefix
 		ensureLocalAndDryRunFlagsOnChildren(t, cmd, name+".")
 	}
 }
+
+func TestSetLocalCloneSet(t *testing.T) {
+	localFile := cliresource.FilenameOptions{Filenames: []string{"../../../testdata/set/cloneset.yaml"}}
+	yamlPrintFlags := func() *genericclioptions.PrintFlags {
+		return genericclioptions.NewPrintFlags("").WithDefaultOutput("yaml").WithTypeSetter(scheme.Scheme)
+	}
+	testCases := []struct {
+		name            string
+		run             func(tf *cmdtesting.TestFactory, streams genericclioptions.IOStreams, args []string) error
+		args            []string
+		expectContainer corev1.Container
+	}{
+		{
+			name: "image",
+			run: func(tf *cmdtesting.TestFactory, streams genericclioptions.IOStreams, args []string) error {
+				o := NewImageOptions(streams)
+				o.PrintFlags, o.FilenameOptions, o.Local = yamlPrintFlags(), localFile, true
+				if err := o.Complete(tf, NewCmdImage(tf, streams), args); err != nil {
+					return err
+				}
+				if err := o.Validate(); err != nil {
+					return err
+				}
+				return o.Run()
+			},
+			args: []string{"nginx=nginx:1.25"},
+			expectContainer: corev1.Container{
+				Name: "nginx", Image: "nginx:1.25", Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+			},
+		},
+		{
+			name: "env",
+			run: func(tf *cmdtesting.TestFactory, streams genericclioptions.IOStreams, args []string) error {
+				o := NewEnvOptions(streams)
+				o.PrintFlags, o.FilenameOptions, o.Local = yamlPrintFlags(), localFile, true
+				if err := o.Complete(tf, NewCmdEnv(tf, streams), args); err != nil {
+					return err
+				}
+				if err := o.Validate(); err != nil {
+					return err
+				}
+				return o.RunEnv()
+			},
+			args: []string{"LOG_LEVEL=debug"},
+			expectContainer: corev1.Container{
+				Name: "nginx", Image: "nginx:1.24", Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+				Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}},
+			},
+		},
+		{
+			name: "resources",
+			run: func(tf *cmdtesting.TestFactory, streams genericclioptions.IOStreams, args []string) error {
+				o := NewResourcesOptions(streams)
+				o.PrintFlags, o.FilenameOptions, o.Local = yamlPrintFlags(), localFile, true
+				o.Limits = "cpu=200m,memory=512Mi"
+				if err := o.Complete(tf, NewCmdResources(tf, streams), args); err != nil {
+					return err
+				}
+				if err := o.Validate(); err != nil {
+					return err
+				}
+				return o.Run()
+			},
+			expectContainer: corev1.Container{
+				Name: "nginx", Image: "nginx:1.24", Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+				Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("200m"),
+					corev1.ResourceMemory: resource.MustParse("512Mi"),
+				}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			tf.Client = &fake.RESTClient{
+				GroupVersion:         schema.GroupVersion{Version: ""},
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					t.Fatalf("unexpected request: %s %#v", req.Method, req.URL)
+					return nil, nil
+				}),
+			}
+
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			assert.NoError(t, tc.run(tf, streams, tc.args))
+			cs := &kruiseappsv1alpha1.CloneSet{}
+			assert.NoError(t, yaml.Unmarshal(out.Bytes(), cs))
+			assert.Equal(t, "web", cs.Name)
+			assert.Equal(t, []corev1.Container{tc.expectContainer}, cs.Spec.Template.Spec.Containers)
+
+			// --local cannot fetch the resources given as arguments
+			streams, _, out, _ = genericclioptions.NewTestIOStreams()
+			assert.Equal(t, cliresource.LocalResourceError, tc.run(tf, streams, append([]string{"cloneset/web"}, tc.args...)))
+			assert.Empty(t, out.String())
+		})
+	}
+}
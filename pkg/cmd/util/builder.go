@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	internalapi "github.com/openkruise/kruise-tools/pkg/api"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// ResourceBuilderOptions describe the targets of a command as given by its arguments and flags.
+type ResourceBuilderOptions struct {
+	Namespace        string
+	EnforceNamespace bool
+	// AllNamespaces selects the targets in every namespace, the namespace of the context is then neither a default
+	// nor a requirement of the files
+	AllNamespaces   bool
+	FilenameOptions *resource.FilenameOptions
+	LabelSelector   string
+	FieldSelector   string
+	// All selects every resource of the types given as arguments when no name or selector is given, as --all does
+	All  bool
+	Args []string
+	// Local only reads the targets from the files, the arguments and selectors are ignored and nothing is fetched
+	// from the server
+	Local bool
+	// AsRead visits the objects read from files as they are read instead of fetching them from the server, e.g. to
+	// tell the kinds of a kustomize directory apart before requesting any
+	AsRead bool
+}
+
+// NewResourceBuilder configures b to visit the targets of o as typed objects of the Kruise and Kubernetes schemes,
+// fetched from the server, and continues past the targets which cannot be visited so that their errors are returned
+// together. Commands needing more, e.g. a stream to read, add it to the builder returned.
+func NewResourceBuilder(b *resource.Builder, o ResourceBuilderOptions) *resource.Builder {
+	b = b.WithScheme(internalapi.GetScheme(), internalapi.GetScheme().PrioritizedVersionsAllGroups()...).
+		LocalParam(o.Local).
+		ContinueOnError().
+		Flatten()
+	if o.AllNamespaces {
+		b = b.AllNamespaces(true)
+	} else {
+		b = b.NamespaceParam(o.Namespace).DefaultNamespace()
+	}
+	if o.FilenameOptions != nil {
+		b = b.FilenameParam(o.EnforceNamespace && !o.AllNamespaces, o.FilenameOptions)
+	}
+	if o.Local {
+		return b
+	}
+	b = b.LabelSelectorParam(o.LabelSelector).
+		FieldSelectorParam(o.FieldSelector).
+		ResourceTypeOrNameArgs(o.All, o.Args...)
+	if !o.AsRead {
+		b = b.Latest()
+	}
+	return b
+}
+
+// BuildResources visits the targets of o with a builder configured by NewResourceBuilder.
+func BuildResources(b *resource.Builder, o ResourceBuilderOptions) *resource.Result {
+	return NewResourceBuilder(b, o).Do()
+}
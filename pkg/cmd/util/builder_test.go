@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestBuildResources(t *testing.T) {
+	// the manifest and the server tell apart whether an object was fetched or read
+	manifest := filepath.Join(t.TempDir(), "web.yaml")
+	err := os.WriteFile(manifest, []byte(`apiVersion: apps.kruise.io/v1alpha1
+kind: CloneSet
+metadata:
+  name: web
+  labels:
+    from: file
+`), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	served := &kruiseappsv1alpha1.CloneSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test", Labels: map[string]string{"from": "server"}},
+	}
+
+	testCases := []struct {
+		name       string
+		options    ResourceBuilderOptions
+		expectFrom []string
+		// whether the server was requested at all
+		expectRequests bool
+		expectErr      string
+	}{
+		{
+			name:           "names are fetched in the default namespace",
+			options:        ResourceBuilderOptions{Namespace: "test", All: true, Args: []string{"cloneset/web"}},
+			expectFrom:     []string{"server"},
+			expectRequests: true,
+		},
+		{
+			name:           "objects read from files are fetched from the server",
+			options:        ResourceBuilderOptions{Namespace: "test", FilenameOptions: &resource.FilenameOptions{Filenames: []string{manifest}}},
+			expectFrom:     []string{"server"},
+			expectRequests: true,
+		},
+		{
+			name: "objects read from files are kept as read",
+			options: ResourceBuilderOptions{
+				Namespace: "test", FilenameOptions: &resource.FilenameOptions{Filenames: []string{manifest}}, AsRead: true,
+			},
+			expectFrom: []string{"file"},
+		},
+		{
+			name: "local targets are only read from files",
+			options: ResourceBuilderOptions{
+				Namespace: "test", FilenameOptions: &resource.FilenameOptions{Filenames: []string{manifest}}, Local: true,
+			},
+			expectFrom: []string{"file"},
+		},
+		{
+			name:           "targets which cannot be fetched do not stop the others",
+			options:        ResourceBuilderOptions{Namespace: "test", All: true, Args: []string{"cloneset/missing", "cloneset/web"}},
+			expectFrom:     []string{"server"},
+			expectRequests: true,
+			expectErr:      "the server could not find the requested resource",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			requests := 0
+			tf.Client = &fake.RESTClient{
+				GroupVersion:         schema.GroupVersion{Group: "apps.kruise.io", Version: "v1alpha1"},
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					requests++
+					if req.Method == http.MethodGet && req.URL.Path == "/namespaces/test/clonesets/web" {
+						codec := scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion)
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, served)}, nil
+					}
+					return &http.Response{StatusCode: http.StatusNotFound, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.StringBody("")}, nil
+				}),
+			}
+
+			infos, err := BuildResources(tf.NewBuilder(), tc.options).Infos()
+			if tc.expectErr != "" {
+				assert.ErrorContains(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			var from []string
+			for _, info := range infos {
+				// the objects are decoded as the typed objects of the Kruise scheme
+				cloneSet, ok := info.Object.(*kruiseappsv1alpha1.CloneSet)
+				if !assert.True(t, ok, "unexpected object %T", info.Object) {
+					continue
+				}
+				if cloneSet.Labels["from"] == "server" {
+					assert.Equal(t, "test", info.Namespace)
+				}
+				from = append(from, cloneSet.Labels["from"])
+			}
+			assert.Equal(t, tc.expectFrom, from)
+			assert.Equal(t, tc.expectRequests, requests > 0)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/cli-runtime/pkg/resource"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+const (
+	// PatchOperationPatched is returned by PatchWorkload if the workload was changed
+	PatchOperationPatched = "patched"
+	// PatchOperationUnchanged is returned by PatchWorkload if the workload was left as it was
+	PatchOperationUnchanged = "patched (no change)"
+)
+
+// PatchWorkload calls mutate on the object of info, and patches the changes it made onto the server, unless dryRun
+// is a client dry-run. The object of info is refreshed with the patched object the server returns. Nothing is sent
+// if mutate made no change, in which case PatchOperationUnchanged is returned rather than PatchOperationPatched.
+func PatchWorkload(info *resource.Info, mutate func(runtime.Object) error, dryRun cmdutil.DryRunStrategy) (string, error) {
+	before, err := runtime.Encode(scheme.DefaultJSONEncoder(), info.Object)
+	if err != nil {
+		return "", err
+	}
+	if err := mutate(info.Object); err != nil {
+		return "", err
+	}
+	after, err := runtime.Encode(scheme.DefaultJSONEncoder(), info.Object)
+	if err != nil {
+		return "", err
+	}
+	operation, _, err := patchChanges(info, before, after, dryRun)
+	return operation, err
+}
+
+// PatchEditedWorkload patches the changes from the object of info to edited onto the server, unless dryRun is a
+// client dry-run, and returns the patch along with the operation. The object of info is refreshed with the patched
+// object the server returns.
+func PatchEditedWorkload(info *resource.Info, edited runtime.Object, dryRun cmdutil.DryRunStrategy) (string, []byte, error) {
+	before, err := runtime.Encode(scheme.DefaultJSONEncoder(), info.Object)
+	if err != nil {
+		return "", nil, err
+	}
+	after, err := runtime.Encode(scheme.DefaultJSONEncoder(), edited)
+	if err != nil {
+		return "", nil, err
+	}
+	return patchChanges(info, before, after, dryRun)
+}
+
+func patchChanges(info *resource.Info, before, after []byte, dryRun cmdutil.DryRunStrategy) (string, []byte, error) {
+	patch, patchType, err := workloadPatch(info, before, after)
+	if err != nil {
+		return "", nil, err
+	}
+	if string(patch) == "{}" || len(patch) == 0 {
+		return PatchOperationUnchanged, nil, nil
+	}
+	if dryRun == cmdutil.DryRunClient {
+		return PatchOperationPatched, patch, nil
+	}
+
+	obj, err := resource.NewHelper(info.Client, info.Mapping).
+		DryRun(dryRun == cmdutil.DryRunServer).
+		Patch(info.Namespace, info.Name, patchType, patch, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to patch: %v", err)
+	}
+	return PatchOperationPatched, patch, info.Refresh(obj, true)
+}
+
+// workloadPatch returns the patch from before to after of the workload of info. Kruise workloads are custom
+// resources, which don't support strategic merge patches, so a JSON merge patch is calculated for any workload
+// which is not a built-in one.
+func workloadPatch(info *resource.Info, before, after []byte) ([]byte, types.PatchType, error) {
+	if !clientgoscheme.Scheme.Recognizes(info.Mapping.GroupVersionKind) {
+		patch, err := jsonpatch.CreateMergePatch(before, after)
+		return patch, types.MergePatchType, err
+	}
+	patch, err := strategicpatch.CreateTwoWayMergePatch(before, after, info.Object)
+	return patch, types.StrategicMergePatchType, err
+}
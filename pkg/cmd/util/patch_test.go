@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	_ "github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/utils/pointer"
+)
+
+type patchRequest struct {
+	path      string
+	query     string
+	patchType string
+	body      string
+}
+
+func newPatchTestInfo(t *testing.T, obj runtime.Object, gvk schema.GroupVersionKind, resourceName string, requests *[]patchRequest) *resource.Info {
+	codec := scheme.Codecs.LegacyCodec(gvk.GroupVersion())
+	client := &fake.RESTClient{
+		GroupVersion:         gvk.GroupVersion(),
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodPatch {
+				t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+			}
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			*requests = append(*requests, patchRequest{path: req.URL.Path, query: req.URL.RawQuery, patchType: req.Header.Get("Content-Type"), body: string(body)})
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+		}),
+	}
+	return &resource.Info{
+		Client: client,
+		Mapping: &meta.RESTMapping{
+			Resource:         gvk.GroupVersion().WithResource(resourceName),
+			GroupVersionKind: gvk,
+			Scope:            meta.RESTScopeNamespace,
+		},
+		Namespace: "test",
+		Name:      "web",
+		Object:    obj.DeepCopyObject(),
+	}
+}
+
+func TestPatchWorkload(t *testing.T) {
+	cloneSet := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec:       kruiseappsv1alpha1.CloneSetSpec{Replicas: pointer.Int32(2)},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(2)},
+	}
+	setReplicas := func(obj runtime.Object) error {
+		switch obj := obj.(type) {
+		case *kruiseappsv1alpha1.CloneSet:
+			obj.Spec.Replicas = pointer.Int32(3)
+		case *appsv1.Deployment:
+			obj.Spec.Replicas = pointer.Int32(3)
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		name            string
+		obj             runtime.Object
+		gvk             schema.GroupVersionKind
+		resource        string
+		mutate          func(runtime.Object) error
+		dryRun          cmdutil.DryRunStrategy
+		expectOperation string
+		expectRequests  []patchRequest
+		expectErr       string
+	}{
+		{
+			name:            "kruise workload is patched with a merge patch",
+			obj:             cloneSet,
+			gvk:             kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"),
+			resource:        "clonesets",
+			mutate:          setReplicas,
+			expectOperation: PatchOperationPatched,
+			expectRequests: []patchRequest{{
+				path: "/namespaces/test/clonesets/web", patchType: string(types.MergePatchType), body: `{"spec":{"replicas":3}}`,
+			}},
+		},
+		{
+			name:            "built-in workload is patched with a strategic merge patch",
+			obj:             deployment,
+			gvk:             appsv1.SchemeGroupVersion.WithKind("Deployment"),
+			resource:        "deployments",
+			mutate:          setReplicas,
+			expectOperation: PatchOperationPatched,
+			expectRequests: []patchRequest{{
+				path: "/namespaces/test/deployments/web", patchType: string(types.StrategicMergePatchType), body: `{"spec":{"replicas":3}}`,
+			}},
+		},
+		{
+			name:            "server dry-run",
+			obj:             cloneSet,
+			gvk:             kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"),
+			resource:        "clonesets",
+			mutate:          setReplicas,
+			dryRun:          cmdutil.DryRunServer,
+			expectOperation: PatchOperationPatched,
+			expectRequests: []patchRequest{{
+				path: "/namespaces/test/clonesets/web", query: "dryRun=All", patchType: string(types.MergePatchType), body: `{"spec":{"replicas":3}}`,
+			}},
+		},
+		{
+			name:            "client dry-run",
+			obj:             cloneSet,
+			gvk:             kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"),
+			resource:        "clonesets",
+			mutate:          setReplicas,
+			dryRun:          cmdutil.DryRunClient,
+			expectOperation: PatchOperationPatched,
+		},
+		{
+			name:            "no change",
+			obj:             cloneSet,
+			gvk:             kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"),
+			resource:        "clonesets",
+			mutate:          func(runtime.Object) error { return nil },
+			expectOperation: PatchOperationUnchanged,
+		},
+		{
+			name:      "mutate error",
+			obj:       cloneSet,
+			gvk:       kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"),
+			resource:  "clonesets",
+			mutate:    func(runtime.Object) error { return fmt.Errorf("not supported") },
+			expectErr: "not supported",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []patchRequest
+			info := newPatchTestInfo(t, tc.obj, tc.gvk, tc.resource, &requests)
+
+			operation, err := PatchWorkload(info, tc.mutate, tc.dryRun)
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectOperation, operation)
+			assert.Equal(t, tc.expectRequests, requests)
+		})
+	}
+}
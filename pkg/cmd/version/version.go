@@ -0,0 +1,237 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	kruiserolloutsv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	kruiserolloutsv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apimachineryversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/component-base/version"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	versionLong = templates.LongDesc(i18n.T(`
+		Print the client and server version information, and the versions of the Kruise APIs served by the cluster.
+
+		A warning is printed for every version of the apps.kruise.io and rollouts.kruise.io APIs the plugin
+		uses which the cluster does not serve, since the commands on the resources of that version fail.
+		A cluster without Kruise or Kruise Rollout installed is reported as such.`))
+
+	versionExample = templates.Examples(i18n.T(`
+		# Print the client and server versions, and the Kruise API versions of the current context
+		kubectl-kruise version
+
+		# Print the version of the plugin only
+		kubectl-kruise version --client`))
+
+	// kruiseAPIGroups are the groups of the Kruise APIs, along with the name of the project which serves them
+	kruiseAPIGroups = []struct {
+		group   string
+		project string
+	}{
+		{kruiseappsv1alpha1.GroupVersion.Group, "Kruise"},
+		{kruiserolloutsv1alpha1.GroupVersion.Group, "Kruise Rollout"},
+	}
+
+	// usedAPIVersions are the versions of the Kruise APIs the plugin reads and writes
+	usedAPIVersions = []schema.GroupVersion{
+		kruiseappsv1alpha1.GroupVersion,
+		kruiseappsv1beta1.GroupVersion,
+		kruiserolloutsv1alpha1.GroupVersion,
+		kruiserolloutsv1beta1.GroupVersion,
+	}
+)
+
+// Version is a struct for version information
+type Version struct {
+	ClientVersion *apimachineryversion.Info `json:"clientVersion,omitempty" yaml:"clientVersion,omitempty"`
+	ServerVersion *apimachineryversion.Info `json:"serverVersion,omitempty" yaml:"serverVersion,omitempty"`
+	// KruiseAPIVersions are the versions served by the cluster of every Kruise API group, e.g. apps.kruise.io/v1beta1
+	KruiseAPIVersions []string `json:"kruiseAPIVersions,omitempty" yaml:"kruiseAPIVersions,omitempty"`
+}
+
+// VersionOptions is the command line options for 'version'
+type VersionOptions struct {
+	ClientOnly bool
+	Output     string
+
+	args []string
+
+	DiscoveryClient discovery.DiscoveryInterface
+
+	genericclioptions.IOStreams
+}
+
+// NewVersionOptions returns an initialized VersionOptions instance
+func NewVersionOptions(streams genericclioptions.IOStreams) *VersionOptions {
+	return &VersionOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdVersion returns a Command instance for the 'version' command
+func NewCmdVersion(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewVersionOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "version",
+		Short:   i18n.T("Print the client and server version information, and the Kruise API versions"),
+		Long:    versionLong,
+		Example: versionExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.ClientOnly, "client", o.ClientOnly, "If true, shows client version only (no server required).")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "One of 'yaml' or 'json'.")
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *VersionOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.args = args
+	if o.ClientOnly {
+		return nil
+	}
+	discoveryClient, err := f.ToDiscoveryClient()
+	// without a kubeconfig, only the client version is printed
+	if err != nil {
+		if clientcmd.IsEmptyConfig(err) {
+			return nil
+		}
+		return err
+	}
+	// the served versions are always read from the server, since Kruise may have been upgraded since they were cached
+	discoveryClient.Invalidate()
+	o.DiscoveryClient = discoveryClient
+	return nil
+}
+
+// Validate makes sure that the options are valid
+func (o *VersionOptions) Validate() error {
+	if len(o.args) != 0 {
+		return fmt.Errorf("extra arguments: %v", o.args)
+	}
+	if o.Output != "" && o.Output != "yaml" && o.Output != "json" {
+		return fmt.Errorf("--output must be 'yaml' or 'json'")
+	}
+	return nil
+}
+
+// Run prints the versions, and warns about the versions of the Kruise APIs the cluster does not serve
+func (o *VersionOptions) Run() error {
+	versionInfo := Version{}
+	clientVersion := version.Get()
+	versionInfo.ClientVersion = &clientVersion
+
+	var warnings []string
+	var serverErr error
+	if !o.ClientOnly && o.DiscoveryClient != nil {
+		versionInfo.ServerVersion, serverErr = o.DiscoveryClient.ServerVersion()
+		if serverErr == nil {
+			versionInfo.KruiseAPIVersions, warnings, serverErr = kruiseAPIVersions(o.DiscoveryClient)
+		}
+	}
+
+	switch o.Output {
+	case "":
+		fmt.Fprintf(o.Out, "Client Version: %s\n", versionInfo.ClientVersion.GitVersion)
+		if versionInfo.ServerVersion != nil {
+			fmt.Fprintf(o.Out, "Server Version: %s\n", versionInfo.ServerVersion.GitVersion)
+		}
+		if len(versionInfo.KruiseAPIVersions) > 0 {
+			fmt.Fprintf(o.Out, "Kruise API Versions: %s\n", strings.Join(versionInfo.KruiseAPIVersions, ", "))
+		}
+	case "yaml":
+		marshalled, err := yaml.Marshal(&versionInfo)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(marshalled))
+	case "json":
+		marshalled, err := json.MarshalIndent(&versionInfo, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(marshalled))
+	}
+
+	for _, warning := range warnings {
+		fmt.Fprintf(o.ErrOut, "Warning: %s\n", warning)
+	}
+	return serverErr
+}
+
+// kruiseAPIVersions returns the versions of the Kruise API groups the cluster serves, and the warnings about the groups
+// and the versions used by the plugin it does not serve.
+func kruiseAPIVersions(client discovery.DiscoveryInterface) ([]string, []string, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the API groups served by the cluster: %v", err)
+	}
+	served := map[string][]string{}
+	for _, group := range groups.Groups {
+		for _, v := range group.Versions {
+			served[group.Name] = append(served[group.Name], v.Version)
+		}
+	}
+
+	var versions, warnings []string
+	for _, g := range kruiseAPIGroups {
+		if len(served[g.group]) == 0 {
+			warnings = append(warnings, fmt.Sprintf("%s is not installed in the cluster, %s is not served", g.project, g.group))
+			continue
+		}
+		for _, v := range served[g.group] {
+			versions = append(versions, schema.GroupVersion{Group: g.group, Version: v}.String())
+		}
+		for _, gv := range usedAPIVersions {
+			if gv.Group != g.group || contains(served[g.group], gv.Version) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("the cluster does not serve %s, which the plugin uses, the commands on its resources fail until %s is upgraded", gv, g.project))
+		}
+	}
+	return versions, warnings, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
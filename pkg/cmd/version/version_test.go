@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/component-base/version"
+)
+
+func newFakeDiscovery(groupVersions ...string) *fakediscovery.FakeDiscovery {
+	client := &fakediscovery.FakeDiscovery{
+		Fake:               &clienttesting.Fake{},
+		FakedServerVersion: &apimachineryversion.Info{GitVersion: "v1.28.9"},
+	}
+	for _, gv := range append([]string{"v1", "apps/v1"}, groupVersions...) {
+		client.Resources = append(client.Resources, &metav1.APIResourceList{GroupVersion: gv})
+	}
+	return client
+}
+
+func TestRunVersion(t *testing.T) {
+	clientVersion := fmt.Sprintf("Client Version: %s\n", version.Get().GitVersion)
+
+	testCases := []struct {
+		name         string
+		discovery    *fakediscovery.FakeDiscovery
+		clientOnly   bool
+		expectOut    string
+		expectErrOut string
+	}{
+		{
+			name:      "all versions served",
+			discovery: newFakeDiscovery("apps.kruise.io/v1alpha1", "apps.kruise.io/v1beta1", "rollouts.kruise.io/v1alpha1", "rollouts.kruise.io/v1beta1"),
+			expectOut: clientVersion + "Server Version: v1.28.9\n" +
+				"Kruise API Versions: apps.kruise.io/v1alpha1, apps.kruise.io/v1beta1, rollouts.kruise.io/v1alpha1, rollouts.kruise.io/v1beta1\n",
+		},
+		{
+			name:      "old Kruise Rollout",
+			discovery: newFakeDiscovery("apps.kruise.io/v1alpha1", "apps.kruise.io/v1beta1", "rollouts.kruise.io/v1alpha1"),
+			expectOut: clientVersion + "Server Version: v1.28.9\n" +
+				"Kruise API Versions: apps.kruise.io/v1alpha1, apps.kruise.io/v1beta1, rollouts.kruise.io/v1alpha1\n",
+			expectErrOut: "Warning: the cluster does not serve rollouts.kruise.io/v1beta1, which the plugin uses, the commands on its resources fail until Kruise Rollout is upgraded\n",
+		},
+		{
+			name:         "Kruise Rollout not installed",
+			discovery:    newFakeDiscovery("apps.kruise.io/v1alpha1", "apps.kruise.io/v1beta1"),
+			expectOut:    clientVersion + "Server Version: v1.28.9\nKruise API Versions: apps.kruise.io/v1alpha1, apps.kruise.io/v1beta1\n",
+			expectErrOut: "Warning: Kruise Rollout is not installed in the cluster, rollouts.kruise.io is not served\n",
+		},
+		{
+			name:      "Kruise not installed",
+			discovery: newFakeDiscovery(),
+			expectOut: clientVersion + "Server Version: v1.28.9\n",
+			expectErrOut: "Warning: Kruise is not installed in the cluster, apps.kruise.io is not served\n" +
+				"Warning: Kruise Rollout is not installed in the cluster, rollouts.kruise.io is not served\n",
+		},
+		{
+			name:       "client only",
+			discovery:  newFakeDiscovery(),
+			clientOnly: true,
+			expectOut:  clientVersion,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+			o := NewVersionOptions(streams)
+			o.ClientOnly = tc.clientOnly
+			o.DiscoveryClient = tc.discovery
+
+			assert.NoError(t, o.Validate())
+			assert.NoError(t, o.Run())
+			assert.Equal(t, tc.expectOut, out.String())
+			assert.Equal(t, tc.expectErrOut, errOut.String())
+			if tc.clientOnly {
+				assert.Empty(t, tc.discovery.Actions())
+			}
+		})
+	}
+}
+
+func TestRunVersionJSON(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewVersionOptions(streams)
+	o.Output = "json"
+	o.DiscoveryClient = newFakeDiscovery("apps.kruise.io/v1alpha1", "apps.kruise.io/v1beta1")
+
+	assert.NoError(t, o.Run())
+	versionInfo := Version{}
+	assert.NoError(t, json.NewDecoder(bytes.NewReader(out.Bytes())).Decode(&versionInfo))
+	assert.Equal(t, "v1.28.9", versionInfo.ServerVersion.GitVersion)
+	assert.Equal(t, []string{"apps.kruise.io/v1alpha1", "apps.kruise.io/v1beta1"}, versionInfo.KruiseAPIVersions)
+}
+
+func TestVersionValidate(t *testing.T) {
+	o := NewVersionOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Output = "wide"
+	assert.EqualError(t, o.Validate(), "--output must be 'yaml' or 'json'")
+
+	o.Output, o.args = "", []string{"extra"}
+	assert.EqualError(t, o.Validate(), "extra arguments: [extra]")
+}
@@ -16,12 +16,21 @@ limitations under the License.
 
 package creation
 
-import "github.com/openkruise/kruise-tools/pkg/api"
+import (
+	"github.com/openkruise/kruise-tools/pkg/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
 
 type Control interface {
+	// Generate returns the dst workload that Create would create from src, without creating it.
+	Generate(src api.ResourceRef, dst api.ResourceRef, opts Options) (client.Object, error)
 	Create(src api.ResourceRef, dst api.ResourceRef, opts Options) error
 }
 
 type Options struct {
+	// CopyReplicas copies the replicas of the src workload and scales the src workload to zero once the dst
+	// workload is created. Otherwise the dst workload is created with zero replicas.
 	CopyReplicas bool
+	// Replicas overrides the replicas of the dst workload if set.
+	Replicas *int32
 }
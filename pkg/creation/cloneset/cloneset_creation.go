@@ -28,6 +28,7 @@ import (
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
@@ -55,23 +56,63 @@ func NewControl(cfg *rest.Config) (creation.Control, error) {
 	return ctrl, nil
 }
 
-func (c *control) Create(src api.ResourceRef, dst api.ResourceRef, opts creation.Options) error {
+func (c *control) Generate(src api.ResourceRef, dst api.ResourceRef, opts creation.Options) (client.Object, error) {
 	if src.GetGroupVersionKind() != api.DeploymentKind {
-		return fmt.Errorf("invalid src type, currently only support %v", api.DeploymentKind.String())
+		return nil, fmt.Errorf("invalid src type, currently only support %v", api.DeploymentKind.String())
 	} else if dst.GetGroupVersionKind() != api.CloneSetKind {
-		return fmt.Errorf("invalid dst type, must be %v", api.CloneSetKind.String())
+		return nil, fmt.Errorf("invalid dst type, must be %v", api.CloneSetKind.String())
+	}
+
+	srcDeployment, err := c.getDeployment(src)
+	if err != nil {
+		return nil, err
 	}
+	return generateCloneSet(srcDeployment, dst, opts), nil
+}
 
+func (c *control) Create(src api.ResourceRef, dst api.ResourceRef, opts creation.Options) error {
 	if err := c.ensureCloneSetNotExists(dst); err != nil {
 		return err
 	}
+	dstCloneSet, err := c.Generate(src, dst, opts)
+	if err != nil {
+		return err
+	}
+	if err := c.client.Create(context.TODO(), dstCloneSet); err != nil {
+		return err
+	}
+
+	if !opts.CopyReplicas {
+		return nil
+	}
+	// the replicas have been copied to the cloneset, so the deployment is scaled to zero to not run them twice
 	srcDeployment, err := c.getDeployment(src)
 	if err != nil {
 		return err
 	}
+	patch := client.MergeFrom(srcDeployment.DeepCopy())
+	srcDeployment.Spec.Replicas = pointer.Int32(0)
+	if err := c.client.Patch(context.TODO(), srcDeployment, patch); err != nil {
+		return fmt.Errorf("failed to scale %v to zero: %v", src, err)
+	}
+	return nil
+}
 
-	dstCloneSet := conversion.DeploymentToCloneSet(srcDeployment, dst.Name)
-	return c.client.Create(context.TODO(), dstCloneSet)
+// generateCloneSet converts the deployment into a paused cloneset, so that it is not updated before it is checked.
+func generateCloneSet(deploy *apps.Deployment, dst api.ResourceRef, opts creation.Options) *appsv1alpha1.CloneSet {
+	cs := conversion.DeploymentToCloneSet(deploy, dst.Name)
+	cs.SetGroupVersionKind(api.CloneSetKind)
+	cs.Spec.UpdateStrategy.Paused = true
+
+	switch {
+	case opts.Replicas != nil:
+		cs.Spec.Replicas = pointer.Int32(*opts.Replicas)
+	case opts.CopyReplicas:
+		// DeploymentToCloneSet has copied the replicas of the deployment
+	default:
+		cs.Spec.Replicas = pointer.Int32(0)
+	}
+	return cs
 }
 
 func (c *control) getDeployment(ref api.ResourceRef) (*apps.Deployment, error) {
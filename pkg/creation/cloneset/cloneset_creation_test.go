@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloneset
+
+import (
+	"context"
+	"testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/openkruise/kruise-tools/pkg/api"
+	"github.com/openkruise/kruise-tools/pkg/creation"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestDeployment() *apps.Deployment {
+	maxUnavailable, maxSurge := intstr.FromInt(1), intstr.FromString("25%")
+	return &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "web",
+			Labels:      map[string]string{"app": "web"},
+			Annotations: map[string]string{"team": "frontend"},
+		},
+		Spec: apps.DeploymentSpec{
+			Replicas: pointer.Int32(5),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx:1.25"}}},
+			},
+			Strategy: apps.DeploymentStrategy{
+				Type:          apps.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &apps.RollingUpdateDeployment{MaxUnavailable: &maxUnavailable, MaxSurge: &maxSurge},
+			},
+			MinReadySeconds:      10,
+			RevisionHistoryLimit: pointer.Int32(3),
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		opts           creation.Options
+		expectReplicas int32
+	}{
+		{
+			name:           "empty cloneset by default",
+			expectReplicas: 0,
+		},
+		{
+			name:           "copy replicas",
+			opts:           creation.Options{CopyReplicas: true},
+			expectReplicas: 5,
+		},
+		{
+			name:           "replicas override",
+			opts:           creation.Options{CopyReplicas: true, Replicas: pointer.Int32(2)},
+			expectReplicas: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deploy := newTestDeployment()
+			c := &control{client: fake.NewClientBuilder().WithScheme(api.GetScheme()).WithObjects(deploy).Build()}
+
+			obj, err := c.Generate(api.NewDeploymentRef("default", "web"), api.NewCloneSetRef("default", "web-cs"), tc.opts)
+			if !assert.NoError(t, err) {
+				return
+			}
+			cs := obj.(*appsv1alpha1.CloneSet)
+
+			assert.Equal(t, api.CloneSetKind, cs.GroupVersionKind())
+			assert.Equal(t, "default", cs.Namespace)
+			assert.Equal(t, "web-cs", cs.Name)
+			assert.Equal(t, deploy.Labels, cs.Labels)
+			assert.Equal(t, deploy.Annotations, cs.Annotations)
+			assert.Equal(t, tc.expectReplicas, *cs.Spec.Replicas)
+			assert.Equal(t, deploy.Spec.Selector, cs.Spec.Selector)
+			assert.Equal(t, deploy.Spec.Template, cs.Spec.Template)
+			assert.Equal(t, deploy.Spec.MinReadySeconds, cs.Spec.MinReadySeconds)
+			assert.Equal(t, deploy.Spec.RevisionHistoryLimit, cs.Spec.RevisionHistoryLimit)
+			assert.Equal(t, deploy.Spec.Strategy.RollingUpdate.MaxUnavailable, cs.Spec.UpdateStrategy.MaxUnavailable)
+			assert.Equal(t, deploy.Spec.Strategy.RollingUpdate.MaxSurge, cs.Spec.UpdateStrategy.MaxSurge)
+			assert.True(t, cs.Spec.UpdateStrategy.Paused)
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	testCases := []struct {
+		name                     string
+		opts                     creation.Options
+		expectCloneSetReplicas   int32
+		expectDeploymentReplicas int32
+	}{
+		{
+			name:                     "deployment keeps its replicas",
+			expectCloneSetReplicas:   0,
+			expectDeploymentReplicas: 5,
+		},
+		{
+			name:                     "copy scales the deployment to zero",
+			opts:                     creation.Options{CopyReplicas: true},
+			expectCloneSetReplicas:   5,
+			expectDeploymentReplicas: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &control{client: fake.NewClientBuilder().WithScheme(api.GetScheme()).WithObjects(newTestDeployment()).Build()}
+			src, dst := api.NewDeploymentRef("default", "web"), api.NewCloneSetRef("default", "web")
+
+			if !assert.NoError(t, c.Create(src, dst, tc.opts)) {
+				return
+			}
+
+			cs := &appsv1alpha1.CloneSet{}
+			if assert.NoError(t, c.client.Get(context.TODO(), dst.GetNamespacedName(), cs)) {
+				assert.Equal(t, tc.expectCloneSetReplicas, *cs.Spec.Replicas)
+			}
+			deploy := &apps.Deployment{}
+			if assert.NoError(t, c.client.Get(context.TODO(), src.GetNamespacedName(), deploy)) {
+				assert.Equal(t, tc.expectDeploymentReplicas, *deploy.Spec.Replicas)
+			}
+
+			assert.EqualError(t, c.Create(src, dst, tc.opts), "cloneset default/web already exists")
+		})
+	}
+}
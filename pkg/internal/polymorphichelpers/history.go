@@ -22,7 +22,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	internalapps "github.com/openkruise/kruise-tools/pkg/internal/apps"
 
@@ -42,6 +44,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/client-go/kubernetes"
 	clientappsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/kubectl/pkg/describe"
 	deploymentutil "k8s.io/kubectl/pkg/util/deployment"
 	sliceutil "k8s.io/kubectl/pkg/util/slice"
@@ -56,6 +59,12 @@ type HistoryViewer interface {
 	ViewHistory(namespace, name string, revision int64) (string, error)
 }
 
+// WideHistoryViewer is implemented by history viewers that can list the revisions of a workload
+// together with the images of each revision and the number of pods running it.
+type WideHistoryViewer interface {
+	ViewHistoryWide(namespace, name string) (string, error)
+}
+
 type HistoryVisitor struct {
 	clientset       kubernetes.Interface
 	kruiseclientset kruiseclientsets.Interface
@@ -134,49 +143,139 @@ func (v *HistoryVisitor) VisitAdvancedDaemonSet(kind internalapps.GroupKindEleme
 	v.result = &AdvancedDaemonSetHistoryViewer{v.clientset, v.kruiseclientset}
 }
 
-// TODO impl ViewHistory func for CloneSet
+// ViewHistory returns a list of the revision history of a CloneSet
 func (h *CloneSetHistoryViewer) ViewHistory(namespace, name string, revision int64) (string, error) {
 
-	cs, history, err := clonesetHistory(h.k.AppsV1(), h.kc.AppsV1alpha1(), namespace, name)
+	cs, history, err := clonesetHistory(context.TODO(), h.k.AppsV1(), h.kc.AppsV1alpha1(), namespace, name)
 	if err != nil {
 		return "", err
 	}
 
-	return printHistory(history, revision, func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	return printHistory(history, revision, cloneSetTemplateOfHistory(cs))
+}
+
+// ViewHistoryWide returns a list of the revision history of a CloneSet with the images and pods of each revision
+func (h *CloneSetHistoryViewer) ViewHistoryWide(namespace, name string) (string, error) {
+	cs, history, err := clonesetHistory(context.TODO(), h.k.AppsV1(), h.kc.AppsV1alpha1(), namespace, name)
+	if err != nil {
+		return "", err
+	}
+	pods, err := controlledPods(h.k.CoreV1(), cs.Spec.Selector, cs)
+	if err != nil {
+		return "", err
+	}
+	return printHistoryWide(history, pods, cloneSetTemplateOfHistory(cs), cloneSetStrategyOfHistory(cs))
+}
+
+func cloneSetTemplateOfHistory(cs *kruiseappsv1alpha1.CloneSet) func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	return func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
 		cloneSetOfHistory, err := applyCloneSetHistory(cs, history)
 		if err != nil {
 			return nil, err
 		}
 		return &cloneSetOfHistory.Spec.Template, err
-	})
+	}
 }
 
+func cloneSetStrategyOfHistory(cs *kruiseappsv1alpha1.CloneSet) func(history *appsv1.ControllerRevision) (string, error) {
+	return func(history *appsv1.ControllerRevision) (string, error) {
+		cloneSetOfHistory, err := applyCloneSetHistory(cs, history)
+		if err != nil {
+			return "", err
+		}
+		return formatUpdateStrategy(string(cloneSetOfHistory.Spec.UpdateStrategy.Type), ""), nil
+	}
+}
+
+// ViewHistory returns a list of the revision history of an Advanced StatefulSet
 func (h *AdvancedStatefulSetHistoryViewer) ViewHistory(namespace, name string, revision int64) (string, error) {
-	asts, history, err := advancedstsHistory(h.k.AppsV1(), h.kc.AppsV1beta1(), namespace, name)
+	asts, history, err := advancedstsHistory(context.TODO(), h.k.AppsV1(), h.kc.AppsV1beta1(), namespace, name)
+	if err != nil {
+		return "", err
+	}
+	return printHistory(history, revision, advancedStatefulSetTemplateOfHistory(asts))
+}
+
+// ViewHistoryWide returns a list of the revision history of an Advanced StatefulSet with the images and pods of each revision
+func (h *AdvancedStatefulSetHistoryViewer) ViewHistoryWide(namespace, name string) (string, error) {
+	asts, history, err := advancedstsHistory(context.TODO(), h.k.AppsV1(), h.kc.AppsV1beta1(), namespace, name)
 	if err != nil {
 		return "", err
 	}
-	return printHistory(history, revision, func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	pods, err := controlledPods(h.k.CoreV1(), asts.Spec.Selector, asts)
+	if err != nil {
+		return "", err
+	}
+	return printHistoryWide(history, pods, advancedStatefulSetTemplateOfHistory(asts), advancedStatefulSetStrategyOfHistory(asts))
+}
+
+func advancedStatefulSetTemplateOfHistory(asts *kruiseappsv1beta1.StatefulSet) func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	return func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
 		astsOfHistory, err := applyAdvancedStatefulSetHistory(asts, history)
 		if err != nil {
 			return nil, err
 		}
 		return &astsOfHistory.Spec.Template, err
-	})
+	}
+}
+
+func advancedStatefulSetStrategyOfHistory(asts *kruiseappsv1beta1.StatefulSet) func(history *appsv1.ControllerRevision) (string, error) {
+	return func(history *appsv1.ControllerRevision) (string, error) {
+		astsOfHistory, err := applyAdvancedStatefulSetHistory(asts, history)
+		if err != nil {
+			return "", err
+		}
+		var podUpdatePolicy string
+		if astsOfHistory.Spec.UpdateStrategy.RollingUpdate != nil {
+			podUpdatePolicy = string(astsOfHistory.Spec.UpdateStrategy.RollingUpdate.PodUpdatePolicy)
+		}
+		return formatUpdateStrategy(string(astsOfHistory.Spec.UpdateStrategy.Type), podUpdatePolicy), nil
+	}
 }
 
 func (h *AdvancedDaemonSetHistoryViewer) ViewHistory(namespace, name string, revision int64) (string, error) {
-	ads, history, err := advancedDaemonSetHistory(h.k.AppsV1(), h.kc.AppsV1alpha1(), namespace, name)
+	ads, history, err := advancedDaemonSetHistory(context.TODO(), h.k.AppsV1(), h.kc.AppsV1alpha1(), namespace, name)
 	if err != nil {
 		return "", err
 	}
-	return printHistory(history, revision, func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	return printHistory(history, revision, advancedDaemonSetTemplateOfHistory(ads))
+}
+
+// ViewHistoryWide returns a list of the revision history of an Advanced DaemonSet with the images and pods of each revision
+func (h *AdvancedDaemonSetHistoryViewer) ViewHistoryWide(namespace, name string) (string, error) {
+	ads, history, err := advancedDaemonSetHistory(context.TODO(), h.k.AppsV1(), h.kc.AppsV1alpha1(), namespace, name)
+	if err != nil {
+		return "", err
+	}
+	pods, err := controlledPods(h.k.CoreV1(), ads.Spec.Selector, ads)
+	if err != nil {
+		return "", err
+	}
+	return printHistoryWide(history, pods, advancedDaemonSetTemplateOfHistory(ads), advancedDaemonSetStrategyOfHistory(ads))
+}
+
+func advancedDaemonSetTemplateOfHistory(ads *kruiseappsv1alpha1.DaemonSet) func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	return func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
 		adsOfHistory, err := applyAdvancedDaemonSetHistory(ads, history)
 		if err != nil {
 			return nil, err
 		}
 		return &adsOfHistory.Spec.Template, err
-	})
+	}
+}
+
+func advancedDaemonSetStrategyOfHistory(ads *kruiseappsv1alpha1.DaemonSet) func(history *appsv1.ControllerRevision) (string, error) {
+	return func(history *appsv1.ControllerRevision) (string, error) {
+		adsOfHistory, err := applyAdvancedDaemonSetHistory(ads, history)
+		if err != nil {
+			return "", err
+		}
+		var rollingUpdateType string
+		if adsOfHistory.Spec.UpdateStrategy.RollingUpdate != nil {
+			rollingUpdateType = string(adsOfHistory.Spec.UpdateStrategy.RollingUpdate.Type)
+		}
+		return formatUpdateStrategy(string(adsOfHistory.Spec.UpdateStrategy.Type), rollingUpdateType), nil
+	}
 }
 
 // ViewHistory returns a revision-to-replicaset map as the revision history of a deployment
@@ -260,17 +359,44 @@ type DaemonSetHistoryViewer struct {
 // ViewHistory returns a revision-to-history map as the revision history of a deployment
 // TODO: this should be a describer
 func (h *DaemonSetHistoryViewer) ViewHistory(namespace, name string, revision int64) (string, error) {
-	ds, history, err := daemonSetHistory(h.c.AppsV1(), namespace, name)
+	ds, history, err := daemonSetHistory(context.TODO(), h.c.AppsV1(), namespace, name)
+	if err != nil {
+		return "", err
+	}
+	return printHistory(history, revision, daemonSetTemplateOfHistory(ds))
+}
+
+// ViewHistoryWide returns a list of the revision history of a DaemonSet with the images and pods of each revision
+func (h *DaemonSetHistoryViewer) ViewHistoryWide(namespace, name string) (string, error) {
+	ds, history, err := daemonSetHistory(context.TODO(), h.c.AppsV1(), namespace, name)
+	if err != nil {
+		return "", err
+	}
+	pods, err := controlledPods(h.c.CoreV1(), ds.Spec.Selector, ds)
 	if err != nil {
 		return "", err
 	}
-	return printHistory(history, revision, func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	return printHistoryWide(history, pods, daemonSetTemplateOfHistory(ds), daemonSetStrategyOfHistory(ds))
+}
+
+func daemonSetTemplateOfHistory(ds *appsv1.DaemonSet) func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	return func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
 		dsOfHistory, err := applyDaemonSetHistory(ds, history)
 		if err != nil {
 			return nil, err
 		}
 		return &dsOfHistory.Spec.Template, err
-	})
+	}
+}
+
+func daemonSetStrategyOfHistory(ds *appsv1.DaemonSet) func(history *appsv1.ControllerRevision) (string, error) {
+	return func(history *appsv1.ControllerRevision) (string, error) {
+		dsOfHistory, err := applyDaemonSetHistory(ds, history)
+		if err != nil {
+			return "", err
+		}
+		return formatUpdateStrategy(string(dsOfHistory.Spec.UpdateStrategy.Type), ""), nil
+	}
 }
 
 // printHistory returns the podTemplate of the given revision if it is non-zero
@@ -299,6 +425,25 @@ func printHistory(history []*appsv1.ControllerRevision, revision int64, getPodTe
 	}
 
 	// Print an overview of all Revisions
+	return printRevisions(historyInfo, nil, nil, nil)
+}
+
+// printHistoryWide returns an overview of all revisions in history, together with the images of
+// the pod template of each revision, its update strategy and the number of pods running it
+func printHistoryWide(history []*appsv1.ControllerRevision, pods []corev1.Pod, getPodTemplate func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error), getUpdateStrategy func(history *appsv1.ControllerRevision) (string, error)) (string, error) {
+	historyInfo := make(map[int64]*appsv1.ControllerRevision)
+	for _, history := range history {
+		historyInfo[history.Revision] = history
+	}
+	if len(historyInfo) == 0 {
+		return "No rollout history found.", nil
+	}
+	return printRevisions(historyInfo, pods, getPodTemplate, getUpdateStrategy)
+}
+
+// printRevisions prints a table of the revisions in historyInfo sorted by revision. The images, update
+// strategy and pods of each revision are added as columns if getPodTemplate is set.
+func printRevisions(historyInfo map[int64]*appsv1.ControllerRevision, pods []corev1.Pod, getPodTemplate func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error), getUpdateStrategy func(history *appsv1.ControllerRevision) (string, error)) (string, error) {
 	// Sort the revisionToChangeCause map by revision
 	revisions := make([]int64, 0, len(historyInfo))
 	for r := range historyInfo {
@@ -306,20 +451,98 @@ func printHistory(history []*appsv1.ControllerRevision, revision int64, getPodTe
 	}
 	sliceutil.SortInts64(revisions)
 
+	wide := getPodTemplate != nil
 	return tabbedString(func(out io.Writer) error {
-		fmt.Fprintf(out, "REVISION\tCHANGE-CAUSE\n")
+		if wide {
+			fmt.Fprintf(out, "REVISION\tCREATED\tCHANGE-CAUSE\tIMAGES\tUPDATE-STRATEGY\tREPLICAS\n")
+		} else {
+			fmt.Fprintf(out, "REVISION\tCREATED\tCHANGE-CAUSE\n")
+		}
 		for _, r := range revisions {
 			// Find the change-cause of revision r
 			changeCause := historyInfo[r].Annotations[ChangeCauseAnnotation]
 			if len(changeCause) == 0 {
 				changeCause = "<none>"
 			}
-			fmt.Fprintf(out, "%d\t%s\n", r, changeCause)
+			created := "<unknown>"
+			if !historyInfo[r].CreationTimestamp.IsZero() {
+				created = historyInfo[r].CreationTimestamp.UTC().Format(time.RFC3339)
+			}
+			if !wide {
+				fmt.Fprintf(out, "%d\t%s\t%s\n", r, created, changeCause)
+				continue
+			}
+			podTemplate, err := getPodTemplate(historyInfo[r])
+			if err != nil {
+				return fmt.Errorf("unable to parse history %s", historyInfo[r].Name)
+			}
+			updateStrategy, err := getUpdateStrategy(historyInfo[r])
+			if err != nil {
+				return fmt.Errorf("unable to parse history %s", historyInfo[r].Name)
+			}
+			fmt.Fprintf(out, "%d\t%s\t%s\t%s\t%s\t%d\n", r, created, changeCause, templateImages(podTemplate), updateStrategy, countRevisionPods(historyInfo[r], pods))
 		}
 		return nil
 	})
 }
 
+// templateImages returns the images of the containers of template, separated by commas
+func templateImages(template *corev1.PodTemplateSpec) string {
+	images := make([]string, 0, len(template.Spec.Containers))
+	for _, container := range template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	if len(images) == 0 {
+		return "<none>"
+	}
+	return strings.Join(images, ",")
+}
+
+// formatUpdateStrategy returns the type of the update strategy of a workload followed by the way its pods are
+// updated, if the strategy has one, e.g. RollingUpdate (InPlaceIfPossible)
+func formatUpdateStrategy(strategyType, podUpdatePolicy string) string {
+	if len(strategyType) == 0 {
+		return "<none>"
+	}
+	if len(podUpdatePolicy) == 0 {
+		return strategyType
+	}
+	return fmt.Sprintf("%s (%s)", strategyType, podUpdatePolicy)
+}
+
+// countRevisionPods returns the number of pods labeled with the revision of history. Workloads label
+// their pods either with the name of the ControllerRevision or with its hash.
+func countRevisionPods(history *appsv1.ControllerRevision, pods []corev1.Pod) int {
+	hash := history.Labels[appsv1.ControllerRevisionHashLabelKey]
+	count := 0
+	for _, pod := range pods {
+		podRevision := pod.Labels[appsv1.ControllerRevisionHashLabelKey]
+		if len(podRevision) > 0 && (podRevision == history.Name || podRevision == hash) {
+			count++
+		}
+	}
+	return count
+}
+
+// controlledPods returns the pods in the namespace of owner that are selected by selector and controlled by owner
+func controlledPods(c clientcorev1.CoreV1Interface, selector *metav1.LabelSelector, owner metav1.Object) ([]corev1.Pod, error) {
+	podSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create selector for %s: %v", owner.GetName(), err)
+	}
+	podList, err := c.Pods(owner.GetNamespace()).List(context.TODO(), metav1.ListOptions{LabelSelector: podSelector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pods of %s: %v", owner.GetName(), err)
+	}
+	var pods []corev1.Pod
+	for i := range podList.Items {
+		if metav1.IsControlledBy(&podList.Items[i], owner) {
+			pods = append(pods, podList.Items[i])
+		}
+	}
+	return pods, nil
+}
+
 type StatefulSetHistoryViewer struct {
 	c kubernetes.Interface
 }
@@ -327,28 +550,56 @@ type StatefulSetHistoryViewer struct {
 // ViewHistory returns a list of the revision history of a statefulset
 // TODO: this should be a describer
 func (h *StatefulSetHistoryViewer) ViewHistory(namespace, name string, revision int64) (string, error) {
-	sts, history, err := statefulSetHistory(h.c.AppsV1(), namespace, name)
+	sts, history, err := statefulSetHistory(context.TODO(), h.c.AppsV1(), namespace, name)
 	if err != nil {
 		return "", err
 	}
-	return printHistory(history, revision, func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	return printHistory(history, revision, statefulSetTemplateOfHistory(sts))
+}
+
+// ViewHistoryWide returns a list of the revision history of a statefulset with the images and pods of each revision
+func (h *StatefulSetHistoryViewer) ViewHistoryWide(namespace, name string) (string, error) {
+	sts, history, err := statefulSetHistory(context.TODO(), h.c.AppsV1(), namespace, name)
+	if err != nil {
+		return "", err
+	}
+	pods, err := controlledPods(h.c.CoreV1(), sts.Spec.Selector, sts)
+	if err != nil {
+		return "", err
+	}
+	return printHistoryWide(history, pods, statefulSetTemplateOfHistory(sts), statefulSetStrategyOfHistory(sts))
+}
+
+func statefulSetTemplateOfHistory(sts *appsv1.StatefulSet) func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	return func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
 		stsOfHistory, err := applyStatefulSetHistory(sts, history)
 		if err != nil {
 			return nil, err
 		}
 		return &stsOfHistory.Spec.Template, err
-	})
+	}
+}
+
+func statefulSetStrategyOfHistory(sts *appsv1.StatefulSet) func(history *appsv1.ControllerRevision) (string, error) {
+	return func(history *appsv1.ControllerRevision) (string, error) {
+		stsOfHistory, err := applyStatefulSetHistory(sts, history)
+		if err != nil {
+			return "", err
+		}
+		return formatUpdateStrategy(string(stsOfHistory.Spec.UpdateStrategy.Type), ""), nil
+	}
 }
 
 // controlledHistories returns all ControllerRevisions in namespace that selected by selector and owned by accessor
 // TODO: Rename this to controllerHistory when other controllers have been upgraded
 func controlledHistoryV1(
+	ctx context.Context,
 	apps clientappsv1.AppsV1Interface,
 	namespace string,
 	selector labels.Selector,
 	accessor metav1.Object) ([]*appsv1.ControllerRevision, error) {
 	var result []*appsv1.ControllerRevision
-	historyList, err := apps.ControllerRevisions(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	historyList, err := apps.ControllerRevisions(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
 	if err != nil {
 		return nil, err
 	}
@@ -364,12 +615,13 @@ func controlledHistoryV1(
 
 // controlledHistories returns all ControllerRevisions in namespace that selected by selector and owned by accessor
 func controlledHistory(
+	ctx context.Context,
 	apps clientappsv1.AppsV1Interface,
 	namespace string,
 	selector labels.Selector,
 	accessor metav1.Object) ([]*appsv1.ControllerRevision, error) {
 	var result []*appsv1.ControllerRevision
-	historyList, err := apps.ControllerRevisions(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	historyList, err := apps.ControllerRevisions(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
 	if err != nil {
 		return nil, err
 	}
@@ -385,9 +637,10 @@ func controlledHistory(
 
 // daemonSetHistory returns the DaemonSet named name in namespace and all ControllerRevisions in its history.
 func daemonSetHistory(
+	ctx context.Context,
 	apps clientappsv1.AppsV1Interface,
 	namespace, name string) (*appsv1.DaemonSet, []*appsv1.ControllerRevision, error) {
-	ds, err := apps.DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	ds, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to retrieve DaemonSet %s: %v", name, err)
 	}
@@ -399,7 +652,7 @@ func daemonSetHistory(
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create accessor for DaemonSet %s: %v", ds.Name, err)
 	}
-	history, err := controlledHistory(apps, ds.Namespace, selector, accessor)
+	history, err := controlledHistory(ctx, apps, ds.Namespace, selector, accessor)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to find history controlled by DaemonSet %s: %v", ds.Name, err)
 	}
@@ -408,9 +661,10 @@ func daemonSetHistory(
 
 // advancedDaemonSetHistory returns the Advanced DaemonSet named name in namespace and all ControllerRevisions in its history.
 func advancedDaemonSetHistory(
+	ctx context.Context,
 	apps clientappsv1.AppsV1Interface, appsv1alpha1 kruiseclientappsv1alpha1.AppsV1alpha1Interface,
 	namespace, name string) (*kruiseappsv1alpha1.DaemonSet, []*appsv1.ControllerRevision, error) {
-	ds, err := appsv1alpha1.DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	ds, err := appsv1alpha1.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to retrieve DaemonSet %s: %v", name, err)
 	}
@@ -422,7 +676,7 @@ func advancedDaemonSetHistory(
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create accessor for DaemonSet %s: %v", ds.Name, err)
 	}
-	history, err := controlledHistoryV1(apps, ds.Namespace, selector, accessor)
+	history, err := controlledHistoryV1(ctx, apps, ds.Namespace, selector, accessor)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to find history controlled by DaemonSet %s: %v", ds.Name, err)
 	}
@@ -430,9 +684,10 @@ func advancedDaemonSetHistory(
 }
 
 func clonesetHistory(
+	ctx context.Context,
 	apps clientappsv1.AppsV1Interface, appsv1alpha1 kruiseclientappsv1alpha1.AppsV1alpha1Interface,
 	namespace, name string) (*kruiseappsv1alpha1.CloneSet, []*appsv1.ControllerRevision, error) {
-	cs, err := appsv1alpha1.CloneSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	cs, err := appsv1alpha1.CloneSets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -444,7 +699,7 @@ func clonesetHistory(
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to obtain accessor for StatefulSet %s: %s", name, err.Error())
 	}
-	history, err := controlledHistoryV1(apps, namespace, selector, accessor)
+	history, err := controlledHistoryV1(ctx, apps, namespace, selector, accessor)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to find history controlled by StatefulSet %s: %v", name, err)
 	}
@@ -453,9 +708,10 @@ func clonesetHistory(
 }
 
 func advancedstsHistory(
+	ctx context.Context,
 	apps clientappsv1.AppsV1Interface, appsv1beta1 kruiseclientappsv1beta1.AppsV1beta1Interface,
 	namespace, name string) (*kruiseappsv1beta1.StatefulSet, []*appsv1.ControllerRevision, error) {
-	asts, err := appsv1beta1.StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	asts, err := appsv1beta1.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -468,7 +724,7 @@ func advancedstsHistory(
 		return nil, nil, fmt.Errorf("failed to obtain accessor for Advanced  StatefulSet %s: %s", name, err.Error())
 	}
 
-	history, err := controlledHistoryV1(apps, namespace, selector, accessor)
+	history, err := controlledHistoryV1(ctx, apps, namespace, selector, accessor)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to find history controlled by StatefulSet %s: %v", name, err)
 	}
@@ -477,9 +733,10 @@ func advancedstsHistory(
 
 // statefulSetHistory returns the StatefulSet named name in namespace and all ControllerRevisions in its history.
 func statefulSetHistory(
+	ctx context.Context,
 	apps clientappsv1.AppsV1Interface,
 	namespace, name string) (*appsv1.StatefulSet, []*appsv1.ControllerRevision, error) {
-	sts, err := apps.StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	sts, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to retrieve Statefulset %s: %s", name, err.Error())
 	}
@@ -491,7 +748,7 @@ func statefulSetHistory(
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to obtain accessor for StatefulSet %s: %s", name, err.Error())
 	}
-	history, err := controlledHistoryV1(apps, namespace, selector, accessor)
+	history, err := controlledHistoryV1(ctx, apps, namespace, selector, accessor)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to find history controlled by StatefulSet %s: %v", name, err)
 	}
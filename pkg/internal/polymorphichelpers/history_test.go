@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var historyTestLabels = map[string]string{"app": "demo"}
+
+func newHistoryTestTemplate() corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: historyTestLabels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main", Image: "nginx:1.3"}},
+		},
+	}
+}
+
+// newHistoryTestRevisions returns three revisions owned by owner, each of them pinning image nginx:1.<revision>.
+func newHistoryTestRevisions(owner metav1.Object, gvk schema.GroupVersionKind) []runtime.Object {
+	var revisions []runtime.Object
+	for r := int64(1); r <= 3; r++ {
+		revision := &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              fmt.Sprintf("%s-%d", owner.GetName(), r),
+				Namespace:         owner.GetNamespace(),
+				Labels:            historyTestLabels,
+				CreationTimestamp: metav1.NewTime(time.Date(2024, 1, int(r), 0, 0, 0, 0, time.UTC)),
+				OwnerReferences:   []metav1.OwnerReference{*metav1.NewControllerRef(owner, gvk)},
+			},
+			Data:     runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"main","image":"nginx:1.%d"}]}}}}`, r))},
+			Revision: r,
+		}
+		if r == 2 {
+			revision.Annotations = map[string]string{ChangeCauseAnnotation: "upgrade to 1.2"}
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions
+}
+
+func TestCloneSetHistoryViewer(t *testing.T) {
+	cs := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid")},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+			Template: newHistoryTestTemplate(),
+		},
+	}
+	revisions := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+
+	testCases := []struct {
+		name        string
+		revision    int64
+		expectOut   string
+		expectImage string
+		expectErr   string
+	}{
+		{
+			name:     "list all revisions",
+			revision: 0,
+			expectOut: "REVISION  CREATED               CHANGE-CAUSE\n" +
+				"1         2024-01-01T00:00:00Z  <none>\n" +
+				"2         2024-01-02T00:00:00Z  upgrade to 1.2\n" +
+				"3         2024-01-03T00:00:00Z  <none>\n",
+		},
+		{
+			name:        "show the template of one revision",
+			revision:    2,
+			expectImage: "nginx:1.2",
+		},
+		{
+			name:      "unknown revision",
+			revision:  5,
+			expectErr: "unable to find the specified revision",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			viewer := &CloneSetHistoryViewer{
+				k:  fake.NewSimpleClientset(revisions...),
+				kc: kruisefake.NewSimpleClientset(cs),
+			}
+			out, err := viewer.ViewHistory("default", "demo", tc.revision)
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			if len(tc.expectOut) > 0 {
+				assert.Equal(t, tc.expectOut, out)
+			}
+			if len(tc.expectImage) > 0 {
+				assert.Contains(t, out, tc.expectImage)
+				assert.NotContains(t, out, "nginx:1.3")
+			}
+		})
+	}
+}
+
+func TestCloneSetHistoryViewerWide(t *testing.T) {
+	cs := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid")},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: historyTestLabels},
+			Template:       newHistoryTestTemplate(),
+			UpdateStrategy: kruiseappsv1alpha1.CloneSetUpdateStrategy{Type: kruiseappsv1alpha1.InPlaceIfPossibleCloneSetUpdateStrategyType},
+		},
+	}
+	objs := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+	// revision 1 records the update strategy it ran with, the others run with the one of the CloneSet
+	objs[0].(*appsv1.ControllerRevision).Data.Raw = []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"main","image":"nginx:1.1"}]}},"updateStrategy":{"type":"ReCreate"}}}`)
+	// two pods run revision 3 and one pod revision 2, the pod of another owner is not counted
+	for i, revision := range []string{"demo-3", "demo-3", "demo-2"} {
+		objs = append(objs, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("demo-%d", i),
+			Namespace:       "default",
+			Labels:          map[string]string{"app": "demo", appsv1.ControllerRevisionHashLabelKey: revision},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))},
+		}})
+	}
+	objs = append(objs, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "other",
+		Namespace: "default",
+		Labels:    map[string]string{"app": "demo", appsv1.ControllerRevisionHashLabelKey: "demo-3"},
+	}})
+
+	viewer := &CloneSetHistoryViewer{
+		k:  fake.NewSimpleClientset(objs...),
+		kc: kruisefake.NewSimpleClientset(cs),
+	}
+	out, err := viewer.ViewHistoryWide("default", "demo")
+	assert.NoError(t, err)
+	assert.Equal(t, "REVISION  CREATED               CHANGE-CAUSE    IMAGES     UPDATE-STRATEGY    REPLICAS\n"+
+		"1         2024-01-01T00:00:00Z  <none>          nginx:1.1  ReCreate           0\n"+
+		"2         2024-01-02T00:00:00Z  upgrade to 1.2  nginx:1.2  InPlaceIfPossible  1\n"+
+		"3         2024-01-03T00:00:00Z  <none>          nginx:1.3  InPlaceIfPossible  2\n", out)
+
+	// the default table stays compact
+	out, err = viewer.ViewHistory("default", "demo", 0)
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "IMAGES")
+}
+
+func TestAdvancedStatefulSetHistoryViewer(t *testing.T) {
+	asts := &kruiseappsv1beta1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("asts-uid")},
+		Spec: kruiseappsv1beta1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+			Template: newHistoryTestTemplate(),
+		},
+	}
+	viewer := &AdvancedStatefulSetHistoryViewer{
+		k:  fake.NewSimpleClientset(newHistoryTestRevisions(asts, kruiseappsv1beta1.SchemeGroupVersion.WithKind("StatefulSet"))...),
+		kc: kruisefake.NewSimpleClientset(asts),
+	}
+
+	out, err := viewer.ViewHistory("default", "demo", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "REVISION  CREATED               CHANGE-CAUSE\n"+
+		"1         2024-01-01T00:00:00Z  <none>\n"+
+		"2         2024-01-02T00:00:00Z  upgrade to 1.2\n"+
+		"3         2024-01-03T00:00:00Z  <none>\n", out)
+
+	out, err = viewer.ViewHistory("default", "demo", 1)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "nginx:1.1")
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	deploymentutil "k8s.io/kubectl/pkg/util/deployment"
+)
+
+// imageRevision returns the number of the most recent revision of obj whose container named container runs image.
+func imageRevision(restClientGetter genericclioptions.RESTClientGetter, obj runtime.Object, container, image string) (int64, error) {
+	clientConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return 0, err
+	}
+	external, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return 0, err
+	}
+	kruiseExternal, err := kruiseclientsets.NewForConfig(clientConfig)
+	if err != nil {
+		return 0, err
+	}
+	return imageRevisionFor(obj, external, kruiseExternal, container, image)
+}
+
+func imageRevisionFor(obj runtime.Object, c kubernetes.Interface, kc kruiseclientsets.Interface, container, image string) (int64, error) {
+	templates, err := revisionTemplates(obj, c, kc)
+	if err != nil {
+		return 0, err
+	}
+
+	revisions := make([]int64, 0, len(templates))
+	for revision := range templates {
+		revisions = append(revisions, revision)
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i] > revisions[j] })
+
+	available := sets.NewString()
+	for _, revision := range revisions {
+		for _, c := range templates[revision].Spec.Containers {
+			if c.Name != container {
+				continue
+			}
+			if c.Image == image {
+				return revision, nil
+			}
+			available.Insert(c.Image)
+		}
+	}
+	if available.Len() == 0 {
+		return 0, fmt.Errorf("no revision in the history has a container %q", container)
+	}
+	return 0, fmt.Errorf("no revision in the history runs image %q in container %q, available images: %s", image, container, strings.Join(available.List(), ", "))
+}
+
+// revisionTemplates returns the pod templates of the revisions of obj by revision number.
+func revisionTemplates(obj runtime.Object, c kubernetes.Interface, kc kruiseclientsets.Interface) (map[int64]*corev1.PodTemplateSpec, error) {
+	templates := map[int64]*corev1.PodTemplateSpec{}
+	var history []*appsv1.ControllerRevision
+	var templateOf func(history *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error)
+	var err error
+	switch obj := obj.(type) {
+	case *appsv1.Deployment:
+		// the revisions of a Deployment are its ReplicaSets
+		_, oldRSs, newRS, err := deploymentutil.GetAllReplicaSets(obj, c.AppsV1())
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve replica sets from deployment %s: %v", obj.Name, err)
+		}
+		for _, rs := range append(oldRSs, newRS) {
+			if rs == nil {
+				continue
+			}
+			revision, err := deploymentutil.Revision(rs)
+			if err != nil {
+				continue
+			}
+			templates[revision] = &rs.Spec.Template
+		}
+		return templates, nil
+	case *kruiseappsv1alpha1.CloneSet:
+		var cs *kruiseappsv1alpha1.CloneSet
+		cs, history, err = clonesetHistory(context.TODO(), c.AppsV1(), kc.AppsV1alpha1(), obj.Namespace, obj.Name)
+		templateOf = cloneSetTemplateOfHistory(cs)
+	case *kruiseappsv1beta1.StatefulSet:
+		var asts *kruiseappsv1beta1.StatefulSet
+		asts, history, err = advancedstsHistory(context.TODO(), c.AppsV1(), kc.AppsV1beta1(), obj.Namespace, obj.Name)
+		templateOf = advancedStatefulSetTemplateOfHistory(asts)
+	case *appsv1.StatefulSet:
+		var sts *appsv1.StatefulSet
+		sts, history, err = statefulSetHistory(context.TODO(), c.AppsV1(), obj.Namespace, obj.Name)
+		templateOf = statefulSetTemplateOfHistory(sts)
+	case *kruiseappsv1alpha1.DaemonSet:
+		var ads *kruiseappsv1alpha1.DaemonSet
+		ads, history, err = advancedDaemonSetHistory(context.TODO(), c.AppsV1(), kc.AppsV1alpha1(), obj.Namespace, obj.Name)
+		templateOf = advancedDaemonSetTemplateOfHistory(ads)
+	case *appsv1.DaemonSet:
+		var ds *appsv1.DaemonSet
+		ds, history, err = daemonSetHistory(context.TODO(), c.AppsV1(), obj.Namespace, obj.Name)
+		templateOf = daemonSetTemplateOfHistory(ds)
+	default:
+		return nil, fmt.Errorf("no revision history can be found for %T", obj)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range history {
+		template, err := templateOf(h)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse revision %d: %v", h.Revision, err)
+		}
+		templates[h.Revision] = template
+	}
+	return templates, nil
+}
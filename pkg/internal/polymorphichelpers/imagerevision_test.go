@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestImageRevisionForCloneSet(t *testing.T) {
+	cs := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid")},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+			Template: newHistoryTestTemplate(),
+		},
+	}
+	revisions := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+
+	testCases := []struct {
+		name           string
+		container      string
+		image          string
+		expectRevision int64
+		expectErr      string
+	}{
+		{
+			name:           "matching revision",
+			container:      "main",
+			image:          "nginx:1.2",
+			expectRevision: 2,
+		},
+		{
+			name:      "no matching image",
+			container: "main",
+			image:     "nginx:1.24",
+			expectErr: `no revision in the history runs image "nginx:1.24" in container "main", available images: nginx:1.1, nginx:1.2, nginx:1.3`,
+		},
+		{
+			name:      "unknown container",
+			container: "sidecar",
+			image:     "nginx:1.2",
+			expectErr: `no revision in the history has a container "sidecar"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			revision, err := imageRevisionFor(cs, fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(cs), tc.container, tc.image)
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectRevision, revision)
+		})
+	}
+}
+
+func TestRevisionsForCloneSet(t *testing.T) {
+	cs := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid")},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+			Template: newHistoryTestTemplate(),
+		},
+	}
+	history := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+
+	revisions, err := revisionsFor(cs, fake.NewSimpleClientset(history...), kruisefake.NewSimpleClientset(cs))
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, revisions)
+}
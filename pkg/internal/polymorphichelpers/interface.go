@@ -88,6 +88,12 @@ type CanBeExposedFunc func(kind schema.GroupKind) error
 // CanBeExposedFn gives a way to easily override the function for unit testing if needed
 var CanBeExposedFn CanBeExposedFunc = canBeExposed
 
+// CanBeAutoscaledFunc is a function type that can tell you whether a given GroupKind is capable of being autoscaled
+type CanBeAutoscaledFunc func(kind schema.GroupKind) error
+
+// CanBeAutoscaledFn gives a way to easily override the function for unit testing if needed
+var CanBeAutoscaledFn CanBeAutoscaledFunc = canBeAutoscaled
+
 // ObjectPauserFunc is a function type that marks the object in a given info as paused.
 type ObjectPauserFunc func(runtime.Object) ([]byte, error)
 
@@ -118,6 +124,24 @@ type RollbackerFunc func(restClientGetter genericclioptions.RESTClientGetter, ma
 // RollbackerFn gives a way to easily override the function for unit testing if needed
 var RollbackerFn RollbackerFunc = rollbacker
 
+// StableRevisionFunc returns the number of the revision of a workload which the given stable revision refers to
+type StableRevisionFunc func(restClientGetter genericclioptions.RESTClientGetter, obj runtime.Object, stableRevision string) (int64, error)
+
+// StableRevisionFn gives a way to easily override the function for unit testing if needed
+var StableRevisionFn StableRevisionFunc = stableRevision
+
+// ImageRevisionFunc returns the number of the most recent revision of a workload whose given container runs the given image
+type ImageRevisionFunc func(restClientGetter genericclioptions.RESTClientGetter, obj runtime.Object, container, image string) (int64, error)
+
+// ImageRevisionFn gives a way to easily override the function for unit testing if needed
+var ImageRevisionFn ImageRevisionFunc = imageRevision
+
+// RevisionsFunc returns the numbers of the revisions in the history of a workload
+type RevisionsFunc func(restClientGetter genericclioptions.RESTClientGetter, obj runtime.Object) ([]int64, error)
+
+// RevisionsFn gives a way to easily override the function for unit testing if needed
+var RevisionsFn RevisionsFunc = revisions
+
 // ObjectRestarterFunc is a function type that updates an annotation in a deployment to restart it..
 type ObjectRestarterFunc func(runtime.Object) ([]byte, error)
 
@@ -26,18 +26,30 @@ import (
 	"k8s.io/kubectl/pkg/scheme"
 )
 
+var errNoCanaryToApprove = errors.New("does not allow to approve, because there is no canary release in progress")
+
+func notPausedErr(state string) error {
+	return fmt.Errorf("does not allow to approve, because current canary state is %q rather than 'StepPaused'", state)
+}
+
 // defaultObjectApprover currently only support Kruise Rollout.
 func defaultObjectApprover(obj runtime.Object) ([]byte, error) {
 	switch obj := obj.(type) {
 	case *rolloutsapiv1alpha1.Rollout:
-		if obj.Status.CanaryStatus == nil || obj.Status.CanaryStatus.CurrentStepState != rolloutsapiv1alpha1.CanaryStepStatePaused {
-			return nil, errors.New("does not allow to approve, because current canary state is not 'StepPaused'")
+		if obj.Status.CanaryStatus == nil {
+			return nil, errNoCanaryToApprove
+		}
+		if obj.Status.CanaryStatus.CurrentStepState != rolloutsapiv1alpha1.CanaryStepStatePaused {
+			return nil, notPausedErr(string(obj.Status.CanaryStatus.CurrentStepState))
 		}
 		obj.Status.CanaryStatus.CurrentStepState = rolloutsapiv1alpha1.CanaryStepStateReady
 		return runtime.Encode(scheme.Codecs.LegacyCodec(rolloutsapiv1alpha1.GroupVersion), obj)
 	case *rolloutsapiv1beta1.Rollout:
-		if obj.Status.CanaryStatus == nil || obj.Status.CanaryStatus.CurrentStepState != rolloutsapiv1beta1.CanaryStepStatePaused {
-			return nil, errors.New("does not allow to approve, because current canary state is not 'StepPaused'")
+		if obj.Status.CanaryStatus == nil {
+			return nil, errNoCanaryToApprove
+		}
+		if obj.Status.CanaryStatus.CurrentStepState != rolloutsapiv1beta1.CanaryStepStatePaused {
+			return nil, notPausedErr(string(obj.Status.CanaryStatus.CurrentStepState))
 		}
 		obj.Status.CanaryStatus.CurrentStepState = rolloutsapiv1beta1.CanaryStepStateReady
 		return runtime.Encode(scheme.Codecs.LegacyCodec(rolloutsapiv1beta1.GroupVersion), obj)
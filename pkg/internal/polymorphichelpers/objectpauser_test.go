@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	rolloutsapiv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	rolloutsapiv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubectl/pkg/scheme"
+
+	// register the kruise types into the kubectl scheme
+	_ "github.com/openkruise/kruise-tools/pkg/api"
+)
+
+type pausableTestCase struct {
+	name   string
+	obj    func(paused bool) runtime.Object
+	paused func(obj runtime.Object) bool
+}
+
+func pausableTestCases() []pausableTestCase {
+	return []pausableTestCase{
+		{
+			name: "deployment",
+			obj: func(paused bool) runtime.Object {
+				d := &appsv1.Deployment{}
+				d.Spec.Paused = paused
+				return d
+			},
+			paused: func(obj runtime.Object) bool { return obj.(*appsv1.Deployment).Spec.Paused },
+		},
+		{
+			name: "cloneset",
+			obj: func(paused bool) runtime.Object {
+				cs := &kruiseappsv1alpha1.CloneSet{}
+				cs.Spec.UpdateStrategy.Paused = paused
+				return cs
+			},
+			paused: func(obj runtime.Object) bool { return obj.(*kruiseappsv1alpha1.CloneSet).Spec.UpdateStrategy.Paused },
+		},
+		{
+			name: "advanced statefulset",
+			obj: func(paused bool) runtime.Object {
+				asts := &kruiseappsv1beta1.StatefulSet{}
+				if paused {
+					asts.Spec.UpdateStrategy.RollingUpdate = &kruiseappsv1beta1.RollingUpdateStatefulSetStrategy{Paused: true}
+				}
+				return asts
+			},
+			paused: func(obj runtime.Object) bool {
+				rollingUpdate := obj.(*kruiseappsv1beta1.StatefulSet).Spec.UpdateStrategy.RollingUpdate
+				return rollingUpdate != nil && rollingUpdate.Paused
+			},
+		},
+		{
+			name: "rollout v1alpha1",
+			obj: func(paused bool) runtime.Object {
+				rollout := &rolloutsapiv1alpha1.Rollout{}
+				rollout.Spec.Strategy.Paused = paused
+				return rollout
+			},
+			paused: func(obj runtime.Object) bool { return obj.(*rolloutsapiv1alpha1.Rollout).Spec.Strategy.Paused },
+		},
+		{
+			name: "rollout v1beta1",
+			obj: func(paused bool) runtime.Object {
+				rollout := &rolloutsapiv1beta1.Rollout{}
+				rollout.Spec.Strategy.Paused = paused
+				return rollout
+			},
+			paused: func(obj runtime.Object) bool { return obj.(*rolloutsapiv1beta1.Rollout).Spec.Strategy.Paused },
+		},
+	}
+}
+
+// testPausable checks that fn turns an object into the desired pause state, and leaves objects already in it unchanged.
+func testPausable(t *testing.T, fn func(runtime.Object) ([]byte, error), desired bool) {
+	for _, tc := range pausableTestCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := fn(tc.obj(!desired))
+			if !assert.NoError(t, err) {
+				return
+			}
+			obj, err := runtime.Decode(scheme.Codecs.UniversalDeserializer(), data)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, desired, tc.paused(obj))
+
+			original := tc.obj(desired)
+			gvks, _, err := scheme.Scheme.ObjectKinds(original)
+			if !assert.NoError(t, err) {
+				return
+			}
+			expected, err := runtime.Encode(scheme.Codecs.LegacyCodec(gvks[0].GroupVersion()), original)
+			if !assert.NoError(t, err) {
+				return
+			}
+			data, err = fn(tc.obj(desired))
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), string(data))
+		})
+	}
+}
+
+func TestDefaultObjectPauser(t *testing.T) {
+	testPausable(t, defaultObjectPauser, true)
+
+	_, err := defaultObjectPauser(&appsv1.StatefulSet{})
+	assert.EqualError(t, err, "pausing is not supported")
+}
+
+func TestDefaultObjectResumer(t *testing.T) {
+	testPausable(t, defaultObjectResumer, false)
+
+	_, err := defaultObjectResumer(&appsv1.StatefulSet{})
+	assert.EqualError(t, err, "resuming is not supported")
+}
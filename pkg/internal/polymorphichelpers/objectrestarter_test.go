@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"testing"
+	"time"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubectl/pkg/scheme"
+
+	// register the kruise types into the kubectl scheme
+	_ "github.com/openkruise/kruise-tools/pkg/api"
+)
+
+func TestDefaultObjectRestarter(t *testing.T) {
+	containers := []corev1.Container{{Name: "main", Image: "nginx"}}
+
+	testCases := []struct {
+		name          string
+		obj           runtime.Object
+		annotation    string
+		template      func(obj runtime.Object) corev1.PodTemplateSpec
+		expectRestart bool
+	}{
+		{
+			name: "deployment",
+			obj: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}},
+			},
+			annotation: "kubectl.kubernetes.io/restartedAt",
+			template:   func(obj runtime.Object) corev1.PodTemplateSpec { return obj.(*appsv1.Deployment).Spec.Template },
+		},
+		{
+			name: "daemonset",
+			obj: &appsv1.DaemonSet{
+				Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}},
+			},
+			annotation: "kubectl.kubernetes.io/restartedAt",
+			template:   func(obj runtime.Object) corev1.PodTemplateSpec { return obj.(*appsv1.DaemonSet).Spec.Template },
+		},
+		{
+			name: "cloneset",
+			obj: &kruiseappsv1alpha1.CloneSet{
+				Spec: kruiseappsv1alpha1.CloneSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}},
+			},
+			annotation: "kubectl.kruise.io/restartedAt",
+			template: func(obj runtime.Object) corev1.PodTemplateSpec {
+				return obj.(*kruiseappsv1alpha1.CloneSet).Spec.Template
+			},
+			expectRestart: true,
+		},
+		{
+			name: "advanced statefulset",
+			obj: &kruiseappsv1beta1.StatefulSet{
+				Spec: kruiseappsv1beta1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}},
+			},
+			annotation: "kubectl.kruise.io/restartedAt",
+			template: func(obj runtime.Object) corev1.PodTemplateSpec {
+				return obj.(*kruiseappsv1beta1.StatefulSet).Spec.Template
+			},
+			expectRestart: true,
+		},
+		{
+			name: "advanced daemonset",
+			obj: &kruiseappsv1alpha1.DaemonSet{
+				Spec: kruiseappsv1alpha1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}},
+			},
+			annotation: "kubectl.kruise.io/restartedAt",
+			template: func(obj runtime.Object) corev1.PodTemplateSpec {
+				return obj.(*kruiseappsv1alpha1.DaemonSet).Spec.Template
+			},
+			expectRestart: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := defaultObjectRestarter(tc.obj)
+			if !assert.NoError(t, err) {
+				return
+			}
+			obj, err := runtime.Decode(scheme.Codecs.UniversalDeserializer(), data)
+			if !assert.NoError(t, err) {
+				return
+			}
+			template := tc.template(obj)
+
+			_, err = time.Parse(time.RFC3339, template.Annotations[tc.annotation])
+			assert.NoError(t, err, "annotation %s should be an RFC3339 time", tc.annotation)
+
+			env := template.Spec.Containers[0].Env
+			if tc.expectRestart {
+				if assert.Len(t, env, 1) {
+					assert.Equal(t, RestartedEnv, env[0].Name)
+					_, err = time.Parse(time.RFC3339, env[0].Value)
+					assert.NoError(t, err)
+				}
+			} else {
+				assert.Empty(t, env)
+			}
+		})
+	}
+
+	_, err := defaultObjectRestarter(&appsv1.Deployment{Spec: appsv1.DeploymentSpec{Paused: true}})
+	assert.EqualError(t, err, "can't restart paused deployment (run rollout resume first)")
+
+	_, err = defaultObjectRestarter(&corev1.Pod{})
+	assert.EqualError(t, err, "restarting is not supported")
+}
@@ -18,10 +18,11 @@ limitations under the License.
 package polymorphichelpers
 
 import (
-	"errors"
 	"fmt"
 
 	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	rolloutsapiv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
 	rolloutsapi "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	appsv1beta1 "k8s.io/api/apps/v1beta1"
@@ -31,48 +32,41 @@ import (
 	"k8s.io/kubectl/pkg/scheme"
 )
 
-// Currently supports Deployments, CloneSet and Kruise Rollout.
+// Currently supports Deployments, CloneSet, Advanced StatefulSet and Kruise Rollout.
+// Objects which are already resumed are encoded unchanged, so that the resulting patch is empty.
 func defaultObjectResumer(obj runtime.Object) ([]byte, error) {
 	switch obj := obj.(type) {
 	case *extensionsv1beta1.Deployment:
-		if !obj.Spec.Paused {
-			return nil, errors.New("is not paused")
-		}
 		obj.Spec.Paused = false
 		return runtime.Encode(scheme.Codecs.LegacyCodec(extensionsv1beta1.SchemeGroupVersion), obj)
 
 	case *appsv1.Deployment:
-		if !obj.Spec.Paused {
-			return nil, errors.New("is not paused")
-		}
 		obj.Spec.Paused = false
 		return runtime.Encode(scheme.Codecs.LegacyCodec(appsv1.SchemeGroupVersion), obj)
 
 	case *appsv1beta2.Deployment:
-		if !obj.Spec.Paused {
-			return nil, errors.New("is not paused")
-		}
 		obj.Spec.Paused = false
 		return runtime.Encode(scheme.Codecs.LegacyCodec(appsv1beta2.SchemeGroupVersion), obj)
 
 	case *appsv1beta1.Deployment:
-		if !obj.Spec.Paused {
-			return nil, errors.New("is not paused")
-		}
 		obj.Spec.Paused = false
 		return runtime.Encode(scheme.Codecs.LegacyCodec(appsv1beta1.SchemeGroupVersion), obj)
 
 	case *kruiseappsv1alpha1.CloneSet:
-		if !obj.Spec.UpdateStrategy.Paused {
-			return nil, errors.New("is not paused")
-		}
 		obj.Spec.UpdateStrategy.Paused = false
 		return runtime.Encode(scheme.Codecs.LegacyCodec(kruiseappsv1alpha1.SchemeGroupVersion), obj)
 
-	case *rolloutsapi.Rollout:
-		if !obj.Spec.Strategy.Paused {
-			return nil, errors.New("is not paused")
+	case *kruiseappsv1beta1.StatefulSet:
+		if obj.Spec.UpdateStrategy.RollingUpdate != nil {
+			obj.Spec.UpdateStrategy.RollingUpdate.Paused = false
 		}
+		return runtime.Encode(scheme.Codecs.LegacyCodec(kruiseappsv1beta1.SchemeGroupVersion), obj)
+
+	case *rolloutsapiv1alpha1.Rollout:
+		obj.Spec.Strategy.Paused = false
+		return runtime.Encode(scheme.Codecs.LegacyCodec(rolloutsapiv1alpha1.SchemeGroupVersion), obj)
+
+	case *rolloutsapi.Rollout:
 		obj.Spec.Strategy.Paused = false
 		return runtime.Encode(scheme.Codecs.LegacyCodec(rolloutsapi.SchemeGroupVersion), obj)
 
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strconv"
 
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	appsv1beta1 "k8s.io/api/apps/v1beta1"
 	appsv1beta2 "k8s.io/api/apps/v1beta2"
@@ -54,6 +55,10 @@ func portsForObject(object runtime.Object) ([]string, error) {
 		return getPorts(t.Spec.Template.Spec), nil
 	case *appsv1beta2.ReplicaSet:
 		return getPorts(t.Spec.Template.Spec), nil
+
+	case *kruiseappsv1alpha1.CloneSet:
+		return getPorts(t.Spec.Template.Spec), nil
+
 	default:
 		return nil, fmt.Errorf("cannot extract ports from %T", object)
 	}
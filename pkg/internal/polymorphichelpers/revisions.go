@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"sort"
+
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+)
+
+// revisions returns the numbers of the revisions in the history of obj, in ascending order.
+func revisions(restClientGetter genericclioptions.RESTClientGetter, obj runtime.Object) ([]int64, error) {
+	clientConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	external, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	kruiseExternal, err := kruiseclientsets.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	return revisionsFor(obj, external, kruiseExternal)
+}
+
+func revisionsFor(obj runtime.Object, c kubernetes.Interface, kc kruiseclientsets.Interface) ([]int64, error) {
+	templates, err := revisionTemplates(obj, c, kc)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]int64, 0, len(templates))
+	for revision := range templates {
+		result = append(result, revision)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result, nil
+}
@@ -20,8 +20,10 @@ package polymorphichelpers
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
 	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
@@ -32,14 +34,18 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/json"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 	deploymentutil "k8s.io/kubectl/pkg/util/deployment"
@@ -55,6 +61,166 @@ type Rollbacker interface {
 	Rollback(obj runtime.Object, updatedAnnotations map[string]string, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (string, error)
 }
 
+// RollbackPreviewer is implemented by rollbackers that can compute locally which pod template
+// a rollback would restore, so that callers can show the change before it is applied.
+type RollbackPreviewer interface {
+	// PreviewRollback returns the live pod template of obj and the pod template it would be rolled back to.
+	PreviewRollback(obj runtime.Object, toRevision int64) (live, target *corev1.PodTemplateSpec, err error)
+}
+
+// RollbackRevisionsResolver is implemented by rollbackers that can tell which revision a rollback starts from,
+// so that callers can report the revisions of several rollbacks.
+type RollbackRevisionsResolver interface {
+	// RollbackRevisions returns the revision the workload obj runs and the revision a rollback to toRevision restores.
+	RollbackRevisions(obj runtime.Object, toRevision int64) (from, to int64, err error)
+}
+
+// FieldManagerSetter is implemented by rollbackers that can attribute the patches they send
+// to the workload to a field manager.
+type FieldManagerSetter interface {
+	SetFieldManager(fieldManager string)
+}
+
+// ContextSetter is implemented by rollbackers whose requests to the API server can be bound
+// to a context, so that a rollback can be given up on once the context is done.
+type ContextSetter interface {
+	SetContext(ctx context.Context)
+}
+
+// TemplateOnlySetter is implemented by rollbackers that can restore only the pod template of a revision,
+// keeping the other fields of the workload a revision may record, such as the replicas and the update strategy.
+type TemplateOnlySetter interface {
+	SetTemplateOnly(templateOnly bool)
+}
+
+// StrategyRestoreSetter is implemented by rollbackers that keep the rolling update settings of the live workload by
+// default, and restore the ones a revision records only when asked to.
+type StrategyRestoreSetter interface {
+	SetRestoreStrategy(restoreStrategy bool)
+}
+
+// rollbackPatcher builds the options of the patches rollbackers send to the workload,
+// and holds the context of the requests of a rollback.
+type rollbackPatcher struct {
+	fieldManager string
+	ctx          context.Context
+	templateOnly bool
+}
+
+// SetFieldManager sets the field manager recorded for the fields changed by a rollback.
+func (p *rollbackPatcher) SetFieldManager(fieldManager string) {
+	p.fieldManager = fieldManager
+}
+
+// SetContext sets the context of the requests of a rollback.
+func (p *rollbackPatcher) SetContext(ctx context.Context) {
+	p.ctx = ctx
+}
+
+// SetTemplateOnly sets whether a rollback restores only the pod template of a revision.
+func (p *rollbackPatcher) SetTemplateOnly(templateOnly bool) {
+	p.templateOnly = templateOnly
+}
+
+func (p *rollbackPatcher) context() context.Context {
+	if p.ctx == nil {
+		return context.TODO()
+	}
+	return p.ctx
+}
+
+func (p *rollbackPatcher) patchOptions(dryRunStrategy cmdutil.DryRunStrategy) metav1.PatchOptions {
+	patchOptions := metav1.PatchOptions{FieldManager: p.fieldManager}
+	if dryRunStrategy == cmdutil.DryRunServer {
+		patchOptions.DryRun = []string{metav1.DryRunAll}
+	}
+	return patchOptions
+}
+
+// rollbackWithRetry runs a rollback, which reads the workload and sends the patch restoring toRevision computed from
+// what it read, along with the resource version read. When the workload changed in between, the server rejects the
+// patch with a conflict, and the rollback is run again with a bounded backoff, so that the patch is computed from the
+// changed workload instead of overwriting the change.
+func rollbackWithRetry(toRevision int64, rollback func() (string, error)) (string, error) {
+	var result string
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() (err error) {
+		result, err = rollback()
+		return err
+	})
+	var statusErr *apierrors.StatusError
+	if errors.As(err, &statusErr) && apierrors.IsConflict(statusErr) {
+		return "", fmt.Errorf("failed restoring revision %d: the workload kept being modified concurrently, giving up after %d attempts: %v", toRevision, retry.DefaultRetry.Steps, statusErr)
+	}
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// restoreFailed returns the error of a patch restoring toRevision, which still is a conflict if the patch conflicted.
+func restoreFailed(toRevision int64, err error) error {
+	return fmt.Errorf("failed restoring revision %d: %w", toRevision, err)
+}
+
+// logPatch logs the patch restoring revision of the workload of kind namespace/name. The payload is only logged from
+// -v=6, since the pod template it restores may hold sensitive data, such as the values of environment variables.
+func logPatch(kind, namespace, name string, revision int64, patchType types.PatchType, patch []byte) {
+	klog.V(4).Infof("Restoring revision %d of %s %s/%s with a %s patch", revision, kind, namespace, name, patchType)
+	klog.V(6).Infof("Patch restoring revision %d of %s %s/%s: %s", revision, kind, namespace, name, patch)
+}
+
+// revisionPatch returns the patch restoring the data of a controller revision, which additionally sets the given
+// annotations, and the resource version of the workload the patch is computed from, if any. The patch only restores
+// spec.template of the revision if only the pod template is restored.
+func (p *rollbackPatcher) revisionPatch(data []byte, annotations map[string]string, resourceVersion string) ([]byte, error) {
+	if p.templateOnly {
+		patchMap := map[string]interface{}{}
+		if err := json.Unmarshal(data, &patchMap); err != nil {
+			return nil, err
+		}
+		template, found, err := unstructured.NestedFieldNoCopy(patchMap, "spec", "template")
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("the revision does not record a pod template")
+		}
+		if data, err = json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": template}}); err != nil {
+			return nil, err
+		}
+	}
+	patch, err := patchWithAnnotations(data, annotations)
+	if err != nil || len(resourceVersion) == 0 {
+		return patch, err
+	}
+	patchMap := map[string]interface{}{}
+	if err := json.Unmarshal(patch, &patchMap); err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedField(patchMap, resourceVersion, "metadata", "resourceVersion"); err != nil {
+		return nil, err
+	}
+	return json.Marshal(patchMap)
+}
+
+// patchWithAnnotations returns a strategic merge or merge patch which additionally sets the given annotations
+// on the workload, so that they are changed by the same request as the pod template.
+func patchWithAnnotations(patch []byte, annotations map[string]string) ([]byte, error) {
+	if len(annotations) == 0 {
+		return patch, nil
+	}
+	patchMap := map[string]interface{}{}
+	if err := json.Unmarshal(patch, &patchMap); err != nil {
+		return nil, err
+	}
+	for k, v := range annotations {
+		if err := unstructured.SetNestedField(patchMap, v, "metadata", "annotations", k); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(patchMap)
+}
+
 type RollbackVisitor struct {
 	clientset       kubernetes.Interface
 	kruiseclientset kruiseclientsets.Interface
@@ -62,15 +228,15 @@ type RollbackVisitor struct {
 }
 
 func (v *RollbackVisitor) VisitDeployment(elem internalapps.GroupKindElement) {
-	v.result = &DeploymentRollbacker{v.clientset}
+	v.result = &DeploymentRollbacker{c: v.clientset}
 }
 
 func (v *RollbackVisitor) VisitStatefulSet(kind internalapps.GroupKindElement) {
-	v.result = &StatefulSetRollbacker{v.clientset}
+	v.result = &StatefulSetRollbacker{c: v.clientset}
 }
 
 func (v *RollbackVisitor) VisitDaemonSet(kind internalapps.GroupKindElement) {
-	v.result = &DaemonSetRollbacker{v.clientset}
+	v.result = &DaemonSetRollbacker{c: v.clientset}
 }
 
 func (v *RollbackVisitor) VisitCloneSet(kind internalapps.GroupKindElement) {
@@ -113,7 +279,14 @@ func RollbackerFor(kind schema.GroupKind, c kubernetes.Interface, kc kruiseclien
 	return visitor.result, nil
 }
 
+// CanRollback returns whether a Rollbacker is implemented for the given schema kind
+func CanRollback(kind schema.GroupKind) bool {
+	visitor := &RollbackVisitor{}
+	return internalapps.GroupKindElement(kind).Accept(visitor) == nil && visitor.result != nil
+}
+
 type DeploymentRollbacker struct {
+	rollbackPatcher
 	c kubernetes.Interface
 }
 
@@ -128,64 +301,118 @@ func (r *DeploymentRollbacker) Rollback(obj runtime.Object, updatedAnnotations m
 	name := accessor.GetName()
 	namespace := accessor.GetNamespace()
 
-	// TODO: Fix this after kubectl has been removed from core. It is not possible to convert the runtime.Object
-	// to the external appsv1 Deployment without round-tripping through an internal version of Deployment. We're
-	// currently getting rid of all internal versions of resources. So we specifically request the appsv1 version
-	// here. This follows the same pattern as for DaemonSet and StatefulSet.
-	deployment, err := r.c.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
-	if err != nil {
-		return "", fmt.Errorf("failed to retrieve Deployment %s: %v", name, err)
-	}
-
-	rsForRevision, err := deploymentRevision(deployment, r.c, toRevision)
-	if err != nil {
-		return "", err
-	}
-	if dryRunStrategy == cmdutil.DryRunClient {
-		return printTemplate(&rsForRevision.Spec.Template)
-	}
+	return rollbackWithRetry(toRevision, func() (string, error) {
+		deployment, rsForRevision, err := r.revision(namespace, name, toRevision)
+		if err != nil {
+			return "", err
+		}
+		if dryRunStrategy == cmdutil.DryRunClient {
+			return printTemplate(&rsForRevision.Spec.Template)
+		}
 
-	// Skip if the revision already matches current Deployment
-	if equalIgnoreHash(&rsForRevision.Spec.Template, &deployment.Spec.Template) {
-		return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
-	}
+		// Skip if the revision already matches current Deployment
+		if equalIgnoreHash(&rsForRevision.Spec.Template, &deployment.Spec.Template) {
+			return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
+		}
 
-	// remove hash label before patching back into the deployment
-	delete(rsForRevision.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+		// remove hash label before patching back into the deployment
+		delete(rsForRevision.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
 
-	// compute deployment annotations
-	annotations := map[string]string{}
+		// compute deployment annotations
+		annotations := map[string]string{}
 
-	// In the same vein as annotationsToSkip, which records annotations to exclude,
-	// IsKruiseRolloutsAnnotation checks whether an annotation is generated by kruise-rollout.
-	// Annotations identified as generated by kruise-rollout
-	// will be skipped when copying from ReplicaSet annotations to Deployment annotations.
-	for k, v := range deployment.Annotations {
-		if annotationsToSkip[k] || utils.IsKruiseRolloutsAnnotation(&k) {
-			annotations[k] = v
+		// In the same vein as annotationsToSkip, which records annotations to exclude,
+		// IsKruiseRolloutsAnnotation checks whether an annotation is generated by kruise-rollout.
+		// Annotations identified as generated by kruise-rollout
+		// will be skipped when copying from ReplicaSet annotations to Deployment annotations.
+		for k, v := range deployment.Annotations {
+			if annotationsToSkip[k] || utils.IsKruiseRolloutsAnnotation(&k) {
+				annotations[k] = v
+			}
 		}
-	}
-	for k, v := range rsForRevision.Annotations {
-		if !annotationsToSkip[k] && !utils.IsKruiseRolloutsAnnotation(&k) {
+		for k, v := range rsForRevision.Annotations {
+			if !annotationsToSkip[k] && !utils.IsKruiseRolloutsAnnotation(&k) {
+				annotations[k] = v
+			}
+		}
+		for k, v := range updatedAnnotations {
 			annotations[k] = v
 		}
+
+		// make patch to restore
+		patchType, patch, err := getDeploymentPatch(&rsForRevision.Spec.Template, annotations, deployment.ResourceVersion)
+		if err != nil {
+			return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+		}
+
+		patchOptions := r.patchOptions(dryRunStrategy)
+		// Restore revision
+		revision, _ := deploymentutil.Revision(rsForRevision)
+		logPatch("Deployment", namespace, name, revision, patchType, patch)
+		if _, err := r.c.AppsV1().Deployments(namespace).Patch(r.context(), name, patchType, patch, patchOptions); err != nil {
+			return "", restoreFailed(toRevision, err)
+		}
+		return rollbackSuccess, nil
+	})
+}
+
+// PreviewRollback returns the live pod template of the Deployment and the one stored in the ReplicaSet of toRevision.
+func (r *DeploymentRollbacker) PreviewRollback(obj runtime.Object, toRevision int64) (*corev1.PodTemplateSpec, *corev1.PodTemplateSpec, error) {
+	if toRevision < 0 {
+		return nil, nil, revisionNotFoundErr(toRevision)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
 	}
+	deployment, rsForRevision, err := r.revision(accessor.GetNamespace(), accessor.GetName(), toRevision)
+	if err != nil {
+		return nil, nil, err
+	}
+	target := rsForRevision.Spec.Template.DeepCopy()
+	delete(target.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	return &deployment.Spec.Template, target, nil
+}
 
-	// make patch to restore
-	patchType, patch, err := getDeploymentPatch(&rsForRevision.Spec.Template, annotations)
+// RollbackRevisions returns the revision the Deployment runs and the revision a rollback to toRevision restores.
+func (r *DeploymentRollbacker) RollbackRevisions(obj runtime.Object, toRevision int64) (int64, int64, error) {
+	if toRevision < 0 {
+		return 0, 0, revisionNotFoundErr(toRevision)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
+	}
+	deployment, rsForRevision, err := r.revision(accessor.GetNamespace(), accessor.GetName(), toRevision)
+	if err != nil {
+		return 0, 0, err
+	}
+	from, err := deploymentutil.Revision(deployment)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := deploymentutil.Revision(rsForRevision)
 	if err != nil {
-		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+		return 0, 0, err
 	}
+	return from, to, nil
+}
 
-	patchOptions := metav1.PatchOptions{}
-	if dryRunStrategy == cmdutil.DryRunServer {
-		patchOptions.DryRun = []string{metav1.DryRunAll}
+// revision returns the live Deployment and the ReplicaSet holding the template of toRevision.
+func (r *DeploymentRollbacker) revision(namespace, name string, toRevision int64) (*appsv1.Deployment, *appsv1.ReplicaSet, error) {
+	// TODO: Fix this after kubectl has been removed from core. It is not possible to convert the runtime.Object
+	// to the external appsv1 Deployment without round-tripping through an internal version of Deployment. We're
+	// currently getting rid of all internal versions of resources. So we specifically request the appsv1 version
+	// here. This follows the same pattern as for DaemonSet and StatefulSet.
+	deployment, err := r.c.AppsV1().Deployments(namespace).Get(r.context(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve Deployment %s: %v", name, err)
 	}
-	// Restore revision
-	if _, err = r.c.AppsV1().Deployments(namespace).Patch(context.TODO(), name, patchType, patch, patchOptions); err != nil {
-		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	rsForRevision, err := deploymentRevision(deployment, r.c, toRevision)
+	if err != nil {
+		return nil, nil, err
 	}
-	return rollbackSuccess, nil
+	return deployment, rsForRevision, nil
 }
 
 // equalIgnoreHash returns true if two given podTemplateSpec are equal, ignoring the diff in value of Labels[pod-template-hash]
@@ -215,9 +442,9 @@ var annotationsToSkip = map[string]bool{
 
 // getPatch returns a patch that can be applied to restore a Deployment to a
 // previous version. If the returned error is nil the patch is valid.
-func getDeploymentPatch(podTemplate *corev1.PodTemplateSpec, annotations map[string]string) (types.PatchType, []byte, error) {
+func getDeploymentPatch(podTemplate *corev1.PodTemplateSpec, annotations map[string]string, resourceVersion string) (types.PatchType, []byte, error) {
 	// Create a patch of the Deployment that replaces spec.template
-	patch, err := json.Marshal([]interface{}{
+	ops := []interface{}{
 		map[string]interface{}{
 			"op":    "replace",
 			"path":  "/spec/template",
@@ -228,7 +455,16 @@ func getDeploymentPatch(podTemplate *corev1.PodTemplateSpec, annotations map[str
 			"path":  "/metadata/annotations",
 			"value": annotations,
 		},
-	})
+	}
+	// the resource version read makes the server reject the patch if the Deployment changed since
+	if len(resourceVersion) != 0 {
+		ops = append(ops, map[string]interface{}{
+			"op":    "replace",
+			"path":  "/metadata/resourceVersion",
+			"value": resourceVersion,
+		})
+	}
+	patch, err := json.Marshal(ops)
 	return types.JSONPatchType, patch, err
 }
 
@@ -324,57 +560,95 @@ func deploymentRevision(deployment *appsv1.Deployment, c kubernetes.Interface, t
 }
 
 type DaemonSetRollbacker struct {
+	rollbackPatcher
 	c kubernetes.Interface
 }
 
 func (r *DaemonSetRollbacker) Rollback(obj runtime.Object, updatedAnnotations map[string]string, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
-	if toRevision < 0 {
-		return "", revisionNotFoundErr(toRevision)
-	}
-	accessor, err := meta.Accessor(obj)
-	if err != nil {
-		return "", fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
-	}
-	ds, history, err := daemonSetHistory(r.c.AppsV1(), accessor.GetNamespace(), accessor.GetName())
-	if err != nil {
-		return "", err
-	}
-	if toRevision == 0 && len(history) <= 1 {
-		return "", fmt.Errorf("no last revision to roll back to")
-	}
+	return rollbackWithRetry(toRevision, func() (string, error) {
+		ds, toHistory, err := r.revision(obj, toRevision)
+		if err != nil {
+			return "", err
+		}
 
-	toHistory := findHistory(toRevision, history)
-	if toHistory == nil {
-		return "", revisionNotFoundErr(toRevision)
-	}
+		if dryRunStrategy == cmdutil.DryRunClient {
+			appliedDS, err := applyDaemonSetHistory(ds, toHistory)
+			if err != nil {
+				return "", err
+			}
+			return printPodTemplate(&appliedDS.Spec.Template)
+		}
 
-	if dryRunStrategy == cmdutil.DryRunClient {
-		appliedDS, err := applyDaemonSetHistory(ds, toHistory)
+		// Skip if the revision already matches current DaemonSet
+		done, err := daemonSetMatch(ds, toHistory)
 		if err != nil {
 			return "", err
 		}
-		return printPodTemplate(&appliedDS.Spec.Template)
-	}
+		if done {
+			return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
+		}
+
+		patchOptions := r.patchOptions(dryRunStrategy)
+		patch, err := r.revisionPatch(toHistory.Data.Raw, updatedAnnotations, ds.ResourceVersion)
+		if err != nil {
+			return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+		}
+		// Restore revision
+		logPatch("DaemonSet", ds.Namespace, ds.Name, toHistory.Revision, types.StrategicMergePatchType, patch)
+		if _, err := r.c.AppsV1().DaemonSets(ds.Namespace).Patch(r.context(), ds.Name, types.StrategicMergePatchType, patch, patchOptions); err != nil {
+			return "", restoreFailed(toRevision, err)
+		}
+		return rollbackSuccess, nil
+	})
+}
 
-	// Skip if the revision already matches current DaemonSet
-	done, err := daemonSetMatch(ds, toHistory)
+// PreviewRollback returns the live pod template of the DaemonSet and the one restored from toRevision.
+func (r *DaemonSetRollbacker) PreviewRollback(obj runtime.Object, toRevision int64) (*corev1.PodTemplateSpec, *corev1.PodTemplateSpec, error) {
+	ds, toHistory, err := r.revision(obj, toRevision)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-	if done {
-		return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
+	applied, err := applyDaemonSetHistory(ds, toHistory)
+	if err != nil {
+		return nil, nil, err
 	}
+	return &ds.Spec.Template, &applied.Spec.Template, nil
+}
 
-	patchOptions := metav1.PatchOptions{}
-	if dryRunStrategy == cmdutil.DryRunServer {
-		patchOptions.DryRun = []string{metav1.DryRunAll}
+// RollbackRevisions returns the revision the DaemonSet runs and the revision a rollback to toRevision restores.
+func (r *DaemonSetRollbacker) RollbackRevisions(obj runtime.Object, toRevision int64) (int64, int64, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
 	}
-	// Restore revision
-	if _, err = r.c.AppsV1().DaemonSets(accessor.GetNamespace()).Patch(context.TODO(), accessor.GetName(), types.StrategicMergePatchType, toHistory.Data.Raw, patchOptions); err != nil {
-		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	_, history, err := daemonSetHistory(r.context(), r.c.AppsV1(), accessor.GetNamespace(), accessor.GetName())
+	if err != nil {
+		return 0, 0, err
 	}
+	return rollbackRevisions(toRevision, history)
+}
 
-	return rollbackSuccess, nil
+// revision returns the live DaemonSet and the controller revision matching toRevision.
+func (r *DaemonSetRollbacker) revision(obj runtime.Object, toRevision int64) (*appsv1.DaemonSet, *appsv1.ControllerRevision, error) {
+	if toRevision < 0 {
+		return nil, nil, revisionNotFoundErr(toRevision)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
+	}
+	ds, history, err := daemonSetHistory(r.context(), r.c.AppsV1(), accessor.GetNamespace(), accessor.GetName())
+	if err != nil {
+		return nil, nil, err
+	}
+	if toRevision == 0 && len(history) <= 1 {
+		return nil, nil, fmt.Errorf("no last revision to roll back to")
+	}
+	toHistory := findHistory(toRevision, history)
+	if toHistory == nil {
+		return nil, nil, historyRevisionNotFoundErr(toRevision, history)
+	}
+	return ds, toHistory, nil
 }
 
 // daemonMatch check if the given DaemonSet's template matches the template stored in the given history.
@@ -383,7 +657,16 @@ func daemonSetMatch(ds *appsv1.DaemonSet, history *appsv1.ControllerRevision) (b
 	if err != nil {
 		return false, err
 	}
-	return bytes.Equal(patch, history.Data.Raw), nil
+	if bytes.Equal(patch, history.Data.Raw) {
+		return true, nil
+	}
+	// the revision may be encoded differently from the live object, e.g. without empty fields,
+	// so compare the template it restores as well
+	applied, err := applyDaemonSetHistory(ds, history)
+	if err != nil {
+		return false, err
+	}
+	return apiequality.Semantic.DeepEqual(ds.Spec.Template, applied.Spec.Template), nil
 }
 
 // getPatch returns a strategic merge patch that can be applied to restore a Daemonset to a
@@ -414,180 +697,322 @@ func getDaemonSetPatch(ds *appsv1.DaemonSet) ([]byte, error) {
 }
 
 type StatefulSetRollbacker struct {
+	rollbackPatcher
 	c kubernetes.Interface
 }
 
 // toRevision is a non-negative integer, with 0 being reserved to indicate rolling back to previous configuration
 func (r *StatefulSetRollbacker) Rollback(obj runtime.Object, updatedAnnotations map[string]string, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
-	if toRevision < 0 {
-		return "", revisionNotFoundErr(toRevision)
-	}
-	accessor, err := meta.Accessor(obj)
-	if err != nil {
-		return "", fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
-	}
-	sts, history, err := statefulSetHistory(r.c.AppsV1(), accessor.GetNamespace(), accessor.GetName())
-	if err != nil {
-		return "", err
-	}
-	if toRevision == 0 && len(history) <= 1 {
-		return "", fmt.Errorf("no last revision to roll back to")
-	}
+	return rollbackWithRetry(toRevision, func() (string, error) {
+		sts, toHistory, err := r.revision(obj, toRevision)
+		if err != nil {
+			return "", err
+		}
 
-	toHistory := findHistory(toRevision, history)
-	if toHistory == nil {
-		return "", revisionNotFoundErr(toRevision)
-	}
+		if dryRunStrategy == cmdutil.DryRunClient {
+			appliedSS, err := applyRevision(sts, toHistory)
+			if err != nil {
+				return "", err
+			}
+			return printPodTemplate(&appliedSS.Spec.Template)
+		}
 
-	if dryRunStrategy == cmdutil.DryRunClient {
-		appliedSS, err := applyRevision(sts, toHistory)
+		// Skip if the revision already matches current StatefulSet
+		done, err := statefulsetMatch(sts, toHistory)
 		if err != nil {
 			return "", err
 		}
-		return printPodTemplate(&appliedSS.Spec.Template)
-	}
+		if done {
+			return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
+		}
 
-	// Skip if the revision already matches current StatefulSet
-	done, err := statefulsetMatch(sts, toHistory)
+		patchOptions := r.patchOptions(dryRunStrategy)
+		patch, err := r.revisionPatch(toHistory.Data.Raw, updatedAnnotations, sts.ResourceVersion)
+		if err != nil {
+			return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+		}
+		// Restore revision
+		logPatch("StatefulSet", sts.Namespace, sts.Name, toHistory.Revision, types.StrategicMergePatchType, patch)
+		if _, err := r.c.AppsV1().StatefulSets(sts.Namespace).Patch(r.context(), sts.Name, types.StrategicMergePatchType, patch, patchOptions); err != nil {
+			return "", restoreFailed(toRevision, err)
+		}
+		return rollbackSuccess, nil
+	})
+}
+
+// PreviewRollback returns the live pod template of the StatefulSet and the one restored from toRevision.
+func (r *StatefulSetRollbacker) PreviewRollback(obj runtime.Object, toRevision int64) (*corev1.PodTemplateSpec, *corev1.PodTemplateSpec, error) {
+	sts, toHistory, err := r.revision(obj, toRevision)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-	if done {
-		return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
+	applied, err := applyRevision(sts, toHistory)
+	if err != nil {
+		return nil, nil, err
 	}
+	return &sts.Spec.Template, &applied.Spec.Template, nil
+}
 
-	patchOptions := metav1.PatchOptions{}
-	if dryRunStrategy == cmdutil.DryRunServer {
-		patchOptions.DryRun = []string{metav1.DryRunAll}
+// RollbackRevisions returns the revision the StatefulSet runs and the revision a rollback to toRevision restores.
+func (r *StatefulSetRollbacker) RollbackRevisions(obj runtime.Object, toRevision int64) (int64, int64, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
 	}
-	// Restore revision
-	if _, err = r.c.AppsV1().StatefulSets(sts.Namespace).Patch(context.TODO(), sts.Name, types.StrategicMergePatchType, toHistory.Data.Raw, patchOptions); err != nil {
-		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	_, history, err := statefulSetHistory(r.context(), r.c.AppsV1(), accessor.GetNamespace(), accessor.GetName())
+	if err != nil {
+		return 0, 0, err
 	}
-
-	return rollbackSuccess, nil
+	return rollbackRevisions(toRevision, history)
 }
 
-type CloneSetRollbacker struct {
-	k  kubernetes.Interface
-	kc kruiseclientsets.Interface
-}
-
-func (r *CloneSetRollbacker) Rollback(obj runtime.Object,
-	updatedAnnotations map[string]string,
-	toRevision int64,
-	dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
-
+// revision returns the live StatefulSet and the controller revision matching toRevision.
+func (r *StatefulSetRollbacker) revision(obj runtime.Object, toRevision int64) (*appsv1.StatefulSet, *appsv1.ControllerRevision, error) {
 	if toRevision < 0 {
-		return "", revisionNotFoundErr(toRevision)
+		return nil, nil, revisionNotFoundErr(toRevision)
 	}
 	accessor, err := meta.Accessor(obj)
 	if err != nil {
-		return "", fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
+		return nil, nil, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
 	}
-	cs, history, err := clonesetHistory(r.k.AppsV1(), r.kc.AppsV1alpha1(), accessor.GetNamespace(), accessor.GetName())
+	sts, history, err := statefulSetHistory(r.context(), r.c.AppsV1(), accessor.GetNamespace(), accessor.GetName())
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 	if toRevision == 0 && len(history) <= 1 {
-		return "", fmt.Errorf("no last revision to roll back to")
+		return nil, nil, fmt.Errorf("no last revision to roll back to")
 	}
 	toHistory := findHistory(toRevision, history)
 	if toHistory == nil {
-		return "", revisionNotFoundErr(toRevision)
+		return nil, nil, historyRevisionNotFoundErr(toRevision, history)
 	}
+	return sts, toHistory, nil
+}
+
+type CloneSetRollbacker struct {
+	rollbackPatcher
+	k  kubernetes.Interface
+	kc kruiseclientsets.Interface
+}
 
-	if dryRunStrategy == cmdutil.DryRunClient {
-		appliedSS, err := applyCloneSetRevision(cs, toHistory)
+func (r *CloneSetRollbacker) Rollback(obj runtime.Object,
+	updatedAnnotations map[string]string,
+	toRevision int64,
+	dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
+
+	return rollbackWithRetry(toRevision, func() (string, error) {
+		cs, toHistory, err := r.revision(obj, toRevision)
 		if err != nil {
 			return "", err
 		}
-		return printPodTemplate(&appliedSS.Spec.Template)
-	}
 
-	// Skip if the revision already matches current CloneSet
-	done, err := cloneSetMatch(cs, toHistory)
+		if dryRunStrategy == cmdutil.DryRunClient {
+			appliedSS, err := applyCloneSetRevision(cs, toHistory)
+			if err != nil {
+				return "", err
+			}
+			return printPodTemplate(&appliedSS.Spec.Template)
+		}
+
+		// Skip if the CloneSet already runs the revision, restoring it would only bump its generation
+		if cs.Status.ObservedGeneration == cs.Generation && isRevision(toHistory, cs.Status.UpdateRevision) {
+			return fmt.Sprintf("%s (already at revision %d)", rollbackSkipped, toHistory.Revision), nil
+		}
+
+		// Skip if the revision already matches current CloneSet
+		done, err := cloneSetMatch(cs, toHistory)
+		if err != nil {
+			return "", err
+		}
+		if done {
+			return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
+		}
+
+		patchOptions := r.patchOptions(dryRunStrategy)
+		patch, err := r.revisionPatch(toHistory.Data.Raw, updatedAnnotations, cs.ResourceVersion)
+		if err != nil {
+			return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+		}
+
+		// Restore revision
+		logPatch("CloneSet", cs.Namespace, cs.Name, toHistory.Revision, types.MergePatchType, patch)
+		if _, err := r.kc.AppsV1alpha1().CloneSets(cs.Namespace).Patch(r.context(), cs.Name, types.MergePatchType, patch, patchOptions); err != nil {
+			return "", restoreFailed(toRevision, err)
+		}
+		return rollbackSuccess, nil
+	})
+}
+
+// PreviewRollback returns the live pod template of the CloneSet and the one restored from toRevision.
+func (r *CloneSetRollbacker) PreviewRollback(obj runtime.Object, toRevision int64) (*corev1.PodTemplateSpec, *corev1.PodTemplateSpec, error) {
+	cs, toHistory, err := r.revision(obj, toRevision)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-	if done {
-		return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
+	applied, err := applyCloneSetRevision(cs, toHistory)
+	if err != nil {
+		return nil, nil, err
 	}
+	return &cs.Spec.Template, &applied.Spec.Template, nil
+}
 
-	patchOptions := metav1.PatchOptions{}
-	if dryRunStrategy == cmdutil.DryRunServer {
-		patchOptions.DryRun = []string{metav1.DryRunAll}
+// RollbackRevisions returns the revision the CloneSet runs and the revision a rollback to toRevision restores.
+func (r *CloneSetRollbacker) RollbackRevisions(obj runtime.Object, toRevision int64) (int64, int64, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
 	}
-
-	// Restore revision
-	_, err = r.kc.AppsV1alpha1().CloneSets(cs.Namespace).Patch(context.TODO(), cs.Name, types.MergePatchType, toHistory.Data.Raw, patchOptions)
+	_, history, err := clonesetHistory(r.context(), r.k.AppsV1(), r.kc.AppsV1alpha1(), accessor.GetNamespace(), accessor.GetName())
 	if err != nil {
-		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+		return 0, 0, err
 	}
-
-	return rollbackSuccess, nil
+	return rollbackRevisions(toRevision, history)
 }
 
-type AdvancedStatefulSetRollbacker struct {
-	k  kubernetes.Interface
-	kc kruiseclientsets.Interface
-}
-
-func (r *AdvancedStatefulSetRollbacker) Rollback(obj runtime.Object,
-	updatedAnnotations map[string]string,
-	toRevision int64,
-	dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
+// revision returns the live CloneSet and the controller revision matching toRevision.
+func (r *CloneSetRollbacker) revision(obj runtime.Object, toRevision int64) (*kruiseappsv1alpha1.CloneSet, *appsv1.ControllerRevision, error) {
 	if toRevision < 0 {
-		return "", revisionNotFoundErr(toRevision)
+		return nil, nil, revisionNotFoundErr(toRevision)
 	}
 	accessor, err := meta.Accessor(obj)
 	if err != nil {
-		return "", fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
+		return nil, nil, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
 	}
-	asts, history, err := advancedstsHistory(r.k.AppsV1(), r.kc.AppsV1beta1(), accessor.GetNamespace(), accessor.GetName())
+	cs, history, err := clonesetHistory(r.context(), r.k.AppsV1(), r.kc.AppsV1alpha1(), accessor.GetNamespace(), accessor.GetName())
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 	if toRevision == 0 && len(history) <= 1 {
-		return "", fmt.Errorf("no latest revision to roll back to")
+		return nil, nil, fmt.Errorf("no last revision to roll back to")
 	}
 	toHistory := findHistory(toRevision, history)
 	if toHistory == nil {
-		return "", revisionNotFoundErr(toRevision)
+		return nil, nil, historyRevisionNotFoundErr(toRevision, history)
 	}
-	if dryRunStrategy == cmdutil.DryRunClient {
-		appliedSS, err := applyAdvancedStatefulSetRevision(asts, toHistory)
+	return cs, toHistory, nil
+}
+
+type AdvancedStatefulSetRollbacker struct {
+	rollbackPatcher
+	k  kubernetes.Interface
+	kc kruiseclientsets.Interface
+	// restoreStrategy restores spec.updateStrategy.rollingUpdate of the revision, instead of keeping the live one
+	restoreStrategy bool
+}
+
+// SetRestoreStrategy sets whether a rollback restores the rolling update settings a revision records. The partition
+// is kept by default, since restoring a lower one updates the pods of the ordinals the live one holds back at once.
+func (r *AdvancedStatefulSetRollbacker) SetRestoreStrategy(restoreStrategy bool) {
+	r.restoreStrategy = restoreStrategy
+}
+
+func (r *AdvancedStatefulSetRollbacker) Rollback(obj runtime.Object,
+	updatedAnnotations map[string]string,
+	toRevision int64,
+	dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
+	return rollbackWithRetry(toRevision, func() (string, error) {
+		asts, toHistory, err := r.revision(obj, toRevision)
 		if err != nil {
 			return "", err
 		}
-		return printPodTemplate(&appliedSS.Spec.Template)
-	}
-	// Skip if the revision already matches current CloneSet
-	done, err := astsMatch(asts, toHistory)
+		if dryRunStrategy == cmdutil.DryRunClient {
+			appliedSS, err := applyAdvancedStatefulSetRevision(asts, toHistory)
+			if err != nil {
+				return "", err
+			}
+			return printPodTemplate(&appliedSS.Spec.Template)
+		}
+		// Skip if the Advanced StatefulSet already runs the revision
+		if asts.Status.ObservedGeneration == asts.Generation && isRevision(toHistory, asts.Status.UpdateRevision) {
+			return fmt.Sprintf("%s (already at revision %d)", rollbackSkipped, toHistory.Revision), nil
+		}
+		// Skip if the revision already matches current CloneSet
+		done, err := astsMatch(asts, toHistory)
+		if err != nil {
+			return "", err
+		}
+		if done {
+			return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
+		}
+
+		patchOptions := r.patchOptions(dryRunStrategy)
+		patch, err := r.revisionPatch(toHistory.Data.Raw, updatedAnnotations, asts.ResourceVersion)
+		if err == nil && !r.restoreStrategy {
+			patch, err = withoutRollingUpdate(patch)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+		}
+
+		// Restore revision
+		logPatch("Advanced StatefulSet", asts.Namespace, asts.Name, toHistory.Revision, types.MergePatchType, patch)
+		if _, err := r.kc.AppsV1beta1().StatefulSets(asts.Namespace).Patch(r.context(), asts.Name, types.MergePatchType, patch, patchOptions); err != nil {
+			return "", restoreFailed(toRevision, err)
+		}
+		return rollbackSuccess, nil
+	})
+}
+
+// PreviewRollback returns the live pod template of the Advanced StatefulSet and the one restored from toRevision.
+func (r *AdvancedStatefulSetRollbacker) PreviewRollback(obj runtime.Object, toRevision int64) (*corev1.PodTemplateSpec, *corev1.PodTemplateSpec, error) {
+	asts, toHistory, err := r.revision(obj, toRevision)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-	if done {
-		return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
+	applied, err := applyAdvancedStatefulSetRevision(asts, toHistory)
+	if err != nil {
+		return nil, nil, err
 	}
+	return &asts.Spec.Template, &applied.Spec.Template, nil
+}
 
-	patchOptions := metav1.PatchOptions{}
-	if dryRunStrategy == cmdutil.DryRunServer {
-		patchOptions.DryRun = []string{metav1.DryRunAll}
+// RollbackRevisions returns the revision the Advanced StatefulSet runs and the revision a rollback to toRevision restores.
+func (r *AdvancedStatefulSetRollbacker) RollbackRevisions(obj runtime.Object, toRevision int64) (int64, int64, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
 	}
-
-	// Restore revision
-	_, err = r.kc.AppsV1beta1().StatefulSets(asts.Namespace).Patch(context.TODO(), asts.Name, types.MergePatchType, toHistory.Data.Raw, patchOptions)
+	_, history, err := advancedstsHistory(r.context(), r.k.AppsV1(), r.kc.AppsV1beta1(), accessor.GetNamespace(), accessor.GetName())
 	if err != nil {
-		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+		return 0, 0, err
 	}
+	return rollbackRevisions(toRevision, history)
+}
 
-	return rollbackSuccess, nil
+// revision returns the live Advanced StatefulSet and the controller revision matching toRevision.
+func (r *AdvancedStatefulSetRollbacker) revision(obj runtime.Object, toRevision int64) (*kruiseappsv1beta1.StatefulSet, *appsv1.ControllerRevision, error) {
+	if toRevision < 0 {
+		return nil, nil, revisionNotFoundErr(toRevision)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
+	}
+	asts, history, err := advancedstsHistory(r.context(), r.k.AppsV1(), r.kc.AppsV1beta1(), accessor.GetNamespace(), accessor.GetName())
+	if err != nil {
+		return nil, nil, err
+	}
+	if toRevision == 0 && len(history) <= 1 {
+		return nil, nil, fmt.Errorf("no latest revision to roll back to")
+	}
+	toHistory := findHistory(toRevision, history)
+	if toHistory == nil {
+		return nil, nil, historyRevisionNotFoundErr(toRevision, history)
+	}
+	return asts, toHistory, nil
 }
 
 type AdvancedDaemonSetRollbacker struct {
+	rollbackPatcher
 	k  kubernetes.Interface
 	kc kruiseclientsets.Interface
+	// restoreStrategy restores spec.updateStrategy.rollingUpdate of the revision, instead of keeping the live one
+	restoreStrategy bool
+}
+
+// SetRestoreStrategy sets whether a rollback restores the rolling update settings a revision records.
+func (r *AdvancedDaemonSetRollbacker) SetRestoreStrategy(restoreStrategy bool) {
+	r.restoreStrategy = restoreStrategy
 }
 
 type RolloutRollbacker struct {
@@ -608,53 +1033,115 @@ func (r *AdvancedDaemonSetRollbacker) Rollback(obj runtime.Object,
 	updatedAnnotations map[string]string,
 	toRevision int64,
 	dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
-	if toRevision < 0 {
-		return "", revisionNotFoundErr(toRevision)
-	}
-	accessor, err := meta.Accessor(obj)
-	if err != nil {
-		return "", fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
-	}
-	ads, history, err := advancedDaemonSetHistory(r.k.AppsV1(), r.kc.AppsV1alpha1(), accessor.GetNamespace(), accessor.GetName())
-	if err != nil {
-		return "", err
-	}
-	if toRevision == 0 && len(history) <= 1 {
-		return "", fmt.Errorf("no last revision to roll back to")
-	}
+	return rollbackWithRetry(toRevision, func() (string, error) {
+		ads, toHistory, err := r.revision(obj, toRevision)
+		if err != nil {
+			return "", err
+		}
 
-	toHistory := findHistory(toRevision, history)
-	if toHistory == nil {
-		return "", revisionNotFoundErr(toRevision)
-	}
+		if dryRunStrategy == cmdutil.DryRunClient {
+			appliedDS, err := applyAdvancedDaemonSetHistory(ads, toHistory)
+			if err != nil {
+				return "", err
+			}
+			return printPodTemplate(&appliedDS.Spec.Template)
+		}
+
+		// Skip if the Advanced DaemonSet already runs the revision, which its status records by hash
+		if ads.Status.ObservedGeneration == ads.Generation && isRevision(toHistory, ads.Status.DaemonSetHash) {
+			return fmt.Sprintf("%s (already at revision %d)", rollbackSkipped, toHistory.Revision), nil
+		}
 
-	if dryRunStrategy == cmdutil.DryRunClient {
-		appliedDS, err := applyAdvancedDaemonSetHistory(ads, toHistory)
+		// Skip if the revision already matches current DaemonSet
+		done, err := advancedDaemonSetMatch(ads, toHistory)
 		if err != nil {
 			return "", err
 		}
-		return printPodTemplate(&appliedDS.Spec.Template)
+		if done {
+			return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
+		}
+
+		patchOptions := r.patchOptions(dryRunStrategy)
+		patch, err := r.revisionPatch(toHistory.Data.Raw, updatedAnnotations, ads.ResourceVersion)
+		if err == nil && !r.restoreStrategy {
+			patch, err = withoutRollingUpdate(patch)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+		}
+		// Restore revision
+		logPatch("Advanced DaemonSet", ads.Namespace, ads.Name, toHistory.Revision, types.MergePatchType, patch)
+		if _, err := r.kc.AppsV1alpha1().DaemonSets(ads.Namespace).Patch(r.context(), ads.Name, types.MergePatchType, patch, patchOptions); err != nil {
+			return "", restoreFailed(toRevision, err)
+		}
+		return rollbackSuccess, nil
+	})
+}
+
+// withoutRollingUpdate removes spec.updateStrategy.rollingUpdate from a merge patch, so that the maxUnavailable and the
+// partition the workload runs with are not changed by the patch, even if the revision records them.
+func withoutRollingUpdate(patch []byte) ([]byte, error) {
+	patchMap := map[string]interface{}{}
+	if err := json.Unmarshal(patch, &patchMap); err != nil {
+		return nil, err
+	}
+	if _, found, err := unstructured.NestedFieldNoCopy(patchMap, "spec", "updateStrategy", "rollingUpdate"); err != nil || !found {
+		return patch, err
 	}
+	unstructured.RemoveNestedField(patchMap, "spec", "updateStrategy", "rollingUpdate")
+	if strategy, _, _ := unstructured.NestedMap(patchMap, "spec", "updateStrategy"); len(strategy) == 0 {
+		unstructured.RemoveNestedField(patchMap, "spec", "updateStrategy")
+	}
+	return json.Marshal(patchMap)
+}
 
-	// Skip if the revision already matches current DaemonSet
-	done, err := advancedDaemonSetMatch(ads, toHistory)
+// PreviewRollback returns the live pod template of the Advanced DaemonSet and the one restored from toRevision.
+func (r *AdvancedDaemonSetRollbacker) PreviewRollback(obj runtime.Object, toRevision int64) (*corev1.PodTemplateSpec, *corev1.PodTemplateSpec, error) {
+	ads, toHistory, err := r.revision(obj, toRevision)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-	if done {
-		return fmt.Sprintf("%s (current template already matches revision %d)", rollbackSkipped, toRevision), nil
+	applied, err := applyAdvancedDaemonSetHistory(ads, toHistory)
+	if err != nil {
+		return nil, nil, err
 	}
+	return &ads.Spec.Template, &applied.Spec.Template, nil
+}
 
-	patchOptions := metav1.PatchOptions{}
-	if dryRunStrategy == cmdutil.DryRunServer {
-		patchOptions.DryRun = []string{metav1.DryRunAll}
+// RollbackRevisions returns the revision the Advanced DaemonSet runs and the revision a rollback to toRevision restores.
+func (r *AdvancedDaemonSetRollbacker) RollbackRevisions(obj runtime.Object, toRevision int64) (int64, int64, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
 	}
-	// Restore revision
-	if _, err = r.kc.AppsV1alpha1().DaemonSets(ads.Namespace).Patch(context.TODO(), ads.Name, types.MergePatchType, toHistory.Data.Raw, patchOptions); err != nil {
-		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	_, history, err := advancedDaemonSetHistory(r.context(), r.k.AppsV1(), r.kc.AppsV1alpha1(), accessor.GetNamespace(), accessor.GetName())
+	if err != nil {
+		return 0, 0, err
 	}
+	return rollbackRevisions(toRevision, history)
+}
 
-	return rollbackSuccess, nil
+// revision returns the live Advanced DaemonSet and the controller revision matching toRevision.
+func (r *AdvancedDaemonSetRollbacker) revision(obj runtime.Object, toRevision int64) (*kruiseappsv1alpha1.DaemonSet, *appsv1.ControllerRevision, error) {
+	if toRevision < 0 {
+		return nil, nil, revisionNotFoundErr(toRevision)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
+	}
+	ads, history, err := advancedDaemonSetHistory(r.context(), r.k.AppsV1(), r.kc.AppsV1alpha1(), accessor.GetNamespace(), accessor.GetName())
+	if err != nil {
+		return nil, nil, err
+	}
+	if toRevision == 0 && len(history) <= 1 {
+		return nil, nil, fmt.Errorf("no last revision to roll back to")
+	}
+	toHistory := findHistory(toRevision, history)
+	if toHistory == nil {
+		return nil, nil, historyRevisionNotFoundErr(toRevision, history)
+	}
+	return ads, toHistory, nil
 }
 
 var appsCodec = scheme.Codecs.LegacyCodec(appsv1.SchemeGroupVersion)
@@ -707,7 +1194,17 @@ func applyAdvancedStatefulSetRevision(asts *kruiseappsv1beta1.StatefulSet,
 	if err != nil {
 		return nil, err
 	}
-	return nil, err
+	return result, nil
+}
+
+// isRevision returns whether history is the revision a workload or a Rollout records as revision, which is either the
+// full name of the controller revision or its controller-revision-hash. An empty revision matches no history.
+func isRevision(history *appsv1.ControllerRevision, revision string) bool {
+	if len(revision) == 0 {
+		return false
+	}
+	return history.Name == revision || strings.HasSuffix(history.Name, "-"+revision) ||
+		history.Labels[appsv1.ControllerRevisionHashLabelKey] == revision
 }
 
 // statefulsetMatch check if the given StatefulSet's template matches the template stored in the given history.
@@ -743,7 +1240,15 @@ func advancedDaemonSetMatch(ads *kruiseappsv1alpha1.DaemonSet, history *appsv1.C
 	if err != nil {
 		return false, err
 	}
-	return bytes.Equal(patch, history.Data.Raw), nil
+	if bytes.Equal(patch, history.Data.Raw) {
+		return true, nil
+	}
+	// see daemonSetMatch
+	applied, err := applyAdvancedDaemonSetHistory(ads, history)
+	if err != nil {
+		return false, err
+	}
+	return apiequality.Semantic.DeepEqual(ads.Spec.Template, applied.Spec.Template), nil
 }
 
 // getStatefulSetPatch returns a strategic merge patch that can be applied to restore a StatefulSet to a
@@ -862,6 +1367,28 @@ func findHistory(toRevision int64, allHistory []*appsv1.ControllerRevision) *app
 	return toHistory
 }
 
+// rollbackRevisions returns the latest revision in history, which is the one the workload runs, and the revision
+// of the history a rollback to toRevision restores.
+func rollbackRevisions(toRevision int64, history []*appsv1.ControllerRevision) (int64, int64, error) {
+	if toRevision < 0 {
+		return 0, 0, revisionNotFoundErr(toRevision)
+	}
+	if toRevision == 0 && len(history) <= 1 {
+		return 0, 0, fmt.Errorf("no last revision to roll back to")
+	}
+	toHistory := findHistory(toRevision, history)
+	if toHistory == nil {
+		return 0, 0, historyRevisionNotFoundErr(toRevision, history)
+	}
+	var from int64
+	for _, h := range history {
+		if h.Revision > from {
+			from = h.Revision
+		}
+	}
+	return from, toHistory.Revision, nil
+}
+
 // printPodTemplate converts a given pod template into a human-readable string.
 func printPodTemplate(specTemplate *corev1.PodTemplateSpec) (string, error) {
 	podSpec, err := printTemplate(specTemplate)
@@ -875,6 +1402,24 @@ func revisionNotFoundErr(r int64) error {
 	return fmt.Errorf("unable to find specified revision %v in history", r)
 }
 
+// historyRevisionNotFoundErr returns the error of toRevision missing from history. A revision older than the oldest
+// one left in history was pruned by the controller, according to the revision history limit of the workload.
+func historyRevisionNotFoundErr(toRevision int64, history []*appsv1.ControllerRevision) error {
+	if toRevision <= 0 || len(history) == 0 {
+		return revisionNotFoundErr(toRevision)
+	}
+	oldest := history[0].Revision
+	for _, h := range history[1:] {
+		if h.Revision < oldest {
+			oldest = h.Revision
+		}
+	}
+	if toRevision < oldest {
+		return fmt.Errorf("revision %d is no longer in history, it was pruned according to the revision history limit, the oldest available revision is %d", toRevision, oldest)
+	}
+	return revisionNotFoundErr(toRevision)
+}
+
 // TODO: copied from daemon controller, should extract to a library
 type historiesByRevision []*appsv1.ControllerRevision
 
@@ -0,0 +1,664 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/utils/pointer"
+)
+
+func TestDaemonSetRollbackerSkipsIdenticalRevision(t *testing.T) {
+	// the live template matches revision 2, although its encoding carries empty fields the revision doesn't
+	template := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "nginx:1.2"}}},
+	}
+	meta := metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("ds-uid")}
+	selector := &metav1.LabelSelector{MatchLabels: historyTestLabels}
+
+	testCases := []struct {
+		name        string
+		toRevision  int64
+		expect      string
+		expectPatch bool
+	}{
+		{
+			name:       "identical revision",
+			toRevision: 2,
+			expect:     "skipped rollback (current template already matches revision 2)",
+		},
+		{
+			name:        "different revision",
+			toRevision:  1,
+			expect:      rollbackSuccess,
+			expectPatch: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run("daemonset "+tc.name, func(t *testing.T) {
+			ds := &appsv1.DaemonSet{ObjectMeta: meta, Spec: appsv1.DaemonSetSpec{Selector: selector, Template: template}}
+			revisions := newHistoryTestRevisions(ds, appsv1.SchemeGroupVersion.WithKind("DaemonSet"))
+			client := fake.NewSimpleClientset(append(revisions, ds)...)
+
+			rollbacker := &DaemonSetRollbacker{c: client}
+			result, err := rollbacker.Rollback(ds, nil, tc.toRevision, cmdutil.DryRunNone)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, result)
+			assert.Equal(t, tc.expectPatch, hasPatchAction(client.Actions()))
+		})
+
+		t.Run("advanced daemonset "+tc.name, func(t *testing.T) {
+			ads := &kruiseappsv1alpha1.DaemonSet{ObjectMeta: meta, Spec: kruiseappsv1alpha1.DaemonSetSpec{Selector: selector, Template: template}}
+			revisions := newHistoryTestRevisions(ads, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("DaemonSet"))
+			client, kruiseClient := fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(ads)
+
+			rollbacker := &AdvancedDaemonSetRollbacker{k: client, kc: kruiseClient}
+			result, err := rollbacker.Rollback(ads, nil, tc.toRevision, cmdutil.DryRunNone)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, result)
+			assert.Equal(t, tc.expectPatch, hasPatchAction(kruiseClient.Actions()))
+		})
+	}
+}
+
+func TestRollbackerSkipsCurrentRevision(t *testing.T) {
+	// the live template differs from the one revision 3 records, so it is the status which tells it is current
+	meta := metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("demo-uid"), Generation: 2}
+	selector := &metav1.LabelSelector{MatchLabels: historyTestLabels}
+
+	testCases := []struct {
+		name               string
+		toRevision         int64
+		observedGeneration int64
+		expect             string
+		expectPatch        bool
+	}{
+		{
+			name:               "current revision",
+			toRevision:         3,
+			observedGeneration: 2,
+			expect:             "skipped rollback (already at revision 3)",
+		},
+		{
+			name:               "other revision",
+			toRevision:         2,
+			observedGeneration: 2,
+			expect:             rollbackSuccess,
+			expectPatch:        true,
+		},
+		{
+			// the controller may not have observed a change of the template yet, the status is out of date then
+			name:               "current revision not observed",
+			toRevision:         3,
+			observedGeneration: 1,
+			expect:             rollbackSuccess,
+			expectPatch:        true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run("cloneset "+tc.name, func(t *testing.T) {
+			cs := &kruiseappsv1alpha1.CloneSet{
+				ObjectMeta: meta,
+				Spec:       kruiseappsv1alpha1.CloneSetSpec{Selector: selector, Template: newHistoryTestTemplate()},
+				Status:     kruiseappsv1alpha1.CloneSetStatus{ObservedGeneration: tc.observedGeneration, UpdateRevision: "demo-3"},
+			}
+			revisions := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+			client, kruiseClient := fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(cs)
+
+			rollbacker := &CloneSetRollbacker{k: client, kc: kruiseClient}
+			result, err := rollbacker.Rollback(cs, nil, tc.toRevision, cmdutil.DryRunNone)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, result)
+			assert.Equal(t, tc.expectPatch, hasPatchAction(kruiseClient.Actions()))
+		})
+
+		t.Run("advanced statefulset "+tc.name, func(t *testing.T) {
+			asts := &kruiseappsv1beta1.StatefulSet{
+				ObjectMeta: meta,
+				Spec:       kruiseappsv1beta1.StatefulSetSpec{Selector: selector, Template: newHistoryTestTemplate()},
+				Status:     kruiseappsv1beta1.StatefulSetStatus{ObservedGeneration: tc.observedGeneration, UpdateRevision: "demo-3"},
+			}
+			revisions := newHistoryTestRevisions(asts, kruiseappsv1beta1.SchemeGroupVersion.WithKind("StatefulSet"))
+			client, kruiseClient := fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(asts)
+
+			rollbacker := &AdvancedStatefulSetRollbacker{k: client, kc: kruiseClient}
+			result, err := rollbacker.Rollback(asts, nil, tc.toRevision, cmdutil.DryRunNone)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, result)
+			assert.Equal(t, tc.expectPatch, hasPatchAction(kruiseClient.Actions()))
+		})
+
+		t.Run("advanced daemonset "+tc.name, func(t *testing.T) {
+			ads := &kruiseappsv1alpha1.DaemonSet{
+				ObjectMeta: meta,
+				Spec:       kruiseappsv1alpha1.DaemonSetSpec{Selector: selector, Template: newHistoryTestTemplate()},
+				// the status of an Advanced DaemonSet records the hash of the revision, not its name
+				Status: kruiseappsv1alpha1.DaemonSetStatus{ObservedGeneration: tc.observedGeneration, DaemonSetHash: "3"},
+			}
+			revisions := newHistoryTestRevisions(ads, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("DaemonSet"))
+			client, kruiseClient := fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(ads)
+
+			rollbacker := &AdvancedDaemonSetRollbacker{k: client, kc: kruiseClient}
+			result, err := rollbacker.Rollback(ads, nil, tc.toRevision, cmdutil.DryRunNone)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, result)
+			assert.Equal(t, tc.expectPatch, hasPatchAction(kruiseClient.Actions()))
+		})
+	}
+}
+
+func TestCloneSetRollbackerRetriesOnConflict(t *testing.T) {
+	testCases := []struct {
+		name          string
+		conflicts     int
+		expectPatches int
+		expectErr     string
+	}{
+		{
+			name:          "conflicts twice then succeeds",
+			conflicts:     2,
+			expectPatches: 3,
+		},
+		{
+			name:          "conflicts until retries are exhausted",
+			conflicts:     10,
+			expectPatches: retry.DefaultRetry.Steps,
+			expectErr:     `failed restoring revision 1: the workload kept being modified concurrently, giving up after 5 attempts: Operation cannot be fulfilled on clonesets.apps.kruise.io "demo": the object has been modified`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &kruiseappsv1alpha1.CloneSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid"), ResourceVersion: "7"},
+				Spec: kruiseappsv1alpha1.CloneSetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+					Template: newHistoryTestTemplate(),
+				},
+			}
+			revisions := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+			client, kruiseClient := fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(cs)
+
+			gets, patches := 0, 0
+			kruiseClient.PrependReactor("get", "clonesets", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				gets++
+				return false, nil, nil
+			})
+			kruiseClient.PrependReactor("patch", "clonesets", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				patches++
+				// the patch is only accepted for the resource version it was computed from
+				assert.Contains(t, string(action.(clienttesting.PatchAction).GetPatch()), `"resourceVersion":"7"`)
+				if patches <= tc.conflicts {
+					return true, nil, apierrors.NewConflict(kruiseappsv1alpha1.Resource("clonesets"), "demo", errors.New("the object has been modified"))
+				}
+				return false, nil, nil
+			})
+
+			rollbacker := &CloneSetRollbacker{k: client, kc: kruiseClient}
+			result, err := rollbacker.Rollback(cs, nil, 1, cmdutil.DryRunNone)
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, rollbackSuccess, result)
+			}
+			assert.Equal(t, tc.expectPatches, patches)
+			// the CloneSet is read again before every patch, so that the patch is computed from its latest state
+			assert.Equal(t, tc.expectPatches, gets)
+		})
+	}
+}
+
+func TestCloneSetRollbackerRecordsChangeCause(t *testing.T) {
+	testCases := []struct {
+		name              string
+		annotations       map[string]string
+		expectAnnotations map[string]string
+	}{
+		{
+			name: "without record",
+		},
+		{
+			name:              "with record",
+			annotations:       map[string]string{ChangeCauseAnnotation: "kubectl-kruise rollout undo cloneset/demo --record=true"},
+			expectAnnotations: map[string]string{ChangeCauseAnnotation: "kubectl-kruise rollout undo cloneset/demo --record=true"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &kruiseappsv1alpha1.CloneSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid")},
+				Spec: kruiseappsv1alpha1.CloneSetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+					Template: newHistoryTestTemplate(),
+				},
+			}
+			revisions := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+			client, kruiseClient := fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(cs)
+
+			rollbacker := &CloneSetRollbacker{k: client, kc: kruiseClient}
+			result, err := rollbacker.Rollback(cs, tc.annotations, 1, cmdutil.DryRunNone)
+			assert.NoError(t, err)
+			assert.Equal(t, rollbackSuccess, result)
+
+			// the annotations are changed by the patch restoring the revision
+			var patches int
+			for _, action := range kruiseClient.Actions() {
+				if action.GetVerb() == "patch" {
+					patches++
+				}
+			}
+			assert.Equal(t, 1, patches)
+			rolledBack, err := kruiseClient.AppsV1alpha1().CloneSets("default").Get(context.TODO(), "demo", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectAnnotations, rolledBack.Annotations)
+			assert.Equal(t, "nginx:1.1", rolledBack.Spec.Template.Spec.Containers[0].Image)
+		})
+	}
+}
+
+func TestCloneSetRollbackerPrunedRevision(t *testing.T) {
+	testCases := []struct {
+		name       string
+		toRevision int64
+		expectErr  string
+	}{
+		{
+			name:       "pruned revision",
+			toRevision: 1,
+			expectErr:  "revision 1 is no longer in history, it was pruned according to the revision history limit, the oldest available revision is 2",
+		},
+		{
+			name:       "future revision",
+			toRevision: 9,
+			expectErr:  "unable to find specified revision 9 in history",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &kruiseappsv1alpha1.CloneSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid")},
+				Spec: kruiseappsv1alpha1.CloneSetSpec{
+					Selector:             &metav1.LabelSelector{MatchLabels: historyTestLabels},
+					Template:             newHistoryTestTemplate(),
+					RevisionHistoryLimit: pointer.Int32(2),
+				},
+			}
+			// the controller pruned the first revision, keeping the last two
+			revisions := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))[1:]
+			client, kruiseClient := fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(cs)
+
+			rollbacker := &CloneSetRollbacker{k: client, kc: kruiseClient}
+			_, err := rollbacker.Rollback(cs, nil, tc.toRevision, cmdutil.DryRunNone)
+			assert.EqualError(t, err, tc.expectErr)
+		})
+	}
+}
+
+func TestCloneSetRollbackerTemplateOnly(t *testing.T) {
+	testCases := []struct {
+		name            string
+		templateOnly    bool
+		expectReplicas  int32
+		expectPartition intstr.IntOrString
+	}{
+		{
+			name:            "whole revision",
+			expectReplicas:  3,
+			expectPartition: intstr.FromInt(0),
+		},
+		{
+			name:            "template only",
+			templateOnly:    true,
+			expectReplicas:  5,
+			expectPartition: intstr.FromInt(2),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			partition := intstr.FromInt(2)
+			cs := &kruiseappsv1alpha1.CloneSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid")},
+				Spec: kruiseappsv1alpha1.CloneSetSpec{
+					Replicas:       pointer.Int32(5),
+					Selector:       &metav1.LabelSelector{MatchLabels: historyTestLabels},
+					Template:       newHistoryTestTemplate(),
+					UpdateStrategy: kruiseappsv1alpha1.CloneSetUpdateStrategy{Partition: &partition},
+				},
+			}
+			revisions := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+			// the revision records the replicas and the partition the cloneset had at the time
+			revisions[0].(*appsv1.ControllerRevision).Data.Raw = []byte(`{"spec":{"replicas":3,"updateStrategy":{"partition":0},"template":{"$patch":"replace","spec":{"containers":[{"name":"main","image":"nginx:1.1"}]}}}}`)
+			client, kruiseClient := fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(cs)
+
+			rollbacker := &CloneSetRollbacker{k: client, kc: kruiseClient}
+			rollbacker.SetTemplateOnly(tc.templateOnly)
+			result, err := rollbacker.Rollback(cs, nil, 1, cmdutil.DryRunNone)
+			assert.NoError(t, err)
+			assert.Equal(t, rollbackSuccess, result)
+
+			rolledBack, err := kruiseClient.AppsV1alpha1().CloneSets("default").Get(context.TODO(), "demo", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, "nginx:1.1", rolledBack.Spec.Template.Spec.Containers[0].Image)
+			assert.Equal(t, tc.expectReplicas, *rolledBack.Spec.Replicas)
+			assert.Equal(t, tc.expectPartition, *rolledBack.Spec.UpdateStrategy.Partition)
+		})
+	}
+}
+
+func TestAdvancedDaemonSetRollbackerRollingUpdate(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		restoreStrategy      bool
+		expectMaxUnavailable intstr.IntOrString
+		expectPartition      int32
+	}{
+		{
+			name:                 "live rolling update kept by default",
+			expectMaxUnavailable: intstr.FromInt(1),
+			expectPartition:      4,
+		},
+		{
+			name:                 "rolling update of the revision restored",
+			restoreStrategy:      true,
+			expectMaxUnavailable: intstr.FromString("50%"),
+			expectPartition:      0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			maxUnavailable := intstr.FromInt(1)
+			ads := &kruiseappsv1alpha1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("ads-uid")},
+				Spec: kruiseappsv1alpha1.DaemonSetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+					Template: newHistoryTestTemplate(),
+					UpdateStrategy: kruiseappsv1alpha1.DaemonSetUpdateStrategy{
+						Type:          kruiseappsv1alpha1.RollingUpdateDaemonSetStrategyType,
+						RollingUpdate: &kruiseappsv1alpha1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable, Partition: pointer.Int32(4)},
+					},
+				},
+			}
+			revisions := newHistoryTestRevisions(ads, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("DaemonSet"))
+			// the revision records the rolling update settings the daemonset had at the time
+			revisions[0].(*appsv1.ControllerRevision).Data.Raw = []byte(`{"spec":{"updateStrategy":{"rollingUpdate":{"maxUnavailable":"50%","partition":0}},"template":{"spec":{"containers":[{"name":"main","image":"nginx:1.1"}]}}}}`)
+			client, kruiseClient := fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(ads)
+
+			rollbacker := &AdvancedDaemonSetRollbacker{k: client, kc: kruiseClient}
+			rollbacker.SetRestoreStrategy(tc.restoreStrategy)
+			result, err := rollbacker.Rollback(ads, nil, 1, cmdutil.DryRunNone)
+			assert.NoError(t, err)
+			assert.Equal(t, rollbackSuccess, result)
+
+			rolledBack, err := kruiseClient.AppsV1alpha1().DaemonSets("default").Get(context.TODO(), "demo", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, "nginx:1.1", rolledBack.Spec.Template.Spec.Containers[0].Image)
+			assert.Equal(t, kruiseappsv1alpha1.RollingUpdateDaemonSetStrategyType, rolledBack.Spec.UpdateStrategy.Type)
+			assert.Equal(t, tc.expectMaxUnavailable, *rolledBack.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable)
+			assert.Equal(t, tc.expectPartition, *rolledBack.Spec.UpdateStrategy.RollingUpdate.Partition)
+		})
+	}
+}
+
+func TestAdvancedStatefulSetRollbackerPartition(t *testing.T) {
+	testCases := []struct {
+		name            string
+		restoreStrategy bool
+		expectPartition int32
+	}{
+		{
+			name:            "live partition kept by default",
+			expectPartition: 3,
+		},
+		{
+			name:            "partition of the revision restored",
+			restoreStrategy: true,
+			expectPartition: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asts := &kruiseappsv1beta1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("asts-uid")},
+				Spec: kruiseappsv1beta1.StatefulSetSpec{
+					Replicas: pointer.Int32(5),
+					Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+					Template: newHistoryTestTemplate(),
+					UpdateStrategy: kruiseappsv1beta1.StatefulSetUpdateStrategy{
+						Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+						RollingUpdate: &kruiseappsv1beta1.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32(3)},
+					},
+				},
+			}
+			revisions := newHistoryTestRevisions(asts, kruiseappsv1beta1.SchemeGroupVersion.WithKind("StatefulSet"))
+			// the revision records the partition the statefulset had at the time, which would update all pods at once
+			revisions[0].(*appsv1.ControllerRevision).Data.Raw = []byte(`{"spec":{"updateStrategy":{"rollingUpdate":{"partition":0}},"template":{"spec":{"containers":[{"name":"main","image":"nginx:1.1"}]}}}}`)
+			client, kruiseClient := fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(asts)
+
+			rollbacker := &AdvancedStatefulSetRollbacker{k: client, kc: kruiseClient}
+			rollbacker.SetRestoreStrategy(tc.restoreStrategy)
+			result, err := rollbacker.Rollback(asts, nil, 1, cmdutil.DryRunNone)
+			assert.NoError(t, err)
+			assert.Equal(t, rollbackSuccess, result)
+
+			rolledBack, err := kruiseClient.AppsV1beta1().StatefulSets("default").Get(context.TODO(), "demo", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, "nginx:1.1", rolledBack.Spec.Template.Spec.Containers[0].Image)
+			assert.Equal(t, appsv1.RollingUpdateStatefulSetStrategyType, rolledBack.Spec.UpdateStrategy.Type)
+			assert.Equal(t, tc.expectPartition, *rolledBack.Spec.UpdateStrategy.RollingUpdate.Partition)
+		})
+	}
+}
+
+func TestDaemonSetRollbackerFieldManager(t *testing.T) {
+	ds := &appsv1.DaemonSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("ds-uid")},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "nginx:1.3"}}},
+			},
+		},
+	}
+	revisions := &appsv1.ControllerRevisionList{}
+	for _, revision := range newHistoryTestRevisions(ds, appsv1.SchemeGroupVersion.WithKind("DaemonSet")) {
+		revisions.Items = append(revisions.Items, *revision.(*appsv1.ControllerRevision))
+	}
+	codec := scheme.Codecs.LegacyCodec(appsv1.SchemeGroupVersion)
+
+	testCases := []struct {
+		name               string
+		fieldManager       string
+		dryRunStrategy     cmdutil.DryRunStrategy
+		expectFieldManager string
+		expectDryRun       string
+	}{
+		{
+			name: "no field manager",
+		},
+		{
+			name:               "configured field manager",
+			fieldManager:       "kubectl-kruise-rollout",
+			expectFieldManager: "kubectl-kruise-rollout",
+		},
+		{
+			name:               "configured field manager with server dry-run",
+			fieldManager:       "team-a",
+			dryRunStrategy:     cmdutil.DryRunServer,
+			expectFieldManager: "team-a",
+			expectDryRun:       metav1.DryRunAll,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var patchQuery url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				var obj runtime.Object
+				switch p, m := req.URL.Path, req.Method; {
+				case p == "/apis/apps/v1/namespaces/default/daemonsets/demo" && m == http.MethodGet:
+					obj = ds
+				case p == "/apis/apps/v1/namespaces/default/controllerrevisions" && m == http.MethodGet:
+					obj = revisions
+				case p == "/apis/apps/v1/namespaces/default/daemonsets/demo" && m == http.MethodPatch:
+					patchQuery = req.URL.Query()
+					obj = ds
+				default:
+					t.Errorf("unexpected request: %s %s", m, p)
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Header().Set("Content-Type", runtime.ContentTypeJSON)
+				assert.NoError(t, codec.Encode(obj, w))
+			}))
+			defer server.Close()
+
+			client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+			assert.NoError(t, err)
+
+			rollbacker := &DaemonSetRollbacker{c: client}
+			rollbacker.SetFieldManager(tc.fieldManager)
+			result, err := rollbacker.Rollback(ds, nil, 1, tc.dryRunStrategy)
+			assert.NoError(t, err)
+			assert.Equal(t, rollbackSuccess, result)
+			assert.Equal(t, tc.expectFieldManager, patchQuery.Get("fieldManager"))
+			assert.Equal(t, tc.expectDryRun, patchQuery.Get("dryRun"))
+		})
+	}
+}
+
+func hasPatchAction(actions []clienttesting.Action) bool {
+	for _, action := range actions {
+		if action.GetVerb() == "patch" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCloneSetRollbackerLogsPatch(t *testing.T) {
+	testCases := []struct {
+		name        string
+		verbosity   string
+		expectPatch bool
+	}{
+		{
+			name:      "v=4",
+			verbosity: "4",
+		},
+		{
+			name:        "v=6",
+			verbosity:   "6",
+			expectPatch: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			flags := flag.NewFlagSet("klog", flag.ContinueOnError)
+			klog.InitFlags(flags)
+			assert.NoError(t, flags.Set("logtostderr", "false"))
+			assert.NoError(t, flags.Set("v", tc.verbosity))
+			klog.SetOutput(&logs)
+			defer func() {
+				assert.NoError(t, flags.Set("v", "0"))
+				assert.NoError(t, flags.Set("logtostderr", "true"))
+				klog.SetOutput(nil)
+			}()
+
+			cs := &kruiseappsv1alpha1.CloneSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid")},
+				Spec: kruiseappsv1alpha1.CloneSetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+					Template: newHistoryTestTemplate(),
+				},
+			}
+			revisions := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+			client, kruiseClient := fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(cs)
+
+			rollbacker := &CloneSetRollbacker{k: client, kc: kruiseClient}
+			_, err := rollbacker.Rollback(cs, nil, 1, cmdutil.DryRunNone)
+			assert.NoError(t, err)
+			klog.Flush()
+
+			assert.Contains(t, logs.String(), "Restoring revision 1 of CloneSet default/demo with a application/merge-patch+json patch")
+			if tc.expectPatch {
+				assert.Contains(t, logs.String(), `Patch restoring revision 1 of CloneSet default/demo: {"spec":{"template":`)
+			} else {
+				assert.NotContains(t, logs.String(), "nginx:1.1")
+			}
+		})
+	}
+}
+
+func TestCloneSetRollbackerRollbackRevisions(t *testing.T) {
+	cs := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid")},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+			Template: newHistoryTestTemplate(),
+		},
+	}
+	revisions := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+	rollbacker := &CloneSetRollbacker{k: fake.NewSimpleClientset(revisions...), kc: kruisefake.NewSimpleClientset(cs)}
+
+	from, to, err := rollbacker.RollbackRevisions(cs, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, []int64{from, to})
+
+	from, to, err = rollbacker.RollbackRevisions(cs, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 1}, []int64{from, to})
+
+	_, _, err = rollbacker.RollbackRevisions(cs, 9)
+	assert.EqualError(t, err, "unable to find specified revision 9 in history")
+}
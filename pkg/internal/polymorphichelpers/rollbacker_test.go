@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestRollbackerImpersonation(t *testing.T) {
+	cs := &kruiseappsv1alpha1.CloneSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kruiseappsv1alpha1.SchemeGroupVersion.String(), Kind: "CloneSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid")},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+			Template: newHistoryTestTemplate(),
+		},
+	}
+	revisions := &appsv1.ControllerRevisionList{}
+	for _, revision := range newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet")) {
+		revisions.Items = append(revisions.Items, *revision.(*appsv1.ControllerRevision))
+	}
+	codec := scheme.Codecs.LegacyCodec(appsv1.SchemeGroupVersion)
+
+	// impersonated records the identity every request was sent as, by method
+	var mu sync.Mutex
+	impersonated := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		impersonated[req.Method] = append(impersonated[req.Method], req.Header.Get("Impersonate-User")+" "+req.Header.Get("Impersonate-Group"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", runtime.ContentTypeJSON)
+		switch p, m := req.URL.Path, req.Method; {
+		case p == "/apis/apps.kruise.io/v1alpha1/namespaces/default/clonesets/demo" && (m == http.MethodGet || m == http.MethodPatch):
+			assert.NoError(t, json.NewEncoder(w).Encode(cs))
+		case p == "/apis/apps/v1/namespaces/default/controllerrevisions" && m == http.MethodGet:
+			assert.NoError(t, codec.Encode(revisions, w))
+		default:
+			t.Errorf("unexpected request: %s %s", m, p)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	flags := genericclioptions.NewConfigFlags(false)
+	// an empty kubeconfig, so that only the flags configure the client
+	kubeConfig := filepath.Join(t.TempDir(), "config")
+	assert.NoError(t, os.WriteFile(kubeConfig, nil, 0600))
+	flags.KubeConfig = &kubeConfig
+	flags.APIServer = &server.URL
+	*flags.Impersonate = "alice"
+	*flags.ImpersonateGroup = []string{"devs"}
+
+	rollbacker, err := rollbacker(flags, &meta.RESTMapping{GroupVersionKind: kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := rollbacker.Rollback(cs, nil, 1, cmdutil.DryRunNone)
+	assert.NoError(t, err)
+	assert.Equal(t, rollbackSuccess, result)
+
+	// the patch is sent as the impersonated user, not only the reads of the history
+	assert.Equal(t, []string{"alice devs"}, impersonated[http.MethodPatch])
+	assert.NotEmpty(t, impersonated[http.MethodGet])
+	for _, identity := range impersonated[http.MethodGet] {
+		assert.Equal(t, "alice devs", identity)
+	}
+}
@@ -22,6 +22,8 @@ import (
 
 	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
 	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	kruiserolloutsv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	kruiserolloutsv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
 
 	appsv1 "k8s.io/api/apps/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
@@ -53,6 +55,12 @@ func StatusViewerFor(kind schema.GroupKind) (StatusViewer, error) {
 
 	case kruiseappsv1beta1.SchemeGroupVersion.WithKind("StatefulSet").GroupKind():
 		return &AdvancedStatefulSetStatusViewer{}, nil
+
+	case kruiseappsv1alpha1.SchemeGroupVersion.WithKind("DaemonSet").GroupKind():
+		return &AdvancedDaemonSetStatusViewer{}, nil
+
+	case kruiserolloutsv1beta1.GroupVersion.WithKind("Rollout").GroupKind():
+		return &RolloutStatusViewer{}, nil
 	}
 	return nil, fmt.Errorf("no status viewer has been implemented for %v", kind)
 }
@@ -72,6 +80,12 @@ type CloneSetStatusViewer struct{}
 // AdvancedStatefulSetStatusViewer  implements the StatusViewer interface
 type AdvancedStatefulSetStatusViewer struct{}
 
+// AdvancedDaemonSetStatusViewer implements the StatusViewer interface
+type AdvancedDaemonSetStatusViewer struct{}
+
+// RolloutStatusViewer implements the StatusViewer interface for the Kruise Rollout CRD
+type RolloutStatusViewer struct{}
+
 // Status returns a message describing deployment status, and a bool value indicating if the status is considered done.
 func (s *DeploymentStatusViewer) Status(c kubernetes.Interface, obj runtime.Unstructured, revision int64) (string, bool, error) {
 	deployment := &appsv1.Deployment{}
@@ -289,6 +303,10 @@ func (s *CloneSetStatusViewer) Status(c kubernetes.Interface, obj runtime.Unstru
 	if cs.Status.ObservedGeneration == 0 || cs.Generation > cs.Status.ObservedGeneration {
 		return "Waiting for CloneSet spec update to be observed...\n", false, nil
 	}
+	if cs.Spec.Replicas != nil && cs.Status.UpdatedReplicas < *cs.Spec.Replicas-int32(partition) {
+		return fmt.Sprintf("Waiting for CloneSet %q rollout to finish: %s...\n", cs.Name,
+			replicaProgress(cs.Status.UpdatedReplicas, *cs.Spec.Replicas-int32(partition), cs.Status.AvailableReplicas)), false, nil
+	}
 	if cs.Spec.Replicas != nil && cs.Status.ReadyReplicas < *cs.Spec.Replicas {
 		return fmt.Sprintf("Waiting for %d pods to be ready...\n", *cs.Spec.Replicas-cs.Status.ReadyReplicas), false, nil
 	}
@@ -325,6 +343,11 @@ func (s *CloneSetStatusViewer) DetailStatus(c kubernetes.Interface, obj runtime.
 	if cs.Status.ObservedGeneration == 0 || cs.Generation > cs.Status.ObservedGeneration {
 		return fmt.Sprintf("Waiting for CloneSet %s spec update to be observed...\n", cs.Name), false, nil
 	}
+	if cs.Spec.Replicas != nil && cs.Status.UpdatedReplicas < *cs.Spec.Replicas-int32(partition) {
+		return fmt.Sprintf("Waiting for CloneSet %q rollout to finish: %s...\n%s", cs.Name,
+			replicaProgress(cs.Status.UpdatedReplicas, *cs.Spec.Replicas-int32(partition), cs.Status.AvailableReplicas),
+			generatePodsInfoForCloneSet(c, cs)), false, nil
+	}
 	if cs.Spec.Replicas != nil && cs.Status.ReadyReplicas < *cs.Spec.Replicas {
 		return fmt.Sprintf("Waiting for %d pods to be ready...\n%s", *cs.Spec.Replicas-cs.Status.ReadyReplicas,
 			generatePodsInfoForCloneSet(c, cs)), false, nil
@@ -344,7 +367,7 @@ func (s *AdvancedStatefulSetStatusViewer) Status(c kubernetes.Interface, obj run
 
 	// check InPlaceOnly and InPlacePossible UpdateStrategy
 	if asts.Spec.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType {
-		if asts.Spec.Replicas != nil && asts.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		if asts.Spec.Replicas != nil && asts.Spec.UpdateStrategy.RollingUpdate != nil && asts.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
 			if asts.Status.UpdatedReplicas < (*asts.Spec.Replicas - *asts.Spec.UpdateStrategy.RollingUpdate.Partition) {
 				return fmt.Sprintf("Waiting for partitioned roll out to finish:%d out of %d new pods has been updated...\n",
 					asts.Status.UpdatedReplicas, *asts.Spec.Replicas-*asts.Spec.UpdateStrategy.RollingUpdate.Partition), false, nil
@@ -359,6 +382,10 @@ func (s *AdvancedStatefulSetStatusViewer) Status(c kubernetes.Interface, obj run
 	if asts.Spec.Replicas != nil && asts.Status.ReadyReplicas < *asts.Spec.Replicas {
 		return fmt.Sprintf("Waiting for %d pods to be ready...\n", *asts.Spec.Replicas-asts.Status.ReadyReplicas), false, nil
 	}
+	if asts.Spec.Replicas != nil && asts.Status.UpdatedReplicas < *asts.Spec.Replicas-advancedStatefulSetPartition(asts) {
+		return fmt.Sprintf("Waiting for Advanced StatefulSet %q rollout to finish: %s...\n", asts.Name,
+			replicaProgress(asts.Status.UpdatedReplicas, *asts.Spec.Replicas-advancedStatefulSetPartition(asts), asts.Status.AvailableReplicas)), false, nil
+	}
 	return fmt.Sprintf("Advanced StatefulSet rolling update complete %d pods at revision %s...\n", asts.Status.AvailableReplicas, asts.Status.UpdateRevision), true, nil
 }
 
@@ -372,7 +399,7 @@ func (s *AdvancedStatefulSetStatusViewer) DetailStatus(c kubernetes.Interface, o
 
 	// check InPlaceOnly and InPlacePossible UpdateStrategy
 	if asts.Spec.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType {
-		if asts.Spec.Replicas != nil && asts.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		if asts.Spec.Replicas != nil && asts.Spec.UpdateStrategy.RollingUpdate != nil && asts.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
 			if asts.Status.UpdatedReplicas < (*asts.Spec.Replicas - *asts.Spec.UpdateStrategy.RollingUpdate.Partition) {
 				return fmt.Sprintf("Waiting for partitioned roll out to finish:%d out of %d new pods has been updated...\n",
 					asts.Status.UpdatedReplicas, *asts.Spec.Replicas-*asts.Spec.UpdateStrategy.RollingUpdate.Partition), false, nil
@@ -387,5 +414,192 @@ func (s *AdvancedStatefulSetStatusViewer) DetailStatus(c kubernetes.Interface, o
 	if asts.Spec.Replicas != nil && asts.Status.ReadyReplicas < *asts.Spec.Replicas {
 		return fmt.Sprintf("Waiting for %d pods to be ready...\n", *asts.Spec.Replicas-asts.Status.ReadyReplicas), false, nil
 	}
+	if asts.Spec.Replicas != nil && asts.Status.UpdatedReplicas < *asts.Spec.Replicas-advancedStatefulSetPartition(asts) {
+		return fmt.Sprintf("Waiting for Advanced StatefulSet %q rollout to finish: %s...\n", asts.Name,
+			replicaProgress(asts.Status.UpdatedReplicas, *asts.Spec.Replicas-advancedStatefulSetPartition(asts), asts.Status.AvailableReplicas)), false, nil
+	}
 	return fmt.Sprintf("Advanced StatefulSet rolling update complete %d pods at revision %s...\n", asts.Status.AvailableReplicas, asts.Status.UpdateRevision), true, nil
 }
+
+func advancedStatefulSetPartition(asts *kruiseappsv1beta1.StatefulSet) int32 {
+	if asts.Spec.UpdateStrategy.RollingUpdate == nil || asts.Spec.UpdateStrategy.RollingUpdate.Partition == nil {
+		return 0
+	}
+	return *asts.Spec.UpdateStrategy.RollingUpdate.Partition
+}
+
+// Status returns a message describing advanced daemonset status, and a bool value indicating if the status is considered done.
+func (s *AdvancedDaemonSetStatusViewer) Status(c kubernetes.Interface, obj runtime.Unstructured, revision int64) (string, bool, error) {
+	return s.DetailStatus(c, obj, false, revision)
+}
+
+// DetailStatus returns a message describing advanced daemonset status, and a bool value indicating if the status is considered done.
+// The nodes kept at the old revision by the partition of the rolling update are not waited for.
+func (s *AdvancedDaemonSetStatusViewer) DetailStatus(c kubernetes.Interface, obj runtime.Unstructured, detail bool, revision int64) (string, bool, error) {
+	ads := &kruiseappsv1alpha1.DaemonSet{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), ads)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to convert %T to %T: %v", obj, ads, err)
+	}
+
+	if ads.Spec.UpdateStrategy.Type != kruiseappsv1alpha1.RollingUpdateDaemonSetStrategyType {
+		return "", true, fmt.Errorf("rollout status is only available for %s strategy type", kruiseappsv1alpha1.RollingUpdateDaemonSetStrategyType)
+	}
+	if ads.Status.ObservedGeneration == 0 || ads.Generation > ads.Status.ObservedGeneration {
+		return "Waiting for Advanced DaemonSet spec update to be observed...\n", false, nil
+	}
+	desired := ads.Status.DesiredNumberScheduled
+	if rollingUpdate := ads.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		desired -= *rollingUpdate.Partition
+	}
+	if ads.Status.UpdatedNumberScheduled < desired {
+		return fmt.Sprintf("Waiting for Advanced DaemonSet %q rollout to finish: %s...\n", ads.Name,
+			replicaProgress(ads.Status.UpdatedNumberScheduled, desired, ads.Status.NumberAvailable)), false, nil
+	}
+	if ads.Status.NumberAvailable < ads.Status.DesiredNumberScheduled {
+		return fmt.Sprintf("Waiting for Advanced DaemonSet %q rollout to finish: %d of %d pods are available...\n",
+			ads.Name, ads.Status.NumberAvailable, ads.Status.DesiredNumberScheduled), false, nil
+	}
+	return fmt.Sprintf("Advanced DaemonSet %q successfully rolled out\n", ads.Name), true, nil
+}
+
+// replicaProgress describes how far the pods of a workload have been updated, e.g. "3 of 5 updated, 4 available".
+func replicaProgress(updated, desired, available int32) string {
+	return fmt.Sprintf("%d of %d updated, %d available", updated, desired, available)
+}
+
+// rolloutProgress is the version independent view of a Kruise Rollout used to report its status.
+type rolloutProgress struct {
+	name               string
+	generation         int64
+	observedGeneration int64
+	phase              string
+	message            string
+	totalSteps         int32
+	// the following fields are only set while a canary release is in progress
+	canary              bool
+	stepIndex           int32
+	stepState           string
+	stepWeight          string
+	canaryReplicas      int32
+	canaryReadyReplicas int32
+}
+
+func getRolloutProgress(obj runtime.Unstructured) (*rolloutProgress, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	switch gvk.Version {
+	case kruiserolloutsv1alpha1.GroupVersion.Version:
+		rollout := &kruiserolloutsv1alpha1.Rollout{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), rollout); err != nil {
+			return nil, fmt.Errorf("failed to convert %T to %T: %v", obj, rollout, err)
+		}
+		p := &rolloutProgress{
+			name:               rollout.Name,
+			generation:         rollout.Generation,
+			observedGeneration: rollout.Status.ObservedGeneration,
+			phase:              string(rollout.Status.Phase),
+			message:            rollout.Status.Message,
+		}
+		var steps []kruiserolloutsv1alpha1.CanaryStep
+		if rollout.Spec.Strategy.Canary != nil {
+			steps = rollout.Spec.Strategy.Canary.Steps
+		}
+		p.totalSteps = int32(len(steps))
+		if cs := rollout.Status.CanaryStatus; cs != nil {
+			p.canary = true
+			p.stepIndex = cs.CurrentStepIndex
+			p.stepState = string(cs.CurrentStepState)
+			p.canaryReplicas = cs.CanaryReplicas
+			p.canaryReadyReplicas = cs.CanaryReadyReplicas
+			if cs.CurrentStepIndex > 0 && int(cs.CurrentStepIndex) <= len(steps) {
+				step := steps[cs.CurrentStepIndex-1]
+				if step.Weight != nil {
+					p.stepWeight = fmt.Sprintf("%d%%", *step.Weight)
+				} else if step.Replicas != nil {
+					p.stepWeight = step.Replicas.String()
+				}
+			}
+		}
+		return p, nil
+	case kruiserolloutsv1beta1.GroupVersion.Version:
+		rollout := &kruiserolloutsv1beta1.Rollout{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), rollout); err != nil {
+			return nil, fmt.Errorf("failed to convert %T to %T: %v", obj, rollout, err)
+		}
+		p := &rolloutProgress{
+			name:               rollout.Name,
+			generation:         rollout.Generation,
+			observedGeneration: rollout.Status.ObservedGeneration,
+			phase:              string(rollout.Status.Phase),
+			message:            rollout.Status.Message,
+		}
+		var steps []kruiserolloutsv1beta1.CanaryStep
+		if rollout.Spec.Strategy.Canary != nil {
+			steps = rollout.Spec.Strategy.Canary.Steps
+		}
+		p.totalSteps = int32(len(steps))
+		if cs := rollout.Status.CanaryStatus; cs != nil {
+			p.canary = true
+			p.stepIndex = cs.CurrentStepIndex
+			p.stepState = string(cs.CurrentStepState)
+			p.canaryReplicas = cs.CanaryReplicas
+			p.canaryReadyReplicas = cs.CanaryReadyReplicas
+			if cs.CurrentStepIndex > 0 && int(cs.CurrentStepIndex) <= len(steps) {
+				step := steps[cs.CurrentStepIndex-1]
+				if step.Traffic != nil {
+					p.stepWeight = *step.Traffic
+				} else if step.Replicas != nil {
+					p.stepWeight = step.Replicas.String()
+				}
+			}
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("unsupported version of Rollout: %s", gvk.GroupVersion())
+}
+
+// Status returns a message describing rollout status, and a bool value indicating if the status is considered done.
+func (s *RolloutStatusViewer) Status(c kubernetes.Interface, obj runtime.Unstructured, revision int64) (string, bool, error) {
+	return s.DetailStatus(c, obj, false, revision)
+}
+
+// DetailStatus returns a message describing rollout status, and a bool value indicating if the status is considered done.
+// The revision is ignored since a Rollout does not keep revisions itself.
+func (s *RolloutStatusViewer) DetailStatus(c kubernetes.Interface, obj runtime.Unstructured, detail bool, revision int64) (string, bool, error) {
+	p, err := getRolloutProgress(obj)
+	if err != nil {
+		return "", false, err
+	}
+
+	if p.observedGeneration == 0 || p.generation > p.observedGeneration {
+		return "Waiting for rollout spec update to be observed...\n", false, nil
+	}
+	switch p.phase {
+	case string(kruiserolloutsv1beta1.RolloutPhaseDisabled), string(kruiserolloutsv1beta1.RolloutPhaseDisabling),
+		string(kruiserolloutsv1beta1.RolloutPhaseTerminating):
+		return "", false, fmt.Errorf("rollout %q is %s", p.name, p.phase)
+	}
+	if p.canary && p.stepState == string(kruiserolloutsv1beta1.CanaryStepStateCompleted) {
+		return fmt.Sprintf("rollout %q successfully completed\n", p.name), true, nil
+	}
+	if !p.canary {
+		if p.phase == string(kruiserolloutsv1beta1.RolloutPhaseHealthy) {
+			return fmt.Sprintf("rollout %q is healthy, no canary release in progress\n", p.name), true, nil
+		}
+		return fmt.Sprintf("Waiting for rollout %q to start: phase %s...\n", p.name, p.phase), false, nil
+	}
+
+	weight := p.stepWeight
+	if len(weight) == 0 {
+		weight = "<none>"
+	}
+	status := fmt.Sprintf("Waiting for rollout %q to finish: step %d of %d (weight %s) in state %s, phase %s",
+		p.name, p.stepIndex, p.totalSteps, weight, p.stepState, p.phase)
+	if detail {
+		status += fmt.Sprintf(", %d of %d canary replicas are ready", p.canaryReadyReplicas, p.canaryReplicas)
+		if len(p.message) > 0 {
+			status += fmt.Sprintf(": %s", p.message)
+		}
+	}
+	return status + "...\n", false, nil
+}
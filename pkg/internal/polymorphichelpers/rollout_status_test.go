@@ -0,0 +1,308 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	kruiserolloutsv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	kruiserolloutsv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func TestRolloutStatusViewer(t *testing.T) {
+	newRollout := func(generation int64, status kruiserolloutsv1beta1.RolloutStatus) *kruiserolloutsv1beta1.Rollout {
+		return &kruiserolloutsv1beta1.Rollout{
+			TypeMeta:   metav1.TypeMeta{APIVersion: kruiserolloutsv1beta1.GroupVersion.String(), Kind: "Rollout"},
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", Generation: generation},
+			Spec: kruiserolloutsv1beta1.RolloutSpec{
+				Strategy: kruiserolloutsv1beta1.RolloutStrategy{
+					Canary: &kruiserolloutsv1beta1.CanaryStrategy{
+						Steps: []kruiserolloutsv1beta1.CanaryStep{
+							{TrafficRoutingStrategy: kruiserolloutsv1beta1.TrafficRoutingStrategy{Traffic: pointer.String("5%")}},
+							{TrafficRoutingStrategy: kruiserolloutsv1beta1.TrafficRoutingStrategy{Traffic: pointer.String("50%")}},
+							{TrafficRoutingStrategy: kruiserolloutsv1beta1.TrafficRoutingStrategy{Traffic: pointer.String("100%")}},
+						},
+					},
+				},
+			},
+			Status: status,
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		rollout      *kruiserolloutsv1beta1.Rollout
+		detail       bool
+		expectStatus string
+		expectDone   bool
+		expectErr    string
+	}{
+		{
+			name:         "spec update not observed",
+			rollout:      newRollout(2, kruiserolloutsv1beta1.RolloutStatus{ObservedGeneration: 1}),
+			expectStatus: "Waiting for rollout spec update to be observed...\n",
+		},
+		{
+			name: "canary in progress",
+			rollout: newRollout(1, kruiserolloutsv1beta1.RolloutStatus{
+				ObservedGeneration: 1,
+				Phase:              kruiserolloutsv1beta1.RolloutPhaseProgressing,
+				CanaryStatus: &kruiserolloutsv1beta1.CanaryStatus{
+					CurrentStepIndex: 2,
+					CurrentStepState: kruiserolloutsv1beta1.CanaryStepStateUpgrade,
+				},
+			}),
+			expectStatus: "Waiting for rollout \"demo\" to finish: step 2 of 3 (weight 50%) in state StepUpgrade, phase Progressing...\n",
+		},
+		{
+			name:   "canary in progress with detail",
+			detail: true,
+			rollout: newRollout(1, kruiserolloutsv1beta1.RolloutStatus{
+				ObservedGeneration: 1,
+				Phase:              kruiserolloutsv1beta1.RolloutPhaseProgressing,
+				Message:            "Rollout is in step(2/3), and upgrade workload to new version",
+				CanaryStatus: &kruiserolloutsv1beta1.CanaryStatus{
+					CurrentStepIndex:    2,
+					CurrentStepState:    kruiserolloutsv1beta1.CanaryStepStateUpgrade,
+					CanaryReplicas:      5,
+					CanaryReadyReplicas: 3,
+				},
+			}),
+			expectStatus: "Waiting for rollout \"demo\" to finish: step 2 of 3 (weight 50%) in state StepUpgrade, phase Progressing, " +
+				"3 of 5 canary replicas are ready: Rollout is in step(2/3), and upgrade workload to new version...\n",
+		},
+		{
+			name: "canary completed",
+			rollout: newRollout(1, kruiserolloutsv1beta1.RolloutStatus{
+				ObservedGeneration: 1,
+				Phase:              kruiserolloutsv1beta1.RolloutPhaseProgressing,
+				CanaryStatus: &kruiserolloutsv1beta1.CanaryStatus{
+					CurrentStepIndex: 3,
+					CurrentStepState: kruiserolloutsv1beta1.CanaryStepStateCompleted,
+				},
+			}),
+			expectStatus: "rollout \"demo\" successfully completed\n",
+			expectDone:   true,
+		},
+		{
+			name: "healthy without canary",
+			rollout: newRollout(1, kruiserolloutsv1beta1.RolloutStatus{
+				ObservedGeneration: 1,
+				Phase:              kruiserolloutsv1beta1.RolloutPhaseHealthy,
+			}),
+			expectStatus: "rollout \"demo\" is healthy, no canary release in progress\n",
+			expectDone:   true,
+		},
+		{
+			name: "disabled",
+			rollout: newRollout(1, kruiserolloutsv1beta1.RolloutStatus{
+				ObservedGeneration: 1,
+				Phase:              kruiserolloutsv1beta1.RolloutPhaseDisabled,
+			}),
+			expectErr: "rollout \"demo\" is Disabled",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tc.rollout)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			status, done, err := (&RolloutStatusViewer{}).DetailStatus(nil, &unstructured.Unstructured{Object: content}, tc.detail, 0)
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectStatus, status)
+			assert.Equal(t, tc.expectDone, done)
+		})
+	}
+}
+
+func TestStatusViewerFor(t *testing.T) {
+	testCases := []struct {
+		gvk          schema.GroupVersionKind
+		expectViewer StatusViewer
+	}{
+		{gvk: kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"), expectViewer: &CloneSetStatusViewer{}},
+		{gvk: kruiseappsv1beta1.SchemeGroupVersion.WithKind("StatefulSet"), expectViewer: &AdvancedStatefulSetStatusViewer{}},
+		{gvk: kruiseappsv1alpha1.SchemeGroupVersion.WithKind("DaemonSet"), expectViewer: &AdvancedDaemonSetStatusViewer{}},
+		{gvk: kruiserolloutsv1alpha1.GroupVersion.WithKind("Rollout"), expectViewer: &RolloutStatusViewer{}},
+		{gvk: kruiserolloutsv1beta1.GroupVersion.WithKind("Rollout"), expectViewer: &RolloutStatusViewer{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.gvk.String(), func(t *testing.T) {
+			viewer, err := StatusViewerFor(tc.gvk.GroupKind())
+			assert.NoError(t, err)
+			assert.IsType(t, tc.expectViewer, viewer)
+		})
+	}
+}
+
+func TestWorkloadStatusViewers(t *testing.T) {
+	objectMeta := metav1.ObjectMeta{Name: "demo", Namespace: "default", Generation: 2}
+
+	newCloneSet := func(status kruiseappsv1alpha1.CloneSetStatus) *kruiseappsv1alpha1.CloneSet {
+		return &kruiseappsv1alpha1.CloneSet{
+			ObjectMeta: objectMeta,
+			Spec: kruiseappsv1alpha1.CloneSetSpec{
+				Replicas:       pointer.Int32(5),
+				UpdateStrategy: kruiseappsv1alpha1.CloneSetUpdateStrategy{Type: kruiseappsv1alpha1.RecreateCloneSetUpdateStrategyType},
+			},
+			Status: status,
+		}
+	}
+	newAdvancedStatefulSet := func(status kruiseappsv1beta1.StatefulSetStatus) *kruiseappsv1beta1.StatefulSet {
+		return &kruiseappsv1beta1.StatefulSet{
+			ObjectMeta: objectMeta,
+			Spec: kruiseappsv1beta1.StatefulSetSpec{
+				Replicas:       pointer.Int32(5),
+				UpdateStrategy: kruiseappsv1beta1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			},
+			Status: status,
+		}
+	}
+	newAdvancedDaemonSet := func(partition *int32, status kruiseappsv1alpha1.DaemonSetStatus) *kruiseappsv1alpha1.DaemonSet {
+		return &kruiseappsv1alpha1.DaemonSet{
+			ObjectMeta: objectMeta,
+			Spec: kruiseappsv1alpha1.DaemonSetSpec{
+				UpdateStrategy: kruiseappsv1alpha1.DaemonSetUpdateStrategy{
+					Type:          kruiseappsv1alpha1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &kruiseappsv1alpha1.RollingUpdateDaemonSet{Partition: partition},
+				},
+			},
+			Status: status,
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		viewer       StatusViewer
+		obj          runtime.Object
+		expectStatus string
+		expectDone   bool
+	}{
+		{
+			name:   "cloneset partially updated",
+			viewer: &CloneSetStatusViewer{},
+			obj: newCloneSet(kruiseappsv1alpha1.CloneSetStatus{
+				ObservedGeneration: 2, UpdatedReplicas: 3, ReadyReplicas: 5, AvailableReplicas: 4,
+			}),
+			expectStatus: "Waiting for CloneSet \"demo\" rollout to finish: 3 of 5 updated, 4 available...\n",
+		},
+		{
+			name:   "cloneset partitioned update complete",
+			viewer: &CloneSetStatusViewer{},
+			obj: func() runtime.Object {
+				cs := newCloneSet(kruiseappsv1alpha1.CloneSetStatus{
+					ObservedGeneration: 2, UpdatedReplicas: 3, ReadyReplicas: 5, AvailableReplicas: 5, UpdateRevision: "demo-2",
+				})
+				cs.Spec.UpdateStrategy.Partition = intOrStrPtr(intstr.FromInt(2))
+				return cs
+			}(),
+			expectStatus: "CloneSet rolling update complete 5 pods at revision demo-2...\n",
+			expectDone:   true,
+		},
+		{
+			name:   "cloneset complete",
+			viewer: &CloneSetStatusViewer{},
+			obj: newCloneSet(kruiseappsv1alpha1.CloneSetStatus{
+				ObservedGeneration: 2, UpdatedReplicas: 5, ReadyReplicas: 5, AvailableReplicas: 5, UpdateRevision: "demo-2",
+			}),
+			expectStatus: "CloneSet rolling update complete 5 pods at revision demo-2...\n",
+			expectDone:   true,
+		},
+		{
+			name:   "advanced statefulset partially updated",
+			viewer: &AdvancedStatefulSetStatusViewer{},
+			obj: newAdvancedStatefulSet(kruiseappsv1beta1.StatefulSetStatus{
+				ObservedGeneration: 2, UpdatedReplicas: 2, ReadyReplicas: 5, AvailableReplicas: 5,
+			}),
+			expectStatus: "Waiting for Advanced StatefulSet \"demo\" rollout to finish: 2 of 5 updated, 5 available...\n",
+		},
+		{
+			name:   "advanced statefulset complete",
+			viewer: &AdvancedStatefulSetStatusViewer{},
+			obj: newAdvancedStatefulSet(kruiseappsv1beta1.StatefulSetStatus{
+				ObservedGeneration: 2, UpdatedReplicas: 5, ReadyReplicas: 5, AvailableReplicas: 5, UpdateRevision: "demo-2",
+			}),
+			expectStatus: "Advanced StatefulSet rolling update complete 5 pods at revision demo-2...\n",
+			expectDone:   true,
+		},
+		{
+			name:         "advanced daemonset spec update not observed",
+			viewer:       &AdvancedDaemonSetStatusViewer{},
+			obj:          newAdvancedDaemonSet(nil, kruiseappsv1alpha1.DaemonSetStatus{ObservedGeneration: 1}),
+			expectStatus: "Waiting for Advanced DaemonSet spec update to be observed...\n",
+		},
+		{
+			name:   "advanced daemonset partially updated",
+			viewer: &AdvancedDaemonSetStatusViewer{},
+			obj: newAdvancedDaemonSet(nil, kruiseappsv1alpha1.DaemonSetStatus{
+				ObservedGeneration: 2, DesiredNumberScheduled: 5, UpdatedNumberScheduled: 3, NumberAvailable: 4,
+			}),
+			expectStatus: "Waiting for Advanced DaemonSet \"demo\" rollout to finish: 3 of 5 updated, 4 available...\n",
+		},
+		{
+			name:   "advanced daemonset updated but not available",
+			viewer: &AdvancedDaemonSetStatusViewer{},
+			obj: newAdvancedDaemonSet(nil, kruiseappsv1alpha1.DaemonSetStatus{
+				ObservedGeneration: 2, DesiredNumberScheduled: 5, UpdatedNumberScheduled: 5, NumberAvailable: 4,
+			}),
+			expectStatus: "Waiting for Advanced DaemonSet \"demo\" rollout to finish: 4 of 5 pods are available...\n",
+		},
+		{
+			name:   "advanced daemonset partitioned update complete",
+			viewer: &AdvancedDaemonSetStatusViewer{},
+			obj: newAdvancedDaemonSet(pointer.Int32(2), kruiseappsv1alpha1.DaemonSetStatus{
+				ObservedGeneration: 2, DesiredNumberScheduled: 5, UpdatedNumberScheduled: 3, NumberAvailable: 5,
+			}),
+			expectStatus: "Advanced DaemonSet \"demo\" successfully rolled out\n",
+			expectDone:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tc.obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			status, done, err := tc.viewer.Status(nil, &unstructured.Unstructured{Object: content}, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectStatus, status)
+			assert.Equal(t, tc.expectDone, done)
+		})
+	}
+}
+
+func intOrStrPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
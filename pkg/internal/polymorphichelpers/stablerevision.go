@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"context"
+	"fmt"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruiseappsv1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	kruiseclientsets "github.com/openkruise/kruise-api/client/clientset/versioned"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	deploymentutil "k8s.io/kubectl/pkg/util/deployment"
+)
+
+// stableRevision returns the number of the revision of obj which stableRevision refers to. stableRevision is the
+// name or the hash of a revision, as recorded by a Rollout. If it is empty, the current revision recorded in the
+// status of obj is used, which only CloneSets, Advanced StatefulSets and StatefulSets record.
+func stableRevision(restClientGetter genericclioptions.RESTClientGetter, obj runtime.Object, stableRevision string) (int64, error) {
+	clientConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return 0, err
+	}
+	external, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return 0, err
+	}
+	kruiseExternal, err := kruiseclientsets.NewForConfig(clientConfig)
+	if err != nil {
+		return 0, err
+	}
+	return stableRevisionFor(obj, external, kruiseExternal, stableRevision)
+}
+
+func stableRevisionFor(obj runtime.Object, c kubernetes.Interface, kc kruiseclientsets.Interface, stableRevision string) (int64, error) {
+	var history []*appsv1.ControllerRevision
+	var err error
+	switch obj := obj.(type) {
+	case *appsv1.Deployment:
+		// the revisions of a Deployment are its ReplicaSets, labeled with the hash of their pod template
+		if len(stableRevision) == 0 {
+			return 0, fmt.Errorf("deployment %s does not record a stable revision, roll it back through its rollout", obj.Name)
+		}
+		_, oldRSs, newRS, err := deploymentutil.GetAllReplicaSets(obj, c.AppsV1())
+		if err != nil {
+			return 0, fmt.Errorf("failed to retrieve replica sets from deployment %s: %v", obj.Name, err)
+		}
+		for _, rs := range append(oldRSs, newRS) {
+			if rs == nil || (rs.Name != stableRevision && rs.Labels[appsv1.DefaultDeploymentUniqueLabelKey] != stableRevision) {
+				continue
+			}
+			return deploymentutil.Revision(rs)
+		}
+		return 0, fmt.Errorf("stable revision %q not found in the history of deployment %s", stableRevision, obj.Name)
+	case *kruiseappsv1alpha1.CloneSet:
+		if len(stableRevision) == 0 {
+			stableRevision = obj.Status.CurrentRevision
+		}
+		_, history, err = clonesetHistory(context.TODO(), c.AppsV1(), kc.AppsV1alpha1(), obj.Namespace, obj.Name)
+	case *kruiseappsv1beta1.StatefulSet:
+		if len(stableRevision) == 0 {
+			stableRevision = obj.Status.CurrentRevision
+		}
+		_, history, err = advancedstsHistory(context.TODO(), c.AppsV1(), kc.AppsV1beta1(), obj.Namespace, obj.Name)
+	case *appsv1.StatefulSet:
+		if len(stableRevision) == 0 {
+			stableRevision = obj.Status.CurrentRevision
+		}
+		_, history, err = statefulSetHistory(context.TODO(), c.AppsV1(), obj.Namespace, obj.Name)
+	case *kruiseappsv1alpha1.DaemonSet:
+		if len(stableRevision) == 0 {
+			return 0, fmt.Errorf("advanced daemonset %s does not record a stable revision, roll it back through its rollout", obj.Name)
+		}
+		_, history, err = advancedDaemonSetHistory(context.TODO(), c.AppsV1(), kc.AppsV1alpha1(), obj.Namespace, obj.Name)
+	case *appsv1.DaemonSet:
+		if len(stableRevision) == 0 {
+			return 0, fmt.Errorf("daemonset %s does not record a stable revision, roll it back through its rollout", obj.Name)
+		}
+		_, history, err = daemonSetHistory(context.TODO(), c.AppsV1(), obj.Namespace, obj.Name)
+	default:
+		return 0, fmt.Errorf("no stable revision can be found for %T", obj)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if len(stableRevision) == 0 {
+		return 0, fmt.Errorf("no current revision is recorded in the status yet")
+	}
+	for _, h := range history {
+		// a Rollout records the hash of the revision, while the workload records its full name
+		if isRevision(h, stableRevision) {
+			return h.Revision, nil
+		}
+	}
+	return 0, fmt.Errorf("stable revision %q not found in the history", stableRevision)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"testing"
+
+	kruiseappsv1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisefake "github.com/openkruise/kruise-api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStableRevisionForCloneSet(t *testing.T) {
+	cs := &kruiseappsv1alpha1.CloneSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: types.UID("cs-uid")},
+		Spec: kruiseappsv1alpha1.CloneSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: historyTestLabels},
+			Template: newHistoryTestTemplate(),
+		},
+		Status: kruiseappsv1alpha1.CloneSetStatus{CurrentRevision: "demo-2"},
+	}
+	revisions := newHistoryTestRevisions(cs, kruiseappsv1alpha1.SchemeGroupVersion.WithKind("CloneSet"))
+
+	testCases := []struct {
+		name           string
+		stableRevision string
+		currentStatus  string
+		expectRevision int64
+		expectErr      string
+	}{
+		{
+			name:           "current revision of the status",
+			currentStatus:  "demo-2",
+			expectRevision: 2,
+		},
+		{
+			name:           "stable revision by name",
+			stableRevision: "demo-1",
+			currentStatus:  "demo-2",
+			expectRevision: 1,
+		},
+		{
+			name:           "stable revision by hash",
+			stableRevision: "3",
+			currentStatus:  "demo-2",
+			expectRevision: 3,
+		},
+		{
+			name:      "no current revision",
+			expectErr: "no current revision is recorded in the status yet",
+		},
+		{
+			name:           "unknown stable revision",
+			stableRevision: "demo-9",
+			currentStatus:  "demo-2",
+			expectErr:      `stable revision "demo-9" not found in the history`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := cs.DeepCopy()
+			obj.Status.CurrentRevision = tc.currentStatus
+			revision, err := stableRevisionFor(obj, fake.NewSimpleClientset(revisions...), kruisefake.NewSimpleClientset(obj), tc.stableRevision)
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectRevision, revision)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"fmt"
+
+	rolloutsapiv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	rolloutsapiv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResolveWorkloadRef returns the group version kind and the name of the workload referenced by a v1alpha1 or v1beta1
+// Rollout. An APIVersion without a group, e.g. "v1", resolves to the core group.
+func ResolveWorkloadRef(obj runtime.Object) (schema.GroupVersionKind, string, error) {
+	var rolloutName string
+	var workloadRef rolloutsapiv1beta1.ObjectRef
+	switch rollout := obj.(type) {
+	case *rolloutsapiv1alpha1.Rollout:
+		rolloutName = rollout.Name
+		if ref := rollout.Spec.ObjectRef.WorkloadRef; ref != nil {
+			workloadRef = rolloutsapiv1beta1.ObjectRef{APIVersion: ref.APIVersion, Kind: ref.Kind, Name: ref.Name}
+		}
+	case *rolloutsapiv1beta1.Rollout:
+		rolloutName = rollout.Name
+		workloadRef = rollout.Spec.WorkloadRef
+	default:
+		return schema.GroupVersionKind{}, "", fmt.Errorf("unsupported version of Rollout")
+	}
+
+	if workloadRef == (rolloutsapiv1beta1.ObjectRef{}) {
+		return schema.GroupVersionKind{}, "", fmt.Errorf("rollout %q does not reference a workload", rolloutName)
+	}
+	if len(workloadRef.APIVersion) == 0 || len(workloadRef.Kind) == 0 || len(workloadRef.Name) == 0 {
+		return schema.GroupVersionKind{}, "", fmt.Errorf("rollout %q has an incomplete workloadRef, apiVersion, kind and name are required", rolloutName)
+	}
+	gv, err := schema.ParseGroupVersion(workloadRef.APIVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, "", fmt.Errorf("rollout %q has an invalid workloadRef: %v", rolloutName, err)
+	}
+	return gv.WithKind(workloadRef.Kind), workloadRef.Name, nil
+}
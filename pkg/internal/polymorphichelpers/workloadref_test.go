@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package polymorphichelpers
+
+import (
+	"testing"
+
+	rolloutsapiv1alpha1 "github.com/openkruise/kruise-rollout-api/rollouts/v1alpha1"
+	rolloutsapiv1beta1 "github.com/openkruise/kruise-rollout-api/rollouts/v1beta1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestResolveWorkloadRef(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "ro", Namespace: "test"}
+	v1beta1Rollout := func(ref rolloutsapiv1beta1.ObjectRef) *rolloutsapiv1beta1.Rollout {
+		return &rolloutsapiv1beta1.Rollout{ObjectMeta: meta, Spec: rolloutsapiv1beta1.RolloutSpec{WorkloadRef: ref}}
+	}
+	v1alpha1Rollout := func(ref *rolloutsapiv1alpha1.WorkloadRef) *rolloutsapiv1alpha1.Rollout {
+		return &rolloutsapiv1alpha1.Rollout{ObjectMeta: meta, Spec: rolloutsapiv1alpha1.RolloutSpec{ObjectRef: rolloutsapiv1alpha1.ObjectRef{WorkloadRef: ref}}}
+	}
+
+	testCases := []struct {
+		name       string
+		rollout    runtime.Object
+		expectGVK  schema.GroupVersionKind
+		expectName string
+		expectErr  string
+	}{
+		{
+			name:       "grouped apiVersion",
+			rollout:    v1beta1Rollout(rolloutsapiv1beta1.ObjectRef{APIVersion: "apps.kruise.io/v1alpha1", Kind: "CloneSet", Name: "web"}),
+			expectGVK:  schema.GroupVersionKind{Group: "apps.kruise.io", Version: "v1alpha1", Kind: "CloneSet"},
+			expectName: "web",
+		},
+		{
+			name:       "core apiVersion",
+			rollout:    v1beta1Rollout(rolloutsapiv1beta1.ObjectRef{APIVersion: "v1", Kind: "ReplicationController", Name: "web"}),
+			expectGVK:  schema.GroupVersionKind{Version: "v1", Kind: "ReplicationController"},
+			expectName: "web",
+		},
+		{
+			name:       "v1alpha1 rollout",
+			rollout:    v1alpha1Rollout(&rolloutsapiv1alpha1.WorkloadRef{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}),
+			expectGVK:  appsv1.SchemeGroupVersion.WithKind("Deployment"),
+			expectName: "web",
+		},
+		{
+			name:      "empty workloadRef",
+			rollout:   v1beta1Rollout(rolloutsapiv1beta1.ObjectRef{}),
+			expectErr: `rollout "ro" does not reference a workload`,
+		},
+		{
+			name:      "missing v1alpha1 workloadRef",
+			rollout:   v1alpha1Rollout(nil),
+			expectErr: `rollout "ro" does not reference a workload`,
+		},
+		{
+			name:      "incomplete workloadRef",
+			rollout:   v1beta1Rollout(rolloutsapiv1beta1.ObjectRef{Kind: "CloneSet", Name: "web"}),
+			expectErr: `rollout "ro" has an incomplete workloadRef, apiVersion, kind and name are required`,
+		},
+		{
+			name:      "invalid apiVersion",
+			rollout:   v1beta1Rollout(rolloutsapiv1beta1.ObjectRef{APIVersion: "apps.kruise.io/v1/beta", Kind: "CloneSet", Name: "web"}),
+			expectErr: `rollout "ro" has an invalid workloadRef: unexpected GroupVersion string: apps.kruise.io/v1/beta`,
+		},
+		{
+			name:      "not a rollout",
+			rollout:   &appsv1.Deployment{},
+			expectErr: "unsupported version of Rollout",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gvk, name, err := ResolveWorkloadRef(tc.rollout)
+			if len(tc.expectErr) > 0 {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectGVK, gvk)
+			assert.Equal(t, tc.expectName, name)
+		})
+	}
+}